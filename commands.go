@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// Command names; serve is used when none is given
+const (
+	commandServe  = "serve"
+	commandIndex  = "index"
+	commandStats  = "stats"
+	commandVerify = "verify"
+)
+
+// commands lists the commands in the order shown by the usage message
+var commands = []struct {
+	name        string
+	description string
+}{
+	{commandServe, "Serve the vault to an MCP client over stdio (default)"},
+	{commandIndex, "Build or refresh the saved search index from every note and print its size"},
+	{commandStats, "Print note, folder and tag statistics"},
+	{commandVerify, "Check notes for unportable names, bad frontmatter and broken links"},
+}
+
+// errProblemsFound makes verify exit non-zero after printing its report
+var errProblemsFound = errors.New("problems found")
+
+// isCommand reports whether arg names a command
+func isCommand(arg string) bool {
+	return slices.ContainsFunc(commands, func(command struct{ name, description string }) bool {
+		return command.name == arg
+	})
+}
+
+// runCommand runs an offline command against v, writing its output to out
+// as text, or as JSON when jsonOutput is set
+func runCommand(ctx context.Context, name string, v vault.Vault, jsonOutput bool, out io.Writer) error {
+	switch name {
+	case commandIndex:
+		return runIndex(ctx, v, jsonOutput, out)
+	case commandStats:
+		return runStats(ctx, v, jsonOutput, out)
+	case commandVerify:
+		return runVerify(ctx, v, jsonOutput, out)
+	default:
+		return fmt.Errorf("unknown command %q", name)
+	}
+}
+
+// runIndex loads every note so the search index covers the whole vault,
+// then saves it for servers started with the search index. Notes indexed
+// in the saved index and unchanged since are not indexed again.
+func runIndex(ctx context.Context, v vault.Vault, jsonOutput bool, out io.Writer) error {
+	start := time.Now()
+	if _, err := v.List(ctx, vault.ListOptions{Recursive: true}); err != nil {
+		return err
+	}
+	stats, err := v.SaveIndex(ctx)
+	if err != nil {
+		return err
+	}
+	elapsed := time.Since(start)
+
+	if jsonOutput {
+		return writeJSON(out, struct {
+			vault.IndexStats
+			DurationMS int64 `json:"duration_ms"`
+		}{stats, elapsed.Milliseconds()})
+	}
+
+	_, err = fmt.Fprintf(out, "Indexed %d notes in %s: %d terms, %d of at most %d postings\n",
+		stats.Notes, elapsed.Round(time.Millisecond), stats.Terms, stats.Postings, stats.MaxPostings)
+	return err
+}
+
+// runStats prints the statistics of the whole vault
+func runStats(ctx context.Context, v vault.Vault, jsonOutput bool, out io.Writer) error {
+	stats, err := v.Stats(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		return writeJSON(out, stats)
+	}
+
+	fmt.Fprintf(out, "%d notes (%d bytes) across %d folders, %d untagged, %d created and %d modified in the last 7 days\n",
+		stats.NoteCount, stats.TotalSize, len(stats.Folders), stats.UntaggedCount, stats.CreatedLast7, stats.ModifiedLast7)
+	for _, tag := range stats.Tags[:min(len(stats.Tags), 10)] {
+		fmt.Fprintf(out, "  #%s: %d\n", tag.Tag, tag.Count)
+	}
+	return nil
+}
+
+// runVerify prints the problems found in the vault and returns
+// errProblemsFound when there are any
+func runVerify(ctx context.Context, v vault.Vault, jsonOutput bool, out io.Writer) error {
+	report, err := v.Verify(ctx, vault.VerifyOptions{})
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		if err := writeJSON(out, report); err != nil {
+			return err
+		}
+	} else {
+		for _, problem := range report.Problems {
+			location := problem.Path
+			if problem.Line > 0 {
+				location = fmt.Sprintf("%s:%d", problem.Path, problem.Line)
+			}
+			fmt.Fprintf(out, "%s: %s: %s\n", location, problem.Kind, problem.Message)
+		}
+		fmt.Fprintf(out, "Checked %d notes, found %d problems\n", report.NotesChecked, len(report.Problems))
+	}
+
+	if len(report.Problems) > 0 {
+		return errProblemsFound
+	}
+	return nil
+}
+
+// writeJSON writes v to out as indented JSON
+func writeJSON(out io.Writer, v any) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -2,7 +2,13 @@ module github.com/kratos/mcp-notes
 
 go 1.25.5
 
-require github.com/mark3labs/mcp-go v0.43.2
+require (
+	github.com/mark3labs/mcp-go v0.43.2
+	github.com/yuin/goldmark v1.8.2
+	golang.org/x/sys v0.9.0
+	golang.org/x/text v0.40.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/bahlo/generic-list-go v0.2.0 // indirect
@@ -13,5 +19,4 @@ require (
 	github.com/spf13/cast v1.7.1 // indirect
 	github.com/wk8/go-ordered-map/v2 v2.1.8 // indirect
 	github.com/yosida95/uritemplate/v3 v3.0.2 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
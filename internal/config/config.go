@@ -0,0 +1,355 @@
+// Package config provides the settings of the notes server, read from a
+// YAML file and overridden by command-line flags, with their defaults and
+// validation.
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"time"
+
+	internalserver "github.com/kratos/mcp-notes/internal/server"
+	"github.com/kratos/mcp-notes/internal/tools"
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// DefaultFile is where a vault's config file is found when --config is not
+// given, relative to the vault root
+const DefaultFile = ".mcp-notes/config.yaml"
+
+// Config holds every server setting. Each section groups the flags of one
+// feature; the yaml keys name them in the config file.
+type Config struct {
+	Vault       string            `yaml:"vault"` // Vault path, replaced by the command-line argument
+	Log         LogConfig         `yaml:"log"`
+	Notes       NotesConfig       `yaml:"notes"`
+	Cache       CacheConfig       `yaml:"cache"`
+	Backups     BackupConfig      `yaml:"backups"`
+	Audit       AuditConfig       `yaml:"audit"`
+	Locks       LockConfig        `yaml:"locks"`
+	Limits      LimitConfig       `yaml:"limits"`
+	Blobs       BlobConfig        `yaml:"blobs"`
+	Paths       PathConfig        `yaml:"paths"`
+	Tools       ToolConfig        `yaml:"tools"`
+	Frontmatter FrontmatterConfig `yaml:"frontmatter"`
+	Capture     CaptureConfig     `yaml:"capture"`
+	Publish     PublishConfig     `yaml:"publish"`
+	Lint        LintConfig        `yaml:"lint"`
+	Types       TypesConfig       `yaml:"types"`
+	Scratch     ScratchConfig     `yaml:"scratch"`
+	Server      ServerConfig      `yaml:"server"`
+	Metrics     MetricsConfig     `yaml:"metrics"`
+	JSON        bool              `yaml:"json"` // Print command output as JSON
+}
+
+// LogConfig sets where logs go and how much is logged
+type LogConfig struct {
+	Level string `yaml:"level"` // debug, info, warn or error
+	File  string `yaml:"file"`  // Log file, stderr when empty
+}
+
+// NotesConfig sets which notes are served and how they are read
+type NotesConfig struct {
+	Root           string   `yaml:"root"` // Folder of the vault the server is limited to, empty for all of it
+	FollowSymlinks bool     `yaml:"follow_symlinks"`
+	IncludeHidden  bool     `yaml:"include_hidden"`
+	Concurrency    int      `yaml:"concurrency"`     // Files read in parallel, 0 for the default
+	CreatedFields  []string `yaml:"created_fields"`  // Frontmatter properties holding the creation date
+	DateFormat     string   `yaml:"date_format"`     // Extra Go time layout for frontmatter dates
+	SourceEncoding string   `yaml:"source_encoding"` // Encoding of notes that are not UTF-8
+	VaultName      string   `yaml:"vault_name"`      // Obsidian vault name for obsidian:// links
+	IndexNotes     []string `yaml:"index_notes"`     // File names of the note describing its folder, first found wins
+	TitleFrom      []string `yaml:"title_from"`      // Sources of a note's title, first found wins
+
+	ObsidianConfig   bool   `yaml:"obsidian_config"`   // Read the settings in .obsidian
+	AttachmentFolder string `yaml:"attachment_folder"` // Replaces Obsidian's attachment folder
+}
+
+// CacheConfig sizes the note cache and the search index
+type CacheConfig struct {
+	SizeMiB     int64 `yaml:"size_mib"` // 0 for unlimited
+	Warm        int   `yaml:"warm"`     // Notes loaded in parallel at startup, 0 for off
+	SearchIndex bool  `yaml:"search_index"`
+}
+
+// BackupConfig sets the versions kept of overwritten notes
+type BackupConfig struct {
+	Versions int  `yaml:"versions"`
+	Disabled bool `yaml:"disabled"`
+}
+
+// AuditConfig sets the log of changes to notes
+type AuditConfig struct {
+	File    string `yaml:"file"`     // .mcp-notes/audit.log in the vault when empty
+	SizeMiB int64  `yaml:"size_mib"` // Size at which the log is rotated
+	Strict  bool   `yaml:"strict"`   // Fail writes that cannot be recorded
+}
+
+// LockConfig sets how clients hold note locks
+type LockConfig struct {
+	ClientName string        `yaml:"client_name"`
+	TTL        time.Duration `yaml:"ttl"`
+}
+
+// LimitConfig bounds the writes of a session and the size of batches
+type LimitConfig struct {
+	WritesPerMinute     int   `yaml:"writes_per_minute"`
+	FileWritesPerMinute int   `yaml:"file_writes_per_minute"`
+	FilesPerSession     int   `yaml:"files_per_session"`
+	BatchOps            int   `yaml:"batch_ops"`
+	BatchKiB            int64 `yaml:"batch_kib"`
+}
+
+// BlobConfig sets when a note counts as embedded data
+type BlobConfig struct {
+	MinSizeKiB int64   `yaml:"min_size_kib"`
+	LineLength int     `yaml:"line_length"`
+	DataRatio  float64 `yaml:"data_ratio"`
+}
+
+// PathConfig restricts the vault paths that may be modified
+type PathConfig struct {
+	ReadOnly []string `yaml:"read_only"` // Globs never modified
+	Writable []string `yaml:"writable"`  // Globs of the only paths modified
+}
+
+// ToolConfig selects the tools exposed to clients
+type ToolConfig struct {
+	NoWrite bool     `yaml:"no_write"` // Expose no tool that modifies the vault
+	Allow   []string `yaml:"allow"`    // Only these tools, all when empty
+	Disable []string `yaml:"disable"`
+}
+
+// FrontmatterConfig sets the frontmatter of created notes
+type FrontmatterConfig struct {
+	Auto       bool     `yaml:"auto"`
+	Tags       []string `yaml:"tags"`
+	DateFormat string   `yaml:"date_format"`
+	Config     string   `yaml:"config"` // File with a template and a schema
+}
+
+// CaptureConfig sets where the capture tool writes and how its entries
+// look
+type CaptureConfig struct {
+	Note       string `yaml:"note"`        // Inbox note, relative to the vault
+	Entry      string `yaml:"entry"`       // Entry template with {text}, {time} and {date}
+	TimeFormat string `yaml:"time_format"` // Go time layout of {time}
+	Heading    string `yaml:"heading"`     // Go time layout of the date heading, empty for none
+}
+
+// PublishConfig names the frontmatter properties publish_note sets
+type PublishConfig struct {
+	Field     string `yaml:"field"`      // Property marking a note for publishing
+	DateField string `yaml:"date_field"` // Property holding when it was marked, empty for none
+}
+
+// LintConfig chooses the rules of the lint tools and sets their options
+type LintConfig struct {
+	Enable  []string                  `yaml:"enable"` // Only these rules, all when empty
+	Disable []string                  `yaml:"disable"`
+	Rules   map[string]LintRuleConfig `yaml:"rules"` // By rule ID
+}
+
+// LintRuleConfig overrides the severity and options of a lint rule
+type LintRuleConfig struct {
+	Severity string         `yaml:"severity"`
+	Options  map[string]any `yaml:",inline"` // The rule's options, e.g. match_filename
+}
+
+// TypesConfig classifies notes into types by path and frontmatter
+type TypesConfig struct {
+	Default string               `yaml:"default"` // Type of notes no rule matches
+	Rules   []NoteTypeRuleConfig `yaml:"rules"`   // The first matching rule applies
+}
+
+// NoteTypeRuleConfig gives a type to the notes matching its path glob and
+// frontmatter conditions
+type NoteTypeRuleConfig struct {
+	Type       string         `yaml:"type"`
+	Path       string         `yaml:"path"`
+	Properties map[string]any `yaml:"properties"` // As search_notes' properties
+}
+
+// ScratchConfig bounds the scratch notes and sets where they are kept
+type ScratchConfig struct {
+	Dir      string `yaml:"dir"`       // Keeps them across restarts, in memory when empty
+	MaxNotes int    `yaml:"max_notes"` // Scratch notes at a time
+	MaxKiB   int64  `yaml:"max_kib"`   // Content of all scratch notes
+}
+
+// ServerConfig sets the limits of tool calls
+type ServerConfig struct {
+	ShutdownTimeout  time.Duration `yaml:"shutdown_timeout"`
+	SearchTimeout    time.Duration `yaml:"search_timeout"`
+	ToolTimeout      time.Duration `yaml:"tool_timeout"`
+	MaxResponseBytes int           `yaml:"max_response_bytes"`
+	IgnoreRoots      bool          `yaml:"ignore_roots"`
+	ExportDir        string        `yaml:"export_dir"`
+}
+
+// MetricsConfig sets how metrics are recorded and served
+type MetricsConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Addr    string `yaml:"addr"` // Serve them at http://ADDR/metrics
+}
+
+// Default returns the settings used when neither the config file nor a
+// flag sets them
+func Default() Config {
+	return Config{
+		Log:     LogConfig{Level: "info"},
+		Notes:   NotesConfig{CreatedFields: []string{"created", "date"}, IndexNotes: slices.Clone(vault.DefaultIndexNotes), TitleFrom: []string{string(vault.TitleFromFrontmatter), string(vault.TitleFromFilename)}, ObsidianConfig: true},
+		Cache:   CacheConfig{SizeMiB: 256},
+		Backups: BackupConfig{Versions: 5},
+		Audit:   AuditConfig{SizeMiB: vault.DefaultAuditMaxBytes >> 20},
+		Locks:   LockConfig{TTL: vault.DefaultLockTTL},
+		Limits:  LimitConfig{BatchOps: vault.DefaultBatchMaxOperations, BatchKiB: vault.DefaultBatchMaxBytes >> 10},
+		Blobs: BlobConfig{
+			MinSizeKiB: vault.DefaultBlobMinSize >> 10,
+			LineLength: vault.DefaultBlobLineLength,
+			DataRatio:  vault.DefaultBlobDataRatio,
+		},
+		Capture: CaptureConfig{
+			Note:       vault.DefaultCaptureNote,
+			Entry:      vault.DefaultCaptureEntry,
+			TimeFormat: vault.DefaultCaptureTimeFormat,
+			Heading:    vault.DefaultCaptureHeading,
+		},
+		Publish: PublishConfig{Field: vault.DefaultPublishField, DateField: vault.DefaultPublishDateField},
+		Scratch: ScratchConfig{MaxNotes: vault.DefaultScratchMaxNotes, MaxKiB: vault.DefaultScratchMaxBytes >> 10},
+		Server: ServerConfig{
+			ShutdownTimeout: internalserver.DefaultGracePeriod,
+			SearchTimeout:   internalserver.DefaultSearchTimeout,
+			ToolTimeout:     internalserver.DefaultToolTimeout,
+		},
+	}
+}
+
+// ToolPolicy returns the tool selection of the tools section
+func (c Config) ToolPolicy() tools.ToolPolicy {
+	return tools.ToolPolicy{ReadOnly: c.Tools.NoWrite, Allow: c.Tools.Allow, Disable: c.Tools.Disable}
+}
+
+// TitleSources returns notes.title_from as vault title sources
+func (c Config) TitleSources() vault.TitleSources {
+	sources := make(vault.TitleSources, len(c.Notes.TitleFrom))
+	for i, source := range c.Notes.TitleFrom {
+		sources[i] = vault.TitleSource(source)
+	}
+	return sources
+}
+
+// CaptureSettings returns the capture section as vault settings
+func (c Config) CaptureSettings() vault.CaptureSettings {
+	return vault.CaptureSettings{Note: c.Capture.Note, Entry: c.Capture.Entry, TimeFormat: c.Capture.TimeFormat, Heading: c.Capture.Heading}
+}
+
+// PublishSettings returns the publish section as vault settings
+func (c Config) PublishSettings() vault.PublishSettings {
+	return vault.PublishSettings{Field: c.Publish.Field, DateField: c.Publish.DateField}
+}
+
+// LintSettings returns the lint section as vault settings
+func (c Config) LintSettings() vault.LintSettings {
+	s := vault.LintSettings{Enable: c.Lint.Enable, Disable: c.Lint.Disable}
+	if len(c.Lint.Rules) > 0 {
+		s.Rules = make(map[string]vault.LintRuleSettings, len(c.Lint.Rules))
+		for id, rule := range c.Lint.Rules {
+			s.Rules[id] = vault.LintRuleSettings{Severity: rule.Severity, Options: rule.Options}
+		}
+	}
+	return s
+}
+
+// NoteTypeSettings returns the types section as vault settings
+func (c Config) NoteTypeSettings() vault.NoteTypeSettings {
+	s := vault.NoteTypeSettings{Default: c.Types.Default}
+	for _, rule := range c.Types.Rules {
+		s.Rules = append(s.Rules, vault.NoteTypeRule{Type: rule.Type, Path: rule.Path, Properties: rule.Properties})
+	}
+	return s
+}
+
+// Validate reports the first setting out of range, naming its key
+func (c Config) Validate() error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Log.Level)); err != nil {
+		return fmt.Errorf("log.level: unknown level %q; valid levels are debug, info, warn and error", c.Log.Level)
+	}
+
+	for _, setting := range []struct {
+		key string
+		n   int64
+	}{
+		{"notes.concurrency", int64(c.Notes.Concurrency)},
+		{"cache.size_mib", c.Cache.SizeMiB},
+		{"cache.warm", int64(c.Cache.Warm)},
+		{"backups.versions", int64(c.Backups.Versions)},
+		{"audit.size_mib", c.Audit.SizeMiB},
+		{"limits.writes_per_minute", int64(c.Limits.WritesPerMinute)},
+		{"limits.file_writes_per_minute", int64(c.Limits.FileWritesPerMinute)},
+		{"limits.files_per_session", int64(c.Limits.FilesPerSession)},
+		{"limits.batch_ops", int64(c.Limits.BatchOps)},
+		{"limits.batch_kib", c.Limits.BatchKiB},
+		{"blobs.min_size_kib", c.Blobs.MinSizeKiB},
+		{"blobs.line_length", int64(c.Blobs.LineLength)},
+		{"scratch.max_notes", int64(c.Scratch.MaxNotes)},
+		{"scratch.max_kib", c.Scratch.MaxKiB},
+	} {
+		if setting.n < 0 {
+			return fmt.Errorf("%s: %d must not be negative", setting.key, setting.n)
+		}
+	}
+	for _, setting := range []struct {
+		key string
+		d   time.Duration
+	}{
+		{"locks.ttl", c.Locks.TTL},
+		{"server.shutdown_timeout", c.Server.ShutdownTimeout},
+		{"server.search_timeout", c.Server.SearchTimeout},
+		{"server.tool_timeout", c.Server.ToolTimeout},
+	} {
+		if setting.d < 0 {
+			return fmt.Errorf("%s: %v must not be negative", setting.key, setting.d)
+		}
+	}
+	if c.Blobs.DataRatio < 0 || c.Blobs.DataRatio > 1 {
+		return fmt.Errorf("blobs.data_ratio: %g must be between 0 and 1", c.Blobs.DataRatio)
+	}
+
+	if err := c.TitleSources().Validate(); err != nil {
+		return fmt.Errorf("notes.title_from: %w", err)
+	}
+
+	if err := c.CaptureSettings().Validate(); err != nil {
+		return fmt.Errorf("capture: %w", err)
+	}
+
+	if err := c.PublishSettings().Validate(); err != nil {
+		return fmt.Errorf("publish: %w", err)
+	}
+
+	if err := c.LintSettings().Validate(); err != nil {
+		return fmt.Errorf("lint: %w", err)
+	}
+	if err := c.NoteTypeSettings().Validate(); err != nil {
+		return fmt.Errorf("types: %w", err)
+	}
+
+	if err := c.ToolPolicy().Validate(); err != nil {
+		return fmt.Errorf("tools: %w", err)
+	}
+	if n := c.Server.MaxResponseBytes; n < 0 || (n > 0 && n < tools.MinResponseBytes) {
+		return fmt.Errorf("server.max_response_bytes: %d must be 0 or at least %d", n, tools.MinResponseBytes)
+	}
+	if dir := c.Server.ExportDir; dir != "" {
+		if stat, err := os.Stat(dir); err != nil {
+			return fmt.Errorf("server.export_dir: %w", err)
+		} else if !stat.IsDir() {
+			return fmt.Errorf("server.export_dir: %s is not a directory", dir)
+		}
+	}
+	return nil
+}
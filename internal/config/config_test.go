@@ -0,0 +1,229 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfig writes content to a config file in a temporary directory
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return file
+}
+
+func TestLoad(t *testing.T) {
+	file := writeConfig(t, `
+vault: notes
+log:
+  level: debug
+  file: /var/log/notes.log
+cache:
+  size_mib: 64
+  colour: blue
+locks:
+  ttl: 2m
+frontmatter:
+  config: frontmatter.yaml
+tools:
+  disable: [create_note, update_note]
+metrics:
+blob:
+  min_size_kib: 1
+lint:
+  disable: [no-trailing-whitespace]
+  rules:
+    single-h1: {severity: warning, match_filename: false}
+types:
+  rules:
+    - {type: daily, path: "Daily/*"}
+    - {type: person, properties: {category: person}}
+`)
+
+	c := Default()
+	warnings, err := Load(file, &c)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := []string{"unknown config key cache.colour (line 8)", "unknown config key blob (line 16)"}
+	if !slices.Equal(warnings, want) {
+		t.Errorf("Load() warnings = %q, want %q", warnings, want)
+	}
+
+	dir := filepath.Dir(file)
+	if c.Vault != filepath.Join(dir, "notes") || c.Frontmatter.Config != filepath.Join(dir, "frontmatter.yaml") || c.Log.File != "/var/log/notes.log" {
+		t.Errorf("Load() paths = %q, %q, %q; want relative paths from the file's directory", c.Vault, c.Frontmatter.Config, c.Log.File)
+	}
+	if c.Log.Level != "debug" || c.Cache.SizeMiB != 64 || c.Locks.TTL != 2*time.Minute || !slices.Equal(c.Tools.Disable, []string{"create_note", "update_note"}) {
+		t.Errorf("Load() = %+v", c)
+	}
+	if rule := c.Lint.Rules["single-h1"]; rule.Severity != "warning" || !reflect.DeepEqual(rule.Options, map[string]any{"match_filename": false}) {
+		t.Errorf("Load() lint rules = %+v, want single-h1 options beside its severity", c.Lint.Rules)
+	}
+	if rules := c.NoteTypeSettings().Rules; len(rules) != 2 || rules[0].Path != "Daily/*" || !reflect.DeepEqual(rules[1].Properties, map[string]any{"category": "person"}) {
+		t.Errorf("Load() note type rules = %+v", rules)
+	}
+	if c.Backups.Versions != 5 || !slices.Equal(c.Notes.CreatedFields, []string{"created", "date"}) {
+		t.Errorf("Load() lost defaults: backups %d, created fields %v", c.Backups.Versions, c.Notes.CreatedFields)
+	}
+	if err := c.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"number", "cache:\n  size_mib: lots\n", `cache.size_mib (line 2): expected a whole number, got "lots"`},
+		{"bool", "notes:\n  include_hidden: maybe\n", `notes.include_hidden (line 2): expected true or false`},
+		{"duration", "server:\n  search_timeout: 5\n", `server.search_timeout (line 2): expected a duration`},
+		{"list", "paths:\n  read_only: Templates\n", `paths.read_only (line 2): expected a list of strings, got "Templates"`},
+		{"section", "blobs: [1, 2]\n", `blobs (line 1): expected a section of settings, got a list`},
+		{"document", "- vault\n", `config (line 1): expected a section of settings`},
+		{"syntax", "log: {level\n", "failed to parse"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Default()
+			_, err := Load(writeConfig(t, tt.content), &c)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml"), new(Config)); err == nil {
+		t.Error("Load() of a missing file succeeded")
+	}
+	c := Default()
+	if warnings, err := Load(writeConfig(t, ""), &c); err != nil || warnings != nil || !reflect.DeepEqual(c, Default()) {
+		t.Errorf("Load() of an empty file = %v, %v; want the defaults", warnings, err)
+	}
+}
+
+func TestFlagPrecedence(t *testing.T) {
+	file := writeConfig(t, `
+cache:
+  size_mib: 64
+  warm: 8
+paths:
+  read_only: [Templates, Daily]
+tools:
+  disable: [create_note]
+notes:
+  created_fields: [born]
+  index_notes: [Home.md]
+`)
+
+	c := Default()
+	if _, err := Load(file, &c); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	c.BindFlags(fs)
+	args := []string{"--cache-size", "32", "--read-only", "Archive", "--read-only", "Inbox/*", "--created-fields", "", "--lock-ttl", "1m"}
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	// Flags win over the file, which wins over the defaults
+	if c.Cache.SizeMiB != 32 || c.Cache.Warm != 8 || c.Locks.TTL != time.Minute || c.Backups.Versions != 5 {
+		t.Errorf("cache %d, warm %d, ttl %v, backups %d; want 32, 8, 1m0s, 5", c.Cache.SizeMiB, c.Cache.Warm, c.Locks.TTL, c.Backups.Versions)
+	}
+	// A list flag replaces the file's list; repeating it adds to the flag's
+	if !slices.Equal(c.Paths.ReadOnly, []string{"Archive", "Inbox/*"}) || len(c.Notes.CreatedFields) != 0 {
+		t.Errorf("read only %q, created fields %q; want the flags' lists", c.Paths.ReadOnly, c.Notes.CreatedFields)
+	}
+	if !slices.Equal(c.Tools.Disable, []string{"create_note"}) {
+		t.Errorf("tools.disable = %q, want the file's list", c.Tools.Disable)
+	}
+	if !slices.Equal(c.Notes.IndexNotes, []string{"Home.md"}) {
+		t.Errorf("notes.index_notes = %q, want the file's list", c.Notes.IndexNotes)
+	}
+}
+
+func TestFlags(t *testing.T) {
+	// Every flag sets a distinct key; BindFlags panics on unknown ones
+	c := Default()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	c.BindFlags(fs)
+	keys := make(map[string]string)
+	for _, f := range Flags {
+		if other, ok := keys[f.Key]; ok {
+			t.Errorf("--%s and --%s both set %s", f.Name, other, f.Key)
+		}
+		keys[f.Key] = f.Name
+		if fs.Lookup(f.Name) == nil {
+			t.Errorf("--%s is not defined", f.Name)
+		}
+	}
+
+	// Every setting but the vault path and the lint and note type rules has a flag
+	var walk func(v reflect.Value, path string)
+	walk = func(v reflect.Value, path string) {
+		for i := range v.NumField() {
+			key := path + v.Type().Field(i).Tag.Get("yaml")
+			if v.Field(i).Kind() == reflect.Struct {
+				walk(v.Field(i), key+".")
+			} else if _, ok := keys[key]; !ok && key != "vault" && key != "lint.rules" && key != "types.rules" {
+				t.Errorf("%s has no flag", key)
+			}
+		}
+	}
+	walk(reflect.ValueOf(c), "")
+}
+
+func TestValidate(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	tests := []struct {
+		name string
+		edit func(*Config)
+		want string
+	}{
+		{"log level", func(c *Config) { c.Log.Level = "loud" }, "log.level"},
+		{"negative cache", func(c *Config) { c.Cache.SizeMiB = -1 }, "cache.size_mib"},
+		{"negative timeout", func(c *Config) { c.Server.SearchTimeout = -time.Second }, "server.search_timeout: -1s"},
+		{"data ratio", func(c *Config) { c.Blobs.DataRatio = 2 }, "blobs.data_ratio"},
+		{"title source", func(c *Config) { c.Notes.TitleFrom = []string{"filename", "first_h1"} }, "notes.title_from: title source filename must come last"},
+		{"unknown tool", func(c *Config) { c.Tools.Allow = []string{"read_notez"} }, "tools: unknown tool read_notez"},
+		{"response size", func(c *Config) { c.Server.MaxResponseBytes = 10 }, "server.max_response_bytes"},
+		{"export dir", func(c *Config) { c.Server.ExportDir = file }, "not a directory"},
+		{"capture entry", func(c *Config) { c.Capture.Entry = "- {time}" }, "capture: entry template"},
+		{"capture heading", func(c *Config) { c.Capture.Heading = "2006-01-02" }, "capture: date heading"},
+		{"publish field", func(c *Config) { c.Publish.Field = "publish now" }, `publish: property "publish now"`},
+		{"lint rule", func(c *Config) { c.Lint.Disable = []string{"single-h2"} }, `lint: unknown rule "single-h2"`},
+		{"lint option", func(c *Config) {
+			c.Lint.Rules = map[string]LintRuleConfig{"single-h1": {Options: map[string]any{"match_filename": "no"}}}
+		}, "lint: single-h1: option match_filename"},
+		{"note type rule", func(c *Config) {
+			c.Types.Rules = []NoteTypeRuleConfig{{Type: "daily"}}
+		}, "types: rule 1 (daily): set path, properties or both"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Default()
+			tt.edit(&c)
+			if err := c.Validate(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+	if err := Default().Validate(); err != nil {
+		t.Errorf("Validate() of the defaults error = %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kratos/mcp-notes/internal/tools"
+)
+
+// Flag is a command-line flag and the config key it sets
+type Flag struct {
+	Name  string
+	Key   string // Dotted key path in the config file
+	Usage string
+	comma bool // A list flag given as one comma-separated value, not repeated
+}
+
+// Flags lists every command-line flag with its config key, in the order
+// the usage message shows them
+var Flags = []Flag{
+	{Name: "follow-symlinks", Key: "notes.follow_symlinks", Usage: "Descend into symlinked directories inside the vault"},
+	{Name: "include-hidden", Key: "notes.include_hidden", Usage: "Include dotfile notes and dot-directories in list and search"},
+	{Name: "log-level", Key: "log.level", Usage: "Log level: debug, info, warn or error"},
+	{Name: "log-file", Key: "log.file", Usage: "Write logs to this file instead of stderr"},
+	{Name: "cache-size", Key: "cache.size_mib", Usage: "Maximum note cache size in MiB (0 for unlimited)"},
+	{Name: "backup-versions", Key: "backups.versions", Usage: "Previous versions kept per note before it is overwritten"},
+	{Name: "no-backups", Key: "backups.disabled", Usage: "Overwrite notes without keeping backups"},
+	{Name: "search-index", Key: "cache.search_index", Usage: "Keep an in-memory word index so literal and tag searches skip notes that cannot match"},
+	{Name: "concurrency", Key: "notes.concurrency", Usage: "Maximum number of files read in parallel during list and search (0 for default)"},
+	{Name: "client-name", Key: "locks.client_name", Usage: "Name under which clients hold note locks, shared with other servers using the vault (default: the name each client sends)"},
+	{Name: "lock-ttl", Key: "locks.ttl", Usage: "How long a note lock lasts when lock_note sets no ttl_seconds"},
+	{Name: "audit-log", Key: "audit.file", Usage: "File recording every change made to notes as JSON lines (default .mcp-notes/audit.log in the vault)"},
+	{Name: "audit-log-size", Key: "audit.size_mib", Usage: "Size in MiB at which the audit log is rotated, keeping 3 old logs"},
+	{Name: "strict-audit", Key: "audit.strict", Usage: "Fail writes that cannot be recorded in the audit log instead of reporting a warning"},
+	{Name: "warm-cache", Key: "cache.warm", Usage: "Notes loaded in parallel into the cache in the background at startup (0 for off)"},
+	{Name: "root", Key: "notes.root", Usage: "Folder of the vault the server is limited to, e.g. Work: paths are relative to it and nothing outside it can be read or written"},
+	{Name: "created-fields", Key: "notes.created_fields", comma: true, Usage: "Comma-separated frontmatter properties holding a note's creation date (empty to use file times only)"},
+	{Name: "date-format", Key: "notes.date_format", Usage: "Extra Go time layout for frontmatter dates, e.g. 02.01.2006"},
+	{Name: "source-encoding", Key: "notes.source_encoding", Usage: "Encoding of notes that are not valid UTF-8, e.g. windows-1252 (default: reject them)"},
+	{Name: "index-notes", Key: "notes.index_notes", comma: true, Usage: "Comma-separated file names of the note describing its folder, tried in order, e.g. _index.md,README.md (empty for none)"},
+	{Name: "title-from", Key: "notes.title_from", comma: true, Usage: "Comma-separated sources of a note's title, tried in order: frontmatter, first_h1 and filename, which always comes last"},
+	{Name: "vault-name", Key: "notes.vault_name", Usage: "Obsidian vault name for obsidian:// links in results (default $MCP_NOTES_VAULT_NAME)"},
+	{Name: "obsidian-config", Key: "notes.obsidian_config", Usage: "Read excluded files, the attachment folder, and template and daily note settings from the vault's .obsidian folder"},
+	{Name: "attachment-folder", Key: "notes.attachment_folder", Usage: "Folder where attachments named without a folder are looked up (default: Obsidian's attachment folder)"},
+	{Name: "max-writes-per-minute", Key: "limits.writes_per_minute", Usage: "Maximum note writes per minute across the vault (0 for unlimited)"},
+	{Name: "max-file-writes-per-minute", Key: "limits.file_writes_per_minute", Usage: "Maximum writes per minute to a single note (0 for unlimited)"},
+	{Name: "max-files-per-session", Key: "limits.files_per_session", Usage: "Maximum distinct notes modified before the server restarts (0 for unlimited)"},
+	{Name: "max-batch-ops", Key: "limits.batch_ops", Usage: "Maximum operations in one apply_changes call"},
+	{Name: "max-batch-bytes", Key: "limits.batch_kib", Usage: "Maximum content of one apply_changes call in KiB"},
+	{Name: "blob-min-size", Key: "blobs.min_size_kib", Usage: "Size in KiB below which a note is never treated as embedded data"},
+	{Name: "blob-line-length", Key: "blobs.line_length", Usage: "Shortest run of characters without whitespace counted as embedded data"},
+	{Name: "blob-data-ratio", Key: "blobs.data_ratio", Usage: "Share of a note in embedded data at which its data is left out of searches and reads"},
+	{Name: "read-only", Key: "paths.read_only", Usage: "Glob of vault paths that must never be modified, e.g. Templates (repeatable)"},
+	{Name: "writable", Key: "paths.writable", Usage: "Glob of the only vault paths that may be modified, e.g. Inbox (repeatable)"},
+	{Name: "no-write-tools", Key: "tools.no_write", Usage: "Read-only mode: do not expose any tool that modifies the vault"},
+	{Name: "tools", Key: "tools.allow", comma: true, Usage: "Comma-separated tools to expose, e.g. list_notes,search_notes,read_note (default all)"},
+	{Name: "disable-tool", Key: "tools.disable", comma: true, Usage: "Comma-separated tools not to expose, e.g. create_note,update_note"},
+	{Name: "auto-frontmatter", Key: "frontmatter.auto", Usage: "Add frontmatter with a created timestamp and source: mcp to created notes that have none"},
+	{Name: "frontmatter-tags", Key: "frontmatter.tags", comma: true, Usage: "Comma-separated tags added by --auto-frontmatter, e.g. inbox"},
+	{Name: "frontmatter-date-format", Key: "frontmatter.date_format", Usage: "Go time layout of the created timestamp added by --auto-frontmatter (default RFC3339)"},
+	{Name: "frontmatter-config", Key: "frontmatter.config", Usage: "YAML file with a frontmatter template for created notes and a schema that created and updated notes must follow"},
+	{Name: "capture-note", Key: "capture.note", Usage: "Note the capture tool writes to when given no target"},
+	{Name: "capture-entry", Key: "capture.entry", Usage: "Template of a captured entry, where {text}, {time} and {date} are replaced"},
+	{Name: "capture-time-format", Key: "capture.time_format", Usage: "Go time layout of {time} in captured entries"},
+	{Name: "capture-heading", Key: "capture.heading", Usage: "Go time layout of the date heading captured entries go under, e.g. \"### Monday 2 January\" (empty for none)"},
+	{Name: "publish-field", Key: "publish.field", Usage: "Frontmatter property publish_note sets to true and unpublish_note to false"},
+	{Name: "publish-date-field", Key: "publish.date_field", Usage: "Frontmatter property publish_note sets to the time a note is marked (empty for none)"},
+	{Name: "scratch-dir", Key: "scratch.dir", Usage: "Folder keeping scratch notes across restarts (default: in memory, cleared when the server stops)"},
+	{Name: "scratch-max-notes", Key: "scratch.max_notes", Usage: "Maximum number of scratch notes kept at a time"},
+	{Name: "scratch-max-kib", Key: "scratch.max_kib", Usage: "Maximum content of all scratch notes together in KiB"},
+	{Name: "shutdown-timeout", Key: "server.shutdown_timeout", Usage: "How long in-flight tool calls may run after SIGINT or SIGTERM"},
+	{Name: "lint-rules", Key: "lint.enable", comma: true, Usage: "Comma-separated lint rules lint_note and lint_vault apply, e.g. single-h1,wiki-links (default all)"},
+	{Name: "lint-disable", Key: "lint.disable", comma: true, Usage: "Comma-separated lint rules not to apply, e.g. no-trailing-whitespace"},
+	{Name: "default-note-type", Key: "types.default", Usage: "Type of notes no rule under types in the config file matches (default \"note\")"},
+	{Name: "search-timeout", Key: "server.search_timeout", Usage: "How long a search may run before returning the notes found so far (0 for no limit)"},
+	{Name: "tool-timeout", Key: "server.tool_timeout", Usage: "How long a tool call may run before it fails; tools that walk the whole vault get at least 10m (0 for no limit)"},
+	{Name: "max-response-bytes", Key: "server.max_response_bytes", Usage: fmt.Sprintf("Maximum size of a tool response in bytes, at least %d; longer lists and notes are cut with a notice (0 for unlimited)", tools.MinResponseBytes)},
+	{Name: "ignore-roots", Key: "server.ignore_roots", Usage: "Serve the whole vault even when the client's MCP roots cover only part of it"},
+	{Name: "metrics-addr", Key: "metrics.addr", Usage: "Serve metrics in the Prometheus text format at http://ADDR/metrics, e.g. 127.0.0.1:9464"},
+	{Name: "metrics", Key: "metrics.enabled", Usage: "Record metrics and report them in server_info (implied by --metrics-addr)"},
+	{Name: "export-dir", Key: "server.export_dir", Usage: "Folder outside the vault where export_vault may write archives (default: archives are only returned in results)"},
+	{Name: "json", Key: "json", Usage: "Print the output of the index, stats and verify commands as JSON"},
+}
+
+// BindFlags defines the Flags on fs, each setting its key in c. Values
+// already in c are the defaults shown by the usage message; a list flag
+// given on the command line replaces the list rather than adding to it.
+func (c *Config) BindFlags(fs *flag.FlagSet) {
+	for _, f := range Flags {
+		switch p := field(c, f.Key).Addr().Interface().(type) {
+		case *bool:
+			fs.BoolVar(p, f.Name, *p, f.Usage)
+		case *string:
+			fs.StringVar(p, f.Name, *p, f.Usage)
+		case *int:
+			fs.IntVar(p, f.Name, *p, f.Usage)
+		case *int64:
+			fs.Int64Var(p, f.Name, *p, f.Usage)
+		case *float64:
+			fs.Float64Var(p, f.Name, *p, f.Usage)
+		case *time.Duration:
+			fs.DurationVar(p, f.Name, *p, f.Usage)
+		case *[]string:
+			fs.Var(&listFlag{list: p, comma: f.comma}, f.Name, f.Usage)
+		default:
+			panic(fmt.Sprintf("config: flag --%s has an unsupported type %T", f.Name, p))
+		}
+	}
+}
+
+// listFlag sets a list setting from a comma-separated or repeated flag
+type listFlag struct {
+	list  *[]string
+	comma bool
+	set   bool // Given on the command line, so later values add to it
+}
+
+func (l *listFlag) String() string {
+	if l.list == nil {
+		return ""
+	}
+	return strings.Join(*l.list, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	if !l.set {
+		*l.list = nil
+		l.set = true
+	}
+	if !l.comma {
+		*l.list = append(*l.list, value)
+		return nil
+	}
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l.list = append(*l.list, item)
+		}
+	}
+	return nil
+}
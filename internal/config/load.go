@@ -0,0 +1,164 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// pathKeys are the settings holding file paths, which a config file gives
+// relative to its own directory
+var pathKeys = []string{"vault", "log.file", "audit.file", "frontmatter.config", "scratch.dir", "server.export_dir"}
+
+// decoder fills a Config from the nodes of a YAML document
+type decoder struct {
+	warnings []string        // Unknown keys
+	set      map[string]bool // Keys present in the document
+}
+
+// Load reads the YAML config file over c, so settings missing from the file
+// keep their values. Unknown keys are returned as warnings; a value of the
+// wrong type fails with its key path. Relative paths in the file are taken
+// from the file's directory.
+func Load(file string, c *Config) ([]string, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil // Empty file
+	}
+
+	d := decoder{set: make(map[string]bool)}
+	if err := d.decode(doc.Content[0], reflect.ValueOf(c).Elem(), ""); err != nil {
+		return d.warnings, fmt.Errorf("%s: %w", file, err)
+	}
+
+	dir := filepath.Dir(file)
+	for _, key := range pathKeys {
+		if path := field(c, key).Addr().Interface().(*string); d.set[key] && *path != "" && !filepath.IsAbs(*path) {
+			*path = filepath.Join(dir, *path)
+		}
+	}
+	return d.warnings, nil
+}
+
+// decode sets v from node, recursing into the sections of the config
+func (d *decoder) decode(node *yaml.Node, v reflect.Value, path string) error {
+	if v.Kind() != reflect.Struct {
+		d.set[path] = true
+		if err := node.Decode(v.Addr().Interface()); err != nil {
+			return fmt.Errorf("%s (line %d): expected %s, got %s", path, node.Line, describeType(v.Type()), describeNode(node))
+		}
+		return nil
+	}
+
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return nil // An empty section
+	}
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s (line %d): expected a section of settings, got %s", label(path), node.Line, describeNode(node))
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		keyPath := key.Value
+		if path != "" {
+			keyPath = path + "." + key.Value
+		}
+		f, ok := fieldByKey(v, key.Value)
+		if !ok {
+			d.warnings = append(d.warnings, fmt.Sprintf("unknown config key %s (line %d)", keyPath, key.Line))
+			continue
+		}
+		if err := d.decode(value, f, keyPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldByKey returns the field of struct v whose yaml key is key
+func fieldByKey(v reflect.Value, key string) (reflect.Value, bool) {
+	for i := range v.NumField() {
+		if v.Type().Field(i).Tag.Get("yaml") == key {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// field returns the setting of c at a dotted key path such as
+// "cache.size_mib". It panics on unknown keys, which are programming
+// errors.
+func field(c *Config, key string) reflect.Value {
+	v := reflect.ValueOf(c).Elem()
+	for part := range strings.SplitSeq(key, ".") {
+		f, ok := fieldByKey(v, part)
+		if !ok {
+			panic(fmt.Sprintf("config: unknown key %q", key))
+		}
+		v = f
+	}
+	return v
+}
+
+// label names a section in errors
+func label(path string) string {
+	if path == "" {
+		return "config"
+	}
+	return path
+}
+
+// describeType names the values a setting of type t takes
+func describeType(t reflect.Type) string {
+	switch {
+	case t == reflect.TypeFor[time.Duration]():
+		return "a duration such as 30s or 5m"
+	case t.Kind() == reflect.Bool:
+		return "true or false"
+	case t.Kind() == reflect.Int || t.Kind() == reflect.Int64:
+		return "a whole number"
+	case t.Kind() == reflect.Float64:
+		return "a number"
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String:
+		return "a list of strings"
+	case t.Kind() == reflect.Slice:
+		return "a list of sections"
+	case t.Kind() == reflect.Map:
+		return "a section of settings"
+	default:
+		return "a string"
+	}
+}
+
+// describeNode names the value given in the file
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a section"
+	case yaml.SequenceNode:
+		return "a list"
+	default:
+		return fmt.Sprintf("%q", node.Value)
+	}
+}
+
+// Find returns the config file of the vault at vaultPath, DefaultFile in
+// it, or "" when it has none
+func Find(vaultPath string) string {
+	file := filepath.Join(vaultPath, DefaultFile)
+	if stat, err := os.Stat(file); err != nil || stat.IsDir() {
+		return ""
+	}
+	return file
+}
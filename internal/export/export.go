@@ -0,0 +1,105 @@
+// Package export converts note markdown into other formats.
+// Rendering uses goldmark with GitHub Flavored Markdown and Obsidian
+// wikilinks, so code blocks, tables and links keep their structure.
+package export
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// Format is an export output format
+type Format string
+
+// Supported export formats
+const (
+	FormatMarkdown Format = "markdown" // Content unchanged
+	FormatHTML     Format = "html"     // Rendered HTML fragment
+	FormatPlain    Format = "plain"    // Text with markdown syntax removed
+)
+
+// ErrUnknownFormat indicates an unsupported export format
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// Options controls how a note is converted
+type Options struct {
+	Format Format
+	// IncludeFrontmatter keeps the YAML frontmatter: as an HTML comment
+	// for html and as a raw block for plain. Markdown is always unchanged.
+	IncludeFrontmatter bool
+}
+
+// markdown is the shared renderer; goldmark instances are safe for
+// concurrent use
+var markdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM, wikiLinks{}),
+)
+
+// ParseFormat validates a format name
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case FormatMarkdown, FormatHTML, FormatPlain:
+		return f, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownFormat, name)
+	}
+}
+
+// Convert renders note content in the requested format
+// A panic inside the renderer is returned as an error so a single bad
+// note cannot take down a folder export.
+func Convert(content string, opts Options) (out string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("rendering failed: %v", r)
+		}
+	}()
+
+	if opts.Format == FormatMarkdown {
+		return content, nil
+	}
+
+	frontmatter, body, hasFrontmatter := vault.SplitFrontmatter(content)
+	source := []byte(body)
+
+	switch opts.Format {
+	case FormatHTML:
+		var buf bytes.Buffer
+		if opts.IncludeFrontmatter && hasFrontmatter {
+			buf.WriteString("<!--\n" + commentText(frontmatter) + "-->\n")
+		}
+		if err := markdown.Convert(source, &buf); err != nil {
+			return "", fmt.Errorf("rendering failed: %w", err)
+		}
+		return buf.String(), nil
+
+	case FormatPlain:
+		doc := markdown.Parser().Parse(text.NewReader(source))
+		plain := renderPlain(doc, source)
+		if opts.IncludeFrontmatter && hasFrontmatter {
+			plain = frontmatter + "\n" + plain
+		}
+		return plain, nil
+
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownFormat, opts.Format)
+	}
+}
+
+// commentText makes text safe inside an HTML comment, where "--" may not
+// appear. A single pass leaves "---" as "- --", so dashes are split until
+// no pair is left.
+func commentText(text string) string {
+	for strings.Contains(text, "--") {
+		text = strings.ReplaceAll(text, "--", "- -")
+	}
+	return text
+}
@@ -0,0 +1,108 @@
+package export
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const sample = "---\ntitle: Sample\n---\n" +
+	"# Heading\n\n" +
+	"See [[Other Note|the other]] and [[Note#Section]] ![[diagram.png]] [site](https://example.com).\n\n" +
+	"- one\n- [x] two\n  - nested\n\n" +
+	"```go\nfunc main() {\n\t// [[not a link]]\n}\n```\n"
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        Options
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:     "markdown passthrough",
+			opts:     Options{Format: FormatMarkdown},
+			contains: []string{sample},
+		},
+		{
+			name: "html",
+			opts: Options{Format: FormatHTML},
+			contains: []string{
+				"<h1>Heading</h1>",
+				`<a href="Other%20Note.md" class="wikilink">the other</a>`,
+				`<a href="Note.md#Section" class="wikilink">Note &gt; Section</a>`,
+				`<img src="diagram.png" alt="diagram.png">`,
+				`<a href="https://example.com">site</a>`,
+				"// [[not a link]]",
+			},
+			notContains: []string{"title: Sample", "<!--"},
+		},
+		{
+			name:     "html with frontmatter comment",
+			opts:     Options{Format: FormatHTML, IncludeFrontmatter: true},
+			contains: []string{"<!--\ntitle: Sample\n-->"},
+		},
+		{
+			name: "plain",
+			opts: Options{Format: FormatPlain},
+			contains: []string{
+				"Heading\n\nSee the other and Note > Section diagram.png site.",
+				"- one\n- [x] two\n  - nested",
+				"func main() {\n\t// [[not a link]]\n}",
+			},
+			notContains: []string{"#", "[[Other", "](", "title: Sample"},
+		},
+		{
+			name:     "plain with frontmatter",
+			opts:     Options{Format: FormatPlain, IncludeFrontmatter: true},
+			contains: []string{"title: Sample\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Convert(sample, tt.opts)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(out, want) {
+					t.Errorf("Output missing %q:\n%s", want, out)
+				}
+			}
+			for _, unwanted := range tt.notContains {
+				if strings.Contains(out, unwanted) {
+					t.Errorf("Output contains %q:\n%s", unwanted, out)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertFrontmatterComment(t *testing.T) {
+	// No dash run may close the comment early
+	content := "---\nnote: a--->b, c-->d, e----f\n---\nBody\n"
+	out, err := Convert(content, Options{Format: FormatHTML, IncludeFrontmatter: true})
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	comment, rest, ok := strings.Cut(strings.TrimPrefix(out, "<!--"), "-->")
+	if !ok || strings.Contains(comment, "--") {
+		t.Fatalf("Convert() = %q, want the frontmatter in one comment without \"--\"", out)
+	}
+	if strings.Contains(rest, "note:") || !strings.Contains(rest, "<p>Body</p>") {
+		t.Errorf("Convert() = %q, want only the body after the comment", out)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, name := range []string{"markdown", "HTML", "plain"} {
+		if _, err := ParseFormat(name); err != nil {
+			t.Errorf("ParseFormat(%q) error = %v", name, err)
+		}
+	}
+
+	if _, err := ParseFormat("pdf"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Expected ErrUnknownFormat, got %v", err)
+	}
+}
@@ -0,0 +1,135 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+)
+
+// renderPlain renders a parsed document as text without markdown syntax
+// Blocks are separated by blank lines, list markers are kept, and code
+// blocks are emitted verbatim.
+func renderPlain(doc ast.Node, source []byte) string {
+	out := plainChildren(doc, source, "\n\n")
+	if out == "" {
+		return ""
+	}
+	return out + "\n"
+}
+
+// plainChildren renders the child blocks of n joined by sep
+func plainChildren(n ast.Node, source []byte, sep string) string {
+	var parts []string
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		if s := plainBlock(child, source); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+// plainBlock renders a single block node
+func plainBlock(n ast.Node, source []byte) string {
+	switch n := n.(type) {
+	case *ast.FencedCodeBlock, *ast.CodeBlock:
+		var b strings.Builder
+		lines := n.Lines()
+		for i := range lines.Len() {
+			segment := lines.At(i)
+			b.Write(segment.Value(source))
+		}
+		return strings.TrimRight(b.String(), "\n")
+
+	case *ast.ThematicBreak, *ast.HTMLBlock:
+		return ""
+
+	case *ast.Paragraph, *ast.Heading, *ast.TextBlock:
+		return plainInline(n, source)
+
+	case *ast.List:
+		sep := "\n"
+		if !n.IsTight {
+			sep = "\n\n"
+		}
+
+		var items []string
+		number := n.Start
+		for item := n.FirstChild(); item != nil; item = item.NextSibling() {
+			marker := "- "
+			if n.IsOrdered() {
+				marker = fmt.Sprintf("%d. ", number)
+				number++
+			}
+			body := plainChildren(item, source, sep)
+			items = append(items, marker+indent(body, len(marker)))
+		}
+		return strings.Join(items, sep)
+
+	case *east.Table:
+		var rows []string
+		for row := n.FirstChild(); row != nil; row = row.NextSibling() {
+			var cells []string
+			for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+				cells = append(cells, plainInline(cell, source))
+			}
+			rows = append(rows, strings.Join(cells, " | "))
+		}
+		return strings.Join(rows, "\n")
+
+	default:
+		// Documents, blockquotes and other containers
+		return plainChildren(n, source, "\n\n")
+	}
+}
+
+// plainInline renders the inline content of n as text
+func plainInline(n ast.Node, source []byte) string {
+	var b strings.Builder
+
+	_ = ast.Walk(n, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch node := node.(type) {
+		case *ast.Text:
+			b.Write(node.Segment.Value(source))
+			if node.SoftLineBreak() || node.HardLineBreak() {
+				b.WriteByte('\n')
+			}
+		case *ast.String:
+			b.Write(node.Value)
+		case *ast.AutoLink:
+			b.Write(node.Label(source))
+			return ast.WalkSkipChildren, nil
+		case *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		case *wikiLink:
+			b.WriteString(node.Label())
+			return ast.WalkSkipChildren, nil
+		case *east.TaskCheckBox:
+			if node.IsChecked {
+				b.WriteString("[x] ")
+			} else {
+				b.WriteString("[ ] ")
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+
+	return strings.TrimSpace(b.String())
+}
+
+// indent prefixes every line after the first with width spaces
+func indent(s string, width int) string {
+	pad := strings.Repeat(" ", width)
+	lines := strings.Split(s, "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = pad + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,167 @@
+package export
+
+import (
+	"bytes"
+	"path"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// kindWikiLink is the node kind of Obsidian wikilinks
+var kindWikiLink = ast.NewNodeKind("WikiLink")
+
+// wikiLink is an Obsidian [[Target#Heading|Display]] or ![[Embed]] reference
+type wikiLink struct {
+	ast.BaseInline
+	Target  string // Note or file name as written
+	Anchor  string // Heading or ^block after '#', if any
+	Display string // Text after '|', if any
+	Embed   bool   // Written as ![[...]]
+}
+
+// Kind implements ast.Node
+func (n *wikiLink) Kind() ast.NodeKind {
+	return kindWikiLink
+}
+
+// Dump implements ast.Node
+func (n *wikiLink) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Target":  n.Target,
+		"Anchor":  n.Anchor,
+		"Display": n.Display,
+	}, nil)
+}
+
+// Label returns the text Obsidian shows for the link: the display text if
+// given, otherwise the target with any heading as "Target > Heading"
+func (n *wikiLink) Label() string {
+	if n.Display != "" {
+		return n.Display
+	}
+	anchor := strings.TrimPrefix(n.Anchor, "^")
+	switch {
+	case n.Target == "":
+		return anchor
+	case anchor == "":
+		return n.Target
+	default:
+		return n.Target + " > " + anchor
+	}
+}
+
+// Href returns a relative link to the target note or file
+func (n *wikiLink) Href() string {
+	href := n.Target
+	if href != "" && path.Ext(href) == "" {
+		href += ".md"
+	}
+	if n.Anchor != "" {
+		href += "#" + n.Anchor
+	}
+	return href
+}
+
+// wikiLinkParser parses [[...]] and ![[...]] before the standard link parser
+type wikiLinkParser struct{}
+
+func (wikiLinkParser) Trigger() []byte {
+	return []byte{'!', '['}
+}
+
+func (wikiLinkParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+
+	embed := false
+	rest := line
+	if bytes.HasPrefix(rest, []byte("!")) {
+		embed = true
+		rest = rest[1:]
+	}
+	if !bytes.HasPrefix(rest, []byte("[[")) {
+		return nil
+	}
+
+	end := bytes.Index(rest[2:], []byte("]]"))
+	if end < 0 {
+		return nil
+	}
+	inner := string(rest[2 : 2+end])
+	if strings.ContainsAny(inner, "[]\n") {
+		return nil
+	}
+
+	link := &wikiLink{Embed: embed}
+	target, display, _ := strings.Cut(inner, "|")
+	link.Display = strings.TrimSpace(display)
+	target, link.Anchor, _ = strings.Cut(strings.TrimSpace(target), "#")
+	link.Target = strings.TrimSpace(target)
+
+	consumed := 2 + end + 2
+	if embed {
+		consumed++
+	}
+	block.Advance(consumed)
+
+	return link
+}
+
+// wikiLinkHTMLRenderer renders wikiLink nodes as anchors, or images for
+// embedded image files
+type wikiLinkHTMLRenderer struct{}
+
+func (wikiLinkHTMLRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindWikiLink, renderWikiLinkHTML)
+}
+
+func renderWikiLinkHTML(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*wikiLink)
+	href := util.EscapeHTML(util.URLEscape([]byte(n.Href()), true))
+	label := util.EscapeHTML([]byte(n.Label()))
+
+	if n.Embed && isImage(n.Target) {
+		_, _ = w.WriteString(`<img src="`)
+		_, _ = w.Write(href)
+		_, _ = w.WriteString(`" alt="`)
+		_, _ = w.Write(label)
+		_, _ = w.WriteString(`">`)
+		return ast.WalkSkipChildren, nil
+	}
+
+	_, _ = w.WriteString(`<a href="`)
+	_, _ = w.Write(href)
+	_, _ = w.WriteString(`" class="wikilink">`)
+	_, _ = w.Write(label)
+	_, _ = w.WriteString(`</a>`)
+	return ast.WalkSkipChildren, nil
+}
+
+// isImage reports whether name has a common image extension
+func isImage(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".svg", ".webp", ".bmp":
+		return true
+	}
+	return false
+}
+
+// wikiLinks is a goldmark extension adding Obsidian wikilinks
+type wikiLinks struct{}
+
+func (wikiLinks) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithInlineParsers(
+		util.Prioritized(wikiLinkParser{}, 199), // Before the link parser
+	))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(wikiLinkHTMLRenderer{}, 500),
+	))
+}
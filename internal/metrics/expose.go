@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// contentType is the media type of the Prometheus text exposition format
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// labelEscaper escapes label values as the exposition format requires
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WriteText writes samples, sorted by name as Snapshot returns them, in
+// the Prometheus text exposition format. Summaries are written as their
+// _count and _sum series.
+func WriteText(w io.Writer, samples []Sample) error {
+	bw := bufio.NewWriter(w)
+	for i, sample := range samples {
+		d := describe(sample.Name)
+		if i == 0 || samples[i-1].Name != sample.Name {
+			if d.help != "" {
+				fmt.Fprintf(bw, "# HELP %s %s\n", sample.Name, d.help)
+			}
+			fmt.Fprintf(bw, "# TYPE %s %s\n", sample.Name, d.kind)
+		}
+
+		labels := ""
+		if sample.Label != "" {
+			labels = fmt.Sprintf("{%s=\"%s\"}", d.label, labelEscaper.Replace(sample.Label))
+		}
+		if d.kind == kindSummary {
+			fmt.Fprintf(bw, "%s_count%s %d\n", sample.Name, labels, sample.Value)
+			fmt.Fprintf(bw, "%s_sum%s %s\n", sample.Name, labels, strconv.FormatFloat(sample.Seconds, 'g', -1, 64))
+		} else {
+			fmt.Fprintf(bw, "%s%s %d\n", sample.Name, labels, sample.Value)
+		}
+	}
+	return bw.Flush()
+}
+
+// ServeHTTP serves the registry's metrics in the Prometheus text
+// exposition format.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+	if req.Method == http.MethodHead {
+		return
+	}
+	WriteText(w, r.Snapshot())
+}
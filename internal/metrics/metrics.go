@@ -0,0 +1,174 @@
+// Package metrics provides the counters the notes server records about its
+// cache, vault and tool calls, and renders them in the Prometheus text
+// exposition format.
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics records counters and durations. Every metric has at most one
+// label, whose key is fixed by the metric; its value is passed with each
+// call, empty for metrics without a label. Implementations must be safe
+// for concurrent use.
+type Metrics interface {
+	// Add increases the counter name by delta
+	Add(name, label string, delta uint64)
+
+	// Observe records one duration of name
+	Observe(name, label string, d time.Duration)
+
+	// Snapshot returns the current value of every metric recorded so far
+	Snapshot() []Sample
+}
+
+// Metric names
+const (
+	CacheHits      = "notes_cache_hits_total"
+	CacheMisses    = "notes_cache_misses_total"
+	CacheEvictions = "notes_cache_evictions_total"
+	WalkDuration   = "notes_vault_walk_duration_seconds"
+	FileReads      = "notes_vault_file_reads_total"
+	BytesRead      = "notes_vault_read_bytes_total"
+	ToolCalls      = "notes_tool_call_duration_seconds" // Label: tool
+	ToolErrors     = "notes_tool_errors_total"          // Label: code
+	ToolAborts     = "notes_tool_aborts_total"          // Label: reason
+)
+
+// kind is how a metric is exposed
+type kind string
+
+const (
+	kindCounter kind = "counter"
+	kindSummary kind = "summary" // A count and a sum of durations
+)
+
+// desc describes a metric for the exposition format
+type desc struct {
+	help  string
+	kind  kind
+	label string // Label key, empty for none
+}
+
+// descs describes every metric the server records
+var descs = map[string]desc{
+	CacheHits:      {"Note cache lookups served from memory.", kindCounter, ""},
+	CacheMisses:    {"Note cache lookups of absent or stale entries.", kindCounter, ""},
+	CacheEvictions: {"Note cache entries evicted to honor the size limit.", kindCounter, ""},
+	WalkDuration:   {"Time spent walking vault directories.", kindSummary, ""},
+	FileReads:      {"Notes read from disk.", kindCounter, ""},
+	BytesRead:      {"Bytes of notes read from disk.", kindCounter, ""},
+	ToolCalls:      {"Duration of tool calls by tool.", kindSummary, "tool"},
+	ToolErrors:     {"Tool calls that failed, by error code.", kindCounter, "code"},
+	ToolAborts:     {"Tool calls that panicked or timed out, by reason.", kindCounter, "reason"},
+}
+
+// describe returns the description of name; unknown metrics are exposed
+// as counters with a generic label key
+func describe(name string) desc {
+	if d, ok := descs[name]; ok {
+		return d
+	}
+	return desc{kind: kindCounter, label: "label"}
+}
+
+// Sample is the value of one metric and label at the time of a Snapshot
+type Sample struct {
+	Name    string  `json:"name"`
+	Label   string  `json:"label,omitempty"`   // Label value, under the metric's label key
+	Value   uint64  `json:"value"`             // Counter value, or number of observed durations
+	Seconds float64 `json:"seconds,omitempty"` // Sum of observed durations
+}
+
+// Nop is a Metrics that records nothing, for when metrics are disabled
+type Nop struct{}
+
+// Add does nothing
+func (Nop) Add(string, string, uint64) {}
+
+// Observe does nothing
+func (Nop) Observe(string, string, time.Duration) {}
+
+// Snapshot returns nil
+func (Nop) Snapshot() []Sample { return nil }
+
+// seriesKey identifies the counters of one metric and label value
+type seriesKey struct {
+	name  string
+	label string
+}
+
+// series holds the counters of one metric and label value
+type series struct {
+	count atomic.Uint64
+	sum   atomic.Uint64 // Nanoseconds observed
+}
+
+// Registry is the default Metrics, keeping atomic counters that are
+// created on first use. Recording takes a read lock and an atomic add.
+type Registry struct {
+	mu     sync.RWMutex
+	series map[seriesKey]*series
+}
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{series: make(map[seriesKey]*series)}
+}
+
+// get returns the series of name and label, creating it if needed
+func (r *Registry) get(name, label string) *series {
+	key := seriesKey{name, label}
+	r.mu.RLock()
+	s := r.series[key]
+	r.mu.RUnlock()
+	if s != nil {
+		return s
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s = r.series[key]; s == nil {
+		s = &series{}
+		r.series[key] = s
+	}
+	return s
+}
+
+// Add increases the counter name by delta
+func (r *Registry) Add(name, label string, delta uint64) {
+	r.get(name, label).count.Add(delta)
+}
+
+// Observe records one duration of name
+func (r *Registry) Observe(name, label string, d time.Duration) {
+	s := r.get(name, label)
+	s.count.Add(1)
+	s.sum.Add(uint64(max(d, 0)))
+}
+
+// Snapshot returns the current value of every metric recorded so far,
+// sorted by name and label
+func (r *Registry) Snapshot() []Sample {
+	r.mu.RLock()
+	samples := make([]Sample, 0, len(r.series))
+	for key, s := range r.series {
+		sample := Sample{Name: key.name, Label: key.label, Value: s.count.Load()}
+		if describe(key.name).kind == kindSummary {
+			sample.Seconds = time.Duration(s.sum.Load()).Seconds()
+		}
+		samples = append(samples, sample)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(samples, func(i, j int) bool {
+		if samples[i].Name != samples[j].Name {
+			return samples[i].Name < samples[j].Name
+		}
+		return samples[i].Label < samples[j].Label
+	})
+	return samples
+}
@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	r.Add(CacheHits, "", 2)
+	r.Add(CacheHits, "", 1)
+	r.Observe(ToolCalls, "read_note", 1500*time.Millisecond)
+	r.Observe(ToolCalls, "read_note", 500*time.Millisecond)
+	r.Observe(ToolCalls, "list_notes", time.Second)
+	r.Add(ToolErrors, "NOT_FOUND", 1)
+
+	want := []Sample{
+		{Name: CacheHits, Value: 3},
+		{Name: ToolCalls, Label: "list_notes", Value: 1, Seconds: 1},
+		{Name: ToolCalls, Label: "read_note", Value: 2, Seconds: 2},
+		{Name: ToolErrors, Label: "NOT_FOUND", Value: 1},
+	}
+	if got := r.Snapshot(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Snapshot = %+v, want %+v", got, want)
+	}
+}
+
+func TestRegistryConcurrency(t *testing.T) {
+	r := NewRegistry()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				r.Add(FileReads, "", 1)
+				r.Observe(WalkDuration, "", time.Millisecond)
+				if j%100 == 0 {
+					r.Snapshot()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	want := []Sample{
+		{Name: FileReads, Value: 8000},
+		{Name: WalkDuration, Value: 8000, Seconds: 8},
+	}
+	if got := r.Snapshot(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Snapshot = %+v, want %+v", got, want)
+	}
+}
+
+func TestNop(t *testing.T) {
+	var m Metrics = Nop{}
+	m.Add(CacheHits, "", 1)
+	m.Observe(WalkDuration, "", time.Second)
+	if got := m.Snapshot(); got != nil {
+		t.Errorf("Snapshot = %+v, want nil", got)
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	samples := []Sample{
+		{Name: CacheHits, Value: 3},
+		{Name: ToolCalls, Label: "list_notes", Value: 1, Seconds: 0.25},
+		{Name: ToolCalls, Label: "read_note", Value: 2, Seconds: 2},
+		{Name: ToolErrors, Label: "a\"b\\c\nd", Value: 1},
+	}
+
+	var b strings.Builder
+	if err := WriteText(&b, samples); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+
+	want := `# HELP notes_cache_hits_total Note cache lookups served from memory.
+# TYPE notes_cache_hits_total counter
+notes_cache_hits_total 3
+# HELP notes_tool_call_duration_seconds Duration of tool calls by tool.
+# TYPE notes_tool_call_duration_seconds summary
+notes_tool_call_duration_seconds_count{tool="list_notes"} 1
+notes_tool_call_duration_seconds_sum{tool="list_notes"} 0.25
+notes_tool_call_duration_seconds_count{tool="read_note"} 2
+notes_tool_call_duration_seconds_sum{tool="read_note"} 2
+# HELP notes_tool_errors_total Tool calls that failed, by error code.
+# TYPE notes_tool_errors_total counter
+notes_tool_errors_total{code="a\"b\\c\nd"} 1
+`
+	if got := b.String(); got != want {
+		t.Errorf("WriteText =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRegistryServeHTTP(t *testing.T) {
+	r := NewRegistry()
+	r.Add(BytesRead, "", 42)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Content-Type = %q, want text/plain", ct)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, "notes_vault_read_bytes_total 42\n") {
+		t.Errorf("Body does not contain the counter:\n%s", body)
+	}
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// methodCancelled is the notification a client sends to cancel a request
+const methodCancelled = "notifications/cancelled"
+
+// callKey identifies a tool call by its session and JSON-RPC request id
+type callKey struct {
+	session string
+	id      string
+}
+
+// newCallKey returns the key of the request id in the session of ctx
+func newCallKey(ctx context.Context, id any) callKey {
+	key := callKey{id: fmt.Sprint(id)}
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		key.session = session.SessionID()
+	}
+	return key
+}
+
+// inflightCalls cancels the context of a tool call when the client sends
+// notifications/cancelled for it. mcp-go neither handles the notification
+// nor passes the request id to tool handlers, so a hook notes the id of
+// each call under the call's context, and the outermost middleware, which
+// is given that same context, makes it cancellable.
+type inflightCalls struct {
+	mu      sync.Mutex
+	pending map[context.Context]callKey    // Calls announced by the hook, not started yet
+	running map[callKey]context.CancelFunc // Calls whose handlers are running
+}
+
+// newInflightCalls creates an empty inflightCalls
+func newInflightCalls() *inflightCalls {
+	return &inflightCalls{
+		pending: make(map[context.Context]callKey),
+		running: make(map[callKey]context.CancelFunc),
+	}
+}
+
+// hooks returns the server hooks that announce tool calls
+func (c *inflightCalls) hooks() *server.Hooks {
+	hooks := &server.Hooks{}
+	hooks.AddBeforeCallTool(func(ctx context.Context, id any, _ *mcp.CallToolRequest) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.pending[ctx] = newCallKey(ctx, id)
+	})
+	hooks.AddOnError(func(ctx context.Context, _ any, method mcp.MCPMethod, _ any, _ error) {
+		if method != mcp.MethodToolsCall {
+			return
+		}
+		// Calls of unknown tools fail before any middleware runs
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.pending, ctx)
+	})
+	return hooks
+}
+
+// middleware returns the tool handler middleware that makes announced
+// calls cancellable; it must be the outermost one
+func (c *inflightCalls) middleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			c.mu.Lock()
+			key, ok := c.pending[ctx]
+			delete(c.pending, ctx)
+			c.mu.Unlock()
+			if !ok {
+				return next(ctx, request)
+			}
+
+			callCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			c.mu.Lock()
+			c.running[key] = cancel
+			c.mu.Unlock()
+			defer func() {
+				c.mu.Lock()
+				defer c.mu.Unlock()
+				delete(c.running, key)
+			}()
+
+			return next(callCtx, request)
+		}
+	}
+}
+
+// cancel is the handler of notifications/cancelled
+func (c *inflightCalls) cancel(ctx context.Context, notification mcp.JSONRPCNotification) {
+	id, ok := notification.Params.AdditionalFields["requestId"]
+	if !ok {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cancel, ok := c.running[newCallKey(ctx, id)]; ok {
+		cancel()
+	}
+}
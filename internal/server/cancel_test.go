@@ -0,0 +1,94 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// slowVault is a vault whose verification walks an endless synthetic
+// vault, reporting progress until it is cancelled
+type slowVault struct {
+	vault.Vault
+	cancelled chan struct{}
+}
+
+func (v *slowVault) Verify(ctx context.Context, opts vault.VerifyOptions) (vault.VerifyReport, error) {
+	for done := 0; ; done++ {
+		vault.ReportProgress(ctx, done, 0)
+		select {
+		case <-ctx.Done():
+			close(v.cancelled)
+			return vault.VerifyReport{}, ctx.Err()
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+}
+
+func TestCancelToolCall(t *testing.T) {
+	v := &slowVault{cancelled: make(chan struct{})}
+	stdio := server.NewStdioServer(NewServer(v, slog.New(slog.DiscardHandler), Options{}))
+
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = stdio.Listen(ctx, stdinReader, stdoutWriter)
+		stdoutWriter.Close()
+	}()
+	defer func() {
+		stdinWriter.Close()
+		cancel()
+		<-done
+	}()
+
+	c := &rootsClient{t: t, stdin: stdinWriter, scanner: bufio.NewScanner(stdoutReader)}
+	c.send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`)
+	c.next()
+	c.send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+
+	c.send(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"verify_vault","arguments":{},"_meta":{"progressToken":"scan"}}}`)
+	var last float64
+	for range 2 {
+		msg := c.next()
+		params, _ := msg["params"].(map[string]any)
+		if msg["method"] != "notifications/progress" || params["progressToken"] != "scan" {
+			t.Fatalf("Expected a progress notification, got %v", msg)
+		}
+		progress, _ := params["progress"].(float64)
+		if progress <= last && last > 0 {
+			t.Errorf("Progress went from %v to %v, want it to grow", last, progress)
+		}
+		last = progress
+	}
+
+	c.send(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":2,"reason":"user"}}`)
+	select {
+	case <-v.cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("The call's context was not cancelled")
+	}
+
+	// Skip progress sent before the cancellation arrived
+	for {
+		msg := c.next()
+		if msg["method"] == "notifications/progress" {
+			continue
+		}
+		if msg["id"] != float64(2) || !strings.Contains(c.scanner.Text(), "CANCELLED") {
+			t.Errorf("Result of the cancelled call = %s, want CANCELLED", c.scanner.Text())
+		}
+		break
+	}
+}
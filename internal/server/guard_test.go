@@ -0,0 +1,82 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// brokenVault is a vault whose task search panics and whose stats never
+// return, ignoring cancellation
+type brokenVault struct {
+	vault.Vault
+	release chan struct{}
+}
+
+func (v *brokenVault) FindTasks(ctx context.Context, opts vault.TaskOptions) ([]vault.NoteTasks, error) {
+	panic("index out of range")
+}
+
+func (v *brokenVault) Stats(ctx context.Context, subpath string) (vault.VaultStats, error) {
+	<-v.release
+	return vault.VaultStats{}, nil
+}
+
+func (v *brokenVault) Info(ctx context.Context) (vault.VaultInfo, error) {
+	return vault.VaultInfo{}, nil
+}
+
+func TestGuardedToolCalls(t *testing.T) {
+	v := &brokenVault{release: make(chan struct{})}
+	defer close(v.release)
+	stdio := server.NewStdioServer(NewServer(v, slog.New(slog.DiscardHandler), Options{ToolTimeout: 50 * time.Millisecond}))
+
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = stdio.Listen(ctx, stdinReader, stdoutWriter)
+		stdoutWriter.Close()
+	}()
+	defer func() {
+		stdinWriter.Close()
+		cancel()
+		<-done
+	}()
+
+	c := &rootsClient{t: t, stdin: stdinWriter, scanner: bufio.NewScanner(stdoutReader)}
+	c.send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`)
+	c.next()
+	c.send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+
+	calls := []struct {
+		id   int
+		tool string
+		want string
+	}{
+		{2, "find_tasks", "INTERNAL_ERROR"},
+		{3, "vault_stats", "did not finish within 50ms"},
+		{4, "server_info", `\"status\": \"ok\"`},
+	}
+	for _, call := range calls {
+		c.send(`{"jsonrpc":"2.0","id":%d,"method":"tools/call","params":{"name":"%s","arguments":{}}}`, call.id, call.tool)
+		msg := c.next()
+		if msg["id"] != float64(call.id) || !strings.Contains(c.scanner.Text(), call.want) {
+			t.Errorf("Result of %s = %s, want %s", call.tool, c.scanner.Text(), call.want)
+		}
+		if call.tool == "find_tasks" && strings.Contains(c.scanner.Text(), "index out of range") {
+			t.Errorf("Result of %s = %s, want no panic detail", call.tool, c.scanner.Text())
+		}
+	}
+}
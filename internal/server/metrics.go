@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/kratos/mcp-notes/internal/metrics"
+)
+
+// metricsPath is where ServeMetrics exposes the metrics
+const metricsPath = "/metrics"
+
+// metricsShutdownTimeout bounds how long open scrapes may take once the
+// metrics server stops
+const metricsShutdownTimeout = 5 * time.Second
+
+// ServeMetrics serves registry in the Prometheus text format at /metrics
+// on addr until ctx is done. It returns the bound address as soon as it
+// listens, so a bad or busy address fails at startup; errors while
+// serving are logged.
+func ServeMetrics(ctx context.Context, addr string, registry *metrics.Registry, logger *slog.Logger) (net.Addr, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("listening for metrics: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath, registry)
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("metrics server failed", "error", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), metricsShutdownTimeout)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info("serving metrics", "addr", listener.Addr().String(), "path", metricsPath)
+	return listener.Addr(), nil
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kratos/mcp-notes/internal/metrics"
+)
+
+func TestServeMetrics(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	registry := metrics.NewRegistry()
+	registry.Add(metrics.CacheHits, "", 7)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	addr, err := ServeMetrics(ctx, "127.0.0.1:0", registry, logger)
+	if err != nil {
+		t.Fatalf("ServeMetrics failed: %v", err)
+	}
+
+	resp, err := http.Get("http://" + addr.String() + "/metrics")
+	if err != nil {
+		t.Fatalf("Scrape failed: %v", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("Failed to read scrape: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Status = %d, want 200", resp.StatusCode)
+	}
+	if !strings.Contains(string(body), "notes_cache_hits_total 7\n") {
+		t.Errorf("Scrape does not contain the counter:\n%s", body)
+	}
+
+	// A busy address fails at startup
+	if _, err := ServeMetrics(ctx, addr.String(), registry, logger); err == nil {
+		t.Error("Expected an error for an address in use")
+	}
+}
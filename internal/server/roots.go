@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/tools"
+)
+
+// methodInitialized is the notification a client sends once it has
+// finished initializing and can answer requests
+const methodInitialized = "notifications/initialized"
+
+// rootsTimeout bounds how long the server waits for the client's roots
+const rootsTimeout = 10 * time.Second
+
+// watchRoots keeps handlers scoped to the client's MCP roots: they are
+// requested when the client finishes initializing and again whenever it
+// reports a change. Clients without the roots capability stay unscoped.
+func watchRoots(srv *server.MCPServer, handlers *tools.Handlers, logger *slog.Logger) {
+	var mu sync.Mutex // Serializes updates so the last fetch applied is the newest
+
+	update := func(ctx context.Context, _ mcp.JSONRPCNotification) {
+		session, ok := server.ClientSessionFromContext(ctx).(server.SessionWithClientInfo)
+		if !ok || session.GetClientCapabilities().Roots == nil {
+			return
+		}
+
+		// Notification handlers run on the transport's read loop, which must
+		// stay free to deliver the client's answer
+		handlers.ExpectRoots()
+		go func() {
+			mu.Lock()
+			defer mu.Unlock()
+
+			ctx, cancel := context.WithTimeout(ctx, rootsTimeout)
+			defer cancel()
+			result, err := srv.RequestRoots(ctx, mcp.ListRootsRequest{})
+			if err != nil {
+				logger.Warn("listing client roots failed", "error", err)
+				handlers.CancelRoots()
+				return
+			}
+
+			uris := make([]string, len(result.Roots))
+			for i, root := range result.Roots {
+				uris[i] = root.URI
+			}
+			handlers.SetRoots(uris)
+
+			if folders, scoped := handlers.RootFolders(); scoped {
+				logger.Info("scoped to client roots", "roots", uris, "folders", folders)
+			} else {
+				logger.Info("client roots do not limit the vault", "roots", uris)
+			}
+		}()
+	}
+
+	srv.AddNotificationHandler(methodInitialized, update)
+	srv.AddNotificationHandler(mcp.MethodNotificationRootsListChanged, update)
+}
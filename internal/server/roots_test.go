@@ -0,0 +1,182 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// rootsClient drives a stdio server as a client declaring MCP roots
+type rootsClient struct {
+	t       *testing.T
+	stdin   io.Writer
+	scanner *bufio.Scanner
+}
+
+// send writes one JSON-RPC message
+func (c *rootsClient) send(format string, args ...any) {
+	c.t.Helper()
+	if _, err := fmt.Fprintf(c.stdin, format+"\n", args...); err != nil {
+		c.t.Fatalf("Failed to write to the server: %v", err)
+	}
+}
+
+// next reads the next JSON-RPC message from the server
+func (c *rootsClient) next() map[string]any {
+	c.t.Helper()
+	if !c.scanner.Scan() {
+		c.t.Fatalf("Server closed stdout (scan error: %v)", c.scanner.Err())
+	}
+	var msg map[string]any
+	if err := json.Unmarshal(c.scanner.Bytes(), &msg); err != nil {
+		c.t.Fatalf("Non JSON output on stdout: %q", c.scanner.Text())
+	}
+	return msg
+}
+
+// expectRootsRequest reads a roots/list request and returns its id
+func (c *rootsClient) expectRootsRequest() any {
+	c.t.Helper()
+	msg := c.next()
+	if msg["method"] != "roots/list" {
+		c.t.Fatalf("Expected a roots/list request, got %v", msg)
+	}
+	return msg["id"]
+}
+
+// answerRoots responds to the roots/list request id with dirs
+func (c *rootsClient) answerRoots(id any, dirs ...string) {
+	c.t.Helper()
+	roots := make([]map[string]string, len(dirs))
+	for i, dir := range dirs {
+		roots[i] = map[string]string{"uri": (&url.URL{Scheme: "file", Path: filepath.ToSlash(dir)}).String()}
+	}
+	result, _ := json.Marshal(map[string]any{"roots": roots})
+	c.send(`{"jsonrpc":"2.0","id":%v,"result":%s}`, id, result)
+}
+
+// callTool sends a tools/call and returns the text of its result
+func (c *rootsClient) callTool(id int, name string, args map[string]any) (string, bool) {
+	c.t.Helper()
+	c.sendCall(id, name, args)
+	return c.toolResult(id)
+}
+
+// sendCall sends a tools/call without reading its result
+func (c *rootsClient) sendCall(id int, name string, args map[string]any) {
+	c.t.Helper()
+	encoded, _ := json.Marshal(args)
+	c.send(`{"jsonrpc":"2.0","id":%d,"method":"tools/call","params":{"name":%q,"arguments":%s}}`, id, name, encoded)
+}
+
+// toolResult reads the result of the tools/call id
+func (c *rootsClient) toolResult(id int) (string, bool) {
+	c.t.Helper()
+	msg := c.next()
+	if msg["id"] != float64(id) {
+		c.t.Fatalf("Expected the result of call %d, got %v", id, msg)
+	}
+	var result struct {
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+		IsError bool `json:"isError"`
+	}
+	encoded, _ := json.Marshal(msg["result"])
+	if err := json.Unmarshal(encoded, &result); err != nil || len(result.Content) == 0 {
+		c.t.Fatalf("Malformed tool result: %v", msg)
+	}
+	return result.Content[0].Text, result.IsError
+}
+
+func TestRootsScopeToolCalls(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, note := range []string{"Work/plan.md", "Personal/diary.md"} {
+		fullPath := filepath.Join(tmpDir, note)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte("Note"), 0644); err != nil {
+			t.Fatalf("Failed to create note: %v", err)
+		}
+	}
+
+	logger := slog.New(slog.DiscardHandler)
+	v, err := vault.NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	stdio := server.NewStdioServer(NewServer(v, logger, Options{}))
+
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = stdio.Listen(ctx, stdinReader, stdoutWriter)
+		stdoutWriter.Close()
+	}()
+	defer func() {
+		stdinWriter.Close()
+		cancel()
+		<-done
+	}()
+
+	scanner := bufio.NewScanner(stdoutReader)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	c := &rootsClient{t: t, stdin: stdinWriter, scanner: scanner}
+
+	c.send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{"roots":{"listChanged":true}},"clientInfo":{"name":"test","version":"1.0"}}}`)
+	c.next()
+	c.send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	rootsID := c.expectRootsRequest()
+
+	// A call made before the roots arrive waits for them
+	c.sendCall(2, "list_notes", map[string]any{})
+	c.answerRoots(rootsID, filepath.Join(tmpDir, "Work"), "/elsewhere")
+	text, isError := c.toolResult(2)
+	if isError || !strings.Contains(text, "Work/plan.md") || strings.Contains(text, "Personal") {
+		t.Errorf("list_notes = %s, want only the notes under Work", text)
+	}
+
+	text, isError = c.callTool(3, "read_note", map[string]any{"path": "Personal/diary.md"})
+	if !isError || !strings.Contains(text, "OUTSIDE_ROOTS") {
+		t.Errorf("read_note outside the roots = %s, want OUTSIDE_ROOTS", text)
+	}
+	if _, isError = c.callTool(4, "read_note", map[string]any{"path": "Work/plan.md"}); isError {
+		t.Error("Expected a note under the roots to be readable")
+	}
+
+	// Changed roots apply to later calls
+	c.send(`{"jsonrpc":"2.0","method":"notifications/roots/list_changed"}`)
+	c.answerRoots(c.expectRootsRequest(), filepath.Join(tmpDir, "Personal"))
+	if text, isError = c.callTool(5, "read_note", map[string]any{"path": "Personal/diary.md"}); isError {
+		t.Errorf("read_note after the roots changed = %s", text)
+	}
+	if text, isError = c.callTool(6, "read_note", map[string]any{"path": "Work/plan.md"}); !isError || !strings.Contains(text, "OUTSIDE_ROOTS") {
+		t.Errorf("read_note outside the changed roots = %s, want OUTSIDE_ROOTS", text)
+	}
+
+	// Roots holding the whole vault lift the limit
+	c.send(`{"jsonrpc":"2.0","method":"notifications/roots/list_changed"}`)
+	c.answerRoots(c.expectRootsRequest(), filepath.Dir(tmpDir))
+	text, isError = c.callTool(7, "list_notes", map[string]any{})
+	if isError || !strings.Contains(text, "Work/plan.md") || !strings.Contains(text, "Personal/diary.md") {
+		t.Errorf("list_notes = %s, want every note", text)
+	}
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// DefaultGracePeriod is how long Run waits for in-flight tool calls by default.
+const DefaultGracePeriod = 10 * time.Second
+
+// ErrShutdownTimeout is returned by Run when in-flight tool calls do not
+// finish within the grace period.
+var ErrShutdownTimeout = errors.New("shutdown grace period exceeded")
+
+// runConfig holds the settings for Run.
+type runConfig struct {
+	gracePeriod time.Duration
+	logger      *slog.Logger
+	stdin       io.Reader
+	stdout      io.Writer
+}
+
+// RunOption configures Run.
+type RunOption func(*runConfig)
+
+// WithGracePeriod sets how long in-flight tool calls may run after shutdown
+// begins before their contexts are cancelled.
+func WithGracePeriod(d time.Duration) RunOption {
+	return func(c *runConfig) {
+		c.gracePeriod = d
+	}
+}
+
+// WithLogger sets the logger for shutdown progress and transport errors.
+func WithLogger(logger *slog.Logger) RunOption {
+	return func(c *runConfig) {
+		c.logger = logger
+	}
+}
+
+// withIO replaces the stdio streams; used by tests.
+func withIO(stdin io.Reader, stdout io.Writer) RunOption {
+	return func(c *runConfig) {
+		c.stdin = stdin
+		c.stdout = stdout
+	}
+}
+
+// Run serves srv over stdio until the client closes stdin or ctx is done.
+//
+// When ctx is cancelled Run stops reading new requests, lets tool calls that
+// were already received finish, and returns nil once they have. If they are
+// still running after the grace period their contexts are cancelled and
+// ErrShutdownTimeout is returned.
+//
+// Vault writes are synchronous, so once every handler has returned there is
+// nothing left to flush.
+func Run(ctx context.Context, srv *server.MCPServer, opts ...RunOption) error {
+	cfg := runConfig{
+		gracePeriod: DefaultGracePeriod,
+		logger:      slog.New(slog.DiscardHandler),
+		stdin:       os.Stdin,
+		stdout:      os.Stdout,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	stdio := server.NewStdioServer(srv)
+	stdio.SetErrorLogger(slog.NewLogLogger(cfg.logger.Handler(), slog.LevelError))
+
+	// Handlers run on a context that outlives ctx until the grace period ends
+	serveCtx, cancelServe := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelServe()
+
+	// Closing the pipe ends the input stream, so no new requests are read
+	// while queued and running tool calls complete
+	input, inputWriter := io.Pipe()
+	go func() {
+		_, err := io.Copy(inputWriter, cfg.stdin)
+		inputWriter.CloseWithError(err)
+	}()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- stdio.Listen(serveCtx, input, cfg.stdout)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+
+	cfg.logger.Info("shutting down", "grace_period", cfg.gracePeriod)
+	inputWriter.Close()
+
+	timer := time.NewTimer(cfg.gracePeriod)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return err
+		}
+		cfg.logger.Info("shutdown complete")
+		return nil
+	case <-timer.C:
+		cfg.logger.Warn("cancelling in-flight tool calls after grace period")
+		return ErrShutdownTimeout
+	}
+}
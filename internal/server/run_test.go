@@ -0,0 +1,117 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// blockingVault is a vault whose reads block until released or cancelled
+type blockingVault struct {
+	vault.Vault
+	started chan struct{}
+	release chan struct{}
+}
+
+func (v *blockingVault) Read(ctx context.Context, path string) (string, error) {
+	close(v.started)
+	select {
+	case <-v.release:
+		return "finished", nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (v *blockingVault) ReadProse(ctx context.Context, path string) (vault.ProseNote, error) {
+	content, err := v.Read(ctx, path)
+	return vault.ProseNote{Content: content}, err
+}
+
+// startRun serves v through Run and sends a read_note call
+// Returns the stdout reader, the cancel func and Run's result channel
+func startRun(t *testing.T, v vault.Vault, grace time.Duration) (*bufio.Scanner, context.CancelFunc, <-chan error) {
+	t.Helper()
+
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	t.Cleanup(func() {
+		stdinWriter.Close()
+		stdoutReader.Close()
+	})
+
+	logger := slog.New(slog.DiscardHandler)
+	srv := NewServer(v, logger, Options{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	result := make(chan error, 1)
+	go func() {
+		result <- Run(ctx, srv, WithGracePeriod(grace), WithLogger(logger), withIO(stdinReader, stdoutWriter))
+		stdoutWriter.Close()
+	}()
+
+	go func() {
+		_, _ = io.WriteString(stdinWriter, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"read_note","arguments":{"path":"note.md"}}}`+"\n")
+	}()
+
+	return bufio.NewScanner(stdoutReader), cancel, result
+}
+
+func TestRunDrainsInFlightCalls(t *testing.T) {
+	v := &blockingVault{started: make(chan struct{}), release: make(chan struct{})}
+	stdout, cancel, result := startRun(t, v, 5*time.Second)
+
+	<-v.started
+	cancel()
+
+	// The call is still running after shutdown begins
+	select {
+	case err := <-result:
+		t.Fatalf("Run returned before in-flight call finished: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(v.release)
+
+	if !stdout.Scan() {
+		t.Fatalf("Expected a response, got scan error %v", stdout.Err())
+	}
+	if !strings.Contains(stdout.Text(), "finished") {
+		t.Errorf("Expected completed read in response, got %s", stdout.Text())
+	}
+
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Errorf("Run() error = %v, want nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after in-flight call finished")
+	}
+}
+
+func TestRunGracePeriodExceeded(t *testing.T) {
+	v := &blockingVault{started: make(chan struct{}), release: make(chan struct{})}
+	_, cancel, result := startRun(t, v, 50*time.Millisecond)
+
+	<-v.started
+	cancel()
+
+	select {
+	case err := <-result:
+		if !errors.Is(err, ErrShutdownTimeout) {
+			t.Errorf("Run() error = %v, want ErrShutdownTimeout", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after grace period")
+	}
+}
@@ -1,26 +1,129 @@
 package server
 
 import (
+	"log/slog"
+	"time"
+
 	"github.com/mark3labs/mcp-go/server"
 
+	"github.com/kratos/mcp-notes/internal/metrics"
 	"github.com/kratos/mcp-notes/internal/tools"
 	"github.com/kratos/mcp-notes/internal/vault"
 )
 
+// DefaultSearchTimeout is the time limit of search_notes used by the
+// command-line server.
+const DefaultSearchTimeout = 10 * time.Second
+
+// DefaultToolTimeout is the time limit of tool calls used by the
+// command-line server.
+const DefaultToolTimeout = 2 * time.Minute
+
+// Options configures NewServer
+type Options struct {
+	// VaultName is the Obsidian vault name used for obsidian:// URIs
+	// Empty omits the URIs
+	VaultName string
+
+	// VaultRoot is the folder of the Obsidian vault the vault is limited
+	// to with vault.WithRoot, so obsidian:// URIs name notes from the
+	// vault root; empty for the whole vault
+	VaultRoot string
+
+	// Version is reported to clients and by server_info
+	// Empty uses Version(), taken from the build info
+	Version string
+
+	// SearchTimeout bounds searches that do not set their own timeout
+	// Zero leaves them unbounded
+	SearchTimeout time.Duration
+
+	// ToolTimeout bounds every tool call; tools that walk the whole
+	// vault get at least ten minutes. Zero leaves calls unbounded
+	ToolTimeout time.Duration
+
+	// MaxResponseBytes limits the size of every tool response
+	// Zero leaves responses unlimited
+	MaxResponseBytes int
+
+	// Metrics receives tool call counts and latencies, and is reported
+	// by server_info; nil records nothing
+	Metrics metrics.Metrics
+
+	// IgnoreRoots serves the whole vault even when the client declares
+	// MCP roots that cover only part of it
+	IgnoreRoots bool
+
+	// Tools selects the tools clients can see; the zero value exposes
+	// all of them. Validate it first: unknown names are ignored here.
+	Tools tools.ToolPolicy
+
+	// ClientName is the lease holder of every client of this server
+	// Empty uses the name each client sends when initializing
+	ClientName string
+
+	// ExportDir is the folder export_vault may write archives to
+	// Empty only returns archives in results
+	ExportDir string
+}
+
 // NewServer creates a new MCP server configured with all note tools.
 // It initializes the server with the "notes" identifier and registers
 // all tools provided by the tools package.
 //
 // The vault parameter provides access to the notes storage backend.
-func NewServer(v vault.Vault) *server.MCPServer {
-	// Create MCP server with name "notes" and version "1.0.0"
-	srv := server.NewMCPServer("notes", "1.0.0")
+// Every tool call is traced to logger. opts describes the server's
+// configuration so server_info reports it accurately.
+func NewServer(v vault.Vault, logger *slog.Logger, opts Options) *server.MCPServer {
+	version := opts.Version
+	if version == "" {
+		version = Version()
+	}
+
+	handlerOpts := []tools.Option{
+		tools.WithVaultName(opts.VaultName),
+		tools.WithVaultRoot(opts.VaultRoot),
+		tools.WithVersion(version),
+		tools.WithSearchTimeout(opts.SearchTimeout),
+		tools.WithToolTimeout(opts.ToolTimeout),
+		tools.WithMaxResponseBytes(opts.MaxResponseBytes),
+		tools.WithToolPolicy(opts.Tools),
+		tools.WithClientName(opts.ClientName),
+		tools.WithExportDir(opts.ExportDir),
+	}
+	if opts.Metrics != nil {
+		handlerOpts = append(handlerOpts, tools.WithMetrics(opts.Metrics))
+	}
 
 	// Create handlers with vault dependency
-	handlers := tools.NewHandlers(v)
+	handlers := tools.NewHandlers(v, logger, handlerOpts...)
+
+	// Create MCP server with name "notes"
+	calls := newInflightCalls()
+	srv := server.NewMCPServer(
+		"notes",
+		version,
+		server.WithHooks(calls.hooks()),
+		server.WithToolHandlerMiddleware(calls.middleware()),
+		server.WithToolHandlerMiddleware(handlers.LoggingMiddleware()),
+		server.WithToolHandlerMiddleware(handlers.MetricsMiddleware()),
+		server.WithToolHandlerMiddleware(handlers.RootsMiddleware()),
+		server.WithToolHandlerMiddleware(handlers.LocksMiddleware()),
+		server.WithToolHandlerMiddleware(handlers.ResponseLimitMiddleware()),
+		server.WithToolHandlerMiddleware(handlers.AuditMiddleware()),
+		server.WithToolHandlerMiddleware(handlers.ProgressMiddleware()),
+	)
 
-	// Register all tools with the server
+	// Let clients cancel their tool calls
+	srv.AddNotificationHandler(methodCancelled, calls.cancel)
+
+	// Register the tools the policy exposes
 	handlers.RegisterTools(srv)
 
+	// Limit tool calls to the client's roots
+	if !opts.IgnoreRoots {
+		watchRoots(srv, handlers, logger)
+	}
+
 	return srv
 }
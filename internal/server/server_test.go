@@ -0,0 +1,114 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent writes from the logger
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestStdioStaysCleanWithLogging(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "note.md"), []byte("Hello #tag"), 0644); err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+
+	var logs syncBuffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	v, err := vault.NewVault(tmpDir, vault.WithLogger(logger))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+
+	srv := NewServer(v, logger, Options{})
+	stdio := server.NewStdioServer(srv)
+
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = stdio.Listen(ctx, stdinReader, stdoutWriter)
+		stdoutWriter.Close()
+	}()
+
+	requests := []string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"list_notes","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"read_note","arguments":{"path":"missing.md"}}}`,
+	}
+	go func() {
+		for _, req := range requests {
+			if _, err := io.WriteString(stdinWriter, req+"\n"); err != nil {
+				return
+			}
+		}
+	}()
+
+	// Every line on stdout must be a JSON-RPC message
+	scanner := bufio.NewScanner(stdoutReader)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	responses := 0
+	for responses < 3 && scanner.Scan() {
+		var msg map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			t.Fatalf("Non JSON output on stdout: %q", scanner.Text())
+		}
+		if msg["jsonrpc"] != "2.0" {
+			t.Fatalf("Non JSON-RPC output on stdout: %q", scanner.Text())
+		}
+		if _, ok := msg["id"]; ok {
+			responses++
+		}
+	}
+	if responses < 3 {
+		t.Fatalf("Expected 3 responses, got %d (scan error: %v)", responses, scanner.Err())
+	}
+
+	stdinWriter.Close()
+	cancel()
+	<-done
+
+	output := logs.String()
+	for _, want := range []string{"tool=list_notes", "tool=read_note", "duration=", "walk completed"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected log output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
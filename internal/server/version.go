@@ -0,0 +1,43 @@
+package server
+
+import (
+	"runtime/debug"
+)
+
+// Version returns the server version recorded in the binary's build info:
+// the module version for `go install`ed builds, otherwise "devel" with the
+// VCS revision when known
+func Version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "devel"
+	}
+	return buildVersion(info)
+}
+
+// buildVersion derives the version string from build info
+func buildVersion(info *debug.BuildInfo) string {
+	if v := info.Main.Version; v != "" && v != "(devel)" {
+		return v
+	}
+
+	var revision string
+	var modified bool
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value == "true"
+		}
+	}
+	if revision == "" {
+		return "devel"
+	}
+
+	version := "devel+" + revision[:min(len(revision), 12)]
+	if modified {
+		version += "-dirty"
+	}
+	return version
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestBuildVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		info debug.BuildInfo
+		want string
+	}{
+		{
+			name: "module version",
+			info: debug.BuildInfo{Main: debug.Module{Version: "v1.4.0"}},
+			want: "v1.4.0",
+		},
+		{
+			name: "local build with revision",
+			info: debug.BuildInfo{
+				Main: debug.Module{Version: "(devel)"},
+				Settings: []debug.BuildSetting{
+					{Key: "vcs.revision", Value: "0123456789abcdef0123"},
+					{Key: "vcs.modified", Value: "false"},
+				},
+			},
+			want: "devel+0123456789ab",
+		},
+		{
+			name: "uncommitted changes",
+			info: debug.BuildInfo{
+				Main: debug.Module{Version: "(devel)"},
+				Settings: []debug.BuildSetting{
+					{Key: "vcs.revision", Value: "abc123"},
+					{Key: "vcs.modified", Value: "true"},
+				},
+			},
+			want: "devel+abc123-dirty",
+		},
+		{
+			name: "no build metadata",
+			info: debug.BuildInfo{Main: debug.Module{Version: "(devel)"}},
+			want: "devel",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildVersion(&tt.info); got != tt.want {
+				t.Errorf("buildVersion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if Version() == "" {
+		t.Error("Version() is empty")
+	}
+}
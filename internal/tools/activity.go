@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// defaultActivityFrom starts activity_report a year before today
+const defaultActivityFrom = "364d"
+
+// ActivityReportTool returns the ServerTool for reporting writing
+// activity per day.
+func (h *Handlers) ActivityReportTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"activity_report",
+		mcp.WithDescription("Report writing activity for dashboards and heatmaps: per day from 'from' to 'to', the notes created and the notes last modified that day, with the words they hold. "+
+			"Days without activity are left out; totals, a per-weekday rollup (Monday first) and a per-month rollup are included. "+
+			"Creation dates come from the frontmatter created fields, then the file's birth time, then its modification time. Words are counted per letter run, and per character in Chinese and Japanese."),
+		mcp.WithString(
+			"from",
+			mcp.Description("First day covered: a date (\"2024-01-01\"), an RFC3339 timestamp or a duration back from now (e.g. \"30d\"). Defaults to a year ago."),
+			mcp.DefaultString(defaultActivityFrom),
+		),
+		mcp.WithString(
+			"to",
+			mcp.Description("Last day covered, inclusive, in the same forms as 'from'. Defaults to today."),
+		),
+		mcp.WithString(
+			"path",
+			mcp.Description("Optional folder to look in, relative to vault root. If empty, covers the entire vault."),
+		),
+		mcp.WithArray(
+			"tags",
+			mcp.Description("Only count notes with any of these tags, e.g. [\"journal\"]."),
+			mcp.WithStringItems(),
+		),
+		withMaxBytes(),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleActivityReport,
+	}
+}
+
+// handleActivityReport implements the activity_report tool handler.
+func (h *Handlers) handleActivityReport(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	now := time.Now()
+	from, err := parseTime(request.GetString("from", defaultActivityFrom), now)
+	if err != nil {
+		return invalidParamResult("from", err), nil
+	}
+	to := now
+	if param := request.GetString("to", ""); param != "" {
+		if to, err = parseTime(param, now); err != nil {
+			return invalidParamResult("to", err), nil
+		}
+	}
+	if from.Format(dateLayout) > to.Format(dateLayout) {
+		return invalidParamResult("from", fmt.Errorf("from %s is after to %s", from.Format(dateLayout), to.Format(dateLayout))), nil
+	}
+	opts := vault.ActivityOptions{
+		Subpath: request.GetString("path", ""),
+		Tags:    request.GetStringSlice("tags", nil),
+		From:    from,
+		To:      to,
+	}
+
+	// Call vault
+	report, err := h.vault.ActivityReport(ctx, opts)
+	if err != nil {
+		return vaultErrorResult(err, "reporting activity", opts.Subpath), nil
+	}
+
+	return fitJSON(len(report.Days), h.responseLimit(request), func(n int) any {
+		fitted := report
+		fitted.Days = report.Days[:n]
+		fitted.Truncated = n < len(report.Days)
+		return fitted
+	})
+}
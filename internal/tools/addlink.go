@@ -0,0 +1,101 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// AddLinkTool returns the ServerTool for linking one note from another.
+func (h *Handlers) AddLinkTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"add_link",
+		mcp.WithDescription("Link a note from another, e.g. a meeting note from its project page, without rewriting the page: inserts a wikilink such as [[Meeting 2024-06-01|kickoff]] "+
+			"at the end of the note or at the end of a heading's section, as a line or a list item. The link text is the shortest that resolves to the target. "+
+			"Nothing is written when that place already links the target; already_linked is then true. Returns the line holding the link and its line number."),
+		mcp.WithString(
+			"from",
+			mcp.Description("Path of the note to add the link to (relative to vault root, must end with .md)."),
+			mcp.Required(),
+		),
+		mcp.WithString(
+			"to",
+			mcp.Description("Note to link to: a path, file name, title or alias, as resolve_note takes."),
+			mcp.Required(),
+		),
+		mcp.WithString(
+			"alias",
+			mcp.Description("Optional display text, written as [[target|alias]]."),
+		),
+		mcp.WithString(
+			"location",
+			mcp.Description("Where the link goes: end_of_note (default), under_heading for a line at the end of the heading's section, or in_section_list for a list item there."),
+			mcp.Enum(string(vault.LinkAtEnd), string(vault.LinkUnderHeading), string(vault.LinkInList)),
+		),
+		mcp.WithString(
+			"heading",
+			mcp.Description("Heading for under_heading and in_section_list, e.g. \"Meetings\" or \"## Meetings\"."),
+		),
+		mcp.WithBoolean(
+			"create_heading",
+			mcp.Description("Append the heading to the end of the note when it is missing, instead of failing. Defaults to false."),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleAddLink,
+	}
+}
+
+// handleAddLink implements the add_link tool handler.
+func (h *Handlers) handleAddLink(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	from, err := request.RequireString("from")
+	if err != nil {
+		return missingParamResult("from", err), nil
+	}
+	// 'from' is not among the middleware's path parameters, as
+	// activity_report takes a date in it
+	if !h.inRoots(from) {
+		folders, _ := h.RootFolders()
+		return errorResult(outsideRootsError(from, folders)), nil
+	}
+
+	name, err := request.RequireString("to")
+	if err != nil {
+		return missingParamResult("to", err), nil
+	}
+
+	location, err := vault.ParseLinkLocation(request.GetString("location", ""))
+	if err != nil {
+		return invalidParamResult("location", err), nil
+	}
+
+	to, errResult := h.resolveName(ctx, name, "adding link")
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	opts := vault.AddLinkOptions{
+		From:          from,
+		To:            to,
+		Alias:         request.GetString("alias", ""),
+		Location:      location,
+		Heading:       request.GetString("heading", ""),
+		CreateHeading: request.GetBool("create_heading", false),
+	}
+
+	// Call vault
+	result, err := h.vault.AddLink(ctx, opts)
+	if err != nil {
+		return vaultErrorResult(err, "adding link", from), nil
+	}
+
+	return jsonResult(result)
+}
@@ -0,0 +1,105 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// AddAliasTool returns the ServerTool for adding an alias to a note.
+func (h *Handlers) AddAliasTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"add_alias",
+		mcp.WithDescription("Add an alias to a note's frontmatter, so [[alias]] links and name lookups find it, without rewriting the note: only the aliases field changes, "+
+			"keeping its form (a single value, [a, b] or a list of - items), and the other properties, their order and comments stay as written. "+
+			"The field, or the whole frontmatter block, is created when missing. Nothing is written when the note already has the alias, ignoring case; changed is then false. Returns every alias of the note afterwards."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Path of the note (relative to vault root, must end with .md)."),
+			mcp.Required(),
+		),
+		mcp.WithString(
+			"alias",
+			mcp.Description("Alias to add, a single line of text such as \"Q3 plan\"."),
+			mcp.Required(),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleAddAlias,
+	}
+}
+
+// RemoveAliasTool returns the ServerTool for removing an alias from a
+// note.
+func (h *Handlers) RemoveAliasTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"remove_alias",
+		mcp.WithDescription("Remove an alias from a note's frontmatter, ignoring case, under aliases and the legacy alias property alike. Only those fields change; one left empty is removed. "+
+			"An alias the note does not have fails with NOT_FOUND listing the aliases it has. Returns every alias of the note afterwards."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Path of the note (relative to vault root, must end with .md)."),
+			mcp.Required(),
+		),
+		mcp.WithString(
+			"alias",
+			mcp.Description("Alias to remove."),
+			mcp.Required(),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleRemoveAlias,
+	}
+}
+
+// handleAddAlias implements the add_alias tool handler.
+func (h *Handlers) handleAddAlias(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	path, err := request.RequireString("path")
+	if err != nil {
+		return missingParamResult("path", err), nil
+	}
+	alias, err := request.RequireString("alias")
+	if err != nil {
+		return missingParamResult("alias", err), nil
+	}
+
+	// Call vault
+	result, err := h.vault.AddAlias(ctx, path, alias)
+	if err != nil {
+		return vaultErrorResult(err, "adding alias", path), nil
+	}
+
+	return jsonResult(result)
+}
+
+// handleRemoveAlias implements the remove_alias tool handler.
+func (h *Handlers) handleRemoveAlias(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	path, err := request.RequireString("path")
+	if err != nil {
+		return missingParamResult("path", err), nil
+	}
+	alias, err := request.RequireString("alias")
+	if err != nil {
+		return missingParamResult("alias", err), nil
+	}
+
+	// Call vault
+	result, err := h.vault.RemoveAlias(ctx, path, alias)
+	if err != nil {
+		return vaultErrorResult(err, "removing alias", path), nil
+	}
+
+	return jsonResult(result)
+}
@@ -0,0 +1,120 @@
+package tools
+
+import (
+	"context"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// taskResults is the result of find_tasks
+type taskResults struct {
+	Count int               `json:"count"` // Tasks across all notes
+	Notes []vault.NoteTasks `json:"notes"`
+}
+
+// AnalyzeNoteTool returns the ServerTool for analyzing a note's structure.
+func (h *Handlers) AnalyzeNoteTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"analyze_note",
+		mcp.WithDescription("Analyze a note: content hash, word count, heading outline, checkbox tasks (- [ ] / - [x]) with their state, text, containing heading, nesting depth and line number, blocks marked with ^block-id with their text and lines, and callouts (> [!type]- Title) with their type, title, fold marker, lines and nesting depth. Tasks inside callouts are included; headings inside callouts are not part of the outline. Block IDs defined more than once are reported in warnings."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Path to the note file (relative to vault root, must end with .md). Either path or name is required."),
+		),
+		mcp.WithString(
+			"name",
+			mcp.Description("Note name, frontmatter title or alias to resolve instead of a path."),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleAnalyzeNote,
+	}
+}
+
+// handleAnalyzeNote implements the analyze_note tool handler.
+func (h *Handlers) handleAnalyzeNote(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	path, errResult := h.notePath(ctx, request, "analyzing note")
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	// Call vault
+	analysis, err := h.vault.Analyze(ctx, path)
+	if err != nil {
+		return vaultErrorResult(err, "analyzing note", path), nil
+	}
+
+	return jsonResult(analysis)
+}
+
+// FindTasksTool returns the ServerTool for collecting tasks across notes.
+func (h *Handlers) FindTasksTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"find_tasks",
+		mcp.WithDescription("Collect checkbox tasks (- [ ] / - [x]) across the vault or a folder. Returns tasks grouped by note, each with its state, text, containing heading and line number, plus the total count. Tasks in code blocks are ignored."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Optional subdirectory to scan. If empty, scans the whole vault."),
+		),
+		mcp.WithString(
+			"status",
+			mcp.Description("Which tasks to return."),
+			mcp.Enum(string(vault.TaskStatusOpen), string(vault.TaskStatusDone), string(vault.TaskStatusAll)),
+			mcp.DefaultString(string(vault.TaskStatusAll)),
+		),
+		mcp.WithString(
+			"tag",
+			mcp.Description("Optional tag; only tasks whose text contains it are returned (with or without #)."),
+		),
+		mcp.WithBoolean(
+			"include_hidden",
+			mcp.Description("Whether to include files and folders whose name starts with a dot."),
+			mcp.DefaultBool(false),
+		),
+		withNoteType(),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleFindTasks,
+	}
+}
+
+// handleFindTasks implements the find_tasks tool handler.
+func (h *Handlers) handleFindTasks(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	status, err := vault.ParseTaskStatus(request.GetString("status", string(vault.TaskStatusAll)))
+	if err != nil {
+		return invalidParamResult("status", err), nil
+	}
+
+	opts := vault.TaskOptions{
+		Subpath:       request.GetString("path", ""),
+		Status:        status,
+		Tag:           request.GetString("tag", ""),
+		IncludeHidden: request.GetBool("include_hidden", false),
+		Type:          request.GetString("type", ""),
+	}
+
+	// Call vault
+	notes, err := h.vault.FindTasks(ctx, opts)
+	if err != nil {
+		return vaultErrorResult(err, "finding tasks", opts.Subpath), nil
+	}
+
+	result := taskResults{Notes: notes}
+	for _, note := range notes {
+		result.Count += len(note.Tasks)
+	}
+
+	return jsonResult(result)
+}
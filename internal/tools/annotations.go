@@ -0,0 +1,115 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// annotationResult is the response of set_note_annotation
+type annotationResult struct {
+	Path    string `json:"path"`
+	Key     string `json:"key"`
+	Removed bool   `json:"removed,omitempty"`
+}
+
+// SetNoteAnnotationTool returns the ServerTool for storing a value
+// alongside a note.
+func (h *Handlers) SetNoteAnnotationTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"set_note_annotation",
+		mcp.WithDescription("Store a value such as a summary or an embedding id alongside a note, without modifying the note. "+
+			"The value is kept in the server's data directory together with the note's content hash, so reads report it as stale once the note changes. "+
+			"Annotations follow folder renames and merges."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Path to the note (relative to vault root, must end with .md)."),
+			mcp.Required(),
+		),
+		mcp.WithString(
+			"key",
+			mcp.Description("Name of the annotation, e.g. \"summary\": 1 to 64 letters, digits, '.', '_' or '-'."),
+			mcp.Required(),
+		),
+		mcp.WithString(
+			"value",
+			mcp.Description(fmt.Sprintf("Value to store, at most %d bytes. An empty string removes the annotation.", vault.MaxAnnotationSize)),
+			mcp.Required(),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleSetNoteAnnotation,
+	}
+}
+
+// handleSetNoteAnnotation implements the set_note_annotation tool handler.
+func (h *Handlers) handleSetNoteAnnotation(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	path, err := request.RequireString("path")
+	if err != nil {
+		return missingParamResult("path", err), nil
+	}
+
+	key, err := request.RequireString("key")
+	if err != nil {
+		return missingParamResult("key", err), nil
+	}
+
+	value, err := request.RequireString("value")
+	if err != nil {
+		return missingParamResult("value", err), nil
+	}
+
+	// Call vault
+	if err := h.vault.SetAnnotation(ctx, path, key, value); err != nil {
+		return vaultErrorResult(err, "annotating note", path), nil
+	}
+
+	return jsonResult(annotationResult{Path: path, Key: key, Removed: value == ""})
+}
+
+// GetNoteAnnotationsTool returns the ServerTool for reading the values
+// stored alongside a note.
+func (h *Handlers) GetNoteAnnotationsTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"get_note_annotations",
+		mcp.WithDescription("Get the annotations stored alongside a note by key, each with when it was set and whether the note has changed since (stale)."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Path to the note (relative to vault root, must end with .md)."),
+			mcp.Required(),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleGetNoteAnnotations,
+	}
+}
+
+// handleGetNoteAnnotations implements the get_note_annotations tool handler.
+func (h *Handlers) handleGetNoteAnnotations(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	path, err := request.RequireString("path")
+	if err != nil {
+		return missingParamResult("path", err), nil
+	}
+
+	// Call vault
+	annotations, err := h.vault.GetAnnotations(ctx, path)
+	if err != nil {
+		return vaultErrorResult(err, "reading annotations", path), nil
+	}
+
+	return jsonResult(annotations)
+}
@@ -0,0 +1,168 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// editParam is one element of the apply_changes operations parameter
+type editParam struct {
+	Op               string `json:"op"`
+	Path             string `json:"path"`
+	NewPath          string `json:"new_path"`
+	Content          string `json:"content"`
+	ExpectedRevision string `json:"expected_revision"`
+}
+
+// batchProblem is an operation apply_changes could not perform
+type batchProblem struct {
+	Index int    `json:"index"`
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	ToolError
+}
+
+// batchErrorResult is a ToolError listing every operation that stopped a
+// batch, so the model can fix them all before retrying.
+type batchErrorResult struct {
+	ToolError
+	Problems    []batchProblem `json:"problems"`
+	RolledBack  bool           `json:"rolled_back"`
+	NotRestored []string       `json:"not_restored,omitempty"`
+}
+
+// ApplyChangesTool returns the ServerTool for applying a batch of note
+// edits atomically.
+func (h *Handlers) ApplyChangesTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"apply_changes",
+		mcp.WithDescription("Apply several note edits in order, all or none. Every operation is checked against the state the earlier ones leave before anything is written, and all problems are reported together; if a write then fails, the operations already done are undone. Returns each note's path and new revision (its content_hash)."),
+		mcp.WithArray(
+			"operations",
+			mcp.Description("Edits to apply in order. op is create, update (replace the content), append (add content on a new line at the end), delete (move to the server's trash) or move (to new_path, which must be free). expected_revision, the content_hash reported by analyze_note, changed_notes or an earlier apply_changes, makes the operation fail if the note has changed since."),
+			mcp.Items(map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"op":                map[string]any{"type": "string", "enum": []string{"create", "update", "append", "delete", "move"}},
+					"path":              map[string]any{"type": "string", "description": "Note path relative to vault root, ending with .md."},
+					"new_path":          map[string]any{"type": "string", "description": "Destination of a move."},
+					"content":           map[string]any{"type": "string", "description": "Content of a create or update, text added by an append."},
+					"expected_revision": map[string]any{"type": "string", "description": "content_hash the note must have."},
+				},
+				"required": []string{"op", "path"},
+			}),
+			mcp.MinItems(1),
+			mcp.Required(),
+		),
+		mcp.WithBoolean(
+			"dry_run",
+			mcp.Description("Validate the operations and return their planned outcome without writing anything."),
+			mcp.DefaultBool(false),
+		),
+		withForce(),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleApplyChanges,
+	}
+}
+
+// handleApplyChanges implements the apply_changes tool handler.
+func (h *Handlers) handleApplyChanges(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	arg, ok := request.GetArguments()["operations"]
+	if !ok {
+		return missingParamResult("operations", errors.New("required argument \"operations\" not found")), nil
+	}
+	edits, err := parseEdits(arg)
+	if err != nil {
+		return invalidParamResult("operations", err), nil
+	}
+
+	// Paths inside operations are not seen by RootsMiddleware
+	if folders, scoped := h.RootFolders(); scoped {
+		for _, e := range edits {
+			for _, p := range []string{e.Path, e.NewPath} {
+				if p != "" && !inFolders(p, folders) {
+					return errorResult(outsideRootsError(p, folders)), nil
+				}
+			}
+		}
+	}
+
+	// Call vault
+	result, err := h.vault.ApplyEdits(ctx, vault.BatchOptions{
+		Edits:  edits,
+		DryRun: request.GetBool("dry_run", false),
+	})
+	if err != nil {
+		return vaultErrorResult(err, "applying changes", ""), nil
+	}
+
+	return jsonResult(result)
+}
+
+// parseEdits converts the operations parameter, an array of objects
+func parseEdits(arg any) ([]vault.Edit, error) {
+	data, err := json.Marshal(arg)
+	if err != nil {
+		return nil, err
+	}
+	var params []editParam
+	if err := json.Unmarshal(data, &params); err != nil {
+		return nil, errors.New("expected an array of objects with op and path")
+	}
+	if len(params) == 0 {
+		return nil, errors.New("expected at least one operation")
+	}
+
+	edits := make([]vault.Edit, len(params))
+	for i, p := range params {
+		if p.Path == "" {
+			return nil, fmt.Errorf("operation %d has no path", i)
+		}
+		edits[i] = vault.Edit{
+			Op:               vault.EditKind(p.Op),
+			Path:             p.Path,
+			NewPath:          p.NewPath,
+			Content:          p.Content,
+			ExpectedRevision: p.ExpectedRevision,
+		}
+	}
+	return edits, nil
+}
+
+// batchToolError converts the problems of a failed batch, summarizing
+// them in a ToolError with the code of the first
+func batchToolError(batchErr *vault.BatchError) batchErrorResult {
+	result := batchErrorResult{RolledBack: batchErr.RolledBack, NotRestored: batchErr.NotRestored}
+	for _, p := range batchErr.Problems {
+		result.Problems = append(result.Problems, batchProblem{
+			Index:     p.Index,
+			Op:        string(p.Op),
+			Path:      p.Path,
+			ToolError: vaultToolError(p.Err, fmt.Sprintf("applying operation %d", p.Index), p.Path),
+		})
+	}
+
+	first := result.Problems[0].ToolError
+	switch {
+	case len(batchErr.NotRestored) > 0:
+		result.ToolError = ToolError{CodeInternal, fmt.Sprintf("Operation %d failed and the rollback could not restore %d notes; the vault is partly changed", result.Problems[0].Index, len(batchErr.NotRestored)), "Check the notes listed in not_restored; deleted notes are in the server's trash and overwritten ones in their versions."}
+	case batchErr.RolledBack:
+		result.ToolError = ToolError{first.Code, fmt.Sprintf("Operation %d failed: %s. The operations before it were rolled back; nothing was changed", result.Problems[0].Index, first.Message), first.Hint}
+	default:
+		result.ToolError = ToolError{first.Code, fmt.Sprintf("%d operations are invalid; nothing was changed", len(result.Problems)), "Fix every operation listed in problems and retry, or use dry_run to check them first."}
+	}
+	return result
+}
@@ -0,0 +1,237 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// maxInlineArchiveBytes caps an archive returned in the result rather than
+// written to the export directory
+const maxInlineArchiveBytes = 10 << 20
+
+// errArchiveTooLarge stops an inline archive that outgrows its cap
+var errArchiveTooLarge = errors.New("archive too large")
+
+// WithExportDir lets export_vault write archives to files below dir. By
+// default archives are only returned in the result.
+func WithExportDir(dir string) Option {
+	return func(h *Handlers) {
+		h.exportDir = dir
+	}
+}
+
+// vaultArchive is the result of export_vault
+type vaultArchive struct {
+	vault.ArchiveManifest
+	ArchiveBytes int    `json:"archive_bytes"`     // Size of the archive itself
+	Output       string `json:"output,omitempty"`  // File written, relative to the export directory
+	Archive      string `json:"archive,omitempty"` // Base64 archive, when no output was given
+}
+
+// cappedBuffer is a buffer that fails writes past max bytes
+type cappedBuffer struct {
+	bytes.Buffer
+	max int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.max {
+		return 0, errArchiveTooLarge
+	}
+	return b.Buffer.Write(p)
+}
+
+// ExportVaultTool returns the ServerTool for exporting notes as an archive.
+func (h *Handlers) ExportVaultTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"export_vault",
+		mcp.WithDescription(fmt.Sprintf("Export the markdown notes of the vault, or of a folder, as a zip or tar archive with a manifest.json listing each note's path, size and content hash. "+
+			"The archive is written to output inside the server's export directory, or returned base64-encoded in 'archive' when it is at most %d MiB. "+
+			"Notes that change while being read are skipped and listed under 'skipped'. Use it to snapshot notes before a large batch of edits.", maxInlineArchiveBytes>>20)),
+		mcp.WithString(
+			"path",
+			mcp.Description("Optional folder to export, relative to the vault root. If empty, exports the whole vault."),
+		),
+		mcp.WithString(
+			"format",
+			mcp.Description("Archive format."),
+			mcp.Enum(string(vault.ArchiveZip), string(vault.ArchiveTar)),
+			mcp.DefaultString(string(vault.ArchiveZip)),
+		),
+		mcp.WithArray(
+			"tags",
+			mcp.Description("Optional list of tags. Notes must have at least one of these tags."),
+			mcp.WithStringItems(),
+		),
+		mcp.WithString(
+			"modified_after",
+			mcp.Description("Only notes modified at or after this point: a duration back from now (e.g. \"-30d\", \"72h\"), a date (\"2024-03-01\") or an RFC3339 timestamp."),
+		),
+		mcp.WithString(
+			"output",
+			mcp.Description("File to write the archive to, relative to the server's export directory (--export-dir) and ending in .zip or .tar to match format. It must not exist. If empty, the archive is returned in the result."),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleExportVault,
+	}
+}
+
+// handleExportVault implements the export_vault tool handler.
+func (h *Handlers) handleExportVault(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	format, err := vault.ParseArchiveFormat(request.GetString("format", string(vault.ArchiveZip)))
+	if err != nil {
+		// Not invalidParamResult: its format hint lists export_note's formats
+		return errorResult(ToolError{
+			Code:    CodeInvalidParams,
+			Message: fmt.Sprintf("Invalid parameter 'format': %v", err),
+			Hint:    "One of zip or tar.",
+		}), nil
+	}
+	opts := vault.ArchiveOptions{
+		Subpath: request.GetString("path", ""),
+		Format:  format,
+		TagsAny: request.GetStringSlice("tags", nil),
+	}
+	if after := request.GetString("modified_after", ""); after != "" {
+		if opts.ModifiedAfter, err = parseTime(after, time.Now()); err != nil {
+			return invalidParamResult("modified_after", err), nil
+		}
+	}
+
+	output := request.GetString("output", "")
+	if output == "" {
+		return h.exportInline(ctx, opts)
+	}
+	return h.exportToFile(ctx, opts, output)
+}
+
+// exportInline returns the archive base64-encoded in the result.
+func (h *Handlers) exportInline(ctx context.Context, opts vault.ArchiveOptions) (*mcp.CallToolResult, error) {
+	buf := &cappedBuffer{max: maxInlineArchiveBytes}
+	manifest, err := h.vault.ExportVault(ctx, opts, buf)
+	if errors.Is(err, errArchiveTooLarge) {
+		return errorResult(ToolError{
+			Code:    CodeTooLarge,
+			Message: fmt.Sprintf("Archive exceeds %d MiB and cannot be returned in the result", maxInlineArchiveBytes>>20),
+			Hint:    "Narrow the export with path, tags or modified_after, or pass output to write it to the export directory.",
+		}), nil
+	}
+	if err != nil {
+		return vaultErrorResult(err, "exporting vault", opts.Subpath), nil
+	}
+
+	return jsonResult(vaultArchive{
+		ArchiveManifest: manifest,
+		ArchiveBytes:    buf.Len(),
+		Archive:         base64.StdEncoding.EncodeToString(buf.Bytes()),
+	})
+}
+
+// exportToFile writes the archive to output below the export directory,
+// through a temporary file so a failed export leaves nothing behind.
+func (h *Handlers) exportToFile(ctx context.Context, opts vault.ArchiveOptions, output string) (*mcp.CallToolResult, error) {
+	if h.exportDir == "" {
+		return errorResult(ToolError{
+			Code:    CodeNotConfigured,
+			Message: "Export directory not configured",
+			Hint:    "Start the server with --export-dir, or omit output to get the archive in the result.",
+		}), nil
+	}
+
+	file, rel, err := h.exportFile(output, opts.Format)
+	if err != nil {
+		return invalidParamResult("output", err), nil
+	}
+	if _, err := os.Lstat(file); err == nil {
+		return errorResult(ToolError{
+			Code:    CodeAlreadyExists,
+			Message: fmt.Sprintf("Export file already exists: %s", output),
+			Hint:    "Choose another output name.",
+		}), nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return vaultErrorResult(err, "creating export folder", output), nil
+	}
+	if !h.withinExportDir(filepath.Dir(file)) {
+		return invalidParamResult("output", fmt.Errorf("%q leads outside the export directory through a symlink", output)), nil
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".export-*")
+	if err != nil {
+		return vaultErrorResult(err, "creating export file", output), nil
+	}
+	defer os.Remove(tmp.Name())
+
+	manifest, err := h.vault.ExportVault(ctx, opts, tmp)
+	if err == nil {
+		err = tmp.Close()
+	} else {
+		tmp.Close()
+	}
+	if err != nil {
+		return vaultErrorResult(err, "exporting vault", opts.Subpath), nil
+	}
+	stat, err := os.Stat(tmp.Name())
+	if err != nil {
+		return vaultErrorResult(err, "exporting vault", opts.Subpath), nil
+	}
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		return vaultErrorResult(err, "writing export file", output), nil
+	}
+
+	return jsonResult(vaultArchive{
+		ArchiveManifest: manifest,
+		ArchiveBytes:    int(stat.Size()),
+		Output:          rel,
+	})
+}
+
+// exportFile returns the full path of output below the export directory
+// and its path relative to it, failing when it leaves the directory or
+// does not end in the format's extension.
+func (h *Handlers) exportFile(output string, format vault.ArchiveFormat) (string, string, error) {
+	file := filepath.Clean(filepath.FromSlash(output))
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(h.exportDir, file)
+	}
+	rel, err := filepath.Rel(h.exportDir, file)
+	if err != nil || !filepath.IsLocal(rel) {
+		return "", "", fmt.Errorf("%q is outside the export directory", output)
+	}
+	if ext := "." + string(format); filepath.Ext(file) != ext {
+		return "", "", fmt.Errorf("%q must end in %s to match format %s", output, ext, format)
+	}
+	return file, filepath.ToSlash(rel), nil
+}
+
+// withinExportDir reports whether dir, once symlinks are resolved, is
+// still inside the export directory
+func (h *Handlers) withinExportDir(dir string) bool {
+	root, err := filepath.EvalSymlinks(h.exportDir)
+	if err != nil {
+		return false
+	}
+	resolved, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(root, resolved)
+	return err == nil && (rel == "." || filepath.IsLocal(rel))
+}
@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportVaultTool(t *testing.T) {
+	h, _ := rootsHandlers(t)
+
+	t.Run("inline", func(t *testing.T) {
+		result := callTool(t, h, "export_vault", map[string]any{"path": "Personal"})
+		var archive vaultArchive
+		if err := json.Unmarshal([]byte(resultText(result)), &archive); err != nil {
+			t.Fatalf("export_vault result = %s: %v", resultText(result), err)
+		}
+		data, err := base64.StdEncoding.DecodeString(archive.Archive)
+		if err != nil || len(data) != archive.ArchiveBytes {
+			t.Fatalf("archive is not %d bytes of base64: %v", archive.ArchiveBytes, err)
+		}
+		r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatalf("archive is not a zip: %v", err)
+		}
+		var names []string
+		for _, f := range r.File {
+			names = append(names, f.Name)
+		}
+		if len(names) != 3 || names[0] != "Personal/diary.md" || names[2] != "manifest.json" || len(archive.Notes) != 2 {
+			t.Errorf("archive holds %v, manifest %+v", names, archive.Notes)
+		}
+	})
+
+	t.Run("output needs an export directory", func(t *testing.T) {
+		checkToolError(t, callTool(t, h, "export_vault", map[string]any{"output": "snap.zip"}), CodeNotConfigured)
+	})
+
+	exportDir := t.TempDir()
+	h.exportDir = exportDir
+
+	t.Run("output", func(t *testing.T) {
+		result := callTool(t, h, "export_vault", map[string]any{"format": "tar", "output": "snapshots/vault.tar"})
+		var archive vaultArchive
+		if err := json.Unmarshal([]byte(resultText(result)), &archive); err != nil {
+			t.Fatalf("export_vault result = %s: %v", resultText(result), err)
+		}
+		if archive.Output != "snapshots/vault.tar" || archive.Archive != "" || len(archive.Notes) != 3 {
+			t.Errorf("export_vault = %+v", archive)
+		}
+		stat, err := os.Stat(filepath.Join(exportDir, "snapshots", "vault.tar"))
+		if err != nil || stat.Size() != int64(archive.ArchiveBytes) {
+			t.Errorf("archive file = %v, %v; want %d bytes", stat, err, archive.ArchiveBytes)
+		}
+
+		checkToolError(t, callTool(t, h, "export_vault", map[string]any{"format": "tar", "output": "snapshots/vault.tar"}), CodeAlreadyExists)
+	})
+
+	t.Run("invalid output", func(t *testing.T) {
+		for _, output := range []string{"../escape.zip", filepath.Join(t.TempDir(), "abs.zip"), "vault.tar", "."} {
+			if toolErr := checkToolError(t, callTool(t, h, "export_vault", map[string]any{"output": output}), CodeInvalidParams); !strings.Contains(toolErr.Hint, ".zip or .tar") {
+				t.Errorf("output %q hint = %q", output, toolErr.Hint)
+			}
+		}
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		if toolErr := checkToolError(t, callTool(t, h, "export_vault", map[string]any{"format": "rar"}), CodeInvalidParams); toolErr.Hint != "One of zip or tar." {
+			t.Errorf("format hint = %q", toolErr.Hint)
+		}
+	})
+
+	t.Run("symlink out of the export directory", func(t *testing.T) {
+		if err := os.Symlink(t.TempDir(), filepath.Join(exportDir, "link")); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+		checkToolError(t, callTool(t, h, "export_vault", map[string]any{"output": "link/vault.zip"}), CodeInvalidParams)
+	})
+}
@@ -0,0 +1,310 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// defaultImageMaxBytes caps images returned by stat_attachment
+const defaultImageMaxBytes = 1 << 20
+
+// maxImageMaxBytes is the largest image size a caller may request
+const maxImageMaxBytes = 10 << 20
+
+// Bounds of the images read_note returns with include_images
+const (
+	maxNoteImages      = 10
+	noteImagesMaxBytes = 4 << 20 // All of them together
+)
+
+// viewableImageTypes are the media types returned as image content, those
+// models can view
+var viewableImageTypes = []string{"image/png", "image/jpeg", "image/gif", "image/webp"}
+
+// attachmentErrorResult is the payload of a failed read_attachment call
+// about an attachment that exists
+type attachmentErrorResult struct {
+	ToolError
+	Attachment vault.AttachmentInfo `json:"attachment"`
+}
+
+// imageContent returns data as image content of mimeType
+func imageContent(data []byte, mimeType string) mcp.ImageContent {
+	return mcp.ImageContent{
+		Type:     "image",
+		Data:     base64.StdEncoding.EncodeToString(data),
+		MIMEType: mimeType,
+	}
+}
+
+// ListAttachmentsTool returns the ServerTool for listing attachments in the vault.
+func (h *Handlers) ListAttachmentsTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"list_attachments",
+		mcp.WithDescription(fmt.Sprintf("List attachments (images, PDFs, audio, video and other non-markdown files) in the vault or a subdirectory with their size, modification time and media type. Content is never returned. Allowed extensions: %s.", strings.Join(vault.AttachmentExtensions(), ", "))),
+		mcp.WithString(
+			"path",
+			mcp.Description("Optional subdirectory path to list attachments from. If empty, lists from vault root."),
+		),
+		mcp.WithBoolean(
+			"recursive",
+			mcp.Description("Whether to recursively list attachments in subdirectories."),
+			mcp.DefaultBool(true),
+		),
+		mcp.WithArray(
+			"extensions",
+			mcp.Description("Optional list of extensions to restrict to, e.g. [\"png\", \"pdf\"]."),
+			mcp.WithStringItems(),
+		),
+		mcp.WithBoolean(
+			"include_hidden",
+			mcp.Description("Whether to include files and folders whose name starts with a dot."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleListAttachments,
+	}
+}
+
+// handleListAttachments implements the list_attachments tool handler.
+func (h *Handlers) handleListAttachments(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	opts := vault.AttachmentOptions{
+		Subpath:       request.GetString("path", ""),
+		Recursive:     request.GetBool("recursive", true),
+		IncludeHidden: request.GetBool("include_hidden", false),
+		Extensions:    request.GetStringSlice("extensions", nil),
+	}
+
+	// Call vault
+	attachments, err := h.vault.ListAttachments(ctx, opts)
+	if err != nil {
+		return vaultErrorResult(err, "listing attachments", opts.Subpath), nil
+	}
+
+	return listResult(attachments, h.maxResponseBytes)
+}
+
+// StatAttachmentTool returns the ServerTool for inspecting a single attachment.
+func (h *Handlers) StatAttachmentTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"stat_attachment",
+		mcp.WithDescription("Check that an attachment exists and return its size, modification time and media type. Use it to verify embeds such as ![[diagram.png]] after resolving them with get_note_links. Images can optionally be returned as image content; other binary content is never returned."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Path to the attachment (relative to vault root)."),
+			mcp.Required(),
+		),
+		mcp.WithBoolean(
+			"include_image",
+			mcp.Description("For images, also return the image itself if it is no larger than max_image_bytes."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithNumber(
+			"max_image_bytes",
+			mcp.Description("Largest image returned by include_image, in bytes."),
+			mcp.DefaultNumber(defaultImageMaxBytes),
+			mcp.Min(1),
+			mcp.Max(maxImageMaxBytes),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleStatAttachment,
+	}
+}
+
+// handleStatAttachment implements the stat_attachment tool handler.
+func (h *Handlers) handleStatAttachment(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	path, err := request.RequireString("path")
+	if err != nil {
+		return missingParamResult("path", err), nil
+	}
+	includeImage := request.GetBool("include_image", false)
+	maxBytes := min(max(request.GetInt("max_image_bytes", defaultImageMaxBytes), 1), maxImageMaxBytes)
+
+	// Call vault
+	info, err := h.vault.StatAttachment(ctx, path)
+	if err != nil {
+		return vaultErrorResult(err, "reading attachment", path), nil
+	}
+
+	// Load the image only when asked for and small enough; a failure here
+	// still leaves the metadata useful, so it is reported as a note
+	var image *mcp.ImageContent
+	var notice string
+	if includeImage {
+		switch {
+		case !info.IsImage():
+			notice = fmt.Sprintf("Content not returned: %s is not an image", info.MimeType)
+		case info.Size > int64(maxBytes):
+			notice = fmt.Sprintf("Image not returned: %d bytes exceeds max_image_bytes (%d)", info.Size, maxBytes)
+		default:
+			data, _, err := h.vault.ReadAttachment(ctx, path, int64(maxBytes))
+			switch {
+			case errors.Is(err, vault.ErrAttachmentTooLarge):
+				notice = fmt.Sprintf("Image not returned: exceeds max_image_bytes (%d)", maxBytes)
+			case err != nil:
+				notice = fmt.Sprintf("Image not returned: %s", formatVaultError(err, "reading attachment", path))
+			default:
+				content := imageContent(data, info.MimeType)
+				image = &content
+			}
+		}
+	}
+
+	result, err := jsonResult(info)
+	if err != nil {
+		return nil, err
+	}
+	if notice != "" {
+		result.Content = append(result.Content, mcp.NewTextContent(notice))
+	}
+	if image != nil {
+		result.Content = append(result.Content, *image)
+	}
+
+	return result, nil
+}
+
+// ReadAttachmentTool returns the ServerTool for viewing an image attachment.
+func (h *Handlers) ReadAttachmentTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"read_attachment",
+		mcp.WithDescription("Return an image attachment, such as a diagram embedded in a note with ![[diagram.png]], as image content the model can view, after its metadata. "+
+			"Only PNG, JPEG, GIF and WebP images are returned; other attachments, PDFs included, fail with NOT_IMAGE, and images larger than max_image_bytes fail with TOO_LARGE, both with the attachment's metadata. "+
+			"To see every image embedded in a note along with its text, use read_note with include_images."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Path to the image (relative to vault root)."),
+			mcp.Required(),
+		),
+		mcp.WithNumber(
+			"max_image_bytes",
+			mcp.Description("Largest image returned, in bytes."),
+			mcp.DefaultNumber(defaultImageMaxBytes),
+			mcp.Min(1),
+			mcp.Max(maxImageMaxBytes),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleReadAttachment,
+	}
+}
+
+// handleReadAttachment implements the read_attachment tool handler.
+func (h *Handlers) handleReadAttachment(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	path, err := request.RequireString("path")
+	if err != nil {
+		return missingParamResult("path", err), nil
+	}
+	maxBytes := min(max(request.GetInt("max_image_bytes", defaultImageMaxBytes), 1), maxImageMaxBytes)
+
+	// Call vault; the type is checked before anything is read
+	info, err := h.vault.StatAttachment(ctx, path)
+	if err != nil {
+		return vaultErrorResult(err, "reading attachment", path), nil
+	}
+	if !slices.Contains(viewableImageTypes, info.MimeType) {
+		return failedResult(attachmentErrorResult{
+			ToolError{CodeNotImage, fmt.Sprintf("Cannot return %s as an image: it is %s, and only PNG, JPEG, GIF and WebP images are returned", info.Path, info.MimeType), "Use stat_attachment for its size and type; other attachments are never returned as content."},
+			info,
+		}), nil
+	}
+
+	data, info, err := h.vault.ReadAttachment(ctx, path, int64(maxBytes))
+	if errors.Is(err, vault.ErrAttachmentTooLarge) {
+		hint := fmt.Sprintf("Pass a larger max_image_bytes, at most %d.", maxImageMaxBytes)
+		if maxBytes == maxImageMaxBytes {
+			hint = "The image is larger than any that can be returned; describe it from its name and the note embedding it."
+		}
+		return failedResult(attachmentErrorResult{
+			ToolError{CodeTooLarge, fmt.Sprintf("Image too large: %s is %d bytes, over max_image_bytes (%d)", info.Path, info.Size, maxBytes), hint},
+			info,
+		}), nil
+	}
+	if err != nil {
+		return vaultErrorResult(err, "reading attachment", path), nil
+	}
+
+	result, err := jsonResult(info)
+	if err != nil {
+		return nil, err
+	}
+	result.Content = append(result.Content, imageContent(data, info.MimeType))
+
+	return result, nil
+}
+
+// appendNoteImages adds the images embedded in the note at path, or in its
+// lines from start to end when end is positive, to result in document
+// order, within maxNoteImages and noteImagesMaxBytes. Images that are not
+// returned are listed in a notice.
+func (h *Handlers) appendNoteImages(ctx context.Context, result *mcp.CallToolResult, path string, start, end int) {
+	links, err := h.vault.Links(ctx, path)
+	if err != nil {
+		result.Content = append(result.Content, mcp.NewTextContent("Images not returned: "+formatVaultError(err, "reading links", path)))
+		return
+	}
+
+	var skipped []string
+	seen := make(map[string]bool)
+	images, budget := 0, noteImagesMaxBytes
+	for _, link := range links {
+		if link.Kind != vault.LinkEmbed || !link.Resolved || seen[link.Path] || end > 0 && (link.Line < start || link.Line > end) {
+			continue
+		}
+		seen[link.Path] = true
+		if !h.inRoots(link.Path) {
+			continue
+		}
+		info, err := h.vault.StatAttachment(ctx, link.Path)
+		if err != nil || !slices.Contains(viewableImageTypes, info.MimeType) {
+			continue // A note, or an attachment that is not an image
+		}
+
+		if images++; images > maxNoteImages {
+			skipped = append(skipped, fmt.Sprintf("%s (more than %d images)", info.Path, maxNoteImages))
+			continue
+		}
+		data, _, err := h.vault.ReadAttachment(ctx, link.Path, int64(min(defaultImageMaxBytes, budget)))
+		switch {
+		case errors.Is(err, vault.ErrAttachmentTooLarge) && info.Size > defaultImageMaxBytes:
+			skipped = append(skipped, fmt.Sprintf("%s (%d bytes, over %d)", info.Path, info.Size, defaultImageMaxBytes))
+		case errors.Is(err, vault.ErrAttachmentTooLarge):
+			skipped = append(skipped, fmt.Sprintf("%s (over the %d bytes of images per note)", info.Path, noteImagesMaxBytes))
+		case err != nil:
+			skipped = append(skipped, fmt.Sprintf("%s (%s)", info.Path, formatVaultError(err, "reading attachment", info.Path)))
+		default:
+			budget -= len(data)
+			result.Content = append(result.Content, imageContent(data, info.MimeType))
+		}
+	}
+
+	if len(skipped) > 0 {
+		result.Content = append(result.Content, mcp.NewTextContent("Images not returned: "+strings.Join(skipped, ", ")+". Use read_attachment with a larger max_image_bytes for one of them."))
+	}
+}
@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// resultImages returns the MIME types and decoded data of the images in
+// result, in order
+func resultImages(t *testing.T, result *mcp.CallToolResult) ([]string, []string) {
+	t.Helper()
+	var types, data []string
+	for _, content := range result.Content {
+		if image, ok := content.(mcp.ImageContent); ok {
+			raw, err := base64.StdEncoding.DecodeString(image.Data)
+			if err != nil {
+				t.Fatalf("Image data is not base64: %v", err)
+			}
+			types, data = append(types, image.MIMEType), append(data, string(raw))
+		}
+	}
+	return types, data
+}
+
+func TestReadAttachment(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"Plan.md":           "# Plan\n\n![[chart.png]]\n\n## Figures\n\n![photo](img/photo.jpg) ![[spec.pdf]] ![[chart.png]]\n",
+		"chart.png":         "png data",
+		"img/photo.jpg":     "jpeg data",
+		"spec.pdf":          "pdf data",
+		"big.webp":          strings.Repeat("w", 2048),
+		"img/drawing.svg":   "<svg/>",
+		"Notes/Embedded.md": "not an image",
+	}
+	writeFiles(t, dir, files)
+	v, err := vault.NewVault(dir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	h := NewHandlers(v, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	t.Run("image", func(t *testing.T) {
+		result := callTool(t, h, "read_attachment", map[string]any{"path": "img/photo.jpg"})
+		if result.IsError {
+			t.Fatalf("read_attachment failed: %s", resultText(result))
+		}
+		types, data := resultImages(t, result)
+		if len(types) != 1 || types[0] != "image/jpeg" || data[0] != "jpeg data" {
+			t.Errorf("Images = %v %q, want the JPEG", types, data)
+		}
+		if !strings.Contains(resultText(result), `"size": 9`) {
+			t.Errorf("Metadata = %s, want the image's size", resultText(result))
+		}
+	})
+
+	t.Run("not an image", func(t *testing.T) {
+		for _, path := range []string{"spec.pdf", "img/drawing.svg"} {
+			result := callTool(t, h, "read_attachment", map[string]any{"path": path})
+			if checkToolError(t, result, CodeNotImage); strings.Contains(resultText(result), "data") {
+				t.Errorf("read_attachment(%s) returned content: %s", path, resultText(result))
+			}
+		}
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		result := callTool(t, h, "read_attachment", map[string]any{"path": "big.webp", "max_image_bytes": 1024})
+		toolErr := checkToolError(t, result, CodeTooLarge)
+		var payload attachmentErrorResult
+		if err := json.Unmarshal([]byte(resultText(result)), &payload); err != nil || payload.Attachment.Size != 2048 {
+			t.Errorf("Payload = %s, want the attachment's metadata", resultText(result))
+		}
+		if !strings.Contains(toolErr.Hint, "max_image_bytes") {
+			t.Errorf("Hint = %q, want it to suggest max_image_bytes", toolErr.Hint)
+		}
+	})
+
+	t.Run("note images", func(t *testing.T) {
+		result := callTool(t, h, "read_note", map[string]any{"path": "Plan.md", "include_images": true})
+		if result.IsError {
+			t.Fatalf("read_note failed: %s", resultText(result))
+		}
+		if types, data := resultImages(t, result); strings.Join(data, ",") != "png data,jpeg data" || types[1] != "image/jpeg" {
+			t.Errorf("Images = %v %q, want the chart then the photo, once each", types, data)
+		}
+		if resultText(result) != files["Plan.md"] {
+			t.Errorf("Text = %q, want the note", resultText(result))
+		}
+
+		result = callTool(t, h, "read_note", map[string]any{"path": "Plan.md", "heading": "Figures", "include_images": true})
+		if _, data := resultImages(t, result); strings.Join(data, ",") != "jpeg data,png data" {
+			t.Errorf("Section images = %q, want the photo then the chart", data)
+		}
+
+		result = callTool(t, h, "read_note", map[string]any{"path": "Plan.md"})
+		if types, _ := resultImages(t, result); len(types) != 0 {
+			t.Errorf("read_note without include_images returned %d images", len(types))
+		}
+	})
+}
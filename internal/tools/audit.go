@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// Limits of get_audit_log
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 1000
+)
+
+// AuditMiddleware returns a tool handler middleware that records the
+// changes a call makes under its tool name, and adds a warning to a
+// successful result for each change the audit log missed.
+func (h *Handlers) AuditMiddleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			call := &vault.AuditCall{Tool: request.Params.Name}
+			result, err := next(vault.AuditContext(ctx, call), request)
+			if err != nil || result == nil || result.IsError {
+				return result, err
+			}
+			for _, warning := range call.Warnings() {
+				result.Content = append(result.Content, mcp.TextContent{Type: "text", Text: "warning: " + warning})
+			}
+			return result, nil
+		}
+	}
+}
+
+// GetAuditLogTool returns the ServerTool for reading the audit log.
+func (h *Handlers) GetAuditLogTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"get_audit_log",
+		mcp.WithDescription("List the changes made to notes through this server, newest first: the time, tool, client, operation, path, new path of a move, size and content hashes before and after. "+
+			"Use it to answer questions like \"what did you change yesterday?\"."),
+		mcp.WithNumber(
+			"limit",
+			mcp.Description("Maximum number of entries to return."),
+			mcp.DefaultNumber(defaultAuditLimit),
+			mcp.Min(1),
+			mcp.Max(maxAuditLimit),
+		),
+		mcp.WithString(
+			"path",
+			mcp.Description("Only list changes to this note or to notes in this folder, including moves into or out of it."),
+		),
+		mcp.WithString(
+			"since",
+			mcp.Description("Only list changes made after this point: a duration back from now (e.g. \"24h\", \"7d\"), a date (\"2024-03-01\") or an RFC3339 timestamp."),
+		),
+		mcp.WithString(
+			"until",
+			mcp.Description("Only list changes made before this point, in the same formats as since."),
+		),
+		withMaxBytes(),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleGetAuditLog,
+	}
+}
+
+// handleGetAuditLog implements the get_audit_log tool handler.
+func (h *Handlers) handleGetAuditLog(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	limit := min(max(request.GetInt("limit", defaultAuditLimit), 1), maxAuditLimit)
+	query := vault.AuditQuery{Path: request.GetString("path", ""), Limit: limit}
+	now := time.Now()
+	if since := request.GetString("since", ""); since != "" {
+		var err error
+		if query.Since, err = parseTime(since, now); err != nil {
+			return invalidParamResult("since", err), nil
+		}
+	}
+	if until := request.GetString("until", ""); until != "" {
+		var err error
+		if query.Until, err = parseTime(until, now); err != nil {
+			return invalidParamResult("until", err), nil
+		}
+	}
+
+	// Entries outside the client's roots are dropped after reading, so
+	// read them all before applying the limit
+	_, scoped := h.RootFolders()
+	if scoped {
+		query.Limit = 0
+	}
+
+	// Call vault
+	entries, err := h.vault.AuditLog(ctx, query)
+	if err != nil {
+		return vaultErrorResult(err, "reading audit log", query.Path), nil
+	}
+	if scoped {
+		entries = slices.DeleteFunc(entries, func(entry vault.AuditEntry) bool {
+			return !h.inRoots(entry.Path) && (entry.NewPath == "" || !h.inRoots(entry.NewPath))
+		})
+		entries = entries[:min(len(entries), limit)]
+	}
+
+	return listResult(entries, h.responseLimit(request))
+}
@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// callAudited invokes the named tool through AuditMiddleware
+func callAudited(t *testing.T, h *Handlers, name string, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	for _, tool := range h.Tools() {
+		if tool.Tool.Name != name {
+			continue
+		}
+		var request mcp.CallToolRequest
+		request.Params.Name = name
+		request.Params.Arguments = args
+		result, err := h.AuditMiddleware()(tool.Handler)(context.Background(), request)
+		if err != nil {
+			t.Fatalf("%s returned error: %v", name, err)
+		}
+		return result
+	}
+	t.Fatalf("No tool named %s", name)
+	return nil
+}
+
+func TestGetAuditLog(t *testing.T) {
+	h, base := rootsHandlers(t)
+
+	if result := callAudited(t, h, "update_note", map[string]any{"path": "Work/plan.md", "content": "# New plan"}); result.IsError {
+		t.Fatalf("update_note failed: %s", resultText(result))
+	}
+	if result := callAudited(t, h, "create_note", map[string]any{"path": "Personal/todo.md", "content": "- [ ] call"}); result.IsError {
+		t.Fatalf("create_note failed: %s", resultText(result))
+	}
+
+	result := callAudited(t, h, "get_audit_log", map[string]any{"path": "Work"})
+	var entries []vault.AuditEntry
+	if err := json.Unmarshal([]byte(resultText(result)), &entries); err != nil {
+		t.Fatalf("get_audit_log result is not a list: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Tool != "update_note" || entries[0].Op != vault.EditUpdate || entries[0].Path != "Work/plan.md" {
+		t.Errorf("get_audit_log = %+v, want the update of Work/plan.md", entries)
+	}
+
+	// Scoped clients only see changes inside their roots
+	h.SetRoots([]string{rootURI(filepath.Join(base, "Work"))})
+	result = callScoped(t, h, "get_audit_log", map[string]any{"limit": 1})
+	entries = nil
+	if err := json.Unmarshal([]byte(resultText(result)), &entries); err != nil {
+		t.Fatalf("get_audit_log result is not a list: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Path != "Work/plan.md" {
+		t.Errorf("scoped get_audit_log = %+v, want only Work/plan.md", entries)
+	}
+
+	checkToolError(t, callAudited(t, h, "get_audit_log", map[string]any{"since": "tomorrow"}), CodeInvalidParams)
+}
+
+func TestAuditWarning(t *testing.T) {
+	_, tmpDir := rootsHandlers(t)
+	blocker := filepath.Join(t.TempDir(), "blocker")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	v, err := vault.NewVault(tmpDir, vault.WithAuditLog(filepath.Join(blocker, "audit.log"), 0))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	h := NewHandlers(v, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	result := callAudited(t, h, "update_note", map[string]any{"path": "Work/plan.md", "content": "# New plan"})
+	if result.IsError {
+		t.Fatalf("update_note failed: %s", resultText(result))
+	}
+	last, ok := result.Content[len(result.Content)-1].(mcp.TextContent)
+	if !ok || !strings.HasPrefix(last.Text, "warning: ") || !strings.Contains(last.Text, "audit log") {
+		t.Errorf("last content = %+v, want an audit log warning", result.Content[len(result.Content)-1])
+	}
+}
@@ -0,0 +1,107 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// maxBatchPaths caps the number of notes read_notes accepts per call
+const maxBatchPaths = 20
+
+// defaultBatchMaxBytes caps the combined content returned by read_notes
+const defaultBatchMaxBytes = 256 << 10
+
+// batchEntry is one note in a read_notes result
+type batchEntry struct {
+	Path      string    `json:"path"`
+	Content   string    `json:"content,omitempty"`
+	Code      ErrorCode `json:"code,omitempty"` // Set with Error
+	Error     string    `json:"error,omitempty"`
+	Truncated bool      `json:"truncated,omitempty"` // Left out by the max_bytes budget
+}
+
+// ReadNotesTool returns the ServerTool for reading several notes at once.
+func (h *Handlers) ReadNotesTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"read_notes",
+		mcp.WithDescription(fmt.Sprintf("Read up to %d notes in one call. Returns a JSON array of {path, content, code, error, truncated} in request order; a note that cannot be read gets an error code and message without failing the others. Once the combined content would exceed max_bytes or the response size limit, the remaining notes are marked truncated and can be read separately.", maxBatchPaths)),
+		mcp.WithArray(
+			"paths",
+			mcp.Description("Paths to the note files (relative to vault root, must end with .md)."),
+			mcp.WithStringItems(),
+			mcp.MinItems(1),
+			mcp.MaxItems(maxBatchPaths),
+			mcp.Required(),
+		),
+		mcp.WithNumber(
+			"max_bytes",
+			mcp.Description("Maximum total size of returned content."),
+			mcp.DefaultNumber(defaultBatchMaxBytes),
+			mcp.Min(1),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleReadNotes,
+	}
+}
+
+// handleReadNotes implements the read_notes tool handler.
+func (h *Handlers) handleReadNotes(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	paths, err := request.RequireStringSlice("paths")
+	if err == nil && (len(paths) == 0 || len(paths) > maxBatchPaths) {
+		err = fmt.Errorf("expected 1 to %d paths, got %d", maxBatchPaths, len(paths))
+	}
+	if err != nil {
+		return invalidParamResult("paths", err), nil
+	}
+	maxBytes := max(request.GetInt("max_bytes", defaultBatchMaxBytes), 1)
+
+	// Call vault
+	notes, err := h.vault.ReadMany(ctx, paths, maxBytes)
+	if err != nil {
+		return vaultErrorResult(err, "reading notes", ""), nil
+	}
+
+	entries := make([]batchEntry, len(notes))
+	for i, note := range notes {
+		entries[i] = batchEntry{
+			Path:      note.Path,
+			Content:   note.Content,
+			Truncated: note.Truncated,
+		}
+		if note.Err != nil {
+			toolErr := vaultToolError(note.Err, "reading note", note.Path)
+			entries[i].Code = toolErr.Code
+			entries[i].Error = toolErr.Message
+		}
+	}
+
+	return fitJSON(len(entries), h.maxResponseBytes, func(n int) any {
+		return truncateEntries(entries, n)
+	})
+}
+
+// truncateEntries returns entries with the content of all but the first n
+// left out and marked truncated, as if the max_bytes budget ran out there
+func truncateEntries(entries []batchEntry, n int) []batchEntry {
+	if n == len(entries) {
+		return entries
+	}
+	truncated := slices.Clone(entries)
+	for i := n; i < len(truncated); i++ {
+		if truncated[i].Content != "" {
+			truncated[i].Content = ""
+			truncated[i].Truncated = true
+		}
+	}
+	return truncated
+}
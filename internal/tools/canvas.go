@@ -0,0 +1,44 @@
+package tools
+
+import (
+	"context"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ReadCanvasTool returns the ServerTool for reading an Obsidian canvas.
+func (h *Handlers) ReadCanvasTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"read_canvas",
+		mcp.WithDescription("Read an Obsidian .canvas file as structured JSON without layout: text cards with their markdown, file cards with resolved vault paths (missing files are flagged), link cards, group labels, and edges with from/to node IDs and labels."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Path to the canvas (relative to vault root, must end with .canvas)."),
+			mcp.Required(),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleReadCanvas,
+	}
+}
+
+// handleReadCanvas implements the read_canvas tool handler.
+func (h *Handlers) handleReadCanvas(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	path, err := request.RequireString("path")
+	if err != nil {
+		return missingParamResult("path", err), nil
+	}
+
+	// Call vault
+	canvas, err := h.vault.ReadCanvas(ctx, path)
+	if err != nil {
+		return vaultErrorResult(err, "reading canvas", path), nil
+	}
+
+	return jsonResult(canvas)
+}
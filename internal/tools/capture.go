@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// CaptureTool returns the ServerTool for jotting an entry into the inbox
+// note.
+func (h *Handlers) CaptureTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"capture",
+		mcp.WithDescription("Jot something down: append a timestamped entry such as \"- 14:32 Call the plumber #home\" under today's date heading of the inbox note, "+
+			"creating the note and the heading when missing. Use it instead of reading and rewriting the inbox with update_note. "+
+			"Returns the note path, the exact lines written and the line they start at."),
+		mcp.WithString(
+			"text",
+			mcp.Description("What to capture. Lines after the first are kept inside the entry."),
+			mcp.Required(),
+		),
+		mcp.WithArray(
+			"tags",
+			mcp.Description("Optional tags added to the entry as hashtags, e.g. [\"home\", \"todo\"]."),
+			mcp.WithStringItems(),
+		),
+		mcp.WithString(
+			"target",
+			mcp.Description("Optional note to capture into, relative to the vault root and ending in .md. Defaults to the server's inbox note, Inbox.md unless configured otherwise."),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleCapture,
+	}
+}
+
+// handleCapture implements the capture tool handler.
+func (h *Handlers) handleCapture(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	text, err := request.RequireString("text")
+	if err != nil {
+		return missingParamResult("text", err), nil
+	}
+
+	opts := vault.CaptureOptions{
+		Text:   text,
+		Tags:   request.GetStringSlice("tags", nil),
+		Target: request.GetString("target", ""),
+	}
+
+	// Call vault
+	result, err := h.vault.Capture(ctx, opts)
+	if err != nil {
+		return vaultErrorResult(err, "capturing", cmp.Or(opts.Target, "the inbox note")), nil
+	}
+
+	return jsonResult(result)
+}
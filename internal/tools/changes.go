@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// ChangedNotesTool returns the ServerTool for listing notes changed since
+// a point in time or an earlier call.
+func (h *Handlers) ChangedNotesTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"changed_notes",
+		mcp.WithDescription("List notes and canvases created, modified or deleted since a time or since an earlier call, with their SHA-256 content hash, for syncing. "+
+			"Pass the returned cursor to the next call. Deletions are only reported for cursors from recent calls to this server process; deletions_tracked says whether they were."),
+		mcp.WithString(
+			"since",
+			mcp.Description("Report notes created or modified after this point: a duration back from now (e.g. \"72h\", \"7d\"), a date (\"2024-03-01\") or an RFC3339 timestamp. If both since and cursor are empty, every note is reported as created."),
+		),
+		mcp.WithString(
+			"cursor",
+			mcp.Description("Cursor returned by a previous changed_notes call. Takes precedence over since."),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleChangedNotes,
+	}
+}
+
+// handleChangedNotes implements the changed_notes tool handler.
+func (h *Handlers) handleChangedNotes(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	opts := vault.ChangesOptions{Cursor: request.GetString("cursor", "")}
+	if since := request.GetString("since", ""); since != "" {
+		var err error
+		if opts.Since, err = parseTime(since, time.Now()); err != nil {
+			return invalidParamResult("since", err), nil
+		}
+	}
+
+	// Call vault
+	changes, err := h.vault.Changes(ctx, opts)
+	if err != nil {
+		return vaultErrorResult(err, "listing changed notes", ""), nil
+	}
+	changes.Changes = slices.DeleteFunc(changes.Changes, func(change vault.NoteChange) bool {
+		return !h.inRoots(change.Path)
+	})
+
+	return jsonResult(changes)
+}
@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// Bounds of the export_chunks parameters
+const (
+	minChunkSize   = 100
+	maxChunkSize   = 20000
+	maxChunksLimit = 2000
+)
+
+// ExportChunksTool returns the ServerTool for splitting notes into chunks
+// for embedding.
+func (h *Handlers) ExportChunksTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"export_chunks",
+		mcp.WithDescription("Split a note, or every note in a folder, into chunks for an embedding or retrieval pipeline. Chunks follow heading and paragraph boundaries; frontmatter and code blocks are never split. Each chunk has an id that stays the same while its text is unchanged, its text, the breadcrumb of headings above it, its line range and the note's tags. Folders are returned a page of whole notes at a time; pass next_cursor to continue."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Note (ending with .md) or folder, relative to vault root. If empty, chunks the whole vault."),
+		),
+		mcp.WithNumber(
+			"target_size",
+			mcp.Description("Target size of a chunk in characters. Longer paragraphs are split between lines; frontmatter and code blocks may exceed it."),
+			mcp.DefaultNumber(vault.DefaultChunkSize),
+			mcp.Min(minChunkSize),
+			mcp.Max(maxChunkSize),
+		),
+		mcp.WithNumber(
+			"overlap",
+			mcp.Description("Characters of closing lines repeated at the start of the next chunk of the same section, at most half of target_size."),
+			mcp.DefaultNumber(vault.DefaultChunkOverlap),
+			mcp.Min(0),
+		),
+		mcp.WithNumber(
+			"max_chunks",
+			mcp.Description("Maximum number of chunks per page of a folder. A page always holds whole notes, and at least one."),
+			mcp.DefaultNumber(vault.DefaultChunkLimit),
+			mcp.Min(1),
+			mcp.Max(maxChunksLimit),
+		),
+		mcp.WithString(
+			"cursor",
+			mcp.Description("next_cursor of the previous page, to continue a folder."),
+		),
+		mcp.WithBoolean(
+			"include_hidden",
+			mcp.Description("Whether to include files and folders whose name starts with a dot."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleExportChunks,
+	}
+}
+
+// handleExportChunks implements the export_chunks tool handler.
+func (h *Handlers) handleExportChunks(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	size := request.GetInt("target_size", vault.DefaultChunkSize)
+	if size < minChunkSize || size > maxChunkSize {
+		return invalidParamResult("target_size", fmt.Errorf("expected %d to %d, got %d", minChunkSize, maxChunkSize, size)), nil
+	}
+	overlap := request.GetInt("overlap", vault.DefaultChunkOverlap)
+	if overlap < 0 || overlap > size/2 {
+		return invalidParamResult("overlap", fmt.Errorf("expected 0 to %d, half of target_size, got %d", size/2, overlap)), nil
+	}
+	opts := vault.ChunkOptions{
+		Path:          request.GetString("path", ""),
+		Size:          size,
+		Overlap:       overlap,
+		Limit:         min(max(request.GetInt("max_chunks", vault.DefaultChunkLimit), 1), maxChunksLimit),
+		Cursor:        request.GetString("cursor", ""),
+		IncludeHidden: request.GetBool("include_hidden", false),
+	}
+
+	// Call vault
+	page, err := h.vault.Chunks(ctx, opts)
+	if err != nil {
+		return vaultErrorResult(err, "exporting chunks", opts.Path), nil
+	}
+
+	// Cut between notes, so the cursor of a cut page resumes after the
+	// last note returned
+	var ends []int
+	for i, c := range page.Chunks {
+		if i+1 == len(page.Chunks) || page.Chunks[i+1].Path != c.Path {
+			ends = append(ends, i+1)
+		}
+	}
+	return fitJSON(len(ends), h.maxResponseBytes, func(n int) any {
+		if n == len(ends) {
+			return page
+		}
+		n = max(n, 1)
+		cut := page
+		cut.Chunks = page.Chunks[:ends[n-1]]
+		cut.Notes = n
+		cut.NextCursor = vault.ChunkCursor(cut.Chunks[len(cut.Chunks)-1].Path)
+		return cut
+	})
+}
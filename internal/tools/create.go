@@ -3,9 +3,13 @@ package tools
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
 )
 
 // CreateNoteTool returns the ServerTool for creating a new note.
@@ -23,6 +27,23 @@ func (h *Handlers) CreateNoteTool() server.ServerTool {
 			mcp.Description("Content of the note in markdown format."),
 			mcp.Required(),
 		),
+		mcp.WithBoolean(
+			"sanitize",
+			mcp.Description("Clean up the path before creating: remove characters invalid on Windows (<>:\"|?*), collapse whitespace, trim trailing dots and spaces and normalize unicode. The final path is returned."),
+			mcp.DefaultBool(true),
+		),
+		mcp.WithString(
+			"on_similar",
+			mcp.Description("What to do when notes with a similar name already exist, such as \"kubernetes-setup.md\" for \"Kubernetes Setup.md\" or a note with that title or alias: \"error\" refuses and lists them so you can update one instead, \"warn\" creates the note and lists them, \"ignore\" does not look."),
+			mcp.Enum(string(vault.SimilarError), string(vault.SimilarWarn), string(vault.SimilarIgnore)),
+			mcp.DefaultString(string(vault.SimilarError)),
+		),
+		mcp.WithBoolean(
+			"dry_run",
+			mcp.Description("Validate the request and preview the result as a unified diff without writing anything."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithOutputSchema[WriteResult](),
 		mcp.WithReadOnlyHintAnnotation(false),
 		mcp.WithDestructiveHintAnnotation(false),
 	)
@@ -38,51 +59,103 @@ func (h *Handlers) handleCreateNote(ctx context.Context, request mcp.CallToolReq
 	// Extract parameters
 	path, err := request.RequireString("path")
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Missing required parameter 'path': %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return missingParamResult("path", err), nil
 	}
 
 	content, err := request.RequireString("content")
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Missing required parameter 'content': %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return missingParamResult("content", err), nil
+	}
+
+	// Normalize the path the model supplied
+	requested := path
+	if request.GetBool("sanitize", true) {
+		path, err = vault.SanitizePath(path)
+		if err != nil {
+			return vaultErrorResult(err, "creating note", requested), nil
+		}
+	}
+
+	mode, err := vault.ParseSimilarMode(request.GetString("on_similar", ""))
+	if err != nil {
+		return invalidParamResult("on_similar", err), nil
+	}
+
+	// Preview without writing
+	if request.GetBool("dry_run", false) {
+		if err := h.vault.ValidateCreate(ctx, path); err != nil {
+			return vaultErrorResult(err, "creating note", path), nil
+		}
+		similar, err := h.similarNotes(ctx, path, mode)
+		if err != nil {
+			return vaultErrorResult(err, "creating note", path), nil
+		}
+		content, err := h.vault.PrepareContent(path, content, true)
+		if err != nil {
+			return vaultErrorResult(err, "creating note", path), nil
+		}
+
+		return structuredResult(textResult(dryRunText(path, "", content)+similarWarning(similar)), h.createResult(path, requested, similar, true)), nil
 	}
 
 	// Call vault
-	err = h.vault.Create(ctx, path, content)
+	similar, err := h.similarNotes(ctx, path, mode)
+	if err == nil {
+		err = h.vault.Create(ctx, path, content)
+	}
+	if err != nil {
+		return vaultErrorResult(err, "creating note", path), nil
+	}
+
+	return structuredResult(textResult(h.withNoteURI(createdMessage(path, requested), path)+similarWarning(similar)), h.createResult(path, requested, similar, false)), nil
+}
+
+// createResult returns the WriteResult of creating path, asked for as
+// requested, next to the similar notes found
+func (h *Handlers) createResult(path, requested string, similar []vault.SimilarNote, dryRun bool) WriteResult {
+	result := h.newWriteResult(path, actionCreated, dryRun)
+	if requested != path {
+		result.RequestedPath = requested
+	}
+	result.Similar = similar
+	return result
+}
+
+// similarNotes looks for notes in the client's roots with a name close to
+// the new note at path. In SimilarError mode finding any is an error; in
+// SimilarWarn mode they are returned.
+func (h *Handlers) similarNotes(ctx context.Context, path string, mode vault.SimilarMode) ([]vault.SimilarNote, error) {
+	if mode == vault.SimilarIgnore {
+		return nil, nil
+	}
+	similar, err := h.vault.SimilarNotes(ctx, path)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: formatVaultError(err, "creating", path),
-				},
-			},
-			IsError: true,
-		}, nil
+		return nil, err
 	}
+	similar = slices.DeleteFunc(similar, func(note vault.SimilarNote) bool { return !h.inRoots(note.Path) })
+	if len(similar) > 0 && mode == vault.SimilarError {
+		return nil, &vault.SimilarNotesError{Path: path, Similar: similar}
+	}
+	return similar, nil
+}
 
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.TextContent{
-				Type: "text",
-				Text: fmt.Sprintf("Successfully created note: %s", path),
-			},
-		},
-		IsError: false,
-	}, nil
+// similarWarning lists the similar notes a note was created beside, or
+// is empty when there are none.
+func similarWarning(similar []vault.SimilarNote) string {
+	if len(similar) == 0 {
+		return ""
+	}
+	lines := []string{"\nWarning: notes with a similar name already exist:"}
+	for _, note := range similar {
+		lines = append(lines, fmt.Sprintf("- %s (%s %q)", note.Path, note.MatchedBy, note.Name))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// createdMessage reports the created path, noting when sanitizing changed it.
+func createdMessage(path, requested string) string {
+	if path == requested {
+		return fmt.Sprintf("Successfully created note: %s", path)
+	}
+	return fmt.Sprintf("Successfully created note: %s (sanitized from %q)", path, requested)
 }
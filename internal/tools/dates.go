@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateLayout is the layout of date-only parameters such as "2024-03-01"
+const dateLayout = "2006-01-02"
+
+// parseTime interprets a time parameter relative to now. It accepts an
+// RFC3339 timestamp, a date such as "2024-03-01" (midnight in now's time
+// zone), or a duration back from now such as "72h", "30d" or "2w". A
+// leading minus, as in "-30d", means the same as without it.
+func parseTime(value string, now time.Time) (time.Time, error) {
+	if ts, err := time.Parse(time.RFC3339, value); err == nil {
+		return ts, nil
+	}
+	if date, err := time.ParseInLocation(dateLayout, value, now.Location()); err == nil {
+		return date, nil
+	}
+
+	ago := strings.TrimPrefix(value, "-")
+	for suffix, days := range map[string]int{"d": 1, "w": 7} {
+		if count, ok := strings.CutSuffix(ago, suffix); ok {
+			n, err := strconv.Atoi(count)
+			if err != nil || n < 0 {
+				break
+			}
+			return now.AddDate(0, 0, -n*days), nil
+		}
+	}
+
+	d, err := time.ParseDuration(ago)
+	if err != nil || d < 0 {
+		return time.Time{}, fmt.Errorf("expected a duration like \"72h\", \"7d\" or \"-30d\", a date like \"2024-03-01\", or an RFC3339 timestamp, got %q", value)
+	}
+
+	return now.Add(-d), nil
+}
+
+// parseDuration interprets a length of time such as "8h", "90m", "2d" or
+// "1w"; days are 24 hours. It must be positive.
+func parseDuration(value string) (time.Duration, error) {
+	for suffix, days := range map[string]int{"d": 1, "w": 7} {
+		if count, ok := strings.CutSuffix(value, suffix); ok {
+			n, err := strconv.Atoi(count)
+			if err != nil || n <= 0 {
+				break
+			}
+			return time.Duration(n*days) * 24 * time.Hour, nil
+		}
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("expected a positive duration like \"8h\", \"90m\" or \"2d\", got %q", value)
+	}
+
+	return d, nil
+}
@@ -0,0 +1,96 @@
+package tools
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "72h", want: now.Add(-72 * time.Hour)},
+		{value: "-72h", want: now.Add(-72 * time.Hour)},
+		{value: "90m", want: now.Add(-90 * time.Minute)},
+		{value: "7d", want: time.Date(2024, 6, 8, 12, 0, 0, 0, time.UTC)},
+		{value: "-30d", want: time.Date(2024, 5, 16, 12, 0, 0, 0, time.UTC)},
+		{value: "2w", want: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)},
+		{value: "0d", want: now},
+		{value: "2024-03-01", want: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{value: "2024-03-01T08:30:00+02:00", want: time.Date(2024, 3, 1, 6, 30, 0, 0, time.UTC)},
+		{value: "", wantErr: true},
+		{value: "March", wantErr: true},
+		{value: "xd", wantErr: true},
+		{value: "--7d", wantErr: true},
+		{value: "7y", wantErr: true},
+		{value: "2024-13-01", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseTime(tt.value, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseTime(%q) = %v, want error", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseTime(%q) error = %v", tt.value, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseTime(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTimeLocalDate(t *testing.T) {
+	// Dates are midnight in the caller's time zone
+	loc := time.FixedZone("UTC+5", 5*60*60)
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, loc)
+
+	got, err := parseTime("2024-03-01", now)
+	if err != nil {
+		t.Fatalf("parseTime() error = %v", err)
+	}
+	if want := time.Date(2024, 3, 1, 0, 0, 0, 0, loc); !got.Equal(want) {
+		t.Errorf("parseTime() = %v, want %v", got, want)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "8h", want: 8 * time.Hour},
+		{value: "90m", want: 90 * time.Minute},
+		{value: "2d", want: 48 * time.Hour},
+		{value: "1w", want: 7 * 24 * time.Hour},
+		{value: "", wantErr: true},
+		{value: "0d", wantErr: true},
+		{value: "-8h", wantErr: true},
+		{value: "soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseDuration(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseDuration(%q) = %v, want error", tt.value, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("parseDuration(%q) = %v, %v; want %v", tt.value, got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,303 @@
+package tools
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Diff output limits
+const (
+	diffContextLines = 3   // Unchanged lines shown around each change
+	maxDiffLines     = 300 // Diff lines returned before truncating
+)
+
+// editOp is a single line operation in an edit script
+type editOp struct {
+	kind byte // ' ' keep, '-' delete, '+' insert
+	line string
+}
+
+// lineDiff is a unified diff together with the size of the change
+type lineDiff struct {
+	text      string
+	added     int  // Lines only in the new text
+	removed   int  // Lines only in the old text
+	truncated bool // text stops after maxDiffLines lines
+}
+
+// unifiedDiff returns a unified diff turning oldText into newText, labelled
+// with name. The output is truncated after maxDiffLines lines.
+// Returns an empty string when the texts are identical.
+func unifiedDiff(name, oldText, newText string) string {
+	return computeDiff(name, oldText, newText).text
+}
+
+// computeDiff returns the unified diff of unifiedDiff with the number of
+// lines added and removed, which count the whole change even when the
+// text is truncated
+func computeDiff(name, oldText, newText string) lineDiff {
+	if oldText == newText {
+		return lineDiff{}
+	}
+
+	ops := diffLines(splitLines(oldText), splitLines(newText))
+
+	var diff lineDiff
+	for _, op := range ops {
+		switch op.kind {
+		case '+':
+			diff.added++
+		case '-':
+			diff.removed++
+		}
+	}
+
+	var out []string
+	out = append(out, "--- a/"+name, "+++ b/"+name)
+
+	// Group operations into hunks with surrounding context
+	oldLine, newLine := 1, 1
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			oldLine++
+			newLine++
+			continue
+		}
+
+		// Start hunk with leading context; hunks are separated by more
+		// than twice the context, so this never overlaps the previous one
+		start := max(i-diffContextLines, 0)
+		hunkOld := oldLine - (i - start)
+		hunkNew := newLine - (i - start)
+
+		// Extend hunk until a run of unchanged lines longer than twice the context
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			run := 0
+			for end+run < len(ops) && ops[end+run].kind == ' ' {
+				run++
+			}
+			if end+run == len(ops) || run > 2*diffContextLines {
+				end += min(run, diffContextLines)
+				break
+			}
+			end += run
+		}
+
+		var oldCount, newCount int
+		var body []string
+		for _, op := range ops[start:end] {
+			switch op.kind {
+			case ' ':
+				oldCount++
+				newCount++
+			case '-':
+				oldCount++
+			case '+':
+				newCount++
+			}
+			body = append(body, string(op.kind)+op.line)
+		}
+
+		out = append(out, fmt.Sprintf("@@ -%d,%d +%d,%d @@", hunkOld, oldCount, hunkNew, newCount))
+		out = append(out, body...)
+
+		// Advance line counters past the hunk
+		for _, op := range ops[i:end] {
+			if op.kind != '+' {
+				oldLine++
+			}
+			if op.kind != '-' {
+				newLine++
+			}
+		}
+		i = end
+	}
+
+	if len(out) > maxDiffLines {
+		omitted := len(out) - maxDiffLines
+		out = append(out[:maxDiffLines], fmt.Sprintf("... diff truncated, %d more lines", omitted))
+		diff.truncated = true
+	}
+
+	diff.text = strings.Join(out, "\n")
+	return diff
+}
+
+// dryRunText describes the change a write would make without performing it.
+func dryRunText(path, oldContent, newContent string) string {
+	diff := unifiedDiff(path, oldContent, newContent)
+	if diff == "" {
+		return fmt.Sprintf("Dry run — no changes made. Content of %s is unchanged.", path)
+	}
+	return fmt.Sprintf("Dry run — no changes made. Diff for %s:\n\n%s", path, diff)
+}
+
+// splitLines splits text into lines without their terminators
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines computes a shortest edit script between a and b using the
+// linear space variant of the Myers O(ND) algorithm, so memory stays
+// proportional to the input however much of it changed. Within each run of
+// changes, deletions come before insertions.
+func diffLines(a, b []string) []editOp {
+	ops := make([]editOp, 0, len(a)+len(b))
+	ops = diffRange(ops, a, b)
+
+	// Order each run of changes as a unified diff shows it
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		end := i
+		for end < len(ops) && ops[end].kind != ' ' {
+			end++
+		}
+		slices.SortStableFunc(ops[i:end], func(x, y editOp) int {
+			return cmp.Compare(y.kind, x.kind) // '-' before '+'
+		})
+		i = end
+	}
+
+	return ops
+}
+
+// diffRange appends an edit script turning a into b to ops. Common lines
+// at either end are matched directly; the rest is split at the middle
+// snake of a shortest edit script and each half diffed in turn.
+func diffRange(ops []editOp, a, b []string) []editOp {
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		ops = append(ops, editOp{kind: ' ', line: a[0]})
+		a, b = a[1:], b[1:]
+	}
+	common := 0
+	for common < len(a) && common < len(b) && a[len(a)-1-common] == b[len(b)-1-common] {
+		common++
+	}
+	suffix := a[len(a)-common:]
+	a, b = a[:len(a)-common], b[:len(b)-common]
+
+	switch {
+	case len(a) == 0:
+		for _, line := range b {
+			ops = append(ops, editOp{kind: '+', line: line})
+		}
+	case len(b) == 0:
+		for _, line := range a {
+			ops = append(ops, editOp{kind: '-', line: line})
+		}
+	default:
+		x, y, ok := middleSnake(a, b)
+		if !ok || x+y == 0 || (x == len(a) && y == len(b)) {
+			// No common line: replace all of a
+			ops = diffRange(ops, a, nil)
+			ops = diffRange(ops, nil, b)
+			break
+		}
+		ops = diffRange(ops, a[:x], b[:y])
+		ops = diffRange(ops, a[x:], b[y:])
+	}
+
+	for _, line := range suffix {
+		ops = append(ops, editOp{kind: ' ', line: line})
+	}
+	return ops
+}
+
+// middleSnake searches a shortest edit script between a and b from both
+// ends at once and returns the point where the two searches meet, which
+// splits the script into two of about half its length each. Only two
+// arrays proportional to len(a)+len(b) are kept.
+func middleSnake(a, b []string) (x, y int, ok bool) {
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	forward := make([]int, 2*maxD+2)
+	backward := make([]int, 2*maxD+2)
+	for i := range forward {
+		forward[i], backward[i] = -1, -1
+	}
+	forward[offset+1], backward[offset+1] = 0, 0
+
+	delta := n - m
+	odd := delta%2 != 0 // The forward search meets the backward one
+
+	// Diagonals leaving the grid are trimmed from the next steps
+	var fStart, fEnd, bStart, bEnd int
+	for d := 0; d < maxD; d++ {
+		for k := -d + fStart; k <= d-fEnd; k += 2 {
+			i := offset + k
+			var x int
+			if k == -d || (k != d && forward[i-1] < forward[i+1]) {
+				x = forward[i+1] // Move down: insertion
+			} else {
+				x = forward[i-1] + 1 // Move right: deletion
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			forward[i] = x
+
+			switch {
+			case x > n:
+				fEnd += 2
+			case y > m:
+				fStart += 2
+			case odd:
+				j := offset + delta - k
+				if j >= 0 && j < len(backward) && backward[j] != -1 && x >= n-backward[j] {
+					return x, y, true
+				}
+			}
+		}
+
+		// The backward search counts x and y from the ends of a and b
+		for k := -d + bStart; k <= d-bEnd; k += 2 {
+			i := offset + k
+			var x int
+			if k == -d || (k != d && backward[i-1] < backward[i+1]) {
+				x = backward[i+1]
+			} else {
+				x = backward[i-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[n-1-x] == b[m-1-y] {
+				x++
+				y++
+			}
+			backward[i] = x
+
+			switch {
+			case x > n:
+				bEnd += 2
+			case y > m:
+				bStart += 2
+			case !odd:
+				j := offset + delta - k
+				if j >= 0 && j < len(forward) && forward[j] != -1 {
+					fx := forward[j]
+					if fx >= n-x {
+						return fx, fx - (j - offset), true
+					}
+				}
+			}
+		}
+	}
+
+	return 0, 0, false
+}
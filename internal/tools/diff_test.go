@@ -0,0 +1,152 @@
+package tools
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	t.Run("identical", func(t *testing.T) {
+		if got := unifiedDiff("note.md", "same\n", "same\n"); got != "" {
+			t.Errorf("unifiedDiff() = %q, want empty", got)
+		}
+	})
+
+	t.Run("single change", func(t *testing.T) {
+		got := unifiedDiff("note.md", "a\nb\nc\n", "a\nB\nc\n")
+		want := strings.Join([]string{
+			"--- a/note.md",
+			"+++ b/note.md",
+			"@@ -1,3 +1,3 @@",
+			" a",
+			"-b",
+			"+B",
+			" c",
+		}, "\n")
+		if got != want {
+			t.Errorf("unifiedDiff() =\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	t.Run("separate hunks", func(t *testing.T) {
+		var oldLines, newLines []string
+		for i := 1; i <= 20; i++ {
+			oldLines = append(oldLines, fmt.Sprintf("line %d", i))
+			newLines = append(newLines, fmt.Sprintf("line %d", i))
+		}
+		newLines[1] = "changed 2"
+		newLines[17] = "changed 18"
+
+		got := unifiedDiff("note.md", strings.Join(oldLines, "\n"), strings.Join(newLines, "\n"))
+		if !strings.Contains(got, "@@ -1,5 +1,5 @@") {
+			t.Errorf("Missing first hunk header:\n%s", got)
+		}
+		if !strings.Contains(got, "@@ -15,6 +15,6 @@") {
+			t.Errorf("Missing second hunk header:\n%s", got)
+		}
+	})
+
+	t.Run("new content", func(t *testing.T) {
+		got := unifiedDiff("note.md", "", "one\ntwo\n")
+		if !strings.Contains(got, "@@ -1,0 +1,2 @@\n+one\n+two") {
+			t.Errorf("unifiedDiff() =\n%s", got)
+		}
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		var lines []string
+		for i := 0; i < maxDiffLines*2; i++ {
+			lines = append(lines, fmt.Sprintf("line %d", i))
+		}
+
+		got := unifiedDiff("note.md", "", strings.Join(lines, "\n"))
+		if !strings.Contains(got, "diff truncated") {
+			t.Error("Expected truncation notice")
+		}
+		if n := strings.Count(got, "\n") + 1; n != maxDiffLines+1 {
+			t.Errorf("Diff has %d lines, want %d", n, maxDiffLines+1)
+		}
+
+		diff := computeDiff("note.md", "", strings.Join(lines, "\n"))
+		if !diff.truncated || diff.added != maxDiffLines*2 || diff.removed != 0 {
+			t.Errorf("computeDiff() = %d added, %d removed, truncated %v; want the whole change counted", diff.added, diff.removed, diff.truncated)
+		}
+	})
+}
+
+func TestDiffLines(t *testing.T) {
+	// Edit scripts rebuild both texts and keep a longest common subsequence
+	rng := rand.New(rand.NewPCG(1, 2))
+	for range 500 {
+		a := make([]string, rng.IntN(12))
+		b := make([]string, rng.IntN(12))
+		for i := range a {
+			a[i] = string(rune('a' + rng.IntN(4)))
+		}
+		for i := range b {
+			b[i] = string(rune('a' + rng.IntN(4)))
+		}
+
+		var gotA, gotB []string
+		kept := 0
+		for _, op := range diffLines(a, b) {
+			if op.kind != '+' {
+				gotA = append(gotA, op.line)
+			}
+			if op.kind != '-' {
+				gotB = append(gotB, op.line)
+			}
+			if op.kind == ' ' {
+				kept++
+			}
+		}
+		if !slices.Equal(gotA, a) || !slices.Equal(gotB, b) {
+			t.Fatalf("diffLines(%q, %q) rebuilds %q and %q", a, b, gotA, gotB)
+		}
+		if want := lcsLength(a, b); kept != want {
+			t.Fatalf("diffLines(%q, %q) keeps %d lines, want %d", a, b, kept, want)
+		}
+	}
+}
+
+func TestDiffLinesMemory(t *testing.T) {
+	// A full rewrite of a large note must not need memory quadratic in its length
+	var oldLines, newLines []string
+	for i := range 5000 {
+		oldLines = append(oldLines, fmt.Sprintf("old line %d", i))
+		newLines = append(newLines, fmt.Sprintf("new line %d", i))
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	ops := diffLines(oldLines, newLines)
+	runtime.ReadMemStats(&after)
+
+	if len(ops) != 10000 {
+		t.Errorf("diffLines() = %d ops, want 10000", len(ops))
+	}
+	if alloc := after.TotalAlloc - before.TotalAlloc; alloc > 16<<20 {
+		t.Errorf("diffLines() allocated %d bytes, want at most 16 MiB", alloc)
+	}
+}
+
+// lcsLength returns the length of a longest common subsequence of a and b
+func lcsLength(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,178 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// digestManifest lists how read_tagged_notes included each matching note
+type digestManifest struct {
+	Full      []string          `json:"full"`
+	Truncated []string          `json:"truncated"`        // Only their excerpt is included
+	Omitted   []string          `json:"omitted"`          // Left out by the budget or unreadable
+	Errors    map[string]string `json:"errors,omitempty"` // Path to read error of omitted notes
+	Bytes     int               `json:"bytes"`            // Total size of the included content
+	MaxBytes  int               `json:"max_total_bytes"`  // Budget the content was fitted to
+}
+
+// ReadTaggedNotesTool returns the ServerTool for reading every note with
+// some tags as one digest.
+func (h *Handlers) ReadTaggedNotesTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"read_tagged_notes",
+		mcp.WithDescription("Read every note with the given tags in one call, as a single digest. Each note is a header comment with its path, tags, modification time and status, "+
+			"its content, and a closing comment. When the notes exceed max_total_bytes some are included as an excerpt (status truncated) or left out (status omitted); "+
+			"a second block holds a JSON manifest listing the notes included in full, truncated and omitted."),
+		mcp.WithArray(
+			"tags",
+			mcp.Description("Notes must have at least one of these tags. Same as tags_any."),
+			mcp.WithStringItems(),
+		),
+		mcp.WithArray(
+			"tags_any",
+			mcp.Description("Notes must have at least one of these tags."),
+			mcp.WithStringItems(),
+		),
+		mcp.WithArray(
+			"tags_all",
+			mcp.Description("Notes must have all of these tags."),
+			mcp.WithStringItems(),
+		),
+		mcp.WithArray(
+			"tags_none",
+			mcp.Description("Notes with any of these tags are excluded."),
+			mcp.WithStringItems(),
+		),
+		mcp.WithString(
+			"path",
+			mcp.Description("Only read notes in this folder (relative to vault root)."),
+		),
+		mcp.WithString(
+			"order",
+			mcp.Description("Order of the notes, which is also the order in which they get the budget."),
+			mcp.Enum(string(vault.DigestModifiedDesc), string(vault.DigestModifiedAsc), string(vault.DigestCreatedDesc), string(vault.DigestCreatedAsc), string(vault.DigestPath)),
+			mcp.DefaultString(string(vault.DigestModifiedDesc)),
+		),
+		mcp.WithString(
+			"mode",
+			mcp.Description("How to spend the budget: depth includes notes in full while they fit, then excerpts; breadth first includes an excerpt of every note, then upgrades notes to full content while the budget lasts."),
+			mcp.Enum(string(vault.DigestDepth), string(vault.DigestBreadth)),
+			mcp.DefaultString(string(vault.DigestDepth)),
+		),
+		mcp.WithNumber(
+			"max_total_bytes",
+			mcp.Description("Maximum total size of the included note content. Capped by the server's response size limit, if any."),
+			mcp.DefaultNumber(vault.DefaultDigestMaxBytes),
+			mcp.Min(1),
+		),
+		mcp.WithNumber(
+			"excerpt_length",
+			mcp.Description("Length in characters of the excerpt of a truncated note: its first paragraph, cut on a word boundary."),
+			mcp.DefaultNumber(vault.DefaultPreviewLength),
+			mcp.Min(1),
+			mcp.Max(vault.MaxPreviewLength),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleReadTaggedNotes,
+	}
+}
+
+// handleReadTaggedNotes implements the read_tagged_notes tool handler.
+func (h *Handlers) handleReadTaggedNotes(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	opts := vault.DigestOptions{
+		Subpath:       request.GetString("path", ""),
+		TagsAny:       append(request.GetStringSlice("tags", nil), request.GetStringSlice("tags_any", nil)...),
+		TagsAll:       request.GetStringSlice("tags_all", nil),
+		TagsNone:      request.GetStringSlice("tags_none", nil),
+		MaxBytes:      max(request.GetInt("max_total_bytes", vault.DefaultDigestMaxBytes), 1),
+		ExcerptLength: min(max(request.GetInt("excerpt_length", vault.DefaultPreviewLength), 1), vault.MaxPreviewLength),
+	}
+	if len(opts.TagsAny) == 0 && len(opts.TagsAll) == 0 {
+		return invalidParamResult("tags", errors.New("set tags, tags_any or tags_all")), nil
+	}
+	if h.maxResponseBytes > 0 {
+		opts.MaxBytes = min(opts.MaxBytes, h.maxResponseBytes)
+	}
+
+	var err error
+	if opts.Order, err = vault.ParseDigestOrder(request.GetString("order", "")); err != nil {
+		return invalidParamResult("order", err), nil
+	}
+	if opts.Mode, err = vault.ParseDigestMode(request.GetString("mode", "")); err != nil {
+		return invalidParamResult("mode", err), nil
+	}
+
+	// Call vault
+	digest, err := h.vault.ReadDigest(ctx, opts)
+	if err != nil {
+		return vaultErrorResult(err, "reading tagged notes", opts.Subpath), nil
+	}
+
+	manifest := digestManifest{
+		Full:      []string{},
+		Truncated: []string{},
+		Omitted:   []string{},
+		Bytes:     digest.Bytes,
+		MaxBytes:  opts.MaxBytes,
+	}
+	var b strings.Builder
+	for _, note := range digest.Notes {
+		switch note.Status {
+		case vault.DigestFull:
+			manifest.Full = append(manifest.Full, note.Path)
+		case vault.DigestTruncated:
+			manifest.Truncated = append(manifest.Truncated, note.Path)
+		default:
+			manifest.Omitted = append(manifest.Omitted, note.Path)
+			if note.Error != "" {
+				if manifest.Errors == nil {
+					manifest.Errors = make(map[string]string)
+				}
+				manifest.Errors[note.Path] = note.Error
+			}
+			continue
+		}
+		writeDigestNote(&b, note)
+	}
+
+	text := b.String()
+	if len(digest.Notes) == 0 {
+		text = "No notes match the tag filters."
+	}
+
+	// Keep the digest as the first block and the manifest after it
+	result, err := jsonResult(manifest)
+	if err != nil {
+		return nil, err
+	}
+	result.Content = append([]mcp.Content{mcp.NewTextContent(text)}, result.Content...)
+	return result, nil
+}
+
+// writeDigestNote appends note to a digest between delimiting comments
+func writeDigestNote(b *strings.Builder, note vault.DigestNote) {
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(b, "<!-- note: %s | tags: %s | modified: %s | %s -->\n",
+		note.Path, strings.Join(note.Tags, ", "), note.Modified.UTC().Format(time.RFC3339), note.Status)
+	b.WriteString(note.Content)
+	if !strings.HasSuffix(note.Content, "\n") {
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(b, "<!-- end note: %s -->\n", note.Path)
+}
@@ -1,31 +1,328 @@
 package tools
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/kratos/mcp-notes/internal/vault"
 )
 
+// ErrorCode is a stable, machine-readable identifier for a failed tool call.
+// Clients may branch on it; the accompanying message is for humans and may
+// change between releases.
+type ErrorCode string
+
+// Error codes returned in the "code" field of a failed tool call.
+const (
+	CodeInvalidParams ErrorCode = "INVALID_PARAMS"    // A parameter is missing or malformed
+	CodePathTraversal ErrorCode = "PATH_TRAVERSAL"    // The path leaves the vault
+	CodeInvalidPath   ErrorCode = "INVALID_PATH"      // The path is malformed
+	CodeNotMarkdown   ErrorCode = "NOT_MARKDOWN"      // A note path does not end in .md
+	CodeNotCanvas     ErrorCode = "NOT_CANVAS"        // A canvas path does not end in .canvas
+	CodeNotAttachment ErrorCode = "NOT_ATTACHMENT"    // The file type is not an allowed attachment
+	CodeNotImage      ErrorCode = "NOT_IMAGE"         // The attachment is not an image that can be returned
+	CodeReservedPath  ErrorCode = "RESERVED_PATH"     // The path holds server data such as backups
+	CodeNotFound      ErrorCode = "NOT_FOUND"         // The note, folder, attachment, canvas or version does not exist
+	CodeAlreadyExists ErrorCode = "ALREADY_EXISTS"    // A note or folder is already at the path
+	CodeSimilarExists ErrorCode = "SIMILAR_EXISTS"    // Notes with a name close to the new note's exist
+	CodeAmbiguous     ErrorCode = "AMBIGUOUS_NAME"    // A note name matches several notes
+	CodeRateLimited   ErrorCode = "RATE_LIMITED"      // A write limit was reached
+	CodeReadOnly      ErrorCode = "READ_ONLY"         // The write policy or file permissions protect the path
+	CodeOutsideRoots  ErrorCode = "OUTSIDE_ROOTS"     // The path is outside the client's MCP roots
+	CodeNotUTF8       ErrorCode = "NOT_UTF8"          // The note cannot be decoded
+	CodeInvalidCanvas ErrorCode = "INVALID_CANVAS"    // The canvas is not valid JSON Canvas
+	CodeTooLarge      ErrorCode = "TOO_LARGE"         // The file exceeds a size limit
+	CodeCancelled     ErrorCode = "CANCELLED"         // The call was cancelled or timed out
+	CodeNotConfigured ErrorCode = "NOT_CONFIGURED"    // The server was started without a required option
+	CodeSchema        ErrorCode = "SCHEMA_VIOLATION"  // The frontmatter breaks the vault's schema
+	CodeConflict      ErrorCode = "CONFLICT"          // The note changed since the revision the call expected
+	CodeLocked        ErrorCode = "LOCKED"            // Another client holds a lock on the note
+	CodeAuditFailed   ErrorCode = "AUDIT_FAILED"      // The change could not be recorded in the audit log
+	CodeUnavailable   ErrorCode = "VAULT_UNAVAILABLE" // The vault directory cannot be reached
+	CodeInternal      ErrorCode = "INTERNAL_ERROR"    // Any other failure
+)
+
 // Error message constants
 const (
 	errMsgPathTraversal = "Invalid path: path traversal not allowed"
 	errMsgInvalidPath   = "Invalid path format"
 	errMsgNotMarkdown   = "Only .md files are allowed"
+	errMsgReservedPath  = "Invalid path: reserved for server data"
+	errMsgNotAttachment = "Not an allowed attachment type"
+	errMsgNotCanvas     = "Only .canvas files are allowed"
 )
 
-// formatVaultError converts vault errors to user-friendly messages
-func formatVaultError(err error, operation, path string) string {
+// Hints suggesting how to recover from common errors
+const (
+	hintNotePath     = "Use a path relative to the vault root ending in .md, e.g. \"Projects/plan.md\"."
+	hintFindNote     = "Use list_notes, search_notes or resolve_note to find the right path."
+	hintUseUpdate    = "Use update_note to change an existing note, or choose another path."
+	hintUsePath      = "Pass one of the candidate paths as 'path' instead of 'name'."
+	hintRestart      = "Do not retry; the limit resets when the server restarts."
+	hintRetryLater   = "Wait for the retry period before writing again."
+	hintReadOnly     = "Write to a folder allowed by the server's --writable and --read-only settings."
+	hintDirectory    = "Omit the path to cover the whole vault, or use list_notes to see its folders."
+	hintLocked       = "Wait for the lock to expire or its holder to call unlock_note, or retry with force=true if that client is gone."
+	hintQueryPattern = "A Go regular expression such as \"kube(rnetes)?\"; escape ( [ . and other special characters with \\, or use match_mode=literal."
+)
+
+// ToolError is the payload of a failed tool call. It is returned as JSON in
+// the result text and as structured content, with the result marked as an
+// error so the model can read it and correct the call.
+type ToolError struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+	Hint    string    `json:"hint,omitempty"`
+}
+
+// Error implements the error interface.
+func (e ToolError) Error() string {
+	return e.Message
+}
+
+// errorDetails is the machine-readable detail of a failed tool call, so
+// the model can correct the call without parsing the message. Only the
+// fields that apply to the error are set.
+type errorDetails struct {
+	Param      string              `json:"param,omitempty"`        // Parameter whose value was rejected
+	Allowed    []string            `json:"allowed,omitempty"`      // Accepted values, such as file extensions
+	Path       string              `json:"path,omitempty"`         // Rejected path, cleaned and relative to the vault root
+	DidYouMean []string            `json:"did_you_mean,omitempty"` // Existing notes with similar paths
+	Fragment   string              `json:"fragment,omitempty"`     // Part of a pattern the regexp parser rejected
+	Position   *int                `json:"position,omitempty"`     // Byte offset of fragment in the pattern
+	Existing   *existingNote       `json:"existing,omitempty"`     // The note already at the path
+	Reason     string              `json:"reason,omitempty"`       // Why a path is read-only: write_policy or file_permissions
+	Similar    []vault.SimilarNote `json:"similar,omitempty"`      // Existing notes with a name close to the new note's
+}
+
+// existingNote describes the note in the way of a new one, so the model
+// can decide to update it instead
+type existingNote struct {
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+// detailedErrorResult is a ToolError with its details
+type detailedErrorResult struct {
+	ToolError
+	Details errorDetails `json:"details"`
+}
+
+// vaultErrorDetails returns the details of a vault error, and false when
+// it has none
+func vaultErrorDetails(err error) (errorDetails, bool) {
+	var notFoundErr *vault.NoteNotFoundError
+	var existsErr *vault.NoteExistsError
+	var patternErr *vault.PatternError
+	var typeErr *vault.UnknownNoteTypeError
+	var similarErr *vault.SimilarNotesError
+
+	switch {
+	case errors.As(err, &notFoundErr):
+		return errorDetails{Path: notFoundErr.Path, DidYouMean: notFoundErr.Suggestions}, true
+	case errors.As(err, &existsErr):
+		return errorDetails{Path: existsErr.Path, Existing: &existingNote{Size: existsErr.Size, Modified: existsErr.Modified.UTC()}}, true
+	case errors.As(err, &similarErr):
+		return errorDetails{Path: similarErr.Path, Similar: similarErr.Similar}, true
+	case errors.Is(err, vault.ErrPathTraversal):
+		path, ok := strings.CutPrefix(err.Error(), vault.ErrPathTraversal.Error()+": ")
+		return errorDetails{Path: path}, ok
+	case errors.Is(err, vault.ErrPermissionDenied):
+		_, path, _ := strings.Cut(err.Error(), vault.ErrPermissionDenied.Error()+": ")
+		return errorDetails{Path: path, Reason: "file_permissions"}, true
+	case errors.Is(err, vault.ErrReadOnly):
+		return errorDetails{Reason: "write_policy"}, true
+	case errors.Is(err, vault.ErrNotMarkdown):
+		return errorDetails{Allowed: []string{".md"}}, true
+	case errors.Is(err, vault.ErrNotAttachment):
+		return errorDetails{Allowed: vault.AttachmentExtensions()}, true
+	case errors.As(err, &patternErr):
+		details := errorDetails{Param: patternErr.Name()}
+		if patternErr.Fragment != "" {
+			details.Fragment, details.Position = patternErr.Fragment, &patternErr.Position
+		}
+		return details, true
+	case errors.As(err, &typeErr):
+		return errorDetails{Param: "type", Allowed: typeErr.Types}, true
+	}
+	return errorDetails{}, false
+}
+
+// vaultToolError classifies a vault error and converts it to a
+// user-friendly message. operation describes what was attempted, e.g.
+// "reading note".
+func vaultToolError(err error, operation, path string) ToolError {
+	var dirErr *vault.DirectoryNotFoundError
+	var ambiguousErr *vault.AmbiguousNoteError
+	var rateErr *vault.RateLimitError
+	var patternErr *vault.PatternError
+	var lockedErr *vault.LockedError
+
 	switch {
+	case errors.Is(err, vault.ErrVaultUnavailable):
+		return ToolError{CodeUnavailable, "The vault is not accessible: is the drive mounted?", "Retry once the vault's folder is back, e.g. the drive is mounted again; call reconnect_vault to see what changed meanwhile."}
 	case errors.Is(err, vault.ErrNoteNotFound):
-		return fmt.Sprintf("Note not found: %s", path)
+		return ToolError{CodeNotFound, fmt.Sprintf("Note not found: %s", path), hintFindNote}
+	case errors.Is(err, vault.ErrAttachmentNotFound):
+		return ToolError{CodeNotFound, fmt.Sprintf("Attachment not found: %s", path), "Use list_attachments to find the right path."}
+	case errors.Is(err, vault.ErrCanvasNotFound):
+		return ToolError{CodeNotFound, fmt.Sprintf("Canvas not found: %s", path), "Use search_notes with include_canvas to find the right path."}
+	case errors.Is(err, vault.ErrSectionNotFound):
+		return ToolError{CodeNotFound, fmt.Sprintf("Section not found: %s", strings.TrimPrefix(err.Error(), vault.ErrSectionNotFound.Error()+": ")), "Use analyze_note to list the headings and block IDs of the note."}
+	case errors.Is(err, vault.ErrAliasNotFound):
+		return ToolError{CodeNotFound, fmt.Sprintf("Alias not found: %s", strings.TrimPrefix(err.Error(), vault.ErrAliasNotFound.Error()+": ")), "Pass one of the aliases the note has."}
+	case errors.Is(err, vault.ErrVersionNotFound):
+		return ToolError{CodeNotFound, fmt.Sprintf("Version not found for note: %s", path), "Use list_note_versions to see the available versions."}
+	case errors.Is(err, vault.ErrInvalidCanvas):
+		return ToolError{CodeInvalidCanvas, fmt.Sprintf("Invalid canvas %s: %s", path, strings.TrimPrefix(err.Error(), vault.ErrInvalidCanvas.Error()+": ")), ""}
+	case errors.As(err, &dirErr):
+		msg := fmt.Sprintf("Directory not found: %s", dirErr.Path)
+		if len(dirErr.Suggestions) > 0 {
+			msg += fmt.Sprintf(". Did you mean: %s?", strings.Join(dirErr.Suggestions, ", "))
+		}
+		return ToolError{CodeNotFound, msg, hintDirectory}
+	case errors.Is(err, vault.ErrDirectoryNotFound):
+		return ToolError{CodeNotFound, fmt.Sprintf("Directory not found: %s", path), hintDirectory}
+	case errors.As(err, &ambiguousErr):
+		candidates := make([]string, len(ambiguousErr.Candidates))
+		for i, c := range ambiguousErr.Candidates {
+			candidates[i] = fmt.Sprintf("%s (%s)", c.Path, c.MatchedBy)
+		}
+		return ToolError{CodeAmbiguous, fmt.Sprintf("Ambiguous note name %q matches: %s. Use a path instead", ambiguousErr.Name, strings.Join(candidates, ", ")), hintUsePath}
+	case errors.Is(err, vault.ErrAmbiguousNote):
+		return ToolError{CodeAmbiguous, fmt.Sprintf("Ambiguous note name %q. Use a path instead", path), hintUsePath}
+	case errors.Is(err, vault.ErrNoteExists):
+		return ToolError{CodeAlreadyExists, fmt.Sprintf("Note already exists: %s", path), hintUseUpdate}
+	case errors.Is(err, vault.ErrSimilarNote):
+		return ToolError{CodeSimilarExists, fmt.Sprintf("Not created: %s", strings.TrimPrefix(err.Error(), vault.ErrSimilarNote.Error()+": ")), "Update one of the notes listed in similar if it covers the same topic, or retry with on_similar=warn or ignore if the new note is meant to be separate."}
+	case errors.Is(err, vault.ErrFolderExists):
+		if existing, ok := strings.CutPrefix(err.Error(), vault.ErrFolderExists.Error()+": "); ok {
+			path = existing
+		}
+		return ToolError{CodeAlreadyExists, fmt.Sprintf("Folder already exists: %s", path), "Choose another path, or use list_folders to see the existing folders."}
+	case errors.As(err, &rateErr):
+		switch rateErr.Scope {
+		case vault.LimitScopeSession:
+			return ToolError{CodeRateLimited, fmt.Sprintf("Rate limit exceeded: at most %d notes may be modified per session. Do not retry; the limit resets when the server restarts", rateErr.Limit), hintRestart}
+		case vault.LimitScopeFile:
+			return ToolError{CodeRateLimited, fmt.Sprintf("Rate limit exceeded: at most %d writes per minute to %s, retry after %ds", rateErr.Limit, rateErr.Path, rateErr.RetrySeconds()), hintRetryLater}
+		default:
+			return ToolError{CodeRateLimited, fmt.Sprintf("Rate limit exceeded: at most %d writes per minute, retry after %ds", rateErr.Limit, rateErr.RetrySeconds()), hintRetryLater}
+		}
+	case errors.Is(err, vault.ErrRateLimited):
+		return ToolError{CodeRateLimited, "Rate limit exceeded", hintRetryLater}
 	case errors.Is(err, vault.ErrPathTraversal):
-		return errMsgPathTraversal
+		return ToolError{CodePathTraversal, errMsgPathTraversal, "Paths must stay inside the vault: no '..' components or absolute paths."}
 	case errors.Is(err, vault.ErrInvalidPath):
-		return errMsgInvalidPath
+		if detail, ok := strings.CutPrefix(err.Error(), vault.ErrInvalidPath.Error()+": "); ok {
+			return ToolError{CodeInvalidPath, fmt.Sprintf("%s: %s", errMsgInvalidPath, detail), hintNotePath}
+		}
+		return ToolError{CodeInvalidPath, errMsgInvalidPath, hintNotePath}
 	case errors.Is(err, vault.ErrNotMarkdown):
-		return errMsgNotMarkdown
+		return ToolError{CodeNotMarkdown, errMsgNotMarkdown, hintNotePath}
+	case errors.Is(err, vault.ErrNotCanvas):
+		return ToolError{CodeNotCanvas, errMsgNotCanvas, "Use read_note for markdown notes."}
+	case errors.Is(err, vault.ErrReservedPath):
+		return ToolError{CodeReservedPath, errMsgReservedPath, "Use list_note_versions and restore_note_version to access backups."}
+	case errors.Is(err, vault.ErrNotAttachment):
+		return ToolError{CodeNotAttachment, fmt.Sprintf("%s. Allowed extensions: %s", errMsgNotAttachment, strings.Join(vault.AttachmentExtensions(), ", ")), "Use read_note for markdown notes."}
+	case errors.Is(err, vault.ErrAttachmentTooLarge):
+		return ToolError{CodeTooLarge, fmt.Sprintf("Attachment too large: %s", path), ""}
+	case errors.Is(err, vault.ErrReadOnly):
+		return ToolError{CodeReadOnly, fmt.Sprintf("Cannot modify %s: this folder is read-only", path), hintReadOnly}
+	case errors.Is(err, vault.ErrPermissionDenied):
+		if _, denied, ok := strings.Cut(err.Error(), vault.ErrPermissionDenied.Error()+": "); ok {
+			path = denied
+		}
+		return ToolError{CodeReadOnly, fmt.Sprintf("Cannot modify %s: the file system does not allow writing it", path), "The file or its folder is not writable, e.g. made read-only with chmod; fix its permissions or leave it unchanged."}
+	case errors.Is(err, vault.ErrNotUTF8):
+		return ToolError{CodeNotUTF8, fmt.Sprintf("Note is not valid UTF-8: %s. Set --source-encoding to read notes in another encoding", path), ""}
+	case errors.Is(err, vault.ErrInvalidCursor):
+		return ToolError{CodeInvalidParams, fmt.Sprintf("Invalid cursor, %s: pass it unchanged", strings.TrimPrefix(err.Error(), vault.ErrInvalidCursor.Error()+": ")), "Omit cursor to start over."}
+	case errors.Is(err, vault.ErrSchemaViolation):
+		return ToolError{CodeSchema, fmt.Sprintf("Cannot write %s: %s", path, err), "Fix the frontmatter properties listed in violations and retry; server_info shows the schema."}
+	case errors.Is(err, vault.ErrRevisionMismatch):
+		return ToolError{CodeConflict, fmt.Sprintf("Note changed since the expected revision: %s", path), "Read the note again and retry with its current content_hash."}
+	case errors.Is(err, vault.ErrInvalidRevision):
+		return ToolError{CodeInvalidParams, fmt.Sprintf("Invalid revision: %s", strings.TrimPrefix(err.Error(), vault.ErrInvalidRevision.Error()+": ")), paramHints["revision"]}
+	case errors.Is(err, vault.ErrBatchTooLarge):
+		return ToolError{CodeTooLarge, fmt.Sprintf("Too many changes in one call: %s", strings.TrimPrefix(err.Error(), vault.ErrBatchTooLarge.Error()+": ")), "Split the operations over several calls; server_info shows the limits under batch_limits."}
+	case errors.As(err, &lockedErr):
+		msg := fmt.Sprintf("%s is locked by %s until %s", lockedErr.Lease.Path, lockedErr.Lease.Holder, lockedErr.Lease.Expires.UTC().Format(time.RFC3339))
+		if lockedErr.Lease.Purpose != "" {
+			msg += fmt.Sprintf(" (%s)", lockedErr.Lease.Purpose)
+		}
+		return ToolError{CodeLocked, msg, hintLocked}
+	case errors.Is(err, vault.ErrLocked):
+		return ToolError{CodeLocked, fmt.Sprintf("Note is locked by another client: %s", path), hintLocked}
+	case errors.Is(err, vault.ErrAuditFailed):
+		return ToolError{CodeAuditFailed, fmt.Sprintf("Error %s: %s", operation, sanitizeError(err)), "The server requires every change to be audited; check that the audit log's folder is writable and has space."}
+	case errors.As(err, &patternErr) && patternErr.Param == "pattern":
+		return ToolError{CodeInvalidParams, fmt.Sprintf("Invalid pattern %q: %s", patternErr.Pattern, patternErr.Reason), paramHints["pattern"]}
+	case errors.As(err, &patternErr):
+		return ToolError{CodeInvalidParams, fmt.Sprintf("Invalid pattern %s %q: %s", patternErr.Name(), patternErr.Pattern, patternErr.Reason), hintQueryPattern}
+	case errors.Is(err, vault.ErrInvalidPattern):
+		return ToolError{CodeInvalidParams, fmt.Sprintf("Invalid pattern: %s", strings.TrimPrefix(err.Error(), vault.ErrInvalidPattern.Error()+": ")), paramHints["pattern"]}
+	case errors.Is(err, vault.ErrInvalidEdit):
+		return ToolError{CodeInvalidParams, fmt.Sprintf("Invalid operation: %s", strings.TrimPrefix(err.Error(), vault.ErrInvalidEdit.Error()+": ")), paramHints["operations"]}
+	case errors.Is(err, vault.ErrSavedSearchNotFound):
+		return ToolError{CodeNotFound, fmt.Sprintf("Saved search not found: %s", path), "Use list_saved_searches to see the saved names."}
+	case errors.Is(err, vault.ErrSavedSearchExists):
+		return ToolError{CodeAlreadyExists, fmt.Sprintf("A search is already saved as %s", path), "Pass overwrite=true to replace it, or choose another name."}
+	case errors.Is(err, vault.ErrInvalidSavedSearch):
+		return ToolError{CodeInvalidParams, fmt.Sprintf("Invalid saved search: %s", strings.TrimPrefix(err.Error(), vault.ErrInvalidSavedSearch.Error()+": ")), hintSearchName}
+	case errors.Is(err, vault.ErrInvalidCapture):
+		return ToolError{CodeInvalidParams, fmt.Sprintf("Invalid capture: %s", strings.TrimPrefix(err.Error(), vault.ErrInvalidCapture.Error()+": ")), paramHints["text"]}
+	case errors.Is(err, vault.ErrInvalidLink):
+		return ToolError{CodeInvalidParams, fmt.Sprintf("Invalid link: %s", strings.TrimPrefix(err.Error(), vault.ErrInvalidLink.Error()+": ")), paramHints["location"]}
+	case errors.Is(err, vault.ErrInvalidFrontmatter):
+		return ToolError{CodeInvalidParams, fmt.Sprintf("Invalid frontmatter: %s", strings.TrimPrefix(err.Error(), vault.ErrInvalidFrontmatter.Error()+": ")), "Fix the frontmatter with update_note so it is a list of properties."}
+	case errors.Is(err, vault.ErrInvalidAlias):
+		return ToolError{CodeInvalidParams, fmt.Sprintf("Invalid alias: %s", strings.TrimPrefix(err.Error(), vault.ErrInvalidAlias.Error()+": ")), paramHints["alias"]}
+	case errors.Is(err, vault.ErrInvalidRollup):
+		return ToolError{CodeInvalidParams, fmt.Sprintf("Invalid rollup: %s", strings.TrimPrefix(err.Error(), vault.ErrInvalidRollup.Error()+": ")), "Template properties rollup_group, rollup_note, rollup_tasks and rollup_task must be text."}
+	case errors.Is(err, vault.ErrUnknownNoteType):
+		return ToolError{CodeInvalidParams, fmt.Sprintf("Invalid type: %s", sanitizeError(err)), "Use list_note_types to see the configured note types."}
+	case errors.Is(err, vault.ErrInvalidPin):
+		return ToolError{CodeInvalidParams, fmt.Sprintf("Cannot pin %s: %s", path, strings.TrimPrefix(err.Error(), vault.ErrInvalidPin.Error()+": ")), paramHints["duration"]}
+	case errors.Is(err, vault.ErrScratchNotFound):
+		return ToolError{CodeNotFound, fmt.Sprintf("Scratch note not found: %s", path), "Use list_scratch to see the scratch notes; they are gone after a restart unless the server has a --scratch-dir."}
+	case errors.Is(err, vault.ErrScratchFull):
+		return ToolError{CodeTooLarge, fmt.Sprintf("Scratch space is full: %s", strings.TrimPrefix(err.Error(), vault.ErrScratchFull.Error()+": ")), "Promote the drafts that are done with promote_scratch, or shorten them; list_scratch shows the limits."}
+	case errors.Is(err, vault.ErrInvalidAnnotation):
+		return ToolError{CodeInvalidParams, fmt.Sprintf("Cannot annotate %s: %s", path, sanitizeError(err)), ""}
+	case errors.Is(err, vault.ErrInvalidCleanup):
+		return ToolError{CodeInvalidParams, fmt.Sprintf("Invalid cleanup: %s", strings.TrimPrefix(err.Error(), vault.ErrInvalidCleanup.Error()+": ")), "Use maintenance_status to see how many backups there are and how old they are."}
+	case errors.Is(err, vault.ErrIndexDisabled):
+		return ToolError{CodeNotConfigured, "The search index is not enabled", "Start the server with --search-index."}
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return ToolError{CodeCancelled, fmt.Sprintf("Error %s: %s", operation, sanitizeError(err)), "Narrow the request, e.g. with a path, if it keeps timing out."}
 	default:
-		return fmt.Sprintf("Error %s note: %v", operation, err)
+		return ToolError{CodeInternal, fmt.Sprintf("Error %s: %s", operation, sanitizeError(err)), ""}
 	}
 }
+
+// formatVaultError converts vault errors to user-friendly messages, for
+// errors reported inside an otherwise successful result
+func formatVaultError(err error, operation, path string) string {
+	return vaultToolError(err, operation, path).Message
+}
+
+// sanitizeError renders err without absolute host paths
+// Filesystem errors embed the full path of the file involved; only its
+// base name is kept so the vault location never leaks into tool output
+func sanitizeError(err error) string {
+	msg := err.Error()
+
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) && filepath.IsAbs(pathErr.Path) {
+		msg = strings.ReplaceAll(msg, pathErr.Path, filepath.Base(pathErr.Path))
+	}
+
+	return msg
+}
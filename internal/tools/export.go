@@ -0,0 +1,190 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/export"
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// defaultExportMaxBytes caps the converted content returned by a folder export
+const defaultExportMaxBytes = 1 << 20
+
+// folderExport is the result of exporting a directory
+type folderExport struct {
+	Format  export.Format     `json:"format"`
+	Notes   map[string]string `json:"notes"`             // Path to converted content
+	Errors  map[string]string `json:"errors,omitempty"`  // Path to read or render error
+	Omitted []string          `json:"omitted,omitempty"` // Notes left out by the size cap
+}
+
+// ExportNoteTool returns the ServerTool for exporting notes to other formats.
+func (h *Handlers) ExportNoteTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"export_note",
+		mcp.WithDescription("Export a note, or every note in a folder, as markdown, rendered HTML or plain text. Wikilinks become links (html) or their display text (plain); code blocks are preserved. A folder export returns a JSON object mapping note paths to converted content."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Note path ending in .md, or a folder path (relative to vault root, empty for the whole vault)."),
+		),
+		mcp.WithString(
+			"format",
+			mcp.Description("Output format."),
+			mcp.Enum(string(export.FormatMarkdown), string(export.FormatHTML), string(export.FormatPlain)),
+			mcp.DefaultString(string(export.FormatMarkdown)),
+		),
+		mcp.WithBoolean(
+			"include_frontmatter",
+			mcp.Description("Keep YAML frontmatter: as an HTML comment for html, as a raw block for plain. Markdown is always returned unchanged."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithBoolean(
+			"recursive",
+			mcp.Description("For folder exports, whether to include notes in subdirectories."),
+			mcp.DefaultBool(true),
+		),
+		mcp.WithNumber(
+			"max_bytes",
+			mcp.Description("For folder exports, maximum total size of converted content. Notes past the limit, or past the response size limit, are listed as omitted."),
+			mcp.DefaultNumber(defaultExportMaxBytes),
+			mcp.Min(1),
+		),
+		mcp.WithNumber(
+			"offset",
+			mcp.Description("For single notes, byte offset into the converted content to start from, as given by the notice of a truncated export."),
+			mcp.DefaultNumber(0),
+			mcp.Min(0),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleExportNote,
+	}
+}
+
+// handleExportNote implements the export_note tool handler.
+func (h *Handlers) handleExportNote(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	path := request.GetString("path", "")
+	recursive := request.GetBool("recursive", true)
+	maxBytes := request.GetInt("max_bytes", defaultExportMaxBytes)
+	offset := request.GetInt("offset", 0)
+	if offset < 0 {
+		return invalidParamResult("offset", fmt.Errorf("must not be negative, got %d", offset)), nil
+	}
+
+	format, err := export.ParseFormat(request.GetString("format", string(export.FormatMarkdown)))
+	if err != nil {
+		return invalidParamResult("format", err), nil
+	}
+
+	opts := export.Options{
+		Format:             format,
+		IncludeFrontmatter: request.GetBool("include_frontmatter", false),
+	}
+
+	if strings.HasSuffix(path, ".md") {
+		result, err := h.exportNote(ctx, path, opts)
+		if err != nil || result.IsError {
+			return result, err
+		}
+		return pageContent(result, offset, h.maxResponseBytes), nil
+	}
+	return h.exportFolder(ctx, path, recursive, maxBytes, opts)
+}
+
+// exportNote converts a single note.
+func (h *Handlers) exportNote(ctx context.Context, path string, opts export.Options) (*mcp.CallToolResult, error) {
+	content, err := h.vault.Read(ctx, path)
+	if err != nil {
+		return vaultErrorResult(err, "exporting note", path), nil
+	}
+
+	converted, err := export.Convert(content, opts)
+	if err != nil {
+		return errorResult(ToolError{
+			Code:    CodeInternal,
+			Message: fmt.Sprintf("Error exporting note %s: %v", path, err),
+		}), nil
+	}
+
+	return textResult(converted), nil
+}
+
+// exportFolder converts every note in a folder. Notes that fail to read
+// or render are reported individually without aborting the export.
+func (h *Handlers) exportFolder(ctx context.Context, path string, recursive bool, maxBytes int, opts export.Options) (*mcp.CallToolResult, error) {
+	notes, err := h.vault.List(ctx, vault.ListOptions{Subpath: path, Recursive: recursive})
+	if err != nil {
+		return vaultErrorResult(err, "exporting folder", path), nil
+	}
+
+	result := folderExport{
+		Format: opts.Format,
+		Notes:  make(map[string]string),
+		Errors: make(map[string]string),
+	}
+
+	total := 0
+	for i, note := range notes {
+		vault.ReportProgress(ctx, i, len(notes))
+		notePath := filepath.ToSlash(note.Path)
+		if total >= maxBytes {
+			result.Omitted = append(result.Omitted, notePath)
+			continue
+		}
+
+		content, err := h.vault.Read(ctx, note.Path)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			result.Errors[notePath] = formatVaultError(err, "reading note", notePath)
+			continue
+		}
+
+		converted, err := export.Convert(content, opts)
+		if err != nil {
+			result.Errors[notePath] = err.Error()
+			continue
+		}
+
+		if total+len(converted) > maxBytes {
+			result.Omitted = append(result.Omitted, notePath)
+			total = maxBytes // Keep later notes out so output stays in order
+			continue
+		}
+		total += len(converted)
+		result.Notes[notePath] = converted
+	}
+
+	if err := ctx.Err(); err != nil {
+		return vaultErrorResult(err, "exporting folder", path), nil
+	}
+
+	// Notes that fit the content budget may still not fit the response
+	paths := slices.Sorted(maps.Keys(result.Notes))
+	return fitJSON(len(paths), h.maxResponseBytes, func(n int) any {
+		if n == len(paths) {
+			return result
+		}
+		cut := result
+		cut.Notes = make(map[string]string, n)
+		for _, notePath := range paths[:n] {
+			cut.Notes[notePath] = result.Notes[notePath]
+		}
+		cut.Omitted = append(slices.Clone(paths[n:]), result.Omitted...)
+		return cut
+	})
+}
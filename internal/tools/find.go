@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// maxFindLimit bounds the matches find_note returns
+const maxFindLimit = 100
+
+// FindNoteTool returns the ServerTool for fuzzy lookup of notes by path.
+func (h *Handlers) FindNoteTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"find_note",
+		mcp.WithDescription("Quickly find notes whose path is something like the query, as in an editor's file finder. "+
+			"The query's characters must appear in the note's path in order, ignoring case and spaces, so 'kuber setup' finds 'DevOps/Kubernetes Setup.md'. "+
+			"Matches at the start of words and runs of consecutive characters score higher, deeper folders lower. Note content is not searched; use search_notes for that."),
+		mcp.WithString(
+			"query",
+			mcp.Description("Part of a note's name or path, e.g. 'kuber setup' or 'proj/q3'."),
+			mcp.Required(),
+		),
+		mcp.WithString(
+			"path",
+			mcp.Description("Only find notes in this folder (relative to vault root)."),
+		),
+		mcp.WithNumber(
+			"limit",
+			mcp.Description("Maximum number of matches to return, best first."),
+			mcp.DefaultNumber(vault.DefaultFuzzyLimit),
+			mcp.Min(1),
+			mcp.Max(maxFindLimit),
+		),
+		withMaxBytes(),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleFindNote,
+	}
+}
+
+// handleFindNote implements the find_note tool handler.
+func (h *Handlers) handleFindNote(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	query, err := request.RequireString("query")
+	if err != nil {
+		return missingParamResult("query", err), nil
+	}
+	if strings.TrimSpace(query) == "" {
+		return invalidParamResult("query", errors.New("must not be empty")), nil
+	}
+
+	opts := vault.FuzzyOptions{
+		Query:   query,
+		Subpath: request.GetString("path", ""),
+		Limit:   min(request.GetInt("limit", vault.DefaultFuzzyLimit), maxFindLimit),
+	}
+
+	// Call vault
+	matches, err := h.vault.FindNote(ctx, opts)
+	if err != nil {
+		return vaultErrorResult(err, "finding note", opts.Subpath), nil
+	}
+
+	return listResult(matches, h.responseLimit(request))
+}
@@ -0,0 +1,176 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// ListFoldersTool returns the ServerTool for listing the folders of the vault.
+func (h *Handlers) ListFoldersTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"list_folders",
+		mcp.WithDescription("List the folder tree of the vault, including empty folders, with the number of notes directly in each folder and in its subfolders. The vault root is \"/\"."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Optional folder to list below. If empty, lists the whole vault."),
+		),
+		mcp.WithBoolean(
+			"include_hidden",
+			mcp.Description("Whether to include folders whose name starts with a dot."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleListFolders,
+	}
+}
+
+// handleListFolders implements the list_folders tool handler.
+func (h *Handlers) handleListFolders(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	opts := vault.FolderOptions{
+		Subpath:       request.GetString("path", ""),
+		IncludeHidden: request.GetBool("include_hidden", false),
+	}
+
+	// Call vault
+	folders, err := h.vault.ListFolders(ctx, opts)
+	if err != nil {
+		return vaultErrorResult(err, "listing folders", opts.Subpath), nil
+	}
+
+	return listResult(folders, h.maxResponseBytes)
+}
+
+// CreateFolderTool returns the ServerTool for creating an empty folder.
+func (h *Handlers) CreateFolderTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"create_folder",
+		mcp.WithDescription("Create an empty folder, and any missing parent folders, to plan the structure of the vault. Notes can be created in new folders directly with create_note."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Path of the new folder relative to the vault root, e.g. \"Projects/2024\"."),
+			mcp.Required(),
+		),
+		mcp.WithBoolean(
+			"sanitize",
+			mcp.Description("Clean up the path before creating it, like create_note does. The final path is returned."),
+			mcp.DefaultBool(true),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleCreateFolder,
+	}
+}
+
+// handleCreateFolder implements the create_folder tool handler.
+func (h *Handlers) handleCreateFolder(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	path, err := request.RequireString("path")
+	if err != nil {
+		return missingParamResult("path", err), nil
+	}
+
+	// Normalize the path the model supplied
+	requested := path
+	if request.GetBool("sanitize", true) {
+		path, err = vault.SanitizePath(path)
+		if err != nil {
+			return vaultErrorResult(err, "creating folder", requested), nil
+		}
+	}
+
+	// Call vault
+	if err := h.vault.CreateFolder(ctx, path); err != nil {
+		return vaultErrorResult(err, "creating folder", path), nil
+	}
+
+	if path != requested {
+		return textResult(fmt.Sprintf("Successfully created folder: %s (sanitized from %q)", path, requested)), nil
+	}
+	return textResult(fmt.Sprintf("Successfully created folder: %s", path)), nil
+}
+
+// RenameFolderTool returns the ServerTool for renaming or moving a folder.
+func (h *Handlers) RenameFolderTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"rename_folder",
+		mcp.WithDescription("Rename or move a folder with every note and attachment inside it. The destination must not exist. With update_links, links anywhere in the vault that would no longer resolve are rewritten to the new paths."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Folder to rename, relative to the vault root."),
+			mcp.Required(),
+		),
+		mcp.WithString(
+			"new_path",
+			mcp.Description("New path of the folder relative to the vault root. Missing parent folders are created."),
+			mcp.Required(),
+		),
+		mcp.WithBoolean(
+			"update_links",
+			mcp.Description("Rewrite wikilinks, embeds and markdown links that point into the folder, or out of it from the moved notes, so they keep resolving. Links that still resolve, such as [[Note]] by name, are left as written."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithBoolean(
+			"sanitize",
+			mcp.Description("Clean up new_path before renaming, like create_note does. The final path is returned."),
+			mcp.DefaultBool(true),
+		),
+		withForce(),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleRenameFolder,
+	}
+}
+
+// handleRenameFolder implements the rename_folder tool handler.
+func (h *Handlers) handleRenameFolder(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	path, err := request.RequireString("path")
+	if err != nil {
+		return missingParamResult("path", err), nil
+	}
+
+	newPath, err := request.RequireString("new_path")
+	if err != nil {
+		return missingParamResult("new_path", err), nil
+	}
+
+	// Normalize the destination the model supplied
+	if request.GetBool("sanitize", true) {
+		requested := newPath
+		newPath, err = vault.SanitizePath(newPath)
+		if err != nil {
+			return vaultErrorResult(err, "renaming folder", requested), nil
+		}
+	}
+
+	// Call vault
+	result, err := h.vault.RenameFolder(ctx, vault.RenameFolderOptions{
+		Path:        path,
+		NewPath:     newPath,
+		UpdateLinks: request.GetBool("update_links", false),
+	})
+	if err != nil {
+		return vaultErrorResult(err, "renaming folder", path), nil
+	}
+
+	return jsonResult(result)
+}
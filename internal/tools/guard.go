@@ -0,0 +1,126 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/metrics"
+)
+
+// longToolTimeout is the timeout of tools that walk or rewrite the whole
+// vault
+const longToolTimeout = 10 * time.Minute
+
+// toolTimeouts overrides the global timeout of tools that take longer on
+// large vaults; the longer of the two applies
+var toolTimeouts = map[string]time.Duration{
+	"apply_changes":    longToolTimeout,
+	"compact_index":    longToolTimeout,
+	"export_chunks":    longToolTimeout,
+	"export_vault":     longToolTimeout,
+	"lint_vault":       longToolTimeout,
+	"rename_folder":    longToolTimeout,
+	"replace_in_notes": longToolTimeout,
+	"sync_titles":      longToolTimeout,
+	"verify_vault":     longToolTimeout,
+}
+
+// WithToolTimeout sets how long a tool call may run before it fails with
+// CANCELLED. Tools that walk or rewrite the whole vault get at least ten
+// minutes. Zero, the default, leaves calls unbounded; panics are
+// recovered either way.
+func WithToolTimeout(timeout time.Duration) Option {
+	return func(h *Handlers) {
+		h.toolTimeout = timeout
+	}
+}
+
+// timeoutFor returns the timeout of the tool name, 0 for none
+func (h *Handlers) timeoutFor(name string) time.Duration {
+	if h.toolTimeout <= 0 {
+		return 0
+	}
+	return max(h.toolTimeout, toolTimeouts[name])
+}
+
+// guard wraps the handler of tool so that a panic becomes an
+// INTERNAL_ERROR result and a call running longer than timeout fails with
+// CANCELLED, leaving the server to serve later calls. The handler keeps
+// running in the background after a timeout until it notices its context
+// is done. A cancelled call still reports the cancellation, not a timeout.
+func (h *Handlers) guard(tool server.ServerTool, timeout time.Duration) server.ServerTool {
+	name, handler := tool.Tool.Name, tool.Handler
+	readOnly := isReadOnlyTool(tool.Tool)
+
+	type outcome struct {
+		result *mcp.CallToolResult
+		err    error
+	}
+	tool.Handler = func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		callCtx, cancel := ctx, context.CancelFunc(func() {})
+		if timeout > 0 {
+			callCtx, cancel = context.WithTimeout(ctx, timeout)
+		}
+		defer cancel()
+
+		// Buffered so the handler can finish after the call was given up
+		done := make(chan outcome, 1)
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					done <- outcome{result: h.panicResult(ctx, name, p)}
+				}
+			}()
+			result, err := handler(callCtx, request)
+			done <- outcome{result, err}
+		}()
+
+		select {
+		case o := <-done:
+			return o.result, o.err
+		case <-callCtx.Done():
+		}
+		if ctx.Err() != nil {
+			return errorResult(ToolError{CodeCancelled, fmt.Sprintf("%s was cancelled", name), ""}), nil
+		}
+
+		h.metrics.Add(metrics.ToolAborts, "timeout", 1)
+		h.logger.WarnContext(ctx, "tool call timed out", slog.String("tool", name), slog.Duration("timeout", timeout))
+		hint := "Narrow the request, e.g. with a path, so it finishes in time."
+		if !readOnly {
+			hint = "The change may still complete in the background: check the notes before retrying."
+		}
+		return errorResult(ToolError{CodeCancelled, fmt.Sprintf("%s did not finish within %v", name, timeout), hint}), nil
+	}
+	return tool
+}
+
+// panicResult logs the panic p of the tool name with its stack under a
+// new correlation ID and returns an INTERNAL_ERROR result naming only
+// the ID
+func (h *Handlers) panicResult(ctx context.Context, name string, p any) *mcp.CallToolResult {
+	var b [8]byte
+	rand.Read(b[:])
+	id := hex.EncodeToString(b[:])
+
+	h.metrics.Add(metrics.ToolAborts, "panic", 1)
+	h.logger.ErrorContext(ctx, "tool call panicked",
+		slog.String("tool", name),
+		slog.String("id", id),
+		slog.Any("panic", p),
+		slog.String("stack", string(debug.Stack())),
+	)
+	return errorResult(ToolError{
+		Code:    CodeInternal,
+		Message: fmt.Sprintf("Internal error in %s (id %s)", name, id),
+		Hint:    "This is a bug in the server; report it with the id, which its log records.",
+	})
+}
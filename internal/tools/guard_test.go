@@ -0,0 +1,115 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/metrics"
+)
+
+func TestGuard(t *testing.T) {
+	var logs bytes.Buffer
+	registry := metrics.NewRegistry()
+	h := NewHandlers(failingVault{}, slog.New(slog.NewTextHandler(&logs, nil)), WithMetrics(registry))
+
+	release := make(chan struct{})
+	defer close(release)
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		switch request.GetString("outcome", "") {
+		case "panic":
+			panic("secret stack detail")
+		case "hang":
+			<-release // Ignores its context, like a stuck handler
+		}
+		return textResult("ok"), nil
+	}
+	call := func(ctx context.Context, tool server.ServerTool, outcome string) *mcp.CallToolResult {
+		var request mcp.CallToolRequest
+		request.Params.Name = tool.Tool.Name
+		request.Params.Arguments = map[string]any{"outcome": outcome}
+		result, err := tool.Handler(ctx, request)
+		if err != nil {
+			t.Fatalf("%s(%s) error = %v", tool.Tool.Name, outcome, err)
+		}
+		return result
+	}
+	ctx := context.Background()
+	readTool := h.guard(server.ServerTool{Tool: mcp.NewTool("read_note", mcp.WithReadOnlyHintAnnotation(true)), Handler: handler}, 20*time.Millisecond)
+	writeTool := h.guard(server.ServerTool{Tool: mcp.NewTool("update_note"), Handler: handler}, 20*time.Millisecond)
+
+	// A panic is logged with its stack; the result only names the ID
+	toolErr := checkToolError(t, call(ctx, readTool, "panic"), CodeInternal)
+	if strings.Contains(toolErr.Message, "secret") || !strings.Contains(toolErr.Message, "(id ") {
+		t.Errorf("Message = %q, want an ID and no panic detail", toolErr.Message)
+	}
+	id := strings.TrimSuffix(toolErr.Message[strings.Index(toolErr.Message, "(id ")+4:], ")")
+	if !strings.Contains(logs.String(), "id="+id) || !strings.Contains(logs.String(), "secret stack detail") {
+		t.Errorf("Log = %s, want the panic under id %s", logs.String(), id)
+	}
+
+	// A hanging call times out; writes warn that they may still complete
+	start := time.Now()
+	toolErr = checkToolError(t, call(ctx, readTool, "hang"), CodeCancelled)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Timed out after %v, want about 20ms", elapsed)
+	}
+	if !strings.Contains(toolErr.Message, "did not finish within 20ms") {
+		t.Errorf("Message = %q, want the timeout", toolErr.Message)
+	}
+	toolErr = checkToolError(t, call(ctx, writeTool, "hang"), CodeCancelled)
+	if !strings.Contains(toolErr.Hint, "background") {
+		t.Errorf("Hint = %q, want a warning that the change may complete", toolErr.Hint)
+	}
+
+	// A call the client cancelled reports the cancellation, not a timeout
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	toolErr = checkToolError(t, call(cancelled, readTool, "hang"), CodeCancelled)
+	if strings.Contains(toolErr.Message, "did not finish") {
+		t.Errorf("Message = %q, want a cancellation", toolErr.Message)
+	}
+
+	// Later calls are served normally
+	if result := call(ctx, readTool, ""); result.IsError {
+		t.Errorf("Result after the failures = %v, want ok", resultText(result))
+	}
+
+	counts := make(map[string]uint64)
+	for _, sample := range registry.Snapshot() {
+		counts[sample.Name+"/"+sample.Label] = sample.Value
+	}
+	if counts[metrics.ToolAborts+"/panic"] != 1 || counts[metrics.ToolAborts+"/timeout"] != 2 {
+		t.Errorf("Recorded %v, want 1 panic and 2 timeouts", counts)
+	}
+}
+
+func TestToolTimeouts(t *testing.T) {
+	h := NewHandlers(failingVault{}, slog.New(slog.DiscardHandler), WithToolTimeout(time.Minute))
+	if got := h.timeoutFor("read_note"); got != time.Minute {
+		t.Errorf("timeoutFor(read_note) = %v, want 1m", got)
+	}
+	if got := h.timeoutFor("verify_vault"); got != longToolTimeout {
+		t.Errorf("timeoutFor(verify_vault) = %v, want %v", got, longToolTimeout)
+	}
+	if got := NewHandlers(failingVault{}, slog.New(slog.DiscardHandler)).timeoutFor("verify_vault"); got != 0 {
+		t.Errorf("timeoutFor() without a timeout = %v, want 0", got)
+	}
+
+	// Every override names a tool
+	names := make(map[string]bool)
+	for _, tool := range h.Tools() {
+		names[tool.Tool.Name] = true
+	}
+	for name := range toolTimeouts {
+		if !names[name] {
+			t.Errorf("toolTimeouts names unknown tool %s", name)
+		}
+	}
+}
@@ -2,6 +2,12 @@
 package tools
 
 import (
+	"log/slog"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/kratos/mcp-notes/internal/metrics"
 	"github.com/kratos/mcp-notes/internal/vault"
 	"github.com/mark3labs/mcp-go/server"
 )
@@ -9,24 +15,185 @@ import (
 // Handlers aggregates all tool handlers for the MCP notes server.
 // It provides a central point for registering tools with the MCP server.
 type Handlers struct {
-	vault vault.Vault
+	vault     vault.Vault
+	logger    *slog.Logger
+	vaultName string    // Obsidian vault name for obsidian:// URIs, empty to omit them
+	vaultRoot string    // Folder of the Obsidian vault the server is limited to, empty for the whole vault
+	version   string    // Server version reported by server_info
+	started   time.Time // When the handlers were created, for uptime
+
+	searchTimeout    time.Duration   // Default time limit of search_notes, 0 for none
+	toolTimeout      time.Duration   // Time limit of every tool call, 0 for none
+	maxResponseBytes int             // Response size limit, 0 for none
+	policy           ToolPolicy      // Which tools RegisterTools exposes
+	metrics          metrics.Metrics // Receives tool call counts and latencies
+	roots            rootScope       // Folders the client's MCP roots allow
+	clientName       string          // Lease holder of every client, empty for the name each sends
+	exportDir        string          // Folder export_vault may write archives to, empty for none
+}
+
+// Option configures optional handler behavior.
+type Option func(*Handlers)
+
+// WithVaultName sets the Obsidian vault name used to build obsidian://open
+// URIs. When set, note listings and read/create results include them.
+func WithVaultName(name string) Option {
+	return func(h *Handlers) {
+		h.vaultName = name
+	}
+}
+
+// WithVaultRoot sets the folder of the Obsidian vault that note paths are
+// relative to when the server is limited to it, so obsidian://open URIs
+// name the file from the vault root.
+func WithVaultRoot(root string) Option {
+	return func(h *Handlers) {
+		root = path.Clean(strings.ReplaceAll(root, `\`, "/"))
+		if root == "." {
+			root = ""
+		}
+		h.vaultRoot = strings.Trim(root, "/")
+	}
+}
+
+// WithVersion sets the server version reported by server_info.
+func WithVersion(version string) Option {
+	return func(h *Handlers) {
+		h.version = version
+	}
+}
+
+// WithSearchTimeout sets how long search_notes may run when the call does
+// not set timeout_ms. Searches that take longer return partial results.
+// Zero, the default, leaves searches unbounded.
+func WithSearchTimeout(timeout time.Duration) Option {
+	return func(h *Handlers) {
+		h.searchTimeout = timeout
+	}
+}
+
+// WithMaxResponseBytes limits the size of every tool response to n bytes.
+// Lists are cut at entry boundaries and note content at line boundaries,
+// with a notice of what was left out; responses that cannot be cut fail
+// with TOO_LARGE. Zero, the default, leaves responses unlimited.
+func WithMaxResponseBytes(n int) Option {
+	return func(h *Handlers) {
+		h.maxResponseBytes = n
+	}
+}
+
+// WithMetrics makes MetricsMiddleware record tool calls in m and
+// server_info report a snapshot of it. By default nothing is recorded.
+func WithMetrics(m metrics.Metrics) Option {
+	return func(h *Handlers) {
+		h.metrics = m
+	}
 }
 
 // NewHandlers creates a new Handlers instance with the given vault.
-func NewHandlers(v vault.Vault) *Handlers {
-	return &Handlers{
-		vault: v,
+// Tool calls are logged to logger.
+func NewHandlers(v vault.Vault, logger *slog.Logger, opts ...Option) *Handlers {
+	h := &Handlers{
+		vault:   v,
+		logger:  logger,
+		started: time.Now(),
+		metrics: metrics.Nop{},
+	}
+	for _, opt := range opts {
+		opt(h)
 	}
+	return h
 }
 
-// RegisterTools registers all tool handlers with the MCP server.
-// This should be called during server initialization.
+// RegisterTools registers the tool handlers the policy exposes with the
+// MCP server. This should be called during server initialization. Each
+// handler is guarded against panics and bounded by its tool's timeout.
 func (h *Handlers) RegisterTools(srv *server.MCPServer) {
-	srv.AddTools(
+	tools := h.EnabledTools()
+	for i, tool := range tools {
+		tools[i] = h.guard(tool, h.timeoutFor(tool.Tool.Name))
+	}
+	srv.AddTools(tools...)
+}
+
+// Tools returns every tool the server provides.
+func (h *Handlers) Tools() []server.ServerTool {
+	return []server.ServerTool{
+		h.ServerInfoTool(),
 		h.ListNotesTool(),
+		h.ListFoldersTool(),
 		h.SearchNotesTool(),
 		h.ReadNoteTool(),
+		h.ReadNotesTool(),
+		h.ReadTaggedNotesTool(),
+		h.ResolveNoteTool(),
+		h.FindNoteTool(),
+		h.GetNoteURITool(),
+		h.ExportNoteTool(),
+		h.ExportChunksTool(),
+		h.ExportVaultTool(),
+		h.ReadCanvasTool(),
 		h.CreateNoteTool(),
 		h.UpdateNoteTool(),
-	)
+		h.CreateFolderTool(),
+		h.RenameFolderTool(),
+		h.MoveNoteTool(),
+		h.MergeNotesTool(),
+		h.SplitNoteTool(),
+		h.ApplyChangesTool(),
+		h.ReplaceInNotesTool(),
+		h.CaptureTool(),
+		h.AddLinkTool(),
+		h.AddAliasTool(),
+		h.RemoveAliasTool(),
+		h.SyncTitlesTool(),
+		h.PublishNoteTool(),
+		h.UnpublishNoteTool(),
+		h.ListPublishableTool(),
+		h.MarkPublishedTool(),
+		h.LockNoteTool(),
+		h.UnlockNoteTool(),
+		h.PinNoteTool(),
+		h.UnpinNoteTool(),
+		h.ListPinnedTool(),
+		h.CreateScratchTool(),
+		h.UpdateScratchTool(),
+		h.ReadScratchTool(),
+		h.ListScratchTool(),
+		h.PromoteScratchTool(),
+		h.GenerateRollupTool(),
+		h.GetNoteLinksTool(),
+		h.AnalyzeNoteTool(),
+		h.GetOutlineTool(),
+		h.FindTasksTool(),
+		h.ListNoteTypesTool(),
+		h.FindRelatedTool(),
+		h.SuggestPlacementTool(),
+		h.ListNoteVersionsTool(),
+		h.DiffNoteTool(),
+		h.RestoreNoteVersionTool(),
+		h.MaintenanceStatusTool(),
+		h.PruneBackupsTool(),
+		h.EmptyTrashTool(),
+		h.CompactIndexTool(),
+		h.ReconnectVaultTool(),
+		h.VaultStatsTool(),
+		h.VerifyVaultTool(),
+		h.LintNoteTool(),
+		h.LintVaultTool(),
+		h.RecentNotesTool(),
+		h.StaleNotesTool(),
+		h.ActivityReportTool(),
+		h.ChangedNotesTool(),
+		h.GetAuditLogTool(),
+		h.SetNoteAnnotationTool(),
+		h.GetNoteAnnotationsTool(),
+		h.SaveSearchTool(),
+		h.ListSavedSearchesTool(),
+		h.RunSavedSearchTool(),
+		h.DeleteSavedSearchTool(),
+		h.ListAttachmentsTool(),
+		h.StatAttachmentTool(),
+		h.ReadAttachmentTool(),
+	}
 }
@@ -0,0 +1,89 @@
+package tools
+
+import (
+	"context"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/metrics"
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// ServerInfo is the health and capability report returned by server_info.
+// It is also the payload for a future HTTP health endpoint.
+type ServerInfo struct {
+	Status        string           `json:"status"` // "ok" when the vault could be inspected
+	Version       string           `json:"version"`
+	Started       time.Time        `json:"started"`
+	UptimeSeconds int64            `json:"uptime_seconds"`
+	ObsidianURIs  bool             `json:"obsidian_uris"`            // Results carry obsidian:// links
+	SearchTimeout int64            `json:"search_timeout_ms"`        // Default time limit of search_notes, 0 for none
+	ToolTimeout   int64            `json:"tool_timeout_ms"`          // Time limit of tool calls, 0 for none
+	MaxResponse   int              `json:"max_response_bytes"`       // Response size limit, 0 for none
+	DisabledTools []string         `json:"disabled_tools,omitempty"` // Tools the server was configured not to expose
+	Roots         *RootsInfo       `json:"roots,omitempty"`          // Set when the client's roots limit tool calls
+	Vault         vault.VaultInfo  `json:"vault"`
+	Metrics       []metrics.Sample `json:"metrics,omitempty"` // Counters recorded since start, when metrics are enabled
+}
+
+// RootsInfo describes the limit the client's MCP roots put on tool calls
+type RootsInfo struct {
+	Folders []string `json:"folders"` // Allowed vault folders, empty when the roots miss the vault
+}
+
+// ServerInfo reports the server version, uptime and the vault's note
+// count, features and cache usage.
+func (h *Handlers) ServerInfo(ctx context.Context) (ServerInfo, error) {
+	info, err := h.vault.Info(ctx)
+	if err != nil {
+		return ServerInfo{}, err
+	}
+
+	var roots *RootsInfo
+	if folders, scoped := h.RootFolders(); scoped {
+		roots = &RootsInfo{Folders: append([]string{}, folders...)}
+	}
+
+	return ServerInfo{
+		Status:        "ok",
+		Version:       h.version,
+		Started:       h.started,
+		UptimeSeconds: int64(time.Since(h.started).Seconds()),
+		ObsidianURIs:  h.vaultName != "",
+		SearchTimeout: h.searchTimeout.Milliseconds(),
+		ToolTimeout:   max(h.toolTimeout, 0).Milliseconds(),
+		MaxResponse:   h.maxResponseBytes,
+		DisabledTools: h.disabledTools(),
+		Roots:         roots,
+		Vault:         info,
+		Metrics:       h.metrics.Snapshot(),
+	}, nil
+}
+
+// ServerInfoTool returns the ServerTool for checking server health and capabilities.
+func (h *Handlers) ServerInfoTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"server_info",
+		mcp.WithDescription("Check that the server is healthy and see its configuration: version, uptime, vault name, note count, enabled features (backups, write limits, read-only paths, cache size, obsidian:// links, search time limit, response size limit), the vault folders the client's roots allow, cache statistics and, when enabled, metrics such as tool call counts and latencies."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleServerInfo,
+	}
+}
+
+// handleServerInfo implements the server_info tool handler.
+func (h *Handlers) handleServerInfo(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Call vault
+	info, err := h.ServerInfo(ctx)
+	if err != nil {
+		return vaultErrorResult(err, "inspecting vault", ""), nil
+	}
+
+	return jsonResult(info)
+}
@@ -0,0 +1,45 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// GetNoteLinksTool returns the ServerTool for listing the outgoing links of a note.
+func (h *Handlers) GetNoteLinksTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"get_note_links",
+		mcp.WithDescription("List outgoing references of a note: wikilinks, embeds, markdown links and external URLs, with heading/block anchors, resolved vault paths and line numbers. Unresolved links are marked as such."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Path to the note (relative to vault root, must end with .md)."),
+			mcp.Required(),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleGetNoteLinks,
+	}
+}
+
+// handleGetNoteLinks implements the get_note_links tool handler.
+func (h *Handlers) handleGetNoteLinks(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	path, err := request.RequireString("path")
+	if err != nil {
+		return missingParamResult("path", err), nil
+	}
+
+	// Call vault
+	links, err := h.vault.Links(ctx, path)
+	if err != nil {
+		return vaultErrorResult(err, "reading note links", path), nil
+	}
+
+	return jsonResult(links)
+}
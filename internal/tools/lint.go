@@ -0,0 +1,125 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// lintVaultResult is the response of lint_vault
+type lintVaultResult struct {
+	NotesChecked int                 `json:"notes_checked"`
+	Counts       map[string]int      `json:"counts"`
+	Findings     []vault.LintFinding `json:"findings"`
+	Truncated    bool                `json:"truncated,omitempty"`
+}
+
+// lintRulesDescription lists the lint rules with their default
+// severities, for tool descriptions
+func lintRulesDescription() string {
+	rules := vault.LintRules()
+	descriptions := make([]string, len(rules))
+	for i, rule := range rules {
+		descriptions[i] = fmt.Sprintf("%s (%s): %s", rule.ID, rule.Severity, rule.Description)
+	}
+	return "Rules: " + strings.Join(descriptions, "; ") + "."
+}
+
+// LintNoteTool returns the ServerTool for checking a note against the
+// vault's conventions.
+func (h *Handlers) LintNoteTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"lint_note",
+		mcp.WithDescription("Check a note against the vault's conventions, such as one H1 matching the file name or wikilinks instead of markdown links. "+
+			"Returns each finding with its rule, severity, line and whether it can be fixed. With fix=true the fixable findings are fixed in one write "+
+			"and the result lists what was fixed and what is left. The rules and their options are set in the lint section of the server's config file. "+
+			lintRulesDescription()),
+		mcp.WithString(
+			"path",
+			mcp.Description("Path to the note (relative to vault root, must end with .md)."),
+			mcp.Required(),
+		),
+		mcp.WithBoolean(
+			"fix",
+			mcp.Description("Fix the findings that can be fixed and write the note back. Defaults to false, which only reports them."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleLintNote,
+	}
+}
+
+// handleLintNote implements the lint_note tool handler.
+func (h *Handlers) handleLintNote(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	path, err := request.RequireString("path")
+	if err != nil {
+		return missingParamResult("path", err), nil
+	}
+	fix := request.GetBool("fix", false)
+
+	// Call vault
+	result, err := h.vault.LintNote(ctx, path, fix)
+	if err != nil {
+		operation := "linting note"
+		if fix {
+			operation = "fixing note"
+		}
+		return vaultErrorResult(err, operation, path), nil
+	}
+
+	return jsonResult(result)
+}
+
+// LintVaultTool returns the ServerTool for checking the notes of a
+// folder against the vault's conventions.
+func (h *Handlers) LintVaultTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"lint_vault",
+		mcp.WithDescription("Check the notes of a folder against the vault's conventions, as lint_note does for one note. "+
+			"Returns the findings sorted by path and line with counts by rule; fix them note by note with lint_note. "+
+			"Reads every note, so pass a path to check part of the vault. "+lintRulesDescription()),
+		mcp.WithString(
+			"path",
+			mcp.Description("Folder to check, relative to vault root. If empty, checks the whole vault."),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleLintVault,
+	}
+}
+
+// handleLintVault implements the lint_vault tool handler.
+func (h *Handlers) handleLintVault(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	subpath := request.GetString("path", "")
+
+	// Call vault
+	report, err := h.vault.LintVault(ctx, subpath)
+	if err != nil {
+		return vaultErrorResult(err, "linting vault", subpath), nil
+	}
+
+	return fitJSON(len(report.Findings), h.maxResponseBytes, func(n int) any {
+		return lintVaultResult{
+			NotesChecked: report.NotesChecked,
+			Counts:       report.Counts,
+			Findings:     report.Findings[:n],
+			Truncated:    n < len(report.Findings),
+		}
+	})
+}
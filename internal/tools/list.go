@@ -2,18 +2,22 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"path"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/kratos/mcp-notes/internal/vault"
 )
 
 // ListNotesTool returns the ServerTool for listing notes in the vault.
 func (h *Handlers) ListNotesTool() server.ServerTool {
 	tool := mcp.NewTool(
 		"list_notes",
-		mcp.WithDescription("List all notes in the vault or a specific subdirectory. Returns note paths with their tags."),
+		mcp.WithDescription("List all notes in the vault or a specific subdirectory. Returns note paths with their tags. Optional filters on modification time, tags, file name and size combine with AND."),
 		mcp.WithString(
 			"path",
 			mcp.Description("Optional subdirectory path to list notes from. If empty, lists from vault root."),
@@ -23,6 +27,82 @@ func (h *Handlers) ListNotesTool() server.ServerTool {
 			mcp.Description("Whether to recursively list notes in subdirectories."),
 			mcp.DefaultBool(true),
 		),
+		mcp.WithBoolean(
+			"include_hidden",
+			mcp.Description("Whether to include files and folders whose name starts with a dot."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithString(
+			"modified_after",
+			mcp.Description("Only notes modified at or after this point: a duration back from now (e.g. \"-30d\", \"72h\"), a date (\"2024-03-01\") or an RFC3339 timestamp."),
+		),
+		mcp.WithString(
+			"modified_before",
+			mcp.Description("Only notes modified before this point, in the same forms as modified_after."),
+		),
+		mcp.WithArray(
+			"tags",
+			mcp.Description("Optional list of tags. Notes must have at least one of these tags."),
+			mcp.WithStringItems(),
+		),
+		mcp.WithArray(
+			"tags_all",
+			mcp.Description("Optional list of tags. Notes must have all of these tags."),
+			mcp.WithStringItems(),
+		),
+		mcp.WithArray(
+			"tags_none",
+			mcp.Description("Optional list of tags. Notes with any of these tags are excluded."),
+			mcp.WithStringItems(),
+		),
+		mcp.WithString(
+			"name_glob",
+			mcp.Description("Only notes whose file name matches this pattern, e.g. \"2024-*.md\". * and ? match within the name only."),
+		),
+		mcp.WithNumber(
+			"min_size",
+			mcp.Description("Only notes of at least this many bytes."),
+			mcp.Min(0),
+		),
+		mcp.WithNumber(
+			"max_size",
+			mcp.Description("Only notes of at most this many bytes, 0 for no limit."),
+			mcp.Min(0),
+		),
+		mcp.WithBoolean(
+			"include_preview",
+			mcp.Description("Whether to add an excerpt field with the start of each note: its first paragraph after the frontmatter, with any headings above it."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithNumber(
+			"preview_length",
+			mcp.Description(fmt.Sprintf("Maximum excerpt length in characters, cut on a word boundary. Defaults to %d.", vault.DefaultPreviewLength)),
+			mcp.DefaultNumber(vault.DefaultPreviewLength),
+			mcp.Min(1),
+			mcp.Max(vault.MaxPreviewLength),
+		),
+		mcp.WithBoolean(
+			"include_annotations",
+			mcp.Description("Whether to add the annotations stored with set_note_annotation to each note."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithBoolean(
+			"include_index",
+			mcp.Description(fmt.Sprintf("Whether to return the listed folder's index note, such as _index.md or README.md, in an index_note field: its content, or an excerpt when it is over %d KiB. "+
+				"Notes are then returned in a results field, and each folder's index note is marked with is_index.", vault.MaxIndexNoteBytes>>10)),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithBoolean(
+			"folder_summaries",
+			mcp.Description("With include_index and recursive, also return the excerpt of each subfolder's index note in a folders field, keyed by folder path."),
+			mcp.DefaultBool(false),
+		),
+		withNoteType(),
+		withSort(vault.SortPath, vault.SortModified, vault.SortCreated),
+		withCollation(),
+		withPinnedFirst("Defaults to false."),
+		withMaxBytes(),
+		mcp.WithOutputSchema[ListResponse](),
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithDestructiveHintAnnotation(false),
 	)
@@ -36,44 +116,206 @@ func (h *Handlers) ListNotesTool() server.ServerTool {
 // handleListNotes implements the list_notes tool handler.
 func (h *Handlers) handleListNotes(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Extract parameters
-	path := request.GetString("path", "")
-	recursive := request.GetBool("recursive", true)
+	opts := vault.ListOptions{
+		Subpath:       request.GetString("path", ""),
+		Recursive:     request.GetBool("recursive", true),
+		IncludeHidden: request.GetBool("include_hidden", false),
+		PreviewLength: previewLength(request),
+		Type:          request.GetString("type", ""),
+
+		IncludeAnnotations: request.GetBool("include_annotations", false),
+		MarkIndexNotes:     request.GetBool("include_index", false),
+	}
+
+	filter, errResult := noteFilter(request, time.Now())
+	if errResult != nil {
+		return errResult, nil
+	}
+	opts.Filter = filter
+
+	opts.Sort, opts.Collation, errResult = noteOrder(request)
+	if errResult != nil {
+		return errResult, nil
+	}
+	if opts.Sort == vault.SortRelevance {
+		return invalidParamResult("sort", fmt.Errorf("relevance only applies to search_notes")), nil
+	}
+	opts.PinnedFirst = request.GetBool("pinned_first", false)
 
 	// Call vault
-	notes, err := h.vault.List(ctx, path, recursive)
+	ctx, warnings := h.walkWarnings(ctx)
+	notes, err := h.vault.List(ctx, opts)
+	if err != nil {
+		return vaultErrorResult(err, "listing notes", opts.Subpath), nil
+	}
+
+	limit := h.responseLimit(request)
+	extras := listExtras{Warnings: warnings()}
+	if opts.MarkIndexNotes {
+		extras = h.indexNotes(ctx, opts.Subpath, notes, opts.Recursive && request.GetBool("folder_summaries", false), limit)
+		extras.Warnings = append(warnings(), extras.Warnings...)
+	}
+	results := h.noteResults(notes)
+	n, data, err := fitData(len(results), limit, listWrap(results, extras, opts.MarkIndexNotes || len(extras.Warnings) > 0))
+	if err != nil {
+		return nil, err
+	}
+	return structuredResult(textResult(string(data)), newListResponse(results, n, extras)), nil
+}
+
+// indexNotes looks up the index note of the listed folder and, with
+// summaries, the excerpts of those of the subfolders holding notes. An
+// index note too large for half the response limit comes as an excerpt,
+// and one that cannot be read is left out with a warning, so index notes
+// never fail the listing.
+func (h *Handlers) indexNotes(ctx context.Context, folder string, notes []vault.NoteInfo, summaries bool, limit int) listExtras {
+	var extras listExtras
+	unreadable := func(dir string) {
+		extras.Warnings = append(extras.Warnings, fmt.Sprintf("index note of folder %q could not be read and was left out", dir))
+	}
+
+	listed := path.Clean(folder)
+	index, err := h.vault.FolderIndex(ctx, folder, true)
+	if err == nil && index != nil && limit > 0 && len(index.Content) > limit/2 {
+		index, err = h.vault.FolderIndex(ctx, folder, false)
+	}
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error listing notes: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
-	}
-
-	// Marshal notes to JSON
-	notesJSON, err := json.MarshalIndent(notes, "", "  ")
+		unreadable(listed)
+	}
+	extras.IndexNote = index
+
+	if !summaries {
+		return extras
+	}
+	for _, note := range notes {
+		dir := path.Dir(note.Path)
+		if _, done := extras.Folders[dir]; done || dir == listed {
+			continue
+		}
+		index, err := h.vault.FolderIndex(ctx, dir, false)
+		if err != nil {
+			unreadable(dir)
+			continue
+		}
+		if index == nil {
+			continue
+		}
+		if extras.Folders == nil {
+			extras.Folders = make(map[string]string)
+		}
+		extras.Folders[dir] = index.Excerpt
+	}
+	return extras
+}
+
+// walkWarnings returns a context collecting the warnings of the vault
+// walks made with it, and a function returning those collected. Warnings
+// about paths outside the client's roots are dropped.
+func (h *Handlers) walkWarnings(ctx context.Context) (context.Context, func() []string) {
+	var mu sync.Mutex
+	var warnings []string
+	ctx = vault.WarningContext(ctx, func(path, warning string) {
+		if !h.inRoots(path) {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		warnings = append(warnings, warning)
+	})
+	return ctx, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return warnings
+	}
+}
+
+// previewLength returns the excerpt length requested by the include_preview
+// and preview_length parameters, clamped to the allowed range, or 0 when no
+// preview was asked for.
+func previewLength(request mcp.CallToolRequest) int {
+	if !request.GetBool("include_preview", false) {
+		return 0
+	}
+	return min(max(request.GetInt("preview_length", vault.DefaultPreviewLength), 1), vault.MaxPreviewLength)
+}
+
+// withSort adds the sort parameter of tools listing notes, offering sorts
+func withSort(sorts ...vault.NoteSort) mcp.ToolOption {
+	values := make([]string, len(sorts))
+	for i, by := range sorts {
+		values[i] = string(by)
+	}
+	description := "Order of the notes: path, or modified or created with the most recent first, ties by path."
+	if slices.Contains(sorts, vault.SortRelevance) {
+		description += " relevance puts the notes with the most matches of query, query_all and query_any first."
+	}
+	return mcp.WithString(
+		"sort",
+		mcp.Description(description),
+		mcp.Enum(values...),
+		mcp.DefaultString(string(vault.SortPath)),
+	)
+}
+
+// withCollation adds the collation parameter of tools listing notes
+func withCollation() mcp.ToolOption {
+	return mcp.WithString(
+		"collation",
+		mcp.Description("How paths are compared: binary byte by byte, natural with numbers by value so \"note2\" comes before \"note10\", "+
+			"or locale: and a language tag such as \"locale:de\" for that language's alphabetical order."),
+		mcp.DefaultString(string(vault.CollationBinary)),
+	)
+}
+
+// noteOrder extracts the sort and collation parameters of request. On
+// failure the error result is returned.
+func noteOrder(request mcp.CallToolRequest) (vault.NoteSort, vault.Collation, *mcp.CallToolResult) {
+	by, err := vault.ParseNoteSort(request.GetString("sort", ""))
+	if err != nil {
+		return "", "", invalidParamResult("sort", err)
+	}
+	collation, err := vault.ParseCollation(request.GetString("collation", ""))
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error marshaling notes: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
-	}
-
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.TextContent{
-				Type: "text",
-				Text: string(notesJSON),
-			},
-		},
-		IsError: false,
-	}, nil
+		return "", "", invalidParamResult("collation", err)
+	}
+	return by, collation, nil
+}
+
+// noteFilter builds the list filter from the optional filter parameters.
+// On failure the error result is returned.
+func noteFilter(request mcp.CallToolRequest, now time.Time) (vault.NoteFilter, *mcp.CallToolResult) {
+	filter := vault.NoteFilter{
+		NameGlob: request.GetString("name_glob", ""),
+		MinSize:  int64(max(request.GetInt("min_size", 0), 0)),
+		MaxSize:  int64(max(request.GetInt("max_size", 0), 0)),
+		TagsAny:  request.GetStringSlice("tags", nil),
+		TagsAll:  request.GetStringSlice("tags_all", nil),
+		TagsNone: request.GetStringSlice("tags_none", nil),
+	}
+
+	for name, field := range map[string]*time.Time{
+		"modified_after":  &filter.ModifiedAfter,
+		"modified_before": &filter.ModifiedBefore,
+	} {
+		value := request.GetString(name, "")
+		if value == "" {
+			continue
+		}
+		ts, err := parseTime(value, now)
+		if err != nil {
+			return vault.NoteFilter{}, invalidParamResult(name, err)
+		}
+		*field = ts
+	}
+
+	if filter.NameGlob != "" {
+		if _, err := path.Match(filter.NameGlob, ""); err != nil {
+			return vault.NoteFilter{}, invalidParamResult("name_glob", err)
+		}
+	}
+	if filter.MaxSize > 0 && filter.MinSize > filter.MaxSize {
+		return vault.NoteFilter{}, invalidParamResult("min_size", fmt.Errorf("%d is larger than max_size %d", filter.MinSize, filter.MaxSize))
+	}
+
+	return filter, nil
 }
@@ -0,0 +1,167 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// anonymousHolder holds the leases of clients that send no name
+const anonymousHolder = "anonymous"
+
+// unlockResult is the response of unlock_note
+type unlockResult struct {
+	Path     string `json:"path"`
+	Released bool   `json:"released"` // False when the note had no lease
+}
+
+// withForce adds the force parameter of tools that write notes.
+func withForce() mcp.ToolOption {
+	return mcp.WithBoolean(
+		"force",
+		mcp.Description("Write even notes another client has locked with lock_note. Only use it when that client is known to be gone."),
+		mcp.DefaultBool(false),
+	)
+}
+
+// WithClientName sets the name under which this server's clients hold
+// note leases. By default each client is known by the name it sends when
+// initializing.
+func WithClientName(name string) Option {
+	return func(h *Handlers) {
+		h.clientName = name
+	}
+}
+
+// holder returns the name the client behind ctx holds leases under
+func (h *Handlers) holder(ctx context.Context) string {
+	if h.clientName != "" {
+		return h.clientName
+	}
+	if session, ok := server.ClientSessionFromContext(ctx).(server.SessionWithClientInfo); ok {
+		if name := session.GetClientInfo().Name; name != "" {
+			return name
+		}
+	}
+	return anonymousHolder
+}
+
+// LocksMiddleware returns a tool handler middleware that makes every call
+// on behalf of the client's lease holder, so writes fail with LOCKED on
+// notes another client has locked unless the call sets force.
+func (h *Handlers) LocksMiddleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			ctx = vault.HolderContext(ctx, h.holder(ctx), request.GetBool("force", false))
+			return next(ctx, request)
+		}
+	}
+}
+
+// LockNoteTool returns the ServerTool for leasing a note.
+func (h *Handlers) LockNoteTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"lock_note",
+		mcp.WithDescription("Lock a note while you edit it, so other clients sharing the vault cannot write, move or delete it until you call unlock_note or the lock expires. "+
+			"Locks are advisory leases kept in the vault, visible to every server process using it. Locking a note you already hold renews the lease. "+
+			"Fails with LOCKED, naming the holder and expiry, if another client holds it."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Path to the note (relative to vault root, must end with .md)."),
+			mcp.Required(),
+		),
+		mcp.WithString(
+			"purpose",
+			mcp.Description("What you are doing with the note, shown to clients the lock stops, e.g. \"rewriting the summary\"."),
+		),
+		mcp.WithNumber(
+			"ttl_seconds",
+			mcp.Description(fmt.Sprintf("How long the lock lasts unless renewed, at most %d. Defaults to the server's --lock-ttl.", int(vault.MaxLockTTL.Seconds()))),
+			mcp.Min(1),
+			mcp.Max(vault.MaxLockTTL.Seconds()),
+		),
+		mcp.WithBoolean(
+			"force",
+			mcp.Description("Take over a lock another client holds. Only use it when that client is known to be gone."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleLockNote,
+	}
+}
+
+// handleLockNote implements the lock_note tool handler.
+func (h *Handlers) handleLockNote(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	path, err := request.RequireString("path")
+	if err != nil {
+		return missingParamResult("path", err), nil
+	}
+
+	opts := vault.LockOptions{
+		Path:    path,
+		Purpose: request.GetString("purpose", ""),
+		TTL:     time.Duration(max(request.GetInt("ttl_seconds", 0), 0)) * time.Second,
+		Force:   request.GetBool("force", false),
+	}
+
+	// Call vault
+	lease, err := h.vault.LockNote(ctx, opts)
+	if err != nil {
+		return vaultErrorResult(err, "locking note", path), nil
+	}
+
+	return jsonResult(lease)
+}
+
+// UnlockNoteTool returns the ServerTool for releasing a note's lease.
+func (h *Handlers) UnlockNoteTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"unlock_note",
+		mcp.WithDescription("Release a lock taken with lock_note, so other clients can write the note again. Unlocking a note without a lock succeeds with released set to false."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Path to the note (relative to vault root, must end with .md)."),
+			mcp.Required(),
+		),
+		mcp.WithBoolean(
+			"force",
+			mcp.Description("Release a lock another client holds. Only use it when that client is known to be gone."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleUnlockNote,
+	}
+}
+
+// handleUnlockNote implements the unlock_note tool handler.
+func (h *Handlers) handleUnlockNote(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	path, err := request.RequireString("path")
+	if err != nil {
+		return missingParamResult("path", err), nil
+	}
+
+	// Call vault
+	released, err := h.vault.UnlockNote(ctx, path, request.GetBool("force", false))
+	if err != nil {
+		return vaultErrorResult(err, "unlocking note", path), nil
+	}
+
+	return jsonResult(unlockResult{Path: path, Released: released})
+}
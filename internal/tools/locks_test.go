@@ -0,0 +1,64 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// callAs invokes the named tool through LocksMiddleware
+func callAs(t *testing.T, h *Handlers, name string, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	for _, tool := range h.Tools() {
+		if tool.Tool.Name != name {
+			continue
+		}
+		var request mcp.CallToolRequest
+		request.Params.Name = name
+		request.Params.Arguments = args
+		result, err := h.LocksMiddleware()(tool.Handler)(context.Background(), request)
+		if err != nil {
+			t.Fatalf("%s returned error: %v", name, err)
+		}
+		return result
+	}
+	t.Fatalf("No tool named %s", name)
+	return nil
+}
+
+func TestLockNoteTools(t *testing.T) {
+	alice, _ := rootsHandlers(t)
+	bob := NewHandlers(alice.vault, slog.New(slog.NewTextHandler(io.Discard, nil)), WithClientName("bob"))
+	alice.clientName = "alice"
+
+	result := callAs(t, alice, "lock_note", map[string]any{"path": "Work/plan.md", "purpose": "rewriting", "ttl_seconds": 60})
+	if result.IsError {
+		t.Fatalf("lock_note failed: %s", resultText(result))
+	}
+
+	result = callAs(t, bob, "update_note", map[string]any{"path": "Work/plan.md", "content": "# Mine"})
+	checkToolError(t, result, CodeLocked)
+	if text := resultText(result); !strings.Contains(text, "alice") || !strings.Contains(text, "rewriting") {
+		t.Errorf("LOCKED error does not name the holder and purpose: %s", text)
+	}
+
+	checkToolError(t, callAs(t, bob, "unlock_note", map[string]any{"path": "Work/plan.md"}), CodeLocked)
+
+	if result := callAs(t, bob, "update_note", map[string]any{"path": "Work/plan.md", "content": "# Forced", "force": true}); result.IsError {
+		t.Errorf("forced update_note failed: %s", resultText(result))
+	}
+
+	result = callAs(t, alice, "unlock_note", map[string]any{"path": "Work/plan.md"})
+	var unlocked unlockResult
+	if err := json.Unmarshal([]byte(resultText(result)), &unlocked); err != nil || !unlocked.Released {
+		t.Errorf("unlock_note = %s, want released", resultText(result))
+	}
+	if result := callAs(t, bob, "update_note", map[string]any{"path": "Work/plan.md", "content": "# Mine"}); result.IsError {
+		t.Errorf("update_note after unlock failed: %s", resultText(result))
+	}
+}
@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// maxLoggedParamLen caps the length of string parameters in log lines so
+// note content does not flood the log
+const maxLoggedParamLen = 200
+
+// LoggingMiddleware returns a tool handler middleware that logs every tool
+// call with its name, parameters, duration, result size and error, if any.
+func (h *Handlers) LoggingMiddleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			start := time.Now()
+			result, err := next(ctx, request)
+
+			attrs := []any{
+				slog.String("tool", request.Params.Name),
+				slog.Any("params", truncateParams(request.GetArguments())),
+				slog.Duration("duration", time.Since(start)),
+				slog.Int("result_size", resultSize(result)),
+			}
+
+			switch {
+			case err != nil:
+				h.logger.ErrorContext(ctx, "tool call failed", append(attrs, slog.Any("error", err))...)
+			case result != nil && result.IsError:
+				if toolErr, ok := resultError(result); ok {
+					attrs = append(attrs, slog.String("code", string(toolErr.Code)), slog.String("error", toolErr.Message))
+				} else {
+					attrs = append(attrs, slog.String("error", resultText(result)))
+				}
+				h.logger.WarnContext(ctx, "tool call returned error", attrs...)
+			default:
+				h.logger.InfoContext(ctx, "tool call", attrs...)
+			}
+
+			return result, err
+		}
+	}
+}
+
+// truncateParams returns a copy of params with long strings shortened,
+// cut at the start of a UTF-8 character so log lines stay valid text
+func truncateParams(params map[string]any) map[string]any {
+	truncated := make(map[string]any, len(params))
+	for key, value := range params {
+		if s, ok := value.(string); ok && len(s) > maxLoggedParamLen {
+			cut := maxLoggedParamLen
+			for cut > 0 && !utf8.RuneStart(s[cut]) {
+				cut--
+			}
+			value = s[:cut] + "...(truncated)"
+		}
+		truncated[key] = value
+	}
+	return truncated
+}
+
+// resultSize returns the total length of the text content in result
+func resultSize(result *mcp.CallToolResult) int {
+	if result == nil {
+		return 0
+	}
+
+	size := 0
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			size += len(text.Text)
+		}
+	}
+	return size
+}
+
+// resultText returns the first text content in result
+func resultText(result *mcp.CallToolResult) string {
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			return text.Text
+		}
+	}
+	return ""
+}
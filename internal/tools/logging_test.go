@@ -0,0 +1,24 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateParams(t *testing.T) {
+	// A two-byte character straddles the cut
+	long := strings.Repeat("a", maxLoggedParamLen-1) + strings.Repeat("é", 10)
+	got := truncateParams(map[string]any{"content": long, "path": "Note.md", "limit": 5})
+
+	content := got["content"].(string)
+	if !utf8.ValidString(content) {
+		t.Errorf("content = %q, want valid UTF-8", content)
+	}
+	if want := strings.Repeat("a", maxLoggedParamLen-1) + "...(truncated)"; content != want {
+		t.Errorf("content = %q, want %q", content, want)
+	}
+	if got["path"] != "Note.md" || got["limit"] != 5 {
+		t.Errorf("truncateParams() = %v, want short params unchanged", got)
+	}
+}
@@ -0,0 +1,217 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// withDryRun returns the dry_run option shared by the cleanup tools.
+func withDryRun() mcp.ToolOption {
+	return mcp.WithBoolean(
+		"dry_run",
+		mcp.Description("List what would be removed and the bytes it would reclaim without removing anything."),
+		mcp.DefaultBool(false),
+	)
+}
+
+// withOlderThan returns the older_than option of the cleanup tools.
+func withOlderThan(what string) mcp.ToolOption {
+	return mcp.WithString(
+		"older_than",
+		mcp.Description(fmt.Sprintf("Only remove %s before this point: a duration back from now (e.g. \"72h\", \"30d\"), a date (\"2024-03-01\") or an RFC3339 timestamp.", what)),
+	)
+}
+
+// olderThanParam parses the optional older_than parameter, returning the
+// zero time when it is not set.
+func olderThanParam(request mcp.CallToolRequest) (time.Time, *mcp.CallToolResult) {
+	param := request.GetString("older_than", "")
+	if param == "" {
+		return time.Time{}, nil
+	}
+	before, err := parseTime(param, time.Now())
+	if err != nil {
+		return time.Time{}, invalidParamResult("older_than", err)
+	}
+	return before, nil
+}
+
+// MaintenanceStatusTool returns the ServerTool for reporting the disk use of
+// the data the server keeps beside the notes.
+func (h *Handlers) MaintenanceStatusTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"maintenance_status",
+		mcp.WithDescription("Report the disk use of the data the server keeps in the vault's .mcp-notes directory: the trash, note backups and audit log, each with its file count, bytes and oldest and newest entries, and the size of the search index. "+
+			"Use prune_backups, empty_trash and compact_index to reclaim it."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleMaintenanceStatus,
+	}
+}
+
+// handleMaintenanceStatus implements the maintenance_status tool handler.
+func (h *Handlers) handleMaintenanceStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Call vault
+	status, err := h.vault.MaintenanceStatus(ctx)
+	if err != nil {
+		return vaultErrorResult(err, "reading maintenance status", ""), nil
+	}
+
+	return jsonResult(status)
+}
+
+// PruneBackupsTool returns the ServerTool for removing old note backups.
+func (h *Handlers) PruneBackupsTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"prune_backups",
+		mcp.WithDescription("Remove earlier versions of notes kept as backups. Keeps the newest keep versions of each note, and of the rest removes only those taken before older_than when it is set; set keep, older_than or both. "+
+			"Files that cannot be removed are listed in failed without stopping the cleanup."),
+		mcp.WithNumber(
+			"keep",
+			mcp.Description("Newest versions to keep per note. 0 removes versions by age only."),
+			mcp.DefaultNumber(0),
+			mcp.Min(0),
+		),
+		withOlderThan("versions taken"),
+		withDryRun(),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handlePruneBackups,
+	}
+}
+
+// handlePruneBackups implements the prune_backups tool handler.
+func (h *Handlers) handlePruneBackups(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	before, errResult := olderThanParam(request)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	// Call vault
+	result, err := h.vault.PruneBackups(ctx, vault.PruneBackupsOptions{
+		Keep:   request.GetInt("keep", 0),
+		Before: before,
+		DryRun: request.GetBool("dry_run", false),
+	})
+	if err != nil {
+		return vaultErrorResult(err, "pruning backups", ""), nil
+	}
+
+	return jsonResult(result)
+}
+
+// EmptyTrashTool returns the ServerTool for removing trashed notes for good.
+func (h *Handlers) EmptyTrashTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"empty_trash",
+		mcp.WithDescription("Permanently remove notes from the trash, where deleted and merged notes are kept. Use older_than so recent deletions can still be recovered. "+
+			"Files that cannot be removed are listed in failed without stopping the cleanup."),
+		withOlderThan("notes trashed"),
+		withDryRun(),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleEmptyTrash,
+	}
+}
+
+// handleEmptyTrash implements the empty_trash tool handler.
+func (h *Handlers) handleEmptyTrash(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	before, errResult := olderThanParam(request)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	// Call vault
+	result, err := h.vault.EmptyTrash(ctx, vault.EmptyTrashOptions{
+		Before: before,
+		DryRun: request.GetBool("dry_run", false),
+	})
+	if err != nil {
+		return vaultErrorResult(err, "emptying the trash", ""), nil
+	}
+
+	return jsonResult(result)
+}
+
+// CompactIndexTool returns the ServerTool for dropping deleted notes from
+// the search index.
+func (h *Handlers) CompactIndexTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"compact_index",
+		mcp.WithDescription("Drop notes whose file no longer exists, such as those deleted outside the server, from the in-memory search index, and report the word-note postings freed. "+
+			"Requires the server to run with --search-index."),
+		withDryRun(),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleCompactIndex,
+	}
+}
+
+// handleCompactIndex implements the compact_index tool handler.
+func (h *Handlers) handleCompactIndex(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Call vault
+	result, err := h.vault.CompactIndex(ctx, request.GetBool("dry_run", false))
+	if err != nil {
+		return vaultErrorResult(err, "compacting the search index", ""), nil
+	}
+
+	return jsonResult(result)
+}
+
+// ReconnectVaultTool returns the ServerTool for revalidating the cache
+// after the vault's folder went away and came back.
+func (h *Handlers) ReconnectVaultTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"reconnect_vault",
+		mcp.WithDescription("Flush the note cache, the search index and the note listing, list the vault again and report the notes that appeared or disappeared compared with what the server knew of before. "+
+			"Use it after the vault's drive was remounted or a sync tool replaced files; the server also flushes on its own the first time it finds the vault's folder back. Fails with VAULT_UNAVAILABLE while the folder cannot be reached."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleReconnectVault,
+	}
+}
+
+// handleReconnectVault implements the reconnect_vault tool handler.
+func (h *Handlers) handleReconnectVault(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Call vault
+	result, err := h.vault.Reconnect(ctx)
+	if err != nil {
+		return vaultErrorResult(err, "reconnecting the vault", ""), nil
+	}
+
+	outside := func(path string) bool { return !h.inRoots(path) }
+	result.Appeared = slices.DeleteFunc(result.Appeared, outside)
+	result.Disappeared = slices.DeleteFunc(result.Disappeared, outside)
+	return jsonResult(result)
+}
@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// MergeNotesTool returns the ServerTool for merging one note into another.
+func (h *Handlers) MergeNotesTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"merge_notes",
+		mcp.WithDescription("Merge the source note into the target note. Tags and aliases are combined, links to the source anywhere in the vault are rewritten to point at the target, and the source is moved to the server's trash. The target is backed up first."),
+		mcp.WithString(
+			"source",
+			mcp.Description("Note to merge away (relative to vault root, must end with .md)."),
+			mcp.Required(),
+		),
+		mcp.WithString(
+			"target",
+			mcp.Description("Note receiving the source's content (relative to vault root, must end with .md)."),
+			mcp.Required(),
+		),
+		mcp.WithString(
+			"strategy",
+			mcp.Description("append adds the source under a heading with its title at the end of the target, prepend at the start. sections adds each section of the source to the end of the target section with the same heading, appending the rest."),
+			mcp.Enum(string(vault.MergeAppend), string(vault.MergePrepend), string(vault.MergeSections)),
+			mcp.DefaultString(string(vault.MergeAppend)),
+		),
+		mcp.WithBoolean(
+			"keep_source",
+			mcp.Description("Leave the source note in place instead of moving it to the trash. Links are rewritten either way."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithBoolean(
+			"dry_run",
+			mcp.Description("Return the merged content and the notes whose links would change without writing anything."),
+			mcp.DefaultBool(false),
+		),
+		withForce(),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleMergeNotes,
+	}
+}
+
+// handleMergeNotes implements the merge_notes tool handler.
+func (h *Handlers) handleMergeNotes(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	source, err := request.RequireString("source")
+	if err != nil {
+		return missingParamResult("source", err), nil
+	}
+
+	target, err := request.RequireString("target")
+	if err != nil {
+		return missingParamResult("target", err), nil
+	}
+
+	strategy, err := vault.ParseMergeStrategy(request.GetString("strategy", string(vault.MergeAppend)))
+	if err != nil {
+		return invalidParamResult("strategy", err), nil
+	}
+
+	// Call vault
+	result, err := h.vault.MergeNotes(ctx, vault.MergeOptions{
+		Source:     source,
+		Target:     target,
+		Strategy:   strategy,
+		KeepSource: request.GetBool("keep_source", false),
+		DryRun:     request.GetBool("dry_run", false),
+	})
+	if err != nil {
+		return vaultErrorResult(err, "merging notes", source), nil
+	}
+
+	return jsonResult(result)
+}
@@ -0,0 +1,37 @@
+package tools
+
+import (
+	"context"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/metrics"
+)
+
+// MetricsMiddleware returns a tool handler middleware that records the
+// duration of every tool call by tool, and failed calls by error code.
+// Calls failing with a Go error are counted as INTERNAL_ERROR.
+func (h *Handlers) MetricsMiddleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			start := time.Now()
+			result, err := next(ctx, request)
+			h.metrics.Observe(metrics.ToolCalls, request.Params.Name, time.Since(start))
+
+			switch {
+			case err != nil:
+				h.metrics.Add(metrics.ToolErrors, string(CodeInternal), 1)
+			case result != nil && result.IsError:
+				code := CodeInternal
+				if toolErr, ok := resultError(result); ok {
+					code = toolErr.Code
+				}
+				h.metrics.Add(metrics.ToolErrors, string(code), 1)
+			}
+
+			return result, err
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kratos/mcp-notes/internal/metrics"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	registry := metrics.NewRegistry()
+	h := NewHandlers(failingVault{}, logger, WithMetrics(registry))
+
+	handler := func(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		switch request.GetString("outcome", "") {
+		case "not_found":
+			return errorResult(ToolError{CodeNotFound, "note not found", ""}), nil
+		case "go_error":
+			return nil, errors.New("boom")
+		}
+		return textResult("ok"), nil
+	}
+	call := func(tool, outcome string) {
+		var request mcp.CallToolRequest
+		request.Params.Name = tool
+		request.Params.Arguments = map[string]any{"outcome": outcome}
+		h.MetricsMiddleware()(handler)(context.Background(), request)
+	}
+
+	call("read_note", "")
+	call("read_note", "not_found")
+	call("list_notes", "go_error")
+
+	counts := make(map[string]uint64)
+	for _, sample := range registry.Snapshot() {
+		counts[sample.Name+"/"+sample.Label] = sample.Value
+	}
+	want := map[string]uint64{
+		metrics.ToolCalls + "/read_note":                2,
+		metrics.ToolCalls + "/list_notes":               1,
+		metrics.ToolErrors + "/" + string(CodeNotFound): 1,
+		metrics.ToolErrors + "/" + string(CodeInternal): 1,
+	}
+	for key, value := range want {
+		if counts[key] != value {
+			t.Errorf("%s = %d, want %d", key, counts[key], value)
+		}
+	}
+	if len(counts) != len(want) {
+		t.Errorf("Recorded %v, want only %v", counts, want)
+	}
+}
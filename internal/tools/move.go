@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// MoveNoteTool returns the ServerTool for moving or renaming a note.
+func (h *Handlers) MoveNoteTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"move_note",
+		mcp.WithDescription("Move or rename a note. The destination must not exist. With update_links, links to the note anywhere in the vault are rewritten to its new name or path, keeping aliases, headings and block references."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Note to move (relative to vault root, must end with .md)."),
+			mcp.Required(),
+		),
+		mcp.WithString(
+			"new_path",
+			mcp.Description("New path of the note relative to the vault root, ending with .md. Missing parent folders are created."),
+			mcp.Required(),
+		),
+		mcp.WithBoolean(
+			"update_links",
+			mcp.Description("Rewrite wikilinks, embeds and markdown links to the note, and links that would otherwise resolve to a different note after the move, including relative links inside the moved note. Links in code and links to other notes of the same name are left alone."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithBoolean(
+			"dry_run",
+			mcp.Description("Return the notes and lines whose links would change without moving or writing anything."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithBoolean(
+			"sanitize",
+			mcp.Description("Clean up new_path before moving, like create_note does. The final path is returned."),
+			mcp.DefaultBool(true),
+		),
+		withForce(),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleMoveNote,
+	}
+}
+
+// handleMoveNote implements the move_note tool handler.
+func (h *Handlers) handleMoveNote(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	path, err := request.RequireString("path")
+	if err != nil {
+		return missingParamResult("path", err), nil
+	}
+
+	newPath, err := request.RequireString("new_path")
+	if err != nil {
+		// Not the folder of rename_folder's new_path
+		return missingParamHintResult("new_path", hintNotePath, err), nil
+	}
+
+	// Normalize the destination the model supplied
+	if request.GetBool("sanitize", true) {
+		requested := newPath
+		newPath, err = vault.SanitizePath(newPath)
+		if err != nil {
+			return vaultErrorResult(err, "moving note", requested), nil
+		}
+	}
+
+	// Call vault
+	result, err := h.vault.MoveNote(ctx, vault.MoveNoteOptions{
+		Path:        path,
+		NewPath:     newPath,
+		UpdateLinks: request.GetBool("update_links", false),
+		DryRun:      request.GetBool("dry_run", false),
+	})
+	if err != nil {
+		return vaultErrorResult(err, "moving note", path), nil
+	}
+
+	return jsonResult(result)
+}
@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// Bounds of the get_outline max_notes parameter
+const (
+	defaultOutlineNotes = 50
+	maxOutlineNotes     = 500
+)
+
+// GetOutlineTool returns the ServerTool for the heading outline of a note
+// or folder.
+func (h *Handlers) GetOutlineTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"get_outline",
+		mcp.WithDescription("Show the structure of a note, or of every note in a folder, without reading them in full. Returns per note its word count and heading tree: level, text, line and the words in each heading's section, with subheadings nested under children. Both # and underlined (=== / ---) headings are recognized; those in code blocks are ignored. Folder outlines are ordered by path and come from a single walk."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Note (ending with .md) or folder, relative to vault root. If empty, outlines the whole vault."),
+		),
+		mcp.WithNumber(
+			"max_depth",
+			mcp.Description("Deepest heading level to include, 1 for # headings only."),
+			mcp.DefaultNumber(6),
+			mcp.Min(1),
+			mcp.Max(6),
+		),
+		mcp.WithNumber(
+			"max_notes",
+			mcp.Description("Maximum number of notes outlined for a folder; total reports how many there are."),
+			mcp.DefaultNumber(defaultOutlineNotes),
+			mcp.Min(1),
+			mcp.Max(maxOutlineNotes),
+		),
+		mcp.WithBoolean(
+			"include_hidden",
+			mcp.Description("Whether to include files and folders whose name starts with a dot."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleGetOutline,
+	}
+}
+
+// handleGetOutline implements the get_outline tool handler.
+func (h *Handlers) handleGetOutline(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	maxDepth := request.GetInt("max_depth", 6)
+	if maxDepth < 1 || maxDepth > 6 {
+		return invalidParamResult("max_depth", fmt.Errorf("expected 1 to 6, got %d", maxDepth)), nil
+	}
+	opts := vault.OutlineOptions{
+		Path:          request.GetString("path", ""),
+		MaxDepth:      maxDepth,
+		MaxNotes:      min(max(request.GetInt("max_notes", defaultOutlineNotes), 1), maxOutlineNotes),
+		IncludeHidden: request.GetBool("include_hidden", false),
+	}
+
+	// Call vault
+	outline, err := h.vault.Outline(ctx, opts)
+	if err != nil {
+		return vaultErrorResult(err, "outlining notes", opts.Path), nil
+	}
+
+	return fitJSON(len(outline.Notes), h.maxResponseBytes, func(n int) any {
+		cut := outline
+		cut.Notes = outline.Notes[:n]
+		cut.Truncated = outline.Truncated || n < len(outline.Notes)
+		return cut
+	})
+}
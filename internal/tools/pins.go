@@ -0,0 +1,143 @@
+package tools
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// withPinnedFirst adds the pinned_first parameter of tools listing notes
+func withPinnedFirst(description string) mcp.ToolOption {
+	return mcp.WithBoolean(
+		"pinned_first",
+		mcp.Description("Put notes pinned with pin_note before the others, each group in the chosen order. "+description),
+	)
+}
+
+// PinNoteTool returns the ServerTool for pinning a note.
+func (h *Handlers) PinNoteTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"pin_note",
+		mcp.WithDescription("Pin a note to the working set, e.g. the project page you keep coming back to. Pinned notes are marked pinned in list_notes and search_notes, "+
+			"come first in relevance-ranked searches and in lists with pinned_first, and stay in the server's cache. "+
+			"Pins are kept in the vault's .mcp-notes folder; when it cannot be written they last until the server stops and session_only is true. "+
+			"Pinning a pinned note again restarts its pin. Returns the working set."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Path to the note (relative to vault root, must end with .md)."),
+			mcp.Required(),
+		),
+		mcp.WithString(
+			"duration",
+			mcp.Description("How long the pin lasts, e.g. \"8h\", \"90m\", \"2d\" or \"1w\". Defaults to until unpin_note."),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handlePinNote,
+	}
+}
+
+// handlePinNote implements the pin_note tool handler.
+func (h *Handlers) handlePinNote(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	path, err := request.RequireString("path")
+	if err != nil {
+		return missingParamResult("path", err), nil
+	}
+
+	var ttl time.Duration
+	if value := request.GetString("duration", ""); value != "" {
+		ttl, err = parseDuration(value)
+		if err != nil {
+			return invalidParamResult("duration", err), nil
+		}
+	}
+
+	// Call vault
+	pins, err := h.vault.PinNote(ctx, path, ttl)
+	if err != nil {
+		return vaultErrorResult(err, "pinning note", path), nil
+	}
+
+	return jsonResult(h.scopePins(pins))
+}
+
+// UnpinNoteTool returns the ServerTool for unpinning a note.
+func (h *Handlers) UnpinNoteTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"unpin_note",
+		mcp.WithDescription("Remove a note pinned with pin_note from the working set. Unpinning a note that is not pinned changes nothing. Returns the working set."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Path to the note (relative to vault root, must end with .md)."),
+			mcp.Required(),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleUnpinNote,
+	}
+}
+
+// handleUnpinNote implements the unpin_note tool handler.
+func (h *Handlers) handleUnpinNote(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	path, err := request.RequireString("path")
+	if err != nil {
+		return missingParamResult("path", err), nil
+	}
+
+	// Call vault
+	pins, err := h.vault.UnpinNote(ctx, path)
+	if err != nil {
+		return vaultErrorResult(err, "unpinning note", path), nil
+	}
+
+	return jsonResult(h.scopePins(pins))
+}
+
+// ListPinnedTool returns the ServerTool for listing pinned notes.
+func (h *Handlers) ListPinnedTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"list_pinned",
+		mcp.WithDescription("List the notes pinned with pin_note, sorted by path, with when each was pinned and when its pin expires, if ever. "+
+			"Expired pins are left out. session_only is true when pins cannot be saved in the vault and last until the server stops."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleListPinned,
+	}
+}
+
+// handleListPinned implements the list_pinned tool handler.
+func (h *Handlers) handleListPinned(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pins, err := h.vault.ListPinned(ctx)
+	if err != nil {
+		return vaultErrorResult(err, "listing pinned notes", ""), nil
+	}
+
+	return jsonResult(h.scopePins(pins))
+}
+
+// scopePins drops the pins outside the client's roots from the working set
+func (h *Handlers) scopePins(pins vault.PinSet) vault.PinSet {
+	pins.Pins = slices.DeleteFunc(pins.Pins, func(pin vault.Pin) bool {
+		return !h.inRoots(pin.Path)
+	})
+	return pins
+}
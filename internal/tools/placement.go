@@ -0,0 +1,80 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// SuggestPlacementTool returns the ServerTool for suggesting the folder a
+// new note belongs in.
+func (h *Handlers) SuggestPlacementTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"suggest_placement",
+		mcp.WithDescription("Suggest the folders a new note belongs in before creating it. The draft's tags, and the vault tags its words name, are compared with the tags of the notes in each folder; with note types configured the draft's type with the type most of a folder's notes have; and its title with their names. "+
+			"Each suggestion has a confidence from 0 to 1 with its breakdown, example notes already there and tags to add so the note fits in. Only cached metadata is used, so nothing is read and identical drafts get identical suggestions; uncached_notes counts notes compared by name only. "+
+			"When nothing matches, the folder of the capture inbox is suggested with fallback set."),
+		mcp.WithString(
+			"content",
+			mcp.Required(),
+			mcp.Description("Raw markdown of the draft, with or without frontmatter."),
+		),
+		mcp.WithString(
+			"title",
+			mcp.Description("Proposed title. Defaults to the draft's frontmatter title or first heading."),
+		),
+		mcp.WithNumber(
+			"limit",
+			mcp.Description(fmt.Sprintf("Maximum number of folders to suggest (at most %d).", vault.MaxPlacementLimit)),
+			mcp.DefaultNumber(vault.DefaultPlacementLimit),
+			mcp.Min(1),
+			mcp.Max(vault.MaxPlacementLimit),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleSuggestPlacement,
+	}
+}
+
+// handleSuggestPlacement implements the suggest_placement tool handler.
+func (h *Handlers) handleSuggestPlacement(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	content, err := request.RequireString("content")
+	if err != nil {
+		return missingParamResult("content", err), nil
+	}
+	limit := min(max(request.GetInt("limit", vault.DefaultPlacementLimit), 1), vault.MaxPlacementLimit)
+
+	// Call vault, asking for every folder so those outside the roots can
+	// be left out before limiting
+	placement, err := h.vault.SuggestPlacement(ctx, vault.PlacementOptions{
+		Content: content,
+		Title:   request.GetString("title", ""),
+		Limit:   vault.MaxPlacementLimit,
+	})
+	if err != nil {
+		return vaultErrorResult(err, "suggesting a placement", ""), nil
+	}
+
+	placement.Suggestions = slices.DeleteFunc(placement.Suggestions, func(s vault.PlacementSuggestion) bool {
+		return !s.Fallback && !h.inRoots(s.Folder)
+	})
+	for i, s := range placement.Suggestions {
+		placement.Suggestions[i].Examples = slices.DeleteFunc(s.Examples, func(path string) bool {
+			return !h.inRoots(path)
+		})
+	}
+	placement.Suggestions = placement.Suggestions[:min(len(placement.Suggestions), limit)]
+
+	return jsonResult(placement)
+}
@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ToolPolicy selects the tools RegisterTools exposes. The zero value
+// exposes every tool.
+type ToolPolicy struct {
+	ReadOnly bool     // Leave out every tool that can modify the vault
+	Allow    []string // Expose only these tools; empty allows every tool
+	Disable  []string // Never expose these tools
+}
+
+// WithToolPolicy sets which tools RegisterTools exposes.
+func WithToolPolicy(policy ToolPolicy) Option {
+	return func(h *Handlers) {
+		h.policy = policy
+	}
+}
+
+// ToolNames returns the name of every tool the server provides, in
+// registration order.
+func ToolNames() []string {
+	h := NewHandlers(nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	var names []string
+	for _, tool := range h.Tools() {
+		names = append(names, tool.Tool.Name)
+	}
+	return names
+}
+
+// Validate reports tool names in the policy that no tool has.
+func (p ToolPolicy) Validate() error {
+	valid := ToolNames()
+	var unknown []string
+	for _, name := range slices.Concat(p.Allow, p.Disable) {
+		if !slices.Contains(valid, name) && !slices.Contains(unknown, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown tool %s; valid tools are %s",
+			strings.Join(unknown, ", "), strings.Join(valid, ", "))
+	}
+	return nil
+}
+
+// Exposes reports whether the policy lets clients see tool.
+func (p ToolPolicy) Exposes(tool mcp.Tool) bool {
+	if p.ReadOnly && !isReadOnlyTool(tool) {
+		return false
+	}
+	if len(p.Allow) > 0 && !slices.Contains(p.Allow, tool.Name) {
+		return false
+	}
+	return !slices.Contains(p.Disable, tool.Name)
+}
+
+// isReadOnlyTool reports whether tool is annotated as never modifying the
+// vault; tools without the annotation count as writes
+func isReadOnlyTool(tool mcp.Tool) bool {
+	hint := tool.Annotations.ReadOnlyHint
+	return hint != nil && *hint
+}
+
+// EnabledTools returns the tools the handlers' policy exposes.
+func (h *Handlers) EnabledTools() []server.ServerTool {
+	var enabled []server.ServerTool
+	for _, tool := range h.Tools() {
+		if h.policy.Exposes(tool.Tool) {
+			enabled = append(enabled, tool)
+		}
+	}
+	return enabled
+}
+
+// disabledTools returns the names of the tools the policy hides
+func (h *Handlers) disabledTools() []string {
+	var disabled []string
+	for _, tool := range h.Tools() {
+		if !h.policy.Exposes(tool.Tool) {
+			disabled = append(disabled, tool.Tool.Name)
+		}
+	}
+	return disabled
+}
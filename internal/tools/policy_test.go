@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// writeTools are the tools that modify the vault
+var writeTools = []string{"create_note", "update_note", "create_folder", "rename_folder", "move_note", "merge_notes", "split_note", "apply_changes", "replace_in_notes", "capture", "add_link", "add_alias", "remove_alias", "sync_titles", "publish_note", "unpublish_note", "mark_published", "lock_note", "unlock_note", "pin_note", "unpin_note", "create_scratch", "update_scratch", "promote_scratch", "generate_rollup", "restore_note_version", "prune_backups", "empty_trash", "compact_index", "lint_note", "set_note_annotation", "save_search", "delete_saved_search"}
+
+// registeredTools returns the sorted names of the tools policy registers
+func registeredTools(policy ToolPolicy) []string {
+	h := NewHandlers(failingVault{}, slog.New(slog.NewTextHandler(io.Discard, nil)), WithToolPolicy(policy))
+	srv := server.NewMCPServer("notes", "test")
+	h.RegisterTools(srv)
+
+	var names []string
+	for name := range srv.ListTools() {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// without returns names minus the excluded ones
+func without(names []string, excluded ...string) []string {
+	var kept []string
+	for _, name := range names {
+		if !slices.Contains(excluded, name) {
+			kept = append(kept, name)
+		}
+	}
+	return kept
+}
+
+func TestToolPolicy(t *testing.T) {
+	all := ToolNames()
+	slices.Sort(all)
+
+	tests := []struct {
+		name   string
+		policy ToolPolicy
+		want   []string
+	}{
+		{"default", ToolPolicy{}, all},
+		{"read-only", ToolPolicy{ReadOnly: true}, without(all, writeTools...)},
+		{"disable", ToolPolicy{Disable: []string{"create_note", "update_note"}}, without(all, "create_note", "update_note")},
+		{"allowlist", ToolPolicy{Allow: []string{"search_notes", "list_notes", "read_note"}}, []string{"list_notes", "read_note", "search_notes"}},
+		{"allowlist and disable", ToolPolicy{Allow: []string{"list_notes", "read_note"}, Disable: []string{"read_note"}}, []string{"list_notes"}},
+		{"read-only allowlist", ToolPolicy{ReadOnly: true, Allow: []string{"read_note", "update_note"}}, []string{"read_note"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := registeredTools(tt.policy); !slices.Equal(got, tt.want) {
+				t.Errorf("Registered tools = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToolPolicyValidate(t *testing.T) {
+	valid := ToolPolicy{ReadOnly: true, Allow: []string{"read_note"}, Disable: []string{"create_note"}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	invalid := ToolPolicy{Allow: []string{"read_note", "read_notez"}, Disable: []string{"delete_note"}}
+	err := invalid.Validate()
+	if err == nil {
+		t.Fatal("Validate() accepted unknown tools")
+	}
+	for _, want := range []string{"read_notez, delete_note", "valid tools are server_info, list_notes"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error = %q, want it to contain %q", err, want)
+		}
+	}
+}
+
+func TestServerInfoListsDisabledTools(t *testing.T) {
+	h := NewHandlers(failingVault{}, slog.New(slog.NewTextHandler(io.Discard, nil)), WithToolPolicy(ToolPolicy{ReadOnly: true}))
+	if got := h.disabledTools(); !slices.Equal(got, writeTools) {
+		t.Errorf("disabledTools() = %v, want %v", got, writeTools)
+	}
+}
@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// methodProgress is the notification carrying the progress of a call
+const methodProgress = "notifications/progress"
+
+// progressInterval is the shortest time between two progress
+// notifications of a call
+const progressInterval = 250 * time.Millisecond
+
+// ProgressMiddleware returns a tool handler middleware that reports how
+// far the vault walks of a call got to the client, when the call carries
+// a progress token. Calls without one run as they would without it.
+func (h *Handlers) ProgressMiddleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			srv := server.ServerFromContext(ctx)
+			if request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil || srv == nil {
+				return next(ctx, request)
+			}
+
+			token := request.Params.Meta.ProgressToken
+			p := &progressNotifier{interval: progressInterval, send: func(progress, total int) {
+				params := map[string]any{
+					"progressToken": token,
+					"progress":      progress,
+					"message":       fmt.Sprintf("%d notes scanned", progress),
+				}
+				if total > 0 {
+					params["total"] = total
+					params["message"] = fmt.Sprintf("%d of %d notes scanned", progress, total)
+				}
+				if err := srv.SendNotificationToClient(ctx, methodProgress, params); err != nil {
+					h.logger.Debug("sending progress failed", "tool", request.Params.Name, "error", err)
+				}
+			}}
+			return next(vault.ProgressContext(ctx, p.report), request)
+		}
+	}
+}
+
+// progressNotifier turns the progress of the walks of a call into
+// notifications whose progress only grows, at most one per interval
+type progressNotifier struct {
+	interval time.Duration
+	send     func(progress, total int)
+
+	mu       sync.Mutex
+	base     int       // Notes of the walks already finished
+	done     int       // Notes done in the current walk
+	total    int       // Notes in the current walk, 0 when unknown
+	sent     int       // Progress of the last notification
+	lastSent time.Time // Time of the last notification
+}
+
+// report is the vault.ProgressFunc of the call
+func (p *progressNotifier) report(done, total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch {
+	case done == 0 && p.done > 0:
+		// A new walk started; count the previous one as finished
+		p.base += max(p.total, p.done)
+		p.done, p.total = 0, total
+	case done < p.done:
+		return // Reported late by another worker
+	default:
+		p.done, p.total = done, total
+	}
+
+	progress := p.base + p.done
+	now := time.Now()
+	if !p.lastSent.IsZero() && (progress <= p.sent || now.Sub(p.lastSent) < p.interval) {
+		return
+	}
+	overall := 0
+	if p.total > 0 {
+		overall = p.base + max(p.total, p.done)
+	}
+	p.sent, p.lastSent = progress, now
+	p.send(progress, overall)
+}
@@ -0,0 +1,36 @@
+package tools
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestProgressNotifier(t *testing.T) {
+	var sent [][2]int
+	p := &progressNotifier{send: func(progress, total int) {
+		sent = append(sent, [2]int{progress, total})
+	}}
+
+	// Two walks: the second counts on from the first, and a late report
+	// of the first walk's workers is dropped
+	for _, r := range [][2]int{{0, 8}, {1, 4}, {3, 4}, {2, 4}, {4, 4}, {0, 0}, {1, 2}, {2, 2}} {
+		p.report(r[0], r[1])
+	}
+	want := [][2]int{{0, 8}, {1, 4}, {3, 4}, {4, 4}, {5, 6}, {6, 6}}
+	if !slices.Equal(sent, want) {
+		t.Errorf("Notifications = %v, want %v", sent, want)
+	}
+
+	// Reports closer together than the interval are left out
+	sent = nil
+	p = &progressNotifier{interval: time.Hour, send: func(progress, total int) {
+		sent = append(sent, [2]int{progress, total})
+	}}
+	for done := range 100 {
+		p.report(done, 100)
+	}
+	if len(sent) != 1 {
+		t.Errorf("Notifications = %v, want only the first", sent)
+	}
+}
@@ -0,0 +1,183 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// publishableResult is a PublishableReport cut to the response limit
+type publishableResult struct {
+	vault.PublishableReport
+	Truncated bool `json:"truncated,omitempty"` // Notes were left out of the report
+}
+
+// PublishNoteTool returns the ServerTool for marking a note for
+// publishing.
+func (h *Handlers) PublishNoteTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"publish_note",
+		mcp.WithDescription("Mark a note for publishing by setting the server's publish property (publish by default) to true in its frontmatter, and the publish date property (published_at by default), when configured, to the current time. "+
+			"Only the lines of those properties change; the others, their order and comments stay as written, and a missing frontmatter block is created. "+
+			"Nothing is written when the note is already marked; changed is then false and the date is kept. The note then shows in list_publishable until mark_published records it."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Path of the note (relative to vault root, must end with .md)."),
+			mcp.Required(),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handlePublishNote,
+	}
+}
+
+// UnpublishNoteTool returns the ServerTool for clearing the publish mark
+// of a note.
+func (h *Handlers) UnpublishNoteTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"unpublish_note",
+		mcp.WithDescription("Clear a note's publish mark by setting the publish property to false, keeping the publish date. Only that line changes. "+
+			"Nothing is written when the note is not marked; changed is then false. A note already published shows in list_publishable as withdrawn until mark_published acknowledges it."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Path of the note (relative to vault root, must end with .md)."),
+			mcp.Required(),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleUnpublishNote,
+	}
+}
+
+// ListPublishableTool returns the ServerTool for listing the notes a
+// publishing pipeline has to act on.
+func (h *Handlers) ListPublishableTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"list_publishable",
+		mcp.WithDescription("List the notes a publishing pipeline has to act on: notes marked for publishing that were never published (state new) or changed since they were (changed), "+
+			"and notes published before whose mark was cleared since (withdrawn). What was published is known from the content hash mark_published recorded, kept in the server's data directory and not in the notes. "+
+			"Returns each note's path, state, current content_hash and, once published, last_published_hash and last_published, plus the number of marked notes unchanged since published."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Folder to list, relative to vault root. If empty, the whole vault."),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleListPublishable,
+	}
+}
+
+// MarkPublishedTool returns the ServerTool for recording that a note was
+// published.
+func (h *Handlers) MarkPublishedTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"mark_published",
+		mcp.WithDescription("Record that a note was published as it is now, after the publishing pipeline ran, so list_publishable leaves it out until it changes. "+
+			"For a withdrawn note, no longer marked, it drops the record instead. The record is kept in the server's data directory; the note is not modified. "+
+			"Pass the content_hash list_publishable returned as revision to fail with CONFLICT when the note changed since, so a version the pipeline did not publish is never recorded."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Path of the note (relative to vault root, must end with .md)."),
+			mcp.Required(),
+		),
+		mcp.WithString(
+			"revision",
+			mcp.Description("The content_hash of the note as published. If empty, the current content is recorded."),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleMarkPublished,
+	}
+}
+
+// handlePublishNote implements the publish_note tool handler.
+func (h *Handlers) handlePublishNote(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	path, err := request.RequireString("path")
+	if err != nil {
+		return missingParamResult("path", err), nil
+	}
+
+	// Call vault
+	result, err := h.vault.PublishNote(ctx, path)
+	if err != nil {
+		return vaultErrorResult(err, "publishing note", path), nil
+	}
+
+	return jsonResult(result)
+}
+
+// handleUnpublishNote implements the unpublish_note tool handler.
+func (h *Handlers) handleUnpublishNote(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	path, err := request.RequireString("path")
+	if err != nil {
+		return missingParamResult("path", err), nil
+	}
+
+	// Call vault
+	result, err := h.vault.UnpublishNote(ctx, path)
+	if err != nil {
+		return vaultErrorResult(err, "unpublishing note", path), nil
+	}
+
+	return jsonResult(result)
+}
+
+// handleListPublishable implements the list_publishable tool handler.
+func (h *Handlers) handleListPublishable(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	path := request.GetString("path", "")
+
+	// Call vault
+	report, err := h.vault.ListPublishable(ctx, path)
+	if err != nil {
+		return vaultErrorResult(err, "listing publishable notes", path), nil
+	}
+
+	return fitJSON(len(report.Notes), h.maxResponseBytes, func(n int) any {
+		cut := publishableResult{PublishableReport: report, Truncated: n < len(report.Notes)}
+		cut.Notes = report.Notes[:n]
+		return cut
+	})
+}
+
+// handleMarkPublished implements the mark_published tool handler.
+func (h *Handlers) handleMarkPublished(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	path, err := request.RequireString("path")
+	if err != nil {
+		return missingParamResult("path", err), nil
+	}
+	revision := request.GetString("revision", "")
+
+	// Call vault
+	result, err := h.vault.MarkPublished(ctx, path, revision)
+	if err != nil {
+		return vaultErrorResult(err, "marking note published", path), nil
+	}
+
+	return jsonResult(result)
+}
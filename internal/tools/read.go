@@ -3,21 +3,69 @@ package tools
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
 )
 
 // ReadNoteTool returns the ServerTool for reading a note's content.
 func (h *Handlers) ReadNoteTool() server.ServerTool {
 	tool := mcp.NewTool(
 		"read_note",
-		mcp.WithDescription("Read the full content of a note by its path."),
+		mcp.WithDescription("Read the full content of a note by its path, or by its name, title or alias, or only the section under a heading or a ^block. "+
+			"Content over the response size limit is cut at a line break, followed by a notice with the offset to continue from. "+
+			"A note mostly made of embedded data, such as an Excalidraw drawing, is returned as its prose unless force_full is set."),
 		mcp.WithString(
 			"path",
-			mcp.Description("Path to the note file (relative to vault root, must end with .md)."),
-			mcp.Required(),
+			mcp.Description("Path to the note file (relative to vault root, must end with .md). Either path or name is required."),
+		),
+		mcp.WithString(
+			"name",
+			mcp.Description("Note name, frontmatter title or alias to resolve instead of a path. Fails with a list of candidates when ambiguous."),
+		),
+		mcp.WithBoolean(
+			"force_full",
+			mcp.Description("Return a note mostly made of embedded data, such as an Excalidraw drawing, in full. By default only its prose is returned, with a notice per stretch of data left out and a summary of the note."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithString(
+			"heading",
+			mcp.Description("Return only the section under this heading, up to the next heading of the same or a higher level. Use \"Parent#Child\" for a nested heading."),
+		),
+		mcp.WithString(
+			"block",
+			mcp.Description("Return only the block with this ID, as marked with ^id: the line for headings, the item with its nested items for lists, or the whole paragraph."),
 		),
+		mcp.WithBoolean(
+			"expand_embeds",
+			mcp.Description(fmt.Sprintf("Replace each ![[Note]] embed with the embedded note's body, or ![[Note#Heading]] and ![[Note#^block]] with that section or block, between <!-- embed: path --> and <!-- end embed: path --> comments. "+
+				"Attachment embeds are left as written. At most %d characters are inlined in total.", vault.DefaultMaxEmbedSize)),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithNumber(
+			"max_depth",
+			mcp.Description("With expand_embeds, how many levels of embeds inside embedded notes to expand. A note is never expanded inside itself."),
+			mcp.DefaultNumber(vault.DefaultEmbedDepth),
+			mcp.Min(1),
+			mcp.Max(vault.MaxEmbedDepth),
+		),
+		mcp.WithBoolean(
+			"include_images",
+			mcp.Description(fmt.Sprintf("Also return the PNG, JPEG, GIF and WebP images the note, or the section read, embeds as image content, in document order, so figures can be seen with the text. "+
+				"At most %d images of up to %d bytes each and %d bytes together are returned, with the first page only; the others are listed in a notice.", maxNoteImages, defaultImageMaxBytes, noteImagesMaxBytes)),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithNumber(
+			"offset",
+			mcp.Description("Byte offset into the content to start from, as given by the notice of a truncated read."),
+			mcp.DefaultNumber(0),
+			mcp.Min(0),
+		),
+		withMaxBytes(),
+		mcp.WithOutputSchema[ReadResponse](),
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithDestructiveHintAnnotation(false),
 	)
@@ -31,40 +79,163 @@ func (h *Handlers) ReadNoteTool() server.ServerTool {
 // handleReadNote implements the read_note tool handler.
 func (h *Handlers) handleReadNote(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Extract parameters
-	path, err := request.RequireString("path")
+	path, errResult := h.notePath(ctx, request, "reading note")
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	offset := request.GetInt("offset", 0)
+	if offset < 0 {
+		return invalidParamResult("offset", fmt.Errorf("must not be negative, got %d", offset)), nil
+	}
+
+	result, err := h.readNote(ctx, request, path)
+	if err != nil || result.IsError {
+		return result, err
+	}
+
+	read, blocks := result.Content[0].(mcp.TextContent).Text, len(result.Content)
+	result = pageContent(result, offset, h.responseLimit(request))
+	if result.IsError {
+		return result, nil
+	}
+	if request.GetBool("include_images", false) && offset == 0 {
+		// Images come once, with the first page; they do not count
+		// against max_bytes. A section read gets the images in its lines.
+		start, end := 0, 0
+		opts := vault.SectionOptions{Heading: request.GetString("heading", ""), Block: request.GetString("block", "")}
+		if opts.Heading != "" || opts.Block != "" {
+			if section, err := h.vault.ReadSection(ctx, path, opts); err == nil {
+				start, end = section.StartLine, section.EndLine
+			}
+		}
+		h.appendNoteImages(ctx, result, path, start, end)
+	}
+	return structuredResult(result, readResponse(path, read, offset, blocks, result)), nil
+}
+
+// readResponse returns the ReadResponse of a read_note result that
+// pageContent cut from read at offset, when it held blocks content blocks
+func readResponse(path, read string, offset, blocks int, result *mcp.CallToolResult) ReadResponse {
+	content := result.Content[0].(mcp.TextContent).Text
+	response := ReadResponse{
+		SchemaVersion: SchemaVersion,
+		Path:          path,
+		Content:       content,
+		Offset:        charOffset(read, offset),
+		TotalBytes:    len(read),
+		Notices:       []string{},
+	}
+
+	// The truncation notice pageContent adds is told by the fields instead
+	notice := -1
+	if end := response.Offset + len(content); end < len(read) {
+		response.Truncated, response.NextOffset, notice = true, end, blocks
+	}
+	for i, block := range result.Content[1:] {
+		if text, ok := block.(mcp.TextContent); ok && i+1 != notice {
+			response.Notices = append(response.Notices, text.Text)
+		}
+	}
+	return response
+}
+
+// readNote reads the note at path, or the part of it the request selects
+func (h *Handlers) readNote(ctx context.Context, request mcp.CallToolRequest, path string) (*mcp.CallToolResult, error) {
+	heading := request.GetString("heading", "")
+	block := request.GetString("block", "")
+	if heading != "" && block != "" {
+		return invalidParamResult("block", fmt.Errorf("set either heading or block, not both")), nil
+	}
+	if heading != "" || block != "" {
+		return h.readSection(ctx, path, vault.SectionOptions{Heading: heading, Block: block})
+	}
+
+	if request.GetBool("expand_embeds", false) {
+		return h.readExpanded(ctx, request, path)
+	}
+
+	if request.GetBool("force_full", false) {
+		// Call vault
+		content, err := h.vault.Read(ctx, path)
+		if err != nil {
+			return vaultErrorResult(err, "reading note", path), nil
+		}
+		return h.noteContentResult(content, path), nil
+	}
+
+	// Call vault
+	note, err := h.vault.ReadProse(ctx, path)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Missing required parameter 'path': %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return vaultErrorResult(err, "reading note", path), nil
 	}
 
+	result := h.noteContentResult(note.Content, path)
+	if blob := note.Blob; blob != nil {
+		summary := fmt.Sprintf("blob: %d bytes, %d of them in %d runs of data left out; %d links", blob.Bytes, blob.DataBytes, blob.DataRuns, blob.Links)
+		if len(blob.Tags) > 0 {
+			summary += ", tags: " + strings.Join(blob.Tags, ", ")
+		}
+		result.Content = append(result.Content, mcp.TextContent{Type: "text", Text: summary + ". Pass force_full=true for the whole note."})
+	}
+
+	return result, nil
+}
+
+// readSection reads the part of the note at path addressed by opts
+func (h *Handlers) readSection(ctx context.Context, path string, opts vault.SectionOptions) (*mcp.CallToolResult, error) {
 	// Call vault
-	content, err := h.vault.Read(ctx, path)
+	section, err := h.vault.ReadSection(ctx, path, opts)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: formatVaultError(err, "reading", path),
-				},
-			},
-			IsError: true,
-		}, nil
-	}
-
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.TextContent{
-				Type: "text",
-				Text: content,
-			},
-		},
-		IsError: false,
-	}, nil
+		return vaultErrorResult(err, "reading note", path), nil
+	}
+
+	result := h.noteContentResult(section.Content, path)
+	result.Content = append(result.Content, mcp.TextContent{
+		Type: "text",
+		Text: fmt.Sprintf("lines: %d-%d", section.StartLine, section.EndLine),
+	})
+	for _, warning := range section.Warnings {
+		result.Content = append(result.Content, mcp.TextContent{Type: "text", Text: "warning: " + warning})
+	}
+
+	return result, nil
+}
+
+// readExpanded reads the note at path with its embeds inlined
+func (h *Handlers) readExpanded(ctx context.Context, request mcp.CallToolRequest, path string) (*mcp.CallToolResult, error) {
+	depth := min(max(request.GetInt("max_depth", vault.DefaultEmbedDepth), 1), vault.MaxEmbedDepth)
+
+	// Call vault
+	note, err := h.vault.ReadExpanded(ctx, path, vault.ExpandOptions{MaxDepth: depth, Allow: h.inRoots})
+	if err != nil {
+		return vaultErrorResult(err, "reading note", path), nil
+	}
+
+	result := h.noteContentResult(note.Content, path)
+	if note.Expanded > 0 || note.Skipped > 0 {
+		summary := fmt.Sprintf("embeds: %d expanded, %d left as written", note.Expanded, note.Skipped)
+		if note.Truncated {
+			summary += fmt.Sprintf(", embedded content truncated at %d characters", vault.DefaultMaxEmbedSize)
+		}
+		result.Content = append(result.Content, mcp.TextContent{Type: "text", Text: summary})
+	}
+
+	return result, nil
+}
+
+// noteContentResult returns content as the first block, followed by the
+// note's Obsidian URI when a vault name is configured
+func (h *Handlers) noteContentResult(content, path string) *mcp.CallToolResult {
+	result := textResult(content)
+
+	// Keep the note itself as the first block so its content stays verbatim
+	if uri := h.noteURI(path); uri != "" {
+		result.Content = append(result.Content, mcp.TextContent{
+			Type: "text",
+			Text: "obsidian_uri: " + uri,
+		})
+	}
+
+	return result
 }
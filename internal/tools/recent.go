@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"context"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// Default parameters for recent_notes
+const (
+	defaultRecentSince = "168h"
+	defaultRecentLimit = 20
+)
+
+// RecentNotesTool returns the ServerTool for listing recently modified notes.
+func (h *Handlers) RecentNotesTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"recent_notes",
+		mcp.WithDescription("List notes modified recently, newest first. Timestamps are RFC3339."),
+		mcp.WithString(
+			"since",
+			mcp.Description("Only include notes modified after this point: a duration back from now (e.g. \"72h\", \"7d\"), a date (\"2024-03-01\") or an RFC3339 timestamp."),
+			mcp.DefaultString(defaultRecentSince),
+		),
+		mcp.WithNumber(
+			"limit",
+			mcp.Description("Maximum number of notes to return. 0 returns all matching notes."),
+			mcp.DefaultNumber(defaultRecentLimit),
+			mcp.Min(0),
+		),
+		mcp.WithString(
+			"path",
+			mcp.Description("Optional subdirectory path to look in. If empty, covers the entire vault."),
+		),
+		withNoteType(),
+		withMaxBytes(),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleRecentNotes,
+	}
+}
+
+// handleRecentNotes implements the recent_notes tool handler.
+func (h *Handlers) handleRecentNotes(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	sinceParam := request.GetString("since", defaultRecentSince)
+	limit := request.GetInt("limit", defaultRecentLimit)
+	path := request.GetString("path", "")
+
+	since, err := parseTime(sinceParam, time.Now())
+	if err != nil {
+		return invalidParamResult("since", err), nil
+	}
+
+	// Call vault
+	notes, err := h.vault.Recent(ctx, vault.RecentOptions{
+		Subpath: path,
+		Since:   since,
+		Limit:   limit,
+		Type:    request.GetString("type", ""),
+	})
+	if err != nil {
+		return vaultErrorResult(err, "listing recent notes", path), nil
+	}
+
+	return listResult(h.noteResults(notes), h.responseLimit(request))
+}
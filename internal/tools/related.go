@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// Limits for find_related
+const (
+	defaultRelatedLimit = 10
+	maxRelatedLimit     = 50
+)
+
+// FindRelatedTool returns the ServerTool for suggesting notes related to a note.
+func (h *Handlers) FindRelatedTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"find_related",
+		mcp.WithDescription("Find existing notes related to a note, or to the content of a note about to be created, so new knowledge can be linked instead of fragmented. Notes are ranked by shared tags, shared link targets, links between the two notes and folder proximity; each result has a score breakdown. Results never include the note itself."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Path to the note (relative to vault root, must end with .md). With content, the path the new note will be created at; optional."),
+		),
+		mcp.WithString(
+			"name",
+			mcp.Description("Note name, frontmatter title or alias to resolve instead of a path."),
+		),
+		mcp.WithString(
+			"content",
+			mcp.Description("Raw markdown of a note that does not exist yet. Its tags and links are compared instead of an existing note's."),
+		),
+		mcp.WithNumber(
+			"limit",
+			mcp.Description(fmt.Sprintf("Maximum number of related notes to return (at most %d).", maxRelatedLimit)),
+			mcp.DefaultNumber(defaultRelatedLimit),
+			mcp.Min(1),
+			mcp.Max(maxRelatedLimit),
+		),
+		mcp.WithBoolean(
+			"use_content",
+			mcp.Description("Also compare words in titles and headings (TF-IDF). Slower on large vaults."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleFindRelated,
+	}
+}
+
+// handleFindRelated implements the find_related tool handler.
+func (h *Handlers) handleFindRelated(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	opts := vault.RelatedOptions{
+		Content:    request.GetString("content", ""),
+		Limit:      min(max(request.GetInt("limit", defaultRelatedLimit), 1), maxRelatedLimit),
+		UseContent: request.GetBool("use_content", false),
+	}
+
+	if opts.Content != "" {
+		if request.GetString("name", "") != "" {
+			return errorResult(ToolError{
+				Code:    CodeInvalidParams,
+				Message: "Provide either 'content' or 'name', not both",
+				Hint:    "Use 'content' for a note that does not exist yet, 'name' for one that does.",
+			}), nil
+		}
+		opts.Path = request.GetString("path", "")
+	} else {
+		path, errResult := h.notePath(ctx, request, "finding related notes")
+		if errResult != nil {
+			return errResult, nil
+		}
+		opts.Path = path
+	}
+
+	// Call vault
+	related, err := h.vault.Related(ctx, opts)
+	if err != nil {
+		return vaultErrorResult(err, "finding related notes", opts.Path), nil
+	}
+
+	related = slices.DeleteFunc(related, func(note vault.RelatedNote) bool {
+		return !h.inRoots(note.Path)
+	})
+	if related == nil {
+		related = []vault.RelatedNote{}
+	}
+
+	return listResult(related, h.maxResponseBytes)
+}
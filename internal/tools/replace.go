@@ -0,0 +1,155 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// maxReplaceFiles bounds the replace_in_notes max_files parameter
+const maxReplaceFiles = 500
+
+// replaceFile is a FileReplacement with the reason a note was skipped or
+// failed as a ToolError
+type replaceFile struct {
+	vault.FileReplacement
+	Error *ToolError `json:"error,omitempty"`
+}
+
+// replaceResult is a ReplaceResult with replaceFile entries
+type replaceResult struct {
+	vault.ReplaceResult
+	Files []replaceFile `json:"files"`
+}
+
+// ReplaceInNotesTool returns the ServerTool for replacing text across
+// notes.
+func (h *Handlers) ReplaceInNotesTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"replace_in_notes",
+		mcp.WithDescription("Replace text across the notes of a folder, e.g. to rename a project everywhere. Each note is rewritten atomically and backed up; a note that cannot be written is reported as skipped or failed and the others are still changed. Returns per note its status, match and replacement counts, and sample lines before and after. Use dry_run first to check the changes."),
+		mcp.WithString(
+			"pattern",
+			mcp.Description("Text to find, or a regular expression with match_mode=regex."),
+			mcp.Required(),
+		),
+		mcp.WithString(
+			"replacement",
+			mcp.Description("Replacement text. With match_mode=regex, $1 or ${name} insert capture groups; write $$ for a literal $."),
+			mcp.Required(),
+		),
+		mcp.WithString(
+			"match_mode",
+			mcp.Description("literal matches pattern as plain text, regex as a Go regular expression."),
+			mcp.Enum(string(vault.ReplaceLiteral), string(vault.ReplaceRegex)),
+			mcp.DefaultString(string(vault.ReplaceLiteral)),
+		),
+		mcp.WithBoolean(
+			"ignore_case",
+			mcp.Description("Match regardless of case."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithBoolean(
+			"preserve_case",
+			mcp.Description("Literal mode only: match regardless of case and give each replacement the case of the text it replaces, so Foo to Bar also turns FOO into BAR and foo into bar."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithString(
+			"path",
+			mcp.Description("Note (ending with .md) or folder to change, relative to vault root. If empty, the whole vault."),
+		),
+		mcp.WithArray(
+			"tags",
+			mcp.Description("Only change notes with at least one of these tags."),
+			mcp.WithStringItems(),
+		),
+		mcp.WithNumber(
+			"max_files",
+			mcp.Description("Maximum number of notes changed; further matching notes are left alone and counted in files_matched."),
+			mcp.DefaultNumber(vault.DefaultReplaceMaxFiles),
+			mcp.Min(1),
+			mcp.Max(maxReplaceFiles),
+		),
+		mcp.WithNumber(
+			"max_replacements_per_file",
+			mcp.Description("Maximum number of replacements in one note, the first ones in the note; 0 for all."),
+			mcp.DefaultNumber(0),
+			mcp.Min(0),
+		),
+		mcp.WithBoolean(
+			"skip_code_blocks",
+			mcp.Description("Leave fenced code blocks unchanged."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithBoolean(
+			"dry_run",
+			mcp.Description("Report the notes and lines that would change without writing anything."),
+			mcp.DefaultBool(false),
+		),
+		withForce(),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleReplaceInNotes,
+	}
+}
+
+// handleReplaceInNotes implements the replace_in_notes tool handler.
+func (h *Handlers) handleReplaceInNotes(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	pattern, err := request.RequireString("pattern")
+	if err != nil {
+		return missingParamResult("pattern", err), nil
+	}
+
+	replacement, err := request.RequireString("replacement")
+	if err != nil {
+		return missingParamResult("replacement", err), nil
+	}
+
+	mode, err := vault.ParseReplaceMode(request.GetString("match_mode", string(vault.ReplaceLiteral)))
+	if err != nil {
+		return invalidParamResult("match_mode", err), nil
+	}
+
+	opts := vault.ReplaceOptions{
+		Pattern:        pattern,
+		Replacement:    replacement,
+		Mode:           mode,
+		IgnoreCase:     request.GetBool("ignore_case", false),
+		PreserveCase:   request.GetBool("preserve_case", false),
+		Path:           request.GetString("path", ""),
+		TagsAny:        request.GetStringSlice("tags", nil),
+		MaxFiles:       min(max(request.GetInt("max_files", vault.DefaultReplaceMaxFiles), 1), maxReplaceFiles),
+		MaxPerFile:     max(request.GetInt("max_replacements_per_file", 0), 0),
+		SkipCodeBlocks: request.GetBool("skip_code_blocks", false),
+		DryRun:         request.GetBool("dry_run", false),
+	}
+
+	// Call vault
+	result, err := h.vault.ReplaceInNotes(ctx, opts)
+	if err != nil {
+		return vaultErrorResult(err, "replacing in notes", opts.Path), nil
+	}
+
+	files := make([]replaceFile, len(result.Files))
+	for i, file := range result.Files {
+		files[i] = replaceFile{FileReplacement: file}
+		if file.Err != nil {
+			toolErr := vaultToolError(file.Err, "replacing in "+file.Path, file.Path)
+			files[i].Error = &toolErr
+		}
+	}
+
+	return fitJSON(len(files), h.maxResponseBytes, func(n int) any {
+		cut := replaceResult{ReplaceResult: result, Files: files[:n]}
+		cut.Truncated = result.Truncated || n < len(files)
+		return cut
+	})
+}
@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"context"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// ResolveNoteTool returns the ServerTool for finding a note by title or alias.
+func (h *Handlers) ResolveNoteTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"resolve_note",
+		mcp.WithDescription("Find a note by name when its exact path is unknown. Matches, case-insensitively and in order of preference, the vault-relative path, the file name without extension, the frontmatter title and frontmatter aliases. Returns the best match, or every equally good candidate when the name is ambiguous."),
+		mcp.WithString(
+			"name",
+			mcp.Description("Note name, title, alias or path, e.g. 'Quarterly Planning'."),
+			mcp.Required(),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleResolveNote,
+	}
+}
+
+// handleResolveNote implements the resolve_note tool handler.
+func (h *Handlers) handleResolveNote(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	name, err := request.RequireString("name")
+	if err != nil {
+		return missingParamResult("name", err), nil
+	}
+
+	// Call vault
+	res, err := h.vault.Resolve(ctx, name)
+	if err != nil {
+		return vaultErrorResult(err, "resolving note", name), nil
+	}
+	if res = h.scopeResolution(res); len(res.Candidates) == 0 {
+		return vaultErrorResult(vault.ErrNoteNotFound, "resolving note", name), nil
+	}
+
+	return jsonResult(res)
+}
+
+// notePath returns the note path for a request taking either 'path' or
+// 'name'. Names are looked up with the resolver; an ambiguous name is an
+// error listing the candidates. On failure the error result is returned.
+func (h *Handlers) notePath(ctx context.Context, request mcp.CallToolRequest, operation string) (string, *mcp.CallToolResult) {
+	path := request.GetString("path", "")
+	name := request.GetString("name", "")
+
+	var toolErr ToolError
+	switch {
+	case path != "" && name != "":
+		toolErr = ToolError{CodeInvalidParams, "Provide either 'path' or 'name', not both", "Use 'path' when the exact path is known, otherwise 'name'."}
+	case path != "":
+		return path, nil
+	case name == "":
+		toolErr = ToolError{CodeInvalidParams, "Missing required parameter: provide 'path' or 'name'", paramHints["path"]}
+	default:
+		return h.resolveName(ctx, name, operation)
+	}
+
+	return "", errorResult(toolErr)
+}
+
+// resolveName returns the path of the note a name, title, alias or path
+// identifies, looked up with the resolver. An ambiguous name is an error
+// listing the candidates. On failure the error result is returned.
+func (h *Handlers) resolveName(ctx context.Context, name, operation string) (string, *mcp.CallToolResult) {
+	res, err := h.vault.Resolve(ctx, name)
+	res = h.scopeResolution(res)
+	switch {
+	case err != nil:
+		return "", errorResult(vaultToolError(err, operation, name))
+	case len(res.Candidates) == 0:
+		return "", errorResult(vaultToolError(vault.ErrNoteNotFound, operation, name))
+	case res.Match == nil:
+		return "", errorResult(vaultToolError(&vault.AmbiguousNoteError{Name: name, Candidates: res.Ambiguous()}, operation, name))
+	}
+	return res.Match.Path, nil
+}
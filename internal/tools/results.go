@@ -0,0 +1,186 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// Tool results follow the MCP convention: failures the model can act on,
+// such as a bad parameter or a missing note, are successful responses
+// marked IsError carrying a ToolError, while failures of the server itself
+// are returned as Go errors, which become JSON-RPC internal errors.
+
+// hintTime describes the accepted forms of time parameters
+const hintTime = "A duration back from now such as \"72h\", \"7d\" or \"-30d\", a date such as \"2024-03-01\", or an RFC3339 timestamp."
+
+// paramHints explains the expected form of common parameters, shown when
+// one is missing or invalid
+var paramHints = map[string]string{
+	"path":            hintNotePath,
+	"name":            "A note name, frontmatter title or alias, e.g. \"Quarterly Planning\".",
+	"content":         "Markdown text of the note; may be an empty string.",
+	"paths":           fmt.Sprintf("An array of 1 to %d note paths relative to the vault root.", maxBatchPaths),
+	"version":         "A version id as returned by list_note_versions.",
+	"revision":        "A content_hash, or a modified time such as \"2024-06-01T09:05:00.123456789Z\", as returned for the note by an earlier call.",
+	"new_path":        "A folder path relative to the vault root, e.g. \"Archive/2024\".", // rename_folder; move_note has its own
+	"since":           hintTime,
+	"modified_after":  hintTime,
+	"modified_before": hintTime,
+	"older_than":      hintTime,
+	"from":            "A date such as \"2024-01-01\" no later than to, an RFC3339 timestamp or a duration back from now such as \"30d\".",
+	"to":              hintTime,
+	"name_glob":       "A file name pattern such as \"2024-*.md\"; * and ? do not match /.",
+	"min_size":        "A size in bytes no larger than max_size.",
+	"format":          "One of markdown, html or plain.",
+	"status":          "One of open, done or all.",
+	"properties":      "An object such as {\"status\": \"done\", \"due\": \"<=2024-06-01\"}.",
+	"source":          hintNotePath,
+	"target":          hintNotePath,
+	"strategy":        "One of append, prepend or sections.",
+	"index_mode":      "One of keep_source or replace_source_with_links.",
+	"query":           "Part of a note's name or path, e.g. \"kuber setup\".",
+	"key":             "An annotation key such as \"summary\".",
+	"value":           "The annotation text; an empty string removes it.",
+	"text":            "The text to capture, with tags as an array of single words such as [\"home\"].",
+	"id":              "A scratch note ID such as \"1f2e3d4c\", as returned by create_scratch or list_scratch.",
+	"duration":        "A positive duration such as \"8h\", \"90m\", \"2d\" or \"1w\"; omit it to pin until unpinned.",
+	"location":        "One of end_of_note, or under_heading or in_section_list with heading set; alias cannot hold brackets or |.",
+	"alias":           "A single line of text such as \"Q3 plan\"; a note whose frontmatter is not a list of properties needs fixing with update_note first.",
+	"fix":             "One of rename, to rename notes after their titles, or retitle, to retitle them after their file names.",
+	"tags":            "An array of tags without #, e.g. [\"book-notes\"].",
+	"target_path":     hintNotePath,
+	"period":          "One of week or month, with from as any day in it and no to.",
+	"group_by":        "One of folder, tag or day.",
+	"include":         "An array of created, modified and tasks-completed, e.g. [\"modified\"].",
+	"order":           "One of modified_desc, modified_asc, created_desc, created_asc or path.",
+	"sort":            "One of path, modified or created, or relevance for search_notes.",
+	"collation":       "One of binary, natural, or locale: and a language tag such as \"locale:sv\".",
+	"mode":            "One of depth or breadth.",
+	"offset":          "A byte offset into the note content, at most its length.",
+	"max_depth":       "A heading level from 1 to 6.",
+	"target_size":     "A number of characters from 100 to 20000.",
+	"overlap":         "A number of characters from 0 to half of target_size.",
+	"block":           "A block ID such as \"quote1\" or \"^quote1\", as listed by analyze_note.",
+	"pattern":         "Plain text, or with match_mode=regex a Go regular expression such as \"Project (\\w+)\"; preserve_case needs match_mode=literal.",
+	"output":          "A file name relative to the export directory ending in .zip or .tar to match format, e.g. \"snapshots/vault.zip\".",
+	"overrides":       "An object of search_notes parameters such as {\"path\": \"Work\", \"tags\": [\"todo\"]}.",
+	"operations":      "An array such as [{\"op\": \"update\", \"path\": \"a.md\", \"content\": \"...\"}, {\"op\": \"move\", \"path\": \"b.md\", \"new_path\": \"Archive/b.md\"}].",
+}
+
+// textResult returns a successful result with one text block per text.
+func textResult(texts ...string) *mcp.CallToolResult {
+	content := make([]mcp.Content, len(texts))
+	for i, text := range texts {
+		content[i] = mcp.NewTextContent(text)
+	}
+	return &mcp.CallToolResult{Content: content}
+}
+
+// jsonResult returns a successful result holding v as indented JSON.
+// Failing to marshal is a server bug, so it is returned as an error.
+func jsonResult(v any) (*mcp.CallToolResult, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshaling result: %w", err)
+	}
+	return textResult(string(data)), nil
+}
+
+// errorResult returns a failed result carrying e.
+func errorResult(e ToolError) *mcp.CallToolResult {
+	return failedResult(e)
+}
+
+// detailedResult returns a failed result carrying e with the details of
+// err, the vault error it stands for, if it has any.
+func detailedResult(e ToolError, err error) *mcp.CallToolResult {
+	if details, ok := vaultErrorDetails(err); ok {
+		return failedResult(detailedErrorResult{e, details})
+	}
+	return errorResult(e)
+}
+
+// schemaErrorResult is a ToolError listing each frontmatter schema
+// violation, so the model can fix them all before retrying.
+type schemaErrorResult struct {
+	ToolError
+	Violations []vault.SchemaViolation `json:"violations"`
+}
+
+// failedResult returns a failed result carrying payload, a ToolError or a
+// type embedding one.
+func failedResult(payload any) *mcp.CallToolResult {
+	// Tool errors always marshal
+	data, _ := json.MarshalIndent(payload, "", "  ")
+	return &mcp.CallToolResult{
+		Content:           []mcp.Content{mcp.NewTextContent(string(data))},
+		StructuredContent: payload,
+		IsError:           true,
+	}
+}
+
+// vaultErrorResult returns a failed result for an error from the vault.
+func vaultErrorResult(err error, operation, path string) *mcp.CallToolResult {
+	toolErr := vaultToolError(err, operation, path)
+
+	var batchErr *vault.BatchError
+	if errors.As(err, &batchErr) && len(batchErr.Problems) > 0 {
+		return failedResult(batchToolError(batchErr))
+	}
+	var schemaErr *vault.SchemaError
+	if errors.As(err, &schemaErr) {
+		return failedResult(schemaErrorResult{toolErr, schemaErr.Violations})
+	}
+	return detailedResult(toolErr, err)
+}
+
+// missingParamResult returns a failed result for a required parameter
+// that is absent or of the wrong type.
+func missingParamResult(name string, err error) *mcp.CallToolResult {
+	return missingParamHintResult(name, paramHints[name], err)
+}
+
+// missingParamHintResult is missingParamResult with a hint of its own, for
+// a parameter whose form differs from the one paramHints gives for its name
+func missingParamHintResult(name, hint string, err error) *mcp.CallToolResult {
+	return failedResult(detailedErrorResult{ToolError{
+		Code:    CodeInvalidParams,
+		Message: fmt.Sprintf("Missing required parameter '%s': %v", name, err),
+		Hint:    hint,
+	}, errorDetails{Param: name}})
+}
+
+// invalidParamResult returns a failed result for a parameter whose value
+// is not acceptable.
+func invalidParamResult(name string, err error) *mcp.CallToolResult {
+	return failedResult(detailedErrorResult{ToolError{
+		Code:    CodeInvalidParams,
+		Message: fmt.Sprintf("Invalid parameter '%s': %v", name, err),
+		Hint:    paramHints[name],
+	}, errorDetails{Param: name}})
+}
+
+// resultError returns the ToolError carried by a failed result, if any.
+func resultError(result *mcp.CallToolResult) (ToolError, bool) {
+	if result == nil || !result.IsError {
+		return ToolError{}, false
+	}
+	switch e := result.StructuredContent.(type) {
+	case ToolError:
+		return e, true
+	case schemaErrorResult:
+		return e.ToolError, true
+	case batchErrorResult:
+		return e.ToolError, true
+	case attachmentErrorResult:
+		return e.ToolError, true
+	case detailedErrorResult:
+		return e.ToolError, true
+	}
+	return ToolError{}, false
+}
@@ -0,0 +1,933 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// failingVault is a Vault whose every method fails with err
+type failingVault struct {
+	err error
+}
+
+func (f failingVault) List(context.Context, vault.ListOptions) ([]vault.NoteInfo, error) {
+	return nil, f.err
+}
+func (f failingVault) FolderIndex(context.Context, string, bool) (*vault.IndexNote, error) {
+	return nil, f.err
+}
+func (f failingVault) Search(context.Context, vault.SearchOptions) ([]vault.NoteInfo, error) {
+	return nil, f.err
+}
+func (f failingVault) SearchFirst(context.Context, vault.SearchOptions) (vault.SearchPage, error) {
+	return vault.SearchPage{}, f.err
+}
+func (f failingVault) Read(context.Context, string) (string, error) { return "", f.err }
+func (f failingVault) Changes(context.Context, vault.ChangesOptions) (vault.ChangeSet, error) {
+	return vault.ChangeSet{}, f.err
+}
+func (f failingVault) SaveSearch(context.Context, vault.SaveSearchOptions) (vault.SavedSearch, error) {
+	return vault.SavedSearch{}, f.err
+}
+func (f failingVault) ListSavedSearches(context.Context) ([]vault.SavedSearch, error) {
+	return nil, f.err
+}
+func (f failingVault) GetSavedSearch(context.Context, string) (vault.SavedSearch, error) {
+	return vault.SavedSearch{}, f.err
+}
+func (f failingVault) DeleteSavedSearch(context.Context, string) error { return f.err }
+func (f failingVault) ReadProse(context.Context, string) (vault.ProseNote, error) {
+	return vault.ProseNote{}, f.err
+}
+func (f failingVault) ReadSection(context.Context, string, vault.SectionOptions) (vault.Section, error) {
+	return vault.Section{}, f.err
+}
+func (f failingVault) ReadExpanded(context.Context, string, vault.ExpandOptions) (vault.ExpandedNote, error) {
+	return vault.ExpandedNote{}, f.err
+}
+func (f failingVault) ReadMany(context.Context, []string, int) ([]vault.NoteContent, error) {
+	return nil, f.err
+}
+func (f failingVault) ReadDigest(context.Context, vault.DigestOptions) (vault.Digest, error) {
+	return vault.Digest{}, f.err
+}
+func (f failingVault) Create(context.Context, string, string) error           { return f.err }
+func (f failingVault) Update(context.Context, string, string) error           { return f.err }
+func (f failingVault) ValidateCreate(context.Context, string) error           { return f.err }
+func (f failingVault) ValidateUpdate(context.Context, string) (string, error) { return "", f.err }
+func (f failingVault) Stats(context.Context, string) (vault.VaultStats, error) {
+	return vault.VaultStats{}, f.err
+}
+func (f failingVault) ListVersions(context.Context, string) ([]vault.NoteVersion, error) {
+	return nil, f.err
+}
+
+func (f failingVault) DiffNote(context.Context, string, string) (vault.NoteDiff, error) {
+	return vault.NoteDiff{}, f.err
+}
+func (f failingVault) Links(context.Context, string) ([]vault.Link, error)  { return nil, f.err }
+func (f failingVault) RestoreVersion(context.Context, string, string) error { return f.err }
+func (f failingVault) SimilarNotes(context.Context, string) ([]vault.SimilarNote, error) {
+	return nil, f.err
+}
+func (f failingVault) MaintenanceStatus(context.Context) (vault.MaintenanceStatus, error) {
+	return vault.MaintenanceStatus{}, f.err
+}
+func (f failingVault) PruneBackups(context.Context, vault.PruneBackupsOptions) (vault.CleanupResult, error) {
+	return vault.CleanupResult{}, f.err
+}
+func (f failingVault) EmptyTrash(context.Context, vault.EmptyTrashOptions) (vault.CleanupResult, error) {
+	return vault.CleanupResult{}, f.err
+}
+func (f failingVault) CompactIndex(context.Context, bool) (vault.IndexCompaction, error) {
+	return vault.IndexCompaction{}, f.err
+}
+func (f failingVault) SaveIndex(context.Context) (vault.IndexStats, error) {
+	return vault.IndexStats{}, f.err
+}
+func (f failingVault) Resolve(context.Context, string) (vault.Resolution, error) {
+	return vault.Resolution{}, f.err
+}
+func (f failingVault) Recent(context.Context, vault.RecentOptions) ([]vault.NoteInfo, error) {
+	return nil, f.err
+}
+func (f failingVault) Analyze(context.Context, string) (vault.NoteAnalysis, error) {
+	return vault.NoteAnalysis{}, f.err
+}
+func (f failingVault) Outline(context.Context, vault.OutlineOptions) (vault.Outline, error) {
+	return vault.Outline{}, f.err
+}
+
+func (f failingVault) Chunks(context.Context, vault.ChunkOptions) (vault.ChunkPage, error) {
+	return vault.ChunkPage{}, f.err
+}
+func (f failingVault) FindTasks(context.Context, vault.TaskOptions) ([]vault.NoteTasks, error) {
+	return nil, f.err
+}
+func (f failingVault) NoteTypes(context.Context, string) ([]vault.NoteTypeCount, error) {
+	return nil, f.err
+}
+func (f failingVault) Related(context.Context, vault.RelatedOptions) ([]vault.RelatedNote, error) {
+	return nil, f.err
+}
+func (f failingVault) Reconnect(context.Context) (vault.Reconnection, error) {
+	return vault.Reconnection{}, f.err
+}
+func (f failingVault) SuggestPlacement(context.Context, vault.PlacementOptions) (vault.Placement, error) {
+	return vault.Placement{}, f.err
+}
+func (f failingVault) ActivityReport(context.Context, vault.ActivityOptions) (vault.ActivityReport, error) {
+	return vault.ActivityReport{}, f.err
+}
+func (f failingVault) Stale(context.Context, vault.StaleOptions) (vault.StaleReport, error) {
+	return vault.StaleReport{}, f.err
+}
+func (f failingVault) Info(context.Context) (vault.VaultInfo, error) { return vault.VaultInfo{}, f.err }
+func (f failingVault) RootFolders([]string) []string                 { return nil }
+func (f failingVault) ListFolders(context.Context, vault.FolderOptions) ([]vault.FolderInfo, error) {
+	return nil, f.err
+}
+func (f failingVault) CreateFolder(context.Context, string) error { return f.err }
+func (f failingVault) RenameFolder(context.Context, vault.RenameFolderOptions) (vault.FolderRename, error) {
+	return vault.FolderRename{}, f.err
+}
+func (f failingVault) MoveNote(context.Context, vault.MoveNoteOptions) (vault.NoteMove, error) {
+	return vault.NoteMove{}, f.err
+}
+func (f failingVault) ExportVault(context.Context, vault.ArchiveOptions, io.Writer) (vault.ArchiveManifest, error) {
+	return vault.ArchiveManifest{}, f.err
+}
+func (f failingVault) MergeNotes(context.Context, vault.MergeOptions) (vault.MergeResult, error) {
+	return vault.MergeResult{}, f.err
+}
+func (f failingVault) SplitNote(context.Context, vault.SplitNoteOptions) (vault.SplitResult, error) {
+	return vault.SplitResult{}, f.err
+}
+func (f failingVault) ReplaceInNotes(context.Context, vault.ReplaceOptions) (vault.ReplaceResult, error) {
+	return vault.ReplaceResult{}, f.err
+}
+
+func (f failingVault) Capture(context.Context, vault.CaptureOptions) (vault.CaptureResult, error) {
+	return vault.CaptureResult{}, f.err
+}
+
+func (f failingVault) AddLink(context.Context, vault.AddLinkOptions) (vault.AddLinkResult, error) {
+	return vault.AddLinkResult{}, f.err
+}
+
+func (f failingVault) AddAlias(context.Context, string, string) (vault.AliasResult, error) {
+	return vault.AliasResult{}, f.err
+}
+func (f failingVault) RemoveAlias(context.Context, string, string) (vault.AliasResult, error) {
+	return vault.AliasResult{}, f.err
+}
+func (f failingVault) SyncTitles(context.Context, vault.SyncTitlesOptions) (vault.SyncTitlesResult, error) {
+	return vault.SyncTitlesResult{}, f.err
+}
+func (f failingVault) PublishNote(context.Context, string) (vault.PublishResult, error) {
+	return vault.PublishResult{}, f.err
+}
+func (f failingVault) UnpublishNote(context.Context, string) (vault.PublishResult, error) {
+	return vault.PublishResult{}, f.err
+}
+func (f failingVault) ListPublishable(context.Context, string) (vault.PublishableReport, error) {
+	return vault.PublishableReport{}, f.err
+}
+func (f failingVault) MarkPublished(context.Context, string, string) (vault.MarkPublishedResult, error) {
+	return vault.MarkPublishedResult{}, f.err
+}
+
+func (f failingVault) ApplyEdits(context.Context, vault.BatchOptions) (vault.BatchResult, error) {
+	return vault.BatchResult{}, f.err
+}
+func (f failingVault) FindNote(context.Context, vault.FuzzyOptions) ([]vault.FuzzyMatch, error) {
+	return nil, f.err
+}
+func (f failingVault) PrepareContent(string, string, bool) (string, error)         { return "", f.err }
+func (f failingVault) SetAnnotation(context.Context, string, string, string) error { return f.err }
+func (f failingVault) GetAnnotations(context.Context, string) (map[string]vault.Annotation, error) {
+	return nil, f.err
+}
+func (f failingVault) Verify(context.Context, vault.VerifyOptions) (vault.VerifyReport, error) {
+	return vault.VerifyReport{}, f.err
+}
+func (f failingVault) CreateScratch(context.Context, string, string) (vault.ScratchInfo, error) {
+	return vault.ScratchInfo{}, f.err
+}
+func (f failingVault) UpdateScratch(context.Context, string, string, string) (vault.ScratchInfo, error) {
+	return vault.ScratchInfo{}, f.err
+}
+func (f failingVault) ReadScratch(context.Context, string, vault.SectionOptions) (vault.ScratchNote, error) {
+	return vault.ScratchNote{}, f.err
+}
+func (f failingVault) ListScratch(context.Context) (vault.ScratchList, error) {
+	return vault.ScratchList{}, f.err
+}
+func (f failingVault) PromoteScratch(context.Context, vault.PromoteScratchOptions) (vault.ScratchPromotion, error) {
+	return vault.ScratchPromotion{}, f.err
+}
+func (f failingVault) GenerateRollup(context.Context, vault.RollupOptions) (vault.RollupResult, error) {
+	return vault.RollupResult{}, f.err
+}
+func (f failingVault) LintNote(context.Context, string, bool) (vault.LintResult, error) {
+	return vault.LintResult{}, f.err
+}
+func (f failingVault) LintVault(context.Context, string) (vault.LintReport, error) {
+	return vault.LintReport{}, f.err
+}
+func (f failingVault) ListAttachments(context.Context, vault.AttachmentOptions) ([]vault.AttachmentInfo, error) {
+	return nil, f.err
+}
+func (f failingVault) StatAttachment(context.Context, string) (vault.AttachmentInfo, error) {
+	return vault.AttachmentInfo{}, f.err
+}
+func (f failingVault) ReadCanvas(context.Context, string) (vault.Canvas, error) {
+	return vault.Canvas{}, f.err
+}
+func (f failingVault) StartWarmup(context.Context) {}
+
+func (f failingVault) LockNote(context.Context, vault.LockOptions) (vault.Lease, error) {
+	return vault.Lease{}, f.err
+}
+
+func (f failingVault) UnlockNote(context.Context, string, bool) (bool, error) {
+	return false, f.err
+}
+
+func (f failingVault) PinNote(context.Context, string, time.Duration) (vault.PinSet, error) {
+	return vault.PinSet{}, f.err
+}
+func (f failingVault) UnpinNote(context.Context, string) (vault.PinSet, error) {
+	return vault.PinSet{}, f.err
+}
+func (f failingVault) ListPinned(context.Context) (vault.PinSet, error) { return vault.PinSet{}, f.err }
+func (f failingVault) AuditLog(context.Context, vault.AuditQuery) ([]vault.AuditEntry, error) {
+	return nil, f.err
+}
+
+func (f failingVault) ReadAttachment(context.Context, string, int64) ([]byte, vault.AttachmentInfo, error) {
+	return nil, vault.AttachmentInfo{}, f.err
+}
+
+var _ vault.Vault = failingVault{}
+
+// vaultErrorTests maps vault errors to the code tools report for them
+var vaultErrorTests = []struct {
+	name string
+	err  error
+	want ErrorCode
+}{
+	{"note not found", vault.ErrNoteNotFound, CodeNotFound},
+	{"wrapped note not found", fmt.Errorf("%w: a.md", vault.ErrNoteNotFound), CodeNotFound},
+	{"path traversal", vault.ErrPathTraversal, CodePathTraversal},
+	{"invalid path", vault.ErrInvalidPath, CodeInvalidPath},
+	{"not markdown", vault.ErrNotMarkdown, CodeNotMarkdown},
+	{"directory not found", vault.ErrDirectoryNotFound, CodeNotFound},
+	{"directory not found with suggestions", &vault.DirectoryNotFoundError{Path: "Projcts", Suggestions: []string{"Projects"}}, CodeNotFound},
+	{"reserved path", vault.ErrReservedPath, CodeReservedPath},
+	{"version not found", vault.ErrVersionNotFound, CodeNotFound},
+	{"section not found", fmt.Errorf("%w: ^quote1 in a.md", vault.ErrSectionNotFound), CodeNotFound},
+	{"ambiguous note", vault.ErrAmbiguousNote, CodeAmbiguous},
+	{"ambiguous note with candidates", &vault.AmbiguousNoteError{Name: "plan"}, CodeAmbiguous},
+	{"attachment not found", vault.ErrAttachmentNotFound, CodeNotFound},
+	{"not attachment", vault.ErrNotAttachment, CodeNotAttachment},
+	{"canvas not found", vault.ErrCanvasNotFound, CodeNotFound},
+	{"not canvas", vault.ErrNotCanvas, CodeNotCanvas},
+	{"invalid canvas", vault.ErrInvalidCanvas, CodeInvalidCanvas},
+	{"rate limited", vault.ErrRateLimited, CodeRateLimited},
+	{"rate limited with scope", &vault.RateLimitError{Scope: vault.LimitScopeFile, Limit: 1}, CodeRateLimited},
+	{"attachment too large", vault.ErrAttachmentTooLarge, CodeTooLarge},
+	{"not UTF-8", vault.ErrNotUTF8, CodeNotUTF8},
+	{"read-only", vault.ErrReadOnly, CodeReadOnly},
+	{"similar note", &vault.SimilarNotesError{Path: "Kubernetes Setup.md", Similar: []vault.SimilarNote{{Path: "Ops/kubernetes-setup.md", MatchedBy: vault.MatchFilename, Name: "kubernetes-setup"}}}, CodeSimilarExists},
+	{"permission denied", fmt.Errorf("failed to write file: %w: Reference/spec.md", vault.ErrPermissionDenied), CodeReadOnly},
+	{"note exists", vault.ErrNoteExists, CodeAlreadyExists},
+	{"folder exists", fmt.Errorf("%w: Archive", vault.ErrFolderExists), CodeAlreadyExists},
+	{"invalid cursor", vault.ErrInvalidCursor, CodeInvalidParams},
+	{"invalid pattern", vault.ErrInvalidPattern, CodeInvalidParams},
+	{"locked", &vault.LockedError{Lease: vault.Lease{Path: "note.md", Holder: "other", Expires: time.Now().Add(time.Minute)}}, CodeLocked},
+	{"audit failed", fmt.Errorf("%w: disk full", vault.ErrAuditFailed), CodeAuditFailed},
+	{"query pattern", &vault.PatternError{Param: "query_any", Index: 1, Pattern: "(", Reason: "missing closing )"}, CodeInvalidParams},
+	{"invalid annotation", vault.ErrInvalidAnnotation, CodeInvalidParams},
+	{"saved search not found", vault.ErrSavedSearchNotFound, CodeNotFound},
+	{"saved search exists", vault.ErrSavedSearchExists, CodeAlreadyExists},
+	{"invalid saved search", vault.ErrInvalidSavedSearch, CodeInvalidParams},
+	{"unknown note type", &vault.UnknownNoteTypeError{Type: "meeting", Types: []string{"daily", "note"}}, CodeInvalidParams},
+	{"invalid link", fmt.Errorf("%w: alias \"a|b\" cannot hold brackets, | or line breaks", vault.ErrInvalidLink), CodeInvalidParams},
+	{"invalid alias", fmt.Errorf("%w: \"\" must be a single line of text", vault.ErrInvalidAlias), CodeInvalidParams},
+	{"alias not found", fmt.Errorf("%w: \"Q3\" in a.md, which has no aliases", vault.ErrAliasNotFound), CodeNotFound},
+	{"scratch not found", fmt.Errorf("%w: 1f2e3d4c", vault.ErrScratchNotFound), CodeNotFound},
+	{"scratch full", fmt.Errorf("%w: 50 scratch notes, at most 50", vault.ErrScratchFull), CodeTooLarge},
+	{"invalid cleanup", fmt.Errorf("%w: set keep, older_than or both", vault.ErrInvalidCleanup), CodeInvalidParams},
+	{"index disabled", vault.ErrIndexDisabled, CodeNotConfigured},
+	{"vault unavailable", fmt.Errorf("failed to walk directory: %w", vault.ErrVaultUnavailable), CodeUnavailable},
+	{"outside data dir", fmt.Errorf("%w: /vault/a.md is outside /vault/.mcp-notes/trash", vault.ErrOutsideDataDir), CodeInternal},
+	{"invalid rollup", fmt.Errorf("%w: rollup_note in template Rollup.md is not text", vault.ErrInvalidRollup), CodeInvalidParams},
+	{"invalid pin", fmt.Errorf("%w: duration -1h0m0s is negative", vault.ErrInvalidPin), CodeInvalidParams},
+	{"invalid capture", fmt.Errorf("%w: empty text", vault.ErrInvalidCapture), CodeInvalidParams},
+	{"invalid revision", fmt.Errorf("%w: \"yesterday\" is neither a content hash nor a modification time", vault.ErrInvalidRevision), CodeInvalidParams},
+	{"revision mismatch", fmt.Errorf("%w: a.md is at revision 1f2e", vault.ErrRevisionMismatch), CodeConflict},
+	{"batch too large", fmt.Errorf("%w: 200 operations, at most 100 allowed", vault.ErrBatchTooLarge), CodeTooLarge},
+	{"invalid edit", fmt.Errorf("%w: move needs new_path", vault.ErrInvalidEdit), CodeInvalidParams},
+	{"batch error", &vault.BatchError{Problems: []vault.EditProblem{{Op: vault.EditUpdate, Path: "a.md", Err: vault.ErrReadOnly}}}, CodeReadOnly},
+	{"schema violation", &vault.SchemaError{Path: "note.md", Violations: []vault.SchemaViolation{{Field: "status", Problem: "is required"}}}, CodeSchema},
+	{"cancelled", context.Canceled, CodeCancelled},
+	{"deadline exceeded", context.DeadlineExceeded, CodeCancelled},
+	{"unknown", errors.New("disk on fire"), CodeInternal},
+}
+
+// writeFiles creates files with their content below dir
+func writeFiles(t testing.TB, dir string, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		fullPath := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", path, err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", path, err)
+		}
+	}
+}
+
+// callTool invokes the named tool with args
+func callTool(t *testing.T, h *Handlers, name string, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+
+	for _, tool := range h.Tools() {
+		if tool.Tool.Name != name {
+			continue
+		}
+		var request mcp.CallToolRequest
+		request.Params.Name = name
+		request.Params.Arguments = args
+		result, err := tool.Handler(context.Background(), request)
+		if err != nil {
+			t.Fatalf("%s returned error: %v", name, err)
+		}
+		return result
+	}
+
+	t.Fatalf("No tool named %s", name)
+	return nil
+}
+
+// checkToolError asserts that result is a failure with code, carried both
+// as structured content and as JSON text
+func checkToolError(t *testing.T, result *mcp.CallToolResult, want ErrorCode) ToolError {
+	t.Helper()
+
+	toolErr, ok := resultError(result)
+	if !ok {
+		t.Fatalf("Expected error result, got %+v", result)
+	}
+	if toolErr.Code != want {
+		t.Errorf("Code = %s, want %s (message %q)", toolErr.Code, want, toolErr.Message)
+	}
+	if toolErr.Message == "" {
+		t.Error("Expected a message")
+	}
+
+	var fromText ToolError
+	if err := json.Unmarshal([]byte(resultText(result)), &fromText); err != nil {
+		t.Fatalf("Error text is not JSON: %v", err)
+	}
+	if fromText != toolErr {
+		t.Errorf("Text payload %+v differs from structured content %+v", fromText, toolErr)
+	}
+
+	return toolErr
+}
+
+func TestVaultErrorCodes(t *testing.T) {
+	// Every tool passes vault errors through the same classification
+	for _, tt := range vaultErrorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(failingVault{err: tt.err}, slog.New(slog.NewTextHandler(io.Discard, nil)), WithVaultName("Notes"))
+			for _, tool := range h.Tools() {
+				args := map[string]any{
+					"path":        "note.md",
+					"content":     "# Note",
+					"paths":       []any{"note.md"},
+					"version":     "20240101T120000Z",
+					"revision":    "2024-01-01T12:00:00Z",
+					"new_path":    "Archive",
+					"source":      "old.md",
+					"target":      "note.md",
+					"key":         "summary",
+					"value":       "A note.",
+					"query":       "note",
+					"tags":        []any{"project"},
+					"pattern":     "old",
+					"replacement": "new",
+					"text":        "Call the plumber",
+					"id":          "1f2e3d4c",
+					"target_path": "note.md",
+					"alias":       "Q3 plan",
+					"fix":         "rename",
+					"operations": []any{
+						map[string]any{"op": "update", "path": "note.md", "content": "# Note"},
+					},
+				}
+				if slices.Contains(tool.Tool.InputSchema.Required, "name") {
+					args = map[string]any{"name": "note"}
+				}
+				if slices.Contains(tool.Tool.InputSchema.Required, "from") {
+					// add_link's notes, rather than activity_report's dates
+					args["from"], args["to"] = "note.md", "note.md"
+				}
+
+				result := callTool(t, h, tool.Tool.Name, args)
+				t.Run(tool.Tool.Name, func(t *testing.T) {
+					checkToolError(t, result, tt.want)
+				})
+			}
+		})
+	}
+}
+
+func TestMissingParameters(t *testing.T) {
+	h := NewHandlers(failingVault{err: vault.ErrNoteNotFound}, slog.New(slog.NewTextHandler(io.Discard, nil)), WithVaultName("Notes"))
+
+	for _, tool := range h.Tools() {
+		_, takesName := tool.Tool.InputSchema.Properties["name"]
+		if len(tool.Tool.InputSchema.Required) == 0 && !takesName {
+			continue
+		}
+
+		t.Run(tool.Tool.Name, func(t *testing.T) {
+			toolErr := checkToolError(t, callTool(t, h, tool.Tool.Name, map[string]any{}), CodeInvalidParams)
+			if toolErr.Hint == "" {
+				t.Errorf("Expected a usage hint for %q", toolErr.Message)
+			}
+		})
+	}
+}
+
+func TestNewPathHints(t *testing.T) {
+	h := NewHandlers(failingVault{err: vault.ErrNoteNotFound}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// new_path names a note for move_note and a folder for rename_folder
+	for tool, want := range map[string]string{"move_note": ".md", "rename_folder": "folder"} {
+		toolErr := checkToolError(t, callTool(t, h, tool, map[string]any{"path": "a.md"}), CodeInvalidParams)
+		if !strings.Contains(toolErr.Hint, want) {
+			t.Errorf("%s hint = %q, want one mentioning %q", tool, toolErr.Hint, want)
+		}
+	}
+}
+
+func TestInvalidParameters(t *testing.T) {
+	h := NewHandlers(failingVault{err: vault.ErrNoteNotFound}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	tests := []struct {
+		tool string
+		args map[string]any
+	}{
+		{"read_note", map[string]any{"path": "a.md", "name": "a"}},
+		{"read_notes", map[string]any{"paths": []any{}}},
+		{"search_notes", map[string]any{"properties": "status=done"}},
+		{"save_search", map[string]any{"match_mode": "glob"}},
+		{"run_saved_search", map[string]any{"name": "todo", "overrides": map[string]any{"limit": 5}}},
+		{"export_note", map[string]any{"format": "pdf"}},
+		{"find_tasks", map[string]any{"status": "pending"}},
+		{"recent_notes", map[string]any{"since": "last week"}},
+		{"stale_notes", map[string]any{"older_than": "a year"}},
+		{"activity_report", map[string]any{"from": "2024-06-01", "to": "2024-01-01"}},
+		{"split_note", map[string]any{"path": "a.md", "index_mode": "replace"}},
+		{"list_notes", map[string]any{"modified_after": "March"}},
+		{"list_notes", map[string]any{"name_glob": "[2024"}},
+		{"list_notes", map[string]any{"min_size": 100, "max_size": 10}},
+		{"list_notes", map[string]any{"sort": "relevance"}},
+		{"search_notes", map[string]any{"collation": "locale:not a tag"}},
+		{"find_related", map[string]any{"content": "# Draft", "name": "plan"}},
+		{"apply_changes", map[string]any{"operations": []any{}}},
+		{"apply_changes", map[string]any{"operations": []any{map[string]any{"op": "update"}}}},
+		{"apply_changes", map[string]any{"operations": "update a.md"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			toolErr := checkToolError(t, callTool(t, h, tt.tool, tt.args), CodeInvalidParams)
+			if toolErr.Hint == "" {
+				t.Errorf("Expected a usage hint for %q", toolErr.Message)
+			}
+		})
+	}
+}
+
+func TestErrorCodesFromVault(t *testing.T) {
+	// Errors raised by a real vault carry the same codes
+	v, err := vault.NewVault(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	h := NewHandlers(v, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if result := callTool(t, h, "create_note", map[string]any{"path": "a.md", "content": "A"}); result.IsError {
+		t.Fatalf("create_note failed: %s", resultText(result))
+	}
+	if result := callTool(t, h, "create_folder", map[string]any{"path": "Plans"}); result.IsError {
+		t.Fatalf("create_folder failed: %s", resultText(result))
+	}
+
+	tests := []struct {
+		name string
+		tool string
+		args map[string]any
+		want ErrorCode
+	}{
+		{"existing note", "create_note", map[string]any{"path": "a.md", "content": "A"}, CodeAlreadyExists},
+		{"traversal", "read_note", map[string]any{"path": "../outside.md"}, CodePathTraversal},
+		{"not markdown", "read_note", map[string]any{"path": "a.txt"}, CodeNotMarkdown},
+		{"missing note", "update_note", map[string]any{"path": "b.md", "content": "B"}, CodeNotFound},
+		{"missing folder", "list_notes", map[string]any{"path": "Nowhere"}, CodeNotFound},
+		{"missing name", "read_note", map[string]any{"name": "Nothing"}, CodeNotFound},
+		{"existing folder", "create_folder", map[string]any{"path": "Plans"}, CodeAlreadyExists},
+		{"rename onto folder", "rename_folder", map[string]any{"path": "Plans", "new_path": "Plans"}, CodeInvalidPath},
+		{"rename missing folder", "rename_folder", map[string]any{"path": "Nowhere", "new_path": "Plans"}, CodeNotFound},
+		{"move onto itself", "move_note", map[string]any{"path": "a.md", "new_path": "Plans/../a.md"}, CodeInvalidPath},
+		{"move missing note", "move_note", map[string]any{"path": "b.md", "new_path": "c.md"}, CodeNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkToolError(t, callTool(t, h, tt.tool, tt.args), tt.want)
+		})
+	}
+}
+
+func TestErrorDetails(t *testing.T) {
+	// Errors carry structured details the model can act on
+	v, err := vault.NewVault(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	h := NewHandlers(v, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	for _, path := range []string{"Projects/plan.md", "Projects/planning.md", "Journal/today.md"} {
+		if result := callTool(t, h, "create_note", map[string]any{"path": path, "content": "Plan the week #plan"}); result.IsError {
+			t.Fatalf("create_note failed: %s", resultText(result))
+		}
+	}
+	position := func(n int) *int { return &n }
+
+	tests := []struct {
+		name string
+		tool string
+		args map[string]any
+		want errorDetails
+	}{
+		{"not markdown", "read_note", map[string]any{"path": "plan.txt"}, errorDetails{Allowed: []string{".md"}}},
+		{"traversal", "read_note", map[string]any{"path": "Projects/../../secret.md"}, errorDetails{Path: "../secret.md"}},
+		{"missing note", "read_note", map[string]any{"path": "Projects/plann.md"}, errorDetails{Path: "Projects/plann.md", DidYouMean: []string{"Projects/planning.md", "Projects/plan.md"}}},
+		{"missing note to update", "update_note", map[string]any{"path": "Journal/tday.md", "content": "x"}, errorDetails{Path: "Journal/tday.md", DidYouMean: []string{"Journal/today.md"}}},
+		{"existing note", "create_note", map[string]any{"path": "Journal/today.md", "content": "x"}, errorDetails{Path: "Journal/today.md", Existing: &existingNote{Size: 19}}},
+		{"search pattern", "search_notes", map[string]any{"query_any": []any{"plan", "week(s"}}, errorDetails{Param: "query_any[1]", Fragment: "week(s", Position: position(0)}},
+		{"replace pattern", "replace_in_notes", map[string]any{"pattern": "plan [a-", "replacement": "x", "match_mode": "regex"}, errorDetails{Param: "pattern", Fragment: "[a-", Position: position(5)}},
+		{"invalid parameter", "search_notes", map[string]any{"query": "plan", "sort": "size"}, errorDetails{Param: "sort"}},
+		{"missing parameter", "create_note", map[string]any{"content": "x"}, errorDetails{Param: "path"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := callTool(t, h, tt.tool, tt.args)
+			if _, ok := resultError(result); !ok {
+				t.Fatalf("Expected a tool error, got %s", resultText(result))
+			}
+			var payload detailedErrorResult
+			if err := json.Unmarshal([]byte(resultText(result)), &payload); err != nil {
+				t.Fatalf("Payload is not JSON: %v", err)
+			}
+			got := payload.Details
+			if got.Existing != nil {
+				if got.Existing.Modified.IsZero() {
+					t.Errorf("existing.modified is not set")
+				}
+				got.Existing.Modified = time.Time{}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("details = %s, want %+v", resultText(result), tt.want)
+			}
+		})
+	}
+}
+
+func TestReadOnlyReasons(t *testing.T) {
+	// Paths protected by the write policy and by file permissions fail
+	// alike, but say which it is
+	tmpDir := t.TempDir()
+	v, err := vault.NewVault(tmpDir, vault.WithReadOnlyPaths("Archive"))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	h := NewHandlers(v, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	for _, dir := range []string{"Archive", "Reference"} {
+		if err := os.MkdirAll(filepath.Join(tmpDir, dir), 0755); err != nil {
+			t.Fatalf("Failed to create folder: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, dir, "spec.md"), []byte("Spec"), 0444); err != nil {
+			t.Fatalf("Failed to create note: %v", err)
+		}
+	}
+
+	tests := []struct {
+		path string
+		want errorDetails
+	}{
+		{"Archive/spec.md", errorDetails{Reason: "write_policy"}},
+		{"Reference/spec.md", errorDetails{Path: "Reference/spec.md", Reason: "file_permissions"}},
+	}
+	for _, tt := range tests {
+		result := callTool(t, h, "update_note", map[string]any{"path": tt.path, "content": "Changed"})
+		checkToolError(t, result, CodeReadOnly)
+		var payload detailedErrorResult
+		if err := json.Unmarshal([]byte(resultText(result)), &payload); err != nil || !reflect.DeepEqual(payload.Details, tt.want) {
+			t.Errorf("update_note(%s) = %s, want details %+v", tt.path, resultText(result), tt.want)
+		}
+		if strings.Contains(resultText(result), tmpDir) {
+			t.Errorf("update_note(%s) error holds the host path: %s", tt.path, resultText(result))
+		}
+	}
+
+	result := callTool(t, h, "list_notes", map[string]any{"path": "Reference"})
+	if !strings.Contains(resultText(result), `"writable": false`) {
+		t.Errorf("list_notes = %s, want the note marked not writable", resultText(result))
+	}
+}
+
+func TestCreateSimilar(t *testing.T) {
+	v, err := vault.NewVault(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	h := NewHandlers(v, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if result := callTool(t, h, "create_note", map[string]any{"path": "Ops/kubernetes-setup.md", "content": "Setup"}); result.IsError {
+		t.Fatalf("create_note failed: %s", resultText(result))
+	}
+
+	// By default the create is refused, listing the similar note
+	args := map[string]any{"path": "Kubernetes Setup.md", "content": "Again"}
+	result := callTool(t, h, "create_note", args)
+	checkToolError(t, result, CodeSimilarExists)
+	var payload detailedErrorResult
+	if err := json.Unmarshal([]byte(resultText(result)), &payload); err != nil || len(payload.Details.Similar) != 1 || payload.Details.Similar[0].Path != "Ops/kubernetes-setup.md" {
+		t.Errorf("create_note = %s, want the similar note in details", resultText(result))
+	}
+
+	args["on_similar"] = "warn"
+	result = callTool(t, h, "create_note", args)
+	if result.IsError || !strings.Contains(resultText(result), "Ops/kubernetes-setup.md") {
+		t.Errorf("create_note with on_similar=warn = %s, want the note created with a warning", resultText(result))
+	}
+
+	// An exact path still reports the note already there
+	args = map[string]any{"path": "Ops/kubernetes-setup.md", "content": "Again", "on_similar": "ignore"}
+	checkToolError(t, callTool(t, h, "create_note", args), CodeAlreadyExists)
+	args["path"] = "Notes/kubernetes setup.md"
+	if result := callTool(t, h, "create_note", args); result.IsError || strings.Contains(resultText(result), "Warning") {
+		t.Errorf("create_note with on_similar=ignore = %s", resultText(result))
+	}
+}
+
+func TestSchemaViolations(t *testing.T) {
+	v, err := vault.NewVault(t.TempDir(), vault.WithFrontmatterSchema(vault.FrontmatterSchema{
+		"status": {Type: vault.FieldString, Required: true, Enum: []string{"draft", "done"}},
+		"due":    {Type: vault.FieldDate},
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	h := NewHandlers(v, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	for _, dryRun := range []bool{false, true} {
+		t.Run(fmt.Sprintf("dry run %v", dryRun), func(t *testing.T) {
+			args := map[string]any{"path": "a.md", "content": "---\nstatus: open\ndue: later\n---\n", "dry_run": dryRun}
+			result := callTool(t, h, "create_note", args)
+			checkToolError(t, result, CodeSchema)
+
+			var got schemaErrorResult
+			if err := json.Unmarshal([]byte(resultText(result)), &got); err != nil {
+				t.Fatalf("Error text is not JSON: %v", err)
+			}
+			want := []vault.SchemaViolation{
+				{Field: "due", Problem: "must be a date such as 2024-06-01, got later"},
+				{Field: "status", Problem: "must be one of draft, done, got open"},
+			}
+			if !reflect.DeepEqual(got.Violations, want) {
+				t.Errorf("Violations = %+v, want %+v", got.Violations, want)
+			}
+		})
+	}
+}
+
+func TestBatchProblems(t *testing.T) {
+	v, err := vault.NewVault(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	h := NewHandlers(v, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if result := callTool(t, h, "create_note", map[string]any{"path": "a.md", "content": "A"}); result.IsError {
+		t.Fatalf("create_note failed: %s", resultText(result))
+	}
+
+	result := callTool(t, h, "apply_changes", map[string]any{"operations": []any{
+		map[string]any{"op": "update", "path": "a.md", "content": "A2", "expected_revision": "stale"},
+		map[string]any{"op": "create", "path": "b.md", "content": "B"},
+		map[string]any{"op": "move", "path": "missing.md", "new_path": "c.md"},
+	}})
+	checkToolError(t, result, CodeConflict)
+
+	var got batchErrorResult
+	if err := json.Unmarshal([]byte(resultText(result)), &got); err != nil {
+		t.Fatalf("Error text is not JSON: %v", err)
+	}
+	if len(got.Problems) != 2 || got.Problems[0].Index != 0 || got.Problems[1].Index != 2 || got.Problems[1].Code != CodeNotFound || got.RolledBack {
+		t.Errorf("Problems = %+v, want operations 0 and 2", got.Problems)
+	}
+	if result := callTool(t, h, "read_note", map[string]any{"path": "b.md"}); !result.IsError {
+		t.Error("Expected an invalid batch to write nothing")
+	}
+}
+
+func TestSearchPartialResults(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("timed out", func(t *testing.T) {
+		h := NewHandlers(failingVault{err: &vault.PartialResultsError{Scanned: 40, Total: 100}}, logger)
+		result := callTool(t, h, "search_notes", map[string]any{"query": "plan", "timeout_ms": 5})
+		if result.IsError {
+			t.Fatalf("Expected partial results, got error %s", resultText(result))
+		}
+
+		var got partialSearchResult
+		if err := json.Unmarshal([]byte(resultText(result)), &got); err != nil {
+			t.Fatalf("Result is not JSON: %v", err)
+		}
+		if !got.Partial || got.ScannedNotes != 40 || got.TotalNotes != 100 || got.Notes == nil {
+			t.Errorf("Result = %+v", got)
+		}
+	})
+
+	t.Run("completed in time", func(t *testing.T) {
+		v, err := vault.NewVault(t.TempDir())
+		if err != nil {
+			t.Fatalf("Failed to create vault: %v", err)
+		}
+		h := NewHandlers(v, logger, WithSearchTimeout(time.Minute))
+		if result := callTool(t, h, "create_note", map[string]any{"path": "a.md", "content": "plan"}); result.IsError {
+			t.Fatalf("create_note failed: %s", resultText(result))
+		}
+
+		// Searches that finish keep returning the plain list
+		unbounded := NewHandlers(v, logger)
+		want := resultText(callTool(t, unbounded, "search_notes", map[string]any{"query": "plan"}))
+		if got := resultText(callTool(t, h, "search_notes", map[string]any{"query": "plan", "timeout_ms": 60000})); got != want {
+			t.Errorf("search_notes = %s, want %s", got, want)
+		}
+	})
+}
+
+func TestSearchFirstResults(t *testing.T) {
+	v, err := vault.NewVault(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	h := NewHandlers(v, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	for _, path := range []string{"a.md", "b.md"} {
+		if result := callTool(t, h, "create_note", map[string]any{"path": path, "content": "plan"}); result.IsError {
+			t.Fatalf("create_note failed: %s", resultText(result))
+		}
+	}
+
+	// Each call returns one more note until the cursor runs out
+	var found []string
+	args := map[string]any{"query": "plan", "first_n": 1}
+	for range 3 {
+		result := callTool(t, h, "search_notes", args)
+		var got firstSearchResult
+		if err := json.Unmarshal([]byte(resultText(result)), &got); err != nil {
+			t.Fatalf("Result is not JSON: %v", err)
+		}
+		for _, note := range got.Notes {
+			found = append(found, note.Path)
+		}
+		if got.NextCursor == "" {
+			break
+		}
+		args["cursor"] = got.NextCursor
+	}
+	if slices.Sort(found); !slices.Equal(found, []string{"a.md", "b.md"}) {
+		t.Errorf("Notes found = %v, want each note once", found)
+	}
+
+	result := callTool(t, h, "search_notes", map[string]any{"query": "plan", "cursor": "bogus"})
+	checkToolError(t, result, CodeInvalidParams)
+}
+
+func TestListIndexNotes(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"Projects/_index.md":       "# Projects\n\nActive work.",
+		"Projects/README.md":       "Second choice",
+		"Projects/Alpha/README.md": "# Alpha\n\nFirst project.\n\nDetails.",
+		"Projects/Alpha/plan.md":   "Plan",
+		"Projects/Beta/notes.md":   "Notes",
+	})
+	v, err := vault.NewVault(dir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	h := NewHandlers(v, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	result := callTool(t, h, "list_notes", map[string]any{"path": "Projects", "include_index": true, "folder_summaries": true})
+	var got listEnvelope[noteResult]
+	if err := json.Unmarshal([]byte(resultText(result)), &got); err != nil {
+		t.Fatalf("Result is not JSON: %v\n%s", err, resultText(result))
+	}
+	if got.IndexNote == nil || got.IndexNote.Path != "Projects/_index.md" || got.IndexNote.Content != "# Projects\n\nActive work." {
+		t.Errorf("index_note = %+v, want the content of Projects/_index.md", got.IndexNote)
+	}
+	if len(got.Folders) != 1 || got.Folders["Projects/Alpha"] != "# Alpha\nFirst project." {
+		t.Errorf("folders = %v, want the excerpt of Projects/Alpha/README.md", got.Folders)
+	}
+	var marked []string
+	for _, note := range got.Results {
+		if note.IsIndex {
+			marked = append(marked, note.Path)
+		}
+	}
+	if !slices.Equal(marked, []string{"Projects/Alpha/README.md", "Projects/_index.md"}) {
+		t.Errorf("Notes marked is_index = %v", marked)
+	}
+
+	// A folder without an index note lists normally
+	result = callTool(t, h, "list_notes", map[string]any{"path": "Projects/Beta", "include_index": true})
+	if text := resultText(result); result.IsError || strings.Contains(text, "index_note") || !strings.Contains(text, `"results"`) {
+		t.Errorf("list_notes of a folder without index note = %s", text)
+	}
+	if text := resultText(callTool(t, h, "list_notes", map[string]any{"path": "Projects"})); !strings.HasPrefix(text, "[") || strings.Contains(text, "is_index") {
+		t.Errorf("list_notes without include_index = %s, want the plain array", text)
+	}
+}
+
+func TestSuggestPlacement(t *testing.T) {
+	v, err := vault.NewVault(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	h := NewHandlers(v, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if result := callTool(t, h, "suggest_placement", map[string]any{"title": "Plan"}); !result.IsError {
+		t.Errorf("suggest_placement without content succeeded: %s", resultText(result))
+	}
+
+	// An empty vault suggests the inbox
+	result := callTool(t, h, "suggest_placement", map[string]any{"content": "# Plan\n#project\n"})
+	var got vault.Placement
+	if err := json.Unmarshal([]byte(resultText(result)), &got); err != nil {
+		t.Fatalf("Result is not JSON: %v\n%s", err, resultText(result))
+	}
+	if len(got.Suggestions) != 1 || !got.Suggestions[0].Fallback || got.Suggestions[0].Folder != "/" || !slices.Equal(got.Tags, []string{"project"}) {
+		t.Errorf("suggest_placement = %+v, want the inbox folder as fallback", got)
+	}
+}
+
+func TestReconnectVault(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "vault")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	v, err := vault.NewVault(dir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	h := NewHandlers(v, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// Calls fail with a clear error while the folder is gone, without its
+	// location
+	if err := os.Rename(dir, dir+".away"); err != nil {
+		t.Fatal(err)
+	}
+	for _, tool := range []string{"list_notes", "reconnect_vault"} {
+		result := callTool(t, h, tool, map[string]any{})
+		if text := resultText(result); !result.IsError || !strings.Contains(text, string(CodeUnavailable)) || strings.Contains(text, dir) {
+			t.Errorf("%s = %s, want %s without the vault's location", tool, text, CodeUnavailable)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir+".away", "new.md"), []byte("# New"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(dir+".away", dir); err != nil {
+		t.Fatal(err)
+	}
+	result := callTool(t, h, "reconnect_vault", map[string]any{})
+	var got vault.Reconnection
+	if err := json.Unmarshal([]byte(resultText(result)), &got); err != nil {
+		t.Fatalf("Result is not JSON: %v\n%s", err, resultText(result))
+	}
+	if got.Notes != 1 || !slices.Equal(got.Appeared, []string{"new.md"}) || len(got.Disappeared) != 0 {
+		t.Errorf("reconnect_vault = %+v, want new.md appeared", got)
+	}
+}
+
+func TestJSONResultMarshalError(t *testing.T) {
+	// A value that cannot be marshaled is a server fault, not a tool error
+	if _, err := jsonResult(make(chan int)); err == nil {
+		t.Error("Expected marshal error")
+	}
+}
@@ -0,0 +1,194 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// Rollup periods of generate_rollup
+const (
+	rollupWeek  = "week"
+	rollupMonth = "month"
+)
+
+// GenerateRollupTool returns the ServerTool for writing a summary note of
+// a week, a month or a range of days.
+func (h *Handlers) GenerateRollupTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"generate_rollup",
+		mcp.WithDescription("Write a summary note of a week, a month or the days from 'from' to 'to': a heading per group of the notes created or last modified in the period, each linked with an excerpt, "+
+			"then the tasks checked in the period. Tasks count on their ✅ completion date when they have one, otherwise on the day their note was last modified. "+
+			"The output is the same for the same vault, so rerunning it only changes what changed. Replaces target_path, or appends to it with append=true, creating it when missing. "+
+			"A template note can override the layout. Returns the counts of notes and tasks and the note's revision, or the rendered markdown with dry_run."),
+		mcp.WithString(
+			"target_path",
+			mcp.Description("Path of the rollup note (relative to vault root, must end with .md)."),
+			mcp.Required(),
+		),
+		mcp.WithString(
+			"period",
+			mcp.Description("Cover the week (Monday to Sunday) or the calendar month holding 'from', or today when 'from' is not set. Defaults to week unless 'from' or 'to' is set without it."),
+			mcp.Enum(rollupWeek, rollupMonth),
+		),
+		mcp.WithString(
+			"from",
+			mcp.Description("First day covered: a date (\"2024-01-01\"), an RFC3339 timestamp or a duration back from now (e.g. \"7d\"). With period, any day in the week or month to cover."),
+		),
+		mcp.WithString(
+			"to",
+			mcp.Description("Last day covered, inclusive, in the same forms as 'from'. Defaults to today; not allowed with period."),
+		),
+		mcp.WithString(
+			"path",
+			mcp.Description("Optional folder to look in, relative to vault root. If empty, covers the entire vault."),
+		),
+		mcp.WithString(
+			"group_by",
+			mcp.Description("Group the notes under a heading per folder, per tag (a note with several tags is listed under each) or per day."),
+			mcp.Enum(string(vault.RollupByFolder), string(vault.RollupByTag), string(vault.RollupByDay)),
+			mcp.DefaultString(string(vault.RollupByFolder)),
+		),
+		mcp.WithArray(
+			"include",
+			mcp.Description("What the rollup covers: notes created, notes last modified and tasks completed in the period. Defaults to all three."),
+			mcp.Items(map[string]any{"type": "string", "enum": []string{string(vault.RollupCreated), string(vault.RollupModified), string(vault.RollupTasks)}}),
+		),
+		mcp.WithString(
+			"template",
+			mcp.Description("Path of a note to render with instead of the default layout. Its body is the layout, with {from}, {to}, {groups} and {tasks}; "+
+				"its frontmatter properties rollup_group ({group}), rollup_note ({link}, {title}, {path}, {date}, {excerpt}), rollup_tasks and rollup_task ({task}, {link}, {title}, {path}, {date}) "+
+				"replace the group heading, the note line, the tasks heading and the task line."),
+		),
+		mcp.WithNumber(
+			"excerpt_length",
+			mcp.Description("Length in characters of the excerpt after each note's link; 0 for none."),
+			mcp.DefaultNumber(vault.DefaultRollupExcerptLength),
+			mcp.Min(0),
+		),
+		mcp.WithBoolean(
+			"append",
+			mcp.Description("Add the rollup to the end of target_path instead of replacing its content."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithBoolean(
+			"dry_run",
+			mcp.Description("Return the rendered markdown without writing anything."),
+			mcp.DefaultBool(false),
+		),
+		withForce(),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleGenerateRollup,
+	}
+}
+
+// handleGenerateRollup implements the generate_rollup tool handler.
+func (h *Handlers) handleGenerateRollup(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	target, err := request.RequireString("target_path")
+	if err != nil {
+		return missingParamResult("target_path", err), nil
+	}
+	from, to, errResult := rollupPeriod(request, time.Now())
+	if errResult != nil {
+		return errResult, nil
+	}
+	group, err := vault.ParseRollupGroup(request.GetString("group_by", ""))
+	if err != nil {
+		return invalidParamResult("group_by", err), nil
+	}
+	var include []vault.RollupInclude
+	for _, s := range request.GetStringSlice("include", nil) {
+		kind, err := vault.ParseRollupInclude(s)
+		if err != nil {
+			return invalidParamResult("include", err), nil
+		}
+		include = append(include, kind)
+	}
+	excerptLength := request.GetInt("excerpt_length", vault.DefaultRollupExcerptLength)
+	if excerptLength == 0 {
+		excerptLength = -1
+	}
+	template := request.GetString("template", "")
+
+	// Call vault
+	result, err := h.vault.GenerateRollup(ctx, vault.RollupOptions{
+		From:          from,
+		To:            to,
+		Target:        target,
+		Subpath:       request.GetString("path", ""),
+		GroupBy:       group,
+		Include:       include,
+		Template:      template,
+		ExcerptLength: excerptLength,
+		Append:        request.GetBool("append", false),
+		DryRun:        request.GetBool("dry_run", false),
+	})
+	if errors.Is(err, vault.ErrNoteNotFound) && template != "" {
+		// Only the template must exist; the rollup note is created
+		return vaultErrorResult(err, "reading rollup template", template), nil
+	}
+	if err != nil {
+		return vaultErrorResult(err, "generating rollup", target), nil
+	}
+
+	return jsonResult(result)
+}
+
+// rollupPeriod returns the first and last day generate_rollup covers, from
+// the period, from and to parameters
+func rollupPeriod(request mcp.CallToolRequest, now time.Time) (time.Time, time.Time, *mcp.CallToolResult) {
+	period := request.GetString("period", "")
+	fromParam, toParam := request.GetString("from", ""), request.GetString("to", "")
+	if period == "" && fromParam == "" && toParam == "" {
+		period = rollupWeek
+	}
+
+	from := now
+	if fromParam != "" {
+		var err error
+		if from, err = parseTime(fromParam, now); err != nil {
+			return time.Time{}, time.Time{}, invalidParamResult("from", err)
+		}
+	}
+
+	switch period {
+	case "":
+		to := now
+		if toParam != "" {
+			var err error
+			if to, err = parseTime(toParam, now); err != nil {
+				return time.Time{}, time.Time{}, invalidParamResult("to", err)
+			}
+		}
+		if from.Format(dateLayout) > to.Format(dateLayout) {
+			return time.Time{}, time.Time{}, invalidParamResult("from", fmt.Errorf("from %s is after to %s", from.Format(dateLayout), to.Format(dateLayout)))
+		}
+		return from, to, nil
+	case rollupWeek, rollupMonth:
+		if toParam != "" {
+			return time.Time{}, time.Time{}, invalidParamResult("to", fmt.Errorf("set either period or to, not both"))
+		}
+	default:
+		return time.Time{}, time.Time{}, invalidParamResult("period", fmt.Errorf("unknown period %q (want week or month)", period))
+	}
+
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	if period == rollupMonth {
+		first := day.AddDate(0, 0, 1-day.Day())
+		return first, first.AddDate(0, 1, -1), nil
+	}
+	monday := day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	return monday, monday.AddDate(0, 0, 6), nil
+}
@@ -0,0 +1,252 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// rootsPathParams are the parameters holding vault paths, which must lie
+// inside the client's roots
+var rootsPathParams = []string{"path", "paths", "source", "target", "new_path", "target_folder", "target_path", "template"}
+
+// rootsWalkTools take a folder in 'path' and walk the whole vault when it
+// is empty, so a scoped call gets the client's root folder instead
+var rootsWalkTools = map[string]bool{
+	"list_notes":        true,
+	"list_folders":      true,
+	"search_notes":      true,
+	"find_note":         true,
+	"find_tasks":        true,
+	"list_note_types":   true,
+	"get_outline":       true,
+	"export_chunks":     true,
+	"export_vault":      true,
+	"read_tagged_notes": true,
+	"recent_notes":      true,
+	"stale_notes":       true,
+	"activity_report":   true,
+	"generate_rollup":   true,
+	"replace_in_notes":  true,
+	"sync_titles":       true,
+	"list_publishable":  true,
+	"vault_stats":       true,
+	"verify_vault":      true,
+	"lint_vault":        true,
+	"list_attachments":  true,
+}
+
+// rootScope holds the vault folders the client's MCP roots allow. It is
+// unscoped until the client declares roots, and while its roots include
+// the whole vault.
+type rootScope struct {
+	mu      sync.Mutex
+	folders []string      // Allowed vault folders, none when the roots miss the vault
+	scoped  bool          // Whether tool calls are limited to folders
+	pending chan struct{} // Closed when an expected update arrives, nil if none
+}
+
+// ExpectRoots makes tool calls wait for the next SetRoots or CancelRoots,
+// so calls made while the client's roots are being fetched are scoped by
+// them.
+func (h *Handlers) ExpectRoots() {
+	h.roots.mu.Lock()
+	defer h.roots.mu.Unlock()
+	if h.roots.pending == nil {
+		h.roots.pending = make(chan struct{})
+	}
+}
+
+// SetRoots limits later tool calls to the vault folders under uris, the
+// file:// roots declared by the client. An empty list, or roots that
+// include the whole vault, remove the limit; roots outside the vault
+// leave nothing allowed.
+func (h *Handlers) SetRoots(uris []string) {
+	var dirs []string
+	for _, uri := range uris {
+		if dir, ok := rootDir(uri); ok {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	var folders []string
+	scoped := len(uris) > 0
+	if scoped {
+		folders = h.vault.RootFolders(dirs)
+		if slices.Contains(folders, "") {
+			folders, scoped = nil, false
+		}
+	}
+
+	h.roots.mu.Lock()
+	defer h.roots.mu.Unlock()
+	h.roots.folders, h.roots.scoped = folders, scoped
+	h.roots.settle()
+}
+
+// CancelRoots ends the wait started by ExpectRoots and keeps the current
+// scope, for when the client's roots could not be fetched.
+func (h *Handlers) CancelRoots() {
+	h.roots.mu.Lock()
+	defer h.roots.mu.Unlock()
+	h.roots.settle()
+}
+
+// RootFolders returns the vault folders tool calls are limited to, and
+// whether they are limited at all.
+func (h *Handlers) RootFolders() ([]string, bool) {
+	h.roots.mu.Lock()
+	defer h.roots.mu.Unlock()
+	return slices.Clone(h.roots.folders), h.roots.scoped
+}
+
+// settle releases the calls waiting for an update; mu must be held
+func (s *rootScope) settle() {
+	if s.pending != nil {
+		close(s.pending)
+		s.pending = nil
+	}
+}
+
+// wait returns the current scope once no update is expected
+func (s *rootScope) wait(ctx context.Context) ([]string, bool, error) {
+	s.mu.Lock()
+	pending := s.pending
+	s.mu.Unlock()
+
+	if pending != nil {
+		select {
+		case <-pending:
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.folders, s.scoped, nil
+}
+
+// rootDir returns the local directory of a file:// root URI
+func rootDir(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" || (u.Host != "" && u.Host != "localhost") {
+		return "", false
+	}
+	p := u.Path
+	// file:///C:/Notes on Windows
+	if len(p) > 2 && p[0] == '/' && p[2] == ':' {
+		p = p[1:]
+	}
+	return filepath.FromSlash(p), p != ""
+}
+
+// inRoots reports whether a vault path is inside the client's roots
+func (h *Handlers) inRoots(path string) bool {
+	folders, scoped := h.RootFolders()
+	return !scoped || inFolders(path, folders)
+}
+
+// inFolders reports whether a vault path is inside any of folders
+func inFolders(path string, folders []string) bool {
+	return slices.ContainsFunc(folders, func(folder string) bool {
+		return vault.InFolder(path, folder)
+	})
+}
+
+// outsideRootsError reports a path outside the allowed folders
+func outsideRootsError(path string, folders []string) ToolError {
+	if len(folders) == 0 {
+		return ToolError{CodeOutsideRoots, "The client's roots do not include this vault", "Add the vault, or a folder in it, to the client's roots."}
+	}
+	if path == "" {
+		return ToolError{CodeOutsideRoots, "The client's roots allow several folders of the vault", fmt.Sprintf("Set 'path' to one of: %s.", strings.Join(folders, ", "))}
+	}
+	return ToolError{CodeOutsideRoots, fmt.Sprintf("%s is outside the client's roots", path), fmt.Sprintf("Use a path inside %s.", strings.Join(folders, ", "))}
+}
+
+// RootsMiddleware returns a tool handler middleware that limits calls to
+// the folders allowed by the client's MCP roots. Paths passed as
+// parameters must lie inside them, tools that walk the whole vault
+// without a 'path' walk the allowed folder instead, and notes suggested
+// for a missing one are limited to them. Calls wait while
+// roots are expected, and pass unchanged when the client declared none.
+func (h *Handlers) RootsMiddleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			folders, scoped, err := h.roots.wait(ctx)
+			if err != nil {
+				return errorResult(ToolError{CodeCancelled, "Cancelled while waiting for the client's roots", ""}), nil
+			}
+			if !scoped {
+				return next(ctx, request)
+			}
+
+			for _, param := range rootsPathParams {
+				paths := request.GetStringSlice(param, nil)
+				if p := request.GetString(param, ""); p != "" {
+					paths = []string{p}
+				}
+				for _, p := range paths {
+					if !inFolders(p, folders) {
+						return errorResult(outsideRootsError(p, folders)), nil
+					}
+				}
+			}
+
+			if rootsWalkTools[request.Params.Name] && request.GetString("path", "") == "" {
+				if len(folders) != 1 {
+					return errorResult(outsideRootsError("", folders)), nil
+				}
+				args := maps.Clone(request.GetArguments())
+				if args == nil {
+					args = make(map[string]any)
+				}
+				args["path"] = folders[0]
+				request.Params.Arguments = args
+			}
+
+			// Suggested paths are only those the client may use
+			result, err := next(ctx, request)
+			if result == nil {
+				return result, err
+			}
+			if e, ok := result.StructuredContent.(detailedErrorResult); ok && len(e.Details.DidYouMean) > 0 {
+				e.Details.DidYouMean = slices.DeleteFunc(slices.Clone(e.Details.DidYouMean), func(p string) bool {
+					return !inFolders(p, folders)
+				})
+				result = failedResult(e)
+			}
+			return result, err
+		}
+	}
+}
+
+// scopeResolution drops the candidates outside the client's roots, and
+// picks the match among those left as if the vault held nothing else
+func (h *Handlers) scopeResolution(res vault.Resolution) vault.Resolution {
+	folders, scoped := h.RootFolders()
+	if !scoped {
+		return res
+	}
+	if res.Match != nil && !inFolders(res.Match.Path, folders) {
+		res.Match = nil
+	}
+	res.Candidates = slices.DeleteFunc(slices.Clone(res.Candidates), func(c vault.NoteMatch) bool {
+		return !inFolders(c.Path, folders)
+	})
+	if best := res.Ambiguous(); len(best) == 1 {
+		res.Match = &best[0]
+	}
+	return res
+}
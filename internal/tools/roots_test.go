@@ -0,0 +1,253 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// rootsHandlers returns handlers for a vault with notes in Work and
+// Personal, and the vault's directory
+func rootsHandlers(t *testing.T) (*Handlers, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	notes := map[string]string{
+		"Work/plan.md":      "# Plan\n\n![[diary]]",
+		"Personal/diary.md": "Dear diary",
+		"Personal/plan.md":  "Holiday plan",
+	}
+	writeFiles(t, tmpDir, notes)
+
+	v, err := vault.NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	return NewHandlers(v, slog.New(slog.NewTextHandler(io.Discard, nil))), tmpDir
+}
+
+// callScoped invokes the named tool through RootsMiddleware
+func callScoped(t *testing.T, h *Handlers, name string, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	for _, tool := range h.Tools() {
+		if tool.Tool.Name != name {
+			continue
+		}
+		var request mcp.CallToolRequest
+		request.Params.Name = name
+		request.Params.Arguments = args
+		result, err := h.RootsMiddleware()(tool.Handler)(context.Background(), request)
+		if err != nil {
+			t.Fatalf("%s returned error: %v", name, err)
+		}
+		return result
+	}
+	t.Fatalf("No tool named %s", name)
+	return nil
+}
+
+// rootURI returns the file:// URI of a directory
+func rootURI(dir string) string {
+	return "file://" + filepath.ToSlash(dir)
+}
+
+func TestRootsMiddleware(t *testing.T) {
+	h, base := rootsHandlers(t)
+
+	// Without roots every call passes
+	if result := callScoped(t, h, "read_note", map[string]any{"path": "Personal/diary.md"}); result.IsError {
+		t.Fatalf("Expected an unscoped read to pass, got %s", resultText(result))
+	}
+	if result := callScoped(t, h, "save_search", map[string]any{"name": "plans", "query": "plan"}); result.IsError {
+		t.Fatalf("save_search = %s", resultText(result))
+	}
+
+	if result := callScoped(t, h, "pin_note", map[string]any{"path": "Personal/diary.md"}); result.IsError {
+		t.Fatalf("pin_note = %s", resultText(result))
+	}
+
+	h.SetRoots([]string{rootURI(filepath.Join(base, "Work")), "https://example.com/repo"})
+	if folders, scoped := h.RootFolders(); !scoped || len(folders) != 1 || folders[0] != "Work" {
+		t.Fatalf("RootFolders() = %v, %v, want [Work]", folders, scoped)
+	}
+
+	t.Run("paths outside the roots", func(t *testing.T) {
+		for _, tc := range []struct {
+			tool string
+			args map[string]any
+		}{
+			{"read_note", map[string]any{"path": "Personal/diary.md"}},
+			{"read_note", map[string]any{"path": "Work/../Personal/diary.md"}},
+			{"read_notes", map[string]any{"paths": []any{"Work/plan.md", "Personal/plan.md"}}},
+			{"merge_notes", map[string]any{"source": "Work/plan.md", "target": "Personal/plan.md"}},
+			{"rename_folder", map[string]any{"path": "Work", "new_path": "Personal/Work"}},
+			{"move_note", map[string]any{"path": "Work/plan.md", "new_path": "Personal/plan 2.md"}},
+			{"split_note", map[string]any{"path": "Work/plan.md", "target_folder": "Personal/plan"}},
+			{"list_notes", map[string]any{"path": "Personal"}},
+			{"apply_changes", map[string]any{"operations": []any{
+				map[string]any{"op": "move", "path": "Work/plan.md", "new_path": "Personal/plan 2.md"},
+			}}},
+			{"run_saved_search", map[string]any{"name": "plans", "overrides": map[string]any{"path": "Personal"}}},
+			{"add_link", map[string]any{"from": "Personal/diary.md", "to": "Work/plan.md"}},
+		} {
+			checkToolError(t, callScoped(t, h, tc.tool, tc.args), CodeOutsideRoots)
+		}
+	})
+
+	t.Run("add_link leaves notes outside the roots alone", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join(base, "Personal/diary.md"))
+		if err != nil || string(data) != "Dear diary" {
+			t.Errorf("Personal/diary.md = %q, %v, want it unchanged", data, err)
+		}
+	})
+
+	t.Run("pins inside the roots", func(t *testing.T) {
+		for _, tc := range []struct {
+			tool string
+			args map[string]any
+		}{
+			{"list_pinned", map[string]any{}},
+			{"pin_note", map[string]any{"path": "Work/plan.md"}},
+			{"unpin_note", map[string]any{"path": "Work/plan.md"}},
+		} {
+			result := callScoped(t, h, tc.tool, tc.args)
+			if text := resultText(result); result.IsError || strings.Contains(text, "Personal") {
+				t.Errorf("%s = %s, want no pins outside Work", tc.tool, text)
+			}
+		}
+	})
+
+	t.Run("walks start from the root folder", func(t *testing.T) {
+		result := callScoped(t, h, "list_notes", map[string]any{})
+		if text := resultText(result); result.IsError || !strings.Contains(text, "Work/plan.md") || strings.Contains(text, "Personal") {
+			t.Errorf("list_notes = %s, want only Work", text)
+		}
+		result = callScoped(t, h, "search_notes", map[string]any{"query": "plan"})
+		if text := resultText(result); result.IsError || strings.Contains(text, "Personal") {
+			t.Errorf("search_notes = %s, want only Work", text)
+		}
+		result = callScoped(t, h, "lint_vault", map[string]any{})
+		var lint lintVaultResult
+		if err := json.Unmarshal([]byte(resultText(result)), &lint); err != nil || result.IsError || lint.NotesChecked != 1 {
+			t.Errorf("lint_vault = %s, want only Work checked", resultText(result))
+		}
+		result = callScoped(t, h, "run_saved_search", map[string]any{"name": "plans"})
+		if text := resultText(result); result.IsError || !strings.Contains(text, "Work/plan.md") || strings.Contains(text, "Personal") {
+			t.Errorf("run_saved_search = %s, want only Work", text)
+		}
+	})
+
+	t.Run("names and embeds resolve inside the roots", func(t *testing.T) {
+		checkToolError(t, callScoped(t, h, "read_note", map[string]any{"name": "diary"}), CodeNotFound)
+		checkToolError(t, callScoped(t, h, "resolve_note", map[string]any{"name": "diary"}), CodeNotFound)
+
+		// plan is ambiguous in the vault but not inside the roots
+		result := callScoped(t, h, "read_note", map[string]any{"name": "plan", "expand_embeds": true})
+		if text := resultText(result); result.IsError || !strings.Contains(text, "# Plan") || strings.Contains(text, "Dear diary") {
+			t.Errorf("read_note = %s, want the embed of Personal/diary.md left as written", text)
+		}
+	})
+
+	t.Run("suggestions inside the roots", func(t *testing.T) {
+		result := callScoped(t, h, "read_note", map[string]any{"path": "Work/plann.md"})
+		checkToolError(t, result, CodeNotFound)
+		var payload detailedErrorResult
+		if err := json.Unmarshal([]byte(resultText(result)), &payload); err != nil || !slices.Equal(payload.Details.DidYouMean, []string{"Work/plan.md"}) {
+			t.Errorf("read_note = %s, want only Work/plan.md suggested", resultText(result))
+		}
+	})
+
+	t.Run("several root folders", func(t *testing.T) {
+		h.SetRoots([]string{rootURI(filepath.Join(base, "Work")), rootURI(filepath.Join(base, "Personal"))})
+		toolErr := checkToolError(t, callScoped(t, h, "list_notes", map[string]any{}), CodeOutsideRoots)
+		if !strings.Contains(toolErr.Hint, "Personal, Work") {
+			t.Errorf("Hint = %q, want the folders to choose from", toolErr.Hint)
+		}
+		if result := callScoped(t, h, "list_notes", map[string]any{"path": "Personal"}); result.IsError {
+			t.Errorf("list_notes in a root folder = %s", resultText(result))
+		}
+	})
+
+	t.Run("roots outside the vault", func(t *testing.T) {
+		h.SetRoots([]string{rootURI(t.TempDir())})
+		checkToolError(t, callScoped(t, h, "list_notes", map[string]any{}), CodeOutsideRoots)
+		checkToolError(t, callScoped(t, h, "read_note", map[string]any{"path": "Work/plan.md"}), CodeOutsideRoots)
+	})
+
+	t.Run("no roots", func(t *testing.T) {
+		h.SetRoots(nil)
+		if _, scoped := h.RootFolders(); scoped {
+			t.Error("Expected no scope without roots")
+		}
+		if result := callScoped(t, h, "read_note", map[string]any{"path": "Personal/diary.md"}); result.IsError {
+			t.Errorf("read_note = %s", resultText(result))
+		}
+	})
+}
+
+func TestRootsMiddlewareWaitsForRoots(t *testing.T) {
+	h, base := rootsHandlers(t)
+	h.ExpectRoots()
+
+	results := make(chan *mcp.CallToolResult, 1)
+	go func() {
+		results <- callScoped(t, h, "read_note", map[string]any{"path": "Personal/diary.md"})
+	}()
+
+	select {
+	case <-results:
+		t.Fatal("Expected the call to wait for the roots")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	h.SetRoots([]string{rootURI(filepath.Join(base, "Work"))})
+	checkToolError(t, <-results, CodeOutsideRoots)
+
+	// A failed fetch keeps the current scope
+	h.ExpectRoots()
+	h.CancelRoots()
+	checkToolError(t, callScoped(t, h, "read_note", map[string]any{"path": "Personal/diary.md"}), CodeOutsideRoots)
+
+	// Cancellation ends the wait
+	h.ExpectRoots()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var request mcp.CallToolRequest
+	request.Params.Name = "read_note"
+	result, err := h.RootsMiddleware()(h.handleReadNote)(ctx, request)
+	if err != nil {
+		t.Fatalf("Middleware returned error: %v", err)
+	}
+	checkToolError(t, result, CodeCancelled)
+}
+
+func TestRootDir(t *testing.T) {
+	tests := []struct {
+		uri  string
+		want string
+		ok   bool
+	}{
+		{"file:///home/me/vault", filepath.FromSlash("/home/me/vault"), true},
+		{"file://localhost/home/me/My%20Vault", filepath.FromSlash("/home/me/My Vault"), true},
+		{"file:///C:/Notes", filepath.FromSlash("C:/Notes"), true},
+		{"file://server/share", "", false},
+		{"https://example.com/vault", "", false},
+		{"file://", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := rootDir(tt.uri)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("rootDir(%q) = %q, %v, want %q, %v", tt.uri, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// SchemaVersion is the version of the output contracts below, reported as
+// schema_version in the structured content of the tools declaring them as
+// their output schema. Adding a field bumps the minor version. Removing or
+// renaming one bumps the major version, and the description of every tool
+// affected must then say how to migrate.
+const SchemaVersion = "1.1"
+
+// ListResponse is the structured result of list_notes
+type ListResponse struct {
+	SchemaVersion string       `json:"schema_version"`
+	Results       []noteResult `json:"results"`
+	Returned      int          `json:"returned"`  // Notes in Results
+	Total         int          `json:"total"`     // Notes listed before truncation
+	Truncated     bool         `json:"truncated"` // Results were cut to fit the response size limit
+	Warnings      []string     `json:"warnings"`  // Parts of the vault the walk could not read
+
+	IndexNote *vault.IndexNote  `json:"index_note,omitempty"` // With include_index, when the folder has one
+	Folders   map[string]string `json:"folders,omitempty"`    // With folder_summaries
+}
+
+// newListResponse returns the ListResponse of the first returned of
+// results, with the extras of the text result
+func newListResponse(results []noteResult, returned int, extras listExtras) ListResponse {
+	return ListResponse{
+		SchemaVersion: SchemaVersion,
+		Results:       nonNil(results[:returned]),
+		Returned:      returned,
+		Total:         len(results),
+		Truncated:     returned < len(results),
+		Warnings:      nonNil(extras.Warnings),
+		IndexNote:     extras.IndexNote,
+		Folders:       extras.Folders,
+	}
+}
+
+// SearchResponse is the structured result of search_notes, whether the
+// search ran to the end, ran out of time or was given first_n or a cursor
+type SearchResponse struct {
+	SchemaVersion string       `json:"schema_version"`
+	Results       []noteResult `json:"results"`
+	Returned      int          `json:"returned"`  // Notes in Results
+	Total         int          `json:"total"`     // Notes found before truncation
+	Truncated     bool         `json:"truncated"` // Results were cut to fit the response size limit
+	Partial       bool         `json:"partial"`   // The search ran out of time
+	Warnings      []string     `json:"warnings"`  // Parts of the vault the search could not read
+
+	ScannedNotes   int    `json:"scanned_notes,omitempty"`   // Notes read, when partial or paged
+	RemainingNotes int    `json:"remaining_notes,omitempty"` // Notes left for calls with next_cursor
+	NextCursor     string `json:"next_cursor,omitempty"`
+}
+
+// newSearchResponse returns the SearchResponse of the first returned of
+// results
+func newSearchResponse(results []noteResult, returned int, warnings []string) SearchResponse {
+	return SearchResponse{
+		SchemaVersion: SchemaVersion,
+		Results:       nonNil(results[:returned]),
+		Returned:      returned,
+		Total:         len(results),
+		Truncated:     returned < len(results),
+		Warnings:      nonNil(warnings),
+	}
+}
+
+// ReadResponse is the structured result of read_note
+type ReadResponse struct {
+	SchemaVersion string   `json:"schema_version"`
+	Path          string   `json:"path"`
+	Content       string   `json:"content"`     // What was read, from Offset
+	Offset        int      `json:"offset"`      // Byte offset of Content in what was read
+	NextOffset    int      `json:"next_offset"` // Offset to continue from when truncated, else 0
+	TotalBytes    int      `json:"total_bytes"` // Size of what was read
+	Truncated     bool     `json:"truncated"`   // Content was cut to fit the response size limit
+	Notices       []string `json:"notices"`     // The text blocks after the content, such as a section's lines
+}
+
+// WriteResult is the structured result of create_note and update_note
+type WriteResult struct {
+	SchemaVersion string `json:"schema_version"`
+	Path          string `json:"path"`   // Note written, or that would be with DryRun
+	Action        string `json:"action"` // created or updated
+	DryRun        bool   `json:"dry_run"`
+
+	RequestedPath string              `json:"requested_path,omitempty"` // Path asked for, when sanitized
+	Similar       []vault.SimilarNote `json:"similar,omitempty"`        // Notes with a similar name, see on_similar
+	ObsidianURI   string              `json:"obsidian_uri,omitempty"`
+}
+
+// Write actions
+const (
+	actionCreated = "created"
+	actionUpdated = "updated"
+)
+
+// newWriteResult returns the WriteResult of the note at path
+func (h *Handlers) newWriteResult(path, action string, dryRun bool) WriteResult {
+	return WriteResult{SchemaVersion: SchemaVersion, Path: path, Action: action, DryRun: dryRun, ObsidianURI: h.noteURI(path)}
+}
+
+// structuredResult returns result with structured as its structured
+// content, which must be of the type the tool declares as output schema
+func structuredResult(result *mcp.CallToolResult, structured any) *mcp.CallToolResult {
+	result.StructuredContent = structured
+	return result
+}
+
+// nonNil returns s, or an empty slice when s is nil, so it is never
+// marshaled as null
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
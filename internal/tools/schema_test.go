@@ -0,0 +1,156 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// update rewrites the golden files from the current output instead of
+// comparing against them: go test ./internal/tools -run TestOutputContracts -update
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// checkGolden compares data with the golden file testdata/golden/name byte
+// for byte
+func checkGolden(t *testing.T, name string, data []byte) {
+	t.Helper()
+	file := filepath.Join("testdata", "golden", name)
+	data = append(data, '\n')
+	if *update {
+		if err := os.WriteFile(file, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("Reading golden file: %v", err)
+	}
+	if !bytes.Equal(data, want) {
+		t.Errorf("%s changed; bump SchemaVersion if that is intended and rerun with -update\ngot:\n%s\nwant:\n%s", name, data, want)
+	}
+}
+
+func TestOutputContracts(t *testing.T) {
+	modified := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	notes := []noteResult{
+		{NoteInfo: vault.NoteInfo{Path: "Projects/_index.md", Tags: []string{"project"}, Modified: modified, Created: modified, ContentHash: "9f86d081", IsIndex: true}},
+		{NoteInfo: vault.NoteInfo{Path: "Projects/plan.md", Tags: []string{}, Modified: modified, Created: modified, ContentHash: "60303ae2", Excerpt: "First steps…"}},
+	}
+	search := newSearchResponse(notes, 1, []string{`folder "Private" could not be read and was skipped: permission denied`})
+	search.ScannedNotes, search.RemainingNotes, search.NextCursor = 40, 60, "c2Nhbm5lZDo0MA"
+	sanitized := WriteResult{SchemaVersion: SchemaVersion, Path: "Ideas/Plan B.md", Action: actionCreated, RequestedPath: "Ideas/Plan:B.md",
+		Similar: []vault.SimilarNote{{Path: "Ideas/plan-b.md", MatchedBy: vault.MatchFilename, Name: "plan-b", Distance: 0}}}
+
+	responses := []struct {
+		name  string
+		value any
+	}{
+		{"list_notes.json", newListResponse(notes, 2, listExtras{IndexNote: &vault.IndexNote{Path: "Projects/_index.md", Size: 24, Content: "# Projects\n\nActive work."}})},
+		{"search_notes.json", search},
+		{"read_note.json", ReadResponse{SchemaVersion: SchemaVersion, Path: "plan.md", Content: "# Plan\n", NextOffset: 7, TotalBytes: 52000, Truncated: true, Notices: []string{"lines: 1-40"}}},
+		{"create_note.json", sanitized},
+		{"update_note.json", WriteResult{SchemaVersion: SchemaVersion, Path: "plan.md", Action: actionUpdated, DryRun: true}},
+	}
+	for _, response := range responses {
+		data, err := json.MarshalIndent(response.value, "", "  ")
+		if err != nil {
+			t.Fatalf("Marshaling %s: %v", response.name, err)
+		}
+		checkGolden(t, response.name, data)
+	}
+
+	// Error results keep their shape too
+	errorResults := []struct {
+		name   string
+		result *mcp.CallToolResult
+	}{
+		{"error_not_found.json", vaultErrorResult(&vault.DirectoryNotFoundError{Path: "Projcts", Suggestions: []string{"Projects"}}, "listing notes", "Projcts")},
+		{"error_similar_exists.json", vaultErrorResult(&vault.SimilarNotesError{Path: "Ideas/Plan B.md", Similar: sanitized.Similar}, "creating note", "Ideas/Plan B.md")},
+	}
+	for _, e := range errorResults {
+		checkGolden(t, e.name, []byte(resultText(e.result)))
+	}
+
+	// The schemas declared to clients follow the types
+	h := NewHandlers(failingVault{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	for _, tool := range []mcp.Tool{h.ListNotesTool().Tool, h.SearchNotesTool().Tool, h.ReadNoteTool().Tool, h.CreateNoteTool().Tool, h.UpdateNoteTool().Tool} {
+		data, err := json.MarshalIndent(tool.OutputSchema, "", "  ")
+		if err != nil {
+			t.Fatalf("Marshaling the output schema of %s: %v", tool.Name, err)
+		}
+		checkGolden(t, tool.Name+".schema.json", data)
+	}
+}
+
+func TestStructuredContent(t *testing.T) {
+	v, err := vault.NewVault(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	h := NewHandlers(v, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	calls := []struct {
+		tool string
+		args map[string]any
+		want any
+	}{
+		{"create_note", map[string]any{"path": "plan.md", "content": "# Plan\n\nFirst steps"}, WriteResult{}},
+		{"update_note", map[string]any{"path": "plan.md", "content": "# Plan\n\nNext steps"}, WriteResult{}},
+		{"read_note", map[string]any{"path": "plan.md"}, ReadResponse{}},
+		{"list_notes", map[string]any{}, ListResponse{}},
+		{"search_notes", map[string]any{"query": "steps"}, SearchResponse{}},
+		{"search_notes", map[string]any{"query": "steps", "first_n": 1}, SearchResponse{}},
+	}
+	for _, call := range calls {
+		result := callTool(t, h, call.tool, call.args)
+		if result.IsError {
+			t.Fatalf("%s failed: %s", call.tool, resultText(result))
+		}
+
+		// Decode through JSON like a client would
+		data, err := json.Marshal(result.StructuredContent)
+		if err != nil {
+			t.Fatalf("Marshaling the structured content of %s: %v", call.tool, err)
+		}
+		var version struct {
+			SchemaVersion string `json:"schema_version"`
+		}
+		if err := json.Unmarshal(data, &version); err != nil || version.SchemaVersion != SchemaVersion {
+			t.Errorf("%s structured content = %s, want schema_version %s", call.tool, data, SchemaVersion)
+		}
+		if got, want := reflect.TypeOf(result.StructuredContent), reflect.TypeOf(call.want); got != want {
+			t.Errorf("%s structured content is a %v, want a %v", call.tool, got, want)
+		}
+	}
+
+	// The text content is unchanged: a plain array for lists
+	result := callTool(t, h, "list_notes", map[string]any{})
+	structured := result.StructuredContent.(ListResponse)
+	var listed []noteResult
+	if err := json.Unmarshal([]byte(resultText(result)), &listed); err != nil || len(listed) != 1 || structured.Returned != 1 || structured.Results[0].Path != listed[0].Path {
+		t.Errorf("list_notes text = %s, structured = %+v; want the same note in both", resultText(result), structured)
+	}
+
+	// Truncated reads report where to continue
+	content := bytes.Repeat([]byte("line of text\n"), 100)
+	if result := callTool(t, h, "update_note", map[string]any{"path": "plan.md", "content": string(content)}); result.IsError {
+		t.Fatalf("update_note failed: %s", resultText(result))
+	}
+	result = callTool(t, h, "read_note", map[string]any{"path": "plan.md", "max_bytes": MinResponseBytes})
+	read := result.StructuredContent.(ReadResponse)
+	if !read.Truncated || read.NextOffset != len(read.Content) || read.TotalBytes != len(content) || len(read.Notices) != 0 {
+		t.Errorf("read_note structured content = %+v, want a truncated read without notices", read)
+	}
+}
@@ -0,0 +1,252 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// listScratchResult is the response of list_scratch
+type listScratchResult struct {
+	vault.ScratchList
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// withScratchID adds the id parameter of the tools using a scratch note
+func withScratchID() mcp.ToolOption {
+	return mcp.WithString(
+		"id",
+		mcp.Description("ID of the scratch note, as returned by create_scratch or list_scratch."),
+		mcp.Required(),
+	)
+}
+
+// CreateScratchTool returns the ServerTool for drafting a scratch note.
+func (h *Handlers) CreateScratchTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"create_scratch",
+		mcp.WithDescription("Start a draft as a scratch note, kept by the server outside the vault so nothing in the vault changes until it is promoted with promote_scratch. "+
+			"Scratch notes are gone when the server stops unless it keeps them in a --scratch-dir folder, and are limited in number and total size; list_scratch shows the limits. "+
+			"Returns the new note's id, tags and revision."),
+		mcp.WithString(
+			"content",
+			mcp.Description("Markdown text of the draft."),
+			mcp.Required(),
+		),
+		mcp.WithString(
+			"title",
+			mcp.Description("Short description to tell drafts apart in list_scratch."),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleCreateScratch,
+	}
+}
+
+// handleCreateScratch implements the create_scratch tool handler.
+func (h *Handlers) handleCreateScratch(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	content, err := request.RequireString("content")
+	if err != nil {
+		return missingParamResult("content", err), nil
+	}
+	title := request.GetString("title", "")
+
+	// Call vault
+	info, err := h.vault.CreateScratch(ctx, title, content)
+	if err != nil {
+		return vaultErrorResult(err, "creating scratch note", ""), nil
+	}
+
+	return jsonResult(info)
+}
+
+// UpdateScratchTool returns the ServerTool for revising a scratch note.
+func (h *Handlers) UpdateScratchTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"update_scratch",
+		mcp.WithDescription("Replace the content of a scratch note with a revised draft. The vault is not changed. Returns the note's tags and new revision."),
+		withScratchID(),
+		mcp.WithString(
+			"content",
+			mcp.Description("The complete new markdown text of the draft."),
+			mcp.Required(),
+		),
+		mcp.WithString(
+			"title",
+			mcp.Description("New title of the draft. Defaults to keeping the current one."),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleUpdateScratch,
+	}
+}
+
+// handleUpdateScratch implements the update_scratch tool handler.
+func (h *Handlers) handleUpdateScratch(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	id, err := request.RequireString("id")
+	if err != nil {
+		return missingParamResult("id", err), nil
+	}
+	content, err := request.RequireString("content")
+	if err != nil {
+		return missingParamResult("content", err), nil
+	}
+	title := request.GetString("title", "")
+
+	// Call vault
+	info, err := h.vault.UpdateScratch(ctx, id, title, content)
+	if err != nil {
+		return vaultErrorResult(err, "updating scratch note", id), nil
+	}
+
+	return jsonResult(info)
+}
+
+// ReadScratchTool returns the ServerTool for reading a scratch note.
+func (h *Handlers) ReadScratchTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"read_scratch",
+		mcp.WithDescription("Read a scratch note with its tags, or only the section under a heading or a ^block as read_note does for notes."),
+		withScratchID(),
+		mcp.WithString(
+			"heading",
+			mcp.Description("Return only the section under this heading, up to the next heading of the same or a higher level. Use \"Parent#Child\" for a nested heading."),
+		),
+		mcp.WithString(
+			"block",
+			mcp.Description("Return only the block with this ID, as marked with ^id."),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleReadScratch,
+	}
+}
+
+// handleReadScratch implements the read_scratch tool handler.
+func (h *Handlers) handleReadScratch(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	id, err := request.RequireString("id")
+	if err != nil {
+		return missingParamResult("id", err), nil
+	}
+	heading := request.GetString("heading", "")
+	block := request.GetString("block", "")
+	if heading != "" && block != "" {
+		return invalidParamResult("block", fmt.Errorf("set either heading or block, not both")), nil
+	}
+
+	// Call vault
+	note, err := h.vault.ReadScratch(ctx, id, vault.SectionOptions{Heading: heading, Block: block})
+	if err != nil {
+		return vaultErrorResult(err, "reading scratch note", id), nil
+	}
+
+	return jsonResult(note)
+}
+
+// ListScratchTool returns the ServerTool for listing the scratch notes.
+func (h *Handlers) ListScratchTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"list_scratch",
+		mcp.WithDescription("List the scratch notes, oldest first, with their titles, tags and sizes, and the space they use against the server's limits. "+
+			"persistent tells whether they outlive the server."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleListScratch,
+	}
+}
+
+// handleListScratch implements the list_scratch tool handler.
+func (h *Handlers) handleListScratch(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Call vault
+	list, err := h.vault.ListScratch(ctx)
+	if err != nil {
+		return vaultErrorResult(err, "listing scratch notes", ""), nil
+	}
+
+	notes := list.Notes
+	return fitJSON(len(notes), h.maxResponseBytes, func(n int) any {
+		list.Notes = notes[:n]
+		return listScratchResult{ScratchList: list, Truncated: n < len(notes)}
+	})
+}
+
+// PromoteScratchTool returns the ServerTool for moving a scratch note into
+// the vault.
+func (h *Handlers) PromoteScratchTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"promote_scratch",
+		mcp.WithDescription("Move an approved draft into the vault: writes the scratch note's content as the note at path, with the same checks, frontmatter template and audit as create_note, "+
+			"and removes the scratch note. Fails if a note is already at path unless overwrite is true, which replaces it as update_note does, keeping a backup. "+
+			"Returns the path and the new note's revision."),
+		withScratchID(),
+		mcp.WithString(
+			"path",
+			mcp.Description("Path of the note to write (relative to vault root, must end with .md)."),
+			mcp.Required(),
+		),
+		mcp.WithBoolean(
+			"overwrite",
+			mcp.Description("Replace a note already at path. Defaults to false, which fails instead."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handlePromoteScratch,
+	}
+}
+
+// handlePromoteScratch implements the promote_scratch tool handler.
+func (h *Handlers) handlePromoteScratch(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	id, err := request.RequireString("id")
+	if err != nil {
+		return missingParamResult("id", err), nil
+	}
+	path, err := request.RequireString("path")
+	if err != nil {
+		return missingParamResult("path", err), nil
+	}
+	opts := vault.PromoteScratchOptions{ID: id, Path: path, Overwrite: request.GetBool("overwrite", false)}
+
+	// Call vault
+	result, err := h.vault.PromoteScratch(ctx, opts)
+	if errors.Is(err, vault.ErrNoteExists) {
+		return detailedResult(ToolError{CodeAlreadyExists, fmt.Sprintf("Note already exists: %s", path), "Pass overwrite=true to replace it, or choose another path."}, err), nil
+	}
+	if errors.Is(err, vault.ErrScratchNotFound) {
+		return vaultErrorResult(err, "promoting scratch note", id), nil
+	}
+	if err != nil {
+		return vaultErrorResult(err, "promoting scratch note", path), nil
+	}
+
+	return jsonResult(result)
+}
@@ -2,32 +2,166 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
 )
 
+// partialSearchResult is returned instead of the plain list of notes when a
+// search reaches its time limit.
+type partialSearchResult struct {
+	Partial      bool         `json:"partial"`
+	ScannedNotes int          `json:"scanned_notes"` // Notes read before the search stopped
+	TotalNotes   int          `json:"total_notes"`   // Notes the search would have read
+	Notes        []noteResult `json:"notes"`
+	Truncated    bool         `json:"truncated"` // Notes found were cut to fit the response size limit
+	Returned     int          `json:"returned"`  // Notes in Notes
+	Total        int          `json:"total"`     // Notes found before truncation
+	Hint         string       `json:"hint"`
+	Warnings     []string     `json:"warnings,omitempty"` // Parts of the vault the search could not read
+}
+
+// firstSearchResult is returned by searches given first_n or a cursor
+type firstSearchResult struct {
+	Notes          []noteResult `json:"notes"`
+	ScannedNotes   int          `json:"scanned_notes"`   // Notes checked by this call
+	RemainingNotes int          `json:"remaining_notes"` // Notes left for calls with next_cursor
+	NextCursor     string       `json:"next_cursor,omitempty"`
+	Partial        bool         `json:"partial,omitempty"`   // The search ran out of time
+	Truncated      bool         `json:"truncated,omitempty"` // Notes found were cut to fit the response size limit
+	Returned       int          `json:"returned,omitempty"`  // Notes in Notes, when truncated
+	Total          int          `json:"total,omitempty"`     // Notes found before truncation
+	Hint           string       `json:"hint,omitempty"`
+	Warnings       []string     `json:"warnings,omitempty"` // Parts of the vault the search could not read
+}
+
 // SearchNotesTool returns the ServerTool for searching notes in the vault.
 func (h *Handlers) SearchNotesTool() server.ServerTool {
 	tool := mcp.NewTool(
 		"search_notes",
-		mcp.WithDescription("Search for notes matching a query and/or tag filters. Query uses regex pattern matching (case-insensitive)."),
+		mcp.WithDescription("Search for notes matching content patterns and/or tag filters. Patterns are case-insensitive regular expressions unless match_mode or case_sensitive say otherwise, and combine: query AND query_all AND any of query_any AND NOT query_none, e.g. query_all [\"kubernetes\"] with query_none [\"helm\"]. Tag filters combine: tags_all AND tags_any AND NOT tags_none. Property filters match frontmatter fields and must all hold. Patterns, tags and properties all apply together."),
 		mcp.WithString(
 			"query",
-			mcp.Description("Regex pattern to search for in note content. Case-insensitive."),
-			mcp.Required(),
+			mcp.Description("Pattern to search for in note content, same as a one-element query_all. If empty, only the other filters apply."),
+		),
+		mcp.WithArray(
+			"query_all",
+			mcp.Description("Optional list of patterns. Notes must match all of them."),
+			mcp.WithStringItems(),
+		),
+		mcp.WithArray(
+			"query_any",
+			mcp.Description("Optional list of patterns. Notes must match at least one of them."),
+			mcp.WithStringItems(),
+		),
+		mcp.WithArray(
+			"query_none",
+			mcp.Description("Optional list of patterns. Notes matching any of them are excluded. Alone, returns every note that matches none."),
+			mcp.WithStringItems(),
+		),
+		mcp.WithString(
+			"match_mode",
+			mcp.Description("How every pattern is read: regex as a Go regular expression, literal as plain text, word as plain text matching whole words only."),
+			mcp.Enum(string(vault.QueryRegex), string(vault.QueryLiteral), string(vault.QueryWord)),
+			mcp.DefaultString(string(vault.QueryRegex)),
+		),
+		mcp.WithBoolean(
+			"case_sensitive",
+			mcp.Description("Whether patterns must match case exactly."),
+			mcp.DefaultBool(false),
 		),
 		mcp.WithString(
 			"path",
 			mcp.Description("Optional subdirectory path to search within. If empty, searches entire vault."),
 		),
+		mcp.WithBoolean(
+			"recursive",
+			mcp.Description("Whether to include notes in subdirectories of path."),
+			mcp.DefaultBool(true),
+		),
 		mcp.WithArray(
 			"tags",
-			mcp.Description("Optional list of tags to filter by. Notes must have at least one of these tags."),
+			mcp.Description("Optional list of tags to filter by. Notes must have at least one of these tags. Same as tags_any."),
 			mcp.WithStringItems(),
 		),
+		mcp.WithArray(
+			"tags_any",
+			mcp.Description("Optional list of tags. Notes must have at least one of these tags."),
+			mcp.WithStringItems(),
+		),
+		mcp.WithArray(
+			"tags_all",
+			mcp.Description("Optional list of tags. Notes must have all of these tags."),
+			mcp.WithStringItems(),
+		),
+		mcp.WithArray(
+			"tags_none",
+			mcp.Description("Optional list of tags. Notes with any of these tags are excluded."),
+			mcp.WithStringItems(),
+		),
+		mcp.WithObject(
+			"properties",
+			mcp.Description("Optional frontmatter conditions, e.g. {\"status\": \"in-progress\", \"due\": \"<=2024-06-01\", \"priority\": 2}. "+
+				"A plain value must be equal (for lists, any element). String values may start with an operator: "+
+				"\"~\" contains (substring or list element), \"*\" exists, \"<\", \"<=\", \">\", \">=\" compare numbers or dates. "+
+				"Notes without frontmatter never match."),
+		),
+		mcp.WithBoolean(
+			"include_hidden",
+			mcp.Description("Whether to include files and folders whose name starts with a dot."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithBoolean(
+			"include_canvas",
+			mcp.Description("Whether to also search the text cards of .canvas files. Malformed canvases are returned with an error field."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithBoolean(
+			"include_preview",
+			mcp.Description("Whether to add an excerpt field with the start of each note: its first paragraph after the frontmatter, with any headings above it."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithNumber(
+			"preview_length",
+			mcp.Description(fmt.Sprintf("Maximum excerpt length in characters, cut on a word boundary. Defaults to %d.", vault.DefaultPreviewLength)),
+			mcp.DefaultNumber(vault.DefaultPreviewLength),
+			mcp.Min(1),
+			mcp.Max(vault.MaxPreviewLength),
+		),
+		mcp.WithBoolean(
+			"include_annotations",
+			mcp.Description("Whether to add the annotations stored with set_note_annotation to each note."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithNumber(
+			"timeout_ms",
+			mcp.Description(fmt.Sprintf("Maximum time the search may take in milliseconds. When it runs out, the notes found so far are returned "+
+				"in an object with partial set to true and how many notes were scanned. %s", h.searchTimeoutDefault())),
+			mcp.Min(1),
+		),
+		mcp.WithNumber(
+			"first_n",
+			mcp.Description("Return as soon as this many notes match, checking the most recently modified notes first, in an object with the notes "+
+				"and a next_cursor continuing the search. Without it the whole scope is searched. Notes come newest first unless sort is given."),
+			mcp.Min(1),
+		),
+		mcp.WithString(
+			"cursor",
+			mcp.Description("next_cursor of an earlier call, to continue that search with the same parameters. Notes modified in between are checked again; deleted ones are skipped."),
+		),
+		withNoteType(),
+		withSort(vault.SortPath, vault.SortModified, vault.SortCreated, vault.SortRelevance),
+		withCollation(),
+		withPinnedFirst("Defaults to true when sorting by relevance, false otherwise."),
+		withMaxBytes(),
+		mcp.WithOutputSchema[SearchResponse](),
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithDestructiveHintAnnotation(false),
 	)
@@ -41,57 +175,181 @@ func (h *Handlers) SearchNotesTool() server.ServerTool {
 // handleSearchNotes implements the search_notes tool handler.
 func (h *Handlers) handleSearchNotes(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Extract parameters
-	query, err := request.RequireString("query")
+	opts, errResult := h.searchOptions(request)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	ctx, warnings := h.walkWarnings(ctx)
+	if opts.FirstN > 0 || opts.Cursor != "" {
+		return h.searchFirst(ctx, request, opts, warnings)
+	}
+
+	// Call vault
+	notes, err := h.vault.Search(ctx, opts)
+	var partial *vault.PartialResultsError
+	if errors.As(err, &partial) {
+		results := h.noteResults(notes)
+		if results == nil {
+			results = []noteResult{}
+		}
+		n, data, err := fitData(len(results), h.responseLimit(request), func(n int) any {
+			return partialSearchResult{
+				Partial:      true,
+				ScannedNotes: partial.Scanned,
+				TotalNotes:   partial.Total,
+				Notes:        results[:n],
+				Truncated:    n < len(results),
+				Returned:     n,
+				Total:        len(results),
+				Hint:         "The search ran out of time. Narrow it with path or tag filters, or raise timeout_ms.",
+				Warnings:     warnings(),
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+		response := newSearchResponse(results, n, warnings())
+		response.Partial, response.ScannedNotes = true, partial.Scanned
+		return structuredResult(textResult(string(data)), response), nil
+	}
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Missing required parameter 'query': %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return vaultErrorResult(err, "searching notes", opts.Subpath), nil
 	}
 
-	path := request.GetString("path", "")
-	tags := request.GetStringSlice("tags", nil)
+	results := h.noteResults(notes)
+	n, data, err := fitData(len(results), h.responseLimit(request), listWrap(results, listExtras{Warnings: warnings()}, len(warnings()) > 0))
+	if err != nil {
+		return nil, err
+	}
+	return structuredResult(textResult(string(data)), newSearchResponse(results, n, warnings())), nil
+}
 
+// searchFirst runs a search_notes call given first_n or a cursor; warnings
+// returns those of the walk
+func (h *Handlers) searchFirst(ctx context.Context, request mcp.CallToolRequest, opts vault.SearchOptions, warnings func() []string) (*mcp.CallToolResult, error) {
 	// Call vault
-	notes, err := h.vault.Search(ctx, query, path, tags)
+	page, err := h.vault.SearchFirst(ctx, opts)
+	var partial *vault.PartialResultsError
+	if err != nil && !errors.As(err, &partial) {
+		return vaultErrorResult(err, "searching notes", opts.Subpath), nil
+	}
+
+	results := h.noteResults(page.Notes)
+	if results == nil {
+		results = []noteResult{}
+	}
+	n, data, err := fitData(len(results), h.responseLimit(request), func(n int) any {
+		result := firstSearchResult{
+			Notes:          results[:n],
+			ScannedNotes:   page.Scanned,
+			RemainingNotes: page.Remaining,
+			NextCursor:     page.NextCursor,
+			Partial:        partial != nil,
+			Warnings:       warnings(),
+		}
+		switch {
+		case n < len(results):
+			result.Truncated, result.Returned, result.Total = true, n, len(results)
+			result.Hint = "Not all notes found fit in the response. Lower first_n to get them all; next_cursor continues after the last one found."
+		case partial != nil:
+			result.Hint = "The search ran out of time. Pass next_cursor as cursor to continue it."
+		case page.NextCursor != "":
+			result.Hint = "Pass next_cursor as cursor, with the same parameters, to look for more."
+		}
+		return result
+	})
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error searching notes: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
-	}
-
-	// Marshal notes to JSON
-	notesJSON, err := json.MarshalIndent(notes, "", "  ")
+		return nil, err
+	}
+	response := newSearchResponse(results, n, warnings())
+	response.Partial = partial != nil
+	response.ScannedNotes, response.RemainingNotes, response.NextCursor = page.Scanned, page.Remaining, page.NextCursor
+	return structuredResult(textResult(string(data)), response), nil
+}
+
+// searchOptions extracts the search_notes parameters of request, or
+// returns the result reporting an invalid one
+func (h *Handlers) searchOptions(request mcp.CallToolRequest) (vault.SearchOptions, *mcp.CallToolResult) {
+	opts := vault.SearchOptions{
+		Query:    request.GetString("query", ""),
+		Subpath:  request.GetString("path", ""),
+		TagsAny:  append(request.GetStringSlice("tags", nil), request.GetStringSlice("tags_any", nil)...),
+		TagsAll:  request.GetStringSlice("tags_all", nil),
+		TagsNone: request.GetStringSlice("tags_none", nil),
+
+		QueryAll:      request.GetStringSlice("query_all", nil),
+		QueryAny:      request.GetStringSlice("query_any", nil),
+		QueryNone:     request.GetStringSlice("query_none", nil),
+		CaseSensitive: request.GetBool("case_sensitive", false),
+
+		NonRecursive:  !request.GetBool("recursive", true),
+		IncludeHidden: request.GetBool("include_hidden", false),
+		IncludeCanvas: request.GetBool("include_canvas", false),
+		PreviewLength: previewLength(request),
+		Type:          request.GetString("type", ""),
+
+		IncludeAnnotations: request.GetBool("include_annotations", false),
+	}
+
+	mode, err := vault.ParseQueryMode(request.GetString("match_mode", string(vault.QueryRegex)))
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error marshaling search results: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
-	}
-
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.TextContent{
-				Type: "text",
-				Text: string(notesJSON),
-			},
-		},
-		IsError: false,
-	}, nil
+		return vault.SearchOptions{}, invalidParamResult("match_mode", err)
+	}
+	opts.QueryMode = mode
+
+	properties, err := parseProperties(request.GetArguments()["properties"])
+	if err != nil {
+		return vault.SearchOptions{}, invalidParamResult("properties", err)
+	}
+	opts.Properties = properties
+
+	by, collation, errResult := noteOrder(request)
+	if errResult != nil {
+		return vault.SearchOptions{}, errResult
+	}
+	opts.Sort, opts.Collation = by, collation
+	opts.PinnedFirst = request.GetBool("pinned_first", by == vault.SortRelevance)
+
+	opts.FirstN = max(request.GetInt("first_n", 0), 0)
+	opts.Cursor = request.GetString("cursor", "")
+
+	opts.Timeout = h.searchTimeout
+	if timeout := request.GetInt("timeout_ms", 0); timeout > 0 {
+		opts.Timeout = time.Duration(timeout) * time.Millisecond
+	}
+
+	return opts, nil
+}
+
+// searchTimeoutDefault describes the time limit used when timeout_ms is omitted
+func (h *Handlers) searchTimeoutDefault() string {
+	if h.searchTimeout <= 0 {
+		return "Defaults to no limit."
+	}
+	return fmt.Sprintf("Defaults to %d.", h.searchTimeout.Milliseconds())
+}
+
+// parseProperties converts the properties argument into vault filters.
+// Filters are sorted by property name so results do not depend on map order.
+func parseProperties(arg any) ([]vault.PropertyFilter, error) {
+	if arg == nil {
+		return nil, nil
+	}
+
+	fields, ok := arg.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expected an object of property conditions")
+	}
+
+	filters := make([]vault.PropertyFilter, 0, len(fields))
+	for _, key := range slices.Sorted(maps.Keys(fields)) {
+		filter, err := vault.ParsePropertyFilter(key, fields[key])
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+
+	return filters, nil
 }
@@ -0,0 +1,244 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// hintSearchName describes the name of a saved search
+const hintSearchName = "The name of a saved search, e.g. \"open tasks in Work\"; list_saved_searches shows them."
+
+// deletedSearchResult is the response of delete_saved_search
+type deletedSearchResult struct {
+	Name    string `json:"name"`
+	Deleted bool   `json:"deleted"`
+}
+
+// savedSearchParams returns the search_notes parameters a saved search
+// can hold: all but max_bytes, which belongs to each run
+func (h *Handlers) savedSearchParams() map[string]any {
+	params := maps.Clone(h.SearchNotesTool().Tool.InputSchema.Properties)
+	delete(params, "max_bytes")
+	return params
+}
+
+// searchNameParam returns the name parameter of request, or the result
+// reporting it missing
+func searchNameParam(request mcp.CallToolRequest) (string, *mcp.CallToolResult) {
+	name, err := request.RequireString("name")
+	if err != nil {
+		return "", errorResult(ToolError{CodeInvalidParams, fmt.Sprintf("Missing required parameter 'name': %v", err), hintSearchName})
+	}
+	return name, nil
+}
+
+// SaveSearchTool returns the ServerTool for saving a named search.
+func (h *Handlers) SaveSearchTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"save_search",
+		mcp.WithDescription("Save a search_notes query under a name, to run it again later with run_saved_search. "+
+			"Takes every search_notes parameter but max_bytes; patterns and the folder are checked before the search is saved. "+
+			"Saved searches are kept in the vault's .mcp-notes folder, so they sync with it."),
+		mcp.WithString(
+			"name",
+			mcp.Description(fmt.Sprintf("Name to save the search under, at most %d characters, e.g. \"open tasks in Work\".", vault.MaxSavedSearchName)),
+			mcp.Required(),
+		),
+		mcp.WithString(
+			"description",
+			mcp.Description("What the search finds, shown by list_saved_searches."),
+		),
+		mcp.WithBoolean(
+			"overwrite",
+			mcp.Description("Replace a search already saved under the name. Without it, saving an existing name fails."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+	maps.Copy(tool.InputSchema.Properties, h.savedSearchParams())
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleSaveSearch,
+	}
+}
+
+// handleSaveSearch implements the save_search tool handler.
+func (h *Handlers) handleSaveSearch(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	name, errResult := searchNameParam(request)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	query, errResult := h.searchOptions(request)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	// Keep the search parameters as given, to run them as search_notes would
+	params := make(map[string]any)
+	searchParams := h.savedSearchParams()
+	for key, value := range request.GetArguments() {
+		if _, ok := searchParams[key]; ok && key != "cursor" { // A cursor belongs to one run
+			params[key] = value
+		}
+	}
+
+	// Call vault
+	saved, err := h.vault.SaveSearch(ctx, vault.SaveSearchOptions{
+		Name:        name,
+		Description: request.GetString("description", ""),
+		Params:      params,
+		Query:       query,
+		Overwrite:   request.GetBool("overwrite", false),
+	})
+	if err != nil {
+		return vaultErrorResult(err, "saving search", name), nil
+	}
+
+	return jsonResult(saved)
+}
+
+// ListSavedSearchesTool returns the ServerTool for listing the saved
+// searches.
+func (h *Handlers) ListSavedSearchesTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"list_saved_searches",
+		mcp.WithDescription("List the searches saved with save_search, sorted by name, each with its description, its search_notes parameters and when it was created and last updated."),
+		withMaxBytes(),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleListSavedSearches,
+	}
+}
+
+// handleListSavedSearches implements the list_saved_searches tool handler.
+func (h *Handlers) handleListSavedSearches(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Call vault
+	searches, err := h.vault.ListSavedSearches(ctx)
+	if err != nil {
+		return vaultErrorResult(err, "listing saved searches", ""), nil
+	}
+
+	return listResult(searches, h.responseLimit(request))
+}
+
+// RunSavedSearchTool returns the ServerTool for running a saved search.
+func (h *Handlers) RunSavedSearchTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"run_saved_search",
+		mcp.WithDescription("Run a search saved with save_search, exactly as search_notes would run its parameters, and return the same results. "+
+			"Overrides replace saved parameters for this run only."),
+		mcp.WithString(
+			"name",
+			mcp.Description("Name of the saved search."),
+			mcp.Required(),
+		),
+		mcp.WithObject(
+			"overrides",
+			mcp.Description("search_notes parameters replacing the saved ones for this run, e.g. {\"path\": \"Work/2024\", \"include_preview\": true}."),
+		),
+		withMaxBytes(),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleRunSavedSearch,
+	}
+}
+
+// handleRunSavedSearch implements the run_saved_search tool handler.
+func (h *Handlers) handleRunSavedSearch(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	name, errResult := searchNameParam(request)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	var overrides map[string]any
+	if arg, ok := request.GetArguments()["overrides"]; ok && arg != nil {
+		if overrides, ok = arg.(map[string]any); !ok {
+			return invalidParamResult("overrides", fmt.Errorf("expected an object of search_notes parameters")), nil
+		}
+	}
+	searchParams := h.savedSearchParams()
+	for _, key := range slices.Sorted(maps.Keys(overrides)) {
+		if _, ok := searchParams[key]; !ok {
+			known := slices.Sorted(maps.Keys(searchParams))
+			return invalidParamResult("overrides", fmt.Errorf("unknown search_notes parameter %q, expected one of %s", key, strings.Join(known, ", "))), nil
+		}
+	}
+
+	// Call vault
+	saved, err := h.vault.GetSavedSearch(ctx, name)
+	if err != nil {
+		return vaultErrorResult(err, "running saved search", name), nil
+	}
+
+	// Run it as a search_notes call, scoped to the client's roots like one
+	args := maps.Clone(saved.Params)
+	if args == nil {
+		args = make(map[string]any)
+	}
+	maps.Copy(args, overrides)
+	if maxBytes, ok := request.GetArguments()["max_bytes"]; ok {
+		args["max_bytes"] = maxBytes
+	}
+	search := mcp.CallToolRequest{}
+	search.Params.Name = "search_notes"
+	search.Params.Arguments = args
+	return h.RootsMiddleware()(h.handleSearchNotes)(ctx, search)
+}
+
+// DeleteSavedSearchTool returns the ServerTool for deleting a saved
+// search.
+func (h *Handlers) DeleteSavedSearchTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"delete_saved_search",
+		mcp.WithDescription("Delete a search saved with save_search."),
+		mcp.WithString(
+			"name",
+			mcp.Description("Name of the saved search."),
+			mcp.Required(),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleDeleteSavedSearch,
+	}
+}
+
+// handleDeleteSavedSearch implements the delete_saved_search tool handler.
+func (h *Handlers) handleDeleteSavedSearch(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	name, errResult := searchNameParam(request)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	// Call vault
+	if err := h.vault.DeleteSavedSearch(ctx, name); err != nil {
+		return vaultErrorResult(err, "deleting saved search", name), nil
+	}
+
+	return jsonResult(deletedSearchResult{Name: strings.TrimSpace(name), Deleted: true})
+}
@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// SplitNoteTool returns the ServerTool for splitting a note into one note
+// per section.
+func (h *Handlers) SplitNoteTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"split_note",
+		mcp.WithDescription("Split a long note into one note per section at the given heading level, named after the headings, with an index linking them in their original order. "+
+			"Sections are copied verbatim, sub-headings included. Either every note is written or none is. Returns each heading with the path of the note created for it."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Note to split (relative to vault root, must end with .md)."),
+			mcp.Required(),
+		),
+		mcp.WithNumber(
+			"heading_level",
+			mcp.Description("Headings of this level each start a new note; a section runs to the next heading of the same or a higher level. Text before the first one stays in the source."),
+			mcp.DefaultNumber(2),
+			mcp.Min(1),
+			mcp.Max(6),
+		),
+		mcp.WithString(
+			"target_folder",
+			mcp.Description("Folder for the new notes, relative to vault root; created if missing. Defaults to a folder named after the note, next to it. Names already taken get a number, e.g. \"Alpha 2.md\"."),
+		),
+		mcp.WithString(
+			"index_mode",
+			mcp.Description("keep_source leaves the note unchanged and writes a separate '<note> Index.md' in the target folder. replace_source_with_links replaces each split section of the note with a link to its new note."),
+			mcp.Enum(string(vault.SplitKeepSource), string(vault.SplitReplaceSource)),
+			mcp.DefaultString(string(vault.SplitKeepSource)),
+		),
+		mcp.WithBoolean(
+			"copy_frontmatter",
+			mcp.Description("Copy the note's frontmatter into every new note. By default each new note gets a 'parent' property linking back to the note instead."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithBoolean(
+			"dry_run",
+			mcp.Description("Return the notes that would be created without writing anything."),
+			mcp.DefaultBool(false),
+		),
+		withForce(),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleSplitNote,
+	}
+}
+
+// handleSplitNote implements the split_note tool handler.
+func (h *Handlers) handleSplitNote(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	path, err := request.RequireString("path")
+	if err != nil {
+		return missingParamResult("path", err), nil
+	}
+
+	mode, err := vault.ParseSplitIndexMode(request.GetString("index_mode", string(vault.SplitKeepSource)))
+	if err != nil {
+		return invalidParamResult("index_mode", err), nil
+	}
+
+	// Normalize the folder the model supplied, like a note path
+	folder := request.GetString("target_folder", "")
+	if folder != "" {
+		requested := folder
+		folder, err = vault.SanitizePath(folder)
+		if err != nil {
+			return vaultErrorResult(err, "splitting note", requested), nil
+		}
+	}
+
+	// Call vault
+	result, err := h.vault.SplitNote(ctx, vault.SplitNoteOptions{
+		Path:            path,
+		HeadingLevel:    min(max(request.GetInt("heading_level", 2), 1), 6),
+		TargetFolder:    folder,
+		IndexMode:       mode,
+		CopyFrontmatter: request.GetBool("copy_frontmatter", false),
+		DryRun:          request.GetBool("dry_run", false),
+	})
+	if err != nil {
+		return vaultErrorResult(err, "splitting note", path), nil
+	}
+
+	return jsonResult(result)
+}
@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// Default parameters for stale_notes
+const (
+	defaultStaleOlderThan = "365d"
+	defaultStaleLimit     = 50
+	maxStaleLimit         = 500
+)
+
+// StaleNotesTool returns the ServerTool for finding notes that have gone
+// stale.
+func (h *Handlers) StaleNotesTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"stale_notes",
+		mcp.WithDescription("Find rotting notes for review: notes not modified in a long time, linked from few or no other notes, and not carrying an excluded tag such as 'evergreen'. "+
+			"Results are stalest first and include each note's last_modified, where that date came from (modified_from), days_stale, inbound_links and tags, so the reason for flagging it can be explained. "+
+			"When most notes share one modification time, as after a migration, unreliable_mtimes is set and those notes are dated from their modified, updated or created frontmatter field instead."),
+		mcp.WithString(
+			"older_than",
+			mcp.Description("Only notes last modified before this point: a duration back from now (e.g. \"365d\", \"26w\"), a date (\"2024-03-01\") or an RFC3339 timestamp."),
+			mcp.DefaultString(defaultStaleOlderThan),
+		),
+		mcp.WithNumber(
+			"max_inbound_links",
+			mcp.Description("Only notes linked from at most this many other notes. 0 finds orphans."),
+			mcp.DefaultNumber(0),
+			mcp.Min(0),
+		),
+		mcp.WithArray(
+			"exclude_tags",
+			mcp.Description("Notes with any of these tags are never reported, e.g. [\"evergreen\"]."),
+			mcp.WithStringItems(),
+		),
+		mcp.WithString(
+			"path",
+			mcp.Description("Optional folder to look in, relative to vault root. If empty, covers the entire vault. Inbound links are always counted from the whole vault."),
+		),
+		mcp.WithNumber(
+			"limit",
+			mcp.Description(fmt.Sprintf("Maximum number of notes to return (at most %d). 'total' counts every stale note.", maxStaleLimit)),
+			mcp.DefaultNumber(defaultStaleLimit),
+			mcp.Min(1),
+			mcp.Max(maxStaleLimit),
+		),
+		withMaxBytes(),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleStaleNotes,
+	}
+}
+
+// handleStaleNotes implements the stale_notes tool handler.
+func (h *Handlers) handleStaleNotes(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	before, err := parseTime(request.GetString("older_than", defaultStaleOlderThan), time.Now())
+	if err != nil {
+		return invalidParamResult("older_than", err), nil
+	}
+	opts := vault.StaleOptions{
+		Subpath:         request.GetString("path", ""),
+		Before:          before,
+		MaxInboundLinks: max(request.GetInt("max_inbound_links", 0), 0),
+		ExcludeTags:     request.GetStringSlice("exclude_tags", nil),
+		Limit:           min(max(request.GetInt("limit", defaultStaleLimit), 1), maxStaleLimit),
+	}
+
+	// Call vault
+	report, err := h.vault.Stale(ctx, opts)
+	if err != nil {
+		return vaultErrorResult(err, "finding stale notes", opts.Subpath), nil
+	}
+
+	return fitJSON(len(report.Notes), h.responseLimit(request), func(n int) any {
+		fitted := report
+		fitted.Notes = report.Notes[:n]
+		return fitted
+	})
+}
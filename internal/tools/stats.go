@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// defaultTopTags is the number of most used tags included in vault_stats output
+const defaultTopTags = 10
+
+// VaultStatsTool returns the ServerTool for computing vault statistics.
+func (h *Handlers) VaultStatsTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"vault_stats",
+		mcp.WithDescription("Get an overview of the vault: note count, total size, notes per top-level folder, most used tags, notes created and modified in the last 7 and 30 days, average note size and untagged note count. A note's created date comes from its frontmatter, then the file's birth time, then its modification time."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Optional subdirectory path to compute statistics for. If empty, covers the entire vault."),
+		),
+		mcp.WithNumber(
+			"top_tags",
+			mcp.Description("Number of most used tags to include."),
+			mcp.DefaultNumber(defaultTopTags),
+			mcp.Min(0),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleVaultStats,
+	}
+}
+
+// handleVaultStats implements the vault_stats tool handler.
+func (h *Handlers) handleVaultStats(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	path := request.GetString("path", "")
+	topTags := request.GetInt("top_tags", defaultTopTags)
+
+	// Call vault
+	stats, err := h.vault.Stats(ctx, path)
+	if err != nil {
+		return vaultErrorResult(err, "computing vault stats", path), nil
+	}
+
+	if topTags >= 0 && len(stats.Tags) > topTags {
+		stats.Tags = stats.Tags[:topTags]
+	}
+
+	summary := fmt.Sprintf(
+		"%d notes (%d bytes) across %d folders, %d untagged, %d created and %d modified in the last 7 days",
+		stats.NoteCount, stats.TotalSize, len(stats.Folders), stats.UntaggedCount, stats.CreatedLast7, stats.ModifiedLast7,
+	)
+
+	result, err := jsonResult(stats)
+	if err != nil {
+		return nil, err
+	}
+	result.Content = append([]mcp.Content{mcp.NewTextContent(summary)}, result.Content...)
+
+	return result, nil
+}
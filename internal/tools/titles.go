@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// syncTitlesResult is a SyncTitlesResult cut to the response limit
+type syncTitlesResult struct {
+	vault.SyncTitlesResult
+	Truncated bool `json:"truncated,omitempty"` // Notes were left out of the report
+}
+
+// SyncTitlesTool returns the ServerTool for making note titles and file
+// names agree.
+func (h *Handlers) SyncTitlesTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"sync_titles",
+		mcp.WithDescription("Find notes whose title, from the frontmatter or first heading as the server's --title-from setting says, differs from their file name, and make them agree. "+
+			"fix=rename renames each note after its title, made safe as a file name, and rewrites the links to it; every rename is planned before any is made, and a note whose new name another note has or would get, ignoring case, is reported as a conflict and left alone. "+
+			"fix=retitle rewrites the title property or heading the title came from to the file name. Index notes keep their names. "+
+			"Returns each note that differed with its status (fixed, skipped or conflict) and reason, plus counts. Use dry_run first to list the discrepancies."),
+		mcp.WithString(
+			"fix",
+			mcp.Description("rename changes the file name to match the title, retitle changes the title to match the file name."),
+			mcp.Enum(string(vault.TitleFixRename), string(vault.TitleFixRetitle)),
+			mcp.Required(),
+		),
+		mcp.WithString(
+			"path",
+			mcp.Description("Folder to check, relative to vault root. If empty, the whole vault."),
+		),
+		mcp.WithBoolean(
+			"dry_run",
+			mcp.Description("Report the notes that differ and what would change without renaming or writing anything."),
+			mcp.DefaultBool(false),
+		),
+		withForce(),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleSyncTitles,
+	}
+}
+
+// handleSyncTitles implements the sync_titles tool handler.
+func (h *Handlers) handleSyncTitles(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	fixParam, err := request.RequireString("fix")
+	if err != nil {
+		return missingParamResult("fix", err), nil
+	}
+	fix, err := vault.ParseTitleFix(fixParam)
+	if err != nil {
+		return invalidParamResult("fix", err), nil
+	}
+
+	opts := vault.SyncTitlesOptions{
+		Path:   request.GetString("path", ""),
+		Fix:    fix,
+		DryRun: request.GetBool("dry_run", false),
+	}
+
+	// Call vault
+	result, err := h.vault.SyncTitles(ctx, opts)
+	if err != nil {
+		return vaultErrorResult(err, "syncing titles", opts.Path), nil
+	}
+
+	return fitJSON(len(result.Notes), h.maxResponseBytes, func(n int) any {
+		cut := syncTitlesResult{SyncTitlesResult: result, Truncated: n < len(result.Notes)}
+		cut.Notes = result.Notes[:n]
+		return cut
+	})
+}
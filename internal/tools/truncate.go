@@ -0,0 +1,245 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// MinResponseBytes is the smallest response size limit accepted, leaving
+// room for the truncation notice and at least some of the content.
+const MinResponseBytes = 512
+
+// hintTruncated tells the model how to see what a truncated list left out
+const hintTruncated = "The response was cut to fit the response size limit. Narrow the request with filters or a smaller limit, or raise max_bytes, to see the rest."
+
+// truncatedList replaces a plain JSON array of results that does not fit
+// the response size limit. Results holds the entries that fit, in order.
+type truncatedList[T any] struct {
+	Results   []T    `json:"results"`
+	Truncated bool   `json:"truncated"`
+	Returned  int    `json:"returned"` // Entries in Results
+	Total     int    `json:"total"`    // Entries before truncation
+	Hint      string `json:"hint"`
+}
+
+// withMaxBytes adds the per-call max_bytes parameter of tools whose
+// response is cut to fit.
+func withMaxBytes() mcp.ToolOption {
+	return mcp.WithNumber(
+		"max_bytes",
+		mcp.Description(fmt.Sprintf("Maximum size of the response in bytes, at least %d. Longer responses are cut and marked truncated. Capped by the server's own limit, if any.", MinResponseBytes)),
+		mcp.Min(MinResponseBytes),
+	)
+}
+
+// responseLimit returns the response size limit of a call: its max_bytes
+// parameter, capped by the server's limit. Zero means unlimited.
+func (h *Handlers) responseLimit(request mcp.CallToolRequest) int {
+	limit := h.maxResponseBytes
+	if n := request.GetInt("max_bytes", 0); n > 0 {
+		n = max(n, MinResponseBytes)
+		if limit == 0 || n < limit {
+			limit = n
+		}
+	}
+	return limit
+}
+
+// listResult returns items as a JSON array like jsonResult, or, when that
+// exceeds limit bytes, as a truncatedList of the longest prefix that fits.
+func listResult[T any](items []T, limit int) (*mcp.CallToolResult, error) {
+	return fitJSON(len(items), limit, listWrap(items, listExtras{}, false))
+}
+
+// listWrap returns the fitJSON wrap of items: the plain array while all of
+// them fit and a truncatedList otherwise, or, when envelope is set, always
+// a listEnvelope carrying extras.
+func listWrap[T any](items []T, extras listExtras, envelope bool) func(n int) any {
+	if envelope && items == nil {
+		items = []T{}
+	}
+	return func(n int) any {
+		if envelope {
+			result := listEnvelope[T]{Results: items[:n], listExtras: extras}
+			if n < len(items) {
+				result.Truncated, result.Returned, result.Total, result.Hint = true, n, len(items), hintTruncated
+			}
+			return result
+		}
+		if n == len(items) {
+			return items
+		}
+		return truncatedList[T]{
+			Results:   items[:n],
+			Truncated: true,
+			Returned:  n,
+			Total:     len(items),
+			Hint:      hintTruncated,
+		}
+	}
+}
+
+// listExtras is what a list response carries besides its results: the
+// warnings of a walk that had to skip part of the vault, so the model
+// learns why the results may be incomplete, and the index notes
+// list_notes was asked for.
+type listExtras struct {
+	Warnings  []string          `json:"warnings,omitempty"`
+	IndexNote *vault.IndexNote  `json:"index_note,omitempty"` // Index note of the listed folder
+	Folders   map[string]string `json:"folders,omitempty"`    // Index note excerpts by subfolder
+}
+
+// listEnvelope replaces a plain JSON array of results when the response
+// carries listExtras.
+type listEnvelope[T any] struct {
+	Results []T `json:"results"`
+	listExtras
+	Truncated bool   `json:"truncated,omitempty"`
+	Returned  int    `json:"returned,omitempty"` // Entries in Results, when truncated
+	Total     int    `json:"total,omitempty"`    // Entries before truncation
+	Hint      string `json:"hint,omitempty"`
+}
+
+// fitJSON returns the JSON of wrap(total) when it fits in limit bytes,
+// and otherwise that of wrap(n) for the largest n below total that fits.
+// wrap must keep the first n of total entries, so the data is cut before
+// it is marshaled and the output always stays valid JSON. When not even
+// wrap(0) fits it is returned anyway, for ResponseLimitMiddleware to
+// reject.
+func fitJSON(total, limit int, wrap func(n int) any) (*mcp.CallToolResult, error) {
+	_, data, err := fitData(total, limit, wrap)
+	if err != nil {
+		return nil, err
+	}
+	return textResult(string(data)), nil
+}
+
+// fitData is fitJSON returning the number of entries kept and their JSON,
+// for tools that report the same entries as structured content too.
+func fitData(total, limit int, wrap func(n int) any) (int, []byte, error) {
+	data, err := json.MarshalIndent(wrap(total), "", "  ")
+	if err != nil {
+		return 0, nil, fmt.Errorf("marshaling result: %w", err)
+	}
+	if limit <= 0 || len(data) <= limit {
+		return total, data, nil
+	}
+
+	// Find the first count that no longer fits; the one before is returned
+	var marshalErr error
+	n := sort.Search(total, func(n int) bool {
+		data, err := json.MarshalIndent(wrap(n), "", "  ")
+		if err != nil {
+			marshalErr = err
+			return true
+		}
+		return len(data) > limit
+	})
+	if marshalErr != nil {
+		return 0, nil, fmt.Errorf("marshaling result: %w", marshalErr)
+	}
+	n = max(n-1, 0)
+	if data, err = json.MarshalIndent(wrap(n), "", "  "); err != nil {
+		return 0, nil, fmt.Errorf("marshaling result: %w", err)
+	}
+	return n, data, nil
+}
+
+// charOffset moves offset forward to the start of the next character of
+// text when it falls inside one.
+func charOffset(text string, offset int) int {
+	for offset < len(text) && !utf8.RuneStart(text[offset]) {
+		offset++
+	}
+	return offset
+}
+
+// cutText returns the longest prefix of text of at most limit bytes that
+// ends at a line break, or, when the first line is already too long, at
+// the end of a UTF-8 character.
+func cutText(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	if limit <= 0 {
+		return ""
+	}
+
+	if i := strings.LastIndexByte(text[:limit], '\n'); i >= 0 {
+		return text[:i+1]
+	}
+	for limit > 0 && !utf8.RuneStart(text[limit]) {
+		limit--
+	}
+	return text[:limit]
+}
+
+// pageContent applies offset and limit to the text in the first block of
+// result, which holds a note's content. The content starts at byte offset,
+// moved forward to the next character if it falls inside one; when the
+// result then exceeds limit bytes the content is cut with cutText and a
+// notice with the offset to continue from is added. A limit of 0 or less
+// means unlimited.
+func pageContent(result *mcp.CallToolResult, offset, limit int) *mcp.CallToolResult {
+	content := result.Content[0].(mcp.TextContent).Text
+	if offset > len(content) {
+		return invalidParamResult("offset", fmt.Errorf("%d is past the end of the content (%d bytes)", offset, len(content)))
+	}
+	offset = charOffset(content, offset)
+	rest := content[offset:]
+	result.Content[0] = mcp.NewTextContent(rest)
+
+	if limit <= 0 || resultSize(result) <= limit {
+		return result
+	}
+
+	// Leave room for the other blocks and the longest possible notice
+	notice := func(end int) string {
+		return fmt.Sprintf("truncated: showing bytes %d-%d of %d; call again with offset=%d for the rest", offset, end, len(content), end)
+	}
+	budget := limit - (resultSize(result) - len(rest)) - len(notice(len(content)))
+
+	kept := cutText(rest, budget)
+	if kept == "" {
+		// Always make progress, even if the limit is then exceeded
+		_, size := utf8.DecodeRuneInString(rest)
+		kept = rest[:size]
+	}
+	result.Content[0] = mcp.NewTextContent(kept)
+	result.Content = append(result.Content, mcp.NewTextContent(notice(offset+len(kept))))
+	return result
+}
+
+// ResponseLimitMiddleware returns a tool handler middleware that replaces
+// any result still larger than the server's response size limit with a
+// TOO_LARGE error. Tools cut their own responses to fit where they can;
+// this catches those that cannot, such as changed_notes, whose cursor
+// would skip what was cut.
+func (h *Handlers) ResponseLimitMiddleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := next(ctx, request)
+			if err != nil || h.maxResponseBytes <= 0 {
+				return result, err
+			}
+
+			if size := resultSize(result); size > h.maxResponseBytes {
+				return errorResult(ToolError{
+					Code:    CodeTooLarge,
+					Message: fmt.Sprintf("Response of %s is %d bytes, over the limit of %d", request.Params.Name, size, h.maxResponseBytes),
+					Hint:    "Narrow the request, for example with a path filter or a smaller limit.",
+				}), nil
+			}
+			return result, nil
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+func TestCutText(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  string
+	}{
+		{"fits", "one\ntwo\n", 8, "one\ntwo\n"},
+		{"line boundary", "one\ntwo\nthree\n", 10, "one\ntwo\n"},
+		{"exactly at line end", "one\ntwo\nthree\n", 8, "one\ntwo\n"},
+		{"long first line", "abcdef", 4, "abcd"},
+		{"multi-byte at cut", "ééé", 3, "é"},
+		{"multi-byte exactly", "ééé", 4, "éé"},
+		{"smaller than a character", "日本", 2, ""},
+		{"no room", "abc", 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cutText(tt.text, tt.limit); got != tt.want {
+				t.Errorf("cutText(%q, %d) = %q, want %q", tt.text, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListResult(t *testing.T) {
+	items := make([]string, 5)
+	for i := range items {
+		items[i] = fmt.Sprintf("%d %s", i, strings.Repeat("Ünïcödé ", 30))
+	}
+	full, _ := json.MarshalIndent(items, "", "  ")
+
+	tests := []struct {
+		name         string
+		limit        int
+		wantReturned int // -1 for the plain array
+	}{
+		{"unlimited", 0, -1},
+		{"exactly at boundary", len(full), -1},
+		{"one byte short", len(full) - 1, 4},
+		{"smaller than one entry", 300, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := listResult(items, tt.limit)
+			if err != nil {
+				t.Fatalf("listResult failed: %v", err)
+			}
+			text := resultText(result)
+
+			if tt.wantReturned < 0 {
+				if text != string(full) {
+					t.Errorf("listResult() = %s, want %s", text, full)
+				}
+				return
+			}
+
+			var got truncatedList[string]
+			if err := json.Unmarshal([]byte(text), &got); err != nil {
+				t.Fatalf("Truncated result is not valid JSON: %v\n%s", err, text)
+			}
+			if !got.Truncated || got.Returned != tt.wantReturned || got.Total != len(items) || len(got.Results) != got.Returned {
+				t.Errorf("listResult() = %+v, want %d of %d", got, tt.wantReturned, len(items))
+			}
+			if tt.wantReturned > 0 && len(text) > tt.limit {
+				t.Errorf("Result is %d bytes, over the limit of %d", len(text), tt.limit)
+			}
+		})
+	}
+}
+
+func TestListEnvelope(t *testing.T) {
+	items := []string{"a.md", "b.md", strings.Repeat("c", 600) + ".md"}
+	warnings := []string{"folder Private could not be read and was skipped: permission denied"}
+
+	for _, limit := range []int{0, 600} {
+		result, err := fitJSON(len(items), limit, listWrap(items, listExtras{Warnings: warnings}, true))
+		if err != nil {
+			t.Fatalf("fitJSON failed: %v", err)
+		}
+		var got listEnvelope[string]
+		if err := json.Unmarshal([]byte(resultText(result)), &got); err != nil {
+			t.Fatalf("Result is not valid JSON: %v\n%s", err, resultText(result))
+		}
+		wantReturned := len(items)
+		if limit > 0 {
+			wantReturned = 2
+		}
+		if len(got.Warnings) != 1 || len(got.Results) != wantReturned || got.Truncated != (limit > 0) {
+			t.Errorf("Envelope (limit %d) = %+v, want %d results and the warning", limit, got, wantReturned)
+		}
+	}
+
+	// Without an envelope the plain array is kept
+	result, _ := listResult(items[:1], 0)
+	if text := resultText(result); !strings.HasPrefix(text, "[") {
+		t.Errorf("listResult() = %s, want an array", text)
+	}
+}
+
+// noticeOffset matches the offset to continue from in a truncation notice
+var noticeOffset = regexp.MustCompile(`offset=(\d+)`)
+
+func TestReadNoteLimit(t *testing.T) {
+	v, err := vault.NewVault(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	h := NewHandlers(v, logger, WithMaxResponseBytes(2048))
+
+	var b strings.Builder
+	for i := range 100 {
+		fmt.Fprintf(&b, "Line %d: crème brûlée\n", i)
+	}
+	b.WriteString(strings.Repeat("日本語", 400) + "\n") // One line longer than the limit
+	content := b.String()
+	if result := callTool(t, h, "create_note", map[string]any{"path": "long.md", "content": content}); result.IsError {
+		t.Fatalf("create_note failed: %s", resultText(result))
+	}
+
+	t.Run("pages through the note", func(t *testing.T) {
+		var got strings.Builder
+		offset := 0
+		for page := 0; ; page++ {
+			if page > 20 {
+				t.Fatal("Paging did not finish")
+			}
+			result := callTool(t, h, "read_note", map[string]any{"path": "long.md", "offset": offset, "max_bytes": 1024})
+			if result.IsError {
+				t.Fatalf("read_note failed: %s", resultText(result))
+			}
+			if size := resultSize(result); size > 1024 {
+				t.Errorf("Page %d is %d bytes, over the limit", page, size)
+			}
+
+			text := resultText(result)
+			if !utf8.ValidString(text) {
+				t.Fatalf("Page %d cut inside a character: %q", page, text)
+			}
+			got.WriteString(text)
+
+			last := result.Content[len(result.Content)-1].(mcp.TextContent).Text
+			match := noticeOffset.FindStringSubmatch(last)
+			if match == nil {
+				break
+			}
+			if page == 0 && !strings.HasSuffix(text, "\n") {
+				t.Errorf("Expected the first page to end at a line break, got %q", text[len(text)-20:])
+			}
+			offset, _ = strconv.Atoi(match[1])
+		}
+
+		if got.String() != content {
+			t.Errorf("Pages joined = %d bytes, want the %d bytes of the note", got.Len(), len(content))
+		}
+	})
+
+	t.Run("offset inside a character", func(t *testing.T) {
+		start := strings.Index(content, "日")
+		result := callTool(t, h, "read_note", map[string]any{"path": "long.md", "offset": start + 1})
+		if text := resultText(result); !strings.HasPrefix(text, "本") {
+			t.Errorf("Expected the content from the next character, got %q", text[:10])
+		}
+	})
+
+	t.Run("offset past the end", func(t *testing.T) {
+		result := callTool(t, h, "read_note", map[string]any{"path": "long.md", "offset": len(content) + 1})
+		checkToolError(t, result, CodeInvalidParams)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		for i := range 30 {
+			callTool(t, h, "create_note", map[string]any{"path": fmt.Sprintf("Notes/note %02d.md", i), "content": "x"})
+		}
+		result := callTool(t, h, "list_notes", map[string]any{"path": "Notes", "max_bytes": 600})
+
+		var got truncatedList[noteResult]
+		if err := json.Unmarshal([]byte(resultText(result)), &got); err != nil {
+			t.Fatalf("Result is not valid JSON: %v", err)
+		}
+		if !got.Truncated || got.Total != 30 || got.Returned == 0 || got.Returned != len(got.Results) {
+			t.Errorf("list_notes = %+v, want a truncated list of 30 notes", got)
+		}
+		if got.Results[0].Path != "Notes/note 00.md" {
+			t.Errorf("Expected the first notes to be kept, got %s", got.Results[0].Path)
+		}
+	})
+}
+
+func TestResponseLimitMiddleware(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handler := func(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return textResult(strings.Repeat("x", request.GetInt("size", 0))), nil
+	}
+	call := func(h *Handlers, size int) *mcp.CallToolResult {
+		var request mcp.CallToolRequest
+		request.Params.Name = "changed_notes"
+		request.Params.Arguments = map[string]any{"size": size}
+		result, err := h.ResponseLimitMiddleware()(handler)(context.Background(), request)
+		if err != nil {
+			t.Fatalf("Middleware returned error: %v", err)
+		}
+		return result
+	}
+
+	limited := NewHandlers(failingVault{}, logger, WithMaxResponseBytes(1000))
+	if result := call(limited, 1000); result.IsError {
+		t.Errorf("Expected a response at the limit to pass, got %s", resultText(result))
+	}
+	checkToolError(t, call(limited, 1001), CodeTooLarge)
+
+	unlimited := NewHandlers(failingVault{}, logger)
+	if result := call(unlimited, 1<<20); result.IsError {
+		t.Errorf("Expected no limit by default, got %s", resultText(result))
+	}
+}
@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// noteTypesResult is the result of list_note_types
+type noteTypesResult struct {
+	Types []vault.NoteTypeCount `json:"types"`
+	Hint  string                `json:"hint,omitempty"`
+}
+
+// withNoteType adds the type parameter of the tools filtering by note type
+func withNoteType() mcp.ToolOption {
+	return mcp.WithString(
+		"type",
+		mcp.Description("Only notes of this type, as classified by the server's note type rules; list_note_types lists the types."),
+	)
+}
+
+// ListNoteTypesTool returns the ServerTool for listing the note types.
+func (h *Handlers) ListNoteTypesTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"list_note_types",
+		mcp.WithDescription("List the note types the server classifies notes into, such as daily notes, people or meetings, with the number of notes of each and the rules giving it: "+
+			"a path glob, frontmatter conditions or both. The first rule matching a note gives its type; notes no rule matches have the default type. "+
+			"Pass a type as 'type' to list_notes, search_notes, recent_notes or find_tasks to keep to notes of that type."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Optional folder to count notes in, relative to vault root. If empty, counts the entire vault."),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleListNoteTypes,
+	}
+}
+
+// handleListNoteTypes implements the list_note_types tool handler.
+func (h *Handlers) handleListNoteTypes(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	path := request.GetString("path", "")
+
+	// Call vault
+	types, err := h.vault.NoteTypes(ctx, path)
+	if err != nil {
+		return vaultErrorResult(err, "listing note types", path), nil
+	}
+
+	result := noteTypesResult{Types: types}
+	if len(types) == 0 {
+		result.Hint = "No note types are configured; add rules under types in the server's config file."
+	}
+	return jsonResult(result)
+}
@@ -15,14 +15,24 @@ func (h *Handlers) UpdateNoteTool() server.ServerTool {
 		mcp.WithDescription("Update an existing note with new content. The note must already exist."),
 		mcp.WithString(
 			"path",
-			mcp.Description("Path to the note to update (relative to vault root, must end with .md)."),
-			mcp.Required(),
+			mcp.Description("Path to the note to update (relative to vault root, must end with .md). Either path or name is required."),
+		),
+		mcp.WithString(
+			"name",
+			mcp.Description("Note name, frontmatter title or alias to resolve instead of a path. Fails with a list of candidates when ambiguous."),
 		),
 		mcp.WithString(
 			"content",
 			mcp.Description("New content for the note in markdown format."),
 			mcp.Required(),
 		),
+		mcp.WithBoolean(
+			"dry_run",
+			mcp.Description("Validate the request and preview the result as a unified diff without writing anything."),
+			mcp.DefaultBool(false),
+		),
+		withForce(),
+		mcp.WithOutputSchema[WriteResult](),
 		mcp.WithReadOnlyHintAnnotation(false),
 		mcp.WithDestructiveHintAnnotation(true),
 	)
@@ -36,53 +46,35 @@ func (h *Handlers) UpdateNoteTool() server.ServerTool {
 // handleUpdateNote implements the update_note tool handler.
 func (h *Handlers) handleUpdateNote(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Extract parameters
-	path, err := request.RequireString("path")
-	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Missing required parameter 'path': %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+	path, errResult := h.notePath(ctx, request, "updating note")
+	if errResult != nil {
+		return errResult, nil
 	}
 
 	content, err := request.RequireString("content")
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Missing required parameter 'content': %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return missingParamResult("content", err), nil
+	}
+
+	// Preview without writing
+	if request.GetBool("dry_run", false) {
+		current, err := h.vault.ValidateUpdate(ctx, path)
+		if err != nil {
+			return vaultErrorResult(err, "updating note", path), nil
+		}
+		content, err := h.vault.PrepareContent(path, content, false)
+		if err != nil {
+			return vaultErrorResult(err, "updating note", path), nil
+		}
+
+		return structuredResult(textResult(dryRunText(path, current, content)), h.newWriteResult(path, actionUpdated, true)), nil
 	}
 
 	// Call vault
 	err = h.vault.Update(ctx, path, content)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: formatVaultError(err, "updating", path),
-				},
-			},
-			IsError: true,
-		}, nil
+		return vaultErrorResult(err, "updating note", path), nil
 	}
 
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.TextContent{
-				Type: "text",
-				Text: fmt.Sprintf("Successfully updated note: %s", path),
-			},
-		},
-		IsError: false,
-	}, nil
+	return structuredResult(textResult(h.withNoteURI(fmt.Sprintf("Successfully updated note: %s", path), path)), h.newWriteResult(path, actionUpdated, false)), nil
 }
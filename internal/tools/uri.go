@@ -0,0 +1,115 @@
+package tools
+
+import (
+	"context"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// noteResult is a NoteInfo with the URI opening it in Obsidian, set only
+// when a vault name is configured.
+type noteResult struct {
+	vault.NoteInfo
+	ObsidianURI string `json:"obsidian_uri,omitempty"`
+}
+
+// noteResults attaches Obsidian URIs to notes. A nil slice stays nil so
+// the JSON output is unchanged when no vault name is configured.
+func (h *Handlers) noteResults(notes []vault.NoteInfo) []noteResult {
+	if notes == nil {
+		return nil
+	}
+
+	results := make([]noteResult, len(notes))
+	for i, note := range notes {
+		results[i] = noteResult{NoteInfo: note, ObsidianURI: h.noteURI(note.Path)}
+	}
+	return results
+}
+
+// noteURI returns the Obsidian URI for a note, or "" without a vault name.
+func (h *Handlers) noteURI(path string) string {
+	if h.vaultName == "" {
+		return ""
+	}
+	if h.vaultRoot != "" {
+		path = h.vaultRoot + "/" + path
+	}
+	return obsidianURI(h.vaultName, path)
+}
+
+// withNoteURI appends the note's Obsidian URI to a status message.
+func (h *Handlers) withNoteURI(text, path string) string {
+	if uri := h.noteURI(path); uri != "" {
+		return text + "\nObsidian URI: " + uri
+	}
+	return text
+}
+
+// obsidianURI builds an obsidian://open link. Obsidian expects the file
+// without its .md extension and both values encoded like JavaScript's
+// encodeURIComponent, so slashes become %2F and spaces %20.
+func obsidianURI(vaultName, path string) string {
+	file := strings.TrimSuffix(filepath.ToSlash(path), ".md")
+	return "obsidian://open?vault=" + encodeURIComponent(vaultName) + "&file=" + encodeURIComponent(file)
+}
+
+// encodeURIComponent percent-encodes s for use as a query value. Unlike
+// url.QueryEscape, spaces are encoded as %20 rather than +.
+func encodeURIComponent(s string) string {
+	// QueryEscape already encodes a literal + as %2B, so any + left is a space
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// GetNoteURITool returns the ServerTool for building a note's Obsidian URI.
+func (h *Handlers) GetNoteURITool() server.ServerTool {
+	tool := mcp.NewTool(
+		"get_note_uri",
+		mcp.WithDescription("Get the obsidian://open URI that opens a note in the Obsidian app. Requires the server to be started with a vault name."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Path to the note file (relative to vault root, must end with .md). Either path or name is required."),
+		),
+		mcp.WithString(
+			"name",
+			mcp.Description("Note name, frontmatter title or alias to resolve instead of a path."),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleGetNoteURI,
+	}
+}
+
+// handleGetNoteURI implements the get_note_uri tool handler.
+func (h *Handlers) handleGetNoteURI(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if h.vaultName == "" {
+		return errorResult(ToolError{
+			Code:    CodeNotConfigured,
+			Message: "Obsidian vault name not configured",
+			Hint:    "Start the server with --vault-name.",
+		}), nil
+	}
+
+	// Extract parameters
+	path, errResult := h.notePath(ctx, request, "building note URI")
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	// Only link to notes that exist inside the vault
+	if _, err := h.vault.Read(ctx, path); err != nil {
+		return vaultErrorResult(err, "building note URI", path), nil
+	}
+
+	return textResult(h.noteURI(path)), nil
+}
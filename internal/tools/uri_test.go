@@ -0,0 +1,126 @@
+package tools
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+func TestObsidianURI(t *testing.T) {
+	tests := []struct {
+		name      string
+		vaultName string
+		path      string
+		want      string
+		wantFile  string
+	}{
+		{
+			name:      "simple note",
+			vaultName: "Notes",
+			path:      "ideas.md",
+			want:      "obsidian://open?vault=Notes&file=ideas",
+			wantFile:  "ideas",
+		},
+		{
+			name:      "spaces",
+			vaultName: "My Vault",
+			path:      "Daily Notes/2024 01 05.md",
+			want:      "obsidian://open?vault=My%20Vault&file=Daily%20Notes%2F2024%2001%2005",
+			wantFile:  "Daily Notes/2024 01 05",
+		},
+		{
+			name:      "nested folders",
+			vaultName: "work",
+			path:      "projects/2024/q1/plan.md",
+			want:      "obsidian://open?vault=work&file=projects%2F2024%2Fq1%2Fplan",
+			wantFile:  "projects/2024/q1/plan",
+		},
+		{
+			name:      "ampersand, plus and equals",
+			vaultName: "R&D",
+			path:      "Q&A/a+b=c.md",
+			want:      "obsidian://open?vault=R%26D&file=Q%26A%2Fa%2Bb%3Dc",
+			wantFile:  "Q&A/a+b=c",
+		},
+		{
+			name:      "unicode",
+			vaultName: "Заметки",
+			path:      "日本語/café.md",
+			wantFile:  "日本語/café",
+		},
+		{
+			name:      "only the final .md is removed",
+			vaultName: "v",
+			path:      "notes.md/readme.md.md",
+			wantFile:  "notes.md/readme.md",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := obsidianURI(tt.vaultName, tt.path)
+			if tt.want != "" && got != tt.want {
+				t.Errorf("obsidianURI() = %s, want %s", got, tt.want)
+			}
+			if strings.Contains(got, "+") || strings.Contains(got, " ") {
+				t.Errorf("obsidianURI() = %s, spaces must be encoded as %%20", got)
+			}
+
+			// Decoding must give back the vault name and path
+			u, err := url.Parse(got)
+			if err != nil {
+				t.Fatalf("url.Parse() error = %v", err)
+			}
+			if u.Scheme != "obsidian" || u.Host != "open" {
+				t.Errorf("URI = %s, want obsidian://open", got)
+			}
+			query := u.Query()
+			if query.Get("vault") != tt.vaultName {
+				t.Errorf("vault = %q, want %q", query.Get("vault"), tt.vaultName)
+			}
+			if query.Get("file") != tt.wantFile {
+				t.Errorf("file = %q, want %q", query.Get("file"), tt.wantFile)
+			}
+		})
+	}
+}
+
+func TestNoteResults(t *testing.T) {
+	notes := []vault.NoteInfo{{Path: "a b.md", Tags: []string{}}}
+
+	t.Run("without vault name output is unchanged", func(t *testing.T) {
+		h := NewHandlers(nil, nil)
+
+		got, _ := json.Marshal(h.noteResults(notes))
+		want, _ := json.Marshal(notes)
+		if string(got) != string(want) {
+			t.Errorf("noteResults() = %s, want %s", got, want)
+		}
+
+		if got, _ := json.Marshal(h.noteResults(nil)); string(got) != "null" {
+			t.Errorf("noteResults(nil) = %s, want null", got)
+		}
+	})
+
+	t.Run("with vault name", func(t *testing.T) {
+		h := NewHandlers(nil, nil, WithVaultName("V"))
+
+		results := h.noteResults(notes)
+		if results[0].ObsidianURI != "obsidian://open?vault=V&file=a%20b" {
+			t.Errorf("ObsidianURI = %s", results[0].ObsidianURI)
+		}
+
+		rooted := NewHandlers(nil, nil, WithVaultName("V"), WithVaultRoot("./Work/"))
+		if uri := rooted.noteResults(notes)[0].ObsidianURI; uri != "obsidian://open?vault=V&file=Work%2Fa%20b" {
+			t.Errorf("ObsidianURI with a root = %s", uri)
+		}
+
+		data, _ := json.Marshal(results)
+		if !strings.Contains(string(data), `"path":"a b.md"`) || !strings.Contains(string(data), `"obsidian_uri"`) {
+			t.Errorf("JSON = %s, want flattened note fields and obsidian_uri", data)
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kratos/mcp-notes/internal/vault"
+)
+
+// verifyResult groups the problems of a VerifyReport by kind
+type verifyResult struct {
+	NotesChecked int                                   `json:"notes_checked"`
+	Counts       map[vault.ProblemKind]int             `json:"counts"`
+	Problems     map[vault.ProblemKind][]vault.Problem `json:"problems"`
+	Truncated    bool                                  `json:"truncated,omitempty"`
+}
+
+// VerifyVaultTool returns the ServerTool for checking the vault for
+// damaged notes.
+func (h *Handlers) VerifyVaultTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"verify_vault",
+		mcp.WithDescription("Check the notes of a folder for damage, e.g. after a sync conflict. Reports empty or whitespace-only notes, sync conflict markers and conflict copies, invalid frontmatter, broken links, content that cannot be decoded, file names that are invalid on Windows or differ only by case, and cached or indexed notes that disagree with disk. Problems are grouped by kind with counts. Reads every note, so pass a path to check part of the vault."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Folder to check, relative to vault root. If empty, checks the whole vault."),
+		),
+		mcp.WithArray(
+			"conflict_markers",
+			mcp.Description("Line prefixes that mark a sync conflict. Defaults to [\"<<<<<<<\", \">>>>>>>\"]."),
+			mcp.WithStringItems(),
+		),
+		mcp.WithArray(
+			"conflict_names",
+			mcp.Description("File name substrings that mark a conflict copy. Defaults to [\".sync-conflict-\", \"conflicted copy\"]."),
+			mcp.WithStringItems(),
+		),
+		mcp.WithBoolean(
+			"repair",
+			mcp.Description("Drop cached and indexed notes that disagree with disk, so they are read again. Never changes a note."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleVerifyVault,
+	}
+}
+
+// handleVerifyVault implements the verify_vault tool handler.
+func (h *Handlers) handleVerifyVault(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	opts := vault.VerifyOptions{
+		Subpath:         request.GetString("path", ""),
+		ConflictMarkers: request.GetStringSlice("conflict_markers", nil),
+		ConflictNames:   request.GetStringSlice("conflict_names", nil),
+		Repair:          request.GetBool("repair", false),
+	}
+
+	// Call vault
+	report, err := h.vault.Verify(ctx, opts)
+	if err != nil {
+		return vaultErrorResult(err, "verifying vault", opts.Subpath), nil
+	}
+
+	return fitJSON(len(report.Problems), h.maxResponseBytes, func(n int) any {
+		result := verifyResult{
+			NotesChecked: report.NotesChecked,
+			Counts:       report.Counts,
+			Problems:     make(map[vault.ProblemKind][]vault.Problem),
+			Truncated:    n < len(report.Problems),
+		}
+		for _, problem := range report.Problems[:n] {
+			result.Problems[problem.Kind] = append(result.Problems[problem.Kind], problem)
+		}
+		return result
+	})
+}
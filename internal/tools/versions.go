@@ -0,0 +1,178 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ListNoteVersionsTool returns the ServerTool for listing backed up versions of a note.
+func (h *Handlers) ListNoteVersionsTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"list_note_versions",
+		mcp.WithDescription("List previous versions of a note saved automatically before it was overwritten, newest first."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Path to the note (relative to vault root, must end with .md)."),
+			mcp.Required(),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleListNoteVersions,
+	}
+}
+
+// handleListNoteVersions implements the list_note_versions tool handler.
+func (h *Handlers) handleListNoteVersions(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	path, err := request.RequireString("path")
+	if err != nil {
+		return missingParamResult("path", err), nil
+	}
+
+	// Call vault
+	versions, err := h.vault.ListVersions(ctx, path)
+	if err != nil {
+		return vaultErrorResult(err, "listing note versions", path), nil
+	}
+
+	return listResult(versions, h.maxResponseBytes)
+}
+
+// RestoreNoteVersionTool returns the ServerTool for restoring a backed up version of a note.
+func (h *Handlers) RestoreNoteVersionTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"restore_note_version",
+		mcp.WithDescription("Restore a note to a previous version from list_note_versions. The current content is backed up first, so the restore can itself be undone."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Path to the note (relative to vault root, must end with .md)."),
+			mcp.Required(),
+		),
+		mcp.WithString(
+			"version",
+			mcp.Description("Version id as returned by list_note_versions."),
+			mcp.Required(),
+		),
+		withForce(),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleRestoreNoteVersion,
+	}
+}
+
+// handleRestoreNoteVersion implements the restore_note_version tool handler.
+func (h *Handlers) handleRestoreNoteVersion(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	path, err := request.RequireString("path")
+	if err != nil {
+		return missingParamResult("path", err), nil
+	}
+
+	version, err := request.RequireString("version")
+	if err != nil {
+		return missingParamResult("version", err), nil
+	}
+
+	// Call vault
+	err = h.vault.RestoreVersion(ctx, path, version)
+	if err != nil {
+		return vaultErrorResult(err, "restoring note version", path), nil
+	}
+
+	return textResult(fmt.Sprintf("Successfully restored note %s to version %s", path, version)), nil
+}
+
+// DiffNoteTool returns the ServerTool for showing what changed in a note since an earlier revision.
+func (h *Handlers) DiffNoteTool() server.ServerTool {
+	tool := mcp.NewTool(
+		"diff_note",
+		mcp.WithDescription("Show what changed in a note since you last read it, given the content_hash or modified time returned then. "+
+			"Returns not_modified=true and nothing else when the note is unchanged, otherwise a unified diff with the lines added and removed. "+
+			"When the earlier content is no longer available, baseline is \"unavailable\" and the full current content is returned instead."),
+		mcp.WithString(
+			"path",
+			mcp.Description("Path to the note (relative to vault root, must end with .md)."),
+			mcp.Required(),
+		),
+		mcp.WithString(
+			"revision",
+			mcp.Description("The note's content_hash, or its modified time, from an earlier call. A content_hash finds the earlier content more often."),
+			mcp.Required(),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: h.handleDiffNote,
+	}
+}
+
+// baselineUnavailable marks a diff_note result without a diff
+const baselineUnavailable = "unavailable"
+
+// noteDiffResult is the diff_note response
+type noteDiffResult struct {
+	Path            string     `json:"path"`
+	NotModified     bool       `json:"not_modified"`
+	Revision        string     `json:"revision"`                   // Current content_hash
+	Modified        *time.Time `json:"modified,omitempty"`         // Current modification time
+	Baseline        string     `json:"baseline,omitempty"`         // Where the earlier content came from: cache, backup or unavailable
+	BaselineVersion string     `json:"baseline_version,omitempty"` // Backup version id of the earlier content
+	LinesAdded      *int       `json:"lines_added,omitempty"`
+	LinesRemoved    *int       `json:"lines_removed,omitempty"`
+	Truncated       bool       `json:"truncated,omitempty"` // The diff was cut; lines_added and lines_removed still count it all
+	Diff            string     `json:"diff,omitempty"`
+	Content         *string    `json:"content,omitempty"` // Full current content when the baseline is unavailable
+}
+
+// handleDiffNote implements the diff_note tool handler.
+func (h *Handlers) handleDiffNote(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	path, err := request.RequireString("path")
+	if err != nil {
+		return missingParamResult("path", err), nil
+	}
+
+	revision, err := request.RequireString("revision")
+	if err != nil {
+		return missingParamResult("revision", err), nil
+	}
+
+	// Call vault
+	diff, err := h.vault.DiffNote(ctx, path, revision)
+	if err != nil {
+		return vaultErrorResult(err, "diffing note", path), nil
+	}
+
+	result := noteDiffResult{Path: diff.Path, NotModified: diff.Unchanged, Revision: diff.Revision}
+	if diff.Unchanged {
+		return jsonResult(result)
+	}
+	result.Modified = &diff.Modified
+
+	if diff.BaselineSource == "" {
+		result.Baseline = baselineUnavailable
+		result.Content = &diff.Content
+		return jsonResult(result)
+	}
+
+	lines := computeDiff(diff.Path, diff.Baseline, diff.Content)
+	result.Baseline, result.BaselineVersion = diff.BaselineSource, diff.BaselineVersion
+	result.LinesAdded, result.LinesRemoved = &lines.added, &lines.removed
+	result.Truncated, result.Diff = lines.truncated, lines.text
+	return jsonResult(result)
+}
@@ -0,0 +1,171 @@
+package vault
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// activityDateLayout formats the days of an ActivityReport
+const activityDateLayout = "2006-01-02"
+
+// wordJoiners continue a word when they appear inside it, so "don't" and
+// "well-known" count once
+const wordJoiners = "'’-_"
+
+// ActivityOptions selects the notes and days ActivityReport covers
+type ActivityOptions struct {
+	Subpath string    // Directory to look in, empty for the whole vault
+	Tags    []string  // Only notes with any of these tags, all notes when empty
+	From    time.Time // First day covered; only the date counts
+	To      time.Time // Last day covered, inclusive; only the date counts
+}
+
+// ActivityCounts are the notes created and modified in a period and the
+// words they hold. Modified counts each note on the day it was last
+// modified.
+type ActivityCounts struct {
+	Created       int `json:"created,omitempty"`
+	CreatedWords  int `json:"created_words,omitempty"`
+	Modified      int `json:"modified,omitempty"`
+	ModifiedWords int `json:"modified_words,omitempty"`
+}
+
+// ActivityPeriod is the activity of a day, weekday or month
+type ActivityPeriod struct {
+	Period string `json:"period"` // "2024-03-01", "Monday" or "2024-03"
+	ActivityCounts
+}
+
+// ActivityReport is the result of ActivityReport
+type ActivityReport struct {
+	From     string           `json:"from"`
+	To       string           `json:"to"`
+	Totals   ActivityCounts   `json:"totals"`
+	Days     []ActivityPeriod `json:"days"`     // Days with activity, in order
+	Weekdays []ActivityPeriod `json:"weekdays"` // Every weekday, Monday first
+	Months   []ActivityPeriod `json:"months"`   // Months with activity, in order
+
+	// Truncated is set when Days was cut to fit a response; the totals
+	// and rollups still cover every day
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// add counts a note with words words as created or modified
+func (c *ActivityCounts) add(created bool, words int) {
+	if created {
+		c.Created++
+		c.CreatedWords += words
+	} else {
+		c.Modified++
+		c.ModifiedWords += words
+	}
+}
+
+// merge adds the counts of o
+func (c *ActivityCounts) merge(o ActivityCounts) {
+	c.Created += o.Created
+	c.CreatedWords += o.CreatedWords
+	c.Modified += o.Modified
+	c.ModifiedWords += o.ModifiedWords
+}
+
+// ActivityReport counts the notes under opts.Subpath created and last
+// modified on each day from opts.From to opts.To, with the words they
+// hold, and rolls the days up by weekday and month. Creation dates come
+// from frontmatter, birth time or mtime as for sorting by creation; words
+// are counted in the cached content, by rune class so CJK text counts
+// too. The vault is walked once.
+func (v *vault) ActivityReport(ctx context.Context, opts ActivityOptions) (ActivityReport, error) {
+	from, to := opts.From.Format(activityDateLayout), opts.To.Format(activityDateLayout)
+	tags := newTagFilter(opts.Tags, nil, nil)
+
+	var mu sync.Mutex
+	days := make(map[string]*ActivityCounts)
+	_, err := v.walkNotes(ctx, ListOptions{Subpath: opts.Subpath, Recursive: true}, func(file noteFile, entry CacheEntry) bool {
+		if !tags.matches(entry.Tags) {
+			return false
+		}
+		mtime := file.info.ModTime()
+		created := v.resolveCreated(file.fullPath, entry.Properties, mtime).Format(activityDateLayout)
+		modified := mtime.Format(activityDateLayout)
+		inRange := func(day string) bool { return day >= from && day <= to }
+		if !inRange(created) && !inRange(modified) {
+			return false
+		}
+
+		words := countWordRunes(entry.Content)
+		mu.Lock()
+		defer mu.Unlock()
+		for _, bucket := range []struct {
+			day     string
+			created bool
+		}{{created, true}, {modified, false}} {
+			if !inRange(bucket.day) {
+				continue
+			}
+			if days[bucket.day] == nil {
+				days[bucket.day] = &ActivityCounts{}
+			}
+			days[bucket.day].add(bucket.created, words)
+		}
+		return false // Only the counts are needed
+	})
+	if err != nil {
+		return ActivityReport{}, err
+	}
+
+	report := ActivityReport{From: from, To: to, Days: []ActivityPeriod{}, Months: []ActivityPeriod{}}
+	for i := range 7 {
+		report.Weekdays = append(report.Weekdays, ActivityPeriod{Period: time.Weekday((i + 1) % 7).String()})
+	}
+	for _, day := range slices.Sorted(maps.Keys(days)) {
+		counts := *days[day]
+		report.Days = append(report.Days, ActivityPeriod{Period: day, ActivityCounts: counts})
+
+		date, _ := time.Parse(activityDateLayout, day)
+		weekday := &report.Weekdays[(int(date.Weekday())+6)%7]
+		month := day[:len("2006-01")]
+		if n := len(report.Months); n == 0 || report.Months[n-1].Period != month {
+			report.Months = append(report.Months, ActivityPeriod{Period: month})
+		}
+		for _, c := range []*ActivityCounts{&report.Totals, &weekday.ActivityCounts, &report.Months[len(report.Months)-1].ActivityCounts} {
+			c.merge(counts)
+		}
+	}
+	return report, nil
+}
+
+// countWordRunes counts the words in the body of a note by rune class: a
+// run of letters, marks and digits is one word, and so is each Han,
+// Hiragana or Katakana character, as those scripts do not put spaces
+// between words. Frontmatter is not counted.
+func countWordRunes(content string) int {
+	if _, body, ok := SplitFrontmatter(content); ok {
+		content = body
+	}
+
+	words := 0
+	inWord := false
+	for _, r := range content {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana):
+			words++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r):
+			if !inWord {
+				words++
+				inWord = true
+			}
+		case inWord && strings.ContainsRune(wordJoiners, r):
+			// Part of the word if a letter follows, harmless otherwise
+		default:
+			inWord = false
+		}
+	}
+	return words
+}
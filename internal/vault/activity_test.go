@@ -0,0 +1,101 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestActivityReport(t *testing.T) {
+	tmpDir := t.TempDir()
+	// Modification times as notes of the same day, local time
+	day := func(date string) time.Time {
+		t, _ := time.ParseInLocation(activityDateLayout+" 15:04", date+" 12:00", time.Local)
+		return t
+	}
+	notes := []struct {
+		path, content string
+		mtime         time.Time
+	}{
+		{"Journal/a.md", "---\ncreated: 2024-03-04\n---\nOne two three #journal", day("2024-03-04")}, // Monday, created and modified
+		{"Journal/b.md", "---\ncreated: 2024-03-04\n---\n今日は晴れ #journal", day("2024-04-06")},         // Saturday edit
+		{"Journal/c.md", "---\ncreated: 2023-12-31\n---\nOld note, edited later", day("2024-03-05")}, // Created before the range
+		{"Other/d.md", "---\ncreated: 2024-03-05\n---\nwell-known don't #work", day("2024-03-05")},   // Tuesday
+		{"Other/e.md", "---\ncreated: 2025-01-01\n---\nAfter the range", day("2025-01-01")},
+	}
+	for _, n := range notes {
+		writeFiles(t, tmpDir, map[string]string{n.path: n.content})
+		fullPath := filepath.Join(tmpDir, n.path)
+		if err := os.Chtimes(fullPath, n.mtime, n.mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	ctx := context.Background()
+	opts := ActivityOptions{From: day("2024-01-01"), To: day("2024-12-31")}
+
+	report, err := v.ActivityReport(ctx, opts)
+	if err != nil {
+		t.Fatalf("ActivityReport() error = %v", err)
+	}
+	wantDays := []ActivityPeriod{
+		{"2024-03-04", ActivityCounts{Created: 2, CreatedWords: 10, Modified: 1, ModifiedWords: 4}},
+		{"2024-03-05", ActivityCounts{Created: 1, CreatedWords: 3, Modified: 2, ModifiedWords: 7}},
+		{"2024-04-06", ActivityCounts{Modified: 1, ModifiedWords: 6}},
+	}
+	if !reflect.DeepEqual(report.Days, wantDays) {
+		t.Errorf("ActivityReport() days = %+v, want %+v", report.Days, wantDays)
+	}
+	if want := (ActivityCounts{Created: 3, CreatedWords: 13, Modified: 4, ModifiedWords: 17}); report.Totals != want {
+		t.Errorf("ActivityReport() totals = %+v, want %+v", report.Totals, want)
+	}
+	if len(report.Weekdays) != 7 || report.Weekdays[0].Period != "Monday" || report.Weekdays[0].Created != 2 ||
+		report.Weekdays[5].Period != "Saturday" || report.Weekdays[5].Modified != 1 || report.Weekdays[6].Period != "Sunday" {
+		t.Errorf("ActivityReport() weekdays = %+v", report.Weekdays)
+	}
+	if len(report.Months) != 2 || report.Months[0].Period != "2024-03" || report.Months[0].Modified != 3 || report.Months[1].Period != "2024-04" {
+		t.Errorf("ActivityReport() months = %+v", report.Months)
+	}
+
+	opts.Tags, opts.Subpath = []string{"journal"}, "Journal"
+	report, err = v.ActivityReport(ctx, opts)
+	if err != nil {
+		t.Fatalf("ActivityReport() error = %v", err)
+	}
+	if want := (ActivityCounts{Created: 2, CreatedWords: 10, Modified: 2, ModifiedWords: 10}); report.Totals != want {
+		t.Errorf("ActivityReport() tagged totals = %+v, want %+v", report.Totals, want)
+	}
+
+	if _, err := v.ActivityReport(ctx, ActivityOptions{Subpath: "missing"}); !errors.Is(err, ErrDirectoryNotFound) {
+		t.Errorf("ActivityReport() missing folder error = %v", err)
+	}
+}
+
+func TestCountWordRunes(t *testing.T) {
+	tests := []struct {
+		content string
+		want    int
+	}{
+		{"", 0},
+		{"---\ntitle: Not counted\n---\nTwo words", 2},
+		{"# Heading\n\n- item, *emphasis* and `code`", 5},
+		{"don't stop the well-known rock-'n'-roll", 5},
+		{"Straße café naïve", 3},
+		{"我爱你", 3},
+		{"今日はいい天気", 7},
+		{"Go言語 version 1.22", 6},
+		{"안녕하세요 세계", 2},
+	}
+	for _, tt := range tests {
+		if got := countWordRunes(tt.content); got != tt.want {
+			t.Errorf("countWordRunes(%q) = %d, want %d", tt.content, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LinkLocation tells AddLink where in the note the link goes
+type LinkLocation string
+
+// Places AddLink can insert a link
+const (
+	LinkAtEnd        LinkLocation = "end_of_note"     // A line of its own at the end of the note
+	LinkUnderHeading LinkLocation = "under_heading"   // A line of its own at the end of a heading's section
+	LinkInList       LinkLocation = "in_section_list" // A list item at the end of a heading's section
+)
+
+// ParseLinkLocation validates a link location, defaulting to LinkAtEnd
+func ParseLinkLocation(s string) (LinkLocation, error) {
+	switch location := LinkLocation(strings.ToLower(strings.TrimSpace(s))); location {
+	case "":
+		return LinkAtEnd, nil
+	case LinkAtEnd, LinkUnderHeading, LinkInList:
+		return location, nil
+	default:
+		return "", fmt.Errorf("unknown location %q (want end_of_note, under_heading or in_section_list)", s)
+	}
+}
+
+// AddLinkOptions describes a link for AddLink
+type AddLinkOptions struct {
+	From          string       // Note to edit
+	To            string       // Vault-relative path of the note linked to
+	Alias         string       // Display text, none when empty
+	Location      LinkLocation // Where the link goes, LinkAtEnd when empty
+	Heading       string       // Heading whose section the link goes in, for LinkUnderHeading and LinkInList
+	CreateHeading bool         // Append a missing heading to the note rather than fail
+}
+
+// AddLinkResult reports the link AddLink inserted or found
+type AddLinkResult struct {
+	Path          string `json:"path"`
+	Target        string `json:"target"`                  // Vault-relative path linked to
+	Link          string `json:"link"`                    // Link as written, e.g. [[Meeting|notes]]
+	Line          string `json:"line"`                    // The line holding the link
+	LineNumber    int    `json:"line_number"`             // 1-based
+	AlreadyLinked bool   `json:"already_linked"`          // The location already linked the target; nothing was written
+	HeadingAdded  bool   `json:"heading_added,omitempty"` // The heading was missing and was appended
+	Revision      string `json:"revision"`                // Content hash of the note afterwards
+}
+
+// AddLink inserts a wikilink to opts.To into the note opts.From, using the
+// shortest link text that resolves to the target from there. When the
+// location, the whole note for LinkAtEnd or the heading's section
+// otherwise, already links the target, whatever the display text, nothing
+// is written and the existing link is reported. A missing heading is
+// ErrSectionNotFound listing the note's headings, unless CreateHeading.
+func (v *vault) AddLink(ctx context.Context, opts AddLinkOptions) (AddLinkResult, error) {
+	location, err := ParseLinkLocation(string(opts.Location))
+	if err != nil {
+		return AddLinkResult{}, fmt.Errorf("%w: %w", ErrInvalidLink, err)
+	}
+	heading := strings.TrimSpace(opts.Heading)
+	if (location == LinkAtEnd) != (heading == "") {
+		return AddLinkResult{}, fmt.Errorf("%w: a heading goes with location %s or %s only", ErrInvalidLink, LinkUnderHeading, LinkInList)
+	}
+	if strings.ContainsAny(opts.Alias, "[]|\r\n") {
+		return AddLinkResult{}, fmt.Errorf("%w: alias %q cannot hold brackets, | or line breaks", ErrInvalidLink, opts.Alias)
+	}
+
+	fullPath, err := v.validatePath(opts.From)
+	if err != nil {
+		return AddLinkResult{}, err
+	}
+	targetPath, err := v.validatePath(opts.To)
+	if err != nil {
+		return AddLinkResult{}, err
+	}
+	if _, err := os.Stat(targetPath); err != nil {
+		return AddLinkResult{}, fmt.Errorf("%w: %s", ErrNoteNotFound, v.relPath(targetPath))
+	}
+	relPath, target := v.relPath(fullPath), v.relPath(targetPath)
+
+	index, err := v.buildFileIndex(ctx)
+	if err != nil {
+		return AddLinkResult{}, err
+	}
+
+	unlock := v.writeLocks.lock(fullPath)
+	defer unlock()
+
+	if _, err := v.checkUpdate(ctx, relPath); err != nil {
+		return AddLinkResult{}, err
+	}
+	stat, err := os.Stat(fullPath)
+	if err != nil {
+		return AddLinkResult{}, ErrNoteNotFound
+	}
+	entry, err := v.loadEntry(fullPath, stat.ModTime())
+	if err != nil {
+		return AddLinkResult{}, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	result := AddLinkResult{Path: relPath, Target: target, Link: "[[" + index.linkTo(relPath, target, false)}
+	if opts.Alias != "" {
+		result.Link += "|" + opts.Alias
+	}
+	result.Link += "]]"
+
+	content := entry.Content
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if content == "" {
+		lines = nil
+	}
+
+	// The lines the location covers: the heading and its section, or all
+	start, end := 1, len(lines)
+	if location != LinkAtEnd {
+		var ok bool
+		start, end, ok = findHeadingSection(content, heading)
+		switch {
+		case ok:
+			end = min(end, len(lines))
+		case !opts.CreateHeading:
+			return AddLinkResult{}, fmt.Errorf("%w: #%s in %s, %s", ErrSectionNotFound, strings.TrimLeft(heading, "# "), relPath, headingList(entry.Headings))
+		default:
+			start, end = 0, 0
+		}
+	}
+
+	for _, link := range entry.Links {
+		if link.Line < start || link.Line > end || link.Heading != "" || link.BlockID != "" ||
+			link.Kind != LinkWiki && link.Kind != LinkMarkdown {
+			continue
+		}
+		if resolved, ok := index.resolve(relPath, link.Target); ok && resolved == target {
+			result.AlreadyLinked, result.LineNumber, result.Line = true, link.Line, lines[link.Line-1]
+			result.Revision = entry.ContentHash
+			return result, nil
+		}
+	}
+
+	result.Line = result.Link
+	if location == LinkInList {
+		result.Line = "- " + result.Link
+	}
+
+	var add []string
+	at := len(lines)
+	if start == 0 {
+		// Missing heading: appended with the link below it
+		if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) != "" {
+			add = append(add, "")
+		}
+		if !headingRegex.MatchString(heading) {
+			heading = "## " + heading
+		}
+		add = append(add, heading, result.Line)
+		result.HeadingAdded = true
+	} else {
+		// After the location's last line with content, so blank lines
+		// before the next heading stay where they are
+		last := start - 1
+		for i := end; i >= start; i-- {
+			if strings.TrimSpace(lines[i-1]) != "" {
+				last = i
+				break
+			}
+		}
+		at = last
+		// A link after a paragraph, or a list item after anything but a
+		// list, starts a block of its own
+		if last >= 1 && !(location != LinkAtEnd && last == start) &&
+			!(location == LinkInList && isListItem(lines[last-1])) {
+			add = append(add, "")
+		}
+		add = append(add, result.Line)
+	}
+	result.LineNumber = at + len(add)
+	lines = append(lines[:at], append(add, lines[at:]...)...)
+
+	updated, err := v.PrepareContent(relPath, strings.Join(lines, "\n")+"\n", false)
+	if err != nil {
+		return AddLinkResult{}, err
+	}
+	result.Revision = contentHash(updated)
+
+	// Check context cancellation before I/O; once writing starts it completes
+	if err := ctx.Err(); err != nil {
+		return AddLinkResult{}, err
+	}
+	written, err := v.writeNote(fullPath, updated)
+	if err != nil {
+		return AddLinkResult{}, err
+	}
+	return result, v.record(ctx, written)
+}
+
+// headingList describes the headings of a note for an error message
+func headingList(headings []Heading) string {
+	if len(headings) == 0 {
+		return "which has no headings"
+	}
+	names := make([]string, len(headings))
+	for i, h := range headings {
+		names[i] = fmt.Sprintf("%q", h.Text)
+	}
+	return "which has headings " + strings.Join(names, ", ")
+}
@@ -0,0 +1,143 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAddLink(t *testing.T) {
+	ctx := context.Background()
+	project := "---\nstatus: active\n---\n# Project\n\nGoals here.\n\n## Meetings\n- [[Kickoff]]\n\n## Notes\nSome text.\n"
+
+	tests := []struct {
+		name     string
+		opts     AddLinkOptions
+		wantLine string
+		wantNum  int
+		want     string // Content after the insertion
+	}{
+		{
+			name:     "end of note",
+			opts:     AddLinkOptions{To: "Meetings/Review.md", Alias: "review"},
+			wantLine: "[[Review|review]]",
+			wantNum:  14,
+			want:     project + "\n[[Review|review]]\n",
+		},
+		{
+			name:     "section list",
+			opts:     AddLinkOptions{To: "Meetings/Review.md", Location: LinkInList, Heading: "meetings"},
+			wantLine: "- [[Review]]",
+			wantNum:  10,
+			want:     strings.Replace(project, "- [[Kickoff]]\n", "- [[Kickoff]]\n- [[Review]]\n", 1),
+		},
+		{
+			name:     "under heading",
+			opts:     AddLinkOptions{To: "Meetings/Review.md", Location: LinkUnderHeading, Heading: "## Notes"},
+			wantLine: "[[Review]]",
+			wantNum:  14,
+			want:     project + "\n[[Review]]\n",
+		},
+		{
+			name:     "created heading",
+			opts:     AddLinkOptions{To: "Meetings/Review.md", Location: LinkInList, Heading: "Related", CreateHeading: true},
+			wantLine: "- [[Review]]",
+			wantNum:  15,
+			want:     project + "\n## Related\n- [[Review]]\n",
+		},
+		{
+			name:     "name of another note",
+			opts:     AddLinkOptions{To: "Archive/Plan.md", Location: LinkUnderHeading, Heading: "Meetings"},
+			wantLine: "[[Archive/Plan]]",
+			wantNum:  11,
+			want:     strings.Replace(project, "- [[Kickoff]]\n", "- [[Kickoff]]\n\n[[Archive/Plan]]\n", 1),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			writeFiles(t, tmpDir, map[string]string{
+				"Project.md":          project,
+				"Meetings/Kickoff.md": "Kickoff",
+				"Meetings/Review.md":  "Review",
+				"Plan.md":             "Current plan",
+				"Archive/Plan.md":     "Old plan",
+			})
+			v, err := NewVault(tmpDir)
+			if err != nil {
+				t.Fatalf("Failed to create vault: %v", err)
+			}
+
+			tt.opts.From = "Project.md"
+			result, err := v.AddLink(ctx, tt.opts)
+			if err != nil {
+				t.Fatalf("AddLink() error = %v", err)
+			}
+			if result.AlreadyLinked || result.Line != tt.wantLine || result.LineNumber != tt.wantNum {
+				t.Errorf("AddLink() = %+v, want line %d %q", result, tt.wantNum, tt.wantLine)
+			}
+			content, _ := v.Read(ctx, "Project.md")
+			if content != tt.want {
+				t.Errorf("Project.md = %q, want %q", content, tt.want)
+			}
+			if got := strings.Split(content, "\n")[result.LineNumber-1]; got != result.Line {
+				t.Errorf("line %d = %q, want %q", result.LineNumber, got, result.Line)
+			}
+			if result.Revision != contentHash(content) {
+				t.Errorf("Revision = %s, want the hash of the new content", result.Revision)
+			}
+
+			// Adding it again finds the link just written
+			again, err := v.AddLink(ctx, tt.opts)
+			if err != nil {
+				t.Fatalf("AddLink() again error = %v", err)
+			}
+			if !again.AlreadyLinked || again.LineNumber != result.LineNumber {
+				t.Errorf("AddLink() again = %+v, want already linked at line %d", again, result.LineNumber)
+			}
+			if after, _ := v.Read(ctx, "Project.md"); after != content {
+				t.Errorf("AddLink() again changed the note to %q", after)
+			}
+		})
+	}
+}
+
+func TestAddLinkErrors(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"Project.md": "# Project\n\n## Meetings\n- [[Kickoff|first meeting]]\n",
+		"Kickoff.md": "Kickoff",
+	})
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+
+	// A link with other display text already links the note
+	result, err := v.AddLink(ctx, AddLinkOptions{From: "Project.md", To: "Kickoff.md", Location: LinkInList, Heading: "Meetings"})
+	if err != nil || !result.AlreadyLinked || result.LineNumber != 4 || result.Line != "- [[Kickoff|first meeting]]" {
+		t.Errorf("AddLink() = %+v, %v; want already linked at line 4", result, err)
+	}
+
+	_, err = v.AddLink(ctx, AddLinkOptions{From: "Project.md", To: "Kickoff.md", Location: LinkUnderHeading, Heading: "Decisions"})
+	if !errors.Is(err, ErrSectionNotFound) || !strings.Contains(err.Error(), `"Meetings"`) {
+		t.Errorf("AddLink() under a missing heading error = %v, want ErrSectionNotFound listing the headings", err)
+	}
+
+	for _, opts := range []AddLinkOptions{
+		{From: "Project.md", To: "Kickoff.md", Location: "sidebar"},
+		{From: "Project.md", To: "Kickoff.md", Location: LinkInList},
+		{From: "Project.md", To: "Kickoff.md", Heading: "Meetings"},
+		{From: "Project.md", To: "Kickoff.md", Alias: "a]]b"},
+	} {
+		if _, err := v.AddLink(ctx, opts); !errors.Is(err, ErrInvalidLink) {
+			t.Errorf("AddLink(%+v) error = %v, want ErrInvalidLink", opts, err)
+		}
+	}
+
+	if _, err := v.AddLink(ctx, AddLinkOptions{From: "Project.md", To: "Missing.md"}); !errors.Is(err, ErrNoteNotFound) {
+		t.Errorf("AddLink() to a missing note error = %v, want ErrNoteNotFound", err)
+	}
+}
@@ -0,0 +1,310 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// aliasKeys are the frontmatter keys holding aliases, the preferred first
+var aliasKeys = []string{"aliases", "alias"}
+
+// newAliasIndent starts the items of alias lists the server writes, as
+// Obsidian does
+const newAliasIndent = "  - "
+
+// AliasResult reports the aliases of a note after AddAlias or RemoveAlias
+type AliasResult struct {
+	Path     string   `json:"path"`
+	Alias    string   `json:"alias"`    // The alias added or removed
+	Aliases  []string `json:"aliases"`  // Every alias of the note afterwards
+	Changed  bool     `json:"changed"`  // False when the alias was already there; nothing was written
+	Revision string   `json:"revision"` // Content hash of the note afterwards
+}
+
+// AddAlias adds alias to the aliases in the frontmatter of the note at
+// path, creating the field or the whole frontmatter block if needed. Only
+// the lines of the field change: other properties, their order and
+// comments stay as written. An alias the note already has, ignoring
+// case, is reported unchanged and nothing is written.
+func (v *vault) AddAlias(ctx context.Context, path, alias string) (AliasResult, error) {
+	return v.editAliases(ctx, path, alias, true)
+}
+
+// RemoveAlias removes alias, ignoring case, from the aliases in the
+// frontmatter of the note at path, under both aliases and the legacy
+// alias key. A field left empty is removed. An alias the note does not
+// have is ErrAliasNotFound listing the aliases it has.
+func (v *vault) RemoveAlias(ctx context.Context, path, alias string) (AliasResult, error) {
+	return v.editAliases(ctx, path, alias, false)
+}
+
+// editAliases implements AddAlias and RemoveAlias
+func (v *vault) editAliases(ctx context.Context, path, alias string, add bool) (AliasResult, error) {
+	alias = strings.TrimSpace(alias)
+	if alias == "" || strings.ContainsAny(alias, "\r\n") {
+		return AliasResult{}, fmt.Errorf("%w: %q must be a single line of text", ErrInvalidAlias, alias)
+	}
+
+	fullPath, err := v.validatePath(path)
+	if err != nil {
+		return AliasResult{}, err
+	}
+	relPath := v.relPath(fullPath)
+
+	unlock := v.writeLocks.lock(fullPath)
+	defer unlock()
+
+	if _, err := v.checkUpdate(ctx, relPath); err != nil {
+		return AliasResult{}, err
+	}
+	stat, err := os.Stat(fullPath)
+	if err != nil {
+		return AliasResult{}, ErrNoteNotFound
+	}
+	entry, err := v.loadEntry(fullPath, stat.ModTime())
+	if err != nil {
+		return AliasResult{}, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	content, aliases, changed, err := editAliasFields(entry.Content, alias, add)
+	if err != nil {
+		return AliasResult{}, fmt.Errorf("%w: %s: %w", ErrInvalidAlias, relPath, err)
+	}
+	result := AliasResult{Path: relPath, Alias: alias, Aliases: aliases, Changed: changed, Revision: entry.ContentHash}
+	switch {
+	case !changed && add:
+		return result, nil
+	case !changed:
+		return AliasResult{}, fmt.Errorf("%w: %q in %s, %s", ErrAliasNotFound, alias, relPath, aliasList(aliases))
+	}
+
+	updated, err := v.PrepareContent(relPath, content, false)
+	if err != nil {
+		return AliasResult{}, err
+	}
+	result.Revision = contentHash(updated)
+
+	// Check context cancellation before I/O; once writing starts it completes
+	if err := ctx.Err(); err != nil {
+		return AliasResult{}, err
+	}
+	written, err := v.writeNote(fullPath, updated)
+	if err != nil {
+		return AliasResult{}, err
+	}
+	return result, v.record(ctx, written)
+}
+
+// aliasList describes the aliases of a note for an error message
+func aliasList(aliases []string) string {
+	if len(aliases) == 0 {
+		return "which has no aliases"
+	}
+	return "whose aliases are: " + strings.Join(aliases, ", ")
+}
+
+// aliasField is an alias property of a frontmatter block
+type aliasField struct {
+	key, value *yaml.Node
+	items      []*yaml.Node // The aliases, in order
+	start, end int          // Lines of the block the field spans, 0-based, end excluded
+}
+
+// editAliasFields adds alias to the alias fields of the frontmatter of
+// content, or removes it from all of them, returning the new content and
+// the note's aliases afterwards. Only the lines of the fields changed are
+// rewritten. changed is false, and content returned as is, when an alias
+// to add is already there or one to remove is not. Fails when the
+// frontmatter is not a YAML mapping.
+func editAliasFields(content, alias string, add bool) (updated string, aliases []string, changed bool, err error) {
+	b, err := parseFrontmatterBlock(content)
+	if err != nil {
+		return "", nil, false, err
+	}
+	fields := aliasFields(b.mapping, b.lines)
+
+	same := func(n *yaml.Node) bool { return strings.EqualFold(n.Value, alias) }
+	has := slices.ContainsFunc(fields, func(f *aliasField) bool { return slices.ContainsFunc(f.items, same) })
+	if has == add {
+		return content, fieldAliases(fields), false, nil
+	}
+
+	if !add {
+		// Bottom up, so the lines of the fields above stay where they are
+		byLine := slices.SortedFunc(slices.Values(fields), func(a, b *aliasField) int { return b.start - a.start })
+		for _, f := range byLine {
+			if !slices.ContainsFunc(f.items, same) {
+				continue
+			}
+			f.items = slices.DeleteFunc(f.items, same)
+			b.lines = slices.Replace(b.lines, f.start, f.end, f.render(b.lines, f.items)...)
+		}
+		aliases = fieldAliases(fields)
+	} else {
+		item := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: alias}
+		if len(fields) == 0 {
+			b.lines = append(b.lines, aliasKeys[0]+":\n", newAliasIndent+renderScalar(item)+"\n")
+			aliases = []string{alias}
+		} else {
+			f := fields[0]
+			b.lines = slices.Replace(b.lines, f.start, f.end, f.render(b.lines, append(slices.Clone(f.items), item))...)
+			f.items = append(f.items, item)
+			aliases = fieldAliases(fields)
+		}
+	}
+	return b.render(), aliases, true, nil
+}
+
+// aliasFields returns the alias fields of mapping, whose block has lines,
+// aliases first
+func aliasFields(mapping *yaml.Node, lines []string) []*aliasField {
+	if mapping == nil {
+		return nil
+	}
+	var fields []*aliasField
+	for _, key := range aliasKeys {
+		for i := 0; i+1 < len(mapping.Content); i += 2 {
+			if mapping.Content[i].Value != key {
+				continue
+			}
+			f := &aliasField{key: mapping.Content[i], value: mapping.Content[i+1]}
+			switch f.value.Kind {
+			case yaml.ScalarNode:
+				if f.value.Tag != "!!null" {
+					f.items = []*yaml.Node{f.value}
+				}
+			case yaml.SequenceNode:
+				for _, item := range f.value.Content {
+					if item.Kind == yaml.ScalarNode && item.Tag != "!!null" {
+						f.items = append(f.items, item)
+					}
+				}
+			}
+			f.start = f.key.Line - 1
+			f.end = fieldEnd(lines, f.start, f.key.Column-1, f.value.Kind == yaml.SequenceNode && f.value.Style&yaml.FlowStyle == 0)
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// fieldEnd returns the line after the last of the field starting at line
+// start with its key indented by indent: the lines indented further, and
+// for a block sequence the items at the key's indentation too. Blank
+// lines belong to the field only when it goes on after them.
+func fieldEnd(lines []string, start, indent int, sequence bool) int {
+	end := start + 1
+	for i := start + 1; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r\n")
+		trimmed := strings.TrimLeft(line, " \t")
+		switch {
+		case trimmed == "":
+			continue
+		case len(line)-len(trimmed) > indent,
+			sequence && len(line)-len(trimmed) == indent && strings.HasPrefix(trimmed, "-"):
+			end = i + 1
+		default:
+			return end
+		}
+	}
+	return end
+}
+
+// fieldAliases returns the aliases of fields in order
+func fieldAliases(fields []*aliasField) []string {
+	aliases := []string{}
+	for _, f := range fields {
+		for _, item := range f.items {
+			aliases = append(aliases, item.Value)
+		}
+	}
+	return aliases
+}
+
+// render returns the lines of the field holding items, none when there
+// are no items. A block list keeps the lines of the items it held, with
+// their comments; a flow list stays a flow list on one line, and a
+// single alias a plain value. Other values become a block list.
+func (f *aliasField) render(lines []string, items []*yaml.Node) []string {
+	if len(items) == 0 {
+		return nil
+	}
+	keyLine := lines[f.start]
+	prefix := keyLine[:strings.Index(keyLine, ":")+1]
+	comment := func(n *yaml.Node) string {
+		if n.LineComment == "" {
+			return ""
+		}
+		return " " + n.LineComment
+	}
+
+	switch {
+	case f.value.Kind == yaml.SequenceNode && f.value.Style&yaml.FlowStyle != 0:
+		seq := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for _, item := range items {
+			seq.Content = append(seq.Content, bareScalar(item))
+		}
+		out, _ := yaml.Marshal(seq)
+		return []string{prefix + " " + strings.TrimSuffix(string(out), "\n") + comment(f.value) + "\n"}
+	case f.value.Kind == yaml.ScalarNode && f.value.Tag != "!!null" && len(items) == 1:
+		return []string{prefix + " " + renderScalar(items[0]) + comment(f.value) + "\n"}
+	case f.value.Kind != yaml.SequenceNode || len(f.value.Content) == 0:
+		out := []string{prefix + comment(f.key) + comment(f.value) + "\n"}
+		for _, item := range items {
+			out = append(out, newAliasIndent+renderScalar(item)+"\n")
+		}
+		return out
+	}
+
+	// A block list: the lines from the key to the first item, then each
+	// item kept, or any other value, from its head comment to the next
+	// item's, then the items added
+	starts := make([]int, len(f.value.Content))
+	for i, item := range f.value.Content {
+		starts[i] = item.Line - 1
+		if item.HeadComment != "" {
+			starts[i] -= strings.Count(item.HeadComment, "\n") + 1
+		}
+	}
+	first := f.value.Content[0]
+	itemPrefix := lines[first.Line-1][:first.Column-1]
+	out := slices.Clone(lines[f.start:starts[0]])
+	for i, item := range f.value.Content {
+		if item.Kind == yaml.ScalarNode && !slices.Contains(items, item) {
+			continue
+		}
+		end := f.end
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		out = append(out, lines[starts[i]:end]...)
+	}
+	for _, item := range items {
+		if !slices.Contains(f.value.Content, item) {
+			out = append(out, itemPrefix+renderScalar(item)+"\n")
+		}
+	}
+	return out
+}
+
+// bareScalar returns a copy of the scalar n on one line, without its
+// comments
+func bareScalar(n *yaml.Node) *yaml.Node {
+	style := n.Style &^ (yaml.LiteralStyle | yaml.FoldedStyle)
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: n.Tag, Style: style, Value: n.Value}
+}
+
+// renderScalar returns the scalar n as YAML on one line, quoted when it
+// would otherwise read back as something else
+func renderScalar(n *yaml.Node) string {
+	out, err := yaml.Marshal(bareScalar(n))
+	if err != nil {
+		return fmt.Sprintf("%q", n.Value)
+	}
+	return strings.TrimSuffix(string(out), "\n")
+}
@@ -0,0 +1,298 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// gnarlyFrontmatter has a multiline string, nested maps, comments and
+// quoted values around a block list of aliases
+const gnarlyFrontmatter = `---
+# Managed by the sync script
+title: "Plan: Q3" # shown in the graph
+summary: |
+  First line
+    indented line
+
+  after a blank line
+aliases: # how people name it
+  - Q3 # the short one
+  # next: the long one
+  - "Third quarter"
+meta:
+  owner: {name: Ann, team: ops}
+  dates:
+    - 2024-07-01
+tags: [plan, 'q3']
+---
+# Plan
+
+Body with --- a rule
+---
+`
+
+func TestEditAliasFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		alias   string
+		add     bool
+		want    string // Content afterwards, empty when unchanged
+		aliases []string
+	}{
+		{
+			name:    "block list add",
+			content: gnarlyFrontmatter,
+			alias:   "Plan 2024",
+			add:     true,
+			want:    strings.Replace(gnarlyFrontmatter, "  - \"Third quarter\"\n", "  - \"Third quarter\"\n  - Plan 2024\n", 1),
+			aliases: []string{"Q3", "Third quarter", "Plan 2024"},
+		},
+		{
+			name:    "block list remove keeps the other comments",
+			content: gnarlyFrontmatter,
+			alias:   "third QUARTER",
+			want:    strings.Replace(gnarlyFrontmatter, "  # next: the long one\n  - \"Third quarter\"\n", "", 1),
+			aliases: []string{"Q3"},
+		},
+		{
+			name:    "block list remove first",
+			content: gnarlyFrontmatter,
+			alias:   "Q3",
+			want:    strings.Replace(gnarlyFrontmatter, "  - Q3 # the short one\n", "", 1),
+			aliases: []string{"Third quarter"},
+		},
+		{
+			name:    "duplicate add",
+			content: gnarlyFrontmatter,
+			alias:   "q3",
+			add:     true,
+			aliases: []string{"Q3", "Third quarter"},
+		},
+		{
+			name:    "missing remove",
+			content: gnarlyFrontmatter,
+			alias:   "Q4",
+			aliases: []string{"Q3", "Third quarter"},
+		},
+		{
+			name:    "flow list add",
+			content: "---\naliases: [One, \"Two\"] # kept\nstatus: draft\n---\nBody\n",
+			alias:   "Three, Four",
+			add:     true,
+			want:    "---\naliases: [One, \"Two\", 'Three, Four'] # kept\nstatus: draft\n---\nBody\n",
+			aliases: []string{"One", "Two", "Three, Four"},
+		},
+		{
+			name:    "multiline flow list remove",
+			content: "---\naliases: [One,\n  Two]\nstatus: draft\n---\nBody\n",
+			alias:   "one",
+			want:    "---\naliases: [Two]\nstatus: draft\n---\nBody\n",
+			aliases: []string{"Two"},
+		},
+		{
+			name:    "scalar add",
+			content: "---\nstatus: draft\naliases: One # single\n---\nBody\n",
+			alias:   "true",
+			add:     true,
+			want:    "---\nstatus: draft\naliases: # single\n  - One\n  - \"true\"\n---\nBody\n",
+			aliases: []string{"One", "true"},
+		},
+		{
+			name:    "scalar remove drops the field",
+			content: "---\nstatus: draft\naliases: One\nnext: 1\n---\nBody\n",
+			alias:   "One",
+			want:    "---\nstatus: draft\nnext: 1\n---\nBody\n",
+			aliases: []string{},
+		},
+		{
+			name:    "empty field add",
+			content: "---\naliases:\nstatus: draft\n---\nBody\n",
+			alias:   "One",
+			add:     true,
+			want:    "---\naliases:\n  - One\nstatus: draft\n---\nBody\n",
+			aliases: []string{"One"},
+		},
+		{
+			name:    "items at the key's indentation",
+			content: "---\naliases:\n- One\n- Two\nstatus: draft\n---\n",
+			alias:   "Three",
+			add:     true,
+			want:    "---\naliases:\n- One\n- Two\n- Three\nstatus: draft\n---\n",
+			aliases: []string{"One", "Two", "Three"},
+		},
+		{
+			name:    "legacy key remove from both",
+			content: "---\nalias: One\naliases: [one, Two]\n---\n",
+			alias:   "ONE",
+			want:    "---\naliases: [Two]\n---\n",
+			aliases: []string{"Two"},
+		},
+		{
+			name:    "legacy key add",
+			content: "---\nalias:\n  - One\n---\n",
+			alias:   "Two",
+			add:     true,
+			want:    "---\nalias:\n  - One\n  - Two\n---\n",
+			aliases: []string{"One", "Two"},
+		},
+		{
+			name:    "no field",
+			content: "---\n# only a comment\nstatus: draft\n...\nBody\n",
+			alias:   "One",
+			add:     true,
+			want:    "---\n# only a comment\nstatus: draft\naliases:\n  - One\n...\nBody\n",
+			aliases: []string{"One"},
+		},
+		{
+			name:    "empty block",
+			content: "---\n---\nBody\n",
+			alias:   "One",
+			add:     true,
+			want:    "---\naliases:\n  - One\n---\nBody\n",
+			aliases: []string{"One"},
+		},
+		{
+			name:    "no block",
+			content: "# Title\n\n---\nnot: frontmatter\n",
+			alias:   "#hash",
+			add:     true,
+			want:    "---\naliases:\n  - '#hash'\n---\n# Title\n\n---\nnot: frontmatter\n",
+			aliases: []string{"#hash"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, aliases, changed, err := editAliasFields(tt.content, tt.alias, tt.add)
+			if err != nil {
+				t.Fatalf("editAliasFields() error = %v", err)
+			}
+			want := tt.want
+			if want == "" {
+				want = tt.content
+			}
+			if got != want || changed != (tt.want != "") {
+				t.Errorf("editAliasFields() = %v\n%s\nwant %v\n%s", changed, got, tt.want != "", want)
+			}
+			if !reflect.DeepEqual(aliases, tt.aliases) {
+				t.Errorf("aliases = %q, want %q", aliases, tt.aliases)
+			}
+
+			// What is written reads back with the same aliases and every
+			// other property unchanged
+			before, after := parseFrontmatter(tt.content), parseFrontmatter(got)
+			if !reflect.DeepEqual(frontmatterAliases(after), nilIfEmpty(tt.aliases)) {
+				t.Errorf("aliases read back = %q, want %q", frontmatterAliases(after), tt.aliases)
+			}
+			for _, key := range aliasKeys {
+				delete(before, key)
+				delete(after, key)
+			}
+			if len(before) > 0 && !reflect.DeepEqual(after, before) {
+				t.Errorf("properties = %v, want %v", after, before)
+			}
+		})
+	}
+}
+
+func nilIfEmpty(s []string) []string {
+	if len(s) == 0 {
+		return nil
+	}
+	return s
+}
+
+func TestEditAliasFieldsInvalid(t *testing.T) {
+	for _, content := range []string{"---\n- a list\n---\n", "---\nkey: [unclosed\n---\n"} {
+		if _, _, _, err := editAliasFields(content, "One", true); err == nil {
+			t.Errorf("editAliasFields(%q) succeeded, want an error", content)
+		}
+	}
+}
+
+func TestAddRemoveAlias(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"Plan.md":  gnarlyFrontmatter,
+		"Other.md": "# Other",
+		"List.md":  "---\n- a\n---\n",
+	})
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+
+	// Cached before the change, found by the new alias right after
+	if _, err := v.Resolve(ctx, "Roadmap"); !errors.Is(err, ErrNoteNotFound) {
+		t.Fatalf("Resolve() error = %v, want ErrNoteNotFound", err)
+	}
+	result, err := v.AddAlias(ctx, "Plan.md", " Roadmap ")
+	if err != nil {
+		t.Fatalf("AddAlias() error = %v", err)
+	}
+	if !result.Changed || result.Alias != "Roadmap" || !reflect.DeepEqual(result.Aliases, []string{"Q3", "Third quarter", "Roadmap"}) {
+		t.Errorf("AddAlias() = %+v, want Roadmap added", result)
+	}
+	resolution, err := v.Resolve(ctx, "roadmap")
+	if err != nil || resolution.Match == nil || resolution.Match.Path != "Plan.md" || resolution.Match.MatchedBy != MatchAlias {
+		t.Errorf("Resolve() = %+v, %v, want Plan.md by alias", resolution, err)
+	}
+	data, err := os.ReadFile(filepath.Join(tmpDir, "Plan.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := strings.Replace(gnarlyFrontmatter, "  - \"Third quarter\"\n", "  - \"Third quarter\"\n  - Roadmap\n", 1); string(data) != want {
+		t.Errorf("Plan.md =\n%s\nwant\n%s", data, want)
+	}
+	if result.Revision != contentHash(string(data)) {
+		t.Errorf("Revision = %s, want the hash of the note written", result.Revision)
+	}
+
+	// A duplicate is reported, not written
+	again, err := v.AddAlias(ctx, "Plan.md", "ROADMAP")
+	if err != nil || again.Changed || again.Revision != result.Revision {
+		t.Errorf("second AddAlias() = %+v, %v, want unchanged", again, err)
+	}
+
+	// Removing an alias the note lacks lists those it has
+	_, err = v.RemoveAlias(ctx, "Plan.md", "Q4")
+	if !errors.Is(err, ErrAliasNotFound) || !strings.Contains(err.Error(), "Q3, Third quarter, Roadmap") {
+		t.Errorf("RemoveAlias() error = %v, want ErrAliasNotFound listing the aliases", err)
+	}
+	if _, err := v.RemoveAlias(ctx, "Other.md", "Q4"); err == nil || !strings.Contains(err.Error(), "no aliases") {
+		t.Errorf("RemoveAlias() error = %v, want no aliases", err)
+	}
+
+	result, err = v.RemoveAlias(ctx, "Plan.md", "roadmap")
+	if err != nil || !result.Changed || !reflect.DeepEqual(result.Aliases, []string{"Q3", "Third quarter"}) {
+		t.Errorf("RemoveAlias() = %+v, %v, want Roadmap removed", result, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(tmpDir, "Plan.md")); string(data) != gnarlyFrontmatter {
+		t.Errorf("Plan.md =\n%s\nwant it as before", data)
+	}
+	if _, err := v.Resolve(ctx, "Roadmap"); !errors.Is(err, ErrNoteNotFound) {
+		t.Errorf("Resolve() error = %v, want ErrNoteNotFound", err)
+	}
+
+	for _, tt := range []struct {
+		path, alias string
+		want        error
+	}{
+		{"Plan.md", "  ", ErrInvalidAlias},
+		{"Plan.md", "two\nlines", ErrInvalidAlias},
+		{"List.md", "One", ErrInvalidAlias},
+		{"Missing.md", "One", ErrNoteNotFound},
+		{"../outside.md", "One", ErrPathTraversal},
+	} {
+		if _, err := v.AddAlias(ctx, tt.path, tt.alias); !errors.Is(err, tt.want) {
+			t.Errorf("AddAlias(%q, %q) error = %v, want %v", tt.path, tt.alias, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,389 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Heading is a markdown heading in a note's outline
+type Heading struct {
+	Level int    `json:"level"` // 1 for #, 6 for ######
+	Text  string `json:"text"`
+	Line  int    `json:"line"`  // 1-based line number
+	Words int    `json:"words"` // Words in its section, subsections included, headings not
+}
+
+// Task is a checkbox list item such as "- [ ] call Bob"
+type Task struct {
+	Text    string   `json:"text"`              // Item text after the checkbox
+	Done    bool     `json:"done"`              // Checked with [x] or [X]
+	Heading string   `json:"heading,omitempty"` // Nearest heading above the task
+	Line    int      `json:"line"`              // 1-based line number
+	Depth   int      `json:"depth"`             // List nesting level, 0 for top-level items
+	Tags    []string `json:"tags"`              // Tags in the task text
+}
+
+// NoteAnalysis summarizes the structure of a note
+type NoteAnalysis struct {
+	Path        string    `json:"path"`
+	ContentHash string    `json:"content_hash"` // Hex SHA-256 of the note's text
+	WordCount   int       `json:"word_count"`   // Words in the body, frontmatter excluded
+	Headings    []Heading `json:"headings"`
+	Tasks       []Task    `json:"tasks"`
+	Blocks      []Block   `json:"blocks"`             // Blocks marked with ^block-id
+	Callouts    []Callout `json:"callouts"`           // Callouts, nested ones after the one holding them
+	Warnings    []string  `json:"warnings,omitempty"` // E.g. duplicate block IDs
+}
+
+// TaskStatus selects tasks by their checked state
+type TaskStatus string
+
+// Task status filters
+const (
+	TaskStatusAll  TaskStatus = "all"
+	TaskStatusOpen TaskStatus = "open"
+	TaskStatusDone TaskStatus = "done"
+)
+
+// TaskOptions selects the tasks returned by FindTasks
+type TaskOptions struct {
+	Subpath       string     // Directory to scan, empty for the whole vault
+	Status        TaskStatus // Checked state, empty for all
+	Tag           string     // Only tasks whose text contains this tag
+	IncludeHidden bool       // Scan files and directories whose name starts with a dot
+	Type          string     // Only notes of this type, see WithNoteTypes
+}
+
+// NoteTasks groups the tasks found in one note
+type NoteTasks struct {
+	Path  string `json:"path"`
+	Tasks []Task `json:"tasks"`
+}
+
+var (
+	// headingRegex matches ATX headings, dropping optional closing hashes
+	headingRegex = regexp.MustCompile(`^(#{1,6})\s+(.*?)(?:\s+#+)?\s*$`)
+
+	// setextRegex matches the === or --- line underlining a setext heading
+	setextRegex = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+
+	// listItemRegex matches bullet and numbered list items
+	listItemRegex = regexp.MustCompile(`^([ \t]*)(?:[-*+]|\d+[.)])\s+(.*)$`)
+
+	// taskRegex matches a checkbox at the start of a list item's text
+	taskRegex = regexp.MustCompile(`^\[([ xX])\](?:\s+(.*))?$`)
+)
+
+// markdownLines calls fn for each line of content after the frontmatter
+// with its 1-based line number. code is set for fenced code blocks and
+// their delimiters.
+func markdownLines(content string, fn func(line string, lineNum int, code bool)) {
+	lines := strings.Split(content, "\n")
+
+	// Skip frontmatter so YAML comments are not read as headings
+	start := 0
+	if frontmatter, _, ok := SplitFrontmatter(content); ok {
+		start = strings.Count(frontmatter, "\n") + 2
+	}
+
+	inFence := false
+	for i := start; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r")
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			fn(line, i+1, true)
+			continue
+		}
+		fn(line, i+1, inFence)
+	}
+}
+
+// ParseHeadings returns the heading outline of markdown content, with
+// the words in each heading's section
+// Both ATX (# Title) and setext (Title over === or ---) headings are
+// recognized; those inside code blocks and frontmatter are ignored
+func ParseHeadings(content string) []Heading {
+	headings := []Heading{}
+	words := make(map[int]int) // Words by line number, headings excluded
+
+	// Paragraph lines a setext underline would turn into a heading
+	var paragraph []string
+	paragraphStart := 0
+
+	markdownLines(content, func(line string, lineNum int, code bool) {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case code:
+			paragraph = nil
+			words[lineNum] = len(strings.Fields(line))
+			return
+		case len(paragraph) > 0 && setextRegex.MatchString(line):
+			level := 1
+			if strings.HasPrefix(trimmed, "-") {
+				level = 2
+			}
+			headings = append(headings, Heading{Level: level, Text: strings.Join(paragraph, " "), Line: paragraphStart})
+			for n := paragraphStart; n < lineNum; n++ {
+				delete(words, n)
+			}
+			paragraph = nil
+			return
+		}
+
+		if m := headingRegex.FindStringSubmatch(line); m != nil && m[2] != "" {
+			headings = append(headings, Heading{Level: len(m[1]), Text: m[2], Line: lineNum})
+			paragraph = nil
+			return
+		}
+		words[lineNum] = len(strings.Fields(line))
+
+		// Only plain text can be underlined; lists, quotes, indented code
+		// and thematic breaks end the paragraph
+		if trimmed == "" || indentWidth(leadingSpace(line)) >= 4 || listItemRegex.MatchString(line) ||
+			strings.HasPrefix(trimmed, ">") || setextRegex.MatchString(line) {
+			paragraph = nil
+			return
+		}
+		if len(paragraph) == 0 {
+			paragraphStart = lineNum
+		}
+		paragraph = append(paragraph, trimmed)
+	})
+
+	// A section runs to the next heading of the same or a higher level
+	for i := range headings {
+		end := math.MaxInt
+		for _, next := range headings[i+1:] {
+			if next.Level <= headings[i].Level {
+				end = next.Line
+				break
+			}
+		}
+		for lineNum, n := range words {
+			if lineNum > headings[i].Line && lineNum < end {
+				headings[i].Words += n
+			}
+		}
+	}
+	return headings
+}
+
+// ParseTasks extracts checkbox list items from markdown content
+// Items inside code blocks are ignored; nesting depth follows the
+// indentation of enclosing list items. Items inside callouts are found
+// with their depth counted within the callout.
+func ParseTasks(content string) []Task {
+	tasks := []Task{}
+	heading := ""
+	var indents []int // Indentation of the enclosing list items
+	callouts, inside := scanCallouts(content)
+	var outer []int // Indents of the list a callout interrupts
+	inCallout := false
+
+	markdownLines(content, func(line string, lineNum int, code bool) {
+		// A callout holds lists of its own and resumes the list around it
+		// when it ends; headings in callouts are not section headings
+		c, ok := inside[lineNum]
+		opener := !ok && isCalloutOpener(callouts, lineNum)
+		switch {
+		case opener || ok && !inCallout:
+			if !inCallout {
+				outer = slices.Clone(indents)
+			}
+			indents = indents[:0]
+		case !ok && inCallout:
+			indents = outer
+		}
+		inCallout = ok || opener
+		if opener || ok && c.code {
+			return
+		}
+		if ok {
+			line = c.text
+		}
+
+		if code {
+			// An unindented code block ends the list
+			if line != "" && line[0] != ' ' && line[0] != '\t' {
+				indents = indents[:0]
+			}
+			return
+		}
+
+		if m := headingRegex.FindStringSubmatch(line); m != nil && !ok {
+			heading = m[2]
+			indents = indents[:0]
+			return
+		}
+
+		m := listItemRegex.FindStringSubmatch(line)
+		if m == nil {
+			// Unindented text ends the list; blank and continuation lines do not
+			if line != "" && line[0] != ' ' && line[0] != '\t' {
+				indents = indents[:0]
+			}
+			return
+		}
+
+		indent := indentWidth(m[1])
+		for len(indents) > 0 && indents[len(indents)-1] >= indent {
+			indents = indents[:len(indents)-1]
+		}
+		depth := len(indents)
+		indents = append(indents, indent)
+
+		t := taskRegex.FindStringSubmatch(m[2])
+		if t == nil {
+			return
+		}
+
+		text := strings.TrimSpace(t[2])
+		tasks = append(tasks, Task{
+			Text:    text,
+			Done:    t[1] != " ",
+			Heading: heading,
+			Line:    lineNum,
+			Depth:   depth,
+			Tags:    ExtractTags(text),
+		})
+	})
+
+	return tasks
+}
+
+// indentWidth measures leading whitespace, counting a tab as four spaces
+func indentWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		if r == '\t' {
+			width += 4
+		} else {
+			width++
+		}
+	}
+	return width
+}
+
+// CountWords counts whitespace-separated words in the note body
+func CountWords(content string) int {
+	if _, body, ok := SplitFrontmatter(content); ok {
+		content = body
+	}
+	return len(strings.Fields(content))
+}
+
+// matches reports whether a task passes the status and tag filters
+func (o TaskOptions) matches(task Task) bool {
+	switch o.Status {
+	case TaskStatusOpen:
+		if task.Done {
+			return false
+		}
+	case TaskStatusDone:
+		if !task.Done {
+			return false
+		}
+	}
+
+	if o.Tag == "" {
+		return true
+	}
+	tag := foldTag(o.Tag)
+	for _, t := range task.Tags {
+		if foldTag(t) == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTaskStatus validates a task status filter, defaulting to all
+func ParseTaskStatus(s string) (TaskStatus, error) {
+	switch status := TaskStatus(strings.ToLower(strings.TrimSpace(s))); status {
+	case "", TaskStatusAll:
+		return TaskStatusAll, nil
+	case TaskStatusOpen, TaskStatusDone:
+		return status, nil
+	default:
+		return "", fmt.Errorf("unknown task status %q (want open, done or all)", s)
+	}
+}
+
+// Analyze returns the word count, heading outline, tasks, blocks and
+// callouts of a note
+func (v *vault) Analyze(ctx context.Context, path string) (NoteAnalysis, error) {
+	fullPath, err := v.validatePath(path)
+	if err != nil {
+		return NoteAnalysis{}, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return NoteAnalysis{}, err
+	}
+
+	stat, err := os.Stat(fullPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NoteAnalysis{}, ErrNoteNotFound
+		}
+		return NoteAnalysis{}, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	entry, err := v.loadEntry(fullPath, stat.ModTime())
+	if err != nil {
+		return NoteAnalysis{}, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return NoteAnalysis{
+		Path:        v.relPath(fullPath),
+		ContentHash: entry.ContentHash,
+		WordCount:   CountWords(entry.Content),
+		Headings:    entry.Headings,
+		Tasks:       entry.Tasks,
+		Blocks:      entry.Blocks,
+		Callouts:    ParseCallouts(entry.Content),
+		Warnings:    duplicateBlockWarnings(entry.Blocks),
+	}, nil
+}
+
+// FindTasks collects the tasks selected by opts in a single walk, grouped
+// by note in walk order. Notes without matching tasks are omitted.
+func (v *vault) FindTasks(ctx context.Context, opts TaskOptions) ([]NoteTasks, error) {
+	var mu sync.Mutex
+	found := make(map[string][]Task)
+
+	scope := ListOptions{Subpath: opts.Subpath, Recursive: true, IncludeHidden: opts.IncludeHidden, Type: opts.Type}
+	notes, err := v.walkNotes(ctx, scope, func(file noteFile, entry CacheEntry) bool {
+		var tasks []Task
+		for _, task := range entry.Tasks {
+			if opts.matches(task) {
+				tasks = append(tasks, task)
+			}
+		}
+		if len(tasks) == 0 {
+			return false
+		}
+
+		mu.Lock()
+		found[file.relPath] = tasks
+		mu.Unlock()
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]NoteTasks, len(notes))
+	for i, note := range notes {
+		results[i] = NoteTasks{Path: note.Path, Tasks: found[note.Path]}
+	}
+
+	return results, nil
+}
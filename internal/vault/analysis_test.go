@@ -0,0 +1,183 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const taskNote = `---
+title: Plan
+# not a heading
+---
+# Project
+
+Intro text with some words.
+
+- [ ] top level #work
+  - [x] nested done
+    - [X] deeper done #work
+- regular item
+  - [ ] under a plain item
+
+## Later ##
+
+1. [ ] numbered task
+* [x] star bullet
+
+` + "```" + `
+- [ ] inside code
+# not a heading either
+` + "```" + `
+
+	- [ ] tab indented
+- [ ]
+- [-] cancelled is not a task
+`
+
+func TestParseTasks(t *testing.T) {
+	tasks := ParseTasks(taskNote)
+
+	want := []Task{
+		{Text: "top level #work", Done: false, Heading: "Project", Line: 9, Depth: 0, Tags: []string{"work"}},
+		{Text: "nested done", Done: true, Heading: "Project", Line: 10, Depth: 1},
+		{Text: "deeper done #work", Done: true, Heading: "Project", Line: 11, Depth: 2, Tags: []string{"work"}},
+		{Text: "under a plain item", Done: false, Heading: "Project", Line: 13, Depth: 1},
+		{Text: "numbered task", Done: false, Heading: "Later", Line: 17, Depth: 0},
+		{Text: "star bullet", Done: true, Heading: "Later", Line: 18, Depth: 0},
+		{Text: "tab indented", Done: false, Heading: "Later", Line: 25, Depth: 0},
+		{Text: "", Done: false, Heading: "Later", Line: 26, Depth: 0},
+	}
+
+	if len(tasks) != len(want) {
+		t.Fatalf("Got %d tasks, want %d: %+v", len(tasks), len(want), tasks)
+	}
+	for i, w := range want {
+		got := tasks[i]
+		if got.Text != w.Text || got.Done != w.Done || got.Heading != w.Heading || got.Line != w.Line || got.Depth != w.Depth {
+			t.Errorf("tasks[%d] = %+v, want %+v", i, got, w)
+		}
+		if len(got.Tags) != len(w.Tags) {
+			t.Errorf("tasks[%d].Tags = %v, want %v", i, got.Tags, w.Tags)
+		}
+	}
+}
+
+func TestParseHeadings(t *testing.T) {
+	headings := ParseHeadings(taskNote)
+
+	want := []Heading{
+		{Level: 1, Text: "Project", Line: 5, Words: 66},
+		{Level: 2, Text: "Later", Line: 15, Words: 36},
+	}
+	if len(headings) != len(want) {
+		t.Fatalf("Got %+v, want %+v", headings, want)
+	}
+	for i := range want {
+		if headings[i] != want[i] {
+			t.Errorf("headings[%d] = %+v, want %+v", i, headings[i], want[i])
+		}
+	}
+}
+
+func TestParseSetextHeadings(t *testing.T) {
+	content := "Title\n===\n\nIntro words here\n\nTwo line\nheading\n---\nBody\n\n- list item\n---\n\n---\n\n" +
+		"```\nnot a heading\n---\n```\n\n    indented\n    ---\n\n> quote\n---\n"
+
+	want := []Heading{
+		{Level: 1, Text: "Title", Line: 1, Words: 20},
+		{Level: 2, Text: "Two line heading", Line: 6, Words: 17},
+	}
+	headings := ParseHeadings(content)
+	if !reflect.DeepEqual(headings, want) {
+		t.Errorf("ParseHeadings() = %+v, want %+v", headings, want)
+	}
+}
+
+func TestCountWords(t *testing.T) {
+	tests := []struct {
+		content string
+		want    int
+	}{
+		{"", 0},
+		{"one two  three\nfour", 4},
+		{"---\ntitle: Ignored words here\n---\nBody only", 2},
+	}
+
+	for _, tt := range tests {
+		if got := CountWords(tt.content); got != tt.want {
+			t.Errorf("CountWords(%q) = %d, want %d", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	v, tmpDir := setupTestVault(t)
+	ctx := context.Background()
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "tasks.md"), []byte(taskNote), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	analysis, err := v.Analyze(ctx, "tasks.md")
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+	if len(analysis.Headings) != 2 || len(analysis.Tasks) != 8 || analysis.WordCount == 0 {
+		t.Errorf("Analyze() = %+v", analysis)
+	}
+
+	if _, err := v.Analyze(ctx, "missing.md"); !errors.Is(err, ErrNoteNotFound) {
+		t.Errorf("Expected ErrNoteNotFound, got %v", err)
+	}
+}
+
+func TestFindTasks(t *testing.T) {
+	v, tmpDir := setupTestVault(t)
+	ctx := context.Background()
+
+	notes := map[string]string{
+		"tasks.md":         taskNote,
+		"subdir/todo.md":   "- [ ] buy milk #home\n- [x] pay rent #home",
+		"other/nothing.md": "No tasks here",
+	}
+	for path, content := range notes {
+		if err := os.WriteFile(filepath.Join(tmpDir, path), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		opts      TaskOptions
+		wantNotes int
+		wantTasks int
+	}{
+		{"all tasks", TaskOptions{}, 2, 10},
+		{"open tasks", TaskOptions{Status: TaskStatusOpen}, 2, 6},
+		{"done tasks", TaskOptions{Status: TaskStatusDone}, 2, 4},
+		{"by tag", TaskOptions{Tag: "#work"}, 1, 2},
+		{"open by tag", TaskOptions{Tag: "home", Status: TaskStatusOpen}, 1, 1},
+		{"in subpath", TaskOptions{Subpath: "subdir"}, 1, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := v.FindTasks(ctx, tt.opts)
+			if err != nil {
+				t.Fatalf("FindTasks() error = %v", err)
+			}
+
+			total := 0
+			for _, r := range results {
+				total += len(r.Tasks)
+			}
+			if len(results) != tt.wantNotes || total != tt.wantTasks {
+				t.Errorf("Got %d notes with %d tasks, want %d and %d", len(results), total, tt.wantNotes, tt.wantTasks)
+			}
+		})
+	}
+}
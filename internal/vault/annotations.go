@@ -0,0 +1,294 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// annotationsFile stores every note's annotations, below the data directory
+const annotationsFile = "annotations.json"
+
+// MaxAnnotationSize is the largest annotation value accepted, in bytes
+const MaxAnnotationSize = 16 << 10
+
+// annotationKeyRegex matches valid annotation keys such as "summary" or
+// "rag.embedding-id"
+var annotationKeyRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
+// Annotation is a value stored alongside a note without changing it
+type Annotation struct {
+	Value       string    `json:"value"`
+	Updated     time.Time `json:"updated"`
+	ContentHash string    `json:"content_hash"` // Hash of the note when the value was set
+	Stale       bool      `json:"stale"`        // The note has changed since
+}
+
+// storedAnnotation is an annotation as kept in annotationsFile
+type storedAnnotation struct {
+	Value       string    `json:"value"`
+	Updated     time.Time `json:"updated"`
+	ContentHash string    `json:"content_hash"`
+}
+
+// annotationData is the content of annotationsFile
+type annotationData struct {
+	Notes map[string]map[string]storedAnnotation `json:"notes"` // path -> key -> annotation
+}
+
+// annotationStore keeps the annotations file in memory, reloading it when
+// it changes on disk, and replaces it atomically on every write
+// The zero value with file set is ready to use
+type annotationStore struct {
+	mu    sync.Mutex
+	file  string
+	notes map[string]map[string]storedAnnotation // nil until loaded
+	mtime time.Time                              // Of the file when loaded
+}
+
+// load reads the file unless the copy in memory is current
+// Caller must hold s.mu
+func (s *annotationStore) load() error {
+	stat, err := os.Stat(s.file)
+	if os.IsNotExist(err) {
+		if s.notes == nil {
+			s.notes = make(map[string]map[string]storedAnnotation)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat annotations: %w", err)
+	}
+	if s.notes != nil && stat.ModTime().Equal(s.mtime) {
+		return nil
+	}
+
+	raw, err := os.ReadFile(s.file)
+	if err != nil {
+		return fmt.Errorf("failed to read annotations: %w", err)
+	}
+	var data annotationData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", annotationsFile, err)
+	}
+	if data.Notes == nil {
+		data.Notes = make(map[string]map[string]storedAnnotation)
+	}
+	s.notes, s.mtime = data.Notes, stat.ModTime()
+	return nil
+}
+
+// save writes the annotations with writeDataFile
+// Caller must hold s.mu
+func (s *annotationStore) save() error {
+	raw, err := json.MarshalIndent(annotationData{Notes: s.notes}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode annotations: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.file), 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	if err := writeDataFile(s.file, raw); err != nil {
+		return fmt.Errorf("failed to write annotations: %w", err)
+	}
+
+	if stat, err := os.Stat(s.file); err == nil {
+		s.mtime = stat.ModTime()
+	}
+	return nil
+}
+
+// get returns a copy of the annotations of each note in paths that has any
+func (s *annotationStore) get(paths ...string) (map[string]map[string]storedAnnotation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	found := make(map[string]map[string]storedAnnotation)
+	for _, path := range paths {
+		if annotations, ok := s.notes[path]; ok {
+			found[path] = maps.Clone(annotations)
+		}
+	}
+	return found, nil
+}
+
+// update applies fn to the annotations and saves them when fn reports a
+// change
+func (s *annotationStore) update(fn func(notes map[string]map[string]storedAnnotation) bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return err
+	}
+	if !fn(s.notes) {
+		return nil
+	}
+	if err := s.save(); err != nil {
+		// Drop the unsaved change; the next call reloads the file
+		s.notes = nil
+		return err
+	}
+	return nil
+}
+
+// rename moves the annotations of notes under folder from to to
+func (s *annotationStore) rename(from, to string) error {
+	return s.update(func(notes map[string]map[string]storedAnnotation) bool {
+		moved := make(map[string]map[string]storedAnnotation)
+		for path, annotations := range notes {
+			if newPath, ok := movedPath(path, from, to); ok {
+				delete(notes, path)
+				moved[newPath] = annotations
+			}
+		}
+		maps.Copy(notes, moved)
+		return len(moved) > 0
+	})
+}
+
+// remove drops the annotations of the note at path
+func (s *annotationStore) remove(path string) error {
+	return s.update(func(notes map[string]map[string]storedAnnotation) bool {
+		if _, ok := notes[path]; !ok {
+			return false
+		}
+		delete(notes, path)
+		return true
+	})
+}
+
+// annotationEntry loads the note at path for annotating
+func (v *vault) annotationEntry(ctx context.Context, path string) (string, CacheEntry, error) {
+	fullPath, err := v.validatePath(path)
+	if err != nil {
+		return "", CacheEntry{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return "", CacheEntry{}, err
+	}
+
+	stat, err := os.Stat(fullPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", CacheEntry{}, ErrNoteNotFound
+		}
+		return "", CacheEntry{}, fmt.Errorf("failed to stat file: %w", err)
+	}
+	entry, err := v.loadEntry(fullPath, stat.ModTime())
+	if err != nil {
+		return "", CacheEntry{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	return v.relPath(fullPath), entry, nil
+}
+
+// SetAnnotation stores value under key for the note at path, recording the
+// note's content hash so later reads can tell whether it went stale. An
+// empty value removes the key. The note itself is not modified.
+func (v *vault) SetAnnotation(ctx context.Context, path, key, value string) error {
+	if !annotationKeyRegex.MatchString(key) {
+		return fmt.Errorf("%w: key %q must be 1 to 64 letters, digits, '.', '_' or '-'", ErrInvalidAnnotation, key)
+	}
+	if len(value) > MaxAnnotationSize {
+		return fmt.Errorf("%w: value is %d bytes, at most %d allowed", ErrInvalidAnnotation, len(value), MaxAnnotationSize)
+	}
+
+	relPath, entry, err := v.annotationEntry(ctx, path)
+	if err != nil {
+		return err
+	}
+
+	return v.annotations.update(func(notes map[string]map[string]storedAnnotation) bool {
+		annotations := notes[relPath]
+		if value == "" {
+			if _, ok := annotations[key]; !ok {
+				return false
+			}
+			delete(annotations, key)
+			if len(annotations) == 0 {
+				delete(notes, relPath)
+			}
+			return true
+		}
+
+		if annotations == nil {
+			annotations = make(map[string]storedAnnotation)
+			notes[relPath] = annotations
+		}
+		annotations[key] = storedAnnotation{Value: value, Updated: time.Now().UTC(), ContentHash: entry.ContentHash}
+		return true
+	})
+}
+
+// GetAnnotations returns the annotations of the note at path by key,
+// marking those set before the note last changed as stale
+func (v *vault) GetAnnotations(ctx context.Context, path string) (map[string]Annotation, error) {
+	relPath, entry, err := v.annotationEntry(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := v.annotations.get(relPath)
+	if err != nil {
+		return nil, err
+	}
+	return annotationsFor(stored[relPath], entry.ContentHash), nil
+}
+
+// annotationsFor marks the stored annotations of a note whose content now
+// hashes to hash
+func annotationsFor(stored map[string]storedAnnotation, hash string) map[string]Annotation {
+	annotations := make(map[string]Annotation, len(stored))
+	for key, a := range stored {
+		annotations[key] = Annotation{
+			Value:       a.Value,
+			Updated:     a.Updated,
+			ContentHash: a.ContentHash,
+			Stale:       a.ContentHash != hash,
+		}
+	}
+	return annotations
+}
+
+// annotateNotes adds their annotations to the listed notes
+func (v *vault) annotateNotes(notes []NoteInfo) {
+	paths := make([]string, len(notes))
+	for i, note := range notes {
+		paths[i] = note.Path
+	}
+	stored, err := v.annotations.get(paths...)
+	if err != nil {
+		v.logger.Warn("reading annotations failed", "error", err)
+		return
+	}
+	for i, note := range notes {
+		if annotations, ok := stored[note.Path]; ok {
+			notes[i].Annotations = annotationsFor(annotations, note.ContentHash)
+		}
+	}
+}
+
+// moveAnnotations re-keys annotations after a rename, logging failures:
+// the move itself has already happened
+func (v *vault) moveAnnotations(from, to string) {
+	if err := v.annotations.rename(from, to); err != nil {
+		v.logger.Warn("moving annotations failed", "path", from, "new_path", to, "error", err)
+	}
+}
+
+// dropAnnotations removes the annotations of a note that no longer exists
+func (v *vault) dropAnnotations(path string) {
+	if err := v.annotations.remove(path); err != nil {
+		v.logger.Warn("removing annotations failed", "path", path, "error", err)
+	}
+}
@@ -0,0 +1,210 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestAnnotations(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("set and get", func(t *testing.T) {
+		v, _ := setupTestVault(t)
+
+		if err := v.SetAnnotation(ctx, "subdir/note3.md", "summary", "Note three"); err != nil {
+			t.Fatalf("SetAnnotation failed: %v", err)
+		}
+		annotations, err := v.GetAnnotations(ctx, "subdir/note3.md")
+		if err != nil {
+			t.Fatalf("GetAnnotations failed: %v", err)
+		}
+		got, ok := annotations["summary"]
+		if !ok || got.Value != "Note three" || got.Stale || got.ContentHash == "" || got.Updated.IsZero() {
+			t.Errorf("Expected a fresh summary annotation, got %+v", annotations)
+		}
+
+		// Notes without annotations return an empty map
+		annotations, err = v.GetAnnotations(ctx, "note1.md")
+		if err != nil || len(annotations) != 0 {
+			t.Errorf("Expected no annotations, got %v, %v", annotations, err)
+		}
+	})
+
+	t.Run("stale after update", func(t *testing.T) {
+		v, _ := setupTestVault(t)
+
+		if err := v.SetAnnotation(ctx, "note1.md", "summary", "Note one"); err != nil {
+			t.Fatalf("SetAnnotation failed: %v", err)
+		}
+		if err := v.Update(ctx, "note1.md", "Rewritten"); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		annotations, err := v.GetAnnotations(ctx, "note1.md")
+		if err != nil {
+			t.Fatalf("GetAnnotations failed: %v", err)
+		}
+		if !annotations["summary"].Stale {
+			t.Errorf("Expected the summary to be stale after an update, got %+v", annotations["summary"])
+		}
+
+		// Setting it again records the new hash
+		if err := v.SetAnnotation(ctx, "note1.md", "summary", "Rewritten note"); err != nil {
+			t.Fatalf("SetAnnotation failed: %v", err)
+		}
+		annotations, _ = v.GetAnnotations(ctx, "note1.md")
+		if annotations["summary"].Stale {
+			t.Errorf("Expected the summary to be fresh after setting it again")
+		}
+	})
+
+	t.Run("empty value removes", func(t *testing.T) {
+		v, _ := setupTestVault(t)
+
+		for key, value := range map[string]string{"summary": "One", "topic": "Tags"} {
+			if err := v.SetAnnotation(ctx, "note1.md", key, value); err != nil {
+				t.Fatalf("SetAnnotation failed: %v", err)
+			}
+		}
+		if err := v.SetAnnotation(ctx, "note1.md", "summary", ""); err != nil {
+			t.Fatalf("SetAnnotation failed: %v", err)
+		}
+		annotations, _ := v.GetAnnotations(ctx, "note1.md")
+		if _, ok := annotations["summary"]; ok || len(annotations) != 1 {
+			t.Errorf("Expected only the topic annotation, got %v", annotations)
+		}
+
+		// Removing a missing key is not an error
+		if err := v.SetAnnotation(ctx, "note1.md", "missing", ""); err != nil {
+			t.Errorf("Removing a missing key failed: %v", err)
+		}
+	})
+
+	t.Run("persists across vaults", func(t *testing.T) {
+		v, tmpDir := setupTestVault(t)
+
+		if err := v.SetAnnotation(ctx, "note2.md", "summary", "Note two"); err != nil {
+			t.Fatalf("SetAnnotation failed: %v", err)
+		}
+		reopened, err := NewVault(tmpDir)
+		if err != nil {
+			t.Fatalf("Failed to create vault: %v", err)
+		}
+		annotations, err := reopened.GetAnnotations(ctx, "note2.md")
+		if err != nil || annotations["summary"].Value != "Note two" {
+			t.Errorf("Expected the summary in a new vault, got %v, %v", annotations, err)
+		}
+
+		// The first vault sees writes made through the second
+		if err := reopened.SetAnnotation(ctx, "note2.md", "summary", "Updated"); err != nil {
+			t.Fatalf("SetAnnotation failed: %v", err)
+		}
+		annotations, _ = v.GetAnnotations(ctx, "note2.md")
+		if annotations["summary"].Value != "Updated" {
+			t.Errorf("Expected the updated summary, got %v", annotations)
+		}
+	})
+
+	t.Run("list and search", func(t *testing.T) {
+		v, _ := setupTestVault(t)
+
+		if err := v.SetAnnotation(ctx, "subdir/note3.md", "summary", "Note three"); err != nil {
+			t.Fatalf("SetAnnotation failed: %v", err)
+		}
+
+		notes, err := v.List(ctx, ListOptions{Recursive: true, IncludeAnnotations: true})
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		for _, note := range notes {
+			want := note.Path == "subdir/note3.md"
+			if got := note.Annotations["summary"].Value == "Note three"; got != want {
+				t.Errorf("Note %s has annotations %v", note.Path, note.Annotations)
+			}
+		}
+
+		notes, err = v.List(ctx, ListOptions{Recursive: true})
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		for _, note := range notes {
+			if note.Annotations != nil {
+				t.Errorf("Expected no annotations without IncludeAnnotations, got %v", note.Annotations)
+			}
+		}
+
+		results, err := v.Search(ctx, SearchOptions{Query: "note 3", IncludeAnnotations: true})
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if len(results) != 1 || results[0].Annotations["summary"].Value != "Note three" {
+			t.Errorf("Expected the annotated note in search results, got %+v", results)
+		}
+	})
+
+	t.Run("follow folder renames", func(t *testing.T) {
+		v, _ := setupTestVault(t)
+
+		if err := v.SetAnnotation(ctx, "subdir/deep/note4.md", "summary", "Deep"); err != nil {
+			t.Fatalf("SetAnnotation failed: %v", err)
+		}
+		if _, err := v.RenameFolder(ctx, RenameFolderOptions{Path: "subdir", NewPath: "moved"}); err != nil {
+			t.Fatalf("RenameFolder failed: %v", err)
+		}
+		annotations, err := v.GetAnnotations(ctx, "moved/deep/note4.md")
+		if err != nil || annotations["summary"].Value != "Deep" {
+			t.Errorf("Expected the annotation at the new path, got %v, %v", annotations, err)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		v, _ := setupTestVault(t)
+
+		tests := []struct {
+			name  string
+			path  string
+			key   string
+			value string
+			want  error
+		}{
+			{"empty key", "note1.md", "", "x", ErrInvalidAnnotation},
+			{"key with slash", "note1.md", "a/b", "x", ErrInvalidAnnotation},
+			{"long key", "note1.md", strings.Repeat("k", 65), "x", ErrInvalidAnnotation},
+			{"large value", "note1.md", "summary", strings.Repeat("x", MaxAnnotationSize+1), ErrInvalidAnnotation},
+			{"missing note", "missing.md", "summary", "x", ErrNoteNotFound},
+			{"not markdown", "readme.txt", "summary", "x", ErrNotMarkdown},
+			{"traversal", "../outside.md", "summary", "x", ErrPathTraversal},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if err := v.SetAnnotation(ctx, tt.path, tt.key, tt.value); !errors.Is(err, tt.want) {
+					t.Errorf("Expected %v, got %v", tt.want, err)
+				}
+			})
+		}
+	})
+
+	t.Run("concurrent writes", func(t *testing.T) {
+		v, _ := setupTestVault(t)
+
+		var wg sync.WaitGroup
+		for i := range 20 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := v.SetAnnotation(ctx, "note1.md", fmt.Sprintf("key%d", i), "value"); err != nil {
+					t.Errorf("SetAnnotation failed: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		annotations, err := v.GetAnnotations(ctx, "note1.md")
+		if err != nil || len(annotations) != 20 {
+			t.Errorf("Expected 20 annotations, got %d, %v", len(annotations), err)
+		}
+	})
+}
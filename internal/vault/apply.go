@@ -0,0 +1,546 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Default limits of an ApplyEdits batch
+const (
+	DefaultBatchMaxOperations = 100
+	DefaultBatchMaxBytes      = 4 << 20
+)
+
+// EditKind is the operation of one Edit
+type EditKind string
+
+const (
+	EditCreate EditKind = "create" // Create a note where none exists
+	EditUpdate EditKind = "update" // Replace the content of a note
+	EditAppend EditKind = "append" // Add content at the end of a note
+	EditDelete EditKind = "delete" // Move a note to the trash
+	EditMove   EditKind = "move"   // Move a note to NewPath, which must be free
+)
+
+// ParseEditKind parses an operation name; the empty string is rejected
+func ParseEditKind(s string) (EditKind, error) {
+	switch kind := EditKind(strings.ToLower(strings.TrimSpace(s))); kind {
+	case EditCreate, EditUpdate, EditAppend, EditDelete, EditMove:
+		return kind, nil
+	default:
+		return "", fmt.Errorf("%w: unknown operation %q (want create, update, append, delete or move)", ErrInvalidEdit, s)
+	}
+}
+
+// Edit is one operation of an ApplyEdits batch
+type Edit struct {
+	Op      EditKind
+	Path    string
+	NewPath string // Destination of a move
+	Content string // Content of a create or update, text added by an append
+
+	// ExpectedRevision, when set, is the revision the note must have when
+	// the operation runs, as reported by an earlier batch or read
+	ExpectedRevision string
+}
+
+// BatchLimits bounds the size of an ApplyEdits batch
+type BatchLimits struct {
+	MaxOperations int   `json:"max_operations"`
+	MaxBytes      int64 `json:"max_bytes"` // Content of all operations combined
+}
+
+// BatchOptions configures ApplyEdits
+type BatchOptions struct {
+	Edits  []Edit
+	DryRun bool // Validate and report the planned outcome without writing
+}
+
+// EditOutcome is the planned or applied result of one operation
+type EditOutcome struct {
+	Op               EditKind `json:"op"`
+	Path             string   `json:"path"`
+	NewPath          string   `json:"new_path,omitempty"`
+	PreviousRevision string   `json:"previous_revision,omitempty"` // Revision before the operation, empty for a create
+	Revision         string   `json:"revision,omitempty"`          // Revision after the operation, empty for a delete
+	Bytes            int      `json:"bytes"`                       // Size of the note after the operation
+	Trashed          string   `json:"trashed,omitempty"`           // Where a deleted note was moved, vault-relative
+}
+
+// BatchResult reports the outcome of ApplyEdits, one entry per operation
+type BatchResult struct {
+	DryRun     bool          `json:"dry_run,omitempty"`
+	Operations []EditOutcome `json:"operations"`
+}
+
+// EditProblem is an operation ApplyEdits could not perform
+type EditProblem struct {
+	Index int // Position of the operation in the batch
+	Op    EditKind
+	Path  string
+	Err   error
+}
+
+// BatchError reports why ApplyEdits left the vault unchanged: every
+// operation validation rejected, or the one that failed while writing,
+// after which the operations before it were rolled back
+type BatchError struct {
+	Problems    []EditProblem
+	RolledBack  bool     // A write failed and the earlier operations were undone
+	NotRestored []string // Notes the rollback could not put back, vault-relative
+}
+
+func (e *BatchError) Error() string {
+	parts := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		parts[i] = fmt.Sprintf("operation %d (%s %s): %v", p.Index, p.Op, p.Path, p.Err)
+	}
+	msg := strings.Join(parts, "; ")
+	if len(e.NotRestored) > 0 {
+		msg += fmt.Sprintf("; rollback could not restore %s", strings.Join(e.NotRestored, ", "))
+	}
+	return msg
+}
+
+// Unwrap returns the errors of the problems, so errors.Is matches them
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Problems))
+	for i, p := range e.Problems {
+		errs[i] = p.Err
+	}
+	return errs
+}
+
+// WithBatchLimits bounds the operations and content bytes of an
+// ApplyEdits batch. Values below 1 keep the defaults of 100 operations
+// and 4 MiB.
+func WithBatchLimits(limits BatchLimits) Option {
+	return func(v *vault) {
+		if limits.MaxOperations >= 1 {
+			v.batchLimits.MaxOperations = limits.MaxOperations
+		}
+		if limits.MaxBytes >= 1 {
+			v.batchLimits.MaxBytes = limits.MaxBytes
+		}
+	}
+}
+
+// ApplyEdits performs a batch of operations in order, all or none. Every
+// operation is validated against the state the earlier ones leave before
+// anything is written, and all problems are reported together in a
+// *BatchError. The notes involved stay locked for the whole batch; if a
+// write fails, the operations already done are undone. Deleted notes go
+// to the trash, and updated notes are backed up.
+func (v *vault) ApplyEdits(ctx context.Context, opts BatchOptions) (BatchResult, error) {
+	if len(opts.Edits) > v.batchLimits.MaxOperations {
+		return BatchResult{}, fmt.Errorf("%w: %d operations, at most %d allowed", ErrBatchTooLarge, len(opts.Edits), v.batchLimits.MaxOperations)
+	}
+	var size int64
+	for _, e := range opts.Edits {
+		size += int64(len(e.Content))
+	}
+	if size > v.batchLimits.MaxBytes {
+		return BatchResult{}, fmt.Errorf("%w: %d bytes of content, at most %d allowed", ErrBatchTooLarge, size, v.batchLimits.MaxBytes)
+	}
+
+	// Lock every valid path up front so the plan still holds when written
+	if !opts.DryRun {
+		var lockPaths []string
+		for _, e := range opts.Edits {
+			for _, p := range []string{e.Path, e.NewPath} {
+				if fullPath, err := v.validatePath(p); err == nil && p != "" {
+					lockPaths = append(lockPaths, fullPath)
+				}
+			}
+		}
+		unlock := v.writeLocks.lock(lockPaths...)
+		defer unlock()
+	}
+
+	steps, outcomes, problems, err := v.planEdits(ctx, opts.Edits)
+	if err != nil {
+		return BatchResult{}, err
+	}
+	if len(problems) > 0 {
+		return BatchResult{}, &BatchError{Problems: problems}
+	}
+	result := BatchResult{DryRun: opts.DryRun, Operations: outcomes}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	// Check context cancellation before I/O; once writing starts it completes
+	if err := ctx.Err(); err != nil {
+		return BatchResult{}, err
+	}
+	if err := v.checkAudit(); err != nil {
+		return BatchResult{}, err
+	}
+
+	var undo []func() error
+	for i, step := range steps {
+		trashed, err := v.applyStep(step, &undo)
+		if err != nil {
+			return BatchResult{}, &BatchError{
+				Problems:    []EditProblem{{Index: i, Op: step.op, Path: v.relPath(step.fullPath), Err: err}},
+				RolledBack:  true,
+				NotRestored: v.rollback(undo),
+			}
+		}
+		result.Operations[i].Trashed = trashed
+	}
+
+	// Side data follows once the whole batch has been written
+	structural := false
+	for _, step := range steps {
+		switch step.op {
+		case EditCreate:
+			structural = true
+		case EditDelete:
+			v.dropAnnotations(v.relPath(step.fullPath))
+			v.dropLease(v.relPath(step.fullPath))
+			v.dropPin(v.relPath(step.fullPath))
+			structural = true
+		case EditMove:
+			from, to := v.relPath(step.fullPath), v.relPath(step.newFullPath)
+			v.moveAnnotations(from, to)
+			v.moveBackups(from, to)
+			v.moveLeases(from, to)
+			v.movePins(from, to)
+			structural = true
+		}
+	}
+	if structural {
+		v.paths.invalidate()
+	}
+
+	audit := make([]AuditEntry, len(result.Operations))
+	for i, op := range result.Operations {
+		audit[i] = AuditEntry{Op: op.Op, Path: op.Path, NewPath: op.NewPath, Bytes: op.Bytes, PrevHash: op.PreviousRevision, Hash: op.Revision}
+	}
+	return result, v.record(ctx, audit...)
+}
+
+// batchStep is one validated operation, ready to write
+type batchStep struct {
+	op          EditKind
+	fullPath    string
+	newFullPath string // Destination of a move
+	content     string // Written by create, update and append
+}
+
+// plannedNote is the state of a note as the batch so far leaves it
+type plannedNote struct {
+	exists  bool
+	content string
+}
+
+// planEdits validates edits in order against the state the earlier ones
+// leave, returning the steps and outcomes of a valid batch or the
+// problems of an invalid one. Fails only when ctx is cancelled.
+func (v *vault) planEdits(ctx context.Context, edits []Edit) ([]batchStep, []EditOutcome, []EditProblem, error) {
+	notes := make(map[string]*plannedNote)
+	note := func(fullPath string) (*plannedNote, error) {
+		if n, ok := notes[fullPath]; ok {
+			return n, nil
+		}
+		n := &plannedNote{}
+		stat, err := os.Stat(fullPath)
+		switch {
+		case os.IsNotExist(err):
+		case err != nil:
+			return nil, fmt.Errorf("failed to stat file: %w", err)
+		case stat.IsDir():
+			return nil, fmt.Errorf("%w: %s is a folder", ErrInvalidPath, v.relPath(fullPath))
+		default:
+			if n.content, err = v.Read(ctx, v.relPath(fullPath)); err != nil {
+				return nil, err
+			}
+			n.exists = true
+		}
+		notes[fullPath] = n
+		return n, nil
+	}
+
+	var steps []batchStep
+	var outcomes []EditOutcome
+	var problems []EditProblem
+	for i, e := range edits {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, nil, err
+		}
+		step, outcome, err := v.planEdit(e, note)
+		if err == nil {
+			err = v.checkLeases(ctx, step.fullPath, step.newFullPath)
+		}
+		if err != nil {
+			problems = append(problems, EditProblem{Index: i, Op: e.Op, Path: e.Path, Err: err})
+			continue
+		}
+		steps = append(steps, step)
+		outcomes = append(outcomes, outcome)
+	}
+	return steps, outcomes, problems, nil
+}
+
+// planEdit validates one operation and updates the planned state of the
+// notes it touches, which note returns
+func (v *vault) planEdit(e Edit, note func(fullPath string) (*plannedNote, error)) (batchStep, EditOutcome, error) {
+	op, err := ParseEditKind(string(e.Op))
+	if err != nil {
+		return batchStep{}, EditOutcome{}, err
+	}
+	if op != EditMove && e.NewPath != "" {
+		return batchStep{}, EditOutcome{}, fmt.Errorf("%w: new_path only applies to move", ErrInvalidEdit)
+	}
+	if op == EditCreate && e.ExpectedRevision != "" {
+		return batchStep{}, EditOutcome{}, fmt.Errorf("%w: a created note has no revision to expect", ErrInvalidEdit)
+	}
+
+	fullPath, err := v.validatePath(e.Path)
+	if err != nil {
+		return batchStep{}, EditOutcome{}, err
+	}
+	relPath := v.relPath(fullPath)
+	src, err := note(fullPath)
+	if err != nil {
+		return batchStep{}, EditOutcome{}, err
+	}
+
+	step := batchStep{op: op, fullPath: fullPath}
+	outcome := EditOutcome{Op: op, Path: relPath}
+	if op == EditCreate {
+		if src.exists {
+			return batchStep{}, EditOutcome{}, fmt.Errorf("%w: %s", ErrNoteExists, relPath)
+		}
+	} else {
+		if !src.exists {
+			return batchStep{}, EditOutcome{}, fmt.Errorf("%w: %s", ErrNoteNotFound, relPath)
+		}
+		outcome.PreviousRevision = contentHash(src.content)
+		if e.ExpectedRevision != "" && e.ExpectedRevision != outcome.PreviousRevision {
+			return batchStep{}, EditOutcome{}, fmt.Errorf("%w: %s is at revision %s", ErrRevisionMismatch, relPath, outcome.PreviousRevision)
+		}
+	}
+
+	switch op {
+	case EditCreate, EditUpdate, EditAppend:
+		if err := v.checkWritable(fullPath); err != nil {
+			return batchStep{}, EditOutcome{}, err
+		}
+		content := e.Content
+		if op == EditAppend {
+			content = appendContent(src.content, e.Content)
+		}
+		if content, err = v.PrepareContent(relPath, content, op == EditCreate); err != nil {
+			return batchStep{}, EditOutcome{}, err
+		}
+		step.content = content
+		outcome.Revision, outcome.Bytes = contentHash(content), len(content)
+		*src = plannedNote{exists: true, content: content}
+
+	case EditDelete:
+		if err := v.checkWritable(fullPath); err != nil {
+			return batchStep{}, EditOutcome{}, err
+		}
+		*src = plannedNote{}
+
+	case EditMove:
+		if e.NewPath == "" {
+			return batchStep{}, EditOutcome{}, fmt.Errorf("%w: move needs new_path", ErrInvalidEdit)
+		}
+		newFullPath, err := v.validatePath(e.NewPath)
+		if err != nil {
+			return batchStep{}, EditOutcome{}, err
+		}
+		if newFullPath == fullPath {
+			return batchStep{}, EditOutcome{}, fmt.Errorf("%w: cannot move a note onto itself", ErrInvalidEdit)
+		}
+		dst, err := note(newFullPath)
+		if err != nil {
+			return batchStep{}, EditOutcome{}, err
+		}
+		if dst.exists {
+			return batchStep{}, EditOutcome{}, fmt.Errorf("%w: %s", ErrNoteExists, v.relPath(newFullPath))
+		}
+		if err := v.checkWritable(fullPath, newFullPath); err != nil {
+			return batchStep{}, EditOutcome{}, err
+		}
+		step.newFullPath = newFullPath
+		outcome.NewPath = v.relPath(newFullPath)
+		outcome.Revision, outcome.Bytes = outcome.PreviousRevision, len(src.content)
+		*dst, *src = *src, plannedNote{}
+	}
+
+	return step, outcome, nil
+}
+
+// appendContent adds text at the end of content, on a line of its own
+func appendContent(content, text string) string {
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content + text
+}
+
+// undoError reports the note an undo function could not restore
+type undoError struct {
+	path string
+	err  error
+}
+
+func (e *undoError) Error() string { return fmt.Sprintf("restoring %s: %v", e.path, e.err) }
+
+func (e *undoError) Unwrap() error { return e.err }
+
+// applyStep writes one step, adding the function undoing it to undo, and
+// returns where a deleted note was moved in the trash
+// Caller must hold the write locks of the step's paths
+func (v *vault) applyStep(step batchStep, undo *[]func() error) (string, error) {
+	relPath := v.relPath(step.fullPath)
+	failed := func(err error) error {
+		return &undoError{path: relPath, err: err}
+	}
+
+	switch step.op {
+	case EditCreate:
+		dirs, err := makeDirs(filepath.Dir(step.fullPath))
+		if err != nil {
+			return "", v.permissionError(step.fullPath, err)
+		}
+		// A note created meanwhile is never overwritten, nor a partial one left
+		if err := createFileAtomic(step.fullPath, []byte(step.content)); err != nil {
+			removeDirs(dirs)
+			return "", v.createError(step.fullPath, err)
+		}
+		v.cacheWritten(step.fullPath, step.content)
+		*undo = append(*undo, func() error {
+			defer removeDirs(dirs)
+			defer v.reloadNote(step.fullPath)
+			if err := os.Remove(step.fullPath); err != nil {
+				return failed(err)
+			}
+			return nil
+		})
+
+	case EditUpdate, EditAppend:
+		previous, err := os.ReadFile(step.fullPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to read file: %w", err)
+		}
+		if _, err := v.writeNote(step.fullPath, step.content); err != nil {
+			return "", err
+		}
+		*undo = append(*undo, func() error {
+			defer v.reloadNote(step.fullPath)
+			if err := os.WriteFile(step.fullPath, previous, 0644); err != nil {
+				return failed(err)
+			}
+			return nil
+		})
+
+	case EditDelete:
+		trashed, err := v.trash(step.fullPath)
+		if err != nil {
+			return "", err
+		}
+		*undo = append(*undo, func() error {
+			defer v.reloadNote(step.fullPath)
+			if err := os.MkdirAll(filepath.Dir(step.fullPath), 0755); err != nil {
+				return failed(err)
+			}
+			if err := os.Rename(filepath.Join(v.basePath, filepath.FromSlash(trashed)), step.fullPath); err != nil {
+				return failed(err)
+			}
+			return nil
+		})
+		return trashed, nil
+
+	case EditMove:
+		dirs, err := makeDirs(filepath.Dir(step.newFullPath))
+		if err != nil {
+			return "", v.permissionError(step.newFullPath, err)
+		}
+		if err := os.Rename(step.fullPath, step.newFullPath); err != nil {
+			removeDirs(dirs)
+			return "", fmt.Errorf("failed to move note: %w", v.permissionError(step.fullPath, err))
+		}
+		v.renameCached(step.fullPath, step.newFullPath)
+		*undo = append(*undo, func() error {
+			if err := os.Rename(step.newFullPath, step.fullPath); err != nil {
+				return failed(err)
+			}
+			v.renameCached(step.newFullPath, step.fullPath)
+			removeDirs(dirs)
+			return nil
+		})
+	}
+
+	return "", nil
+}
+
+// rollback runs the undo functions of applied steps, latest first, and
+// returns the notes it could not restore
+func (v *vault) rollback(undo []func() error) []string {
+	var notRestored []string
+	for _, fn := range slices.Backward(undo) {
+		var undoErr *undoError
+		if err := fn(); errors.As(err, &undoErr) {
+			v.logger.Warn("rolling back batch failed", "path", undoErr.path, "error", undoErr.err)
+			notRestored = append(notRestored, undoErr.path)
+		}
+	}
+	return notRestored
+}
+
+// renameCached moves the cache and index entries of a moved note
+func (v *vault) renameCached(oldPath, newPath string) {
+	v.cache.Rename(oldPath, newPath)
+	if v.index != nil {
+		v.index.rename(oldPath, newPath)
+	}
+}
+
+// reloadNote replaces the cache and index entries of fullPath with what
+// is on disk, dropping them when the note no longer exists
+func (v *vault) reloadNote(fullPath string) {
+	v.cache.Delete(fullPath)
+	if v.index != nil {
+		v.index.remove(fullPath)
+	}
+	if stat, err := os.Stat(fullPath); err == nil {
+		_, _ = v.loadEntry(fullPath, stat.ModTime())
+	}
+}
+
+// makeDirs creates dir and its missing parents, returning the ones it
+// created from the outermost in
+func makeDirs(dir string) ([]string, error) {
+	var missing []string
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil || filepath.Dir(d) == d {
+			break
+		}
+		missing = append(missing, d)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directories: %w", err)
+	}
+	slices.Reverse(missing)
+	return missing, nil
+}
+
+// removeDirs removes the directories makeDirs created, innermost first,
+// leaving any that are no longer empty
+func removeDirs(dirs []string) {
+	for _, dir := range slices.Backward(dirs) {
+		if err := os.Remove(dir); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// setupApplyVault creates a vault with a few plain notes
+func setupApplyVault(t *testing.T, opts ...Option) (*vault, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"a.md":        "Alpha",
+		"b.md":        "Bravo\n",
+		"c.md":        "Charlie",
+		"Old/d.md":    "Delta",
+		"keep/far.md": "Far",
+	})
+
+	v, err := NewVault(tmpDir, opts...)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	return v.(*vault), tmpDir
+}
+
+// exists reports whether the vault file at path exists
+func exists(tmpDir, path string) bool {
+	_, err := os.Stat(filepath.Join(tmpDir, path))
+	return err == nil
+}
+
+// mixedBatch exercises every kind of operation
+var mixedBatch = []Edit{
+	{Op: EditCreate, Path: "New/e.md", Content: "Echo"},
+	{Op: EditUpdate, Path: "a.md", Content: "Alpha 2", ExpectedRevision: contentHash("Alpha")},
+	{Op: EditAppend, Path: "b.md", Content: "more"},
+	{Op: EditMove, Path: "c.md", NewPath: "Archive/c.md"},
+	{Op: EditDelete, Path: "Old/d.md"},
+}
+
+func TestApplyEdits(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("applies every operation", func(t *testing.T) {
+		v, tmpDir := setupApplyVault(t)
+		result, err := v.ApplyEdits(ctx, BatchOptions{Edits: mixedBatch})
+		if err != nil {
+			t.Fatalf("ApplyEdits failed: %v", err)
+		}
+
+		for path, want := range map[string]string{"New/e.md": "Echo", "a.md": "Alpha 2", "b.md": "Bravo\nmore", "Archive/c.md": "Charlie"} {
+			if got := readFile(t, tmpDir, path); got != want {
+				t.Errorf("%s = %q, want %q", path, got, want)
+			}
+			if got, err := v.Read(ctx, path); err != nil || got != want {
+				t.Errorf("Read(%s) = %q, %v, want %q", path, got, err, want)
+			}
+		}
+		if exists(tmpDir, "c.md") || exists(tmpDir, "Old/d.md") {
+			t.Error("Expected the moved and deleted notes to be gone")
+		}
+
+		ops := result.Operations
+		if len(ops) != len(mixedBatch) {
+			t.Fatalf("Got %d outcomes, want %d", len(ops), len(mixedBatch))
+		}
+		if ops[1].PreviousRevision != contentHash("Alpha") || ops[1].Revision != contentHash("Alpha 2") {
+			t.Errorf("Update outcome = %+v", ops[1])
+		}
+		if ops[3].NewPath != "Archive/c.md" || ops[3].Revision != contentHash("Charlie") {
+			t.Errorf("Move outcome = %+v", ops[3])
+		}
+		if ops[4].Trashed == "" || readFile(t, tmpDir, ops[4].Trashed) != "Delta" {
+			t.Errorf("Delete outcome = %+v, want the note in the trash", ops[4])
+		}
+	})
+
+	t.Run("dry run writes nothing", func(t *testing.T) {
+		v, tmpDir := setupApplyVault(t)
+		result, err := v.ApplyEdits(ctx, BatchOptions{Edits: mixedBatch, DryRun: true})
+		if err != nil {
+			t.Fatalf("ApplyEdits failed: %v", err)
+		}
+		if !result.DryRun || len(result.Operations) != len(mixedBatch) || result.Operations[2].Bytes != len("Bravo\nmore") {
+			t.Errorf("Dry run result = %+v", result)
+		}
+		if exists(tmpDir, "New/e.md") || readFile(t, tmpDir, "a.md") != "Alpha" || !exists(tmpDir, "Old/d.md") {
+			t.Error("Expected a dry run to leave the vault unchanged")
+		}
+	})
+
+	t.Run("operations see the earlier ones", func(t *testing.T) {
+		v, tmpDir := setupApplyVault(t)
+		_, err := v.ApplyEdits(ctx, BatchOptions{Edits: []Edit{
+			{Op: EditMove, Path: "a.md", NewPath: "z.md"},
+			{Op: EditAppend, Path: "z.md", Content: "tail", ExpectedRevision: contentHash("Alpha")},
+			{Op: EditCreate, Path: "a.md", Content: "Fresh"},
+			{Op: EditDelete, Path: "b.md"},
+			{Op: EditCreate, Path: "b.md", Content: "Reborn"},
+		}})
+		if err != nil {
+			t.Fatalf("ApplyEdits failed: %v", err)
+		}
+		for path, want := range map[string]string{"z.md": "Alpha\ntail", "a.md": "Fresh", "b.md": "Reborn"} {
+			if got := readFile(t, tmpDir, path); got != want {
+				t.Errorf("%s = %q, want %q", path, got, want)
+			}
+		}
+	})
+
+	t.Run("reports every problem and writes nothing", func(t *testing.T) {
+		v, tmpDir := setupApplyVault(t, WithReadOnlyPaths("keep"))
+		_, err := v.ApplyEdits(ctx, BatchOptions{Edits: []Edit{
+			{Op: EditCreate, Path: "New/e.md", Content: "Echo"},
+			{Op: EditUpdate, Path: "missing.md", Content: "x"},
+			{Op: EditCreate, Path: "a.md", Content: "x"},
+			{Op: EditUpdate, Path: "b.md", Content: "x", ExpectedRevision: "stale"},
+			{Op: "rename", Path: "c.md"},
+			{Op: EditDelete, Path: "keep/far.md"},
+			{Op: EditMove, Path: "c.md", NewPath: "a.md"},
+			{Op: EditDelete, Path: "../outside.md"},
+		}})
+
+		var batchErr *BatchError
+		if !errors.As(err, &batchErr) {
+			t.Fatalf("Expected a BatchError, got %v", err)
+		}
+		var indices []int
+		for _, p := range batchErr.Problems {
+			indices = append(indices, p.Index)
+		}
+		if want := []int{1, 2, 3, 4, 5, 6, 7}; !reflect.DeepEqual(indices, want) {
+			t.Errorf("Problems at %v, want %v", indices, want)
+		}
+		for _, want := range []error{ErrNoteNotFound, ErrNoteExists, ErrRevisionMismatch, ErrInvalidEdit, ErrReadOnly, ErrPathTraversal} {
+			if !errors.Is(err, want) {
+				t.Errorf("Expected the error to match %v: %v", want, err)
+			}
+		}
+		if batchErr.RolledBack || exists(tmpDir, "New/e.md") {
+			t.Error("Expected validation to stop the batch before writing")
+		}
+	})
+
+	t.Run("rolls back when a write fails", func(t *testing.T) {
+		v, tmpDir := setupApplyVault(t)
+		// A file where the backups of c.md belong makes its update fail
+		blocker := v.backupPath("c.md")
+		if err := os.MkdirAll(filepath.Dir(blocker), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(blocker, nil, 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		if _, err := v.Read(ctx, "a.md"); err != nil {
+			t.Fatalf("Read failed: %v", err)
+		}
+
+		_, err := v.ApplyEdits(ctx, BatchOptions{Edits: []Edit{
+			{Op: EditCreate, Path: "New/Deep/e.md", Content: "Echo"},
+			{Op: EditUpdate, Path: "a.md", Content: "Alpha 2"},
+			{Op: EditMove, Path: "b.md", NewPath: "Moved/b.md"},
+			{Op: EditDelete, Path: "Old/d.md"},
+			{Op: EditUpdate, Path: "c.md", Content: "Charlie 2"},
+		}})
+
+		var batchErr *BatchError
+		if !errors.As(err, &batchErr) || !batchErr.RolledBack {
+			t.Fatalf("Expected a rolled back BatchError, got %v", err)
+		}
+		if len(batchErr.Problems) != 1 || batchErr.Problems[0].Index != 4 || len(batchErr.NotRestored) != 0 {
+			t.Errorf("BatchError = %+v, want operation 4 to fail and everything restored", batchErr)
+		}
+
+		for path, want := range map[string]string{"a.md": "Alpha", "b.md": "Bravo\n", "c.md": "Charlie", "Old/d.md": "Delta"} {
+			if got := readFile(t, tmpDir, path); got != want {
+				t.Errorf("%s = %q, want %q", path, got, want)
+			}
+			if got, err := v.Read(ctx, path); err != nil || got != want {
+				t.Errorf("Read(%s) = %q, %v, want %q", path, got, err, want)
+			}
+		}
+		for _, path := range []string{"New", "Moved"} {
+			if exists(tmpDir, path) {
+				t.Errorf("Expected %s, created by the batch, to be removed", path)
+			}
+		}
+	})
+
+	t.Run("limits", func(t *testing.T) {
+		v, _ := setupApplyVault(t, WithBatchLimits(BatchLimits{MaxOperations: 2, MaxBytes: 8}))
+		_, err := v.ApplyEdits(ctx, BatchOptions{Edits: mixedBatch[:3]})
+		if !errors.Is(err, ErrBatchTooLarge) {
+			t.Errorf("Expected ErrBatchTooLarge for too many operations, got %v", err)
+		}
+		_, err = v.ApplyEdits(ctx, BatchOptions{Edits: []Edit{{Op: EditAppend, Path: "a.md", Content: "123456789"}}})
+		if !errors.Is(err, ErrBatchTooLarge) {
+			t.Errorf("Expected ErrBatchTooLarge for too much content, got %v", err)
+		}
+	})
+}
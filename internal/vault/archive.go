@@ -0,0 +1,201 @@
+package vault
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ArchiveFormat selects the container ExportVault writes
+type ArchiveFormat string
+
+// Archive formats
+const (
+	ArchiveZip ArchiveFormat = "zip" // Deflate-compressed zip
+	ArchiveTar ArchiveFormat = "tar" // Uncompressed POSIX tar
+)
+
+// manifestFile is the name of the manifest inside an archive; notes all
+// end in .md so it cannot clash with one
+const manifestFile = "manifest.json"
+
+// ParseArchiveFormat validates an archive format, defaulting to zip
+func ParseArchiveFormat(s string) (ArchiveFormat, error) {
+	switch format := ArchiveFormat(strings.ToLower(strings.TrimSpace(s))); format {
+	case "":
+		return ArchiveZip, nil
+	case ArchiveZip, ArchiveTar:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown archive format %q (want zip or tar)", s)
+	}
+}
+
+// ArchiveOptions selects the notes ExportVault writes
+// All criteria are optional; zero values export every note
+type ArchiveOptions struct {
+	Subpath       string        // Directory to export, empty for the whole vault
+	Format        ArchiveFormat // Container format, zip when empty
+	TagsAny       []string      // Notes must have at least one of these tags
+	ModifiedAfter time.Time     // Only notes modified at or after this time
+}
+
+// ArchivedNote is one note in an archive's manifest
+type ArchivedNote struct {
+	Path        string    `json:"path"`
+	Bytes       int64     `json:"bytes"`
+	Modified    time.Time `json:"modified"`
+	ContentHash string    `json:"content_hash"` // Hex SHA-256 of the file as archived
+}
+
+// SkippedNote is a selected note left out of an archive
+type SkippedNote struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// ArchiveManifest describes the content of an archive. It is written into
+// the archive as manifest.json after the notes.
+type ArchiveManifest struct {
+	Format     ArchiveFormat  `json:"format"`
+	Created    time.Time      `json:"created"`
+	Subpath    string         `json:"subpath,omitempty"`
+	Notes      []ArchivedNote `json:"notes"`             // Sorted by path
+	TotalBytes int64          `json:"total_bytes"`       // Size of the archived notes, before compression
+	Skipped    []SkippedNote  `json:"skipped,omitempty"` // Notes that changed or vanished while being read
+}
+
+// archiveWriter adds files to a zip or tar stream
+type archiveWriter interface {
+	add(name string, modified time.Time, data []byte) error
+	Close() error
+}
+
+type zipArchive struct{ w *zip.Writer }
+
+func (a zipArchive) add(name string, modified time.Time, data []byte) error {
+	f, err := a.w.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+func (a zipArchive) Close() error { return a.w.Close() }
+
+type tarArchive struct{ w *tar.Writer }
+
+func (a tarArchive) add(name string, modified time.Time, data []byte) error {
+	header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: modified, Format: tar.FormatPAX}
+	if err := a.w.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := a.w.Write(data)
+	return err
+}
+
+func (a tarArchive) Close() error { return a.w.Close() }
+
+// ExportVault writes the markdown notes selected by opts to w as a zip or
+// tar archive, at their vault-relative paths, followed by a manifest of
+// their paths, sizes and hashes. Hidden files and the server's data
+// directory are left out. Notes are read one at a time rather than from a
+// single point in time; a note that changes or disappears while it is
+// read is skipped and reported in the manifest. Fails when w does.
+func (v *vault) ExportVault(ctx context.Context, opts ArchiveOptions, w io.Writer) (ArchiveManifest, error) {
+	format, err := ParseArchiveFormat(string(opts.Format))
+	if err != nil {
+		return ArchiveManifest{}, err
+	}
+
+	notes, err := v.List(ctx, ListOptions{
+		Subpath:   opts.Subpath,
+		Recursive: true,
+		Filter:    NoteFilter{TagsAny: opts.TagsAny, ModifiedAfter: opts.ModifiedAfter},
+	})
+	if err != nil {
+		return ArchiveManifest{}, err
+	}
+	slices.SortFunc(notes, func(a, b NoteInfo) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	manifest := ArchiveManifest{Format: format, Created: time.Now().UTC(), Notes: []ArchivedNote{}}
+	if opts.Subpath != "" {
+		if root, err := v.validateDir(opts.Subpath); err == nil {
+			manifest.Subpath = v.folderPath(root)
+		}
+	}
+
+	var archive archiveWriter
+	if format == ArchiveTar {
+		archive = tarArchive{tar.NewWriter(w)}
+	} else {
+		archive = zipArchive{zip.NewWriter(w)}
+	}
+
+	for i, note := range notes {
+		if err := ctx.Err(); err != nil {
+			return ArchiveManifest{}, err
+		}
+		ReportProgress(ctx, i, len(notes))
+		relPath := filepath.ToSlash(note.Path)
+		data, modified, skipped := readStable(filepath.Join(v.basePath, filepath.FromSlash(relPath)), note.Modified)
+		if skipped != "" {
+			manifest.Skipped = append(manifest.Skipped, SkippedNote{Path: relPath, Reason: skipped})
+			continue
+		}
+		if err := archive.add(relPath, modified, data); err != nil {
+			return ArchiveManifest{}, fmt.Errorf("failed to write archive: %w", err)
+		}
+		manifest.Notes = append(manifest.Notes, ArchivedNote{
+			Path:        relPath,
+			Bytes:       int64(len(data)),
+			Modified:    modified,
+			ContentHash: contentHash(string(data)),
+		})
+		manifest.TotalBytes += int64(len(data))
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return ArchiveManifest{}, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := archive.add(manifestFile, manifest.Created, data); err != nil {
+		return ArchiveManifest{}, fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := archive.Close(); err != nil {
+		return ArchiveManifest{}, fmt.Errorf("failed to write archive: %w", err)
+	}
+	return manifest, nil
+}
+
+// readStable reads the file at fullPath, returning why it is skipped
+// instead when it is gone or was modified since listed or while read
+func readStable(fullPath string, listed time.Time) ([]byte, time.Time, string) {
+	before, err := os.Stat(fullPath)
+	if err != nil {
+		return nil, time.Time{}, "removed during export"
+	}
+	data, err := os.ReadFile(fullPath)
+	if err != nil {
+		return nil, time.Time{}, "could not be read"
+	}
+	after, err := os.Stat(fullPath)
+	if err != nil {
+		return nil, time.Time{}, "removed during export"
+	}
+	if !before.ModTime().Equal(listed) || !after.ModTime().Equal(before.ModTime()) || after.Size() != int64(len(data)) {
+		return nil, time.Time{}, "changed during export"
+	}
+	return data, after.ModTime(), ""
+}
@@ -0,0 +1,155 @@
+package vault
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// archiveFiles returns the content of every file in a zip or tar archive
+func archiveFiles(t *testing.T, format ArchiveFormat, data []byte) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	if format == ArchiveTar {
+		r := tar.NewReader(bytes.NewReader(data))
+		for {
+			header, err := r.Next()
+			if err == io.EOF {
+				return files
+			}
+			if err != nil {
+				t.Fatalf("Reading tar failed: %v", err)
+			}
+			content, _ := io.ReadAll(r)
+			files[header.Name] = string(content)
+		}
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Reading zip failed: %v", err)
+	}
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("Opening %s failed: %v", f.Name, err)
+		}
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(content)
+	}
+	return files
+}
+
+func TestExportVault(t *testing.T) {
+	v, tmpDir := setupTestVault(t)
+	ctx := context.Background()
+
+	for _, format := range []ArchiveFormat{ArchiveZip, ArchiveTar} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			manifest, err := v.ExportVault(ctx, ArchiveOptions{Format: format}, &buf)
+			if err != nil {
+				t.Fatalf("ExportVault() error = %v", err)
+			}
+
+			want := []string{"note1.md", "note2.md", "other/note5.md", "subdir/deep/note4.md", "subdir/note3.md"}
+			var paths []string
+			var total int64
+			for _, note := range manifest.Notes {
+				paths = append(paths, note.Path)
+				total += note.Bytes
+			}
+			if !slices.Equal(paths, want) || manifest.TotalBytes != total || manifest.Format != format || len(manifest.Skipped) != 0 {
+				t.Errorf("ExportVault() manifest = %+v, want notes %v", manifest, want)
+			}
+
+			files := archiveFiles(t, format, buf.Bytes())
+			if len(files) != len(want)+1 {
+				t.Errorf("archive holds %d files, want the notes and the manifest", len(files))
+			}
+			for _, note := range manifest.Notes {
+				content := readFile(t, tmpDir, note.Path)
+				if files[note.Path] != content || note.ContentHash != contentHash(content) {
+					t.Errorf("%s archived as %q with hash %s", note.Path, files[note.Path], note.ContentHash)
+				}
+			}
+			var archived ArchiveManifest
+			if err := json.Unmarshal([]byte(files[manifestFile]), &archived); err != nil || len(archived.Notes) != len(want) {
+				t.Errorf("manifest.json = %q, %v", files[manifestFile], err)
+			}
+		})
+	}
+
+	t.Run("filters", func(t *testing.T) {
+		old := time.Now().Add(-48 * time.Hour)
+		if err := os.Chtimes(filepath.Join(tmpDir, "note2.md"), old, old); err != nil {
+			t.Fatal(err)
+		}
+
+		tests := []struct {
+			name string
+			opts ArchiveOptions
+			want []string
+		}{
+			{"subpath", ArchiveOptions{Subpath: "subdir"}, []string{"subdir/deep/note4.md", "subdir/note3.md"}},
+			{"tags", ArchiveOptions{TagsAny: []string{"tag2"}}, []string{"note1.md", "note2.md"}},
+			{"modified after", ArchiveOptions{TagsAny: []string{"tag2"}, ModifiedAfter: time.Now().Add(-time.Hour)}, []string{"note1.md"}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				manifest, err := v.ExportVault(ctx, tt.opts, io.Discard)
+				if err != nil {
+					t.Fatalf("ExportVault() error = %v", err)
+				}
+				var paths []string
+				for _, note := range manifest.Notes {
+					paths = append(paths, note.Path)
+				}
+				if !slices.Equal(paths, tt.want) {
+					t.Errorf("ExportVault() notes = %v, want %v", paths, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if _, err := v.ExportVault(ctx, ArchiveOptions{Subpath: "missing"}, io.Discard); !errors.Is(err, ErrDirectoryNotFound) {
+			t.Errorf("ExportVault() missing folder error = %v", err)
+		}
+		if _, err := v.ExportVault(ctx, ArchiveOptions{Format: "rar"}, io.Discard); err == nil {
+			t.Error("ExportVault() accepted an unknown format")
+		}
+	})
+}
+
+func TestReadStable(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "note.md")
+	if err := os.WriteFile(file, []byte("text"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stat, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data, _, skipped := readStable(file, stat.ModTime()); skipped != "" || string(data) != "text" {
+		t.Errorf("readStable() = %q, %q", data, skipped)
+	}
+	if _, _, skipped := readStable(file, stat.ModTime().Add(-time.Second)); skipped != "changed during export" {
+		t.Errorf("readStable() of a note modified since listed skipped = %q", skipped)
+	}
+	if _, _, skipped := readStable(filepath.Join(dir, "gone.md"), stat.ModTime()); skipped != "removed during export" {
+		t.Errorf("readStable() of a removed note skipped = %q", skipped)
+	}
+}
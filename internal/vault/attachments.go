@@ -0,0 +1,267 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// AttachmentInfo represents metadata about a non-markdown file
+type AttachmentInfo struct {
+	Path     string    `json:"path"`      // Relative path from vault root
+	Size     int64     `json:"size"`      // File size in bytes
+	Modified time.Time `json:"modified"`  // File modification time
+	MimeType string    `json:"mime_type"` // Media type derived from the extension
+}
+
+// IsImage reports whether the attachment is an image
+func (a AttachmentInfo) IsImage() bool {
+	return strings.HasPrefix(a.MimeType, "image/")
+}
+
+// AttachmentOptions selects the attachments returned by ListAttachments
+type AttachmentOptions struct {
+	Subpath       string   // Directory to list, empty for the vault root
+	Recursive     bool     // Include attachments in subdirectories
+	IncludeHidden bool     // Include files and directories whose name starts with a dot
+	Extensions    []string // Restrict to these extensions, empty for all allowed
+}
+
+// attachmentTypes is the allowlist of attachment extensions with their
+// media types. Anything else, notes included, is not an attachment.
+var attachmentTypes = map[string]string{
+	"png":  "image/png",
+	"jpg":  "image/jpeg",
+	"jpeg": "image/jpeg",
+	"gif":  "image/gif",
+	"webp": "image/webp",
+	"svg":  "image/svg+xml",
+	"bmp":  "image/bmp",
+	"avif": "image/avif",
+	"pdf":  "application/pdf",
+	"mp3":  "audio/mpeg",
+	"wav":  "audio/wav",
+	"m4a":  "audio/mp4",
+	"ogg":  "audio/ogg",
+	"flac": "audio/flac",
+	"mp4":  "video/mp4",
+	"webm": "video/webm",
+	"mov":  "video/quicktime",
+	"mkv":  "video/x-matroska",
+	"csv":  "text/csv",
+	"txt":  "text/plain",
+}
+
+// AttachmentExtensions returns the allowed attachment extensions, sorted
+func AttachmentExtensions() []string {
+	exts := make([]string, 0, len(attachmentTypes))
+	for ext := range attachmentTypes {
+		exts = append(exts, ext)
+	}
+	slices.Sort(exts)
+	return exts
+}
+
+// attachmentType returns the media type for path if its extension is allowed
+func attachmentType(path string) (string, bool) {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	mimeType, ok := attachmentTypes[ext]
+	return mimeType, ok
+}
+
+// normalizeExtensions lowercases and strips dots from extensions,
+// rejecting any outside the allowlist
+func normalizeExtensions(exts []string) (map[string]bool, error) {
+	if len(exts) == 0 {
+		return nil, nil
+	}
+
+	allowed := make(map[string]bool, len(exts))
+	for _, ext := range exts {
+		ext = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+		if _, ok := attachmentTypes[ext]; !ok {
+			return nil, fmt.Errorf("%w: .%s", ErrNotAttachment, ext)
+		}
+		allowed[ext] = true
+	}
+	return allowed, nil
+}
+
+// validateAttachmentPath is validatePath for attachments: the traversal
+// and reserved path checks apply, but instead of requiring .md the
+// extension must be on the attachment allowlist. A bare file name missing
+// from the vault root is looked up in the attachment folder, where
+// Obsidian puts pasted files.
+func (v *vault) validateAttachmentPath(path string) (string, error) {
+	fullPath, err := v.validateFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	if _, ok := attachmentType(fullPath); !ok {
+		return "", ErrNotAttachment
+	}
+
+	if folder := v.effectiveAttachmentFolder(); folder != "" && !strings.ContainsAny(path, `/\`) {
+		if _, err := os.Stat(fullPath); os.IsNotExist(err) {
+			if inFolder, err := v.validateFile(folder + "/" + path); err == nil {
+				return inFolder, nil
+			}
+		}
+	}
+
+	return fullPath, nil
+}
+
+// ListAttachments returns the attachments selected by opts, sorted by path
+func (v *vault) ListAttachments(ctx context.Context, opts AttachmentOptions) ([]AttachmentInfo, error) {
+	root, err := v.validateDir(opts.Subpath)
+	if err != nil {
+		return nil, err
+	}
+
+	exts, err := normalizeExtensions(opts.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	includeHidden := v.includeHidden || opts.IncludeHidden
+
+	attachments := []AttachmentInfo{}
+
+	walkFn := func(path string, info os.FileInfo, err error) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		if err != nil {
+			return nil // Skip inaccessible files and directories
+		}
+
+		// The root was requested by name, so only entries below it can be hidden
+		if path != root && !includeHidden && isHidden(path) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if info.IsDir() {
+			if !opts.Recursive && path != root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		mimeType, ok := attachmentType(path)
+		if !ok {
+			return nil
+		}
+		if exts != nil && !exts[strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))] {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(v.basePath, path)
+		if err != nil {
+			return nil
+		}
+
+		attachments = append(attachments, AttachmentInfo{
+			Path:     filepath.ToSlash(relPath),
+			Size:     info.Size(),
+			Modified: info.ModTime(),
+			MimeType: mimeType,
+		})
+		return nil
+	}
+
+	if err := v.walk(root, walkFn); err != nil {
+		return nil, fmt.Errorf("failed to walk directory: %w", err)
+	}
+
+	slices.SortFunc(attachments, func(a, b AttachmentInfo) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return attachments, nil
+}
+
+// StatAttachment returns metadata about a single attachment
+func (v *vault) StatAttachment(ctx context.Context, path string) (AttachmentInfo, error) {
+	fullPath, err := v.validateAttachmentPath(path)
+	if err != nil {
+		return AttachmentInfo{}, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return AttachmentInfo{}, err
+	}
+
+	return v.statAttachment(fullPath)
+}
+
+// statAttachment stats a validated attachment path
+func (v *vault) statAttachment(fullPath string) (AttachmentInfo, error) {
+	stat, err := os.Stat(fullPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return AttachmentInfo{}, ErrAttachmentNotFound
+		}
+		return AttachmentInfo{}, fmt.Errorf("failed to stat file: %w", err)
+	}
+	if stat.IsDir() {
+		return AttachmentInfo{}, ErrAttachmentNotFound
+	}
+
+	mimeType, _ := attachmentType(fullPath)
+	return AttachmentInfo{
+		Path:     v.relPath(fullPath),
+		Size:     stat.Size(),
+		Modified: stat.ModTime(),
+		MimeType: mimeType,
+	}, nil
+}
+
+// ReadAttachment returns the content of an attachment of at most maxBytes
+// Larger files fail with ErrAttachmentTooLarge without being read
+func (v *vault) ReadAttachment(ctx context.Context, path string, maxBytes int64) ([]byte, AttachmentInfo, error) {
+	fullPath, err := v.validateAttachmentPath(path)
+	if err != nil {
+		return nil, AttachmentInfo{}, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, AttachmentInfo{}, err
+	}
+
+	info, err := v.statAttachment(fullPath)
+	if err != nil {
+		return nil, AttachmentInfo{}, err
+	}
+	if info.Size > maxBytes {
+		return nil, info, ErrAttachmentTooLarge
+	}
+
+	f, err := os.Open(fullPath)
+	if err != nil {
+		return nil, info, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	// Guard against the file growing between stat and read
+	data, err := io.ReadAll(io.LimitReader(f, maxBytes+1))
+	if err != nil {
+		return nil, info, fmt.Errorf("failed to read file: %w", err)
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, info, ErrAttachmentTooLarge
+	}
+
+	return data, info, nil
+}
@@ -0,0 +1,167 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// setupAttachmentVault adds attachments next to the standard test notes
+func setupAttachmentVault(t *testing.T) (Vault, string) {
+	v, tmpDir := setupTestVault(t)
+
+	files := map[string]string{
+		"images/diagram.png":     "png data",
+		"images/photo.JPG":       "jpeg data",
+		"docs/spec.pdf":          "pdf data",
+		"docs/archive.zip":       "not allowed",
+		".obsidian/icon.png":     "hidden",
+		"images/nested/deep.gif": "gif data",
+	}
+
+	writeFiles(t, tmpDir, files)
+
+	return v, tmpDir
+}
+
+func TestListAttachments(t *testing.T) {
+	v, _ := setupAttachmentVault(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		opts    AttachmentOptions
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "all recursive",
+			opts: AttachmentOptions{Recursive: true},
+			want: []string{"docs/spec.pdf", "images/diagram.png", "images/nested/deep.gif", "images/photo.JPG", "readme.txt"},
+		},
+		{
+			name: "non-recursive subdirectory",
+			opts: AttachmentOptions{Subpath: "images"},
+			want: []string{"images/diagram.png", "images/photo.JPG"},
+		},
+		{
+			name: "extension filter",
+			opts: AttachmentOptions{Recursive: true, Extensions: []string{".PNG", "jpg"}},
+			want: []string{"images/diagram.png", "images/photo.JPG"},
+		},
+		{
+			name: "including hidden",
+			opts: AttachmentOptions{Subpath: ".obsidian", IncludeHidden: true},
+			want: []string{".obsidian/icon.png"},
+		},
+		{
+			name:    "extension outside allowlist",
+			opts:    AttachmentOptions{Extensions: []string{"zip"}},
+			wantErr: ErrNotAttachment,
+		},
+		{
+			name:    "path traversal",
+			opts:    AttachmentOptions{Subpath: "../.."},
+			wantErr: ErrPathTraversal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attachments, err := v.ListAttachments(ctx, tt.opts)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ListAttachments() error = %v", err)
+			}
+
+			if len(attachments) != len(tt.want) {
+				t.Fatalf("Got %d attachments, want %d: %v", len(attachments), len(tt.want), attachments)
+			}
+			for i, a := range attachments {
+				if got := filepath.ToSlash(a.Path); got != tt.want[i] {
+					t.Errorf("attachments[%d] = %s, want %s", i, got, tt.want[i])
+				}
+				if a.Size == 0 || a.Modified.IsZero() || a.MimeType == "" {
+					t.Errorf("Incomplete attachment info: %+v", a)
+				}
+			}
+		})
+	}
+}
+
+func TestStatAttachment(t *testing.T) {
+	v, _ := setupAttachmentVault(t)
+	ctx := context.Background()
+
+	t.Run("existing image", func(t *testing.T) {
+		info, err := v.StatAttachment(ctx, "images/photo.JPG")
+		if err != nil {
+			t.Fatalf("StatAttachment() error = %v", err)
+		}
+		if info.Size != int64(len("jpeg data")) {
+			t.Errorf("Size = %d, want %d", info.Size, len("jpeg data"))
+		}
+		if info.MimeType != "image/jpeg" || !info.IsImage() {
+			t.Errorf("MimeType = %s, want image/jpeg", info.MimeType)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr error
+	}{
+		{"missing attachment", "images/missing.png", ErrAttachmentNotFound},
+		{"note is not an attachment", "note1.md", ErrNotAttachment},
+		{"disallowed extension", "docs/archive.zip", ErrNotAttachment},
+		{"path traversal", "../secret.png", ErrPathTraversal},
+		{"empty path", "", ErrInvalidPath},
+		{"server data", ".mcp-notes/backups/x.png", ErrReservedPath},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.StatAttachment(ctx, tt.path)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestReadAttachment(t *testing.T) {
+	v, _ := setupAttachmentVault(t)
+	ctx := context.Background()
+
+	t.Run("within limit", func(t *testing.T) {
+		data, info, err := v.ReadAttachment(ctx, "images/diagram.png", 1024)
+		if err != nil {
+			t.Fatalf("ReadAttachment() error = %v", err)
+		}
+		if string(data) != "png data" {
+			t.Errorf("data = %q, want %q", data, "png data")
+		}
+		if info.MimeType != "image/png" {
+			t.Errorf("MimeType = %s, want image/png", info.MimeType)
+		}
+	})
+
+	t.Run("over limit", func(t *testing.T) {
+		data, info, err := v.ReadAttachment(ctx, "images/diagram.png", 3)
+		if !errors.Is(err, ErrAttachmentTooLarge) {
+			t.Fatalf("Expected ErrAttachmentTooLarge, got %v", err)
+		}
+		if data != nil {
+			t.Error("Expected no data for oversized attachment")
+		}
+		if info.Size != int64(len("png data")) {
+			t.Errorf("Size = %d, want %d", info.Size, len("png data"))
+		}
+	})
+}
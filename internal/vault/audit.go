@@ -0,0 +1,290 @@
+package vault
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Audit log defaults
+const (
+	auditFile = "audit.log"
+
+	// DefaultAuditMaxBytes is the size at which the audit log is rotated
+	DefaultAuditMaxBytes = 10 << 20
+
+	// auditKeep is the number of rotated logs kept, audit.log.1 newest
+	auditKeep = 3
+)
+
+// AuditEntry records one change made to a note
+type AuditEntry struct {
+	Time     time.Time `json:"time"`
+	Tool     string    `json:"tool,omitempty"`   // Tool call that made the change
+	Client   string    `json:"client,omitempty"` // Holder of the call, see HolderContext
+	Op       EditKind  `json:"op"`
+	Path     string    `json:"path"`
+	NewPath  string    `json:"new_path,omitempty"`  // Destination of a move
+	Bytes    int       `json:"bytes"`               // Size of the note's text after the change, 0 for a delete
+	PrevHash string    `json:"prev_hash,omitempty"` // Content hash before the change, empty for a create
+	Hash     string    `json:"hash,omitempty"`      // Content hash after the change, empty for a delete
+}
+
+// AuditQuery selects the entries returned by AuditLog
+// All criteria are optional; zero values match every entry
+type AuditQuery struct {
+	Path  string    // Note or folder the entry's path or new path must be in
+	Since time.Time // Entries at or after this time
+	Until time.Time // Entries before this time
+	Limit int       // Most recent entries returned, 0 or less for all
+}
+
+// AuditCall collects the audit log failures of one call, which are
+// otherwise only logged when the audit is not strict
+type AuditCall struct {
+	Tool string
+
+	mu       sync.Mutex
+	warnings []string
+}
+
+// Warnings returns the audit log failures of the call so far
+func (c *AuditCall) Warnings() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.warnings)
+}
+
+func (c *AuditCall) warn(msg string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.warnings = append(c.warnings, msg)
+}
+
+// auditCallKey is the context key of the AuditCall
+type auditCallKey struct{}
+
+// AuditContext returns a context whose changes are recorded as made by
+// call.Tool, with audit log failures added to call
+func AuditContext(ctx context.Context, call *AuditCall) context.Context {
+	return context.WithValue(ctx, auditCallKey{}, call)
+}
+
+// WithAuditLog writes the audit log to file instead of
+// .mcp-notes/audit.log, rotating it once it exceeds maxBytes. A maxBytes
+// below 1 keeps the default of 10 MiB.
+func WithAuditLog(file string, maxBytes int64) Option {
+	return func(v *vault) {
+		if file != "" {
+			v.audit.file = file
+		}
+		if maxBytes >= 1 {
+			v.audit.maxBytes = maxBytes
+		}
+	}
+}
+
+// WithStrictAudit makes writes fail when they cannot be recorded in the
+// audit log. By default the change is made and the failure is logged and
+// reported to the call's AuditCall.
+func WithStrictAudit(strict bool) Option {
+	return func(v *vault) {
+		v.audit.strict = strict
+	}
+}
+
+// auditLog appends entries to a JSON-lines file, rotated by size
+type auditLog struct {
+	mu       sync.Mutex
+	file     string
+	maxBytes int64
+	strict   bool
+}
+
+// checkAudit fails with ErrAuditFailed when the audit is strict and the
+// log cannot be written, so a change is refused rather than made unrecorded
+func (v *vault) checkAudit() error {
+	if !v.audit.strict {
+		return nil
+	}
+	v.audit.mu.Lock()
+	defer v.audit.mu.Unlock()
+	f, err := v.audit.open()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrAuditFailed, err)
+	}
+	return f.Close()
+}
+
+// record appends entries for changes just made on behalf of ctx. A
+// failure is returned only when the audit is strict; otherwise it is
+// logged and added to the call's warnings.
+func (v *vault) record(ctx context.Context, entries ...AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	call, _ := ctx.Value(auditCallKey{}).(*AuditCall)
+	now := time.Now().UTC()
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		entry.Time = now
+		entry.Client = claimFrom(ctx).holder
+		if call != nil {
+			entry.Tool = call.Tool
+		}
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	err := v.audit.append(buf.Bytes())
+	if err == nil {
+		return nil
+	}
+	if v.audit.strict {
+		return fmt.Errorf("%w: the change was made but not recorded: %w", ErrAuditFailed, err)
+	}
+	v.logger.Warn("writing audit log failed", "path", entries[0].Path, "error", err)
+	if call != nil {
+		call.warn(fmt.Sprintf("the change to %s was made but could not be recorded in the audit log", entries[0].Path))
+	}
+	return nil
+}
+
+// open opens the log for appending, creating it and its directory
+// Caller must hold mu
+func (l *auditLog) open() (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(l.file), 0755); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(l.file, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+}
+
+// append writes lines to the log, rotating it first if they would take
+// it past maxBytes
+func (l *auditLog) append(lines []byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if stat, err := os.Stat(l.file); err == nil && stat.Size() > 0 && stat.Size()+int64(len(lines)) > l.maxBytes {
+		if err := l.rotate(); err != nil {
+			return fmt.Errorf("rotating audit log: %w", err)
+		}
+	}
+
+	f, err := l.open()
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(lines); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// rotate shifts the log to audit.log.1 and older logs one place up,
+// dropping the oldest
+// Caller must hold mu
+func (l *auditLog) rotate() error {
+	for i := auditKeep - 1; i >= 1; i-- {
+		err := os.Rename(l.rotated(i), l.rotated(i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Rename(l.file, l.rotated(1))
+}
+
+// rotated returns the path of the i-th rotated log, the log itself for 0
+func (l *auditLog) rotated(i int) string {
+	if i == 0 {
+		return l.file
+	}
+	return fmt.Sprintf("%s.%d", l.file, i)
+}
+
+// AuditLog returns the recorded changes selected by q, newest first,
+// from the log and its rotated predecessors. Lines that do not parse are
+// skipped.
+func (v *vault) AuditLog(ctx context.Context, q AuditQuery) ([]AuditEntry, error) {
+	if err := v.checkAvailable(); err != nil {
+		return nil, err
+	}
+	folder := ""
+	if q.Path != "" {
+		cleaned, err := cleanVaultPath(q.Path)
+		if err != nil {
+			return nil, err
+		}
+		if cleaned != rootFolder {
+			folder = cleaned
+		}
+	}
+	inFolder := func(p string) bool {
+		return p != "" && (folder == "" || p == folder || strings.HasPrefix(p, folder+"/"))
+	}
+
+	entries := []AuditEntry{}
+	for i := 0; i <= auditKeep; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		fileEntries, err := readAuditFile(v.audit.rotated(i))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audit log: %w", err)
+		}
+		for _, entry := range slices.Backward(fileEntries) {
+			if !q.Since.IsZero() && entry.Time.Before(q.Since) {
+				continue
+			}
+			if !q.Until.IsZero() && !entry.Time.Before(q.Until) {
+				continue
+			}
+			if !inFolder(entry.Path) && !inFolder(entry.NewPath) {
+				continue
+			}
+			entries = append(entries, entry)
+			if q.Limit > 0 && len(entries) == q.Limit {
+				return entries, nil
+			}
+		}
+	}
+	return entries, nil
+}
+
+// readAuditFile parses the entries of one log file in the order written,
+// none if it does not exist
+func readAuditFile(file string) ([]AuditEntry, error) {
+	f, err := os.Open(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if json.Unmarshal(scanner.Bytes(), &entry) == nil && entry.Op != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
@@ -0,0 +1,185 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// auditOps returns the operation and path of each entry
+func auditOps(entries []AuditEntry) []string {
+	ops := make([]string, len(entries))
+	for i, entry := range entries {
+		ops[i] = string(entry.Op) + " " + entry.Path
+		if entry.NewPath != "" {
+			ops[i] += " " + entry.NewPath
+		}
+	}
+	return ops
+}
+
+func TestAuditLog(t *testing.T) {
+	v, _ := setupTestVault(t)
+	call := &AuditCall{Tool: "update_note"}
+	ctx := AuditContext(HolderContext(context.Background(), "alice", false), call)
+	start := time.Now().Add(-time.Second)
+
+	if err := v.Create(ctx, "new.md", "hello"); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := v.Update(ctx, "new.md", "hello again"); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if _, err := v.ApplyEdits(ctx, BatchOptions{Edits: []Edit{
+		{Op: EditMove, Path: "new.md", NewPath: "subdir/new.md"},
+		{Op: EditDelete, Path: "note2.md"},
+	}}); err != nil {
+		t.Fatalf("ApplyEdits() error = %v", err)
+	}
+	// Dry runs and failed writes are not recorded
+	if _, err := v.ApplyEdits(ctx, BatchOptions{Edits: []Edit{{Op: EditDelete, Path: "note1.md"}}, DryRun: true}); err != nil {
+		t.Fatalf("ApplyEdits() dry run error = %v", err)
+	}
+	if err := v.Update(ctx, "missing.md", "x"); !errors.Is(err, ErrNoteNotFound) {
+		t.Fatalf("Update() missing error = %v", err)
+	}
+
+	entries, err := v.AuditLog(context.Background(), AuditQuery{})
+	if err != nil {
+		t.Fatalf("AuditLog() error = %v", err)
+	}
+	got := auditOps(entries)
+	want := []string{"delete note2.md", "move new.md subdir/new.md", "update new.md", "create new.md"}
+	if len(got) != len(want) {
+		t.Fatalf("AuditLog() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("AuditLog()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	created, updated := entries[3], entries[2]
+	if created.PrevHash != "" || created.Hash != contentHash("hello") || created.Bytes != len("hello") {
+		t.Errorf("create entry = %+v", created)
+	}
+	if updated.PrevHash != created.Hash || updated.Hash != contentHash("hello again") {
+		t.Errorf("update entry = %+v, want the create's hash before", updated)
+	}
+	if moved := entries[1]; moved.PrevHash != updated.Hash || moved.Hash != updated.Hash {
+		t.Errorf("move entry = %+v, want unchanged hashes", moved)
+	}
+	if deleted := entries[0]; deleted.PrevHash == "" || deleted.Hash != "" || deleted.Bytes != 0 {
+		t.Errorf("delete entry = %+v", deleted)
+	}
+	for _, entry := range entries {
+		if entry.Tool != "update_note" || entry.Client != "alice" || entry.Time.Before(start) {
+			t.Errorf("entry = %+v, want tool, client and time set", entry)
+		}
+	}
+	if warnings := call.Warnings(); len(warnings) != 0 {
+		t.Errorf("Warnings() = %v, want none", warnings)
+	}
+
+	// Filters
+	tests := []struct {
+		name  string
+		query AuditQuery
+		want  int
+	}{
+		{"limit", AuditQuery{Limit: 2}, 2},
+		{"note", AuditQuery{Path: "new.md"}, 3},
+		{"folder catches moves into it", AuditQuery{Path: "subdir"}, 1},
+		{"since", AuditQuery{Since: time.Now().Add(time.Hour)}, 0},
+		{"until", AuditQuery{Until: start}, 0},
+		{"range", AuditQuery{Since: start, Until: time.Now().Add(time.Second)}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := v.AuditLog(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("AuditLog() error = %v", err)
+			}
+			if len(entries) != tt.want {
+				t.Errorf("AuditLog() = %v, want %d entries", auditOps(entries), tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditLogRotation(t *testing.T) {
+	_, tmpDir := setupTestVault(t)
+	file := filepath.Join(t.TempDir(), "audit.log")
+	v, err := NewVault(tmpDir, WithAuditLog(file, 300))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	ctx := context.Background()
+
+	// Each entry is around 250 bytes, so every write rotates
+	for i := range 6 {
+		if err := v.Update(ctx, "note1.md", string(rune('a'+i))); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+	}
+	for i := 1; i <= auditKeep; i++ {
+		if _, err := os.Stat(fmt.Sprintf("%s.%d", file, i)); err != nil {
+			t.Errorf("rotated log %d missing: %v", i, err)
+		}
+	}
+	if _, err := os.Stat(fmt.Sprintf("%s.%d", file, auditKeep+1)); !os.IsNotExist(err) {
+		t.Errorf("more than %d rotated logs kept", auditKeep)
+	}
+
+	entries, err := v.AuditLog(ctx, AuditQuery{})
+	if err != nil {
+		t.Fatalf("AuditLog() error = %v", err)
+	}
+	if len(entries) != auditKeep+1 {
+		t.Fatalf("AuditLog() returned %d entries, want %d", len(entries), auditKeep+1)
+	}
+	if entries[0].Hash != contentHash("f") {
+		t.Errorf("newest entry = %+v, want the last update", entries[0])
+	}
+}
+
+func TestAuditLogFailure(t *testing.T) {
+	_, tmpDir := setupTestVault(t)
+	// A file in place of the log's folder makes every append fail
+	blocker := filepath.Join(t.TempDir(), "blocker")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(blocker, "audit.log")
+
+	v, err := NewVault(tmpDir, WithAuditLog(file, 0))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	call := &AuditCall{Tool: "update_note"}
+	if err := v.Update(AuditContext(context.Background(), call), "note1.md", "changed"); err != nil {
+		t.Fatalf("Update() error = %v, want the change made", err)
+	}
+	if warnings := call.Warnings(); len(warnings) != 1 {
+		t.Errorf("Warnings() = %v, want one", warnings)
+	}
+
+	strict, err := NewVault(tmpDir, WithAuditLog(file, 0), WithStrictAudit(true))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	if err := strict.Update(context.Background(), "note1.md", "strict"); !errors.Is(err, ErrAuditFailed) {
+		t.Errorf("strict Update() error = %v, want ErrAuditFailed", err)
+	}
+	if err := strict.Create(context.Background(), "strict.md", "strict"); !errors.Is(err, ErrAuditFailed) {
+		t.Errorf("strict Create() error = %v, want ErrAuditFailed", err)
+	}
+	content, err := os.ReadFile(filepath.Join(tmpDir, "note1.md"))
+	if err != nil || string(content) != "changed" {
+		t.Errorf("note1.md = %q, %v, want it left unchanged by the strict vault", content, err)
+	}
+}
@@ -0,0 +1,134 @@
+package vault
+
+import (
+	"context"
+	"maps"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// MaxReconnectPaths bounds the notes listed as appeared or disappeared by
+// Reconnect; the totals count them all
+const MaxReconnectPaths = 100
+
+// availability tracks whether the vault directory could be reached at the
+// last check
+type availability struct {
+	down atomic.Bool
+	mu   sync.Mutex // Serializes the transitions
+}
+
+// Reconnection reports what Reconnect found after flushing the cache
+type Reconnection struct {
+	Notes            int      `json:"notes"`             // Notes in the vault now
+	Appeared         []string `json:"appeared"`          // Notes the server did not know of, at most MaxReconnectPaths
+	Disappeared      []string `json:"disappeared"`       // Notes the server knew of that are gone, at most MaxReconnectPaths
+	AppearedTotal    int      `json:"appeared_total"`    // Notes that appeared
+	DisappearedTotal int      `json:"disappeared_total"` // Notes that disappeared
+	CacheFlushed     int      `json:"cache_flushed"`     // Cache entries dropped
+	IndexFlushed     int      `json:"index_flushed"`     // Notes dropped from the search index
+}
+
+// checkAvailable returns ErrVaultUnavailable when the vault directory is
+// gone or no longer a directory, such as when the drive holding it was
+// unmounted or a sync tool moved it away. It costs one stat. The first
+// check finding the directory back flushes the cache, the search index
+// and the path listing before any call can use them, as notes may have
+// changed without their modification times telling.
+func (v *vault) checkAvailable() error {
+	stat, err := os.Stat(v.basePath)
+	up := err == nil && stat.IsDir()
+	if up && !v.liveness.down.Load() {
+		return nil
+	}
+
+	v.liveness.mu.Lock()
+	defer v.liveness.mu.Unlock()
+	switch down := v.liveness.down.Load(); {
+	case !up && !down:
+		v.liveness.down.Store(true)
+		v.logger.Warn("vault became unavailable", "error", err)
+	case up && down:
+		cached, indexed := v.flush()
+		v.liveness.down.Store(false)
+		v.logger.Info("vault available again, cache flushed", "cache_entries", cached, "indexed_notes", indexed)
+	}
+	if !up {
+		return ErrVaultUnavailable
+	}
+	return nil
+}
+
+// flush drops every cached note, the search index and the path listing,
+// returning the cache entries and indexed notes dropped
+func (v *vault) flush() (cached, indexed int) {
+	cached = v.cache.CacheStats().Entries
+	v.cache.Clear()
+	if v.index != nil {
+		indexed = v.index.reset()
+	}
+	v.paths.invalidate()
+	return cached, indexed
+}
+
+// knownNotes returns the vault-relative paths of the notes the server
+// knows of: those of the last path listing, the cache and the index
+func (v *vault) knownNotes() map[string]struct{} {
+	known := make(map[string]struct{})
+	v.paths.mu.Lock()
+	for _, c := range v.paths.candidates {
+		known[c.path] = struct{}{}
+	}
+	v.paths.mu.Unlock()
+
+	for path := range v.cache.Stamps() {
+		known[v.relPath(path)] = struct{}{}
+	}
+	if v.index != nil {
+		for path := range v.index.stamps() {
+			known[v.relPath(path)] = struct{}{}
+		}
+	}
+	return known
+}
+
+// Reconnect flushes the cache, the search index and the path listing
+// whether or not the vault directory went away, lists the notes again and
+// reports those that appeared or disappeared compared with what the
+// server knew of before. It fails with ErrVaultUnavailable while the
+// directory cannot be reached.
+func (v *vault) Reconnect(ctx context.Context) (Reconnection, error) {
+	known := v.knownNotes()
+	result := Reconnection{CacheFlushed: v.cache.CacheStats().Entries}
+	if v.index != nil {
+		result.IndexFlushed = v.index.stats().Notes
+	}
+
+	if err := v.checkAvailable(); err != nil {
+		return Reconnection{}, err
+	}
+	v.flush()
+	candidates, err := v.noteCandidates(ctx)
+	if err != nil {
+		return Reconnection{}, err
+	}
+
+	var appeared []string
+	for _, c := range candidates {
+		if _, ok := known[c.path]; ok {
+			delete(known, c.path)
+		} else {
+			appeared = append(appeared, c.path)
+		}
+	}
+	slices.Sort(appeared)
+	disappeared := slices.Sorted(maps.Keys(known))
+
+	result.Notes = len(candidates)
+	result.AppearedTotal, result.DisappearedTotal = len(appeared), len(disappeared)
+	result.Appeared = append([]string{}, appeared[:min(len(appeared), MaxReconnectPaths)]...)
+	result.Disappeared = append([]string{}, disappeared[:min(len(disappeared), MaxReconnectPaths)]...)
+	return result, nil
+}
@@ -0,0 +1,86 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestVaultUnavailable(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := filepath.Join(t.TempDir(), "vault")
+	writeFiles(t, tmpDir, map[string]string{
+		"a.md":          "# A\nfirst",
+		"b.md":          "# B",
+		"Projects/p.md": "# P",
+	})
+	v, err := NewVault(tmpDir, WithSearchIndex())
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	if _, err := v.Search(ctx, SearchOptions{Query: "first"}); err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if _, err := v.FindNote(ctx, FuzzyOptions{Query: "a"}); err != nil {
+		t.Fatalf("FindNote() error = %v", err)
+	}
+
+	// The drive is unmounted: every call fails the same way
+	moved := tmpDir + ".away"
+	if err := os.Rename(tmpDir, moved); err != nil {
+		t.Fatal(err)
+	}
+	calls := map[string]func() error{
+		"Read":      func() error { _, err := v.Read(ctx, "a.md"); return err },
+		"List":      func() error { _, err := v.List(ctx, ListOptions{Recursive: true}); return err },
+		"Search":    func() error { _, err := v.Search(ctx, SearchOptions{Query: "first"}); return err },
+		"Create":    func() error { return v.Create(ctx, "c.md", "# C") },
+		"FindNote":  func() error { _, err := v.FindNote(ctx, FuzzyOptions{Query: "a"}); return err },
+		"Subfolder": func() error { _, err := v.List(ctx, ListOptions{Subpath: "Projects"}); return err },
+		"Reconnect": func() error { _, err := v.Reconnect(ctx); return err },
+	}
+	for name, call := range calls {
+		if err := call(); !errors.Is(err, ErrVaultUnavailable) {
+			t.Errorf("%s() error = %v, want ErrVaultUnavailable", name, err)
+		}
+	}
+
+	// Meanwhile a note changes without its time telling, one goes and one
+	// comes
+	stat, err := os.Stat(filepath.Join(moved, "a.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, moved, map[string]string{"a.md": "# A\nfresh", "c.md": "# C"})
+	if err := os.Chtimes(filepath.Join(moved, "a.md"), time.Now(), stat.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(moved, "b.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Once it is back the cache and index are flushed before they are used
+	if err := os.Rename(moved, tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	content, err := v.Read(ctx, "a.md")
+	if err != nil || content != "# A\nfresh" {
+		t.Errorf("Read() = %q, %v, want the content changed meanwhile", content, err)
+	}
+
+	reconnection, err := v.Reconnect(ctx)
+	if err != nil {
+		t.Fatalf("Reconnect() error = %v", err)
+	}
+	want := Reconnection{Notes: 3, Appeared: []string{"c.md"}, Disappeared: []string{"b.md"}, AppearedTotal: 1, DisappearedTotal: 1, CacheFlushed: 1, IndexFlushed: 1}
+	if !reflect.DeepEqual(reconnection, want) {
+		t.Errorf("Reconnect() = %+v, want %+v", reconnection, want)
+	}
+	if notes, err := v.Search(ctx, SearchOptions{Query: "fresh"}); err != nil || len(notes) != 1 {
+		t.Errorf("Search() = %v, %v, want a.md", notes, err)
+	}
+}
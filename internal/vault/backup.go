@@ -0,0 +1,222 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Locations of server-managed data inside the vault
+const (
+	dataDir   = ".mcp-notes"
+	backupDir = "backups"
+)
+
+// defaultBackupVersions is the number of versions kept per note by default
+const defaultBackupVersions = 5
+
+// versionTimeFormat names backup files; it sorts lexically in time order
+const versionTimeFormat = "20060102T150405.000000000Z"
+
+// NoteVersion describes a backed up version of a note
+type NoteVersion struct {
+	ID      string    `json:"id"`      // Version identifier used for restoring
+	Created time.Time `json:"created"` // When the backup was taken
+	Size    int64     `json:"size"`    // Size of the backed up content in bytes
+}
+
+// WithBackups sets how many previous versions are kept for each note
+// before it is overwritten. Zero or less disables backups.
+func WithBackups(keep int) Option {
+	return func(v *vault) {
+		v.backupVersions = keep
+	}
+}
+
+// isDataPath reports whether fullPath is inside the server data directory
+func (v *vault) isDataPath(fullPath string) bool {
+	return isWithin(fullPath, filepath.Join(v.basePath, dataDir))
+}
+
+// backupPath returns the directory holding versions of the note at relPath
+func (v *vault) backupPath(relPath string) string {
+	return filepath.Join(v.basePath, dataDir, backupDir, relPath)
+}
+
+// backup copies the current content of the note at fullPath into the
+// backup directory and prunes versions beyond the configured limit
+// Does nothing when backups are disabled or the note does not exist
+func (v *vault) backup(fullPath string) error {
+	if v.backupVersions <= 0 {
+		return nil
+	}
+
+	data, err := os.ReadFile(fullPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read note for backup: %w", err)
+	}
+
+	dir := v.backupPath(v.relPath(fullPath))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	name := time.Now().UTC().Format(versionTimeFormat) + ".md"
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	return v.pruneBackups(dir)
+}
+
+// pruneBackups removes the oldest versions in dir beyond the limit
+func (v *vault) pruneBackups(dir string) error {
+	versions, err := readVersions(dir)
+	if err != nil {
+		return err
+	}
+
+	// Versions are sorted newest first
+	for i := v.backupVersions; i < len(versions); i++ {
+		if err := os.Remove(filepath.Join(dir, versions[i].ID+".md")); err != nil {
+			return fmt.Errorf("failed to prune backup: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// readVersions lists the versions stored in dir, newest first
+func readVersions(dir string) ([]NoteVersion, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []NoteVersion{}, nil
+		}
+		return nil, fmt.Errorf("failed to read backups: %w", err)
+	}
+
+	versions := []NoteVersion{}
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".md")
+		if entry.IsDir() || !ok {
+			continue
+		}
+
+		created, err := time.Parse(versionTimeFormat, id)
+		if err != nil {
+			continue // Not a backup file
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		versions = append(versions, NoteVersion{ID: id, Created: created, Size: info.Size()})
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].ID > versions[j].ID
+	})
+
+	return versions, nil
+}
+
+// ListVersions returns the backed up versions of a note, newest first
+func (v *vault) ListVersions(ctx context.Context, path string) ([]NoteVersion, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	fullPath, err := v.validatePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return readVersions(v.backupPath(v.relPath(fullPath)))
+}
+
+// RestoreVersion replaces a note's content with a backed up version
+// The current content is itself backed up first, so a restore can be undone
+func (v *vault) RestoreVersion(ctx context.Context, path, versionID string) error {
+	fullPath, err := v.validatePath(path)
+	if err != nil {
+		return err
+	}
+
+	unlock := v.writeLocks.lock(fullPath)
+	defer unlock()
+
+	if err := v.checkWritable(fullPath); err != nil {
+		return err
+	}
+	if err := v.checkLeases(ctx, fullPath); err != nil {
+		return err
+	}
+	if err := v.checkAudit(); err != nil {
+		return err
+	}
+
+	// Version IDs are timestamps; reject anything else before touching disk
+	if _, err := time.Parse(versionTimeFormat, versionID); err != nil {
+		return ErrVersionNotFound
+	}
+
+	data, err := os.ReadFile(filepath.Join(v.backupPath(v.relPath(fullPath)), versionID+".md"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ErrVersionNotFound
+		}
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	// Hash the content being replaced for the audit log
+	entry := AuditEntry{Op: EditUpdate, Path: v.relPath(fullPath)}
+	if existing, err := os.ReadFile(fullPath); err == nil {
+		previous, _, err := v.decodeNote(existing)
+		if err != nil {
+			previous = string(existing)
+		}
+		entry.PrevHash = contentHash(previous)
+	}
+
+	if err := v.backup(fullPath); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directories: %w", err)
+	}
+
+	if err := os.WriteFile(fullPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	// Update cache; versions are byte-for-byte copies and decode like the note
+	content, _, err := v.decodeNote(data)
+	if err != nil {
+		v.cache.Delete(fullPath)
+		content = string(data)
+	} else {
+		v.cacheWritten(fullPath, content)
+	}
+	entry.Bytes, entry.Hash = len(content), contentHash(content)
+
+	return v.record(ctx, entry)
+}
@@ -0,0 +1,177 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUpdateBackups(t *testing.T) {
+	v, tmpDir := setupTestVault(t)
+	ctx := context.Background()
+
+	if err := v.Update(ctx, "note1.md", "Second version"); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	versions, err := v.ListVersions(ctx, "note1.md")
+	if err != nil {
+		t.Fatalf("ListVersions() error = %v", err)
+	}
+	if len(versions) != 1 {
+		t.Fatalf("Expected 1 version, got %d", len(versions))
+	}
+
+	backup := filepath.Join(tmpDir, dataDir, backupDir, "note1.md", versions[0].ID+".md")
+	data, err := os.ReadFile(backup)
+	if err != nil {
+		t.Fatalf("Failed to read backup: %v", err)
+	}
+	if !strings.Contains(string(data), "note 1") {
+		t.Errorf("Backup content = %q, want original content", string(data))
+	}
+
+	t.Run("restore previous version", func(t *testing.T) {
+		if err := v.RestoreVersion(ctx, "note1.md", versions[0].ID); err != nil {
+			t.Fatalf("RestoreVersion() error = %v", err)
+		}
+
+		content, err := v.Read(ctx, "note1.md")
+		if err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+		if !strings.Contains(content, "note 1") {
+			t.Errorf("Content = %q, want original content", content)
+		}
+
+		// Restoring backed up "Second version" as well
+		versions, err := v.ListVersions(ctx, "note1.md")
+		if err != nil {
+			t.Fatalf("ListVersions() error = %v", err)
+		}
+		if len(versions) != 2 {
+			t.Errorf("Expected 2 versions after restore, got %d", len(versions))
+		}
+	})
+
+	t.Run("restore unknown version", func(t *testing.T) {
+		err := v.RestoreVersion(ctx, "note1.md", "20000101T000000.000000000Z")
+		if !errors.Is(err, ErrVersionNotFound) {
+			t.Errorf("Expected ErrVersionNotFound, got %v", err)
+		}
+
+		err = v.RestoreVersion(ctx, "note1.md", "../../note2")
+		if !errors.Is(err, ErrVersionNotFound) {
+			t.Errorf("Expected ErrVersionNotFound for malformed id, got %v", err)
+		}
+	})
+
+	t.Run("backups hidden from walks", func(t *testing.T) {
+		notes, err := v.List(ctx, ListOptions{Recursive: true})
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		for _, note := range notes {
+			if strings.HasPrefix(note.Path, dataDir) {
+				t.Errorf("Backup listed: %s", note.Path)
+			}
+		}
+
+		results, err := v.Search(ctx, SearchOptions{Query: "Second version"})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		for _, note := range results {
+			if strings.HasPrefix(note.Path, dataDir) {
+				t.Errorf("Backup found by search: %s", note.Path)
+			}
+		}
+	})
+
+	t.Run("backups not addressable as notes", func(t *testing.T) {
+		_, err := v.Read(ctx, filepath.Join(dataDir, backupDir, "note1.md", versions[0].ID+".md"))
+		if !errors.Is(err, ErrReservedPath) {
+			t.Errorf("Expected ErrReservedPath, got %v", err)
+		}
+	})
+}
+
+func TestBackupPruning(t *testing.T) {
+	tmpDir := t.TempDir()
+	v, err := NewVault(tmpDir, WithBackups(2))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	ctx := context.Background()
+
+	if err := v.Create(ctx, "note.md", "v0"); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	for _, content := range []string{"v1", "v2", "v3", "v4"} {
+		if err := v.Update(ctx, "note.md", content); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+	}
+
+	versions, err := v.ListVersions(ctx, "note.md")
+	if err != nil {
+		t.Fatalf("ListVersions() error = %v", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("Expected 2 versions, got %d", len(versions))
+	}
+
+	// Newest backup holds v3, the content before the last update
+	data, err := os.ReadFile(filepath.Join(tmpDir, dataDir, backupDir, "note.md", versions[0].ID+".md"))
+	if err != nil {
+		t.Fatalf("Failed to read backup: %v", err)
+	}
+	if string(data) != "v3" {
+		t.Errorf("Newest backup = %q, want v3", string(data))
+	}
+}
+
+func TestBackupsDisabled(t *testing.T) {
+	tmpDir := t.TempDir()
+	v, err := NewVault(tmpDir, WithBackups(0))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	ctx := context.Background()
+
+	if err := v.Create(ctx, "note.md", "v0"); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := v.Update(ctx, "note.md", "v1"); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpDir, dataDir, backupDir)); !os.IsNotExist(err) {
+		t.Errorf("Expected no backup directory, got %v", err)
+	}
+}
+
+func TestBackupFailureFailsUpdate(t *testing.T) {
+	v, tmpDir := setupTestVault(t)
+	ctx := context.Background()
+
+	// A file where the data directory should be makes backups impossible
+	if err := os.WriteFile(filepath.Join(tmpDir, dataDir), []byte("blocker"), 0644); err != nil {
+		t.Fatalf("Failed to create blocker: %v", err)
+	}
+
+	if err := v.Update(ctx, "note1.md", "Overwritten"); err == nil {
+		t.Fatal("Expected Update() to fail when backup cannot be written")
+	}
+
+	content, err := v.Read(ctx, "note1.md")
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if content == "Overwritten" {
+		t.Error("Note was overwritten without a backup")
+	}
+}
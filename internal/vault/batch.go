@@ -0,0 +1,83 @@
+package vault
+
+import (
+	"context"
+	"sync"
+)
+
+// maxBatchWorkers bounds the parallel reads of a single ReadMany call
+const maxBatchWorkers = 4
+
+// NoteContent is the outcome of reading one note in a batch
+type NoteContent struct {
+	Path      string // Path as requested
+	Content   string // Note content, empty on error or truncation
+	Err       error  // Why the note could not be read
+	Truncated bool   // Content left out because the byte budget was spent
+}
+
+// ReadMany reads several notes concurrently through the cache, returning
+// one result per path in the same order. Failures are reported per note.
+// Once the combined content would exceed maxBytes, that note and all
+// following ones are returned as Truncated; maxBytes <= 0 disables the
+// budget. Only cancelling ctx fails the whole batch.
+func (v *vault) ReadMany(ctx context.Context, paths []string, maxBytes int) ([]NoteContent, error) {
+	results := make([]NoteContent, len(paths))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var panics workerPanics
+
+	for range min(maxBatchWorkers, v.concurrency, len(paths)) {
+		wg.Go(func() {
+			for i := range jobs {
+				if ctx.Err() != nil {
+					continue // Drain remaining jobs without doing work
+				}
+
+				// Each worker writes only its own indices, so no locking is needed
+				panics.run(func() {
+					content, err := v.Read(ctx, paths[i])
+					results[i] = NoteContent{Path: paths[i], Content: content, Err: err}
+				})
+			}
+		})
+	}
+
+feed:
+	for i := range paths {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	panics.repanic()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if maxBytes <= 0 {
+		return results, nil
+	}
+
+	// Apply the budget in request order so truncation is deterministic
+	total := 0
+	for i := range results {
+		if total+len(results[i].Content) > maxBytes {
+			for j := i; j < len(results); j++ {
+				if results[j].Err == nil {
+					results[j].Content = ""
+					results[j].Truncated = true
+				}
+			}
+			break
+		}
+		total += len(results[i].Content)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,73 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestReadMany(t *testing.T) {
+	v, _ := setupTestVault(t)
+	ctx := context.Background()
+
+	t.Run("per note results in order", func(t *testing.T) {
+		paths := []string{"subdir/note3.md", "missing.md", "note1.md", "../outside.md"}
+		results, err := v.ReadMany(ctx, paths, 0)
+		if err != nil {
+			t.Fatalf("ReadMany() error = %v", err)
+		}
+		if len(results) != len(paths) {
+			t.Fatalf("Got %d results, want %d", len(results), len(paths))
+		}
+
+		for i, r := range results {
+			if r.Path != paths[i] {
+				t.Errorf("results[%d].Path = %s, want %s", i, r.Path, paths[i])
+			}
+		}
+		if results[0].Content != "This is note 3 in subdir with #tag1" || results[0].Err != nil {
+			t.Errorf("results[0] = %+v", results[0])
+		}
+		if !errors.Is(results[1].Err, ErrNoteNotFound) {
+			t.Errorf("Expected ErrNoteNotFound, got %v", results[1].Err)
+		}
+		if results[2].Err != nil {
+			t.Errorf("results[2].Err = %v", results[2].Err)
+		}
+		if !errors.Is(results[3].Err, ErrPathTraversal) {
+			t.Errorf("Expected ErrPathTraversal, got %v", results[3].Err)
+		}
+	})
+
+	t.Run("byte budget truncates remaining notes", func(t *testing.T) {
+		// Each test note is 35 or 36 bytes; the budget fits exactly two
+		paths := []string{"note1.md", "missing.md", "note2.md", "subdir/note3.md", "other/note5.md"}
+		results, err := v.ReadMany(ctx, paths, 72)
+		if err != nil {
+			t.Fatalf("ReadMany() error = %v", err)
+		}
+
+		wantTruncated := []bool{false, false, false, true, true}
+		for i, r := range results {
+			if r.Truncated != wantTruncated[i] {
+				t.Errorf("results[%d].Truncated = %v, want %v", i, r.Truncated, wantTruncated[i])
+			}
+			if r.Truncated && r.Content != "" {
+				t.Errorf("results[%d] truncated but has content", i)
+			}
+		}
+		if results[1].Err == nil || results[1].Truncated {
+			t.Errorf("Failed reads keep their error: %+v", results[1])
+		}
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := v.ReadMany(ctx, []string{"note1.md"}, 0)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	})
+}
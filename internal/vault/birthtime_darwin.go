@@ -0,0 +1,19 @@
+package vault
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// birthTime returns the file creation time from the platform stat data
+func birthTime(fullPath string) (time.Time, bool) {
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return time.Unix(stat.Birthtimespec.Unix()), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,20 @@
+package vault
+
+import (
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// birthTime returns the file creation time using statx
+// Reports false when the kernel or filesystem does not record it
+func birthTime(fullPath string) (time.Time, bool) {
+	var stx unix.Statx_t
+	if err := unix.Statx(unix.AT_FDCWD, fullPath, 0, unix.STATX_BTIME, &stx); err != nil {
+		return time.Time{}, false
+	}
+	if stx.Mask&unix.STATX_BTIME == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(stx.Btime.Sec, int64(stx.Btime.Nsec)), true
+}
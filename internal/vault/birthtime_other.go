@@ -0,0 +1,11 @@
+//go:build !darwin && !linux && !windows
+
+package vault
+
+import "time"
+
+// birthTime reports false on platforms whose stat data does not expose
+// file creation time
+func birthTime(fullPath string) (time.Time, bool) {
+	return time.Time{}, false
+}
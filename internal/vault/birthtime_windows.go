@@ -0,0 +1,19 @@
+package vault
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// birthTime returns the file creation time from the platform stat data
+func birthTime(fullPath string) (time.Time, bool) {
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if attrs, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		return time.Unix(0, attrs.CreationTime.Nanoseconds()), true
+	}
+	return time.Time{}, false
+}
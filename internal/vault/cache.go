@@ -1,16 +1,50 @@
 package vault
 
 import (
+	"container/list"
+	"maps"
 	"os"
 	"sync"
 	"time"
+
+	"github.com/kratos/mcp-notes/internal/metrics"
 )
 
 // CacheEntry represents a cached note with its metadata
 type CacheEntry struct {
-	Content string    // File content
-	Tags    []string  // Extracted tags
-	Mtime   time.Time // File modification time
+	Content        string         // File content
+	Tags           []string       // Extracted tags
+	Links          []Link         // Parsed outgoing links
+	Tasks          []Task         // Checkbox list items; task tags are shared, treat as read-only
+	Headings       []Heading      // Heading outline with section word counts
+	Blocks         []Block        // Blocks marked with ^block-id
+	Title          string         // Frontmatter title, if any
+	Aliases        []string       // Frontmatter aliases
+	Properties     map[string]any // Parsed frontmatter; nested values are shared, treat as read-only
+	Mtime          time.Time      // File modification time
+	Created        time.Time      // Resolved creation time, zero if unknown
+	ContentHash    string         // SHA-256 of the note's text, or of the canvas JSON, in hex
+	ContentOmitted bool           // Content was too large to cache; read it from disk
+	Blob           bool           // Mostly embedded data; metadata comes from Prose
+	Prose          string         // A blob's content without its data, kept in the cache
+}
+
+// searchText returns the text searches match: the prose of a blob, the
+// whole content otherwise
+func (e CacheEntry) searchText() string {
+	if e.Blob {
+		return e.Prose
+	}
+	return e.Content
+}
+
+// CacheStats reports cache usage counters
+type CacheStats struct {
+	Entries   int    `json:"entries"`   // Number of cached notes
+	Bytes     int64  `json:"bytes"`     // Total size of cached content
+	Hits      uint64 `json:"hits"`      // Successful lookups
+	Misses    uint64 `json:"misses"`    // Lookups of absent or stale entries
+	Evictions uint64 `json:"evictions"` // Entries evicted to honor limits
 }
 
 // CacheInterface defines the contract for note caching
@@ -18,39 +52,104 @@ type CacheEntry struct {
 type CacheInterface interface {
 	// Get retrieves a cache entry if it exists and is valid
 	Get(path string) (CacheEntry, bool)
+	// Peek retrieves the content and stamps of a cache entry without
+	// validating them against disk, so it may hold an earlier version
+	Peek(path string) (CacheEntry, bool)
+	// Metadata returns the tags, title, aliases, headings and frontmatter
+	// of a cache entry without its content or validating it against disk, like Peek
+	Metadata(path string) (CacheEntry, bool)
 	// Set stores a cache entry with the given metadata
 	Set(path string, content string, tags []string, mtime time.Time)
+	// SetEntry stores a complete cache entry including parsed metadata
+	SetEntry(path string, entry CacheEntry)
 	// Delete removes a cache entry
 	Delete(path string)
+	// Rename moves the entry for oldPath to newPath, replacing any entry there
+	Rename(oldPath, newPath string)
+	// Clear removes every entry, keeping the counters and pinned paths
+	Clear()
+	// Pin exempts the entries of paths from eviction, in place of the
+	// paths given before
+	Pin(paths []string)
+	// CacheStats returns current usage counters
+	CacheStats() CacheStats
+	// Stamps returns the modification time and content hash of every
+	// entry, without validating them against disk
+	Stamps() map[string]CacheStamp
+}
+
+// CacheStamp identifies the version of a note held by a cache or index
+type CacheStamp struct {
+	Mtime       time.Time
+	ContentHash string
 }
 
 // Cache provides thread-safe caching of note content and metadata
 // Cache entries are validated against file modification time
+// When limits are set, least recently used entries are evicted
 type Cache struct {
-	mu      sync.RWMutex
-	entries map[string]CacheEntry
+	mu         sync.RWMutex
+	entries    map[string]*list.Element // Values are *cacheItem
+	lru        *list.List               // Front is most recently used
+	maxBytes   int64                    // Content byte limit, 0 for unlimited
+	maxEntries int                      // Entry count limit, 0 for unlimited
+	pinned     map[string]bool          // Paths never evicted
+	bytes      int64
+	hits       uint64
+	misses     uint64
+	evictions  uint64
+	metrics    metrics.Metrics // Also receives hits, misses and evictions
+}
+
+// cacheItem is the value stored in the LRU list
+type cacheItem struct {
+	path  string
+	entry CacheEntry
 }
 
 // Ensure Cache implements CacheInterface
 var _ CacheInterface = (*Cache)(nil)
 
-// NewCache creates a new cache instance
+// NewCache creates a new unbounded cache instance
 func NewCache() *Cache {
+	return NewBoundedCache(0, 0)
+}
+
+// NewBoundedCache creates a cache holding at most maxBytes of content and
+// maxEntries notes. A zero limit disables that bound.
+// A single note larger than maxBytes is cached without its content.
+func NewBoundedCache(maxBytes int64, maxEntries int) *Cache {
 	return &Cache{
-		entries: make(map[string]CacheEntry),
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+		maxBytes:   maxBytes,
+		maxEntries: maxEntries,
+		metrics:    metrics.Nop{},
 	}
 }
 
+// SetMetrics makes the cache record its hits, misses and evictions in m as
+// well as in CacheStats. Call it before the cache is shared.
+func (c *Cache) SetMetrics(m metrics.Metrics) {
+	c.metrics = m
+}
+
 // Get retrieves a cache entry if it exists and is valid
 // Returns the entry and true if found and valid, otherwise empty entry and false
 // Validates cache freshness by comparing modification times
+// A successful Get marks the entry as most recently used
 func (c *Cache) Get(path string) (CacheEntry, bool) {
 	c.mu.RLock()
-	entry, exists := c.entries[path]
+	elem, exists := c.entries[path]
+	var entry CacheEntry
+	if exists {
+		entry = elem.Value.(*cacheItem).entry
+	}
 	entryMtime := entry.Mtime
 	c.mu.RUnlock()
 
 	if !exists {
+		c.recordMiss()
 		return CacheEntry{}, false
 	}
 
@@ -59,9 +158,9 @@ func (c *Cache) Get(path string) (CacheEntry, bool) {
 	if err != nil {
 		// Re-acquire lock to verify entry hasn't changed, then delete
 		c.mu.Lock()
-		if current, stillExists := c.entries[path]; stillExists && current.Mtime.Equal(entryMtime) {
-			delete(c.entries, path)
-		}
+		c.removeIfUnchanged(path, entryMtime)
+		c.misses++
+		c.metrics.Add(metrics.CacheMisses, "", 1)
 		c.mu.Unlock()
 		return CacheEntry{}, false
 	}
@@ -69,55 +168,287 @@ func (c *Cache) Get(path string) (CacheEntry, bool) {
 	fileMtime := stat.ModTime()
 
 	// Re-acquire lock to compare and ensure entry hasn't been modified by another goroutine
-	c.mu.RLock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	current, stillExists := c.entries[path]
-	c.mu.RUnlock()
 
 	// If entry was modified/deleted while we were checking stat, return cache miss
-	if !stillExists || !current.Mtime.Equal(entryMtime) {
+	if !stillExists || !current.Value.(*cacheItem).entry.Mtime.Equal(entryMtime) {
+		c.misses++
+		c.metrics.Add(metrics.CacheMisses, "", 1)
 		return CacheEntry{}, false
 	}
 
 	// Now check if file has been modified on disk
 	if !fileMtime.Equal(entryMtime) {
 		// Delete stale entry
-		c.mu.Lock()
-		if current, stillExists := c.entries[path]; stillExists && current.Mtime.Equal(entryMtime) {
-			delete(c.entries, path)
-		}
-		c.mu.Unlock()
+		c.removeIfUnchanged(path, entryMtime)
+		c.misses++
+		c.metrics.Add(metrics.CacheMisses, "", 1)
 		return CacheEntry{}, false
 	}
 
-	// Create defensive copy of tags slice to prevent external modification
-	tagsCopy := make([]string, len(entry.Tags))
-	copy(tagsCopy, entry.Tags)
+	c.lru.MoveToFront(current)
+	c.hits++
+	c.metrics.Add(metrics.CacheHits, "", 1)
+
+	// Create defensive copies to prevent external modification
+	entry.Tags = copyStrings(entry.Tags)
+	entry.Links = copyLinks(entry.Links)
+	entry.Tasks = copyTasks(entry.Tasks)
+	entry.Blocks = copyBlocks(entry.Blocks)
+	entry.Headings = copyHeadings(entry.Headings)
+	entry.Aliases = copyStrings(entry.Aliases)
+	entry.Properties = maps.Clone(entry.Properties)
+
+	return entry, true
+}
 
+// Peek retrieves the content and stamps of a cache entry without
+// validating them against disk or marking the entry as used, so a note
+// changed outside the server still has the content it had when cached.
+// It does not count as a hit or a miss.
+func (c *Cache) Peek(path string) (CacheEntry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	elem, exists := c.entries[path]
+	if !exists {
+		return CacheEntry{}, false
+	}
+	// Parsed metadata is left out rather than copied
+	entry := elem.Value.(*cacheItem).entry
+	return CacheEntry{Content: entry.Content, Mtime: entry.Mtime, ContentHash: entry.ContentHash, ContentOmitted: entry.ContentOmitted}, true
+}
+
+// Metadata returns the tags, title, aliases, headings and frontmatter of
+// a cache entry without its content or validating it against disk, like
+// Peek. The headings and frontmatter are shared; treat them as read-only.
+func (c *Cache) Metadata(path string) (CacheEntry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	elem, exists := c.entries[path]
+	if !exists {
+		return CacheEntry{}, false
+	}
+	entry := elem.Value.(*cacheItem).entry
 	return CacheEntry{
-		Content: entry.Content,
-		Tags:    tagsCopy,
-		Mtime:   entry.Mtime,
+		Tags:       copyStrings(entry.Tags),
+		Title:      entry.Title,
+		Aliases:    copyStrings(entry.Aliases),
+		Headings:   entry.Headings,
+		Properties: entry.Properties,
+		Mtime:      entry.Mtime,
 	}, true
 }
 
 // Set stores a cache entry with the given metadata
+// Evicts least recently used entries if a limit is exceeded
 func (c *Cache) Set(path string, content string, tags []string, mtime time.Time) {
-	// Create defensive copy of tags to prevent external modification
-	tagsCopy := make([]string, len(tags))
-	copy(tagsCopy, tags)
-
-	c.mu.Lock()
-	c.entries[path] = CacheEntry{
+	c.SetEntry(path, CacheEntry{
 		Content: content,
-		Tags:    tagsCopy,
+		Tags:    tags,
 		Mtime:   mtime,
+	})
+}
+
+// SetEntry stores a complete cache entry including parsed metadata
+// Evicts least recently used entries if a limit is exceeded
+func (c *Cache) SetEntry(path string, entry CacheEntry) {
+	// Create defensive copies to prevent external modification
+	entry.Tags = copyStrings(entry.Tags)
+	entry.Links = copyLinks(entry.Links)
+	entry.Tasks = copyTasks(entry.Tasks)
+	entry.Blocks = copyBlocks(entry.Blocks)
+	entry.Headings = copyHeadings(entry.Headings)
+	entry.Aliases = copyStrings(entry.Aliases)
+	entry.Properties = maps.Clone(entry.Properties)
+	entry.ContentOmitted = false
+
+	// Oversized notes and blobs keep their metadata but not their content
+	if entry.Blob || c.maxBytes > 0 && int64(len(entry.Content)) > c.maxBytes {
+		entry.Content = ""
+		entry.ContentOmitted = true
 	}
-	c.mu.Unlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, exists := c.entries[path]; exists {
+		c.remove(elem)
+	}
+
+	elem := c.lru.PushFront(&cacheItem{path: path, entry: entry})
+	c.entries[path] = elem
+	c.bytes += int64(len(entry.Content) + len(entry.Prose))
+
+	c.evict()
 }
 
 // Delete removes a cache entry
 func (c *Cache) Delete(path string) {
 	c.mu.Lock()
-	delete(c.entries, path)
+	if elem, exists := c.entries[path]; exists {
+		c.remove(elem)
+	}
+	c.mu.Unlock()
+}
+
+// Rename moves the entry for oldPath to newPath, replacing any entry there
+// The entry keeps its modification time, so it stays valid when the file
+// was moved without being changed
+func (c *Cache) Rename(oldPath, newPath string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, exists := c.entries[oldPath]
+	if !exists || oldPath == newPath {
+		return
+	}
+	if existing, ok := c.entries[newPath]; ok {
+		c.remove(existing)
+	}
+	delete(c.entries, oldPath)
+	elem.Value.(*cacheItem).path = newPath
+	c.entries[newPath] = elem
+}
+
+// Clear removes every entry, keeping the hit, miss and eviction counters
+// and the pinned paths, whose entries are cached again when next read
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+	c.bytes = 0
+	c.mu.Unlock()
+}
+
+// Stamps returns the modification time and content hash of every entry,
+// without validating them against disk or marking them as used
+func (c *Cache) Stamps() map[string]CacheStamp {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	stamps := make(map[string]CacheStamp, len(c.entries))
+	for path, elem := range c.entries {
+		entry := elem.Value.(*cacheItem).entry
+		stamps[path] = CacheStamp{entry.Mtime, entry.ContentHash}
+	}
+	return stamps
+}
+
+// CacheStats returns current usage counters
+func (c *Cache) CacheStats() CacheStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return CacheStats{
+		Entries:   len(c.entries),
+		Bytes:     c.bytes,
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+	}
+}
+
+// recordMiss increments the miss counter
+func (c *Cache) recordMiss() {
+	c.mu.Lock()
+	c.misses++
+	c.metrics.Add(metrics.CacheMisses, "", 1)
 	c.mu.Unlock()
 }
+
+// removeIfUnchanged deletes the entry for path if its mtime still matches
+// Caller must hold the write lock
+func (c *Cache) removeIfUnchanged(path string, mtime time.Time) {
+	if elem, exists := c.entries[path]; exists && elem.Value.(*cacheItem).entry.Mtime.Equal(mtime) {
+		c.remove(elem)
+	}
+}
+
+// remove unlinks elem and updates size accounting
+// Caller must hold the write lock
+func (c *Cache) remove(elem *list.Element) {
+	item := elem.Value.(*cacheItem)
+	c.lru.Remove(elem)
+	delete(c.entries, item.path)
+	c.bytes -= int64(len(item.entry.Content) + len(item.entry.Prose))
+}
+
+// evict drops least recently used entries until limits are satisfied
+// Caller must hold the write lock
+func (c *Cache) evict() {
+	for c.lru.Len() > 0 {
+		overBytes := c.maxBytes > 0 && c.bytes > c.maxBytes
+		overEntries := c.maxEntries > 0 && c.lru.Len() > c.maxEntries
+		if !overBytes && !overEntries {
+			return
+		}
+
+		// Least recently used first, passing over pinned entries; when
+		// only pinned entries are left the limits give way
+		victim := c.lru.Back()
+		for victim != nil && c.pinned[victim.Value.(*cacheItem).path] {
+			victim = victim.Prev()
+		}
+		if victim == nil {
+			return
+		}
+		c.remove(victim)
+		c.evictions++
+		c.metrics.Add(metrics.CacheEvictions, "", 1)
+	}
+}
+
+// Pin exempts the entries of paths from eviction, in place of the paths
+// given before. Pinned entries still count towards the limits, and a note
+// larger than the byte limit is still cached without its content.
+func (c *Cache) Pin(paths []string) {
+	pinned := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		pinned[path] = true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pinned = pinned
+	c.evict()
+}
+
+// copyStrings returns a copy of s that is never nil
+func copyStrings(s []string) []string {
+	c := make([]string, len(s))
+	copy(c, s)
+	return c
+}
+
+// copyTasks returns a copy of tasks that is never nil
+func copyTasks(tasks []Task) []Task {
+	c := make([]Task, len(tasks))
+	copy(c, tasks)
+	return c
+}
+
+// copyBlocks returns a copy of blocks that is never nil
+func copyBlocks(blocks []Block) []Block {
+	c := make([]Block, len(blocks))
+	copy(c, blocks)
+	return c
+}
+
+// copyHeadings returns a copy of headings that is never nil
+func copyHeadings(headings []Heading) []Heading {
+	c := make([]Heading, len(headings))
+	copy(c, headings)
+	return c
+}
+
+// copyLinks returns a copy of links that is never nil
+func copyLinks(links []Link) []Link {
+	c := make([]Link, len(links))
+	copy(c, links)
+	return c
+}
@@ -1,11 +1,15 @@
 package vault
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
+
+	"github.com/kratos/mcp-notes/internal/metrics"
 )
 
 func TestNewCache(t *testing.T) {
@@ -212,3 +216,205 @@ func TestCacheConcurrency(t *testing.T) {
 		<-done
 	}
 }
+
+// writeCacheFile creates a file and returns its path and mtime
+func writeCacheFile(t *testing.T, dir, name, content string) (string, time.Time) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat test file: %v", err)
+	}
+	return path, stat.ModTime()
+}
+
+func TestCacheLRUEvictionByBytes(t *testing.T) {
+	cache := NewBoundedCache(10, 0)
+	tmpDir := t.TempDir()
+
+	a, aMtime := writeCacheFile(t, tmpDir, "a.md", "aaaa")
+	b, bMtime := writeCacheFile(t, tmpDir, "b.md", "bbbb")
+	c, cMtime := writeCacheFile(t, tmpDir, "c.md", "cccc")
+
+	cache.Set(a, "aaaa", nil, aMtime)
+	cache.Set(b, "bbbb", nil, bMtime)
+
+	// Touch a so b becomes least recently used
+	if _, ok := cache.Get(a); !ok {
+		t.Fatal("Expected a to be cached")
+	}
+
+	cache.Set(c, "cccc", nil, cMtime)
+
+	if _, ok := cache.Get(b); ok {
+		t.Error("Expected b to be evicted")
+	}
+	if _, ok := cache.Get(a); !ok {
+		t.Error("Expected a to survive eviction")
+	}
+	if _, ok := cache.Get(c); !ok {
+		t.Error("Expected c to be cached")
+	}
+
+	stats := cache.CacheStats()
+	if stats.Entries != 2 || stats.Bytes != 8 {
+		t.Errorf("Stats = %+v, want 2 entries and 8 bytes", stats)
+	}
+	if stats.Evictions != 1 {
+		t.Errorf("Evictions = %d, want 1", stats.Evictions)
+	}
+	if stats.Hits != 3 || stats.Misses != 1 {
+		t.Errorf("Hits = %d, Misses = %d, want 3 and 1", stats.Hits, stats.Misses)
+	}
+}
+
+func TestCacheLRUEvictionByEntries(t *testing.T) {
+	cache := NewBoundedCache(0, 2)
+	tmpDir := t.TempDir()
+
+	for i := 0; i < 5; i++ {
+		path, mtime := writeCacheFile(t, tmpDir, fmt.Sprintf("note%d.md", i), "content")
+		cache.Set(path, "content", nil, mtime)
+	}
+
+	stats := cache.CacheStats()
+	if stats.Entries != 2 {
+		t.Errorf("Entries = %d, want 2", stats.Entries)
+	}
+	if stats.Evictions != 3 {
+		t.Errorf("Evictions = %d, want 3", stats.Evictions)
+	}
+}
+
+func TestCachePinnedEntriesKept(t *testing.T) {
+	cache := NewBoundedCache(0, 2)
+	tmpDir := t.TempDir()
+
+	var paths []string
+	for i := 0; i < 4; i++ {
+		path, mtime := writeCacheFile(t, tmpDir, fmt.Sprintf("note%d.md", i), "content")
+		cache.Set(path, "content", nil, mtime)
+		paths = append(paths, path)
+		if i == 0 {
+			cache.Pin(paths[:1])
+		}
+	}
+
+	// note0 is the least recently used but pinned, so note1 goes first
+	if _, ok := cache.Peek(paths[0]); !ok {
+		t.Error("pinned note0 was evicted")
+	}
+	if _, ok := cache.Peek(paths[1]); ok {
+		t.Error("note1 is still cached, want it evicted")
+	}
+
+	// Once every entry is pinned the limit gives way
+	path, mtime := writeCacheFile(t, tmpDir, "note4.md", "content")
+	cache.Pin(append(paths, path))
+	cache.Set(path, "content", nil, mtime)
+	if stats := cache.CacheStats(); stats.Entries != 3 {
+		t.Errorf("Entries = %d, want 3", stats.Entries)
+	}
+}
+
+func TestCacheOversizedEntry(t *testing.T) {
+	cache := NewBoundedCache(4, 0)
+	tmpDir := t.TempDir()
+
+	path, mtime := writeCacheFile(t, tmpDir, "big.md", "too large for the cache")
+	cache.Set(path, "too large for the cache", []string{"tag"}, mtime)
+
+	entry, ok := cache.Get(path)
+	if !ok {
+		t.Fatal("Expected metadata entry for oversized note")
+	}
+	if !entry.ContentOmitted || entry.Content != "" {
+		t.Errorf("Expected content to be omitted, got %+v", entry)
+	}
+	if len(entry.Tags) != 1 || entry.Tags[0] != "tag" {
+		t.Errorf("Tags = %v, want [tag]", entry.Tags)
+	}
+	if stats := cache.CacheStats(); stats.Bytes != 0 {
+		t.Errorf("Bytes = %d, want 0", stats.Bytes)
+	}
+}
+
+func TestCacheReplaceAccounting(t *testing.T) {
+	cache := NewCache()
+	tmpDir := t.TempDir()
+
+	path, mtime := writeCacheFile(t, tmpDir, "note.md", "12345")
+	cache.Set(path, "12345", nil, mtime)
+	cache.Set(path, "123", nil, mtime)
+
+	if stats := cache.CacheStats(); stats.Entries != 1 || stats.Bytes != 3 {
+		t.Errorf("Stats = %+v, want 1 entry and 3 bytes", stats)
+	}
+
+	cache.Delete(path)
+	if stats := cache.CacheStats(); stats.Entries != 0 || stats.Bytes != 0 {
+		t.Errorf("Stats = %+v, want empty cache", stats)
+	}
+}
+
+func TestCacheMetrics(t *testing.T) {
+	cache := NewBoundedCache(0, 1)
+	registry := metrics.NewRegistry()
+	cache.SetMetrics(registry)
+	tmpDir := t.TempDir()
+
+	a, aMtime := writeCacheFile(t, tmpDir, "a.md", "aaaa")
+	b, bMtime := writeCacheFile(t, tmpDir, "b.md", "bbbb")
+
+	cache.Set(a, "aaaa", nil, aMtime)
+	cache.Get(a)
+	cache.Set(b, "bbbb", nil, bMtime) // Evicts a
+	cache.Get(a)
+
+	want := []metrics.Sample{
+		{Name: metrics.CacheEvictions, Value: 1},
+		{Name: metrics.CacheHits, Value: 1},
+		{Name: metrics.CacheMisses, Value: 1},
+	}
+	if got := registry.Snapshot(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Snapshot = %+v, want %+v", got, want)
+	}
+}
+
+func TestVaultMetrics(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeCacheFile(t, tmpDir, "note.md", "hello")
+
+	registry := metrics.NewRegistry()
+	v, err := NewVault(tmpDir, WithMetrics(registry))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := v.Read(ctx, "note.md"); err != nil {
+			t.Fatalf("Read failed: %v", err)
+		}
+	}
+	if _, err := v.List(ctx, ListOptions{}); err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	values := make(map[string]uint64)
+	for _, sample := range registry.Snapshot() {
+		values[sample.Name] = sample.Value
+	}
+	if values[metrics.FileReads] != 1 || values[metrics.BytesRead] != 5 {
+		t.Errorf("Reads = %d, bytes = %d, want 1 and 5", values[metrics.FileReads], values[metrics.BytesRead])
+	}
+	if values[metrics.CacheHits] == 0 {
+		t.Error("Expected the second read to hit the cache")
+	}
+	if values[metrics.WalkDuration] == 0 {
+		t.Error("Expected the walk to be timed")
+	}
+}
@@ -0,0 +1,138 @@
+package vault
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Callout is an Obsidian callout: a blockquote opened by a line such as
+// "> [!warning]- Title", running while the following lines keep at least
+// as many > markers
+type Callout struct {
+	Type      string `json:"type"`            // Lowercase, e.g. "note" or "warning"
+	Title     string `json:"title,omitempty"` // Text after the type, if any
+	Fold      string `json:"fold,omitempty"`  // "+" when expanded, "-" when collapsed, empty if not foldable
+	StartLine int    `json:"start_line"`      // 1-based line of the opener
+	EndLine   int    `json:"end_line"`        // 1-based last line, inclusive
+	Depth     int    `json:"depth"`           // Callouts it is nested in, 0 for none
+
+	quotes int // > markers of its lines
+}
+
+// calloutLine is a line inside a callout with its > markers removed
+type calloutLine struct {
+	text string
+	code bool // Inside or delimiting a fenced code block in the callout
+}
+
+// calloutRegex matches a callout opener after its > markers
+var calloutRegex = regexp.MustCompile(`^\[!([A-Za-z0-9][\w-]*)\]([+-]?)(?:\s+(.*?))?\s*$`)
+
+// quoteMarkers returns the number of > markers opening line and the text
+// after them. The markers may be indented, as in a list item.
+func quoteMarkers(line string) (int, string) {
+	rest := strings.TrimLeft(line, " \t")
+	if !strings.HasPrefix(rest, ">") {
+		return 0, line
+	}
+	quotes := 0
+	for strings.HasPrefix(rest, ">") {
+		quotes++
+		rest = strings.TrimPrefix(rest[1:], " ")
+		if trimmed := strings.TrimLeft(rest, " "); strings.HasPrefix(trimmed, ">") {
+			rest = trimmed
+		}
+	}
+	return quotes, rest
+}
+
+// ParseCallouts returns the callouts of markdown content in the order they
+// open, so a callout comes before those nested in it. Callouts in code
+// blocks and frontmatter are ignored.
+func ParseCallouts(content string) []Callout {
+	callouts, _ := scanCallouts(content)
+	return callouts
+}
+
+// scanCallouts returns the callouts of content and, by line number, the
+// lines inside them after their openers, without their > markers
+func scanCallouts(content string) ([]Callout, map[int]calloutLine) {
+	callouts := []Callout{}
+	inside := make(map[int]calloutLine)
+	var open []int   // Indexes of the callouts still open, outermost first
+	prevQuotes := 0  // > markers of the previous line
+	fenceQuotes := 0 // > markers of the fence open inside a callout, 0 if none
+
+	markdownLines(content, func(line string, lineNum int, code bool) {
+		quotes, text := quoteMarkers(line)
+		if code {
+			quotes = 0
+		}
+		defer func() { prevQuotes = quotes }()
+
+		for len(open) > 0 && callouts[open[len(open)-1]].quotes > quotes {
+			open = open[:len(open)-1]
+		}
+		if quotes < fenceQuotes || len(open) == 0 {
+			fenceQuotes = 0
+		}
+
+		// An opener starts the blockquote at its level
+		if fenceQuotes == 0 && quotes > prevQuotes {
+			if m := calloutRegex.FindStringSubmatch(text); m != nil {
+				for _, i := range open {
+					callouts[i].EndLine = lineNum
+				}
+				open = append(open, len(callouts))
+				callouts = append(callouts, Callout{
+					Type:      strings.ToLower(m[1]),
+					Title:     m[3],
+					Fold:      m[2],
+					StartLine: lineNum,
+					EndLine:   lineNum,
+					Depth:     len(open) - 1,
+					quotes:    quotes,
+				})
+				return
+			}
+		}
+		if len(open) == 0 {
+			return
+		}
+
+		for _, i := range open {
+			callouts[i].EndLine = lineNum
+		}
+		trimmed := strings.TrimSpace(text)
+		fence := strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")
+		if fence {
+			if fenceQuotes == 0 {
+				fenceQuotes = quotes
+			} else {
+				fenceQuotes = 0
+			}
+		}
+		inside[lineNum] = calloutLine{text: text, code: fence || fenceQuotes > 0}
+	})
+	return callouts, inside
+}
+
+// outerCallout returns the outermost callout holding the 1-based line
+func outerCallout(callouts []Callout, lineNum int) (Callout, bool) {
+	for _, c := range callouts {
+		if c.Depth == 0 && c.StartLine <= lineNum && lineNum <= c.EndLine {
+			return c, true
+		}
+	}
+	return Callout{}, false
+}
+
+// isCalloutOpener reports whether the 1-based line opens one of callouts
+func isCalloutOpener(callouts []Callout, lineNum int) bool {
+	for _, c := range callouts {
+		if c.StartLine == lineNum {
+			return true
+		}
+	}
+	return false
+}
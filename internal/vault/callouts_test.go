@@ -0,0 +1,124 @@
+package vault
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const calloutNote = `---
+title: Trip
+---
+# Trip
+
+> [!WARNING]- Book early
+> Trains sell out.
+>
+> ## Not in the outline
+> - [ ] book the train #travel
+>
+> > [!tip] Cheaper
+> > - [x] compare fares
+> >   - [ ] ask Ann
+> > ` + "```" + `
+> > - [ ] inside code
+> > ` + "```" + `
+> Back in the outer callout
+Not quoted any more
+
+- Packing
+  > [!todo]+
+  > - [ ] passport
+  - [ ] charger
+
+> plain quote
+> [!note] not an opener mid-quote
+
+` + "```" + `
+> [!note] in code
+` + "```" + `
+> [!info]
+`
+
+func TestParseCallouts(t *testing.T) {
+	got := ParseCallouts(calloutNote)
+	want := []Callout{
+		{Type: "warning", Title: "Book early", Fold: "-", StartLine: 6, EndLine: 18, quotes: 1},
+		{Type: "tip", Title: "Cheaper", StartLine: 12, EndLine: 17, Depth: 1, quotes: 2},
+		{Type: "todo", Fold: "+", StartLine: 22, EndLine: 23, quotes: 1},
+		{Type: "info", StartLine: 32, EndLine: 32, quotes: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseCallouts() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestParseTasksInCallouts(t *testing.T) {
+	got := ParseTasks(calloutNote)
+	want := []Task{
+		{Text: "book the train #travel", Heading: "Trip", Line: 10, Tags: []string{"travel"}},
+		{Text: "compare fares", Done: true, Heading: "Trip", Line: 13, Tags: []string{}},
+		{Text: "ask Ann", Heading: "Trip", Line: 14, Depth: 1, Tags: []string{}},
+		{Text: "passport", Heading: "Trip", Line: 23, Tags: []string{}},
+		{Text: "charger", Heading: "Trip", Line: 24, Depth: 1, Tags: []string{}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseTasks() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	if headings := ParseHeadings(calloutNote); len(headings) != 1 || headings[0].Text != "Trip" {
+		t.Errorf("ParseHeadings() = %+v, want only Trip", headings)
+	}
+}
+
+func TestCalloutBlocks(t *testing.T) {
+	v, tmpDir := setupTestVault(t)
+	ctx := context.Background()
+
+	content := strings.Join([]string{
+		"# Notes",
+		"> [!note] Keep",
+		"> First paragraph",
+		">",
+		"> > [!quote]",
+		"> > Nested ^inner",
+		">",
+		"> Last paragraph",
+		"",
+		"- item",
+		"  > [!tip]",
+		"  > Listed ^listed",
+		"  > hint",
+		"## Next",
+	}, "\n")
+	if err := os.WriteFile(filepath.Join(tmpDir, "callouts.md"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+
+	section, err := v.ReadSection(ctx, "callouts.md", SectionOptions{Block: "inner"})
+	if err != nil {
+		t.Fatalf("ReadSection() error = %v", err)
+	}
+	if section.StartLine != 2 || section.EndLine != 8 || !strings.HasSuffix(section.Content, "> Last paragraph") {
+		t.Errorf("ReadSection() = %+v, want the whole callout", section)
+	}
+
+	section, err = v.ReadSection(ctx, "callouts.md", SectionOptions{Block: "listed"})
+	if err != nil {
+		t.Fatalf("ReadSection() error = %v", err)
+	}
+	if section.StartLine != 11 || section.EndLine != 13 {
+		t.Errorf("ReadSection() = %+v, want the callout in the list", section)
+	}
+
+	analysis, err := v.Analyze(ctx, "callouts.md")
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+	if len(analysis.Callouts) != 3 || analysis.Callouts[1].Type != "quote" || analysis.Callouts[1].Depth != 1 {
+		t.Errorf("Analyze() callouts = %+v", analysis.Callouts)
+	}
+}
@@ -0,0 +1,213 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// canvasExt is the extension of Obsidian canvas files
+const canvasExt = ".canvas"
+
+// Canvas node types
+const (
+	CanvasNodeText  = "text"
+	CanvasNodeFile  = "file"
+	CanvasNodeLink  = "link"
+	CanvasNodeGroup = "group"
+)
+
+// Canvas is a simplified view of an Obsidian canvas: card contents and
+// connections, without layout
+type Canvas struct {
+	Nodes []CanvasNode `json:"nodes"`
+	Edges []CanvasEdge `json:"edges"`
+}
+
+// CanvasNode is a card or group on a canvas
+type CanvasNode struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`              // text, file, link or group
+	Text    string `json:"text,omitempty"`    // Markdown content of a text card
+	File    string `json:"file,omitempty"`    // File as written in a file card
+	Subpath string `json:"subpath,omitempty"` // Heading or block of a file card, e.g. #Heading
+	Path    string `json:"path,omitempty"`    // Resolved vault-relative path of a file card
+	Missing bool   `json:"missing,omitempty"` // Whether a file card points to a missing file
+	URL     string `json:"url,omitempty"`     // Target of a link card
+	Label   string `json:"label,omitempty"`   // Label of a group
+}
+
+// CanvasEdge connects two canvas nodes
+type CanvasEdge struct {
+	ID    string `json:"id"`
+	From  string `json:"from"` // Source node ID
+	To    string `json:"to"`   // Target node ID
+	Label string `json:"label,omitempty"`
+}
+
+// canvasFile mirrors the JSON Canvas format for decoding
+type canvasFile struct {
+	Nodes []struct {
+		ID      string `json:"id"`
+		Type    string `json:"type"`
+		Text    string `json:"text"`
+		File    string `json:"file"`
+		Subpath string `json:"subpath"`
+		URL     string `json:"url"`
+		Label   string `json:"label"`
+	} `json:"nodes"`
+	Edges []struct {
+		ID       string `json:"id"`
+		FromNode string `json:"fromNode"`
+		ToNode   string `json:"toNode"`
+		Label    string `json:"label"`
+	} `json:"edges"`
+}
+
+// ParseCanvas decodes canvas JSON into its simplified form
+// Fails with ErrInvalidCanvas on malformed JSON, nodes without an ID or
+// type, and edges referring to unknown nodes
+func ParseCanvas(content string) (Canvas, error) {
+	var raw canvasFile
+	if err := json.Unmarshal([]byte(content), &raw); err != nil {
+		return Canvas{}, fmt.Errorf("%w: %v", ErrInvalidCanvas, err)
+	}
+
+	canvas := Canvas{
+		Nodes: make([]CanvasNode, 0, len(raw.Nodes)),
+		Edges: make([]CanvasEdge, 0, len(raw.Edges)),
+	}
+
+	ids := make(map[string]bool, len(raw.Nodes))
+	for i, n := range raw.Nodes {
+		if n.ID == "" || n.Type == "" {
+			return Canvas{}, fmt.Errorf("%w: node %d has no id or type", ErrInvalidCanvas, i)
+		}
+		ids[n.ID] = true
+
+		node := CanvasNode{ID: n.ID, Type: n.Type}
+		switch n.Type {
+		case CanvasNodeText:
+			node.Text = n.Text
+		case CanvasNodeFile:
+			node.File = n.File
+			node.Subpath = n.Subpath
+		case CanvasNodeLink:
+			node.URL = n.URL
+		case CanvasNodeGroup:
+			node.Label = n.Label
+		}
+		canvas.Nodes = append(canvas.Nodes, node)
+	}
+
+	for _, e := range raw.Edges {
+		if !ids[e.FromNode] || !ids[e.ToNode] {
+			return Canvas{}, fmt.Errorf("%w: edge %q connects unknown nodes", ErrInvalidCanvas, e.ID)
+		}
+		canvas.Edges = append(canvas.Edges, CanvasEdge{ID: e.ID, From: e.FromNode, To: e.ToNode, Label: e.Label})
+	}
+
+	return canvas, nil
+}
+
+// Text returns the content of every text card, separated by blank lines
+func (c Canvas) Text() string {
+	var texts []string
+	for _, node := range c.Nodes {
+		if node.Type == CanvasNodeText && node.Text != "" {
+			texts = append(texts, node.Text)
+		}
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// isCanvas reports whether path names a canvas file
+func isCanvas(path string) bool {
+	return strings.HasSuffix(path, canvasExt)
+}
+
+// validateCanvasPath is validatePath for canvases: the traversal and
+// reserved path checks apply, but the file must end with .canvas
+func (v *vault) validateCanvasPath(path string) (string, error) {
+	fullPath, err := v.validateFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	if !isCanvas(fullPath) {
+		return "", ErrNotCanvas
+	}
+
+	return fullPath, nil
+}
+
+// newCanvasEntry indexes a canvas by the text of its cards so it can be
+// searched like a note
+func newCanvasEntry(content string, mtime time.Time) (CacheEntry, error) {
+	canvas, err := ParseCanvas(content)
+	if err != nil {
+		return CacheEntry{}, err
+	}
+
+	text := canvas.Text()
+	return CacheEntry{
+		Content:     text,
+		Tags:        ExtractTags(text),
+		Links:       ParseLinks(text),
+		Mtime:       mtime,
+		ContentHash: contentHash(content),
+	}, nil
+}
+
+// ReadCanvas returns the parsed canvas at path with file cards resolved
+// to vault-relative paths
+func (v *vault) ReadCanvas(ctx context.Context, path string) (Canvas, error) {
+	fullPath, err := v.validateCanvasPath(path)
+	if err != nil {
+		return Canvas{}, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return Canvas{}, err
+	}
+
+	data, err := os.ReadFile(fullPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Canvas{}, ErrCanvasNotFound
+		}
+		return Canvas{}, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	text, _, err := v.decodeNote(data)
+	if err != nil {
+		return Canvas{}, err
+	}
+
+	canvas, err := ParseCanvas(text)
+	if err != nil {
+		return Canvas{}, err
+	}
+
+	index, err := v.buildFileIndex(ctx)
+	if err != nil {
+		return Canvas{}, err
+	}
+
+	source := v.relPath(fullPath)
+	for i, node := range canvas.Nodes {
+		if node.Type != CanvasNodeFile {
+			continue
+		}
+		if p, ok := index.resolve(source, node.File); ok && node.File != "" {
+			canvas.Nodes[i].Path = p
+		} else {
+			canvas.Nodes[i].Missing = true
+		}
+	}
+
+	return canvas, nil
+}
@@ -0,0 +1,162 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+const testCanvas = `{
+	"nodes": [
+		{"id": "a", "type": "text", "text": "Plan the #launch", "x": 0, "y": 0, "width": 200, "height": 100},
+		{"id": "b", "type": "file", "file": "subdir/note3.md", "subpath": "#Goals", "x": 300, "y": 0, "width": 200, "height": 100},
+		{"id": "c", "type": "file", "file": "gone.md", "x": 600, "y": 0, "width": 200, "height": 100},
+		{"id": "d", "type": "link", "url": "https://example.com", "x": 0, "y": 200, "width": 200, "height": 100},
+		{"id": "g", "type": "group", "label": "Phase 1", "x": -50, "y": -50, "width": 900, "height": 400}
+	],
+	"edges": [
+		{"id": "e1", "fromNode": "a", "fromSide": "right", "toNode": "b", "toSide": "left", "label": "details"},
+		{"id": "e2", "fromNode": "b", "toNode": "c"}
+	]
+}`
+
+func TestParseCanvas(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"valid canvas", testCanvas, false},
+		{"empty canvas", `{}`, false},
+		{"malformed JSON", `{"nodes": [`, true},
+		{"node without id", `{"nodes": [{"type": "text"}]}`, true},
+		{"edge to unknown node", `{"nodes": [{"id": "a", "type": "text"}], "edges": [{"id": "e", "fromNode": "a", "toNode": "z"}]}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCanvas(tt.content)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("ParseCanvas() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidCanvas) {
+				t.Errorf("Expected ErrInvalidCanvas, got %v", err)
+			}
+		})
+	}
+}
+
+func setupCanvasVault(t *testing.T) (Vault, string) {
+	v, tmpDir := setupTestVault(t)
+
+	files := map[string]string{
+		"plans/board.canvas":  testCanvas,
+		"plans/broken.canvas": `{"nodes": [`,
+	}
+	writeFiles(t, tmpDir, files)
+
+	return v, tmpDir
+}
+
+func TestReadCanvas(t *testing.T) {
+	v, _ := setupCanvasVault(t)
+	ctx := context.Background()
+
+	t.Run("simplified structure", func(t *testing.T) {
+		canvas, err := v.ReadCanvas(ctx, "plans/board.canvas")
+		if err != nil {
+			t.Fatalf("ReadCanvas() error = %v", err)
+		}
+
+		if len(canvas.Nodes) != 5 || len(canvas.Edges) != 2 {
+			t.Fatalf("Got %d nodes and %d edges, want 5 and 2", len(canvas.Nodes), len(canvas.Edges))
+		}
+
+		nodes := make(map[string]CanvasNode)
+		for _, n := range canvas.Nodes {
+			nodes[n.ID] = n
+		}
+
+		if nodes["a"].Text != "Plan the #launch" {
+			t.Errorf("text node = %+v", nodes["a"])
+		}
+		if nodes["b"].Path != "subdir/note3.md" || nodes["b"].Subpath != "#Goals" || nodes["b"].Missing {
+			t.Errorf("file node = %+v, want resolved subdir/note3.md", nodes["b"])
+		}
+		if !nodes["c"].Missing || nodes["c"].Path != "" {
+			t.Errorf("missing file node = %+v, want Missing", nodes["c"])
+		}
+		if nodes["d"].URL != "https://example.com" {
+			t.Errorf("link node = %+v", nodes["d"])
+		}
+		if nodes["g"].Label != "Phase 1" {
+			t.Errorf("group node = %+v", nodes["g"])
+		}
+
+		edge := canvas.Edges[0]
+		if edge.From != "a" || edge.To != "b" || edge.Label != "details" {
+			t.Errorf("edge = %+v", edge)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr error
+	}{
+		{"malformed canvas", "plans/broken.canvas", ErrInvalidCanvas},
+		{"missing canvas", "plans/missing.canvas", ErrCanvasNotFound},
+		{"note is not a canvas", "note1.md", ErrNotCanvas},
+		{"path traversal", "../board.canvas", ErrPathTraversal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.ReadCanvas(ctx, tt.path)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSearchCanvas(t *testing.T) {
+	v, _ := setupCanvasVault(t)
+	ctx := context.Background()
+
+	t.Run("canvases excluded by default", func(t *testing.T) {
+		notes, err := v.Search(ctx, SearchOptions{Query: "plan the"})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		if len(notes) != 0 {
+			t.Errorf("Expected no results, got %v", notes)
+		}
+	})
+
+	t.Run("text cards searched on request", func(t *testing.T) {
+		notes, err := v.Search(ctx, SearchOptions{TagsAny: []string{"launch"}, IncludeCanvas: true})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+
+		var found, broken bool
+		for _, n := range notes {
+			switch filepath.ToSlash(n.Path) {
+			case "plans/board.canvas":
+				found = n.Error == ""
+			case "plans/broken.canvas":
+				broken = n.Error != ""
+			default:
+				t.Errorf("Unexpected result %s", n.Path)
+			}
+		}
+		if !found {
+			t.Error("Expected plans/board.canvas to match")
+		}
+		if !broken {
+			t.Error("Expected plans/broken.canvas to be reported with an error")
+		}
+	})
+}
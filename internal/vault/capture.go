@@ -0,0 +1,249 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Default capture settings; see CaptureSettings
+const (
+	DefaultCaptureNote       = "Inbox.md"
+	DefaultCaptureEntry      = "- {time} {text}"
+	DefaultCaptureTimeFormat = "15:04"
+	DefaultCaptureHeading    = "## 2006-01-02"
+)
+
+// CaptureSettings decide where Capture writes and how its entries look
+type CaptureSettings struct {
+	Note       string // Note captured into when no target is given
+	Entry      string // Entry template; {text}, {time} and {date} are replaced
+	TimeFormat string // Go time layout of {time}
+	Heading    string // Go time layout of the date heading entries go under, a markdown heading; empty for none
+}
+
+// Validate reports an entry template without {text} or a date heading
+// that is not a markdown heading
+func (s CaptureSettings) Validate() error {
+	if s.Entry != "" && !strings.Contains(s.Entry, "{text}") {
+		return fmt.Errorf("entry template %q has no {text}", s.Entry)
+	}
+	if s.Heading != "" && !headingRegex.MatchString(s.Heading) {
+		return fmt.Errorf("date heading %q is not a markdown heading such as %q", s.Heading, DefaultCaptureHeading)
+	}
+	return nil
+}
+
+// WithCapture sets where Capture writes and how its entries look, as
+// checked by CaptureSettings.Validate. Empty Note, Entry and TimeFormat
+// keep the defaults; an empty Heading puts entries at the end of the note
+// without a date heading.
+func WithCapture(s CaptureSettings) Option {
+	return func(v *vault) {
+		if s.Note == "" {
+			s.Note = DefaultCaptureNote
+		}
+		if s.Entry == "" {
+			s.Entry = DefaultCaptureEntry
+		}
+		if s.TimeFormat == "" {
+			s.TimeFormat = DefaultCaptureTimeFormat
+		}
+		v.capture = s
+	}
+}
+
+// CaptureOptions describes an entry for Capture
+type CaptureOptions struct {
+	Text   string    // Entry text; lines after the first stay inside a list item
+	Tags   []string  // Added to the first line as hashtags, with or without #
+	Target string    // Note to capture into, the configured inbox when empty
+	Time   time.Time // When the entry was captured, now when zero
+}
+
+// CaptureResult reports what Capture wrote
+type CaptureResult struct {
+	Path         string   `json:"path"`
+	Lines        []string `json:"lines"`             // Entry lines as written
+	Line         int      `json:"line"`              // 1-based line of the entry's first line
+	Heading      string   `json:"heading,omitempty"` // Date heading the entry is under
+	HeadingAdded bool     `json:"heading_added"`     // The date heading was not in the note yet
+	Created      bool     `json:"created"`           // The note did not exist yet
+	Revision     string   `json:"revision"`          // Content hash of the note after the capture
+}
+
+// Capture appends a timestamped entry to the inbox note, or to
+// opts.Target, under today's date heading, creating the note and the
+// heading when missing. The note is read and written under its write
+// lock, so concurrent captures never interleave or lose entries.
+func (v *vault) Capture(ctx context.Context, opts CaptureOptions) (CaptureResult, error) {
+	now := opts.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	lines, err := v.capture.entryLines(opts.Text, opts.Tags, now)
+	if err != nil {
+		return CaptureResult{}, err
+	}
+	target := opts.Target
+	if target == "" {
+		target = v.capture.Note
+	}
+	fullPath, err := v.validatePath(target)
+	if err != nil {
+		return CaptureResult{}, err
+	}
+	relPath := v.relPath(fullPath)
+
+	unlock := v.writeLocks.lock(fullPath)
+	defer unlock()
+
+	result := CaptureResult{Path: relPath, Lines: lines}
+	if v.capture.Heading != "" {
+		result.Heading = now.Format(v.capture.Heading)
+	}
+
+	var current string
+	if _, err := os.Stat(fullPath); os.IsNotExist(err) {
+		if _, err := v.checkCreate(ctx, relPath); err != nil {
+			return CaptureResult{}, err
+		}
+		result.Created = true
+	} else {
+		if _, err := v.checkUpdate(ctx, relPath); err != nil {
+			return CaptureResult{}, err
+		}
+		stat, err := os.Stat(fullPath)
+		if err != nil {
+			return CaptureResult{}, ErrNoteNotFound
+		}
+		entry, err := v.loadEntry(fullPath, stat.ModTime())
+		if err != nil {
+			return CaptureResult{}, fmt.Errorf("failed to read file: %w", err)
+		}
+		current = entry.Content
+	}
+
+	raw, line, added := insertCapture(current, result.Heading, lines)
+	content, err := v.PrepareContent(relPath, raw, result.Created)
+	if err != nil {
+		return CaptureResult{}, err
+	}
+	// A frontmatter template only adds lines above the entry
+	result.Line = line + strings.Count(content, "\n") - strings.Count(raw, "\n")
+	result.HeadingAdded = added
+	result.Revision = contentHash(content)
+
+	// Check context cancellation before I/O; once writing starts it completes
+	if err := ctx.Err(); err != nil {
+		return CaptureResult{}, err
+	}
+	if result.Created {
+		return result, v.createNote(ctx, fullPath, content)
+	}
+	written, err := v.writeNote(fullPath, content)
+	if err != nil {
+		return CaptureResult{}, err
+	}
+	return result, v.record(ctx, written)
+}
+
+// entryLines renders the entry template for text and tags at now
+func (s CaptureSettings) entryLines(text string, tags []string, now time.Time) ([]string, error) {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if text == "" {
+		return nil, fmt.Errorf("%w: empty text", ErrInvalidCapture)
+	}
+	first, rest, _ := strings.Cut(text, "\n")
+	for _, tag := range tags {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+		if tag == "" || strings.ContainsFunc(tag, isTagBreak) {
+			return nil, fmt.Errorf("%w: tag %q is not a single word", ErrInvalidCapture, tag)
+		}
+		first += " #" + tag
+	}
+
+	// Continuation lines are indented to stay inside a list item entry
+	if rest != "" {
+		indent := ""
+		if isListItem(s.Entry) {
+			indent = "  "
+		}
+		for line := range strings.SplitSeq(rest, "\n") {
+			if strings.TrimSpace(line) == "" {
+				first += "\n"
+			} else {
+				first += "\n" + indent + strings.TrimRight(line, " \t")
+			}
+		}
+	}
+
+	entry := strings.NewReplacer(
+		"{time}", now.Format(s.TimeFormat),
+		"{date}", now.Format(time.DateOnly),
+		"{text}", first,
+	).Replace(s.Entry)
+	return strings.Split(entry, "\n"), nil
+}
+
+// isTagBreak reports whether r cannot be part of a hashtag
+func isTagBreak(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '#'
+}
+
+// isListItem reports whether line starts a bullet or numbered list item
+func isListItem(line string) bool {
+	line = strings.TrimLeft(line, " \t")
+	if strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ") || strings.HasPrefix(line, "+ ") {
+		return true
+	}
+	digits := strings.TrimLeft(line, "0123456789")
+	return len(digits) < len(line) && (strings.HasPrefix(digits, ". ") || strings.HasPrefix(digits, ") "))
+}
+
+// insertCapture adds entry lines to content at the end of the section
+// under heading, appending the heading first when content lacks it.
+// Returns the new content, the 1-based line of the entry and whether the
+// heading was added.
+func insertCapture(content, heading string, entry []string) (string, int, bool) {
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if content == "" {
+		lines = nil
+	}
+
+	if m := headingRegex.FindStringSubmatch(heading); m != nil {
+		if start, end, ok := findHeadingSection(content, m[2]); ok {
+			// After the section's last line with content, so blank lines
+			// before the next heading stay where they are
+			last := start
+			for i := min(end, len(lines)); i > start; i-- {
+				if strings.TrimSpace(lines[i-1]) != "" {
+					last = i
+					break
+				}
+			}
+			lines = append(lines[:last], append(entry, lines[last:]...)...)
+			return strings.Join(lines, "\n") + "\n", last + 1, false
+		}
+	}
+
+	// Entries without a date heading, and new headings, go at the end
+	if strings.TrimSpace(content) == "" {
+		lines = nil
+	}
+	var add []string
+	if len(lines) > 0 && heading != "" && strings.TrimSpace(lines[len(lines)-1]) != "" {
+		add = append(add, "")
+	}
+	if heading != "" {
+		add = append(add, heading)
+	}
+	line := len(lines) + len(add) + 1
+	lines = append(append(lines, add...), entry...)
+	return strings.Join(lines, "\n") + "\n", line, heading != ""
+}
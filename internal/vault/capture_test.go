@@ -0,0 +1,148 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCapture(t *testing.T) {
+	at := time.Date(2024, 6, 1, 9, 5, 0, 0, time.Local)
+	ctx := context.Background()
+
+	t.Run("new note", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		v, err := NewVault(tmpDir)
+		if err != nil {
+			t.Fatalf("Failed to create vault: %v", err)
+		}
+
+		result, err := v.Capture(ctx, CaptureOptions{Text: "Call the plumber", Tags: []string{"#home", "todo"}, Time: at})
+		if err != nil {
+			t.Fatalf("Capture() error = %v", err)
+		}
+		want := CaptureResult{
+			Path:         "Inbox.md",
+			Lines:        []string{"- 09:05 Call the plumber #home #todo"},
+			Line:         2,
+			Heading:      "## 2024-06-01",
+			HeadingAdded: true,
+			Created:      true,
+			Revision:     contentHash("## 2024-06-01\n- 09:05 Call the plumber #home #todo\n"),
+		}
+		if !slices.Equal(result.Lines, want.Lines) || result.Line != want.Line || result.Heading != want.Heading ||
+			!result.HeadingAdded || !result.Created || result.Revision != want.Revision || result.Path != want.Path {
+			t.Errorf("Capture() = %+v, want %+v", result, want)
+		}
+		content, _ := v.Read(ctx, "Inbox.md")
+		if content != "## 2024-06-01\n- 09:05 Call the plumber #home #todo\n" {
+			t.Errorf("Inbox.md = %q", content)
+		}
+	})
+
+	t.Run("existing heading", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		writeFiles(t, tmpDir, map[string]string{
+			"Journal/Log.md": "---\ntags: [log]\n---\n# Log\n\n## 2024-06-01\n- 08:00 Coffee\n\n## 2024-05-31\n- 18:00 Older\n",
+		})
+		v, err := NewVault(tmpDir)
+		if err != nil {
+			t.Fatalf("Failed to create vault: %v", err)
+		}
+
+		result, err := v.Capture(ctx, CaptureOptions{Text: "Ideas:\n\nfirst\nsecond", Target: "Journal/Log.md", Time: at})
+		if err != nil {
+			t.Fatalf("Capture() error = %v", err)
+		}
+		if result.Created || result.HeadingAdded || result.Line != 8 || !slices.Equal(result.Lines, []string{"- 09:05 Ideas:", "", "  first", "  second"}) {
+			t.Errorf("Capture() = %+v", result)
+		}
+		content, _ := v.Read(ctx, "Journal/Log.md")
+		want := "---\ntags: [log]\n---\n# Log\n\n## 2024-06-01\n- 08:00 Coffee\n- 09:05 Ideas:\n\n  first\n  second\n\n## 2024-05-31\n- 18:00 Older\n"
+		if content != want {
+			t.Errorf("Log.md = %q, want %q", content, want)
+		}
+
+		// A new day's heading goes at the end, after a blank line
+		result, err = v.Capture(ctx, CaptureOptions{Text: "Tomorrow", Target: "Journal/Log.md", Time: at.AddDate(0, 0, 1)})
+		if err != nil {
+			t.Fatalf("Capture() error = %v", err)
+		}
+		content, _ = v.Read(ctx, "Journal/Log.md")
+		if !result.HeadingAdded || !strings.HasSuffix(content, "- 18:00 Older\n\n## 2024-06-02\n- 09:05 Tomorrow\n") || result.Line != 17 {
+			t.Errorf("Capture() = %+v, content %q", result, content)
+		}
+	})
+
+	t.Run("settings", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		writeFiles(t, tmpDir, map[string]string{"Notes/Capture.md": "Loose notes"})
+		v, err := NewVault(tmpDir, WithCapture(CaptureSettings{Note: "Notes/Capture.md", Entry: "* [{date} {time}] {text}", TimeFormat: "3:04pm"}))
+		if err != nil {
+			t.Fatalf("Failed to create vault: %v", err)
+		}
+		if _, err := v.Capture(ctx, CaptureOptions{Text: "no heading", Time: at}); err != nil {
+			t.Fatalf("Capture() error = %v", err)
+		}
+		content, _ := v.Read(ctx, "Notes/Capture.md")
+		if content != "Loose notes\n* [2024-06-01 9:05am] no heading\n" {
+			t.Errorf("Capture.md = %q", content)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		v, err := NewVault(t.TempDir())
+		if err != nil {
+			t.Fatalf("Failed to create vault: %v", err)
+		}
+		for _, opts := range []CaptureOptions{{Text: "  \n "}, {Text: "x", Tags: []string{"two words"}}} {
+			if _, err := v.Capture(ctx, opts); !errors.Is(err, ErrInvalidCapture) {
+				t.Errorf("Capture(%+v) error = %v, want ErrInvalidCapture", opts, err)
+			}
+		}
+		if _, err := v.Capture(ctx, CaptureOptions{Text: "x", Target: "inbox.txt"}); !errors.Is(err, ErrNotMarkdown) {
+			t.Errorf("Capture() into a text file error = %v, want ErrNotMarkdown", err)
+		}
+	})
+}
+
+func TestCaptureConcurrent(t *testing.T) {
+	tmpDir := t.TempDir()
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	at := time.Date(2024, 6, 1, 9, 5, 0, 0, time.Local)
+
+	const captures = 20
+	var wg sync.WaitGroup
+	for i := range captures {
+		wg.Go(func() {
+			if _, err := v.Capture(context.Background(), CaptureOptions{Text: fmt.Sprintf("entry %d", i), Time: at}); err != nil {
+				t.Errorf("Capture(%d) error = %v", i, err)
+			}
+		})
+	}
+	wg.Wait()
+
+	raw, err := os.ReadFile(filepath.Join(tmpDir, "Inbox.md"))
+	if err != nil {
+		t.Fatalf("Failed to read inbox: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(raw), "\n"), "\n")
+	if len(lines) != captures+1 || lines[0] != "## 2024-06-01" {
+		t.Fatalf("Inbox.md = %q, want one heading and %d entries", raw, captures)
+	}
+	for i := range captures {
+		if !slices.Contains(lines, fmt.Sprintf("- 09:05 entry %d", i)) {
+			t.Errorf("entry %d is missing from %q", i, raw)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package vault
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ChangeKind describes how a note changed
+type ChangeKind string
+
+// Kinds of change reported by Changes
+const (
+	ChangeCreated  ChangeKind = "created"
+	ChangeModified ChangeKind = "modified"
+	ChangeDeleted  ChangeKind = "deleted"
+)
+
+// maxSnapshots bounds the snapshots kept for cursors; older cursors fall
+// back to comparing modification times
+const maxSnapshots = 8
+
+// ChangesOptions selects the starting point of Changes
+type ChangesOptions struct {
+	Since  time.Time // Report notes created or modified after this time
+	Cursor string    // Cursor returned by an earlier call, overrides Since
+}
+
+// NoteChange is a note created, modified or deleted since the starting point
+type NoteChange struct {
+	Path        string     `json:"path"`
+	Change      ChangeKind `json:"change"`
+	Modified    time.Time  `json:"modified,omitzero"`      // Zero for deleted notes
+	ContentHash string     `json:"content_hash,omitempty"` // Empty for deleted notes
+}
+
+// ChangeSet lists the changes since the starting point, sorted by path
+type ChangeSet struct {
+	Changes []NoteChange `json:"changes"`
+	Cursor  string       `json:"cursor"` // Starting point for the next call
+
+	// DeletionsTracked reports whether deleted notes are included. They
+	// are only known for cursors of recent calls to this process; other
+	// starting points compare modification times.
+	DeletionsTracked bool `json:"deletions_tracked"`
+}
+
+// changeLog remembers the content hashes seen by recent Changes calls so a
+// later call with their cursor can report deletions and exact edits
+// The zero value is ready to use
+type changeLog struct {
+	mu        sync.Mutex
+	epoch     int64 // Identifies this process in cursors
+	seq       uint64
+	snapshots map[uint64]map[string]string // seq -> path -> content hash
+}
+
+// changeCursor is the decoded form of a cursor
+type changeCursor struct {
+	epoch int64
+	seq   uint64
+	taken time.Time // When the snapshot walk started
+}
+
+// String encodes the cursor as an opaque token
+func (c changeCursor) String() string {
+	raw := fmt.Sprintf("%d:%d:%d", c.epoch, c.seq, c.taken.UnixNano())
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// parseChangeCursor decodes a cursor returned by Changes
+func parseChangeCursor(token string) (changeCursor, error) {
+	invalid := fmt.Errorf("%w: not a cursor returned by changed_notes", ErrInvalidCursor)
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return changeCursor{}, invalid
+	}
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 3 {
+		return changeCursor{}, invalid
+	}
+	epoch, err1 := strconv.ParseInt(parts[0], 10, 64)
+	seq, err2 := strconv.ParseUint(parts[1], 10, 64)
+	taken, err3 := strconv.ParseInt(parts[2], 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return changeCursor{}, invalid
+	}
+	return changeCursor{epoch: epoch, seq: seq, taken: time.Unix(0, taken)}, nil
+}
+
+// snapshot returns the hashes recorded for cursor, if still kept
+func (l *changeLog) snapshot(cursor changeCursor) (map[string]string, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if cursor.epoch != l.epoch {
+		return nil, false
+	}
+	hashes, ok := l.snapshots[cursor.seq]
+	return hashes, ok
+}
+
+// record keeps hashes, dropping the oldest snapshot beyond maxSnapshots,
+// and returns the cursor that refers to them
+func (l *changeLog) record(hashes map[string]string, taken time.Time) changeCursor {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.snapshots == nil {
+		l.epoch = time.Now().UnixNano()
+		l.snapshots = make(map[uint64]map[string]string)
+	}
+	l.seq++
+	l.snapshots[l.seq] = hashes
+	delete(l.snapshots, l.seq-maxSnapshots)
+
+	return changeCursor{epoch: l.epoch, seq: l.seq, taken: taken}
+}
+
+// Changes reports the notes created, modified or deleted since opts.Since
+// or since the call that returned opts.Cursor. Every call reads the whole
+// vault through the cache, so unchanged notes cost a stat each.
+func (v *vault) Changes(ctx context.Context, opts ChangesOptions) (ChangeSet, error) {
+	since := opts.Since
+	var previous map[string]string
+	if opts.Cursor != "" {
+		cursor, err := parseChangeCursor(opts.Cursor)
+		if err != nil {
+			return ChangeSet{}, err
+		}
+		since = cursor.taken
+		previous, _ = v.changes.snapshot(cursor)
+	}
+
+	taken := time.Now()
+	notes, err := v.walkNotes(ctx, ListOptions{Recursive: true, IncludeCanvas: true}, nil)
+	if err != nil {
+		return ChangeSet{}, err
+	}
+
+	current := make(map[string]string, len(notes))
+	changes := []NoteChange{}
+	for _, note := range notes {
+		current[note.Path] = note.ContentHash
+
+		change := NoteChange{Path: note.Path, Modified: note.Modified, ContentHash: note.ContentHash}
+		if previous != nil {
+			hash, seen := previous[note.Path]
+			switch {
+			case !seen:
+				change.Change = ChangeCreated
+			case hash != note.ContentHash:
+				change.Change = ChangeModified
+			default:
+				continue
+			}
+		} else {
+			if !note.Modified.After(since) {
+				continue
+			}
+			change.Change = ChangeModified
+			if note.Created.After(since) {
+				change.Change = ChangeCreated
+			}
+		}
+		changes = append(changes, change)
+	}
+
+	for path := range previous {
+		if _, ok := current[path]; !ok {
+			changes = append(changes, NoteChange{Path: path, Change: ChangeDeleted})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+
+	return ChangeSet{
+		Changes:          changes,
+		Cursor:           v.changes.record(current, taken).String(),
+		DeletionsTracked: previous != nil,
+	}, nil
+}
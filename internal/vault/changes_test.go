@@ -0,0 +1,154 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// changeKinds maps each changed path to its kind
+func changeKinds(set ChangeSet) map[string]ChangeKind {
+	kinds := make(map[string]ChangeKind, len(set.Changes))
+	for _, change := range set.Changes {
+		kinds[change.Path] = change.Change
+	}
+	return kinds
+}
+
+func TestChanges(t *testing.T) {
+	v, tmpDir := setupTestVault(t)
+	ctx := context.Background()
+
+	first, err := v.Changes(ctx, ChangesOptions{})
+	if err != nil {
+		t.Fatalf("Changes() error = %v", err)
+	}
+	if len(first.Changes) != 5 || first.DeletionsTracked || first.Cursor == "" {
+		t.Fatalf("Changes() = %+v, want every note created", first)
+	}
+	for _, change := range first.Changes {
+		content, err := os.ReadFile(filepath.Join(tmpDir, change.Path))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", change.Path, err)
+		}
+		if change.Change != ChangeCreated || change.ContentHash != contentHash(string(content)) {
+			t.Errorf("Change = %+v, want created with the content hash", change)
+		}
+	}
+
+	if err := v.Update(ctx, "note1.md", "Note 1 rewritten"); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err := v.Create(ctx, "new.md", "New note"); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := os.Remove(filepath.Join(tmpDir, "other", "note5.md")); err != nil {
+		t.Fatalf("Failed to delete note: %v", err)
+	}
+	// Touched but unchanged content is not a change
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(tmpDir, "note2.md"), future, future); err != nil {
+		t.Fatalf("Failed to touch note: %v", err)
+	}
+
+	second, err := v.Changes(ctx, ChangesOptions{Cursor: first.Cursor})
+	if err != nil {
+		t.Fatalf("Changes() error = %v", err)
+	}
+	want := map[string]ChangeKind{
+		"new.md":         ChangeCreated,
+		"note1.md":       ChangeModified,
+		"other/note5.md": ChangeDeleted,
+	}
+	if got := changeKinds(second); !second.DeletionsTracked || len(got) != len(want) || got["new.md"] != want["new.md"] ||
+		got["note1.md"] != want["note1.md"] || got["other/note5.md"] != want["other/note5.md"] {
+		t.Errorf("Changes() = %+v, want %v", second, want)
+	}
+	paths := make([]string, len(second.Changes))
+	for i, change := range second.Changes {
+		paths[i] = change.Path
+	}
+	if !slices.IsSorted(paths) {
+		t.Errorf("Changes not sorted by path: %v", paths)
+	}
+
+	third, err := v.Changes(ctx, ChangesOptions{Cursor: second.Cursor})
+	if err != nil {
+		t.Fatalf("Changes() error = %v", err)
+	}
+	if len(third.Changes) != 0 || !third.DeletionsTracked {
+		t.Errorf("Changes() = %+v, want no changes", third)
+	}
+}
+
+func TestChangesWithoutSnapshot(t *testing.T) {
+	v, tmpDir := setupTestVault(t)
+	ctx := context.Background()
+
+	old, err := v.Changes(ctx, ChangesOptions{})
+	if err != nil {
+		t.Fatalf("Changes() error = %v", err)
+	}
+
+	// A restarted server no longer has the snapshot and compares times
+	restarted, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(tmpDir, "note2.md"), future, future); err != nil {
+		t.Fatalf("Failed to touch note: %v", err)
+	}
+	if err := os.Remove(filepath.Join(tmpDir, "note1.md")); err != nil {
+		t.Fatalf("Failed to delete note: %v", err)
+	}
+
+	changes, err := restarted.Changes(ctx, ChangesOptions{Cursor: old.Cursor})
+	if err != nil {
+		t.Fatalf("Changes() error = %v", err)
+	}
+	if got := changeKinds(changes); changes.DeletionsTracked || len(got) != 1 || got["note2.md"] != ChangeModified {
+		t.Errorf("Changes() = %+v, want only note2.md modified", changes)
+	}
+
+	t.Run("since", func(t *testing.T) {
+		changes, err := v.Changes(ctx, ChangesOptions{Since: time.Now().Add(30 * time.Minute)})
+		if err != nil {
+			t.Fatalf("Changes() error = %v", err)
+		}
+		if got := changeKinds(changes); changes.DeletionsTracked || len(got) != 1 || got["note2.md"] != ChangeModified {
+			t.Errorf("Changes() = %+v, want only note2.md modified", changes)
+		}
+	})
+
+	t.Run("evicted cursor", func(t *testing.T) {
+		first, err := v.Changes(ctx, ChangesOptions{})
+		if err != nil {
+			t.Fatalf("Changes() error = %v", err)
+		}
+		for range maxSnapshots {
+			if _, err := v.Changes(ctx, ChangesOptions{}); err != nil {
+				t.Fatalf("Changes() error = %v", err)
+			}
+		}
+		changes, err := v.Changes(ctx, ChangesOptions{Cursor: first.Cursor})
+		if err != nil {
+			t.Fatalf("Changes() error = %v", err)
+		}
+		if changes.DeletionsTracked {
+			t.Errorf("Changes() = %+v, want the snapshot to be evicted", changes)
+		}
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		for _, cursor := range []string{"garbage!", "Zm9v"} {
+			if _, err := v.Changes(ctx, ChangesOptions{Cursor: cursor}); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("Changes(%q) error = %v, want ErrInvalidCursor", cursor, err)
+			}
+		}
+	})
+}
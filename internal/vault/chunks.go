@@ -0,0 +1,335 @@
+package vault
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Defaults of ChunkOptions
+const (
+	DefaultChunkSize    = 1000 // Characters
+	DefaultChunkOverlap = 100  // Characters
+	DefaultChunkLimit   = 500  // Chunks per page
+)
+
+// ChunkOptions selects the notes Chunks splits and how
+type ChunkOptions struct {
+	Path          string // Note to split, or folder whose notes are split; empty for the whole vault
+	Size          int    // Target characters per chunk, DefaultChunkSize if 0
+	Overlap       int    // Characters of a section's previous chunk repeated at the start of the next
+	Limit         int    // Chunks per page of a folder, DefaultChunkLimit if 0; whole notes are never split across pages
+	Cursor        string // Continues a folder after the page that returned it
+	IncludeHidden bool   // Walk files and directories whose name starts with a dot
+}
+
+// Chunk is a piece of a note sized for embedding
+type Chunk struct {
+	// ID is the note path, the anchor of the chunk's heading and a hash of
+	// its text, so it stays the same while that text is unchanged
+	ID        string   `json:"id"`
+	Path      string   `json:"path"`
+	Headings  []string `json:"headings"` // Breadcrumb of the headings above the chunk, outermost first
+	StartLine int      `json:"start_line"`
+	EndLine   int      `json:"end_line"` // Inclusive
+	Text      string   `json:"text"`
+	Tags      []string `json:"tags"` // Tags of the whole note
+}
+
+// ChunkPage is one page of the chunks of the notes selected by
+// ChunkOptions, grouped by note in path order
+type ChunkPage struct {
+	Chunks     []Chunk `json:"chunks"`
+	Notes      int     `json:"notes"`                 // Notes covered by this page
+	NextCursor string  `json:"next_cursor,omitempty"` // Set while notes remain
+}
+
+// ChunkCursor returns the cursor continuing a folder's chunks after the
+// note at path. Pages cut short by a caller resume with it.
+func ChunkCursor(path string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("chunks:" + path))
+}
+
+// parseChunkCursor returns the note path a cursor continues after
+func parseChunkCursor(cursor string) (string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	path, ok := strings.CutPrefix(string(raw), "chunks:")
+	if err != nil || !ok {
+		return "", fmt.Errorf("%w: not a cursor returned by export_chunks", ErrInvalidCursor)
+	}
+	return path, nil
+}
+
+// Chunks splits a note, or every note under a folder, into chunks along
+// heading and paragraph boundaries. A chunk never spans two sections, and
+// frontmatter and fenced code blocks stay whole even beyond Size.
+// Folders are returned a page of whole notes at a time, in path order.
+func (v *vault) Chunks(ctx context.Context, opts ChunkOptions) (ChunkPage, error) {
+	if opts.Size <= 0 {
+		opts.Size = DefaultChunkSize
+	}
+	opts.Overlap = min(max(opts.Overlap, 0), opts.Size/2)
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultChunkLimit
+	}
+
+	if strings.HasSuffix(strings.ToLower(opts.Path), ".md") {
+		fullPath, err := v.validatePath(opts.Path)
+		if err != nil {
+			return ChunkPage{}, err
+		}
+		stat, err := statNote(fullPath, opts.Path)
+		if err != nil {
+			return ChunkPage{}, err
+		}
+		entry, err := v.loadEntry(fullPath, stat.ModTime())
+		if err != nil {
+			return ChunkPage{}, fmt.Errorf("failed to read file: %w", err)
+		}
+		return ChunkPage{Chunks: chunkNote(v.relPath(fullPath), entry, opts.Size, opts.Overlap), Notes: 1}, nil
+	}
+
+	after := ""
+	if opts.Cursor != "" {
+		var err error
+		if after, err = parseChunkCursor(opts.Cursor); err != nil {
+			return ChunkPage{}, err
+		}
+	}
+
+	type loadedNote struct {
+		path  string
+		entry CacheEntry
+	}
+	var mu sync.Mutex
+	var notes []loadedNote
+	scope := ListOptions{Subpath: opts.Path, Recursive: true, IncludeHidden: opts.IncludeHidden}
+	skip := func(file noteFile) bool { return after != "" && file.relPath <= after }
+	_, err := v.walkNotesSkipping(ctx, scope, skip, func(file noteFile, entry CacheEntry) bool {
+		mu.Lock()
+		notes = append(notes, loadedNote{file.relPath, entry})
+		mu.Unlock()
+		return false
+	})
+	if err != nil {
+		return ChunkPage{}, err
+	}
+	slices.SortFunc(notes, func(a, b loadedNote) int {
+		return strings.Compare(a.path, b.path)
+	})
+
+	page := ChunkPage{Chunks: []Chunk{}}
+	for i, note := range notes {
+		chunks := chunkNote(note.path, note.entry, opts.Size, opts.Overlap)
+		if page.Notes > 0 && len(page.Chunks)+len(chunks) > opts.Limit {
+			page.NextCursor = ChunkCursor(notes[i-1].path)
+			break
+		}
+		page.Chunks = append(page.Chunks, chunks...)
+		page.Notes++
+	}
+	return page, nil
+}
+
+// chunkBlock is a run of lines that is kept together when possible
+type chunkBlock struct {
+	start, end int  // 1-based, inclusive
+	atomic     bool // Frontmatter or a fenced code block, never split
+	heading    bool // Heading lines, which start a section
+}
+
+// chunkSection is a heading, or the start of the note, with the blocks
+// up to the next heading
+type chunkSection struct {
+	headings []string
+	blocks   []chunkBlock
+}
+
+// chunkNote splits a note into chunks of about size characters
+func chunkNote(path string, entry CacheEntry, size, overlap int) []Chunk {
+	lines := strings.Split(entry.Content, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, "\r")
+	}
+	tags := entry.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	chunks := []Chunk{}
+	seen := make(map[string]int)
+	for _, section := range noteSections(lines, entry.Headings) {
+		for _, r := range packSection(lines, section.blocks, size, overlap) {
+			text := strings.Join(lines[r[0]-1:r[1]], "\n")
+			anchor := ""
+			if len(section.headings) > 0 {
+				anchor = section.headings[len(section.headings)-1]
+			}
+			id := fmt.Sprintf("%s#%s@%s", path, anchor, contentHash(text)[:12])
+			if seen[id]++; seen[id] > 1 {
+				id = fmt.Sprintf("%s-%d", id, seen[id])
+			}
+			chunks = append(chunks, Chunk{
+				ID:        id,
+				Path:      path,
+				Headings:  slices.Clone(section.headings),
+				StartLine: r[0],
+				EndLine:   r[1],
+				Text:      text,
+				Tags:      tags,
+			})
+		}
+	}
+	return chunks
+}
+
+// noteSections groups the lines of a note into sections of blocks: the
+// frontmatter, paragraphs separated by blank lines, fenced code blocks
+// and the lines of each heading
+func noteSections(lines []string, headings []Heading) []chunkSection {
+	headingAt := make(map[int]Heading, len(headings))
+	for _, h := range headings {
+		headingAt[h.Line] = h
+	}
+
+	sections := []chunkSection{{headings: []string{}}}
+	current := &sections[0]
+	var trail []Heading // Enclosing headings of the current section
+	add := func(b chunkBlock) {
+		current.blocks = append(current.blocks, b)
+	}
+
+	i := 0 // 0-based index of the next line
+	if frontmatter, _, ok := SplitFrontmatter(strings.Join(lines, "\n")); ok {
+		i = strings.Count(frontmatter, "\n") + 2
+		add(chunkBlock{start: 1, end: i, atomic: true})
+	}
+
+	paragraph := -1 // Index where the open paragraph started
+	closeParagraph := func(end int) {
+		if paragraph >= 0 {
+			add(chunkBlock{start: paragraph + 1, end: end})
+			paragraph = -1
+		}
+	}
+
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if h, ok := headingAt[i+1]; ok {
+			closeParagraph(i)
+			for len(trail) > 0 && trail[len(trail)-1].Level >= h.Level {
+				trail = trail[:len(trail)-1]
+			}
+			trail = append(trail, h)
+			names := make([]string, len(trail))
+			for j, t := range trail {
+				names[j] = t.Text
+			}
+			sections = append(sections, chunkSection{headings: names})
+			current = &sections[len(sections)-1]
+
+			// Setext headings run to their underline
+			end := i
+			if !headingRegex.MatchString(lines[i]) {
+				for end+1 < len(lines) && !setextRegex.MatchString(lines[end+1]) {
+					end++
+				}
+				end = min(end+1, len(lines)-1)
+			}
+			add(chunkBlock{start: i + 1, end: end + 1, heading: true})
+			i = end
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			closeParagraph(i)
+			end := i + 1
+			for end < len(lines) && !isFence(lines[end]) {
+				end++
+			}
+			end = min(end, len(lines)-1)
+			add(chunkBlock{start: i + 1, end: end + 1, atomic: true})
+			i = end
+		case trimmed == "":
+			closeParagraph(i)
+		case paragraph < 0:
+			paragraph = i
+		}
+	}
+	closeParagraph(len(lines))
+
+	// Drop the empty section before a leading heading
+	if len(sections[0].blocks) == 0 {
+		sections = sections[1:]
+	}
+	return sections
+}
+
+// isFence reports whether line opens or closes a fenced code block
+func isFence(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")
+}
+
+// packSection packs the blocks of a section into line ranges of about
+// size characters. Paragraphs longer than size are split between lines;
+// each range after the first repeats up to overlap characters of the
+// previous one's closing lines, unless those lie in an atomic block.
+func packSection(lines []string, blocks []chunkBlock, size, overlap int) [][2]int {
+	lineSize := func(n int) int { return utf8.RuneCountInString(lines[n-1]) + 1 }
+
+	// Oversized paragraphs become one piece per line
+	var pieces []chunkBlock
+	for _, b := range blocks {
+		total := 0
+		for n := b.start; n <= b.end; n++ {
+			total += lineSize(n)
+		}
+		if b.atomic || b.heading || total <= size {
+			pieces = append(pieces, b)
+			continue
+		}
+		for n := b.start; n <= b.end; n++ {
+			pieces = append(pieces, chunkBlock{start: n, end: n})
+		}
+	}
+
+	var ranges [][2]int
+	start, end, used := 0, 0, 0
+	var current []chunkBlock
+	for _, p := range pieces {
+		pieceSize := 0
+		for n := p.start; n <= p.end; n++ {
+			pieceSize += lineSize(n)
+		}
+		// A heading stays with the content after it
+		headingOnly := len(current) == 1 && current[0].heading
+		if len(current) > 0 && !headingOnly && used+pieceSize > size {
+			ranges = append(ranges, [2]int{start, end})
+
+			// Carry over closing lines of the last piece, if it is plain text
+			last := current[len(current)-1]
+			start, used = p.start, 0
+			if !last.atomic && !last.heading {
+				for n := end; n > ranges[len(ranges)-1][0] && n >= last.start && used+lineSize(n) <= overlap; n-- {
+					start, used = n, used+lineSize(n)
+				}
+			}
+			current = nil
+		}
+		if len(current) == 0 && used == 0 {
+			start = p.start
+		}
+		current = append(current, p)
+		end, used = p.end, used+pieceSize
+	}
+	if len(current) > 0 {
+		ranges = append(ranges, [2]int{start, end})
+	}
+	return ranges
+}
@@ -0,0 +1,99 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const chunkedNote = "---\ntags: [rag]\n---\nPreamble #ml\n\n# Guide\n\nFirst paragraph of the guide.\n\n" +
+	"Second paragraph, a bit longer than the first one.\n\n```go\nfunc main() {\n\n\tprintln(\"hi\")\n}\n```\n\n" +
+	"## Setup\n\nsetup line no. 1\nsetup line no. 2\nsetup line no. 3\nsetup line no. 4\n\nSubtitle\n--------\n\nUnder the setext heading.\n"
+
+func TestChunkNote(t *testing.T) {
+	entry := newCacheEntry(chunkedNote, time.Time{})
+	chunks := chunkNote("guide.md", entry, 60, 20)
+
+	type span struct {
+		headings   []string
+		start, end int
+	}
+	var got []span
+	for _, c := range chunks {
+		got = append(got, span{c.Headings, c.StartLine, c.EndLine})
+		if !reflect.DeepEqual(c.Tags, []string{"ml"}) {
+			t.Errorf("Tags = %v, want the note's tags", c.Tags)
+		}
+	}
+	want := []span{
+		{[]string{}, 1, 4},                      // Frontmatter with the preamble
+		{[]string{"Guide"}, 6, 8},               // Heading with its first paragraph
+		{[]string{"Guide"}, 10, 10},             // Paragraph that does not fit with it
+		{[]string{"Guide"}, 12, 17},             // Code block, whole
+		{[]string{"Guide", "Setup"}, 19, 23},    // Lines until the size is reached
+		{[]string{"Guide", "Setup"}, 23, 24},    // Overlapping the previous line
+		{[]string{"Guide", "Subtitle"}, 26, 29}, // Setext heading of the same level
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Chunks = %+v, want %+v", got, want)
+	}
+	if !strings.HasPrefix(chunks[3].Text, "```go") || !strings.HasSuffix(chunks[3].Text, "```") {
+		t.Errorf("Code chunk = %q, want the whole block", chunks[3].Text)
+	}
+
+	// IDs depend on the text only, not on the chunks before
+	if !strings.HasPrefix(chunks[1].ID, "guide.md#Guide@") {
+		t.Errorf("ID = %q, want path, anchor and hash", chunks[1].ID)
+	}
+	edited := newCacheEntry(strings.Replace(chunkedNote, "Preamble", "Longer preamble text", 1), time.Time{})
+	again := chunkNote("guide.md", edited, 60, 20)
+	if again[0].ID == chunks[0].ID || again[1].ID != chunks[1].ID || again[len(again)-1].ID != chunks[len(chunks)-1].ID {
+		t.Error("Expected only the edited chunk to get a new ID")
+	}
+}
+
+func TestChunks(t *testing.T) {
+	tmpDir := t.TempDir()
+	notes := make(map[string]string)
+	for _, path := range []string{"docs/a.md", "docs/b.md", "docs/c.md", "other.md"} {
+		notes[path] = "# " + path + "\n\nOne\n\n# Two\n\nTwo"
+	}
+	writeFiles(t, tmpDir, notes)
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	ctx := context.Background()
+
+	// Pages hold whole notes
+	var paths []string
+	cursor := ""
+	for range 4 {
+		page, err := v.Chunks(ctx, ChunkOptions{Path: "docs", Limit: 3, Cursor: cursor})
+		if err != nil {
+			t.Fatalf("Chunks failed: %v", err)
+		}
+		if page.Notes != 1 || len(page.Chunks) != 2 {
+			t.Fatalf("Page = %+v, want one note of two chunks", page)
+		}
+		paths = append(paths, page.Chunks[0].Path)
+		if cursor = page.NextCursor; cursor == "" {
+			break
+		}
+	}
+	if want := []string{"docs/a.md", "docs/b.md", "docs/c.md"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("Paged through %v, want %v", paths, want)
+	}
+
+	page, err := v.Chunks(ctx, ChunkOptions{Path: "other.md"})
+	if err != nil || page.Notes != 1 || len(page.Chunks) != 2 {
+		t.Errorf("Chunks of a note = %+v, %v", page, err)
+	}
+
+	if _, err := v.Chunks(ctx, ChunkOptions{Cursor: "bogus"}); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Expected ErrInvalidCursor, got %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Default blob thresholds; see BlobThresholds
+const (
+	DefaultBlobMinSize    = 64 << 10
+	DefaultBlobLineLength = 200
+	DefaultBlobDataRatio  = 0.5
+)
+
+// BlobThresholds decide when a note is classified as a blob: a note
+// mostly made of embedded data, such as an Excalidraw drawing or a pasted
+// base64 image. Data is any run of printable ASCII without whitespace at
+// least LineLength bytes long; prose never has words that long, and
+// non-Latin text is never data.
+type BlobThresholds struct {
+	MinSize    int64   // Notes smaller than this are never blobs
+	LineLength int     // Shortest run of bytes without whitespace counted as data
+	DataRatio  float64 // Share of the note's bytes in data that makes it a blob
+}
+
+// WithBlobThresholds sets when a note is classified as a blob. Zero
+// fields keep the defaults of 64 KiB, 200 bytes and a ratio of 0.5.
+func WithBlobThresholds(t BlobThresholds) Option {
+	return func(v *vault) {
+		if t.MinSize >= 1 {
+			v.blobThresholds.MinSize = t.MinSize
+		}
+		if t.LineLength >= 1 {
+			v.blobThresholds.LineLength = t.LineLength
+		}
+		if t.DataRatio > 0 && t.DataRatio <= 1 {
+			v.blobThresholds.DataRatio = t.DataRatio
+		}
+	}
+}
+
+// ProseNote is a note as read_note shows it unless the full content is
+// requested: a blob is reduced to its prose, with each stretch of data
+// replaced by a notice
+type ProseNote struct {
+	Content string       // Whole content, or the prose of a blob
+	Blob    *BlobSummary // Set when the note is a blob
+}
+
+// BlobSummary describes the data left out of a blob's prose
+type BlobSummary struct {
+	Bytes     int      // Size of the whole note
+	DataBytes int      // Bytes of data left out
+	DataRuns  int      // Runs of data left out
+	Tags      []string // Tags found in the prose
+	Links     int      // Links found in the prose
+}
+
+// isBlob reports whether content is mostly data by t
+func (t BlobThresholds) isBlob(content string) bool {
+	if int64(len(content)) < t.MinSize {
+		return false
+	}
+	data := 0
+	forDataRuns(content, t.LineLength, func(start, end int) {
+		data += end - start
+	})
+	return float64(data) >= t.DataRatio*float64(len(content))
+}
+
+// forDataRuns calls fn with the bounds of every run of at least length
+// printable ASCII bytes in content
+func forDataRuns(content string, length int, fn func(start, end int)) {
+	start := -1
+	for i := 0; i <= len(content); i++ {
+		if i < len(content) && content[i] > ' ' && content[i] < 0x7f {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 && i-start >= length {
+			fn(start, i)
+		}
+		start = -1
+	}
+}
+
+// proseParts returns content with its data runs removed. Line breaks are
+// kept, so line numbers parsed from the prose match the note's.
+func proseParts(content string, length int) string {
+	var b strings.Builder
+	last := 0
+	forDataRuns(content, length, func(start, end int) {
+		b.WriteString(content[last:start])
+		last = end
+	})
+	if last == 0 {
+		return content
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+// newNoteEntry parses content into a cache entry, classifying it as a blob
+// by v's thresholds. The tags, links and other metadata of a blob come
+// from its prose alone.
+func (v *vault) newNoteEntry(content string, mtime time.Time) CacheEntry {
+	if !v.blobThresholds.isBlob(content) {
+		return newCacheEntry(content, mtime)
+	}
+	prose := proseParts(content, v.blobThresholds.LineLength)
+	entry := newCacheEntry(prose, mtime)
+	entry.Content = content
+	entry.ContentHash = contentHash(content)
+	entry.Prose = prose
+	entry.Blob = true
+	return entry
+}
+
+// ReadProse returns the content of a note, or for a blob its prose with
+// each stretch of data lines replaced by a notice of the bytes left out
+func (v *vault) ReadProse(ctx context.Context, path string) (ProseNote, error) {
+	content, err := v.Read(ctx, path)
+	if err != nil {
+		return ProseNote{}, err
+	}
+	if !v.blobThresholds.isBlob(content) {
+		return ProseNote{Content: content}, nil
+	}
+
+	summary := &BlobSummary{Bytes: len(content)}
+	prose := summarizeData(content, v.blobThresholds.LineLength, summary)
+	entry := newCacheEntry(proseParts(content, v.blobThresholds.LineLength), time.Time{})
+	summary.Tags = entry.Tags
+	summary.Links = len(entry.Links)
+	return ProseNote{Content: prose, Blob: summary}, nil
+}
+
+// summarizeData replaces the data runs of content with notices, counting
+// them in summary. Lines holding only data, and the blank lines between
+// them, collapse into a single notice line.
+func summarizeData(content string, length int, summary *BlobSummary) string {
+	var b strings.Builder
+	omitted := 0 // Data bytes of the current stretch of data lines
+	blanks := 0  // Blank lines held back since its last data line
+	flush := func() {
+		if omitted > 0 {
+			fmt.Fprintf(&b, "[%d bytes of data omitted]\n", omitted)
+			omitted = 0
+		}
+		b.WriteString(strings.Repeat("\n", blanks))
+		blanks = 0
+	}
+
+	for line := range strings.SplitAfterSeq(content, "\n") {
+		data, runs := 0, 0
+		forDataRuns(line, length, func(start, end int) {
+			data += end - start
+			runs++
+		})
+		summary.DataBytes += data
+		summary.DataRuns += runs
+
+		switch {
+		case data > 0 && strings.TrimSpace(proseParts(line, length)) == "":
+			omitted += data
+			blanks = 0
+		case omitted > 0 && strings.TrimSpace(line) == "":
+			blanks++
+		default:
+			flush()
+			b.WriteString(markData(line, length))
+		}
+	}
+	flush()
+	return b.String()
+}
+
+// markData replaces each data run in line with a notice of its size
+func markData(line string, length int) string {
+	var b strings.Builder
+	last := 0
+	forDataRuns(line, length, func(start, end int) {
+		b.WriteString(line[last:start])
+		fmt.Fprintf(&b, "[%d bytes of data omitted]", end-start)
+		last = end
+	})
+	if last == 0 {
+		return line
+	}
+	b.WriteString(line[last:])
+	return b.String()
+}
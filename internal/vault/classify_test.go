@@ -0,0 +1,156 @@
+package vault
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// base64Data returns about size bytes of base64 encoded random data
+func base64Data(size int) string {
+	raw := make([]byte, size*3/4)
+	rand.New(rand.NewSource(1)).Read(raw)
+	return base64.StdEncoding.EncodeToString(raw)
+}
+
+// excalidrawData returns the base64 drawing of an Excalidraw note of
+// about size bytes, in the 256 character chunks the plugin writes
+func excalidrawData(size int) string {
+	encoded := base64Data(size)
+	var chunks []string
+	for len(encoded) > 256 {
+		chunks = append(chunks, encoded[:256])
+		encoded = encoded[256:]
+	}
+	return strings.Join(append(chunks, encoded), "\n\n")
+}
+
+// excalidrawNote returns an Excalidraw note holding the drawing data
+func excalidrawNote(data string) string {
+	return "---\nexcalidraw-plugin: parsed\ntags: [excalidraw]\n---\n" +
+		"==⚠  Switch to EXCALIDRAW VIEW in the MORE OPTIONS menu of this document. ⚠== You can decompress Drawing data with the command palette: 'Decompress current Excalidraw file'. For more info check in plugin settings under 'Saving'\n\n\n" +
+		"# Excalidraw Data\n\n## Text Elements\nLoad balancer ^k3Jd8s2a\n\nSee [[Architecture]] #diagram ^p0s9d8f7\n\n" +
+		"%%\n## Drawing\n```compressed-json\n" + data + "\n```\n%%"
+}
+
+// repeatLines repeats line until the result holds at least size bytes
+func repeatLines(line string, size int) string {
+	return strings.Repeat(line+"\n", size/len(line)+1)
+}
+
+func TestBlobThresholds(t *testing.T) {
+	trackingURL := "https://example.com/redirect?url=" + strings.Repeat("aHR0cHM6Ly9leGFtcGxlLm9yZy8", 12) + "&utm_source=newsletter"
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{"excalidraw drawing", excalidrawNote(excalidrawData(200 << 10)), true},
+		{"small excalidraw drawing", excalidrawNote(excalidrawData(20 << 10)), false},
+		{"pasted image", "# Screenshot\n\n![shot](data:image/png;base64," + base64Data(100<<10) + ")\n", true},
+		{"long URL lines", repeatLines("- [Release notes for the spring update](https://example.com/blog/2024/03/release-notes-for-the-spring-update-with-many-words-in-the-slug?utm_source=feed&utm_medium=rss)", 200<<10), false},
+		{"notes with tracking URLs", repeatLines(strings.Repeat("Worth keeping for later, from the newsletter of the week:\n", 10)+"- [redirect]("+trackingURL+")", 200<<10), false},
+		{"long prose", repeatLines("It was the best of times, it was the worst of times, it was the age of wisdom, it was the age of foolishness.", 1<<20), false},
+		{"prose on one line", strings.Repeat("A very long paragraph written without any line breaks at all. ", 4<<10), false},
+		{"CJK prose", repeatLines("吾輩は猫である。名前はまだ無い。どこで生れたかとんと見当がつかぬ。何でも薄暗いじめじめした所でニャーニャー泣いていた事だけは記憶している。", 500<<10), false},
+		{"markdown table", repeatLines("| 2024-03-01 | deploy | ok | 12ms | https://ci.example.com/jobs/1234567/artifacts/logs |", 200<<10), false},
+	}
+	thresholds := BlobThresholds{MinSize: DefaultBlobMinSize, LineLength: DefaultBlobLineLength, DataRatio: DefaultBlobDataRatio}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := thresholds.isBlob(tt.content); got != tt.want {
+				t.Errorf("isBlob() = %v, want %v for %d bytes", got, tt.want, len(tt.content))
+			}
+		})
+	}
+}
+
+func TestSummarizeData(t *testing.T) {
+	content := "# Title\nAAAAAAAAAAAAAA\n\nBBBBBBBB\n\ntext CCCCCCCCC and ünïcödé_wörds end\n"
+	var summary BlobSummary
+	got := summarizeData(content, 8, &summary)
+	if want := "# Title\n[22 bytes of data omitted]\n\ntext [9 bytes of data omitted] and ünïcödé_wörds end\n"; got != want {
+		t.Errorf("summarizeData() = %q, want %q", got, want)
+	}
+	if summary.DataBytes != 31 || summary.DataRuns != 3 {
+		t.Errorf("summarizeData() summary = %+v, want 31 bytes in 3 runs", summary)
+	}
+	if got, want := proseParts(content, 8), "# Title\n\n\n\n\ntext  and ünïcödé_wörds end\n"; got != want {
+		t.Errorf("proseParts() = %q, want %q", got, want)
+	}
+}
+
+func TestBlobNotes(t *testing.T) {
+	tmpDir := t.TempDir()
+	data := excalidrawData(200 << 10)
+	drawing := excalidrawNote(data)
+	prose := repeatLines("Notes on the load balancer, see https://example.com/docs/load-balancing for details.", 200<<10)
+	for path, content := range map[string]string{"drawing.md": drawing, "prose.md": prose} {
+		if err := os.WriteFile(filepath.Join(tmpDir, path), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file %s: %v", path, err)
+		}
+	}
+	vi, err := NewVault(tmpDir, WithSearchIndex())
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	v := vi.(*vault)
+	ctx := context.Background()
+
+	search := func(query string) []string {
+		t.Helper()
+		notes, err := v.Search(ctx, SearchOptions{Query: query, QueryMode: QueryLiteral, PreviewLength: 40})
+		if err != nil {
+			t.Fatalf("Search(%q) error = %v", query, err)
+		}
+		var paths []string
+		for _, n := range notes {
+			paths = append(paths, n.Path)
+			if strings.Contains(n.Excerpt, data[:20]) {
+				t.Errorf("Search(%q) excerpt of %s = %q, want it without data", query, n.Path, n.Excerpt)
+			}
+		}
+		slices.Sort(paths)
+		return paths
+	}
+	if got := search(data[1000:1012]); len(got) != 0 {
+		t.Errorf("Search() for drawing data = %v, want no notes", got)
+	}
+	if got := search("load balancer"); !slices.Equal(got, []string{"drawing.md", "prose.md"}) {
+		t.Errorf("Search() for prose = %v, want both notes", got)
+	}
+
+	fullPath := filepath.Join(tmpDir, "drawing.md")
+	cached, ok := v.cache.Get(fullPath)
+	if !ok || !cached.Blob || !cached.ContentOmitted || strings.Contains(cached.Prose, data[:20]) {
+		t.Errorf("cache entry = blob %v, omitted %v, %d bytes of prose; want a blob without data", cached.Blob, cached.ContentOmitted, len(cached.Prose))
+	}
+	if !slices.Equal(cached.Tags, []string{"diagram"}) || len(cached.Links) != 1 || cached.ContentHash != contentHash(drawing) {
+		t.Errorf("cache entry tags = %v, links = %v; want the prose's", cached.Tags, cached.Links)
+	}
+
+	note, err := v.ReadProse(ctx, "drawing.md")
+	if err != nil {
+		t.Fatalf("ReadProse() error = %v", err)
+	}
+	if note.Blob == nil || note.Blob.Bytes != len(drawing) || note.Blob.DataBytes < 200<<10 || note.Blob.Links != 1 {
+		t.Fatalf("ReadProse() summary = %+v", note.Blob)
+	}
+	want := fmt.Sprintf("## Text Elements\nLoad balancer ^k3Jd8s2a\n\nSee [[Architecture]] #diagram ^p0s9d8f7\n\n%%%%\n## Drawing\n```compressed-json\n[%d bytes of data omitted]\n```\n%%%%", note.Blob.DataBytes)
+	if !strings.HasSuffix(note.Content, want) {
+		t.Errorf("ReadProse() content ends %q", note.Content[max(len(note.Content)-200, 0):])
+	}
+	if content, err := v.Read(ctx, "drawing.md"); err != nil || content != drawing {
+		t.Errorf("Read() = %d bytes, %v; want the whole drawing", len(content), err)
+	}
+
+	if note, err := v.ReadProse(ctx, "prose.md"); err != nil || note.Blob != nil || note.Content != prose {
+		t.Errorf("ReadProse() of prose = %+v, %v; want it whole", note.Blob, err)
+	}
+}
@@ -0,0 +1,72 @@
+package vault
+
+import (
+	"strings"
+	"time"
+)
+
+// defaultCreatedFields are the frontmatter properties checked, in order,
+// for a note's creation date
+var defaultCreatedFields = []string{"created", "date"}
+
+// WithCreatedFields sets the frontmatter properties checked, in order, for
+// a note's creation date before falling back to file times. With no
+// fields frontmatter is ignored.
+func WithCreatedFields(fields ...string) Option {
+	return func(v *vault) {
+		v.createdFields = fields
+	}
+}
+
+// WithDateFormat adds a Go time layout tried before the built-in ISO
+// formats when parsing frontmatter dates, e.g. "02.01.2006"
+func WithDateFormat(layout string) Option {
+	return func(v *vault) {
+		v.dateFormat = layout
+	}
+}
+
+// resolveCreated determines when a note was created: the first frontmatter
+// date found, then the file's birth time where the platform records it,
+// then mtime
+func (v *vault) resolveCreated(fullPath string, properties map[string]any, mtime time.Time) time.Time {
+	if t, ok := v.frontmatterCreated(properties); ok {
+		return t
+	}
+	if t, ok := birthTime(fullPath); ok {
+		return t
+	}
+	return mtime
+}
+
+// frontmatterCreated returns the first created field holding a date
+func (v *vault) frontmatterCreated(properties map[string]any) (time.Time, bool) {
+	t, _, ok := v.frontmatterDate(properties, v.createdFields)
+	return t, ok
+}
+
+// frontmatterDate returns the first of fields holding a date, and its name
+func (v *vault) frontmatterDate(properties map[string]any, fields []string) (time.Time, string, bool) {
+	for _, field := range fields {
+		value, ok := lookupProperty(properties, field)
+		if !ok {
+			continue
+		}
+
+		switch value := value.(type) {
+		case time.Time:
+			return value, field, true
+		case string:
+			value = strings.TrimSpace(value)
+			if v.dateFormat != "" {
+				if t, err := time.Parse(v.dateFormat, value); err == nil {
+					return t, field, true
+				}
+			}
+			if t, ok := parseDate(value); ok {
+				return t, field, true
+			}
+		}
+	}
+	return time.Time{}, "", false
+}
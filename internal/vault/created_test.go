@@ -0,0 +1,143 @@
+package vault
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestResolveCreated(t *testing.T) {
+	mtime := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	date := time.Date(2021, 3, 14, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		options    []Option
+		content    string
+		want       time.Time
+		fileBacked bool // Expect the file's birth time or mtime
+	}{
+		{
+			name:    "created field",
+			content: "---\ncreated: 2021-03-14\n---\nBody",
+			want:    date,
+		},
+		{
+			name:    "date field",
+			content: "---\ndate: \"2021-03-14\"\n---\nBody",
+			want:    date,
+		},
+		{
+			name:    "created preferred over date",
+			content: "---\ndate: 2020-01-01\nCreated: 2021-03-14\n---\nBody",
+			want:    date,
+		},
+		{
+			name:    "configured fields",
+			options: []Option{WithCreatedFields("born")},
+			content: "---\ncreated: 2020-01-01\nborn: 2021-03-14\n---\nBody",
+			want:    date,
+		},
+		{
+			name:    "configured format",
+			options: []Option{WithDateFormat("02.01.2006")},
+			content: "---\ncreated: 14.03.2021\n---\nBody",
+			want:    date,
+		},
+		{
+			name:       "unparseable date falls back to file times",
+			content:    "---\ncreated: someday\n---\nBody",
+			fileBacked: true,
+		},
+		{
+			name:       "no frontmatter falls back to file times",
+			content:    "Body",
+			fileBacked: true,
+		},
+		{
+			name:       "frontmatter disabled",
+			options:    []Option{WithCreatedFields()},
+			content:    "---\ncreated: 2021-03-14\n---\nBody",
+			fileBacked: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			fullPath := filepath.Join(tmpDir, "note.md")
+			if err := os.WriteFile(fullPath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create file: %v", err)
+			}
+			if err := os.Chtimes(fullPath, mtime, mtime); err != nil {
+				t.Fatalf("Failed to set mtime: %v", err)
+			}
+
+			vi, err := NewVault(tmpDir, tt.options...)
+			if err != nil {
+				t.Fatalf("Failed to create vault: %v", err)
+			}
+			v := vi.(*vault)
+
+			got := v.resolveCreated(fullPath, parseFrontmatter(tt.content), mtime)
+
+			if tt.fileBacked {
+				want := mtime
+				if birth, ok := birthTime(fullPath); ok {
+					want = birth
+				}
+				if !got.Equal(want) {
+					t.Errorf("resolveCreated() = %v, want file time %v", got, want)
+				}
+				return
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("resolveCreated() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreatedInNoteInfo(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := "---\ncreated: 2021-03-14\n---\nBody"
+	if err := os.WriteFile(filepath.Join(tmpDir, "note.md"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	cache := NewCache()
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	v.(*vault).cache = cache
+	ctx := context.Background()
+
+	for i := range 2 {
+		notes, err := v.List(ctx, ListOptions{})
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if len(notes) != 1 {
+			t.Fatalf("Expected 1 note, got %d", len(notes))
+		}
+		if want := time.Date(2021, 3, 14, 0, 0, 0, 0, time.UTC); !notes[0].Created.Equal(want) {
+			t.Errorf("List #%d Created = %v, want %v", i, notes[0].Created, want)
+		}
+	}
+
+	// The second listing is served from the cache with the resolved date
+	if stats := cache.CacheStats(); stats.Hits != 1 {
+		t.Errorf("Cache hits = %d, want 1", stats.Hits)
+	}
+
+	stats, err := v.Stats(ctx, "")
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	if stats.CreatedLast30 != 0 || stats.ModifiedLast30 != 1 {
+		t.Errorf("CreatedLast30 = %d, ModifiedLast30 = %d, want 0 and 1", stats.CreatedLast30, stats.ModifiedLast30)
+	}
+}
@@ -0,0 +1,61 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Data file lock timing: how often a held lock is retried, and the age at
+// which it is taken to be left over from a crashed process
+const (
+	dataLockRetry = 10 * time.Millisecond
+	dataLockStale = 10 * time.Second
+)
+
+// lockDataFile creates the lock file of file, waiting while another
+// process holds it, and returns the function that removes it. A lock
+// older than dataLockStale is taken over.
+func lockDataFile(ctx context.Context, file string) (func(), error) {
+	lock := file + ".lock"
+	for {
+		f, err := os.OpenFile(lock, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lock) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to lock %s: %w", filepath.Base(file), err)
+		}
+		if stat, err := os.Stat(lock); err == nil && time.Since(stat.ModTime()) > dataLockStale {
+			os.Remove(lock)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(dataLockRetry):
+		}
+	}
+}
+
+// writeDataFile writes data to a temporary file and renames it over file,
+// so readers never see a partial write
+func writeDataFile(file string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
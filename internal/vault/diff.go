@@ -0,0 +1,157 @@
+package vault
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Where DiffNote found the content of the requested revision
+const (
+	BaselineCache  = "cache"  // The note cache still held it
+	BaselineBackup = "backup" // A backed up version has it
+)
+
+// NoteDiff holds the current content of a note and, when it can still be
+// found, its content at an earlier revision
+type NoteDiff struct {
+	Path            string
+	Revision        string    // Content hash of the current content
+	Modified        time.Time // Current modification time
+	Unchanged       bool      // The note is still at the requested revision
+	Content         string    // Current content
+	Baseline        string    // Content at the requested revision
+	BaselineSource  string    // BaselineCache or BaselineBackup, empty when the baseline is unavailable
+	BaselineVersion string    // Backup version id the baseline came from
+}
+
+// noteRevision is a revision token: a content hash or a modification time
+type noteRevision struct {
+	hash  string
+	mtime time.Time
+}
+
+// parseRevision reads a content hash as returned in content_hash, or a
+// modification time in RFC 3339 as returned in modified
+func parseRevision(revision string) (noteRevision, error) {
+	if _, err := hex.DecodeString(revision); err == nil && len(revision) == 64 {
+		return noteRevision{hash: revision}, nil
+	}
+	if mtime, err := time.Parse(time.RFC3339Nano, revision); err == nil {
+		return noteRevision{mtime: mtime}, nil
+	}
+	return noteRevision{}, fmt.Errorf("%w: %q is neither a content hash nor a modification time", ErrInvalidRevision, revision)
+}
+
+// matches reports whether the content hash and modification time belong
+// to the revision
+func (r noteRevision) matches(hash string, mtime time.Time) bool {
+	if r.hash != "" {
+		return r.hash == hash
+	}
+	return r.mtime.Equal(mtime)
+}
+
+// DiffNote returns the current content of a note together with its content
+// at revision, a content hash or modification time from an earlier read.
+// The earlier content comes from the cache when it still holds that
+// version, or else, for a content hash, from the note's backups. When
+// neither has it the baseline is unavailable and BaselineSource is empty.
+func (v *vault) DiffNote(ctx context.Context, path, revision string) (NoteDiff, error) {
+	rev, err := parseRevision(revision)
+	if err != nil {
+		return NoteDiff{}, err
+	}
+
+	fullPath, err := v.validatePath(path)
+	if err != nil {
+		return NoteDiff{}, err
+	}
+
+	// Peek before loading: a load replaces a stale entry
+	cached, hasCached := v.cache.Peek(fullPath)
+
+	stat, err := os.Stat(fullPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NoteDiff{}, ErrNoteNotFound
+		}
+		return NoteDiff{}, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return NoteDiff{}, ctx.Err()
+	default:
+	}
+
+	entry, err := v.loadEntry(fullPath, stat.ModTime())
+	if err != nil {
+		return NoteDiff{}, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	diff := NoteDiff{
+		Path:     v.relPath(fullPath),
+		Revision: entry.ContentHash,
+		Modified: stat.ModTime(),
+		Content:  entry.Content,
+	}
+	if rev.matches(entry.ContentHash, stat.ModTime()) {
+		diff.Unchanged = true
+		return diff, nil
+	}
+
+	switch {
+	case hasCached && !cached.ContentOmitted && rev.matches(cached.ContentHash, cached.Mtime):
+		diff.Baseline, diff.BaselineSource = cached.Content, BaselineCache
+	case rev.hash != "":
+		// Backup files carry the time they were taken, not the note's, so
+		// only a content hash can pick one
+		version, content, err := v.findVersion(ctx, diff.Path, rev.hash)
+		if err != nil {
+			return NoteDiff{}, err
+		}
+		if version != "" {
+			diff.Baseline, diff.BaselineSource, diff.BaselineVersion = content, BaselineBackup, version
+		}
+	}
+
+	// A note touched without changing is still at the revision
+	if diff.BaselineSource != "" && diff.Baseline == diff.Content {
+		diff.Unchanged = true
+	}
+	return diff, nil
+}
+
+// findVersion returns the id and content of the newest backed up version
+// of the note at relPath whose content hashes to hash, or an empty id when
+// there is none
+func (v *vault) findVersion(ctx context.Context, relPath, hash string) (string, string, error) {
+	dir := v.backupPath(relPath)
+	versions, err := readVersions(dir)
+	if err != nil {
+		return "", "", err
+	}
+
+	for _, version := range versions {
+		if err := ctx.Err(); err != nil {
+			return "", "", err
+		}
+		data, err := os.ReadFile(filepath.Join(dir, version.ID+".md"))
+		if err != nil {
+			continue // Pruned meanwhile
+		}
+		// Versions are byte-for-byte copies and decode like the note
+		content, _, err := v.decodeNote(data)
+		if err != nil {
+			content = string(data)
+		}
+		if contentHash(content) == hash {
+			return version.ID, content, nil
+		}
+	}
+	return "", "", nil
+}
@@ -0,0 +1,112 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiffNote(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("unchanged", func(t *testing.T) {
+		v, tmpDir := setupTestVault(t)
+		stat, err := os.Stat(filepath.Join(tmpDir, "note1.md"))
+		if err != nil {
+			t.Fatalf("Failed to stat note: %v", err)
+		}
+		hash := contentHash("This is note 1 with #tag1 and #tag2")
+
+		for _, revision := range []string{hash, stat.ModTime().Format(time.RFC3339Nano)} {
+			diff, err := v.DiffNote(ctx, "note1.md", revision)
+			if err != nil {
+				t.Fatalf("DiffNote(%q) error = %v", revision, err)
+			}
+			if !diff.Unchanged || diff.Revision != hash {
+				t.Errorf("DiffNote(%q) = %+v, want unchanged", revision, diff)
+			}
+		}
+	})
+
+	t.Run("baseline from cache", func(t *testing.T) {
+		v, tmpDir := setupTestVault(t)
+		fullPath := filepath.Join(tmpDir, "note1.md")
+		before, err := os.Stat(fullPath)
+		if err != nil {
+			t.Fatalf("Failed to stat note: %v", err)
+		}
+		old, _ := v.Read(ctx, "note1.md")
+
+		// Changed outside the server, so no backup is taken
+		if err := os.WriteFile(fullPath, []byte(old+"Added line\n"), 0644); err != nil {
+			t.Fatalf("Failed to write note: %v", err)
+		}
+		later := before.ModTime().Add(time.Minute)
+		if err := os.Chtimes(fullPath, later, later); err != nil {
+			t.Fatalf("Failed to set times: %v", err)
+		}
+
+		diff, err := v.DiffNote(ctx, "note1.md", before.ModTime().Format(time.RFC3339Nano))
+		if err != nil {
+			t.Fatalf("DiffNote() error = %v", err)
+		}
+		if diff.Unchanged || diff.BaselineSource != BaselineCache || diff.Baseline != old || diff.Content != old+"Added line\n" {
+			t.Errorf("DiffNote() = %+v, want the cached content as baseline", diff)
+		}
+	})
+
+	t.Run("baseline from backup", func(t *testing.T) {
+		v, _ := setupTestVault(t)
+		old, _ := v.Read(ctx, "note1.md")
+		if err := v.Update(ctx, "note1.md", "Second version"); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if err := v.Update(ctx, "note1.md", "Third version"); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+
+		diff, err := v.DiffNote(ctx, "note1.md", contentHash(old))
+		if err != nil {
+			t.Fatalf("DiffNote() error = %v", err)
+		}
+		if diff.BaselineSource != BaselineBackup || diff.BaselineVersion == "" || diff.Baseline != old || diff.Content != "Third version" {
+			t.Errorf("DiffNote() = %+v, want the first backup as baseline", diff)
+		}
+	})
+
+	t.Run("baseline missing", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		writeFiles(t, tmpDir, map[string]string{"a.md": "First"})
+		v, err := NewVault(tmpDir, WithBackups(0))
+		if err != nil {
+			t.Fatalf("Failed to create vault: %v", err)
+		}
+		if _, err := v.Read(ctx, "a.md"); err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+		if err := v.Update(ctx, "a.md", "Second"); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+
+		diff, err := v.DiffNote(ctx, "a.md", contentHash("First"))
+		if err != nil {
+			t.Fatalf("DiffNote() error = %v", err)
+		}
+		if diff.Unchanged || diff.BaselineSource != "" || diff.Content != "Second" || diff.Revision != contentHash("Second") {
+			t.Errorf("DiffNote() = %+v, want no baseline and the current content", diff)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		v, _ := setupTestVault(t)
+		if _, err := v.DiffNote(ctx, "note1.md", "yesterday"); !errors.Is(err, ErrInvalidRevision) {
+			t.Errorf("DiffNote() error = %v, want ErrInvalidRevision", err)
+		}
+		if _, err := v.DiffNote(ctx, "missing.md", contentHash("")); !errors.Is(err, ErrNoteNotFound) {
+			t.Errorf("DiffNote() error = %v, want ErrNoteNotFound", err)
+		}
+	})
+}
@@ -0,0 +1,261 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Digest defaults
+const (
+	DefaultDigestMaxBytes = 128 << 10
+	MaxDigestNotes        = 200 // Matching notes considered; later ones are omitted unread
+)
+
+// DigestOrder is the order of notes in a digest
+type DigestOrder string
+
+// Digest orders
+const (
+	DigestModifiedDesc DigestOrder = "modified_desc"
+	DigestModifiedAsc  DigestOrder = "modified_asc"
+	DigestCreatedDesc  DigestOrder = "created_desc"
+	DigestCreatedAsc   DigestOrder = "created_asc"
+	DigestPath         DigestOrder = "path"
+)
+
+// DigestMode decides how a digest spends its byte budget
+type DigestMode string
+
+// Digest modes
+const (
+	// DigestDepth includes notes in full, in order, as long as they fit;
+	// a note that no longer fits gets its excerpt instead, or is omitted
+	DigestDepth DigestMode = "depth"
+
+	// DigestBreadth first gives every note its excerpt, in order, then
+	// spends what is left on including notes in full, in the same order
+	DigestBreadth DigestMode = "breadth"
+)
+
+// DigestStatus tells how much of a note a digest includes
+type DigestStatus string
+
+// Digest statuses
+const (
+	DigestFull      DigestStatus = "full"
+	DigestTruncated DigestStatus = "truncated" // Only the note's excerpt
+	DigestOmitted   DigestStatus = "omitted"
+)
+
+// DigestOptions selects the notes of a digest and how much of them to include
+type DigestOptions struct {
+	Subpath  string   // Directory to gather notes from, empty for the whole vault
+	TagsAny  []string // Notes must have at least one of these tags
+	TagsAll  []string // Notes must have all of these tags
+	TagsNone []string // Notes must have none of these tags
+
+	Order DigestOrder // DigestModifiedDesc when empty
+	Mode  DigestMode  // DigestDepth when empty
+
+	// MaxBytes bounds the total size of the included content,
+	// DefaultDigestMaxBytes when 0 or less
+	MaxBytes int
+
+	// ExcerptLength is the length in characters of the excerpt used for a
+	// truncated note, DefaultPreviewLength when 0 or less
+	ExcerptLength int
+}
+
+// DigestNote is one note of a digest
+type DigestNote struct {
+	Path     string       `json:"path"`
+	Tags     []string     `json:"tags"`
+	Modified time.Time    `json:"modified"`
+	Status   DigestStatus `json:"status"`
+	Content  string       `json:"-"`               // Full content or excerpt, empty when omitted
+	Error    string       `json:"error,omitempty"` // Why an omitted note could not be read
+}
+
+// Digest is the content of the notes matching a tag filter, in order
+type Digest struct {
+	Notes []DigestNote `json:"notes"`
+	Bytes int          `json:"bytes"` // Total size of the included content
+}
+
+// digestCandidate is a matching note with both forms it can be included in
+type digestCandidate struct {
+	note    *DigestNote
+	full    string
+	excerpt string
+}
+
+// ReadDigest gathers the notes matching the tag filters of opts, in
+// opts.Order, and includes as much of their content as opts.MaxBytes
+// allows according to opts.Mode. Every matching note is listed, with a
+// status telling whether it was included in full, as an excerpt, or not
+// at all.
+func (v *vault) ReadDigest(ctx context.Context, opts DigestOptions) (Digest, error) {
+	notes, err := v.Search(ctx, SearchOptions{
+		Subpath:  opts.Subpath,
+		TagsAny:  opts.TagsAny,
+		TagsAll:  opts.TagsAll,
+		TagsNone: opts.TagsNone,
+	})
+	if err != nil {
+		return Digest{}, err
+	}
+	sortDigestNotes(notes, opts.Order)
+
+	digest := Digest{Notes: make([]DigestNote, len(notes))}
+	paths := make([]string, 0, min(len(notes), MaxDigestNotes))
+	for i, note := range notes {
+		digest.Notes[i] = DigestNote{Path: note.Path, Tags: note.Tags, Modified: note.Modified, Status: DigestOmitted}
+		if i < MaxDigestNotes {
+			paths = append(paths, note.Path)
+		}
+	}
+
+	contents, err := v.ReadMany(ctx, paths, 0)
+	if err != nil {
+		return Digest{}, err
+	}
+
+	excerptLength := opts.ExcerptLength
+	if excerptLength <= 0 {
+		excerptLength = DefaultPreviewLength
+	}
+
+	candidates := make([]digestCandidate, 0, len(contents))
+	for i, content := range contents {
+		if content.Err != nil {
+			digest.Notes[i].Error = content.Err.Error()
+			continue
+		}
+		candidates = append(candidates, digestCandidate{
+			note:    &digest.Notes[i],
+			full:    content.Content,
+			excerpt: excerpt(content.Content, excerptLength),
+		})
+	}
+
+	maxBytes := opts.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultDigestMaxBytes
+	}
+	if opts.Mode == DigestBreadth {
+		digest.Bytes = budgetBreadth(candidates, maxBytes)
+	} else {
+		digest.Bytes = budgetDepth(candidates, maxBytes)
+	}
+
+	return digest, nil
+}
+
+// budgetDepth includes each candidate in full while it fits, otherwise
+// its excerpt while that fits, and returns the bytes spent
+func budgetDepth(candidates []digestCandidate, maxBytes int) int {
+	spent := 0
+	for _, c := range candidates {
+		switch {
+		case spent+len(c.full) <= maxBytes:
+			c.include(DigestFull, c.full)
+			spent += len(c.full)
+		case spent+len(c.excerpt) <= maxBytes:
+			c.include(DigestTruncated, c.excerpt)
+			spent += len(c.excerpt)
+		}
+	}
+	return spent
+}
+
+// budgetBreadth includes the excerpt of each candidate while it fits,
+// then upgrades them to full content in order while the difference fits,
+// and returns the bytes spent
+func budgetBreadth(candidates []digestCandidate, maxBytes int) int {
+	spent := 0
+	for _, c := range candidates {
+		// A note no longer than its excerpt is included in full right away
+		if len(c.full) <= len(c.excerpt) {
+			if spent+len(c.full) <= maxBytes {
+				c.include(DigestFull, c.full)
+				spent += len(c.full)
+			}
+			continue
+		}
+		if spent+len(c.excerpt) <= maxBytes {
+			c.include(DigestTruncated, c.excerpt)
+			spent += len(c.excerpt)
+		}
+	}
+
+	for _, c := range candidates {
+		if c.note.Status != DigestTruncated {
+			continue
+		}
+		if extra := len(c.full) - len(c.excerpt); spent+extra <= maxBytes {
+			c.include(DigestFull, c.full)
+			spent += extra
+		}
+	}
+	return spent
+}
+
+// ParseDigestOrder validates a digest order, defaulting to newest first
+func ParseDigestOrder(s string) (DigestOrder, error) {
+	switch order := DigestOrder(strings.ToLower(strings.TrimSpace(s))); order {
+	case "":
+		return DigestModifiedDesc, nil
+	case DigestModifiedDesc, DigestModifiedAsc, DigestCreatedDesc, DigestCreatedAsc, DigestPath:
+		return order, nil
+	default:
+		return "", fmt.Errorf("unknown order %q (want modified_desc, modified_asc, created_desc, created_asc or path)", s)
+	}
+}
+
+// ParseDigestMode validates a digest mode, defaulting to depth
+func ParseDigestMode(s string) (DigestMode, error) {
+	switch mode := DigestMode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case "":
+		return DigestDepth, nil
+	case DigestDepth, DigestBreadth:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown mode %q (want depth or breadth)", s)
+	}
+}
+
+// include sets how the candidate's note is included
+func (c digestCandidate) include(status DigestStatus, content string) {
+	c.note.Status = status
+	c.note.Content = content
+}
+
+// sortDigestNotes sorts notes in order, ties broken by path
+func sortDigestNotes(notes []NoteInfo, order DigestOrder) {
+	sort.Slice(notes, func(i, j int) bool {
+		a, b := notes[i], notes[j]
+		switch order {
+		case DigestModifiedAsc:
+			if !a.Modified.Equal(b.Modified) {
+				return a.Modified.Before(b.Modified)
+			}
+		case DigestCreatedDesc:
+			if !a.Created.Equal(b.Created) {
+				return a.Created.After(b.Created)
+			}
+		case DigestCreatedAsc:
+			if !a.Created.Equal(b.Created) {
+				return a.Created.Before(b.Created)
+			}
+		case DigestPath:
+		default:
+			if !a.Modified.Equal(b.Modified) {
+				return a.Modified.After(b.Modified)
+			}
+		}
+		return a.Path < b.Path
+	})
+}
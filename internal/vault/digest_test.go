@@ -0,0 +1,210 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// digestNote builds a note whose first paragraph is "Intro of name.",
+// followed by its hashtags and filler bringing it to exactly size bytes
+func digestNote(t *testing.T, name string, tags string, size int) string {
+	t.Helper()
+	content := fmt.Sprintf("Intro of %s.\n\n%s\n\n", name, tags)
+	if len(content) > size {
+		t.Fatalf("Note %s cannot be as small as %d bytes", name, size)
+	}
+	return content + strings.Repeat("x", size-len(content))
+}
+
+// digestStatuses returns the status of each note of digest by path
+func digestStatuses(digest Digest) map[string]DigestStatus {
+	statuses := make(map[string]DigestStatus, len(digest.Notes))
+	for _, note := range digest.Notes {
+		statuses[note.Path] = note.Status
+	}
+	return statuses
+}
+
+func TestReadDigest(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+
+	// Three book notes of 1000 bytes, newest first a, b, c, and two others
+	now := time.Now()
+	notes := []struct {
+		path     string
+		tags     string
+		size     int
+		modified time.Time
+	}{
+		{"Books/a.md", "#book", 1000, now.Add(-1 * time.Hour)},
+		{"Books/b.md", "#book", 1000, now.Add(-2 * time.Hour)},
+		{"Reading/c.md", "#book #fiction", 1000, now.Add(-3 * time.Hour)},
+		{"Books/d.md", "#book #draft", 500, now.Add(-4 * time.Hour)},
+		{"Other/e.md", "#film", 500, now},
+	}
+	for _, note := range notes {
+		writeFiles(t, tmpDir, map[string]string{note.path: digestNote(t, note.path, note.tags, note.size)})
+		fullPath := filepath.Join(tmpDir, note.path)
+		if err := os.Chtimes(fullPath, note.modified, note.modified); err != nil {
+			t.Fatalf("Failed to set note time: %v", err)
+		}
+	}
+
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+
+	// Every excerpt is the intro line, e.g. "Intro of Books/a.md."
+	excerptSize := len("Intro of Books/a.md.")
+	const (
+		full      = DigestFull
+		truncated = DigestTruncated
+		omitted   = DigestOmitted
+	)
+
+	tests := []struct {
+		name      string
+		opts      DigestOptions
+		wantOrder []string
+		want      map[string]DigestStatus
+		wantBytes int
+	}{
+		{
+			name:      "everything fits",
+			opts:      DigestOptions{TagsAny: []string{"book"}, TagsNone: []string{"draft"}, MaxBytes: 3000},
+			wantOrder: []string{"Books/a.md", "Books/b.md", "Reading/c.md"},
+			want:      map[string]DigestStatus{"Books/a.md": full, "Books/b.md": full, "Reading/c.md": full},
+			wantBytes: 3000,
+		},
+		{
+			name:      "depth cuts the last note to its excerpt",
+			opts:      DigestOptions{TagsAny: []string{"book"}, TagsNone: []string{"draft"}, MaxBytes: 2999},
+			want:      map[string]DigestStatus{"Books/a.md": full, "Books/b.md": full, "Reading/c.md": truncated},
+			wantBytes: 2000 + excerptSize + 2, // Reading/ is two bytes longer than Books/
+		},
+		{
+			name:      "depth with no room for an excerpt",
+			opts:      DigestOptions{TagsAny: []string{"book"}, TagsNone: []string{"draft"}, MaxBytes: 2010},
+			want:      map[string]DigestStatus{"Books/a.md": full, "Books/b.md": full, "Reading/c.md": omitted},
+			wantBytes: 2000,
+		},
+		{
+			name:      "depth budget smaller than any note",
+			opts:      DigestOptions{TagsAny: []string{"book"}, TagsNone: []string{"draft"}, MaxBytes: 50},
+			want:      map[string]DigestStatus{"Books/a.md": truncated, "Books/b.md": truncated, "Reading/c.md": omitted},
+			wantBytes: 2 * excerptSize,
+		},
+		{
+			name:      "breadth upgrades in order",
+			opts:      DigestOptions{TagsAny: []string{"book"}, TagsNone: []string{"draft"}, Mode: DigestBreadth, MaxBytes: 1100},
+			want:      map[string]DigestStatus{"Books/a.md": full, "Books/b.md": truncated, "Reading/c.md": truncated},
+			wantBytes: 1000 + 2*excerptSize + 2,
+		},
+		{
+			name:      "breadth keeps excerpts where depth would not",
+			opts:      DigestOptions{TagsAny: []string{"book"}, TagsNone: []string{"draft"}, Mode: DigestBreadth, MaxBytes: 2010},
+			want:      map[string]DigestStatus{"Books/a.md": full, "Books/b.md": truncated, "Reading/c.md": truncated},
+			wantBytes: 1000 + 2*excerptSize + 2,
+		},
+		{
+			name:      "breadth with room for two excerpts",
+			opts:      DigestOptions{TagsAny: []string{"book"}, TagsNone: []string{"draft"}, Mode: DigestBreadth, MaxBytes: 2 * excerptSize},
+			want:      map[string]DigestStatus{"Books/a.md": truncated, "Books/b.md": truncated, "Reading/c.md": omitted},
+			wantBytes: 2 * excerptSize,
+		},
+		{
+			name:      "oldest first",
+			opts:      DigestOptions{TagsAny: []string{"book"}, TagsNone: []string{"draft"}, Order: DigestModifiedAsc, MaxBytes: 2010},
+			wantOrder: []string{"Reading/c.md", "Books/b.md", "Books/a.md"},
+			want:      map[string]DigestStatus{"Reading/c.md": full, "Books/b.md": full, "Books/a.md": omitted},
+			wantBytes: 2000,
+		},
+		{
+			name:      "all tags and folder",
+			opts:      DigestOptions{TagsAll: []string{"book", "draft"}, Subpath: "Books", Order: DigestPath},
+			wantOrder: []string{"Books/d.md"},
+			want:      map[string]DigestStatus{"Books/d.md": full},
+			wantBytes: 500,
+		},
+		{
+			name: "no matches",
+			opts: DigestOptions{TagsAny: []string{"missing"}},
+			want: map[string]DigestStatus{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			digest, err := v.ReadDigest(ctx, tt.opts)
+			if err != nil {
+				t.Fatalf("ReadDigest failed: %v", err)
+			}
+
+			if got := digestStatuses(digest); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Statuses = %v, want %v", got, tt.want)
+			}
+			if digest.Bytes != tt.wantBytes {
+				t.Errorf("Bytes = %d, want %d", digest.Bytes, tt.wantBytes)
+			}
+			if tt.wantOrder != nil {
+				var order []string
+				for _, note := range digest.Notes {
+					order = append(order, note.Path)
+				}
+				if !reflect.DeepEqual(order, tt.wantOrder) {
+					t.Errorf("Order = %v, want %v", order, tt.wantOrder)
+				}
+			}
+
+			// The content matches the status and adds up to Bytes
+			total := 0
+			for _, note := range digest.Notes {
+				switch note.Status {
+				case DigestFull:
+					if len(note.Content) < 500 {
+						t.Errorf("Expected the full content of %s, got %q", note.Path, note.Content)
+					}
+				case DigestTruncated:
+					if note.Content != "Intro of "+note.Path+"." {
+						t.Errorf("Expected the excerpt of %s, got %q", note.Path, note.Content)
+					}
+				case DigestOmitted:
+					if note.Content != "" {
+						t.Errorf("Expected no content for omitted %s", note.Path)
+					}
+				}
+				total += len(note.Content)
+			}
+			if total != digest.Bytes {
+				t.Errorf("Content adds up to %d bytes, Bytes = %d", total, digest.Bytes)
+			}
+		})
+	}
+}
+
+func TestParseDigestOptions(t *testing.T) {
+	if order, err := ParseDigestOrder(""); err != nil || order != DigestModifiedDesc {
+		t.Errorf("ParseDigestOrder(\"\") = %q, %v, want the default", order, err)
+	}
+	if order, err := ParseDigestOrder("Path"); err != nil || order != DigestPath {
+		t.Errorf("ParseDigestOrder(\"Path\") = %q, %v", order, err)
+	}
+	if _, err := ParseDigestOrder("size"); err == nil {
+		t.Error("Expected an error for an unknown order")
+	}
+
+	if mode, err := ParseDigestMode(""); err != nil || mode != DigestDepth {
+		t.Errorf("ParseDigestMode(\"\") = %q, %v, want the default", mode, err)
+	}
+	if _, err := ParseDigestMode("wide"); err == nil {
+		t.Error("Expected an error for an unknown mode")
+	}
+}
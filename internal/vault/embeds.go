@@ -0,0 +1,197 @@
+package vault
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Embed expansion limits
+const (
+	DefaultEmbedDepth   = 1
+	MaxEmbedDepth       = 5
+	DefaultMaxEmbedSize = 100_000 // Characters of embedded content per read
+)
+
+// ExpandOptions controls how ReadExpanded inlines embedded notes
+type ExpandOptions struct {
+	MaxDepth int // Levels of nested embeds to expand, DefaultEmbedDepth when 0
+	MaxSize  int // Characters of embedded content to inline in total, DefaultMaxEmbedSize when 0
+
+	// Allow reports whether a note may be embedded; nil allows every note
+	Allow func(path string) bool
+}
+
+// ExpandedNote is a note with its embeds replaced by the embedded content
+type ExpandedNote struct {
+	Content   string `json:"content"`
+	Expanded  int    `json:"expanded"`  // Embeds replaced by their target
+	Skipped   int    `json:"skipped"`   // Note embeds left as written: unresolved, disallowed, cyclic, too deep or over the size limit
+	Truncated bool   `json:"truncated"` // The size limit cut embedded content short
+}
+
+// embedExpander carries the state of one ReadExpanded call
+type embedExpander struct {
+	v         *vault
+	ctx       context.Context
+	index     *fileIndex
+	maxDepth  int
+	allow     func(path string) bool
+	remaining int // Characters of embedded content still allowed
+	result    ExpandedNote
+}
+
+// ReadExpanded returns the content of a note with each ![[embed]] of
+// another note replaced by that note's body, or by the embedded section
+// for ![[Note#Heading]] and ![[Note#^block]]. Spliced content is delimited
+// by HTML comments naming its source. Attachment embeds are left as written.
+func (v *vault) ReadExpanded(ctx context.Context, notePath string, opts ExpandOptions) (ExpandedNote, error) {
+	content, err := v.Read(ctx, notePath)
+	if err != nil {
+		return ExpandedNote{}, err
+	}
+
+	if !strings.Contains(content, "![[") {
+		return ExpandedNote{Content: content}, nil
+	}
+
+	index, err := v.buildFileIndex(ctx)
+	if err != nil {
+		return ExpandedNote{}, err
+	}
+
+	e := &embedExpander{
+		v:         v,
+		ctx:       ctx,
+		index:     index,
+		maxDepth:  cmp.Or(opts.MaxDepth, DefaultEmbedDepth),
+		allow:     opts.Allow,
+		remaining: cmp.Or(opts.MaxSize, DefaultMaxEmbedSize),
+	}
+
+	fullPath, err := v.validatePath(notePath)
+	if err != nil {
+		return ExpandedNote{}, err
+	}
+	source := v.relPath(fullPath)
+
+	e.result.Content = e.expand(content, source, []string{source})
+	return e.result, nil
+}
+
+// expand replaces the note embeds in content, written in the note at
+// source, skipping code like ParseLinks. chain holds the notes being
+// expanded, outermost first, so an embed of any of them is a cycle.
+func (e *embedExpander) expand(content, source string, chain []string) string {
+	blank := func(s string) string {
+		return strings.Repeat(" ", len(s))
+	}
+
+	lines := strings.Split(content, "\n")
+	inFence := false
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		masked := inlineCodeRegex.ReplaceAllStringFunc(line, blank)
+		var edits []linkEdit
+		for _, m := range wikiLinkRegex.FindAllStringSubmatchIndex(masked, -1) {
+			if m[3] == m[2] {
+				continue // A link, not an embed
+			}
+			target, heading, blockID := splitAnchor(strings.TrimSpace(line[m[4]:m[5]]))
+			if replacement, ok := e.embed(source, target, heading, blockID, chain); ok {
+				edits = append(edits, linkEdit{m[0], m[1], replacement})
+			}
+		}
+
+		// Apply from the end so earlier offsets stay valid
+		sort.Slice(edits, func(a, b int) bool { return edits[a].start > edits[b].start })
+		for _, edit := range edits {
+			line = line[:edit.start] + edit.text + line[edit.end:]
+		}
+		lines[i] = line
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// embed returns the text replacing one embed, or false to keep it
+func (e *embedExpander) embed(source, target, heading, blockID string, chain []string) (string, bool) {
+	notePath, ok := e.index.resolve(source, target)
+	if !ok {
+		e.result.Skipped++
+		return "", false
+	}
+	if path.Ext(notePath) != ".md" {
+		return "", false // Attachments stay embedded
+	}
+	if e.allow != nil && !e.allow(notePath) {
+		e.result.Skipped++
+		return "", false
+	}
+	if len(chain) > e.maxDepth || slices.Contains(chain, notePath) || e.remaining == 0 {
+		e.result.Skipped++
+		if e.remaining == 0 {
+			e.result.Truncated = true
+		}
+		return "", false
+	}
+
+	var body, label string
+	if heading == "" && blockID == "" {
+		content, err := e.v.Read(e.ctx, notePath)
+		if err != nil {
+			e.result.Skipped++
+			return "", false
+		}
+		_, body, _ = SplitFrontmatter(content)
+		label = notePath
+	} else {
+		section, err := e.v.ReadSection(e.ctx, notePath, SectionOptions{Heading: heading, Block: blockID})
+		if err != nil {
+			e.result.Skipped++
+			return "", false
+		}
+		body = section.Content
+		label = notePath + "#" + heading
+		if blockID != "" {
+			label = notePath + "#^" + blockID
+		}
+	}
+	body = strings.Trim(body, "\n")
+
+	// Charge the embedded note's own text; its embeds draw on what is left
+	truncated := false
+	if size := utf8.RuneCountInString(body); size > e.remaining {
+		body = truncateText(body, e.remaining)
+		e.remaining = 0
+		truncated = true
+		e.result.Truncated = true
+	} else {
+		e.remaining -= size
+	}
+
+	body = e.expand(body, notePath, append(slices.Clip(chain), notePath))
+	e.result.Expanded++
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<!-- embed: %s -->\n%s\n", label, body)
+	if truncated {
+		b.WriteString("<!-- embed truncated: size limit reached -->\n")
+	}
+	fmt.Fprintf(&b, "<!-- end embed: %s -->", label)
+	return b.String(), true
+}
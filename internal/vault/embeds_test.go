@@ -0,0 +1,115 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// setupEmbedVault creates a vault whose notes embed each other
+func setupEmbedVault(t *testing.T) *vault {
+	t.Helper()
+	tmpDir := t.TempDir()
+
+	notes := map[string]string{
+		"daily.md":          "# Today\n![[Projects/plan]]\n![[plan#Goals]]\n![[plan#^risk]]\n![[diagram.png]]\n![[missing]]\n`![[plan]]`\n```\n![[plan]]\n```",
+		"Projects/plan.md":  "---\nstatus: active\n---\nPlan intro\n![[tasks]]\n\n## Goals\nShip it\n### Detail\nSoon\n## Risks\nNone ^risk",
+		"Projects/tasks.md": "- [ ] Write spec",
+		"diagram.png":       "png",
+		"a.md":              "A embeds ![[b]]",
+		"b.md":              "B embeds ![[a]]",
+	}
+	writeFiles(t, tmpDir, notes)
+
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	return v.(*vault)
+}
+
+func TestReadExpanded(t *testing.T) {
+	v := setupEmbedVault(t)
+	ctx := context.Background()
+
+	t.Run("one level", func(t *testing.T) {
+		note, err := v.ReadExpanded(ctx, "daily.md", ExpandOptions{})
+		if err != nil {
+			t.Fatalf("ReadExpanded() error = %v", err)
+		}
+
+		want := "# Today\n" +
+			"<!-- embed: Projects/plan.md -->\nPlan intro\n![[tasks]]\n\n## Goals\nShip it\n### Detail\nSoon\n## Risks\nNone ^risk\n<!-- end embed: Projects/plan.md -->\n" +
+			"<!-- embed: Projects/plan.md#Goals -->\n## Goals\nShip it\n### Detail\nSoon\n<!-- end embed: Projects/plan.md#Goals -->\n" +
+			"<!-- embed: Projects/plan.md#^risk -->\nNone ^risk\n<!-- end embed: Projects/plan.md#^risk -->\n" +
+			"![[diagram.png]]\n![[missing]]\n`![[plan]]`\n```\n![[plan]]\n```"
+		if note.Content != want {
+			t.Errorf("Content = %q, want %q", note.Content, want)
+		}
+		// The nested ![[tasks]] is beyond the depth and ![[missing]] does not resolve
+		if note.Expanded != 3 || note.Skipped != 2 || note.Truncated {
+			t.Errorf("ReadExpanded() = %+v", note)
+		}
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		note, err := v.ReadExpanded(ctx, "daily.md", ExpandOptions{MaxDepth: 2})
+		if err != nil {
+			t.Fatalf("ReadExpanded() error = %v", err)
+		}
+		want := "<!-- embed: Projects/tasks.md -->\n- [ ] Write spec\n<!-- end embed: Projects/tasks.md -->"
+		if strings.Count(note.Content, want) != 1 || note.Expanded != 4 {
+			t.Errorf("ReadExpanded() = %+v", note)
+		}
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		note, err := v.ReadExpanded(ctx, "a.md", ExpandOptions{MaxDepth: MaxEmbedDepth})
+		if err != nil {
+			t.Fatalf("ReadExpanded() error = %v", err)
+		}
+		want := "A embeds <!-- embed: b.md -->\nB embeds ![[a]]\n<!-- end embed: b.md -->"
+		if note.Content != want || note.Expanded != 1 || note.Skipped != 1 {
+			t.Errorf("ReadExpanded() = %+v, want content %q", note, want)
+		}
+	})
+
+	t.Run("size limit", func(t *testing.T) {
+		note, err := v.ReadExpanded(ctx, "daily.md", ExpandOptions{MaxSize: 12})
+		if err != nil {
+			t.Fatalf("ReadExpanded() error = %v", err)
+		}
+		want := "<!-- embed: Projects/plan.md -->\nPlan intro…\n<!-- embed truncated: size limit reached -->\n<!-- end embed: Projects/plan.md -->\n![[plan#Goals]]"
+		if !note.Truncated || !strings.Contains(note.Content, want) {
+			t.Errorf("ReadExpanded() = %+v, want content containing %q", note, want)
+		}
+	})
+
+	t.Run("disallowed notes", func(t *testing.T) {
+		allow := func(path string) bool { return !strings.HasPrefix(path, "Projects/") }
+		note, err := v.ReadExpanded(ctx, "daily.md", ExpandOptions{Allow: allow})
+		if err != nil {
+			t.Fatalf("ReadExpanded() error = %v", err)
+		}
+		if note.Expanded != 0 || note.Skipped != 4 || strings.Contains(note.Content, "<!-- embed:") {
+			t.Errorf("ReadExpanded() = %+v, want every embed left as written", note)
+		}
+	})
+
+	t.Run("no embeds", func(t *testing.T) {
+		note, err := v.ReadExpanded(ctx, "Projects/tasks.md", ExpandOptions{})
+		if err != nil {
+			t.Fatalf("ReadExpanded() error = %v", err)
+		}
+		if note != (ExpandedNote{Content: "- [ ] Write spec"}) {
+			t.Errorf("ReadExpanded() = %+v", note)
+		}
+	})
+
+	t.Run("missing note", func(t *testing.T) {
+		if _, err := v.ReadExpanded(ctx, "nope.md", ExpandOptions{}); !errors.Is(err, ErrNoteNotFound) {
+			t.Errorf("ReadExpanded() error = %v, want ErrNoteNotFound", err)
+		}
+	})
+}
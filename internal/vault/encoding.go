@@ -0,0 +1,97 @@
+package vault
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// utf8BOM is the byte order mark some Windows editors prepend to UTF-8
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// noteFormat records how a note is stored on disk so content handed out
+// as clean UTF-8 with LF line endings can be written back the same way
+type noteFormat struct {
+	bom      bool              // File starts with a UTF-8 byte order mark
+	crlf     bool              // Every line ends with CRLF
+	encoding encoding.Encoding // Source encoding, nil for UTF-8
+}
+
+// WithSourceEncoding transcodes notes that are not valid UTF-8 from the
+// named encoding (e.g. "windows-1252") instead of rejecting them with
+// ErrNotUTF8. Such notes are written back in the same encoding.
+// Unknown names are reported by NewVault.
+func WithSourceEncoding(name string) Option {
+	return func(v *vault) {
+		v.sourceEncodingName = name
+	}
+}
+
+// lookupEncoding resolves an encoding by its WHATWG name or label
+func lookupEncoding(name string) (encoding.Encoding, error) {
+	enc, err := htmlindex.Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown source encoding %q", name)
+	}
+	return enc, nil
+}
+
+// decodeNote converts raw file data to UTF-8 content with LF line endings
+// and reports the format needed to reproduce the original bytes
+func (v *vault) decodeNote(data []byte) (string, noteFormat, error) {
+	var format noteFormat
+
+	if rest, ok := bytes.CutPrefix(data, utf8BOM); ok {
+		format.bom = true
+		data = rest
+	}
+
+	if !utf8.Valid(data) {
+		if v.sourceEncoding == nil {
+			return "", noteFormat{}, ErrNotUTF8
+		}
+		decoded, err := v.sourceEncoding.NewDecoder().Bytes(data)
+		if err != nil {
+			return "", noteFormat{}, fmt.Errorf("%w: %v", ErrNotUTF8, err)
+		}
+		data = decoded
+		format.encoding = v.sourceEncoding
+	}
+
+	content := string(data)
+
+	// Only consistent CRLF files are normalized; mixed line endings are
+	// left alone so writing them back cannot change lines nobody edited
+	if crlf := strings.Count(content, "\r\n"); crlf > 0 && crlf == strings.Count(content, "\n") {
+		format.crlf = true
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+	}
+
+	return content, format, nil
+}
+
+// encode converts content to the bytes stored for a note in format
+func (f noteFormat) encode(content string) ([]byte, error) {
+	if f.crlf {
+		content = strings.ReplaceAll(strings.ReplaceAll(content, "\r\n", "\n"), "\n", "\r\n")
+	}
+
+	data := []byte(content)
+	if f.encoding != nil {
+		encoded, err := f.encoding.NewEncoder().Bytes(data)
+		if err != nil {
+			return nil, fmt.Errorf("content cannot be written in the note's encoding: %w", err)
+		}
+		data = encoded
+	}
+
+	if f.bom {
+		data = append(append([]byte{}, utf8BOM...), data...)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,154 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncodingRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      []byte
+		opts     []Option
+		wantRead string
+	}{
+		{
+			name:     "plain utf-8",
+			raw:      []byte("# Title\nbody\n"),
+			wantRead: "# Title\nbody\n",
+		},
+		{
+			name:     "bom",
+			raw:      []byte("\xEF\xBB\xBF# Title\nbody\n"),
+			wantRead: "# Title\nbody\n",
+		},
+		{
+			name:     "crlf",
+			raw:      []byte("---\r\ntitle: T\r\n---\r\n# Title\r\nbody\r\n"),
+			wantRead: "---\ntitle: T\n---\n# Title\nbody\n",
+		},
+		{
+			name:     "bom and crlf",
+			raw:      []byte("\xEF\xBB\xBFline one\r\nline two"),
+			wantRead: "line one\nline two",
+		},
+		{
+			name:     "mixed line endings are kept",
+			raw:      []byte("a\r\nb\nc\r\n"),
+			wantRead: "a\r\nb\nc\r\n",
+		},
+		{
+			name:     "windows-1252",
+			raw:      []byte("caf\xE9 \x80 na\xEFve\r\n"),
+			opts:     []Option{WithSourceEncoding("windows-1252")},
+			wantRead: "café € naïve\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			v, err := NewVault(tmpDir, tt.opts...)
+			if err != nil {
+				t.Fatalf("NewVault() error = %v", err)
+			}
+			ctx := context.Background()
+			fullPath := filepath.Join(tmpDir, "note.md")
+			if err := os.WriteFile(fullPath, tt.raw, 0644); err != nil {
+				t.Fatalf("Failed to create file: %v", err)
+			}
+
+			content, err := v.Read(ctx, "note.md")
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if content != tt.wantRead {
+				t.Fatalf("Read() = %q, want %q", content, tt.wantRead)
+			}
+
+			// Writing back the same logical content must not change a byte
+			if err := v.Update(ctx, "note.md", content); err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+			got, _ := os.ReadFile(fullPath)
+			if !bytes.Equal(got, tt.raw) {
+				t.Errorf("File after round trip = %q, want %q", got, tt.raw)
+			}
+
+			// Edits keep the original format
+			if err := v.Update(ctx, "note.md", content+"added\n"); err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+			got, _ = os.ReadFile(fullPath)
+			if !bytes.HasPrefix(got, tt.raw) {
+				t.Errorf("File after edit = %q, want prefix %q", got, tt.raw)
+			}
+			if reread, _ := v.Read(ctx, "note.md"); reread != content+"added\n" {
+				t.Errorf("Read() after edit = %q, want %q", reread, content+"added\n")
+			}
+		})
+	}
+}
+
+func TestNotUTF8(t *testing.T) {
+	v, tmpDir := setupTestVault(t)
+	ctx := context.Background()
+
+	raw := []byte("caf\xE9\n")
+	fullPath := filepath.Join(tmpDir, "latin1.md")
+	if err := os.WriteFile(fullPath, raw, 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	if _, err := v.Read(ctx, "latin1.md"); !errors.Is(err, ErrNotUTF8) {
+		t.Errorf("Read() error = %v, want ErrNotUTF8", err)
+	}
+
+	// Overwriting would silently change the encoding
+	if err := v.Update(ctx, "latin1.md", "café\n"); !errors.Is(err, ErrNotUTF8) {
+		t.Errorf("Update() error = %v, want ErrNotUTF8", err)
+	}
+	if got, _ := os.ReadFile(fullPath); !bytes.Equal(got, raw) {
+		t.Errorf("File changed to %q", got)
+	}
+
+	// Walks report the note instead of hiding it
+	notes, err := v.List(ctx, ListOptions{Recursive: true})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	found := false
+	for _, note := range notes {
+		if note.Path == "latin1.md" {
+			found = strings.Contains(note.Error, ErrNotUTF8.Error())
+		}
+	}
+	if !found {
+		t.Errorf("List() = %+v, want latin1.md with an encoding error", notes)
+	}
+}
+
+func TestWithSourceEncodingUnknown(t *testing.T) {
+	if _, err := NewVault(t.TempDir(), WithSourceEncoding("no-such-charset")); err == nil {
+		t.Error("NewVault() with unknown encoding succeeded, want error")
+	}
+}
+
+func TestEncodeUnrepresentable(t *testing.T) {
+	v, err := NewVault(t.TempDir(), WithSourceEncoding("windows-1252"))
+	if err != nil {
+		t.Fatalf("NewVault() error = %v", err)
+	}
+	_, format, err := v.(*vault).decodeNote([]byte("caf\xE9"))
+	if err != nil {
+		t.Fatalf("decodeNote() error = %v", err)
+	}
+	if _, err := format.encode("日本語"); err == nil {
+		t.Error("encode() of characters outside windows-1252 succeeded, want error")
+	}
+}
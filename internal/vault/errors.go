@@ -1,6 +1,13 @@
 package vault
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp/syntax"
+	"strings"
+	"time"
+)
 
 // Sentinel errors for vault operations
 var (
@@ -15,4 +22,396 @@ var (
 
 	// ErrNotMarkdown indicates the file is not a markdown file
 	ErrNotMarkdown = errors.New("only .md files allowed")
+
+	// ErrDirectoryNotFound indicates the requested directory does not exist
+	ErrDirectoryNotFound = errors.New("directory not found")
+
+	// ErrReservedPath indicates the path points into server-managed data
+	ErrReservedPath = errors.New("path is reserved for server data")
+
+	// ErrVersionNotFound indicates the requested note version does not exist
+	ErrVersionNotFound = errors.New("note version not found")
+
+	// ErrAmbiguousNote indicates a note name matches more than one note
+	ErrAmbiguousNote = errors.New("note name is ambiguous")
+
+	// ErrAttachmentNotFound indicates the requested attachment does not exist
+	ErrAttachmentNotFound = errors.New("attachment not found")
+
+	// ErrNotAttachment indicates the file type is not an allowed attachment
+	ErrNotAttachment = errors.New("file type is not an allowed attachment")
+
+	// ErrCanvasNotFound indicates the requested canvas does not exist
+	ErrCanvasNotFound = errors.New("canvas not found")
+
+	// ErrNotCanvas indicates the file is not a canvas file
+	ErrNotCanvas = errors.New("only .canvas files allowed")
+
+	// ErrInvalidCanvas indicates a canvas file is not valid JSON Canvas
+	ErrInvalidCanvas = errors.New("invalid canvas")
+
+	// ErrRateLimited indicates a write was rejected by the write limits
+	ErrRateLimited = errors.New("rate limit exceeded")
+
+	// ErrAttachmentTooLarge indicates an attachment exceeds the size limit
+	ErrAttachmentTooLarge = errors.New("attachment too large")
+
+	// ErrNotUTF8 indicates a note is not valid UTF-8 and no source
+	// encoding is configured to transcode it
+	ErrNotUTF8 = errors.New("note is not valid UTF-8")
+
+	// ErrReadOnly indicates the write policy does not allow modifying the path
+	ErrReadOnly = errors.New("path is read-only")
+
+	// ErrPermissionDenied indicates the file system does not let the server
+	// modify the path, e.g. a note made read-only with chmod
+	ErrPermissionDenied = errors.New("permission denied by the file system")
+
+	// ErrSimilarNote indicates a note is not created because notes with a
+	// similar name already exist
+	ErrSimilarNote = errors.New("similar note exists")
+
+	// ErrNoteExists indicates a note cannot be created because one is
+	// already at the path
+	ErrNoteExists = errors.New("note already exists")
+
+	// ErrFolderExists indicates a folder cannot be created or renamed
+	// because something is already at the path
+	ErrFolderExists = errors.New("folder already exists")
+
+	// ErrPartialResults indicates a search reached its time limit before
+	// reading every note; the notes matched so far come with the error
+	ErrPartialResults = errors.New("search timed out")
+
+	// ErrSectionNotFound indicates the note has no heading or block with
+	// the requested name
+	ErrSectionNotFound = errors.New("section not found")
+
+	// ErrInvalidAnnotation indicates an annotation key or value that
+	// cannot be stored
+	ErrInvalidAnnotation = errors.New("invalid annotation")
+
+	// ErrSchemaViolation indicates a note's frontmatter breaks the rules
+	// set with WithFrontmatterSchema
+	ErrSchemaViolation = errors.New("frontmatter does not match the schema")
+
+	// ErrInvalidCursor indicates a cursor that was not returned by Changes,
+	// Chunks or SearchFirst
+	ErrInvalidCursor = errors.New("invalid cursor")
+
+	// ErrInvalidEdit indicates an ApplyEdits operation that is malformed
+	// whatever the state of the vault, such as an unknown kind
+	ErrInvalidEdit = errors.New("invalid edit")
+
+	// ErrRevisionMismatch indicates a note changed since the revision an
+	// edit expected
+	ErrRevisionMismatch = errors.New("note revision does not match")
+
+	// ErrInvalidRevision indicates a revision that is neither a content
+	// hash nor a modification time
+	ErrInvalidRevision = errors.New("invalid revision")
+
+	// ErrBatchTooLarge indicates an ApplyEdits batch beyond the limits set
+	// with WithBatchLimits
+	ErrBatchTooLarge = errors.New("batch too large")
+
+	// ErrLocked indicates a write to a note another client has leased
+	ErrLocked = errors.New("note is locked")
+
+	// ErrAuditFailed indicates a change could not be recorded in the
+	// audit log while the audit is strict
+	ErrAuditFailed = errors.New("audit log not writable")
+
+	// ErrInvalidPattern indicates a ReplaceInNotes or Search pattern or
+	// mode that cannot be used
+	ErrInvalidPattern = errors.New("invalid pattern")
+
+	// ErrSavedSearchNotFound indicates no search is saved under the name
+	ErrSavedSearchNotFound = errors.New("saved search not found")
+
+	// ErrSavedSearchExists indicates a search is already saved under the
+	// name and overwriting was not requested
+	ErrSavedSearchExists = errors.New("saved search already exists")
+
+	// ErrInvalidSavedSearch indicates a saved search name that cannot be
+	// used
+	ErrInvalidSavedSearch = errors.New("invalid saved search")
+
+	// ErrInvalidCapture indicates a Capture entry without text or with a
+	// tag that is not a single word
+	ErrInvalidCapture = errors.New("invalid capture")
+
+	// ErrInvalidPin indicates a pin duration that cannot be used
+	ErrInvalidPin = errors.New("invalid pin")
+
+	// ErrInvalidLink indicates an AddLink location or alias that cannot
+	// be used
+	ErrInvalidLink = errors.New("invalid link")
+
+	// ErrInvalidAlias indicates an alias that cannot be used, or a note
+	// whose frontmatter cannot hold aliases
+	ErrInvalidAlias = errors.New("invalid alias")
+
+	// ErrAliasNotFound indicates a RemoveAlias of an alias the note does
+	// not have
+	ErrAliasNotFound = errors.New("alias not found")
+
+	// ErrInvalidFrontmatter indicates a note whose frontmatter cannot be
+	// edited property by property because it is not a YAML mapping
+	ErrInvalidFrontmatter = errors.New("invalid frontmatter")
+
+	// ErrScratchNotFound indicates no scratch note has the requested ID
+	ErrScratchNotFound = errors.New("scratch note not found")
+
+	// ErrScratchFull indicates a scratch note would exceed the count or
+	// size limits set with WithScratch
+	ErrScratchFull = errors.New("scratch space is full")
+
+	// ErrInvalidRollup indicates a GenerateRollup grouping, period or
+	// template that cannot be used
+	ErrInvalidRollup = errors.New("invalid rollup")
+
+	// ErrUnknownNoteType indicates a type filter naming no configured
+	// note type; see UnknownNoteTypeError
+	ErrUnknownNoteType = errors.New("unknown note type")
+
+	// ErrInvalidCleanup indicates PruneBackups options selecting nothing
+	// or out of range
+	ErrInvalidCleanup = errors.New("invalid cleanup")
+
+	// ErrIndexDisabled indicates an index operation on a vault without
+	// WithSearchIndex
+	ErrIndexDisabled = errors.New("search index is disabled")
+
+	// ErrOutsideDataDir indicates a cleanup about to delete a file outside
+	// the data directory it cleans; nothing is deleted
+	ErrOutsideDataDir = errors.New("outside the server's data directory")
+
+	// ErrVaultUnavailable indicates the vault directory cannot be reached,
+	// such as when the drive holding it is unmounted
+	ErrVaultUnavailable = errors.New("vault is not accessible")
 )
+
+// DirectoryNotFoundError reports a missing directory together with
+// similarly named directories that do exist
+// It matches ErrDirectoryNotFound with errors.Is
+type DirectoryNotFoundError struct {
+	Path        string   // Vault-relative path that was requested
+	Suggestions []string // Vault-relative paths of similarly named directories
+}
+
+func (e *DirectoryNotFoundError) Error() string {
+	return fmt.Sprintf("directory not found: %s", e.Path)
+}
+
+// Is reports whether target is ErrDirectoryNotFound
+func (e *DirectoryNotFoundError) Is(target error) bool {
+	return target == ErrDirectoryNotFound
+}
+
+// NoteNotFoundError reports a missing note together with the paths of
+// similarly named notes that do exist
+// It matches ErrNoteNotFound with errors.Is
+type NoteNotFoundError struct {
+	Path        string   // Vault-relative path that was requested
+	Suggestions []string // Vault-relative paths of similarly named notes
+}
+
+func (e *NoteNotFoundError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrNoteNotFound, e.Path)
+}
+
+// Is reports whether target is ErrNoteNotFound
+func (e *NoteNotFoundError) Is(target error) bool {
+	return target == ErrNoteNotFound
+}
+
+// NoteExistsError reports a note already at the path of a new note, with
+// its size and modification time
+// It matches ErrNoteExists with errors.Is
+type NoteExistsError struct {
+	Path     string    // Vault-relative path of the existing note
+	Size     int64     // Size of the existing note in bytes
+	Modified time.Time // Modification time of the existing note
+}
+
+func (e *NoteExistsError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrNoteExists, e.Path)
+}
+
+// Is reports whether target is ErrNoteExists
+func (e *NoteExistsError) Is(target error) bool {
+	return target == ErrNoteExists
+}
+
+// SimilarNotesError reports existing notes whose name is close to that
+// of a new note, see SimilarNotes
+// It matches ErrSimilarNote with errors.Is
+type SimilarNotesError struct {
+	Path    string        // Vault-relative path of the new note
+	Similar []SimilarNote // The similar notes, closest first
+}
+
+func (e *SimilarNotesError) Error() string {
+	paths := make([]string, len(e.Similar))
+	for i, note := range e.Similar {
+		paths[i] = note.Path
+	}
+	return fmt.Sprintf("%s: %s is close to %s", ErrSimilarNote, e.Path, strings.Join(paths, ", "))
+}
+
+// Is reports whether target is ErrSimilarNote
+func (e *SimilarNotesError) Is(target error) bool {
+	return target == ErrSimilarNote
+}
+
+// LockedError reports a note leased to another client
+// It matches ErrLocked with errors.Is
+type LockedError struct {
+	Lease Lease // The other client's lease
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%s: %s by %s until %s", ErrLocked, e.Lease.Path, e.Lease.Holder, e.Lease.Expires.UTC().Format(time.RFC3339))
+}
+
+// Is reports whether target is ErrLocked
+func (e *LockedError) Is(target error) bool {
+	return target == ErrLocked
+}
+
+// PatternError reports a Search pattern that does not compile
+// It matches ErrInvalidPattern with errors.Is
+type PatternError struct {
+	Param    string // Option holding the pattern: query, query_all, query_any, query_none or pattern
+	Index    int    // Position of the pattern within Param
+	Pattern  string // Pattern as given
+	Reason   string // Why it does not compile
+	Fragment string // Part of Pattern the regexp parser rejected, empty when unknown
+	Position int    // Byte offset of Fragment in Pattern, when Fragment is set
+}
+
+// newPatternError reports pattern, given in param at index, failing to
+// compile with err, and locates the part the regexp parser rejected
+func newPatternError(param string, index int, pattern string, err error) *PatternError {
+	e := &PatternError{Param: param, Index: index, Pattern: pattern, Reason: strings.TrimPrefix(err.Error(), "error parsing regexp: ")}
+	var syntaxErr *syntax.Error
+	if errors.As(err, &syntaxErr) {
+		// Flags the server adds, such as (?i), are not in the pattern
+		fragment := strings.TrimPrefix(syntaxErr.Expr, "(?i)")
+		if i := strings.Index(pattern, fragment); fragment != "" && i >= 0 {
+			e.Fragment, e.Position = fragment, i
+		}
+	}
+	return e
+}
+
+func (e *PatternError) Error() string {
+	return fmt.Sprintf("%s: %s %q: %s", ErrInvalidPattern, e.Name(), e.Pattern, e.Reason)
+}
+
+// Name identifies the pattern as its option and index, e.g. query_any[1],
+// or as its option alone when it takes one pattern
+func (e *PatternError) Name() string {
+	if e.Param == "query" || e.Param == "pattern" {
+		return e.Param
+	}
+	return fmt.Sprintf("%s[%d]", e.Param, e.Index)
+}
+
+// Is reports whether target is ErrInvalidPattern
+func (e *PatternError) Is(target error) bool {
+	return target == ErrInvalidPattern
+}
+
+// AmbiguousNoteError reports a note name that matches several notes
+// It matches ErrAmbiguousNote with errors.Is
+type AmbiguousNoteError struct {
+	Name       string      // Name that was looked up
+	Candidates []NoteMatch // Equally good matches, best first
+}
+
+func (e *AmbiguousNoteError) Error() string {
+	return fmt.Sprintf("note name is ambiguous: %s matches %d notes", e.Name, len(e.Candidates))
+}
+
+// Is reports whether target is ErrAmbiguousNote
+func (e *AmbiguousNoteError) Is(target error) bool {
+	return target == ErrAmbiguousNote
+}
+
+// RateLimitError reports a write rejected by the write limits
+// It matches ErrRateLimited with errors.Is
+type RateLimitError struct {
+	Scope      string        // LimitScopeVault, LimitScopeFile or LimitScopeSession
+	Path       string        // Note path for LimitScopeFile
+	Limit      int           // Configured limit that was hit
+	RetryAfter time.Duration // Wait before a retry can succeed, 0 if it never will
+}
+
+func (e *RateLimitError) Error() string {
+	switch e.Scope {
+	case LimitScopeSession:
+		return fmt.Sprintf("rate limit exceeded: at most %d notes may be modified per session", e.Limit)
+	case LimitScopeFile:
+		return fmt.Sprintf("rate limit exceeded: at most %d writes per minute to %s, retry after %ds", e.Limit, e.Path, e.RetrySeconds())
+	default:
+		return fmt.Sprintf("rate limit exceeded: at most %d writes per minute, retry after %ds", e.Limit, e.RetrySeconds())
+	}
+}
+
+// RetrySeconds returns RetryAfter rounded up to whole seconds
+func (e *RateLimitError) RetrySeconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
+
+// Is reports whether target is ErrRateLimited
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// PartialResultsError reports how much of the vault a search covered
+// before its time limit
+// It matches ErrPartialResults with errors.Is
+type PartialResultsError struct {
+	Scanned int // Notes read before the search stopped
+	Total   int // Notes the search would have read, a lower bound if listing them was cut short
+}
+
+func (e *PartialResultsError) Error() string {
+	return fmt.Sprintf("search timed out after scanning %d of %d notes", e.Scanned, e.Total)
+}
+
+// Is reports whether target is ErrPartialResults
+func (e *PartialResultsError) Is(target error) bool {
+	return target == ErrPartialResults
+}
+
+// SchemaViolation is one way a note's frontmatter breaks the schema
+type SchemaViolation struct {
+	Field   string `json:"field,omitempty"` // Property name, empty for the frontmatter as a whole
+	Problem string `json:"problem"`
+}
+
+// SchemaError lists every schema rule a note's frontmatter breaks
+// It matches ErrSchemaViolation with errors.Is
+type SchemaError struct {
+	Path       string
+	Violations []SchemaViolation
+}
+
+func (e *SchemaError) Error() string {
+	problems := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		problems[i] = violation.Problem
+		if violation.Field != "" {
+			problems[i] = violation.Field + " " + violation.Problem
+		}
+	}
+	return fmt.Sprintf("frontmatter does not match the schema: %s", strings.Join(problems, "; "))
+}
+
+// Is reports whether target is ErrSchemaViolation
+func (e *SchemaError) Is(target error) bool {
+	return target == ErrSchemaViolation
+}
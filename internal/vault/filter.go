@@ -0,0 +1,64 @@
+package vault
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// NoteFilter narrows the notes returned by List
+// Zero fields do not filter; the others combine with AND
+type NoteFilter struct {
+	NameGlob       string    // Pattern for the file name, e.g. "2024-*.md"
+	ModifiedAfter  time.Time // Only notes modified at or after this time
+	ModifiedBefore time.Time // Only notes modified before this time
+	MinSize        int64     // Minimum file size in bytes
+	MaxSize        int64     // Maximum file size in bytes, 0 for no limit
+
+	// Tag filters with the same semantics as SearchOptions
+	TagsAny  []string
+	TagsAll  []string
+	TagsNone []string
+}
+
+// validate reports a malformed name pattern
+func (f NoteFilter) validate() error {
+	if f.NameGlob == "" {
+		return nil
+	}
+	if _, err := path.Match(f.NameGlob, ""); err != nil {
+		return fmt.Errorf("invalid name glob %q: %w", f.NameGlob, err)
+	}
+	return nil
+}
+
+// hasTags reports whether the filter needs note content to decide
+func (f NoteFilter) hasTags() bool {
+	return len(f.TagsAny) > 0 || len(f.TagsAll) > 0 || len(f.TagsNone) > 0
+}
+
+// matchesFile applies the filters decided from the walk alone, without
+// reading the note
+func (f NoteFilter) matchesFile(file noteFile) bool {
+	if f.NameGlob != "" {
+		if ok, _ := path.Match(f.NameGlob, filepath.Base(file.relPath)); !ok {
+			return false
+		}
+	}
+
+	size := file.info.Size()
+	if size < f.MinSize || (f.MaxSize > 0 && size > f.MaxSize) {
+		return false
+	}
+
+	mtime := file.info.ModTime()
+	if !f.ModifiedAfter.IsZero() && mtime.Before(f.ModifiedAfter) {
+		return false
+	}
+	if !f.ModifiedBefore.IsZero() && !mtime.Before(f.ModifiedBefore) {
+		return false
+	}
+
+	return true
+}
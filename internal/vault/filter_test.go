@@ -0,0 +1,173 @@
+package vault
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setupFilterVault creates notes with known names, sizes, tags and
+// modification times
+func setupFilterVault(t *testing.T, opts ...Option) Vault {
+	t.Helper()
+	tmpDir := t.TempDir()
+
+	notes := []struct {
+		path    string
+		content string
+		mtime   time.Time
+	}{
+		{"Projects/2024-01-plan.md", "Plan #active", time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)},
+		{"Projects/2024-02-old.md", "Old #archived #active", time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)},
+		{"Projects/notes.md", "Notes #active #work", time.Date(2024, 4, 2, 12, 0, 0, 0, time.UTC)},
+		{"Daily/2024-03-05.md", "Daily #journal " + strings.Repeat("words ", 40), time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC)},
+	}
+
+	for _, note := range notes {
+		writeFiles(t, tmpDir, map[string]string{note.path: note.content})
+		fullPath := filepath.Join(tmpDir, note.path)
+		if err := os.Chtimes(fullPath, note.mtime, note.mtime); err != nil {
+			t.Fatalf("Failed to set mtime: %v", err)
+		}
+	}
+
+	// Not valid UTF-8, so it is reported with an error whenever it is read
+	if err := os.WriteFile(filepath.Join(tmpDir, "Projects", "latin1.md"), []byte("caf\xe9 #active"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	v, err := NewVault(tmpDir, opts...)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	return v
+}
+
+func TestListFilter(t *testing.T) {
+	march := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		subpath string
+		filter  NoteFilter
+		want    []string
+	}{
+		{
+			name:   "name glob",
+			filter: NoteFilter{NameGlob: "2024-*.md"},
+			want:   []string{"Daily/2024-03-05.md", "Projects/2024-01-plan.md", "Projects/2024-02-old.md"},
+		},
+		{
+			name:   "modified after",
+			filter: NoteFilter{ModifiedAfter: march, ModifiedBefore: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+			want:   []string{"Daily/2024-03-05.md", "Projects/2024-01-plan.md", "Projects/notes.md"},
+		},
+		{
+			name:   "modified before",
+			filter: NoteFilter{ModifiedBefore: march},
+			want:   []string{"Projects/2024-02-old.md"},
+		},
+		{
+			name:   "size range",
+			filter: NoteFilter{MinSize: 100},
+			want:   []string{"Daily/2024-03-05.md"},
+		},
+		{
+			name:   "max size",
+			filter: NoteFilter{MaxSize: 12, NameGlob: "*-*.md"},
+			want:   []string{"Projects/2024-01-plan.md"},
+		},
+		{
+			name:    "tags and dates combine",
+			subpath: "Projects",
+			filter:  NoteFilter{ModifiedAfter: march, ModifiedBefore: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), TagsAny: []string{"#active"}},
+			want:    []string{"Projects/2024-01-plan.md", "Projects/notes.md"},
+		},
+		{
+			name:   "tags all and none",
+			filter: NoteFilter{TagsAll: []string{"active"}, TagsNone: []string{"archived"}, NameGlob: "*.md", MaxSize: 1000, ModifiedBefore: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+			want:   []string{"Projects/2024-01-plan.md", "Projects/notes.md"},
+		},
+		{
+			name:   "no match",
+			filter: NoteFilter{NameGlob: "*.canvas"},
+			want:   nil,
+		},
+	}
+
+	for _, withIndex := range []bool{false, true} {
+		var opts []Option
+		if withIndex {
+			opts = append(opts, WithSearchIndex())
+		}
+		v := setupFilterVault(t, opts...)
+		ctx := context.Background()
+
+		// Load every note once so the index, if any, is populated
+		if _, err := v.List(ctx, ListOptions{Recursive: true}); err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+
+		for _, tt := range tests {
+			name := tt.name
+			if withIndex {
+				name += " with index"
+			}
+			t.Run(name, func(t *testing.T) {
+				notes, err := v.List(ctx, ListOptions{Subpath: tt.subpath, Recursive: true, Filter: tt.filter})
+				if err != nil {
+					t.Fatalf("List() error = %v", err)
+				}
+
+				var got []string
+				for _, note := range notes {
+					got = append(got, filepath.ToSlash(note.Path))
+				}
+				slices.Sort(got)
+				if !slices.Equal(got, tt.want) {
+					t.Errorf("List() = %v, want %v", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestListFilterInvalidGlob(t *testing.T) {
+	v := setupFilterVault(t)
+
+	if _, err := v.List(context.Background(), ListOptions{Recursive: true, Filter: NoteFilter{NameGlob: "[2024"}}); err == nil {
+		t.Error("Expected error for malformed glob")
+	}
+}
+
+func TestListFilterSkipsBeforeReading(t *testing.T) {
+	v := setupFilterVault(t)
+	ctx := context.Background()
+
+	// Without a filter the undecodable note is read and reported
+	notes, err := v.List(ctx, ListOptions{Subpath: "Projects", Recursive: true})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if !slices.ContainsFunc(notes, func(n NoteInfo) bool { return n.Error != "" }) {
+		t.Fatal("Expected the undecodable note to be reported")
+	}
+
+	// A name filter rules it out from the walk alone, so it is never read
+	notes, err = v.List(ctx, ListOptions{Subpath: "Projects", Recursive: true, Filter: NoteFilter{NameGlob: "2024-*"}})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	for _, note := range notes {
+		if note.Error != "" {
+			t.Errorf("Expected %s to be filtered out before reading", note.Path)
+		}
+	}
+	if len(notes) != 2 {
+		t.Errorf("Expected 2 notes, got %d", len(notes))
+	}
+}
@@ -0,0 +1,36 @@
+package vault
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// dotlessI maps the Turkish dotted capital and dotless small i to a plain
+// i before folding; Unicode folding keeps them apart from I and i, so
+// "İstanbul" would otherwise never match "istanbul"
+var dotlessI = strings.NewReplacer("İ", "i", "ı", "i")
+
+// foldText returns s NFC-normalized and case-folded, the form in which
+// tags and case-insensitive literal searches are compared: "Straße"
+// matches "STRASSE", and é typed as one rune or as e plus a combining
+// accent are equal. ASCII text is only lowercased.
+func foldText(s string) string {
+	if isASCII(s) {
+		return strings.ToLower(s)
+	}
+	// A Caser is stateful, so each call gets its own
+	return norm.NFC.String(cases.Fold().String(dotlessI.Replace(norm.NFC.String(s))))
+}
+
+// isASCII reports whether s holds only ASCII characters
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
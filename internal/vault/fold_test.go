@@ -0,0 +1,57 @@
+package vault
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestFoldText(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Hello World", "hello world"},
+		{"Straße", "strasse"},
+		{"café", "café"},
+		{"CAFÉ", "café"},
+		{"İstanbul", "istanbul"},
+		{"ISPARTA ısparta", "isparta isparta"},
+		{"\u212a", "k"}, // Kelvin sign
+	}
+
+	for _, tt := range tests {
+		if got := foldText(tt.text); got != tt.want {
+			t.Errorf("foldText(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFoldedQueriesMatchASCIIAsBefore(t *testing.T) {
+	// In an ASCII vault, folded literal and word queries must match exactly
+	// what the (?i) expressions used before folding matched
+	contents := []string{"Plan the Q3 ROADMAP", "snake_case v1.2 (beta)", "planning\nKubernetes", ""}
+	patterns := []string{"plan", "PLAN", "roadmap", "v1.2", "(beta)", "Case", "q3 road", "kubernetes", "K8s", "x"}
+
+	for _, mode := range []QueryMode{QueryLiteral, QueryWord} {
+		for _, pattern := range patterns {
+			before := regexp.QuoteMeta(pattern)
+			if mode == QueryWord {
+				before = `(?:^|[^\pL\pN_])` + before + `(?:[^\pL\pN_]|$)`
+			}
+			beforeRe := regexp.MustCompile("(?i)" + before)
+
+			expr, folded := queryExpr(pattern, mode, false)
+			m := queryMatcher{all: []queryPattern{{regexp.MustCompile(expr), folded}}}
+			for _, content := range contents {
+				if got, want := m.matches(content), beforeRe.MatchString(content); got != want {
+					t.Errorf("%s %q in %q = %v, want %v", mode, pattern, content, got, want)
+				}
+			}
+		}
+	}
+
+	if s := "Mixed CASE ascii_123 #Tag"; foldText(s) != strings.ToLower(s) {
+		t.Errorf("foldText(%q) = %q, want it lowercased", s, foldText(s))
+	}
+}
@@ -0,0 +1,422 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// FolderInfo describes a folder in the vault
+type FolderInfo struct {
+	Path       string `json:"path"`        // Vault-relative path, "/" for the vault root
+	Notes      int    `json:"notes"`       // Notes directly inside the folder
+	TotalNotes int    `json:"total_notes"` // Notes inside the folder and its subfolders
+}
+
+// FolderOptions selects the folders returned by ListFolders
+type FolderOptions struct {
+	Subpath       string // Folder to list, empty for the whole vault
+	IncludeHidden bool   // Include folders whose name starts with a dot
+}
+
+// RenameFolderOptions describes a folder rename
+type RenameFolderOptions struct {
+	Path        string // Folder to rename
+	NewPath     string // New location of the folder; missing parents are created
+	UpdateLinks bool   // Rewrite links that would no longer resolve after the move
+}
+
+// FolderRename reports the outcome of RenameFolder
+type FolderRename struct {
+	Path         string   `json:"path"`
+	NewPath      string   `json:"new_path"`
+	NotesMoved   int      `json:"notes_moved"`
+	LinksUpdated int      `json:"links_updated"`
+	UpdatedNotes []string `json:"updated_notes,omitempty"` // Notes whose links were rewritten, at their new paths
+	NotUpdated   []string `json:"not_updated,omitempty"`   // Notes whose links could not be rewritten
+}
+
+// validateFolder ensures a folder path is safe and is not the vault root,
+// returning the full filesystem path
+func (v *vault) validateFolder(folder string) (string, error) {
+	if cleaned := filepath.Clean(folder); folder == "" || cleaned == "." || cleaned == string(os.PathSeparator) {
+		return "", fmt.Errorf("%w: the vault root cannot be used as a folder here", ErrInvalidPath)
+	}
+
+	fullPath, err := v.resolveInVault(folder)
+	if err != nil {
+		return "", err
+	}
+	if v.isDataPath(fullPath) {
+		return "", ErrReservedPath
+	}
+
+	return fullPath, nil
+}
+
+// folderPath returns the vault-relative path of a folder with forward
+// slashes, rootFolder for the vault root
+func (v *vault) folderPath(fullPath string) string {
+	if fullPath == v.basePath {
+		return rootFolder
+	}
+	return v.relPath(fullPath)
+}
+
+// movedPath returns where the vault-relative path p ends up when folder
+// from is renamed to to, and whether it is inside from at all
+func movedPath(p, from, to string) (string, bool) {
+	if p == from {
+		return to, true
+	}
+	if rest, ok := strings.CutPrefix(p, from+"/"); ok {
+		return to + "/" + rest, true
+	}
+	return p, false
+}
+
+// ListFolders returns the folders below opts.Subpath, including it, with
+// the number of notes in each, sorted by path. Empty folders are listed.
+// The vault is walked once and no note is read.
+func (v *vault) ListFolders(ctx context.Context, opts FolderOptions) ([]FolderInfo, error) {
+	root, err := v.validateDir(opts.Subpath)
+	if err != nil {
+		return nil, err
+	}
+	includeHidden := v.includeHidden || opts.IncludeHidden
+
+	folders := make(map[string]*FolderInfo)
+
+	walkFn := func(fullPath string, info os.FileInfo, err error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err != nil {
+			return nil // Skip inaccessible files and directories
+		}
+
+		if fullPath != root && !includeHidden && isHidden(fullPath) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if info.IsDir() {
+			key := v.folderPath(fullPath)
+			folders[key] = &FolderInfo{Path: key}
+			return nil
+		}
+
+		// Directories are visited before the files inside them
+		if folder, ok := folders[v.folderPath(filepath.Dir(fullPath))]; ok && strings.HasSuffix(fullPath, ".md") {
+			folder.Notes++
+		}
+		return nil
+	}
+
+	if err := v.walk(root, walkFn); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("failed to walk directory: %w", err)
+	}
+
+	// Add each folder's notes to every listed folder above it
+	rootKey := v.folderPath(root)
+	for key, folder := range folders {
+		for p := key; ; {
+			if ancestor, ok := folders[p]; ok {
+				ancestor.TotalNotes += folder.Notes
+			}
+			if p == rootKey || p == rootFolder {
+				break
+			}
+			if p = path.Dir(p); p == "." {
+				p = rootFolder
+			}
+		}
+	}
+
+	result := make([]FolderInfo, 0, len(folders))
+	for _, key := range slices.Sorted(maps.Keys(folders)) {
+		result = append(result, *folders[key])
+	}
+	return result, nil
+}
+
+// CreateFolder creates an empty folder and any missing parents
+func (v *vault) CreateFolder(ctx context.Context, folder string) error {
+	fullPath, err := v.validateFolder(folder)
+	if err != nil {
+		return err
+	}
+	if err := v.checkWritable(fullPath); err != nil {
+		return err
+	}
+
+	if _, err := os.Lstat(fullPath); err == nil {
+		return fmt.Errorf("%w: %s", ErrFolderExists, folder)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(fullPath, 0755); err != nil {
+		return fmt.Errorf("failed to create folder: %w", err)
+	}
+
+	return nil
+}
+
+// RenameFolder moves a folder with everything inside it to a new path in
+// the vault. Cached notes, the search index and backups follow the move.
+// With UpdateLinks, links anywhere in the vault that would stop resolving,
+// including relative links inside the moved notes, are rewritten to the
+// new vault-relative path; links that still resolve are left as written.
+// The destination must not exist, except when only the case of the name
+// changes.
+func (v *vault) RenameFolder(ctx context.Context, opts RenameFolderOptions) (FolderRename, error) {
+	fullPath, err := v.validateFolder(opts.Path)
+	if err != nil {
+		return FolderRename{}, err
+	}
+	newFullPath, err := v.validateFolder(opts.NewPath)
+	if err != nil {
+		return FolderRename{}, err
+	}
+	if _, err := v.validateDir(opts.Path); err != nil {
+		return FolderRename{}, err
+	}
+
+	caseOnly := fullPath != newFullPath && strings.EqualFold(fullPath, newFullPath)
+	if fullPath == newFullPath || (!caseOnly && isWithin(newFullPath, fullPath)) {
+		return FolderRename{}, fmt.Errorf("%w: cannot move %s into itself", ErrInvalidPath, opts.Path)
+	}
+	if err := v.checkDestination(fullPath, newFullPath, caseOnly, opts.NewPath); err != nil {
+		return FolderRename{}, err
+	}
+
+	from, to := v.folderPath(fullPath), v.folderPath(newFullPath)
+	result := FolderRename{Path: from, NewPath: to}
+
+	// Notes moving with the folder, by full path before and after
+	moved := make(map[string]string)
+	walkFn := func(notePath string, info os.FileInfo, err error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err == nil && !info.IsDir() && strings.HasSuffix(notePath, ".md") {
+			moved[notePath] = filepath.Join(newFullPath, strings.TrimPrefix(notePath, fullPath))
+		}
+		return nil
+	}
+	if err := v.walk(fullPath, walkFn); err != nil {
+		return FolderRename{}, fmt.Errorf("failed to walk directory: %w", err)
+	}
+
+	// Notes whose links need rewriting, by full path before the move
+	var relinked []string
+	var oldIndex, newIndex *fileIndex
+	if opts.UpdateLinks {
+		oldIndex, err = v.buildFileIndex(ctx)
+		if err != nil {
+			return FolderRename{}, err
+		}
+		newIndex = oldIndex.renamed(from, to)
+
+		var mu sync.Mutex
+		_, err = v.walkNotes(ctx, ListOptions{Recursive: true}, func(file noteFile, entry CacheEntry) bool {
+			if _, changed := v.relinkNote(oldIndex, newIndex, file.fullPath, from, to, entry.Content); changed > 0 {
+				mu.Lock()
+				relinked = append(relinked, file.fullPath)
+				mu.Unlock()
+			}
+			return false
+		})
+		if err != nil {
+			return FolderRename{}, err
+		}
+	}
+
+	// Every note touched must be writable at its old and new path
+	lockPaths := []string{fullPath, newFullPath}
+	for oldPath, newPath := range moved {
+		lockPaths = append(lockPaths, oldPath, newPath)
+	}
+	for _, notePath := range relinked {
+		lockPaths = append(lockPaths, notePath)
+		if newPath, ok := moved[notePath]; ok {
+			lockPaths = append(lockPaths, newPath)
+		}
+	}
+	if err := v.checkWritable(lockPaths...); err != nil {
+		return FolderRename{}, err
+	}
+	if err := v.checkLeases(ctx, lockPaths...); err != nil {
+		return FolderRename{}, err
+	}
+	if err := v.checkAudit(); err != nil {
+		return FolderRename{}, err
+	}
+
+	unlock := v.writeLocks.lock(lockPaths...)
+	defer unlock()
+
+	// Check again under the lock; a concurrent create may have made it
+	if err := v.checkDestination(fullPath, newFullPath, caseOnly, opts.NewPath); err != nil {
+		return FolderRename{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return FolderRename{}, err
+	}
+
+	// Move the folder; once it has moved the rest is completed regardless
+	if err := os.MkdirAll(filepath.Dir(newFullPath), 0755); err != nil {
+		return FolderRename{}, fmt.Errorf("failed to create parent folder: %w", err)
+	}
+	if err := os.Rename(fullPath, newFullPath); err != nil {
+		return FolderRename{}, fmt.Errorf("failed to rename folder: %w", err)
+	}
+	result.NotesMoved = len(moved)
+
+	var audit []AuditEntry
+	for oldPath, newPath := range moved {
+		v.cache.Rename(oldPath, newPath)
+		if v.index != nil {
+			v.index.rename(oldPath, newPath)
+		}
+		audit = append(audit, AuditEntry{Op: EditMove, Path: v.relPath(oldPath), NewPath: v.relPath(newPath)})
+	}
+	slices.SortFunc(audit, func(a, b AuditEntry) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	v.moveBackups(from, to)
+	v.moveAnnotations(from, to)
+	v.moveLeases(from, to)
+	v.movePins(from, to)
+	v.paths.invalidate()
+
+	for _, oldPath := range relinked {
+		notePath := oldPath
+		if newPath, ok := moved[oldPath]; ok {
+			notePath = newPath
+		}
+		relPath := v.relPath(notePath)
+
+		changed, written, err := v.relinkWritten(oldIndex, newIndex, oldPath, notePath, from, to)
+		if err != nil {
+			v.logger.Warn("updating links failed", "path", relPath, "error", err)
+			result.NotUpdated = append(result.NotUpdated, relPath)
+			continue
+		}
+		if changed > 0 {
+			result.LinksUpdated += changed
+			result.UpdatedNotes = append(result.UpdatedNotes, relPath)
+			audit = append(audit, written)
+		}
+	}
+	slices.Sort(result.UpdatedNotes)
+	slices.Sort(result.NotUpdated)
+
+	return result, v.record(ctx, audit...)
+}
+
+// checkDestination fails unless nothing is at newFullPath, or it is the
+// source folder itself reached through a case-only rename
+func (v *vault) checkDestination(fullPath, newFullPath string, caseOnly bool, newPath string) error {
+	newStat, err := os.Lstat(newFullPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat destination: %w", err)
+	}
+
+	if caseOnly {
+		if oldStat, err := os.Lstat(fullPath); err == nil && os.SameFile(oldStat, newStat) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrFolderExists, newPath)
+}
+
+// relinkNote rewrites the links in content of the note at fullPath for
+// the rename of folder from to to, returning the new content and the
+// number of links changed
+func (v *vault) relinkNote(oldIndex, newIndex *fileIndex, fullPath, from, to, content string) (string, int) {
+	oldSource := v.relPath(fullPath)
+	newSource, _ := movedPath(oldSource, from, to)
+
+	return rewriteLinks(content, func(target string) (string, bool) {
+		if target == "" {
+			return "", false // Same-note heading or block reference
+		}
+		resolved, ok := oldIndex.resolve(oldSource, target)
+		if !ok {
+			return "", false // Broken links are left alone
+		}
+
+		want, _ := movedPath(resolved, from, to)
+		if got, ok := newIndex.resolve(newSource, target); ok && got == want {
+			return "", false
+		}
+
+		if path.Ext(target) == "" {
+			want = strings.TrimSuffix(want, ".md")
+		}
+		return want, true
+	})
+}
+
+// relinkWritten rewrites the links of a note now at notePath, known as
+// oldPath before the rename, and writes it back when any changed,
+// returning the number of links changed and the update for the audit log
+// Caller must hold the note's write lock
+func (v *vault) relinkWritten(oldIndex, newIndex *fileIndex, oldPath, notePath, from, to string) (int, AuditEntry, error) {
+	stat, err := os.Stat(notePath)
+	if err != nil {
+		return 0, AuditEntry{}, err
+	}
+	entry, err := v.loadEntry(notePath, stat.ModTime())
+	if err != nil {
+		return 0, AuditEntry{}, err
+	}
+
+	content, changed := v.relinkNote(oldIndex, newIndex, oldPath, from, to, entry.Content)
+	if changed == 0 {
+		return 0, AuditEntry{}, nil
+	}
+	written, err := v.writeNote(notePath, content)
+	if err != nil {
+		return 0, AuditEntry{}, err
+	}
+	return changed, written, nil
+}
+
+// moveBackups moves the backed up versions of notes in folder from to to
+// Backups are left in place when versions already exist at the destination
+func (v *vault) moveBackups(from, to string) {
+	src, dst := v.backupPath(filepath.FromSlash(from)), v.backupPath(filepath.FromSlash(to))
+	if _, err := os.Stat(src); err != nil {
+		return
+	}
+	if _, err := os.Lstat(dst); err == nil {
+		v.logger.Warn("backups not moved, destination exists", "path", from, "new_path", to)
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		v.logger.Warn("moving backups failed", "path", from, "error", err)
+		return
+	}
+	if err := os.Rename(src, dst); err != nil {
+		v.logger.Warn("moving backups failed", "path", from, "error", err)
+	}
+}
@@ -0,0 +1,236 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// setupFolderVault creates a vault with linked notes in nested folders
+func setupFolderVault(t *testing.T, opts ...Option) (*vault, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+
+	notes := map[string]string{
+		"index.md":                   "[[Projects/Alpha/plan]] [[plan]] [spec](Projects/Alpha/spec%20v1.md#Scope) [[Projects/Alpha/plan#Goals|goals]] [[missing]]",
+		"Projects/Alpha/plan.md":     "Plan with [[spec v1]] and [home](../../index.md)",
+		"Projects/Alpha/spec v1.md":  "Spec ![[Projects/Alpha/diagram.png]]",
+		"Projects/Alpha/diagram.png": "png",
+		"Projects/Beta/notes.md":     "Beta notes",
+		"Projects/.hidden/draft.md":  "Hidden draft",
+		"Areas/health.md":            "Health",
+	}
+	writeFiles(t, tmpDir, notes)
+	if err := os.MkdirAll(filepath.Join(tmpDir, "Empty"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	v, err := NewVault(tmpDir, opts...)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	return v.(*vault), tmpDir
+}
+
+func TestListFolders(t *testing.T) {
+	v, _ := setupFolderVault(t)
+	ctx := context.Background()
+
+	t.Run("whole vault", func(t *testing.T) {
+		folders, err := v.ListFolders(ctx, FolderOptions{})
+		if err != nil {
+			t.Fatalf("ListFolders() error = %v", err)
+		}
+		want := []FolderInfo{
+			{Path: "/", Notes: 1, TotalNotes: 5},
+			{Path: "Areas", Notes: 1, TotalNotes: 1},
+			{Path: "Empty", Notes: 0, TotalNotes: 0},
+			{Path: "Projects", Notes: 0, TotalNotes: 3},
+			{Path: "Projects/Alpha", Notes: 2, TotalNotes: 2},
+			{Path: "Projects/Beta", Notes: 1, TotalNotes: 1},
+		}
+		if !slices.Equal(folders, want) {
+			t.Errorf("ListFolders() = %+v, want %+v", folders, want)
+		}
+	})
+
+	t.Run("subpath with hidden", func(t *testing.T) {
+		folders, err := v.ListFolders(ctx, FolderOptions{Subpath: "Projects", IncludeHidden: true})
+		if err != nil {
+			t.Fatalf("ListFolders() error = %v", err)
+		}
+		want := []FolderInfo{
+			{Path: "Projects", Notes: 0, TotalNotes: 4},
+			{Path: "Projects/.hidden", Notes: 1, TotalNotes: 1},
+			{Path: "Projects/Alpha", Notes: 2, TotalNotes: 2},
+			{Path: "Projects/Beta", Notes: 1, TotalNotes: 1},
+		}
+		if !slices.Equal(folders, want) {
+			t.Errorf("ListFolders() = %+v, want %+v", folders, want)
+		}
+	})
+
+	t.Run("missing folder", func(t *testing.T) {
+		if _, err := v.ListFolders(ctx, FolderOptions{Subpath: "Nope"}); !errors.Is(err, ErrDirectoryNotFound) {
+			t.Errorf("ListFolders() error = %v, want ErrDirectoryNotFound", err)
+		}
+	})
+}
+
+func TestCreateFolder(t *testing.T) {
+	v, tmpDir := setupFolderVault(t, WithReadOnlyPaths("Areas"))
+	ctx := context.Background()
+
+	if err := v.CreateFolder(ctx, "Plans/2025/Q1"); err != nil {
+		t.Fatalf("CreateFolder() error = %v", err)
+	}
+	if stat, err := os.Stat(filepath.Join(tmpDir, "Plans", "2025", "Q1")); err != nil || !stat.IsDir() {
+		t.Errorf("Expected folder to be created, got %v", err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want error
+	}{
+		{"existing folder", "Projects", ErrFolderExists},
+		{"existing note", "index.md", ErrFolderExists},
+		{"vault root", "", ErrInvalidPath},
+		{"dot", ".", ErrInvalidPath},
+		{"traversal", "../outside", ErrPathTraversal},
+		{"server data", dataDir + "/x", ErrReservedPath},
+		{"read-only", "Areas/New", ErrReadOnly},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := v.CreateFolder(ctx, tt.path); !errors.Is(err, tt.want) {
+				t.Errorf("CreateFolder(%q) error = %v, want %v", tt.path, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenameFolder(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("moves notes, cache and backups", func(t *testing.T) {
+		v, tmpDir := setupFolderVault(t)
+
+		// Warm the cache and create a backup for a note in the folder
+		if err := v.Update(ctx, "Projects/Beta/notes.md", "Beta notes v2"); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+
+		result, err := v.RenameFolder(ctx, RenameFolderOptions{Path: "Projects/Beta", NewPath: "Archive/Beta"})
+		if err != nil {
+			t.Fatalf("RenameFolder() error = %v", err)
+		}
+		if result.Path != "Projects/Beta" || result.NewPath != "Archive/Beta" || result.NotesMoved != 1 || result.LinksUpdated != 0 {
+			t.Errorf("RenameFolder() = %+v", result)
+		}
+
+		if _, err := os.Stat(filepath.Join(tmpDir, "Projects", "Beta")); !os.IsNotExist(err) {
+			t.Errorf("Expected old folder to be gone, got %v", err)
+		}
+		newPath := filepath.Join(tmpDir, "Archive", "Beta", "notes.md")
+		if entry, ok := v.cache.Get(newPath); !ok || entry.Content != "Beta notes v2" {
+			t.Errorf("Expected cache entry under the new path, got %+v (%v)", entry, ok)
+		}
+		versions, err := v.ListVersions(ctx, "Archive/Beta/notes.md")
+		if err != nil || len(versions) != 1 {
+			t.Errorf("ListVersions() = %v, %v; want the backup to follow the note", versions, err)
+		}
+	})
+
+	t.Run("updates links", func(t *testing.T) {
+		v, tmpDir := setupFolderVault(t)
+
+		result, err := v.RenameFolder(ctx, RenameFolderOptions{Path: "Projects/Alpha", NewPath: "Archive/2024/Alpha Team", UpdateLinks: true})
+		if err != nil {
+			t.Fatalf("RenameFolder() error = %v", err)
+		}
+
+		wantNotes := []string{"Archive/2024/Alpha Team/plan.md", "Archive/2024/Alpha Team/spec v1.md", "index.md"}
+		if result.NotesMoved != 2 || result.LinksUpdated != 5 || !slices.Equal(result.UpdatedNotes, wantNotes) {
+			t.Errorf("RenameFolder() = %+v", result)
+		}
+
+		want := map[string]string{
+			// [[plan]] still resolves by name and [[missing]] was already broken
+			"index.md": "[[Archive/2024/Alpha Team/plan]] [[plan]] [spec](Archive/2024/Alpha%20Team/spec%20v1.md#Scope) [[Archive/2024/Alpha Team/plan#Goals|goals]] [[missing]]",
+			// The relative link broke with the extra level of nesting
+			"Archive/2024/Alpha Team/plan.md":    "Plan with [[spec v1]] and [home](index.md)",
+			"Archive/2024/Alpha Team/spec v1.md": "Spec ![[Archive/2024/Alpha Team/diagram.png]]",
+		}
+		for path, content := range want {
+			data, err := os.ReadFile(filepath.Join(tmpDir, path))
+			if err != nil {
+				t.Fatalf("Failed to read %s: %v", path, err)
+			}
+			if string(data) != content {
+				t.Errorf("%s = %q, want %q", path, data, content)
+			}
+		}
+
+		report, err := v.Verify(ctx, VerifyOptions{})
+		if err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+		for _, problem := range report.Problems {
+			if problem.Kind == ProblemBrokenLink && problem.Message != `Link target "missing" does not exist` {
+				t.Errorf("Unexpected broken link after rename: %+v", problem)
+			}
+		}
+	})
+
+	t.Run("case only", func(t *testing.T) {
+		v, tmpDir := setupFolderVault(t)
+
+		if _, err := v.RenameFolder(ctx, RenameFolderOptions{Path: "Areas", NewPath: "areas"}); err != nil {
+			t.Fatalf("RenameFolder() error = %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(tmpDir, "areas", "health.md")); err != nil {
+			t.Errorf("Expected note under the renamed folder: %v", err)
+		}
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		v, tmpDir := setupFolderVault(t, WithReadOnlyPaths("Areas"))
+
+		tests := []struct {
+			name    string
+			path    string
+			newPath string
+			want    error
+		}{
+			{"onto existing folder", "Projects/Alpha", "Projects/Beta", ErrFolderExists},
+			{"onto existing note", "Projects/Beta", "index.md", ErrFolderExists},
+			{"into itself", "Projects", "Projects/Sub", ErrInvalidPath},
+			{"same path", "Projects", "Projects", ErrInvalidPath},
+			{"outside vault", "Projects", "../Projects", ErrPathTraversal},
+			{"from outside vault", "../x", "Moved", ErrPathTraversal},
+			{"vault root", "", "Moved", ErrInvalidPath},
+			{"into server data", "Projects", dataDir + "/Projects", ErrReservedPath},
+			{"missing source", "Nope", "Moved", ErrDirectoryNotFound},
+			{"read-only source", "Areas", "Moved", ErrReadOnly},
+			{"read-only destination", "Projects/Beta", "Areas/Beta", ErrReadOnly},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if _, err := v.RenameFolder(ctx, RenameFolderOptions{Path: tt.path, NewPath: tt.newPath}); !errors.Is(err, tt.want) {
+					t.Errorf("RenameFolder(%q, %q) error = %v, want %v", tt.path, tt.newPath, err, tt.want)
+				}
+			})
+		}
+
+		// Nothing moved
+		for _, path := range []string{"Projects/Alpha/plan.md", "Projects/Beta/notes.md", "Areas/health.md"} {
+			if _, err := os.Stat(filepath.Join(tmpDir, path)); err != nil {
+				t.Errorf("Expected %s to stay in place: %v", path, err)
+			}
+		}
+	})
+}
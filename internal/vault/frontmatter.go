@@ -0,0 +1,172 @@
+package vault
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SplitFrontmatter separates a leading YAML frontmatter block from the
+// note body. The returned frontmatter excludes the --- delimiters.
+// ok is false, and body is the whole content, when there is no block.
+func SplitFrontmatter(content string) (frontmatter, body string, ok bool) {
+	rest, found := strings.CutPrefix(content, "---")
+	if !found {
+		return "", content, false
+	}
+	rest, found = strings.CutPrefix(strings.TrimPrefix(rest, "\r"), "\n")
+	if !found {
+		return "", content, false
+	}
+
+	// The block ends at the first line consisting of --- or ...
+	offset := 0
+	for line := range strings.Lines(rest) {
+		trimmed := strings.TrimRight(line, "\r\n")
+		if trimmed == "---" || trimmed == "..." {
+			return rest[:offset], rest[offset+len(line):], true
+		}
+		offset += len(line)
+	}
+
+	return "", content, false
+}
+
+// parseFrontmatter decodes the YAML block at the start of content
+// Returns nil when there is no frontmatter or it is not valid YAML
+func parseFrontmatter(content string) map[string]any {
+	block, _, ok := SplitFrontmatter(content)
+	if !ok {
+		return nil
+	}
+
+	var fields map[string]any
+	if err := yaml.Unmarshal([]byte(block), &fields); err != nil {
+		return nil
+	}
+	return fields
+}
+
+// frontmatterTitle returns the title property, if set
+func frontmatterTitle(fields map[string]any) string {
+	title, ok := fields["title"]
+	if !ok || title == nil {
+		return ""
+	}
+	return strings.TrimSpace(fmt.Sprint(title))
+}
+
+// frontmatterAliases returns the aliases property as a list
+// Accepts a single string or a list, under "aliases" or the legacy "alias"
+func frontmatterAliases(fields map[string]any) []string {
+	var aliases []string
+	for _, key := range []string{"aliases", "alias"} {
+		switch value := fields[key].(type) {
+		case string:
+			if alias := strings.TrimSpace(value); alias != "" {
+				aliases = append(aliases, alias)
+			}
+		case []any:
+			for _, item := range value {
+				if item == nil {
+					continue
+				}
+				if alias := strings.TrimSpace(fmt.Sprint(item)); alias != "" {
+					aliases = append(aliases, alias)
+				}
+			}
+		}
+	}
+	return aliases
+}
+
+// frontmatterBlock is the frontmatter of a note split into lines, for
+// editing properties without rewriting the others
+type frontmatterBlock struct {
+	content string     // The whole note
+	mapping *yaml.Node // The properties, nil when there are none
+	lines   []string   // Lines of the block without its delimiters
+}
+
+// parseFrontmatterBlock splits the frontmatter of content for editing,
+// failing when it is not a YAML mapping. A note without frontmatter gets
+// an empty block.
+func parseFrontmatterBlock(content string) (*frontmatterBlock, error) {
+	block, _, _ := SplitFrontmatter(content)
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(block), &doc); err != nil {
+		return nil, fmt.Errorf("invalid frontmatter: %w", err)
+	}
+	b := &frontmatterBlock{content: content}
+	switch {
+	case doc.Kind == 0, doc.Kind == yaml.DocumentNode && len(doc.Content) == 0:
+		// No properties, at most comments
+	case doc.Kind == yaml.DocumentNode && doc.Content[0].Kind == yaml.MappingNode:
+		b.mapping = doc.Content[0]
+	default:
+		return nil, fmt.Errorf("frontmatter is not a list of properties")
+	}
+
+	b.lines = strings.SplitAfter(block, "\n")
+	if b.lines[len(b.lines)-1] == "" {
+		b.lines = b.lines[:len(b.lines)-1]
+	}
+	return b, nil
+}
+
+// render returns the note with the lines of the block as its frontmatter,
+// keeping the delimiters it had, or adding a block when it had none
+func (b *frontmatterBlock) render() string {
+	newBlock := strings.Join(b.lines, "")
+	block, body, hasBlock := SplitFrontmatter(b.content)
+	if !hasBlock {
+		return "---\n" + newBlock + "---\n" + b.content
+	}
+	opening, _, _ := strings.Cut(b.content, "\n")
+	closing := b.content[len(opening)+1+len(block) : len(b.content)-len(body)]
+	return opening + "\n" + newBlock + closing + body
+}
+
+// setProperty sets the property key to the scalar value on one line,
+// keeping the comment after it, or removes the property when value is
+// nil. Only the lines of the property change; a property the block lacks
+// is appended. The mapping is not updated, so each block takes one edit.
+func (b *frontmatterBlock) setProperty(key string, value *yaml.Node) {
+	if b.mapping != nil {
+		for i := 0; i+1 < len(b.mapping.Content); i += 2 {
+			k, v := b.mapping.Content[i], b.mapping.Content[i+1]
+			if k.Value != key {
+				continue
+			}
+			start := k.Line - 1
+			end := fieldEnd(b.lines, start, k.Column-1, v.Kind == yaml.SequenceNode && v.Style&yaml.FlowStyle == 0)
+			var replacement []string
+			if value != nil {
+				line := b.lines[start][:strings.Index(b.lines[start], ":")+1] + " " + renderScalar(value)
+				if v.LineComment != "" {
+					line += " " + v.LineComment
+				}
+				replacement = []string{line + "\n"}
+			}
+			b.lines = slices.Replace(b.lines, start, end, replacement...)
+			return
+		}
+	}
+	if value != nil {
+		b.lines = append(b.lines, key+": "+renderScalar(value)+"\n")
+	}
+}
+
+// setFrontmatterProperty sets or, with a nil value, removes the property
+// key in the frontmatter of content, see frontmatterBlock.setProperty
+func setFrontmatterProperty(content, key string, value *yaml.Node) (string, error) {
+	b, err := parseFrontmatterBlock(content)
+	if err != nil {
+		return "", err
+	}
+	b.setProperty(key, value)
+	return b.render(), nil
+}
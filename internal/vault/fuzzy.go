@@ -0,0 +1,322 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// DefaultFuzzyLimit is the number of matches FindNote returns by default
+const DefaultFuzzyLimit = 10
+
+// Scores of the fuzzy matcher, modelled on editor fuzzy finders. Each
+// matched character earns fuzzyMatch plus a bonus for starting a word, the
+// first one twice; characters continuing a run keep the bonus of the run's
+// start. Skipped characters and folders cost a little.
+const (
+	fuzzyMatch          = 16
+	fuzzyBoundaryWhite  = 10 // After whitespace
+	fuzzyBoundarySlash  = 9  // First character of a folder or file name
+	fuzzyBoundary       = 8  // After other punctuation such as - or _
+	fuzzyCamel          = 7  // Upper case letter after a lower case one, or a digit after a letter
+	fuzzyConsecutive    = 4  // Least bonus of a character right after the previous match
+	fuzzyFirstCharBonus = 2  // Multiplier of the first matched character's bonus
+	fuzzyGapStart       = 3  // First skipped character between two matches
+	fuzzyGapExtend      = 1  // Every further skipped character
+	fuzzyDepth          = 4  // Every folder above the note
+	fuzzyNoMatch        = math.MinInt / 2
+)
+
+// FuzzyMatch is a note found by FindNote
+type FuzzyMatch struct {
+	Path  string `json:"path"`
+	Score int    `json:"score"` // Higher is better
+}
+
+// FuzzyOptions selects the notes FindNote considers
+type FuzzyOptions struct {
+	Query   string
+	Subpath string // Only notes in this folder, empty for the whole vault
+	Limit   int    // Maximum matches, DefaultFuzzyLimit when 0 or less
+}
+
+// fuzzyCandidate is a note path prepared for matching
+type fuzzyCandidate struct {
+	path   string
+	folded []rune // Case folded runes of the path without .md
+	bonus  []int  // Boundary bonus of each rune
+	depth  int    // Folders above the note
+}
+
+// pathListing caches the note paths of the vault for FindNote, with the
+// modification time of every directory seen. Adding, removing or renaming
+// an entry changes its directory's time, so the listing is only walked
+// again when one of them differs.
+type pathListing struct {
+	mu         sync.Mutex
+	candidates []fuzzyCandidate
+	dirs       map[string]time.Time // Full path -> mtime when listed, nil when stale
+}
+
+// invalidate makes the next use walk the vault again, for changes made
+// within the resolution of directory times
+func (l *pathListing) invalidate() {
+	l.mu.Lock()
+	l.dirs = nil
+	l.mu.Unlock()
+}
+
+// current reports whether no listed directory changed since the walk
+// Caller must hold l.mu
+func (l *pathListing) current() bool {
+	if l.dirs == nil {
+		return false
+	}
+	for dir, mtime := range l.dirs {
+		stat, err := os.Stat(dir)
+		if err != nil || !stat.ModTime().Equal(mtime) {
+			return false
+		}
+	}
+	return true
+}
+
+// noteCandidates returns the prepared paths of every visible note,
+// walking the vault only when a directory changed
+func (v *vault) noteCandidates(ctx context.Context) ([]fuzzyCandidate, error) {
+	l := &v.paths
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.current() {
+		return l.candidates, nil
+	}
+
+	start := time.Now()
+	dirs := make(map[string]time.Time)
+	var candidates []fuzzyCandidate
+
+	walkFn := func(fullPath string, info os.FileInfo, err error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err != nil {
+			return nil // Skip inaccessible files and directories
+		}
+
+		if fullPath != v.basePath && !v.includeHidden && isHidden(fullPath) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if info.IsDir() {
+			dirs[fullPath] = info.ModTime()
+			return nil
+		}
+		if strings.HasSuffix(fullPath, ".md") {
+			candidates = append(candidates, newFuzzyCandidate(v.relPath(fullPath)))
+		}
+		return nil
+	}
+
+	if err := v.walk(v.basePath, walkFn); err != nil {
+		return nil, fmt.Errorf("failed to walk directory: %w", err)
+	}
+
+	l.candidates, l.dirs = candidates, dirs
+	v.logger.Debug("note paths listed", "notes", len(candidates), "dirs", len(dirs), "duration", time.Since(start))
+	return candidates, nil
+}
+
+// newFuzzyCandidate prepares a vault-relative note path for matching
+func newFuzzyCandidate(relPath string) fuzzyCandidate {
+	original := []rune(norm.NFC.String(strings.TrimSuffix(relPath, ".md")))
+	c := fuzzyCandidate{
+		path:   relPath,
+		folded: make([]rune, len(original)),
+		bonus:  make([]int, len(original)),
+		depth:  strings.Count(relPath, "/"),
+	}
+
+	prev := '/'
+	for i, r := range original {
+		c.folded[i] = foldRune(r)
+		switch {
+		case !isFuzzyWordRune(r):
+		case unicode.IsSpace(prev):
+			c.bonus[i] = fuzzyBoundaryWhite
+		case prev == '/':
+			c.bonus[i] = fuzzyBoundarySlash
+		case !isFuzzyWordRune(prev):
+			c.bonus[i] = fuzzyBoundary
+		case unicode.IsUpper(r) && unicode.IsLower(prev):
+			c.bonus[i] = fuzzyCamel
+		case unicode.IsDigit(r) && !unicode.IsDigit(prev):
+			c.bonus[i] = fuzzyCamel
+		}
+		prev = r
+	}
+	return c
+}
+
+// isFuzzyWordRune reports whether r is part of a word; unlike in the
+// search index, _ separates words in file names
+func isFuzzyWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
+
+// foldQuery case folds query for matching, dropping a .md extension and
+// whitespace so that "kuber setup" can match across a separator
+func foldQuery(query string) []rune {
+	var folded []rune
+	for _, r := range norm.NFC.String(strings.TrimSuffix(strings.TrimSpace(query), ".md")) {
+		if !unicode.IsSpace(r) {
+			folded = append(folded, foldRune(r))
+		}
+	}
+	return folded
+}
+
+// score returns the best score of query as a subsequence of the
+// candidate, and false when it is not one
+func (c fuzzyCandidate) score(query []rune) (int, bool) {
+	n, m := len(c.folded), len(query)
+	if m == 0 || m > n {
+		return 0, false
+	}
+
+	// Cheap rejection before the full scoring pass
+	i := 0
+	for _, r := range c.folded {
+		if r == query[i] {
+			if i++; i == m {
+				break
+			}
+		}
+	}
+	if i < m {
+		return 0, false
+	}
+
+	// prev[j] is the best score with the previous query rune matched at
+	// j and prevRun[j] the bonus of the run of consecutive matches ending
+	// there; cur and curRun fill in the same for the current rune
+	prev, prevRun := make([]int, n), make([]int, n)
+	cur, curRun := make([]int, n), make([]int, n)
+	for j := range n {
+		prev[j] = fuzzyNoMatch
+		if c.folded[j] == query[0] {
+			prev[j] = fuzzyMatch + c.bonus[j]*fuzzyFirstCharBonus
+			prevRun[j] = c.bonus[j]
+		}
+	}
+
+	for i := 1; i < m; i++ {
+		// gapped is the best score of a previous match before j-1, less
+		// the cost of the characters skipped up to j
+		gapped := fuzzyNoMatch
+		for j := range n {
+			cur[j] = fuzzyNoMatch
+			if j >= 2 {
+				gapped = max(gapped-fuzzyGapExtend, prev[j-2]-fuzzyGapStart)
+			}
+			if c.folded[j] != query[i] || j == 0 {
+				continue
+			}
+
+			if gapped > fuzzyNoMatch/2 {
+				cur[j], curRun[j] = gapped+fuzzyMatch+c.bonus[j], c.bonus[j]
+			}
+			if prev[j-1] == fuzzyNoMatch {
+				continue
+			}
+			run := prevRun[j-1]
+			if c.bonus[j] >= fuzzyBoundary && c.bonus[j] > run {
+				run = c.bonus[j]
+			}
+			if score := prev[j-1] + fuzzyMatch + max(c.bonus[j], run, fuzzyConsecutive); score >= cur[j] {
+				cur[j], curRun[j] = score, run
+			}
+		}
+		prev, cur = cur, prev
+		prevRun, curRun = curRun, prevRun
+	}
+
+	best := fuzzyNoMatch
+	for _, s := range prev {
+		best = max(best, s)
+	}
+	if best <= fuzzyNoMatch/2 {
+		return 0, false
+	}
+	return best - c.depth*fuzzyDepth, true
+}
+
+// FindNote matches opts.Query against note paths as an ordered
+// subsequence of characters, ignoring case, and returns the best matches
+// first. No note content is read; the path listing is cached and only
+// walked again when a directory in the vault changes.
+func (v *vault) FindNote(ctx context.Context, opts FuzzyOptions) ([]FuzzyMatch, error) {
+	query := foldQuery(opts.Query)
+	if len(query) == 0 {
+		return nil, fmt.Errorf("%w: query must not be empty", ErrInvalidPath)
+	}
+
+	prefix := ""
+	if opts.Subpath != "" {
+		root, err := v.validateDir(opts.Subpath)
+		if err != nil {
+			return nil, err
+		}
+		if rel := v.relPath(root); rel != "." {
+			prefix = rel + "/"
+		}
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultFuzzyLimit
+	}
+
+	candidates, err := v.noteCandidates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []FuzzyMatch{}
+	for _, c := range candidates {
+		if !strings.HasPrefix(c.path, prefix) {
+			continue
+		}
+		if score, ok := c.score(query); ok {
+			matches = append(matches, FuzzyMatch{Path: c.path, Score: score})
+		}
+	}
+
+	// Best score first, then shortest path
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		if len(matches[i].Path) != len(matches[j].Path) {
+			return len(matches[i].Path) < len(matches[j].Path)
+		}
+		return matches[i].Path < matches[j].Path
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
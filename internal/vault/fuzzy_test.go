@@ -0,0 +1,216 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// setupFuzzyVault creates a vault with the given empty notes
+func setupFuzzyVault(t testing.TB, paths ...string) (Vault, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	notes := make(map[string]string, len(paths))
+	for _, path := range paths {
+		notes[path] = ""
+	}
+	writeFiles(t, tmpDir, notes)
+
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	return v, tmpDir
+}
+
+// matchPaths returns the paths of matches in order
+func matchPaths(matches []FuzzyMatch) []string {
+	paths := make([]string, len(matches))
+	for i, m := range matches {
+		paths[i] = m.Path
+	}
+	return paths
+}
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		better string
+		worse  string
+	}{
+		{"word boundaries", "ks", "kubernetes setup.md", "kiosks.md"},
+		{"consecutive run", "setup", "setup.md", "s-e-t-u-p.md"},
+		{"shallow path", "plan", "plan.md", "archive/2023/plan.md"},
+		{"camel case", "qp", "QuarterlyPlan.md", "quip.md"},
+		{"file name start", "kube", "devops/kubernetes.md", "devops/mikube.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := foldQuery(tt.query)
+			better, ok := newFuzzyCandidate(tt.better).score(query)
+			if !ok {
+				t.Fatalf("%q does not match %s", tt.query, tt.better)
+			}
+			worse, ok := newFuzzyCandidate(tt.worse).score(query)
+			if !ok {
+				t.Fatalf("%q does not match %s", tt.query, tt.worse)
+			}
+			if better <= worse {
+				t.Errorf("Score of %s = %d, want more than %s = %d", tt.better, better, tt.worse, worse)
+			}
+		})
+	}
+
+	t.Run("no match", func(t *testing.T) {
+		for _, query := range []string{"xyz", "tespu", "setupx"} {
+			if _, ok := newFuzzyCandidate("setup.md").score(foldQuery(query)); ok {
+				t.Errorf("Expected %q not to match setup.md", query)
+			}
+		}
+	})
+}
+
+func TestFindNote(t *testing.T) {
+	ctx := context.Background()
+	v, tmpDir := setupFuzzyVault(t,
+		"DevOps/Kubernetes Setup.md",
+		"DevOps/kubectl cheatsheet.md",
+		"Archive/2022/Kubernetes Setup.md",
+		"Cooking/Crème Brûlée.md",
+		"Projects/Q3 Planning.md",
+		"Projects/.secret/plan.md",
+		"notes.txt",
+	)
+
+	t.Run("ranking", func(t *testing.T) {
+		matches, err := v.FindNote(ctx, FuzzyOptions{Query: "kuber setup"})
+		if err != nil {
+			t.Fatalf("FindNote failed: %v", err)
+		}
+		want := []string{"DevOps/Kubernetes Setup.md", "Archive/2022/Kubernetes Setup.md"}
+		if got := matchPaths(matches); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("FindNote() = %v, want %v", got, want)
+		}
+		if matches[0].Score <= matches[1].Score {
+			t.Errorf("Expected a lower score for the deeper note, got %+v", matches)
+		}
+	})
+
+	tests := []struct {
+		name string
+		opts FuzzyOptions
+		want []string
+	}{
+		{"case insensitive", FuzzyOptions{Query: "KUBECTL"}, []string{"DevOps/kubectl cheatsheet.md"}},
+		{"unicode", FuzzyOptions{Query: "crème BRÛ"}, []string{"Cooking/Crème Brûlée.md"}},
+		{"extension", FuzzyOptions{Query: "q3 planning.md"}, []string{"Projects/Q3 Planning.md"}},
+		{"folder", FuzzyOptions{Query: "kuber", Subpath: "Archive"}, []string{"Archive/2022/Kubernetes Setup.md"}},
+		{"limit", FuzzyOptions{Query: "kube", Limit: 1}, []string{"DevOps/Kubernetes Setup.md"}},
+		{"hidden and non-notes", FuzzyOptions{Query: "secret"}, []string{}},
+		{"nothing", FuzzyOptions{Query: "zzz"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := v.FindNote(ctx, tt.opts)
+			if err != nil {
+				t.Fatalf("FindNote failed: %v", err)
+			}
+			if got := matchPaths(matches); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("FindNote() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("ties broken by shorter path", func(t *testing.T) {
+		v, _ := setupFuzzyVault(t, "b/topic long.md", "a/topic.md", "c/topic.md")
+		matches, err := v.FindNote(ctx, FuzzyOptions{Query: "topic"})
+		if err != nil {
+			t.Fatalf("FindNote failed: %v", err)
+		}
+		want := []string{"a/topic.md", "c/topic.md", "b/topic long.md"}
+		if got := matchPaths(matches); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("FindNote() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("listing follows changes", func(t *testing.T) {
+		if err := os.WriteFile(filepath.Join(tmpDir, "DevOps", "Helm.md"), nil, 0644); err != nil {
+			t.Fatalf("Failed to create note: %v", err)
+		}
+		if err := os.Remove(filepath.Join(tmpDir, "DevOps", "kubectl cheatsheet.md")); err != nil {
+			t.Fatalf("Failed to remove note: %v", err)
+		}
+		v.(*vault).paths.invalidate() // Directory times may not have ticked yet
+
+		if matches, _ := v.FindNote(ctx, FuzzyOptions{Query: "helm"}); len(matches) != 1 {
+			t.Errorf("Expected the new note, got %v", matches)
+		}
+		if matches, _ := v.FindNote(ctx, FuzzyOptions{Query: "kubectl"}); len(matches) != 0 {
+			t.Errorf("Expected the removed note to be gone, got %v", matches)
+		}
+
+		if err := v.Create(ctx, "Inbox/kubeadm.md", "x"); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		if matches, _ := v.FindNote(ctx, FuzzyOptions{Query: "kubeadm"}); len(matches) != 1 {
+			t.Errorf("Expected the created note, got %v", matches)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := v.FindNote(ctx, FuzzyOptions{Query: "  "}); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("Expected ErrInvalidPath for an empty query, got %v", err)
+		}
+		if _, err := v.FindNote(ctx, FuzzyOptions{Query: "x", Subpath: "Nowhere"}); !errors.Is(err, ErrDirectoryNotFound) {
+			t.Errorf("Expected ErrDirectoryNotFound, got %v", err)
+		}
+	})
+}
+
+func TestPathListingReuse(t *testing.T) {
+	v, tmpDir := setupFuzzyVault(t, "a/one.md")
+	vt := v.(*vault)
+
+	first, err := vt.noteCandidates(context.Background())
+	if err != nil {
+		t.Fatalf("noteCandidates failed: %v", err)
+	}
+	second, _ := vt.noteCandidates(context.Background())
+	if &first[0] != &second[0] {
+		t.Error("Expected the listing to be reused while no directory changed")
+	}
+
+	// A directory whose time changed triggers a new walk
+	mtime := vt.paths.dirs[filepath.Join(vt.basePath, "a")]
+	if err := os.Chtimes(filepath.Join(tmpDir, "a"), mtime, mtime.Add(time.Second)); err != nil {
+		t.Fatalf("Failed to touch directory: %v", err)
+	}
+	third, _ := vt.noteCandidates(context.Background())
+	if &first[0] == &third[0] {
+		t.Error("Expected a new listing after a directory changed")
+	}
+}
+
+func BenchmarkFindNote(b *testing.B) {
+	paths := make([]string, 0, 10000)
+	for i := range 10000 {
+		paths = append(paths, fmt.Sprintf("Area %d/Project %d/Meeting notes %d.md", i%20, i%200, i))
+	}
+	v, _ := setupFuzzyVault(b, paths...)
+	ctx := context.Background()
+	if _, err := v.FindNote(ctx, FuzzyOptions{Query: "warm"}); err != nil {
+		b.Fatalf("FindNote failed: %v", err)
+	}
+
+	for b.Loop() {
+		if _, err := v.FindNote(ctx, FuzzyOptions{Query: "proj 12 meet 9"}); err != nil {
+			b.Fatalf("FindNote failed: %v", err)
+		}
+	}
+}
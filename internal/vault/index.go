@@ -0,0 +1,508 @@
+package vault
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp/syntax"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// defaultIndexPostings bounds the search index to this many word-note
+// pairs, roughly 100 MB; the least recently indexed notes are dropped
+// beyond it and searched by scanning again
+const defaultIndexPostings = 2_000_000
+
+// tagTermPrefix marks tag terms in the index; words never contain it
+const tagTermPrefix = "#"
+
+// indexFile stores the search index saved by SaveIndex, below the data
+// directory
+const indexFile = "index.json"
+
+// indexFormat is the version of the index file; files of another version
+// are ignored, as their terms may have been split or folded differently
+const indexFormat = 1
+
+// indexData is the layout of the index file
+type indexData struct {
+	Format int            `json:"format"`
+	Notes  []indexedEntry `json:"notes"` // Least recently indexed first
+}
+
+// indexedEntry is one note in the index file
+type indexedEntry struct {
+	Path  string    `json:"path"` // Relative to the vault, with forward slashes
+	Mtime time.Time `json:"mtime"`
+	Hash  string    `json:"hash"`
+	Terms []string  `json:"terms"`
+}
+
+// WithSearchIndex enables an in-memory inverted index of the words and
+// tags of every note loaded. Search uses it to skip notes that cannot
+// match a literal query or tag filter without reading them; regular
+// expressions still scan every note. The index follows file modification
+// times, so edits made outside the server are picked up like the cache.
+// NewVault starts it from the index saved by SaveIndex, if any.
+func WithSearchIndex() Option {
+	return func(v *vault) {
+		v.index = newSearchIndex(defaultIndexPostings)
+	}
+}
+
+// indexedDoc is one note in the search index
+type indexedDoc struct {
+	path  string        // Full path of the note
+	mtime time.Time     // Modification time of the indexed content
+	hash  string        // Content hash of the indexed content
+	terms []string      // Distinct folded words and tag terms
+	elem  *list.Element // Position in the index order
+}
+
+// searchIndex maps folded words and tags to the notes containing them
+// It is safe for concurrent use
+type searchIndex struct {
+	mu          sync.RWMutex
+	docs        map[string]*indexedDoc              // Full path -> document
+	postings    map[string]map[*indexedDoc]struct{} // Term -> documents
+	order       *list.List                          // Front is least recently indexed
+	size        int                                 // Total postings
+	maxPostings int
+}
+
+// newSearchIndex creates an index holding at most maxPostings postings
+func newSearchIndex(maxPostings int) *searchIndex {
+	return &searchIndex{
+		docs:        make(map[string]*indexedDoc),
+		postings:    make(map[string]map[*indexedDoc]struct{}),
+		order:       list.New(),
+		maxPostings: maxPostings,
+	}
+}
+
+// has reports whether the note at path is indexed as of mtime
+func (idx *searchIndex) has(path string, mtime time.Time) bool {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	doc, ok := idx.docs[path]
+	return ok && doc.mtime.Equal(mtime)
+}
+
+// add indexes the content and tags of the note at path, replacing any
+// previous version
+func (idx *searchIndex) add(path string, mtime time.Time, hash, content string, tags []string) {
+	idx.addTerms(path, mtime, hash, indexTerms(content, tags))
+}
+
+// addTerms indexes the note at path under terms, as add does
+func (idx *searchIndex) addTerms(path string, mtime time.Time, hash string, terms []string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if old, ok := idx.docs[path]; ok {
+		idx.removeDoc(old)
+	}
+
+	doc := &indexedDoc{path: path, mtime: mtime, hash: hash, terms: terms}
+	doc.elem = idx.order.PushBack(doc)
+	idx.docs[path] = doc
+	for _, term := range terms {
+		set, ok := idx.postings[term]
+		if !ok {
+			set = make(map[*indexedDoc]struct{})
+			idx.postings[term] = set
+		}
+		set[doc] = struct{}{}
+	}
+	idx.size += len(terms)
+
+	// Drop the oldest notes, but always keep the one just added
+	for idx.size > idx.maxPostings && idx.order.Len() > 1 {
+		idx.removeDoc(idx.order.Front().Value.(*indexedDoc))
+	}
+}
+
+// IndexStats reports the size of the search index
+type IndexStats struct {
+	Notes       int `json:"notes"`        // Notes currently indexed
+	Terms       int `json:"terms"`        // Distinct words and tags
+	Postings    int `json:"postings"`     // Word-note pairs
+	MaxPostings int `json:"max_postings"` // Postings kept before the oldest notes are dropped
+}
+
+// stats returns the current size of the index
+func (idx *searchIndex) stats() IndexStats {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return IndexStats{
+		Notes:       len(idx.docs),
+		Terms:       len(idx.postings),
+		Postings:    idx.size,
+		MaxPostings: idx.maxPostings,
+	}
+}
+
+// countWithin returns the number of indexed notes at or below dir
+func (idx *searchIndex) countWithin(dir string) int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	n := 0
+	for path := range idx.docs {
+		if isWithin(path, dir) {
+			n++
+		}
+	}
+	return n
+}
+
+// stamps returns the modification time and content hash of every
+// indexed note
+func (idx *searchIndex) stamps() map[string]CacheStamp {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	stamps := make(map[string]CacheStamp, len(idx.docs))
+	for path, doc := range idx.docs {
+		stamps[path] = CacheStamp{doc.mtime, doc.hash}
+	}
+	return stamps
+}
+
+// termCounts returns the number of postings of every indexed note
+func (idx *searchIndex) termCounts() map[string]int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	counts := make(map[string]int, len(idx.docs))
+	for path, doc := range idx.docs {
+		counts[path] = len(doc.terms)
+	}
+	return counts
+}
+
+// remove drops the note at path from the index
+func (idx *searchIndex) remove(path string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if doc, ok := idx.docs[path]; ok {
+		idx.removeDoc(doc)
+	}
+}
+
+// reset drops every note from the index, returning how many there were
+func (idx *searchIndex) reset() int {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	n := len(idx.docs)
+	idx.docs = make(map[string]*indexedDoc)
+	idx.postings = make(map[string]map[*indexedDoc]struct{})
+	idx.order.Init()
+	idx.size = 0
+	return n
+}
+
+// rename moves the note indexed at oldPath to newPath
+func (idx *searchIndex) rename(oldPath, newPath string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	doc, ok := idx.docs[oldPath]
+	if !ok || oldPath == newPath {
+		return
+	}
+	if existing, ok := idx.docs[newPath]; ok {
+		idx.removeDoc(existing)
+	}
+	delete(idx.docs, oldPath)
+	doc.path = newPath
+	idx.docs[newPath] = doc
+}
+
+// entries returns the indexed notes, least recently indexed first, with
+// paths made relative by rel
+func (idx *searchIndex) entries(rel func(string) string) []indexedEntry {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	entries := make([]indexedEntry, 0, idx.order.Len())
+	for e := idx.order.Front(); e != nil; e = e.Next() {
+		doc := e.Value.(*indexedDoc)
+		entries = append(entries, indexedEntry{Path: rel(doc.path), Mtime: doc.mtime, Hash: doc.hash, Terms: doc.terms})
+	}
+	return entries
+}
+
+// removeDoc unlinks doc from every posting list
+// Caller must hold the write lock
+func (idx *searchIndex) removeDoc(doc *indexedDoc) {
+	for _, term := range doc.terms {
+		set := idx.postings[term]
+		delete(set, doc)
+		if len(set) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	idx.size -= len(doc.terms)
+	idx.order.Remove(doc.elem)
+	delete(idx.docs, doc.path)
+}
+
+// indexQuery is what the index can tell about a search
+type indexQuery struct {
+	words   []string // Folded words that must each occur inside some word of a match
+	tagsAll []string // Tags a match must all have
+	tagsAny []string // Tags of which a match must have one
+}
+
+// newIndexQuery derives the index lookups for a search, reporting false
+// when the index cannot narrow it down
+func newIndexQuery(opts SearchOptions) (indexQuery, bool) {
+	var q indexQuery
+	for _, pattern := range opts.queriesAll() {
+		literal, ok := pattern, opts.QueryMode == QueryLiteral || opts.QueryMode == QueryWord
+		if !ok {
+			literal, ok = queryLiteral(pattern)
+		}
+		if ok {
+			q.words = append(q.words, wordTokens(literal)...)
+		}
+	}
+	q.tagsAll = tagTerms(opts.TagsAll)
+	q.tagsAny = tagTerms(opts.TagsAny)
+
+	return q, len(q.words) > 0 || len(q.tagsAll) > 0 || len(q.tagsAny) > 0
+}
+
+// tagTerms converts tag filters to index terms
+func tagTerms(tags []string) []string {
+	var terms []string
+	for _, tag := range tags {
+		terms = append(terms, tagTermPrefix+foldTag(tag))
+	}
+	return terms
+}
+
+// queryLiteral returns the text a search query matches literally, such as
+// "meeting notes" or "v1\.2", reporting false for real regular expressions
+func queryLiteral(query string) (string, bool) {
+	re, err := syntax.Parse(query, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	re = re.Simplify()
+	if re.Op != syntax.OpLiteral {
+		return "", false
+	}
+	return string(re.Rune), true
+}
+
+// matching returns the indexed notes that may match q
+// Each query word must occur within a word of the note, so substring
+// matches such as "plan" in "planning" are kept
+// Caller must hold the read lock
+func (idx *searchIndex) matching(q indexQuery) map[*indexedDoc]struct{} {
+	var sets []map[*indexedDoc]struct{}
+	for _, word := range q.words {
+		set := make(map[*indexedDoc]struct{})
+		for term, docs := range idx.postings {
+			if strings.HasPrefix(term, tagTermPrefix) || !strings.Contains(term, word) {
+				continue
+			}
+			for doc := range docs {
+				set[doc] = struct{}{}
+			}
+		}
+		sets = append(sets, set)
+	}
+	for _, tag := range q.tagsAll {
+		sets = append(sets, idx.postings[tag])
+	}
+	if len(q.tagsAny) > 0 {
+		set := make(map[*indexedDoc]struct{})
+		for _, tag := range q.tagsAny {
+			for doc := range idx.postings[tag] {
+				set[doc] = struct{}{}
+			}
+		}
+		sets = append(sets, set)
+	}
+
+	// Intersect, starting from the smallest set
+	result := make(map[*indexedDoc]struct{})
+	if len(sets) == 0 {
+		return result
+	}
+	smallest := 0
+	for i, set := range sets {
+		if len(set) < len(sets[smallest]) {
+			smallest = i
+		}
+	}
+next:
+	for doc := range sets[smallest] {
+		for i, set := range sets {
+			if i == smallest {
+				continue
+			}
+			if _, ok := set[doc]; !ok {
+				continue next
+			}
+		}
+		result[doc] = struct{}{}
+	}
+	return result
+}
+
+// skipper returns a function reporting whether a walked note can be left
+// out of a search because the index proves it cannot match q
+// Notes that are not indexed, or changed since, are never skipped
+func (idx *searchIndex) skipper(q indexQuery) func(file noteFile) bool {
+	// Snapshot matches and index state together so they agree
+	idx.mu.RLock()
+	matches := idx.matching(q)
+	skippable := make(map[string]time.Time, len(idx.docs)-len(matches))
+	for path, doc := range idx.docs {
+		if _, ok := matches[doc]; !ok {
+			skippable[path] = doc.mtime
+		}
+	}
+	idx.mu.RUnlock()
+
+	return func(file noteFile) bool {
+		mtime, ok := skippable[file.fullPath]
+		return ok && mtime.Equal(file.info.ModTime())
+	}
+}
+
+// indexTerms returns the distinct folded words of content plus tag terms
+func indexTerms(content string, tags []string) []string {
+	seen := make(map[string]struct{})
+	var terms []string
+	for _, word := range wordTokens(content) {
+		if _, ok := seen[word]; !ok {
+			seen[word] = struct{}{}
+			terms = append(terms, word)
+		}
+	}
+	for _, tag := range tags {
+		term := tagTermPrefix + foldTag(tag)
+		if _, ok := seen[term]; !ok {
+			seen[term] = struct{}{}
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// wordTokens splits text into folded runs of word characters
+// Any text matched by a case-insensitive literal search lies within the
+// same runs of the folded text, which is what makes the index a safe
+// prefilter
+func wordTokens(text string) []string {
+	var tokens []string
+	var b strings.Builder
+	flush := func() {
+		if b.Len() > 0 {
+			tokens = append(tokens, b.String())
+			b.Reset()
+		}
+	}
+	for _, r := range foldText(text) {
+		if !isWordRune(r) {
+			flush()
+			continue
+		}
+		b.WriteRune(r)
+	}
+	flush()
+	return tokens
+}
+
+// isWordRune reports whether r is part of an indexed word
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
+
+// foldRune maps r to the smallest rune that matches it case-insensitively,
+// mirroring the case folding of (?i) regular expressions
+func foldRune(r rune) rune {
+	folded := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		folded = min(folded, f)
+	}
+	return folded
+}
+
+// SaveIndex writes the search index to the data directory, where NewVault
+// loads it again with WithSearchIndex, and returns the size saved
+func (v *vault) SaveIndex(ctx context.Context) (IndexStats, error) {
+	if v.index == nil {
+		return IndexStats{}, ErrIndexDisabled
+	}
+	if err := ctx.Err(); err != nil {
+		return IndexStats{}, err
+	}
+
+	stats := v.index.stats()
+	raw, err := json.Marshal(indexData{Format: indexFormat, Notes: v.index.entries(v.relPath)})
+	if err != nil {
+		return IndexStats{}, fmt.Errorf("failed to encode search index: %w", err)
+	}
+	file := filepath.Join(v.basePath, dataDir, indexFile)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return IndexStats{}, fmt.Errorf("failed to create data directory: %w", err)
+	}
+	if err := writeDataFile(file, raw); err != nil {
+		return IndexStats{}, fmt.Errorf("failed to write search index: %w", err)
+	}
+	return stats, nil
+}
+
+// loadIndex fills the search index from the saved index file, leaving out
+// notes deleted or changed since it was saved. A missing, unreadable or
+// outdated file leaves the index empty, to be built as notes are read.
+func (v *vault) loadIndex() {
+	raw, err := os.ReadFile(filepath.Join(v.basePath, dataDir, indexFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	var data indexData
+	if err == nil {
+		err = json.Unmarshal(raw, &data)
+	}
+	if err != nil {
+		v.logger.Warn("cannot load saved search index", "error", err)
+		return
+	}
+	if data.Format != indexFormat {
+		v.logger.Info("ignoring search index saved in another format", "format", data.Format)
+		return
+	}
+
+	loaded := 0
+	for _, entry := range data.Notes {
+		fullPath, err := v.validateFile(entry.Path) // Canvas files are indexed too
+		if err != nil {
+			continue
+		}
+		if stat, err := os.Stat(fullPath); err != nil || !stat.ModTime().Equal(entry.Mtime) {
+			continue
+		}
+		v.index.addTerms(fullPath, entry.Mtime, entry.Hash, entry.Terms)
+		loaded++
+	}
+	v.logger.Debug("loaded saved search index", "notes", loaded, "saved", len(data.Notes))
+}
@@ -0,0 +1,346 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWordTokens(t *testing.T) {
+	// Text is case-folded, so ASCII letters come out lower case
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Hello, World!", []string{"hello", "world"}},
+		{"snake_case and v1.2", []string{"snake_case", "and", "v1", "2"}},
+		{"#tag [[Link]]", []string{"tag", "link"}},
+		{"Straße café", []string{"strasse", "café"}},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		if got := wordTokens(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("wordTokens(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+
+	// Case variants a (?i) regex treats as equal fold to the same token,
+	// as do composed and decomposed accents and the Turkish i
+	for _, pair := range [][2]string{{"hello", "HELLO"}, {"\u212a", "k"}, {"\u017f", "s"}, {"ÉTÉ", "été"}, {"cafe\u0301", "café"}, {"İstanbul", "ıstanbul"}} {
+		if a, b := wordTokens(pair[0]), wordTokens(pair[1]); !reflect.DeepEqual(a, b) {
+			t.Errorf("wordTokens(%q) = %q, wordTokens(%q) = %q, want equal", pair[0], a, pair[1], b)
+		}
+	}
+}
+
+func TestQueryLiteral(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    string
+		literal bool
+	}{
+		{"meeting", "meeting", true},
+		{"meeting notes", "meeting notes", true},
+		{`v1\.2`, "v1.2", true},
+		{"plan.*", "", false},
+		{"^todo", "", false},
+		{"a|b", "", false},
+		{"[abc]", "", false},
+		{"(", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := queryLiteral(tt.query)
+		if ok != tt.literal || got != tt.want {
+			t.Errorf("queryLiteral(%q) = %q, %v, want %q, %v", tt.query, got, ok, tt.want, tt.literal)
+		}
+	}
+}
+
+func TestSearchIndexMatchesScan(t *testing.T) {
+	tmpDir := generateVault(t, 200)
+	extra := map[string]string{
+		"special/planning.md": "Quarterly PLANNING session with #Work",
+		"special/unicode.md":  "Résumé of the \u017ftraße visit",
+		"special/punct.md":    "Version v1.2 released; see C++ notes",
+		"special/phrase.md":   "meeting notes from Monday",
+	}
+	writeFiles(t, tmpDir, extra)
+	ctx := context.Background()
+
+	scan, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	indexed, err := NewVault(tmpDir, WithSearchIndex())
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+
+	searches := []SearchOptions{
+		{Query: "topic7"},
+		{Query: "plan"},             // Substring of a word
+		{Query: "planning session"}, // Phrase
+		{Query: "ning ses"},         // Phrase spanning partial words
+		{Query: "RÉSUMÉ"},           // Unicode case
+		{Query: "STRASSE"},          // No simple fold match
+		{Query: "straße"},           // ſ folds to s
+		{Query: `v1\.2`},            // Escaped literal
+		{Query: `c\+\+`},            // Punctuation only after a word
+		{Query: "nothing-matches-this"},
+		{Query: "topic[12]"}, // Regex: full scan
+		{Query: "--"},        // No words: full scan
+		{TagsAll: []string{"tag1", "#group0"}},
+		{TagsAny: []string{"work", "tag3"}},
+		{Query: "note 1", TagsAny: []string{"tag2"}, Subpath: "folder2"},
+		{Query: "text", TagsNone: []string{"group1"}},
+		{QueryAll: []string{"planning", "session"}},
+		{Query: "meeting", QueryAll: []string{"notes"}, QueryNone: []string{"monday"}},
+		{QueryAny: []string{"topic3", "topic4"}, QueryNone: []string{"topic4"}},
+		{QueryAll: []string{"c++"}, QueryMode: QueryLiteral},
+		{QueryAll: []string{"plan"}, QueryMode: QueryWord},
+		{QueryAll: []string{"PLANNING"}, CaseSensitive: true},
+	}
+
+	for _, opts := range searches {
+		want, err := scan.Search(ctx, opts)
+		if err != nil {
+			t.Fatalf("Search(%+v) error = %v", opts, err)
+		}
+		// First search fills the index, the second one uses it
+		for range 2 {
+			got, err := indexed.Search(ctx, opts)
+			if err != nil {
+				t.Fatalf("Indexed Search(%+v) error = %v", opts, err)
+			}
+			if !reflect.DeepEqual(notePaths(got), notePaths(want)) {
+				t.Errorf("Indexed Search(%+v) = %v, want %v", opts, notePaths(got), notePaths(want))
+			}
+		}
+	}
+}
+
+// notePaths returns the paths of notes in order
+func notePaths(notes []NoteInfo) []string {
+	paths := []string{}
+	for _, n := range notes {
+		paths = append(paths, n.Path)
+	}
+	return paths
+}
+
+func TestSearchIndexTracksChanges(t *testing.T) {
+	_, tmpDir := setupTestVault(t)
+	ctx := context.Background()
+
+	v, err := NewVault(tmpDir, WithSearchIndex())
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+
+	search := func(query string) []string {
+		t.Helper()
+		notes, err := v.Search(ctx, SearchOptions{Query: query})
+		if err != nil {
+			t.Fatalf("Search(%q) error = %v", query, err)
+		}
+		return notePaths(notes)
+	}
+
+	if got := search("zebra"); len(got) != 0 {
+		t.Fatalf("Search() = %v, want none", got)
+	}
+
+	// Through the vault
+	if err := v.Update(ctx, "note1.md", "A zebra appears"); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err := v.Create(ctx, "new.md", "Another zebra"); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if got := search("zebra"); !reflect.DeepEqual(got, []string{"new.md", "note1.md"}) {
+		t.Errorf("Search() after writes = %v", got)
+	}
+
+	// Behind the server's back
+	fullPath := filepath.Join(tmpDir, "note2.md")
+	if err := os.WriteFile(fullPath, []byte("zebra crossing"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(fullPath, future, future); err != nil {
+		t.Fatalf("Failed to set mtime: %v", err)
+	}
+	if got := search("zebra"); !reflect.DeepEqual(got, []string{"new.md", "note1.md", "note2.md"}) {
+		t.Errorf("Search() after external edit = %v", got)
+	}
+
+	if err := os.Remove(filepath.Join(tmpDir, "new.md")); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+	if got := search("zebra"); !reflect.DeepEqual(got, []string{"note1.md", "note2.md"}) {
+		t.Errorf("Search() after delete = %v", got)
+	}
+}
+
+func TestSaveIndex(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"Work/plan.md":  "A zebra plan",
+		"Work/notes.md": "Nothing here",
+		"gone.md":       "zebra",
+		"changed.md":    "zebra",
+	})
+	ctx := context.Background()
+
+	v, err := NewVault(tmpDir, WithSearchIndex())
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	if _, err := v.List(ctx, ListOptions{Recursive: true}); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	stats, err := v.SaveIndex(ctx)
+	if err != nil || stats.Notes != 4 {
+		t.Fatalf("SaveIndex() = %+v, %v, want 4 notes", stats, err)
+	}
+
+	// Deleted and changed notes are left out when the index is loaded
+	if err := os.Remove(filepath.Join(tmpDir, "gone.md")); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(tmpDir, "changed.md"), future, future); err != nil {
+		t.Fatalf("Failed to set mtime: %v", err)
+	}
+	loaded, err := NewVault(tmpDir, WithSearchIndex())
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	idx := loaded.(*vault).index
+	if got := idx.stats().Notes; got != 2 {
+		t.Errorf("Loaded %d notes, want the 2 unchanged", got)
+	}
+	skip := idx.skipper(indexQuery{words: []string{"zebra"}})
+	for path, want := range map[string]bool{"Work/plan.md": false, "Work/notes.md": true, "changed.md": false} {
+		fullPath := filepath.Join(tmpDir, filepath.FromSlash(path))
+		info, err := os.Stat(fullPath)
+		if err != nil {
+			t.Fatalf("Failed to stat %s: %v", path, err)
+		}
+		if got := skip(noteFile{fullPath: fullPath, info: info}); got != want {
+			t.Errorf("skip(%s) = %v, want %v", path, got, want)
+		}
+	}
+
+	plain, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	if _, err := plain.SaveIndex(ctx); !errors.Is(err, ErrIndexDisabled) {
+		t.Errorf("SaveIndex() without an index error = %v, want ErrIndexDisabled", err)
+	}
+}
+
+func TestSearchIndexBounded(t *testing.T) {
+	idx := newSearchIndex(10)
+	now := time.Now()
+
+	for i := range 5 {
+		idx.add(fmt.Sprintf("/n%d.md", i), now, "", "one two three four", nil)
+	}
+	if idx.size > 10 || len(idx.docs) != 2 {
+		t.Errorf("size = %d with %d docs, want at most 10 postings in 2 docs", idx.size, len(idx.docs))
+	}
+	if idx.has("/n0.md", now) || !idx.has("/n4.md", now) {
+		t.Error("Expected the oldest notes to be dropped first")
+	}
+
+	// A single note larger than the bound is still indexed
+	idx.add("/big.md", now, "", "a b c d e f g h i j k l", nil)
+	if !idx.has("/big.md", now) || len(idx.docs) != 1 {
+		t.Errorf("docs = %d, want only the large note", len(idx.docs))
+	}
+
+	idx.remove("/big.md")
+	if idx.size != 0 || len(idx.postings) != 0 || idx.order.Len() != 0 {
+		t.Errorf("Index not empty after remove: size %d, %d terms", idx.size, len(idx.postings))
+	}
+}
+
+func TestSearchIndexConcurrent(t *testing.T) {
+	tmpDir := generateVault(t, 100)
+	ctx := context.Background()
+
+	v, err := NewVault(tmpDir, WithSearchIndex(), WithCacheSize(4<<10))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := range 4 {
+		wg.Go(func() {
+			for j := range 20 {
+				path := fmt.Sprintf("folder%d/sub%d/note%04d.md", j%7, j%3, j)
+				if err := v.Update(ctx, path, fmt.Sprintf("writer %d pass %d #tag%d", i, j, j%5)); err != nil {
+					t.Errorf("Update() error = %v", err)
+				}
+			}
+		})
+		wg.Go(func() {
+			for range 20 {
+				if _, err := v.Search(ctx, SearchOptions{Query: "writer", TagsAny: []string{"tag1"}}); err != nil {
+					t.Errorf("Search() error = %v", err)
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	// Once writes settle, the index agrees with a full scan
+	scan, _ := NewVault(tmpDir)
+	for _, q := range []string{"writer", "pass 3", "topic2"} {
+		want, _ := scan.Search(ctx, SearchOptions{Query: q})
+		got, _ := v.Search(ctx, SearchOptions{Query: q})
+		if !reflect.DeepEqual(notePaths(got), notePaths(want)) {
+			t.Errorf("Search(%q) = %v, want %v", q, notePaths(got), notePaths(want))
+		}
+	}
+}
+
+func BenchmarkSearchIndex(b *testing.B) {
+	tmpDir := generateVault(b, 2000)
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		name string
+		opts []Option
+	}{
+		{"scan", nil},
+		{"index", []Option{WithSearchIndex()}},
+	} {
+		v, err := NewVault(tmpDir, tt.opts...)
+		if err != nil {
+			b.Fatalf("Failed to create vault: %v", err)
+		}
+		// Warm the cache and index
+		if _, err := v.Search(ctx, SearchOptions{Query: "topic7"}); err != nil {
+			b.Fatalf("Search() error = %v", err)
+		}
+
+		b.Run(tt.name, func(b *testing.B) {
+			for b.Loop() {
+				if _, err := v.Search(ctx, SearchOptions{Query: "topic7"}); err != nil {
+					b.Fatalf("Search() error = %v", err)
+				}
+			}
+		})
+	}
+}
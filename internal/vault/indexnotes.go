@@ -0,0 +1,97 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// DefaultIndexNotes are the file names of the note describing its folder,
+// tried in order
+var DefaultIndexNotes = []string{"_index.md", "README.md", "index.md"}
+
+// MaxIndexNoteBytes is the size beyond which FolderIndex returns an excerpt
+// of an index note instead of its content
+const MaxIndexNoteBytes = 16 << 10
+
+// WithIndexNotes sets the file names of the note describing its folder,
+// tried in order so the first found wins; none turns index notes off
+func WithIndexNotes(names ...string) Option {
+	return func(v *vault) {
+		v.indexNotes = names
+	}
+}
+
+// IndexNote is the note describing a folder, see WithIndexNotes
+type IndexNote struct {
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`              // File size in bytes
+	Content string `json:"content,omitempty"` // Whole note, when asked for and at most MaxIndexNoteBytes
+	Excerpt string `json:"excerpt,omitempty"` // Start of the note otherwise
+}
+
+// indexNote returns the full path and file info of the index note directly
+// in the directory dir, or "" when it has none
+func (v *vault) indexNote(dir string) (string, os.FileInfo) {
+	for _, name := range v.indexNotes {
+		fullPath := filepath.Join(dir, name)
+		info, err := os.Stat(fullPath)
+		if err != nil || !info.Mode().IsRegular() || v.isIgnored(fullPath, false) {
+			continue
+		}
+		return fullPath, info
+	}
+	return "", nil
+}
+
+// FolderIndex returns the index note directly in folder, nil when it has
+// none. With full a note of at most MaxIndexNoteBytes comes whole; larger
+// notes, and every note without full, come as an excerpt.
+func (v *vault) FolderIndex(ctx context.Context, folder string, full bool) (*IndexNote, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	dir, err := v.validateDir(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	fullPath, info := v.indexNote(dir)
+	if fullPath == "" {
+		return nil, nil
+	}
+	entry, err := v.loadEntry(fullPath, info.ModTime())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index note %s: %w", v.relPath(fullPath), err)
+	}
+
+	note := &IndexNote{Path: v.relPath(fullPath), Size: info.Size()}
+	if full && info.Size() <= MaxIndexNoteBytes {
+		note.Content = entry.Content
+	} else {
+		note.Excerpt = excerpt(entry.Content, DefaultPreviewLength)
+	}
+	return note, nil
+}
+
+// markIndexNotes sets IsIndex on the notes that are their folder's index
+// note
+func (v *vault) markIndexNotes(notes []NoteInfo) {
+	if len(v.indexNotes) == 0 {
+		return
+	}
+	indexes := make(map[string]string) // Index note by folder, "" for none
+	for i, note := range notes {
+		dir := path.Dir(note.Path)
+		index, ok := indexes[dir]
+		if !ok {
+			if fullPath, _ := v.indexNote(filepath.Join(v.basePath, filepath.FromSlash(dir))); fullPath != "" {
+				index = v.relPath(fullPath)
+			}
+			indexes[dir] = index
+		}
+		notes[i].IsIndex = note.Path == index
+	}
+}
@@ -0,0 +1,81 @@
+package vault
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestFolderIndex(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"Projects/Home.md":        "# Projects\n\nWhat we work on.",
+		"Projects/README.md":      "Also an index",
+		"Projects/plan.md":        "Plan",
+		"Archive/README.md":       "# Archive\n\nOld work.\n\n" + strings.Repeat("word ", MaxIndexNoteBytes/5),
+		"Archive/2019/README.md":  "# 2019",
+		"Inbox/Home.md/child.md":  "A folder named like an index note",
+		"Inbox/unsorted.md":       "Unsorted",
+		"Templates/README.md.bak": "Not a note",
+	})
+	v, err := NewVault(tmpDir, WithIndexNotes("Home.md", "README.md"))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+
+	tests := []struct {
+		folder      string
+		full        bool
+		wantPath    string
+		wantContent string
+		wantExcerpt string
+	}{
+		{"Projects", true, "Projects/Home.md", "# Projects\n\nWhat we work on.", ""},
+		{"Projects", false, "Projects/Home.md", "", "# Projects\nWhat we work on."},
+		{"Archive", true, "Archive/README.md", "", "# Archive\nOld work."},
+		{"Inbox", true, "", "", ""},
+		{"", true, "", "", ""},
+	}
+	for _, tt := range tests {
+		index, err := v.FolderIndex(ctx, tt.folder, tt.full)
+		if err != nil {
+			t.Fatalf("FolderIndex(%q) error = %v", tt.folder, err)
+		}
+		if tt.wantPath == "" {
+			if index != nil {
+				t.Errorf("FolderIndex(%q) = %+v, want none", tt.folder, index)
+			}
+			continue
+		}
+		if index == nil || index.Path != tt.wantPath || index.Content != tt.wantContent || index.Excerpt != tt.wantExcerpt {
+			t.Errorf("FolderIndex(%q, %v) = %+v, want %s with content %q and excerpt %q", tt.folder, tt.full, index, tt.wantPath, tt.wantContent, tt.wantExcerpt)
+		}
+	}
+	if _, err := v.FolderIndex(ctx, "Nowhere", true); err == nil {
+		t.Error("FolderIndex() of a missing folder succeeded")
+	}
+
+	// Listings mark each folder's index note only when asked to
+	notes, err := v.List(ctx, ListOptions{Recursive: true, MarkIndexNotes: true})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	var marked []string
+	for _, note := range notes {
+		if note.IsIndex {
+			marked = append(marked, note.Path)
+		}
+	}
+	if want := "Archive/2019/README.md Archive/README.md Projects/Home.md"; strings.Join(marked, " ") != want {
+		t.Errorf("List() marked %v, want %s", marked, want)
+	}
+
+	noIndexes, err := NewVault(tmpDir, WithIndexNotes())
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	if index, err := noIndexes.FolderIndex(ctx, "Projects", true); err != nil || index != nil {
+		t.Errorf("FolderIndex() without index note names = %+v, %v; want none", index, err)
+	}
+}
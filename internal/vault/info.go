@@ -0,0 +1,125 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxCountedNotes bounds the walk counting notes for Info
+const maxCountedNotes = 100_000
+
+// errCountLimit stops the counting walk once maxCountedNotes is reached
+var errCountLimit = errors.New("note count limit reached")
+
+// VaultFeatures reports which optional vault behaviors are enabled
+type VaultFeatures struct {
+	BackupVersions int          `json:"backup_versions"` // Versions kept per note, 0 when backups are off
+	FollowSymlinks bool         `json:"follow_symlinks"`
+	IncludeHidden  bool         `json:"include_hidden"`
+	SearchIndex    bool         `json:"search_index"`
+	CacheMaxBytes  int64        `json:"cache_max_bytes"`           // 0 for an unbounded cache
+	SourceEncoding string       `json:"source_encoding,omitempty"` // Encoding of non-UTF-8 notes
+	TitleFrom      TitleSources `json:"title_from"`                // Where note titles come from, in order
+	Root           string       `json:"root,omitempty"`            // Folder of the vault directory the vault is limited to
+	ReadOnlyPaths  []string     `json:"read_only_paths,omitempty"`
+	WritablePaths  []string     `json:"writable_paths,omitempty"`
+	WriteLimits    *WriteLimits `json:"write_limits,omitempty"` // Set by NewRateLimitedVault
+	BatchLimits    BatchLimits  `json:"batch_limits"`
+	WarmCache      int          `json:"warm_cache,omitempty"`   // Notes loaded in parallel by the warm-up, 0 when off
+	StrictAudit    bool         `json:"strict_audit,omitempty"` // Writes fail when they cannot be audited
+
+	AttachmentFolder string            `json:"attachment_folder,omitempty"` // Where bare attachment names are looked up
+	Obsidian         *ObsidianSettings `json:"obsidian,omitempty"`          // Settings read from .obsidian
+
+	FrontmatterTemplate *FrontmatterTemplate `json:"frontmatter_template,omitempty"` // Added to created notes without frontmatter
+	FrontmatterSchema   FrontmatterSchema    `json:"frontmatter_schema,omitempty"`   // Rules written frontmatter must follow
+}
+
+// VaultInfo describes the vault a server is pointed at
+type VaultInfo struct {
+	Name            string        `json:"name"`                        // Base directory name, never the full path
+	NoteCount       int           `json:"note_count"`                  // Visible notes in the vault
+	NoteCountCapped bool          `json:"note_count_capped,omitempty"` // Counting stopped at the limit
+	Features        VaultFeatures `json:"features"`
+	Cache           CacheStats    `json:"cache"`
+	Index           *IndexStats   `json:"index,omitempty"`  // Set when the search index is enabled
+	Warmup          *WarmupStatus `json:"warmup,omitempty"` // Set once the cache warm-up started
+}
+
+// Info returns the vault name, note count, enabled features and cache
+// usage. Notes are counted by a walk that reads no file content and stops
+// after 100000 notes.
+func (v *vault) Info(ctx context.Context) (VaultInfo, error) {
+	info := VaultInfo{
+		Name: filepath.Base(v.vaultPath),
+		Features: VaultFeatures{
+			BackupVersions: v.backupVersions,
+			FollowSymlinks: v.followSymlinks,
+			IncludeHidden:  v.includeHidden,
+			SearchIndex:    v.index != nil,
+			CacheMaxBytes:  v.cacheMaxBytes,
+			SourceEncoding: v.sourceEncodingName,
+			TitleFrom:      v.titleSources,
+			Root:           v.root,
+			ReadOnlyPaths:  v.readOnlyPaths,
+			WritablePaths:  v.writablePaths,
+			BatchLimits:    v.batchLimits,
+			WarmCache:      v.warmup.concurrency,
+			StrictAudit:    v.audit.strict,
+
+			AttachmentFolder: v.effectiveAttachmentFolder(),
+			Obsidian:         v.obsidian,
+
+			FrontmatterTemplate: v.template,
+			FrontmatterSchema:   v.schema,
+		},
+		Cache:  v.cache.CacheStats(),
+		Warmup: v.warmupStatus(),
+	}
+	if v.index != nil {
+		stats := v.index.stats()
+		info.Index = &stats
+	}
+
+	walkFn := func(path string, fi os.FileInfo, err error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err != nil {
+			return nil // Skip inaccessible files and directories
+		}
+
+		if path != v.basePath && !v.includeHidden && isHidden(path) {
+			if fi.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if fi.IsDir() || !strings.HasSuffix(path, ".md") {
+			return nil
+		}
+
+		info.NoteCount++
+		if info.NoteCount >= maxCountedNotes {
+			return errCountLimit
+		}
+		return nil
+	}
+
+	if err := v.walk(v.basePath, walkFn); err != nil {
+		if !errors.Is(err, errCountLimit) {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return VaultInfo{}, ctxErr
+			}
+			return VaultInfo{}, fmt.Errorf("failed to walk directory: %w", err)
+		}
+		info.NoteCountCapped = true
+	}
+
+	return info, nil
+}
@@ -0,0 +1,78 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInfo(t *testing.T) {
+	ctx := context.Background()
+	setup, _ := setupTestVault(t)
+	base := setup.(*vault).basePath
+
+	v, err := NewVault(base, WithCacheSize(1<<20), WithReadOnlyPaths("other"))
+	if err != nil {
+		t.Fatalf("NewVault() error = %v", err)
+	}
+	if _, err := v.Read(ctx, "note1.md"); err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	info, err := v.Info(ctx)
+	if err != nil {
+		t.Fatalf("Info() error = %v", err)
+	}
+
+	if info.Name != filepath.Base(base) {
+		t.Errorf("Name = %q, want %q", info.Name, filepath.Base(base))
+	}
+	// note1, note2, note3, note4, note5; the hidden note is not counted
+	if info.NoteCount != 5 || info.NoteCountCapped {
+		t.Errorf("NoteCount = %d (capped %v), want 5", info.NoteCount, info.NoteCountCapped)
+	}
+	if info.Features.CacheMaxBytes != 1<<20 || info.Features.BackupVersions != defaultBackupVersions {
+		t.Errorf("Features = %+v", info.Features)
+	}
+	if len(info.Features.ReadOnlyPaths) != 1 || info.Features.WriteLimits != nil {
+		t.Errorf("Features = %+v", info.Features)
+	}
+	if info.Cache.Entries != 1 {
+		t.Errorf("Cache.Entries = %d, want 1", info.Cache.Entries)
+	}
+	if info.Index != nil {
+		t.Errorf("Index = %+v, want nil without the search index", info.Index)
+	}
+
+	// The absolute vault location never leaks
+	data, _ := json.Marshal(info)
+	if strings.Contains(string(data), base) {
+		t.Errorf("Info JSON contains an absolute path: %s", data)
+	}
+
+	limited := NewRateLimitedVault(v, WriteLimits{PerMinute: 5})
+	info, err = limited.Info(ctx)
+	if err != nil {
+		t.Fatalf("Info() error = %v", err)
+	}
+	if info.Features.WriteLimits == nil || info.Features.WriteLimits.PerMinute != 5 {
+		t.Errorf("WriteLimits = %+v, want PerMinute 5", info.Features.WriteLimits)
+	}
+
+	indexed, err := NewVault(base, WithSearchIndex())
+	if err != nil {
+		t.Fatalf("NewVault() error = %v", err)
+	}
+	if _, err := indexed.List(ctx, ListOptions{Recursive: true}); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	info, err = indexed.Info(ctx)
+	if err != nil {
+		t.Fatalf("Info() error = %v", err)
+	}
+	if info.Index == nil || info.Index.Notes != 5 || info.Index.Terms == 0 || info.Index.Postings < info.Index.Terms {
+		t.Errorf("Index = %+v, want 5 notes with terms", info.Index)
+	}
+}
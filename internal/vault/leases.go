@@ -0,0 +1,396 @@
+package vault
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// locksDir holds one file per leased note, below the data directory
+const locksDir = "locks"
+
+// Lease durations
+const (
+	DefaultLockTTL = 15 * time.Minute // Used when LockOptions.TTL is 0
+	MaxLockTTL     = 24 * time.Hour   // Longest lease LockNote grants
+)
+
+// leaseRetries bounds the attempts to take over a lease another process
+// is replacing at the same time
+const leaseRetries = 3
+
+// Lease is an advisory lock on a note, held by one client until it
+// expires
+type Lease struct {
+	Path     string    `json:"path"`              // Vault-relative path of the note
+	Holder   string    `json:"holder"`            // Client holding the lease
+	Purpose  string    `json:"purpose,omitempty"` // What the holder is doing
+	Acquired time.Time `json:"acquired"`
+	Expires  time.Time `json:"expires"`
+}
+
+// live reports whether the lease has not expired at now
+func (l Lease) live(now time.Time) bool {
+	return now.Before(l.Expires)
+}
+
+// LockOptions describes the lease LockNote takes
+type LockOptions struct {
+	Path    string        // Note to lease
+	Purpose string        // What the holder is doing, shown to other clients
+	TTL     time.Duration // Lease duration, the vault's default if 0, at most MaxLockTTL
+	Force   bool          // Take over a lease another holder has not released
+}
+
+// WithLockTTL sets how long leases last when LockOptions.TTL is 0
+// Values of 0 or less keep DefaultLockTTL
+func WithLockTTL(ttl time.Duration) Option {
+	return func(v *vault) {
+		if ttl > 0 {
+			v.lockTTL = min(ttl, MaxLockTTL)
+		}
+	}
+}
+
+// lockClaim identifies the client behind a call
+type lockClaim struct {
+	holder string
+	force  bool // Write even to notes leased to another holder
+}
+
+// lockClaimKey is the context key of the lockClaim
+type lockClaimKey struct{}
+
+// HolderContext returns a context whose calls are made by holder: they
+// hold the leases LockNote takes, and cannot write notes leased to
+// another holder unless force is set. Calls without a holder write only
+// notes nobody leases.
+func HolderContext(ctx context.Context, holder string, force bool) context.Context {
+	return context.WithValue(ctx, lockClaimKey{}, lockClaim{holder: holder, force: force})
+}
+
+// claimFrom returns the lockClaim of ctx, the zero value if it has none
+func claimFrom(ctx context.Context) lockClaim {
+	claim, _ := ctx.Value(lockClaimKey{}).(lockClaim)
+	return claim
+}
+
+// LockNote leases a note to the holder of ctx, or renews the lease it
+// already holds. A live lease of another holder fails with a
+// *LockedError unless opts.Force is set.
+func (v *vault) LockNote(ctx context.Context, opts LockOptions) (Lease, error) {
+	claim := claimFrom(ctx)
+	if claim.holder == "" {
+		return Lease{}, errors.New("no holder to lease the note to")
+	}
+	fullPath, err := v.validatePath(opts.Path)
+	if err != nil {
+		return Lease{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return Lease{}, err
+	}
+	if _, err := statNote(fullPath, opts.Path); err != nil {
+		return Lease{}, err
+	}
+
+	ttl := opts.TTL
+	if ttl <= 0 {
+		ttl = v.lockTTL
+	}
+	now := time.Now().UTC()
+	lease := Lease{
+		Path:     v.relPath(fullPath),
+		Holder:   claim.holder,
+		Purpose:  opts.Purpose,
+		Acquired: now,
+		Expires:  now.Add(min(ttl, MaxLockTTL)),
+	}
+	return v.leases.acquire(lease, opts.Force || claim.force)
+}
+
+// UnlockNote releases the lease the holder of ctx has on a note,
+// reporting false when the note was not leased. A lease of another
+// holder fails with a *LockedError unless force is set.
+func (v *vault) UnlockNote(ctx context.Context, path string, force bool) (bool, error) {
+	fullPath, err := v.validatePath(path)
+	if err != nil {
+		return false, err
+	}
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	claim := claimFrom(ctx)
+	return v.leases.release(v.relPath(fullPath), claim.holder, force || claim.force)
+}
+
+// checkLeases fails with a *LockedError if a note at one of fullPaths is
+// leased to another holder than the one of ctx; empty paths are skipped
+func (v *vault) checkLeases(ctx context.Context, fullPaths ...string) error {
+	claim := claimFrom(ctx)
+	if claim.force {
+		return nil
+	}
+	for _, fullPath := range fullPaths {
+		if fullPath == "" {
+			continue
+		}
+		lease, ok, err := v.leases.current(v.relPath(fullPath))
+		if err != nil {
+			return err
+		}
+		if ok && lease.Holder != claim.holder {
+			return &LockedError{Lease: lease}
+		}
+	}
+	return nil
+}
+
+// moveLeases re-keys the leases of notes moved from from to to, a note
+// or a folder, logging failures: the move itself has already happened
+func (v *vault) moveLeases(from, to string) {
+	if err := v.leases.rename(from, to); err != nil {
+		v.logger.Warn("moving leases failed", "path", from, "new_path", to, "error", err)
+	}
+}
+
+// dropLease removes the lease of a note that no longer exists
+func (v *vault) dropLease(path string) {
+	if err := v.leases.remove(path); err != nil {
+		v.logger.Warn("removing lease failed", "path", path, "error", err)
+	}
+}
+
+// leaseStore keeps one JSON file per leased note in dir. Files are
+// created with O_EXCL, so server processes sharing the vault never both
+// take a lease; expired ones are removed when next looked at.
+// The zero value with dir set is ready to use
+type leaseStore struct {
+	mu  sync.Mutex // Serializes this process's changes
+	dir string
+}
+
+// file returns the lease file of a note
+// Names are hashed from the lowercased path, so notes differing only in
+// case, which are the same file on macOS and Windows, share a lease
+func (s *leaseStore) file(path string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(norm.NFC.String(filepath.ToSlash(path)))))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:16])+".json")
+}
+
+// current returns the live lease of a note, removing an expired one
+func (s *leaseStore) current(path string) (Lease, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lease, ok, err := s.read(s.file(path))
+	if err != nil || !ok {
+		return Lease{}, false, err
+	}
+	if !lease.live(time.Now()) {
+		s.removeIf(s.file(path), lease)
+		return Lease{}, false, nil
+	}
+	return lease, true, nil
+}
+
+// acquire stores lease unless another holder has a live lease on the
+// note and force is not set. The holder's own lease is renewed, keeping
+// when it was first acquired.
+func (s *leaseStore) acquire(lease Lease, force bool) (Lease, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file := s.file(lease.Path)
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return Lease{}, fmt.Errorf("failed to create locks directory: %w", err)
+	}
+	for range leaseRetries {
+		err := s.create(file, lease)
+		if err == nil {
+			return lease, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return Lease{}, err
+		}
+
+		existing, ok, err := s.read(file)
+		if err != nil {
+			return Lease{}, err
+		}
+		if !ok {
+			continue // Released meanwhile
+		}
+		live := existing.live(time.Now())
+		switch {
+		case live && existing.Holder == lease.Holder:
+			lease.Acquired = existing.Acquired
+			if err := s.replace(file, lease); err != nil {
+				return Lease{}, err
+			}
+			return lease, nil
+		case live && !force:
+			return Lease{}, &LockedError{Lease: existing}
+		}
+		s.removeIf(file, existing)
+	}
+	return Lease{}, fmt.Errorf("failed to lease %s: another process keeps replacing its lease", lease.Path)
+}
+
+// release removes the lease on a note if holder holds it, or if force is
+// set, reporting whether there was a live lease
+func (s *leaseStore) release(path, holder string, force bool) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file := s.file(path)
+	lease, ok, err := s.read(file)
+	if err != nil || !ok {
+		return false, err
+	}
+	if !lease.live(time.Now()) {
+		s.removeIf(file, lease)
+		return false, nil
+	}
+	if lease.Holder != holder && !force {
+		return false, &LockedError{Lease: lease}
+	}
+	s.removeIf(file, lease)
+	return true, nil
+}
+
+// rename moves the leases of notes at or under from to to
+func (s *leaseStore) rename(from, to string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list leases: %w", err)
+	}
+	var errs []error
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		file := filepath.Join(s.dir, entry.Name())
+		lease, ok, err := s.read(file)
+		if err != nil || !ok {
+			continue
+		}
+		newPath, moved := movedPath(lease.Path, from, to)
+		if !moved || !lease.live(time.Now()) {
+			continue
+		}
+		lease.Path = newPath
+		if err := s.replace(s.file(newPath), lease); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		os.Remove(file)
+	}
+	return errors.Join(errs...)
+}
+
+// remove drops the lease on a note, whoever holds it
+func (s *leaseStore) remove(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.file(path)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove lease: %w", err)
+	}
+	return nil
+}
+
+// create writes a lease file that must not exist yet
+// Caller must hold s.mu
+func (s *leaseStore) create(file string, lease Lease) error {
+	raw, err := json.Marshal(lease)
+	if err != nil {
+		return fmt.Errorf("failed to encode lease: %w", err)
+	}
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return err
+		}
+		return fmt.Errorf("failed to create lease: %w", err)
+	}
+	_, err = f.Write(raw)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file)
+		return fmt.Errorf("failed to write lease: %w", err)
+	}
+	return nil
+}
+
+// replace overwrites a lease file atomically
+// Caller must hold s.mu
+func (s *leaseStore) replace(file string, lease Lease) error {
+	raw, err := json.Marshal(lease)
+	if err != nil {
+		return fmt.Errorf("failed to encode lease: %w", err)
+	}
+	if err := writeDataFile(file, raw); err != nil {
+		return fmt.Errorf("failed to write lease: %w", err)
+	}
+	return nil
+}
+
+// read returns the lease in file, reporting false when there is none
+// Another process may have created the file without writing it yet, so
+// unreadable content is read again before the file is taken as stale
+// Caller must hold s.mu
+func (s *leaseStore) read(file string) (Lease, bool, error) {
+	for attempt := 0; ; attempt++ {
+		raw, err := os.ReadFile(file)
+		if errors.Is(err, fs.ErrNotExist) {
+			return Lease{}, false, nil
+		}
+		if err != nil {
+			return Lease{}, false, fmt.Errorf("failed to read lease: %w", err)
+		}
+		var lease Lease
+		if err := json.Unmarshal(raw, &lease); err == nil {
+			return lease, true, nil
+		}
+		if attempt == leaseRetries {
+			return Lease{}, true, nil // Expired zero lease: taken over or removed
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// removeIf removes file if it still holds lease. The file is renamed
+// aside first, so a lease another process created in its place
+// meanwhile is put back rather than lost.
+// Caller must hold s.mu
+func (s *leaseStore) removeIf(file string, lease Lease) {
+	aside := fmt.Sprintf("%s.%d.%d.old", file, os.Getpid(), time.Now().UnixNano())
+	if err := os.Rename(file, aside); err != nil {
+		return
+	}
+	defer os.Remove(aside)
+
+	if moved, ok, _ := s.read(aside); ok && (moved.Holder != lease.Holder || !moved.Expires.Equal(lease.Expires)) {
+		os.Link(aside, file) // Fails if yet another lease was created
+	}
+}
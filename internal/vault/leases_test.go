@@ -0,0 +1,200 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLockNote(t *testing.T) {
+	v, tmpDir := setupTestVault(t)
+	alice := HolderContext(context.Background(), "alice", false)
+	bob := HolderContext(context.Background(), "bob", false)
+
+	lease, err := v.LockNote(alice, LockOptions{Path: "note1.md", Purpose: "rewriting", TTL: time.Minute})
+	if err != nil {
+		t.Fatalf("LockNote() error = %v", err)
+	}
+	if lease.Holder != "alice" || lease.Path != "note1.md" || lease.Purpose != "rewriting" {
+		t.Errorf("LockNote() = %+v", lease)
+	}
+	if d := time.Until(lease.Expires); d <= 0 || d > time.Minute {
+		t.Errorf("lease expires in %v, want within a minute", d)
+	}
+
+	// Renewing keeps when the lease was acquired
+	renewed, err := v.LockNote(alice, LockOptions{Path: "note1.md", TTL: time.Hour})
+	if err != nil {
+		t.Fatalf("LockNote() renew error = %v", err)
+	}
+	if !renewed.Acquired.Equal(lease.Acquired) || !renewed.Expires.After(lease.Expires) {
+		t.Errorf("renewed lease = %+v, want acquired %v and a later expiry", renewed, lease.Acquired)
+	}
+
+	// Another holder can neither lock nor write
+	_, err = v.LockNote(bob, LockOptions{Path: "note1.md"})
+	var lockedErr *LockedError
+	if !errors.As(err, &lockedErr) || lockedErr.Lease.Holder != "alice" {
+		t.Fatalf("LockNote() by bob error = %v, want a LockedError naming alice", err)
+	}
+	if err := v.Update(bob, "note1.md", "bob was here"); !errors.Is(err, ErrLocked) {
+		t.Errorf("Update() by bob error = %v, want ErrLocked", err)
+	}
+	if err := v.Update(context.Background(), "note1.md", "anonymous"); !errors.Is(err, ErrLocked) {
+		t.Errorf("Update() without holder error = %v, want ErrLocked", err)
+	}
+	if _, err := v.ValidateUpdate(bob, "note1.md"); !errors.Is(err, ErrLocked) {
+		t.Errorf("ValidateUpdate() by bob error = %v, want ErrLocked", err)
+	}
+	if err := v.Update(alice, "note1.md", "alice was here"); err != nil {
+		t.Errorf("Update() by alice error = %v", err)
+	}
+	if err := v.Update(HolderContext(context.Background(), "bob", true), "note1.md", "forced"); err != nil {
+		t.Errorf("forced Update() error = %v", err)
+	}
+
+	// Notes differing only in case share a lease
+	if err := v.Update(bob, "NOTE1.md", "x"); !errors.Is(err, ErrLocked) {
+		t.Errorf("Update() by bob of NOTE1.md error = %v, want ErrLocked", err)
+	}
+
+	// Unlocking
+	if _, err := v.UnlockNote(bob, "note1.md", false); !errors.Is(err, ErrLocked) {
+		t.Errorf("UnlockNote() by bob error = %v, want ErrLocked", err)
+	}
+	if released, err := v.UnlockNote(alice, "note1.md", false); err != nil || !released {
+		t.Errorf("UnlockNote() by alice = %v, %v, want true", released, err)
+	}
+	if released, err := v.UnlockNote(alice, "note1.md", false); err != nil || released {
+		t.Errorf("UnlockNote() again = %v, %v, want false", released, err)
+	}
+	if err := v.Update(bob, "note1.md", "bob again"); err != nil {
+		t.Errorf("Update() by bob after unlock error = %v", err)
+	}
+
+	// Force takes over the lease
+	if _, err := v.LockNote(alice, LockOptions{Path: "note2.md"}); err != nil {
+		t.Fatalf("LockNote() error = %v", err)
+	}
+	if lease, err := v.LockNote(bob, LockOptions{Path: "note2.md", Force: true}); err != nil || lease.Holder != "bob" {
+		t.Errorf("forced LockNote() = %+v, %v, want bob's lease", lease, err)
+	}
+
+	if _, err := v.LockNote(alice, LockOptions{Path: "missing.md"}); !errors.Is(err, ErrNoteNotFound) {
+		t.Errorf("LockNote() on a missing note error = %v, want ErrNoteNotFound", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, dataDir, locksDir)); err != nil {
+		t.Errorf("locks directory missing: %v", err)
+	}
+}
+
+func TestLockNoteExpires(t *testing.T) {
+	v, tmpDir := setupTestVault(t)
+	alice := HolderContext(context.Background(), "alice", false)
+	bob := HolderContext(context.Background(), "bob", false)
+
+	if _, err := v.LockNote(alice, LockOptions{Path: "note1.md", TTL: time.Millisecond}); err != nil {
+		t.Fatalf("LockNote() error = %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	// The expired lease is removed by the next write
+	if err := v.Update(bob, "note1.md", "bob"); err != nil {
+		t.Fatalf("Update() after expiry error = %v", err)
+	}
+	entries, err := os.ReadDir(filepath.Join(tmpDir, dataDir, locksDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("lease files left after expiry: %d", len(entries))
+	}
+}
+
+func TestLockNoteEnforced(t *testing.T) {
+	v, _ := setupTestVault(t)
+	alice := HolderContext(context.Background(), "alice", false)
+	bob := HolderContext(context.Background(), "bob", false)
+	if _, err := v.LockNote(alice, LockOptions{Path: "subdir/note3.md"}); err != nil {
+		t.Fatalf("LockNote() error = %v", err)
+	}
+
+	for _, op := range []Edit{
+		{Op: EditUpdate, Path: "subdir/note3.md", Content: "x"},
+		{Op: EditAppend, Path: "subdir/note3.md", Content: "x"},
+		{Op: EditDelete, Path: "subdir/note3.md"},
+		{Op: EditMove, Path: "subdir/note3.md", NewPath: "moved.md"},
+	} {
+		if _, err := v.ApplyEdits(bob, BatchOptions{Edits: []Edit{op}}); !errors.Is(err, ErrLocked) {
+			t.Errorf("ApplyEdits(%s) by bob error = %v, want ErrLocked", op.Op, err)
+		}
+	}
+	if _, err := v.MergeNotes(bob, MergeOptions{Source: "note1.md", Target: "subdir/note3.md"}); !errors.Is(err, ErrLocked) {
+		t.Errorf("MergeNotes() by bob error = %v, want ErrLocked", err)
+	}
+	if _, err := v.RenameFolder(bob, RenameFolderOptions{Path: "subdir", NewPath: "renamed"}); !errors.Is(err, ErrLocked) {
+		t.Errorf("RenameFolder() by bob error = %v, want ErrLocked", err)
+	}
+	result, err := v.ReplaceInNotes(bob, ReplaceOptions{Pattern: "note", Replacement: "memo", Path: "subdir"})
+	if err != nil {
+		t.Fatalf("ReplaceInNotes() error = %v", err)
+	}
+	for _, file := range result.Files {
+		locked := file.Path == "subdir/note3.md"
+		if locked != errors.Is(file.Err, ErrLocked) || locked != (file.Status == ReplaceSkipped) {
+			t.Errorf("ReplaceInNotes() %s = %s, %v", file.Path, file.Status, file.Err)
+		}
+	}
+
+	// The holder's move carries the lease along, and a delete drops it
+	if _, err := v.ApplyEdits(alice, BatchOptions{Edits: []Edit{{Op: EditMove, Path: "subdir/note3.md", NewPath: "moved.md"}}}); err != nil {
+		t.Fatalf("ApplyEdits(move) by alice error = %v", err)
+	}
+	if err := v.Update(bob, "moved.md", "x"); !errors.Is(err, ErrLocked) {
+		t.Errorf("Update() by bob of the moved note error = %v, want ErrLocked", err)
+	}
+	if _, err := v.ApplyEdits(alice, BatchOptions{Edits: []Edit{{Op: EditDelete, Path: "moved.md"}}}); err != nil {
+		t.Fatalf("ApplyEdits(delete) by alice error = %v", err)
+	}
+	if err := v.Create(bob, "moved.md", "new"); err != nil {
+		t.Errorf("Create() by bob after delete error = %v", err)
+	}
+}
+
+func TestLockNoteAcrossVaults(t *testing.T) {
+	_, tmpDir := setupTestVault(t)
+
+	// Separate vaults on one directory stand in for server processes
+	const holders = 8
+	var wg sync.WaitGroup
+	errs := make([]error, holders)
+	for i := range holders {
+		v, err := NewVault(tmpDir)
+		if err != nil {
+			t.Fatalf("NewVault() error = %v", err)
+		}
+		wg.Go(func() {
+			ctx := HolderContext(context.Background(), fmt.Sprintf("client%d", i), false)
+			_, errs[i] = v.LockNote(ctx, LockOptions{Path: "note1.md"})
+		})
+	}
+	wg.Wait()
+
+	won := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			won++
+		case !errors.Is(err, ErrLocked):
+			t.Errorf("LockNote() error = %v, want ErrLocked", err)
+		}
+	}
+	if won != 1 {
+		t.Errorf("%d holders got the lease, want 1", won)
+	}
+}
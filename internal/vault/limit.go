@@ -0,0 +1,432 @@
+package vault
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// WriteLimits bounds how fast notes may be modified
+// Zero values disable the corresponding limit
+type WriteLimits struct {
+	PerMinute       int `json:"per_minute"`        // Writes per minute across the whole vault
+	FilePerMinute   int `json:"file_per_minute"`   // Writes per minute to any single note
+	FilesPerSession int `json:"files_per_session"` // Distinct notes modified over the vault's lifetime
+}
+
+// Enabled reports whether any limit is set
+func (l WriteLimits) Enabled() bool {
+	return l.PerMinute > 0 || l.FilePerMinute > 0 || l.FilesPerSession > 0
+}
+
+// Limit scopes reported by RateLimitError
+const (
+	LimitScopeVault   = "vault"
+	LimitScopeFile    = "file"
+	LimitScopeSession = "session"
+)
+
+// tokenBucket allows bursts of up to capacity operations, refilling
+// continuously at capacity per minute
+type tokenBucket struct {
+	capacity float64
+	tokens   float64
+	last     time.Time
+}
+
+func newTokenBucket(perMinute int, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		capacity: float64(perMinute),
+		tokens:   float64(perMinute),
+		last:     now,
+	}
+}
+
+// refill adds the tokens accrued since the last call
+func (b *tokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last)
+	if elapsed <= 0 {
+		return
+	}
+	b.tokens = min(b.capacity, b.tokens+elapsed.Minutes()*b.capacity)
+	b.last = now
+}
+
+// wait returns how long until a token is available, 0 if one is now
+func (b *tokenBucket) wait(now time.Time) time.Duration {
+	b.refill(now)
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.capacity * float64(time.Minute))
+}
+
+// full reports whether the bucket has refilled completely
+func (b *tokenBucket) full(now time.Time) bool {
+	b.refill(now)
+	return b.tokens >= b.capacity
+}
+
+// limitedVault wraps a Vault and throttles operations that modify notes
+// Reads, searches and dry-run validation are never throttled
+type limitedVault struct {
+	Vault
+
+	limits WriteLimits
+	now    func() time.Time
+
+	mu       sync.Mutex
+	global   *tokenBucket
+	files    map[string]*tokenBucket // Per-note buckets, dropped once full again
+	modified map[string]bool         // Notes modified this session
+}
+
+var _ Vault = (*limitedVault)(nil)
+
+// NewRateLimitedVault wraps v so Create, Update and RestoreVersion fail
+// with a RateLimitError once limits are exceeded. With no limits set v
+// is returned unchanged.
+func NewRateLimitedVault(v Vault, limits WriteLimits) Vault {
+	if !limits.Enabled() {
+		return v
+	}
+	return newLimitedVault(v, limits, time.Now)
+}
+
+// newLimitedVault creates the wrapper with an injectable clock
+func newLimitedVault(v Vault, limits WriteLimits, now func() time.Time) *limitedVault {
+	l := &limitedVault{
+		Vault:    v,
+		limits:   limits,
+		now:      now,
+		files:    make(map[string]*tokenBucket),
+		modified: make(map[string]bool),
+	}
+	if limits.PerMinute > 0 {
+		l.global = newTokenBucket(limits.PerMinute, now())
+	}
+	return l
+}
+
+// write runs fn if the limits allow a write to path
+// Failed writes still use up rate tokens so a looping caller is slowed
+// down either way, but only successful ones count against the session
+func (l *limitedVault) write(path string, fn func() error) error {
+	key := filepath.ToSlash(filepath.Clean(path))
+
+	added, err := l.acquire(key)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(); err != nil {
+		if added {
+			l.mu.Lock()
+			delete(l.modified, key)
+			l.mu.Unlock()
+		}
+		return err
+	}
+	return nil
+}
+
+// acquire reserves a write to key, checking every limit before taking
+// from any bucket so a rejected write consumes nothing
+// Reports whether key was newly added to the session's modified notes
+func (l *limitedVault) acquire(key string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	if l.limits.FilesPerSession > 0 && !l.modified[key] && len(l.modified) >= l.limits.FilesPerSession {
+		return false, &RateLimitError{Scope: LimitScopeSession, Limit: l.limits.FilesPerSession}
+	}
+
+	if l.global != nil {
+		if wait := l.global.wait(now); wait > 0 {
+			return false, &RateLimitError{Scope: LimitScopeVault, Limit: l.limits.PerMinute, RetryAfter: wait}
+		}
+	}
+
+	var file *tokenBucket
+	if l.limits.FilePerMinute > 0 {
+		// Forget notes that have not been written recently
+		for p, b := range l.files {
+			if p != key && b.full(now) {
+				delete(l.files, p)
+			}
+		}
+
+		file = l.files[key]
+		if file == nil {
+			file = newTokenBucket(l.limits.FilePerMinute, now)
+			l.files[key] = file
+		}
+		if wait := file.wait(now); wait > 0 {
+			return false, &RateLimitError{Scope: LimitScopeFile, Path: key, Limit: l.limits.FilePerMinute, RetryAfter: wait}
+		}
+	}
+
+	if l.global != nil {
+		l.global.tokens--
+	}
+	if file != nil {
+		file.tokens--
+	}
+	added := l.limits.FilesPerSession > 0 && !l.modified[key]
+	if added {
+		l.modified[key] = true
+	}
+
+	return added, nil
+}
+
+// Create creates a note if the write limits allow it
+func (l *limitedVault) Create(ctx context.Context, path, content string) error {
+	return l.write(path, func() error {
+		return l.Vault.Create(ctx, path, content)
+	})
+}
+
+// Update modifies a note if the write limits allow it
+func (l *limitedVault) Update(ctx context.Context, path, content string) error {
+	return l.write(path, func() error {
+		return l.Vault.Update(ctx, path, content)
+	})
+}
+
+// RestoreVersion restores a note version if the write limits allow it
+func (l *limitedVault) RestoreVersion(ctx context.Context, path, versionID string) error {
+	return l.write(path, func() error {
+		return l.Vault.RestoreVersion(ctx, path, versionID)
+	})
+}
+
+// CreateFolder creates a folder if the write limits allow it
+func (l *limitedVault) CreateFolder(ctx context.Context, path string) error {
+	return l.write(path, func() error {
+		return l.Vault.CreateFolder(ctx, path)
+	})
+}
+
+// RenameFolder renames a folder if the write limits allow it
+// The rename counts as one write to the folder, however many notes move
+func (l *limitedVault) RenameFolder(ctx context.Context, opts RenameFolderOptions) (FolderRename, error) {
+	var result FolderRename
+	err := l.write(opts.Path, func() error {
+		var err error
+		result, err = l.Vault.RenameFolder(ctx, opts)
+		return err
+	})
+	return result, err
+}
+
+// MoveNote moves a note if the write limits allow it
+// The move counts as one write to the note; dry runs are not limited
+func (l *limitedVault) MoveNote(ctx context.Context, opts MoveNoteOptions) (NoteMove, error) {
+	if opts.DryRun {
+		return l.Vault.MoveNote(ctx, opts)
+	}
+	var result NoteMove
+	err := l.write(opts.Path, func() error {
+		var err error
+		result, err = l.Vault.MoveNote(ctx, opts)
+		return err
+	})
+	return result, err
+}
+
+// MergeNotes merges two notes if the write limits allow it
+// The merge counts as one write to the target; dry runs are not limited
+func (l *limitedVault) MergeNotes(ctx context.Context, opts MergeOptions) (MergeResult, error) {
+	if opts.DryRun {
+		return l.Vault.MergeNotes(ctx, opts)
+	}
+	var result MergeResult
+	err := l.write(opts.Target, func() error {
+		var err error
+		result, err = l.Vault.MergeNotes(ctx, opts)
+		return err
+	})
+	return result, err
+}
+
+// SplitNote splits a note if the write limits allow it
+// The split counts as one write to the source; dry runs are not limited
+func (l *limitedVault) SplitNote(ctx context.Context, opts SplitNoteOptions) (SplitResult, error) {
+	if opts.DryRun {
+		return l.Vault.SplitNote(ctx, opts)
+	}
+	var result SplitResult
+	err := l.write(opts.Path, func() error {
+		var err error
+		result, err = l.Vault.SplitNote(ctx, opts)
+		return err
+	})
+	return result, err
+}
+
+// ApplyEdits applies a batch of edits if the write limits allow it
+// The batch counts as one write to its first note; dry runs are not limited
+func (l *limitedVault) ApplyEdits(ctx context.Context, opts BatchOptions) (BatchResult, error) {
+	if opts.DryRun || len(opts.Edits) == 0 {
+		return l.Vault.ApplyEdits(ctx, opts)
+	}
+	var result BatchResult
+	err := l.write(opts.Edits[0].Path, func() error {
+		var err error
+		result, err = l.Vault.ApplyEdits(ctx, opts)
+		return err
+	})
+	return result, err
+}
+
+// ReplaceInNotes replaces across notes if the write limits allow it
+// The call counts as one write to its path; dry runs are not limited
+func (l *limitedVault) ReplaceInNotes(ctx context.Context, opts ReplaceOptions) (ReplaceResult, error) {
+	if opts.DryRun {
+		return l.Vault.ReplaceInNotes(ctx, opts)
+	}
+	var result ReplaceResult
+	err := l.write(opts.Path, func() error {
+		var err error
+		result, err = l.Vault.ReplaceInNotes(ctx, opts)
+		return err
+	})
+	return result, err
+}
+
+// Capture appends an entry if the write limits allow it
+func (l *limitedVault) Capture(ctx context.Context, opts CaptureOptions) (CaptureResult, error) {
+	var result CaptureResult
+	err := l.write(opts.Target, func() error {
+		var err error
+		result, err = l.Vault.Capture(ctx, opts)
+		return err
+	})
+	return result, err
+}
+
+// AddLink inserts a link if the write limits allow it
+func (l *limitedVault) AddLink(ctx context.Context, opts AddLinkOptions) (AddLinkResult, error) {
+	var result AddLinkResult
+	err := l.write(opts.From, func() error {
+		var err error
+		result, err = l.Vault.AddLink(ctx, opts)
+		return err
+	})
+	return result, err
+}
+
+// AddAlias adds an alias if the write limits allow it
+func (l *limitedVault) AddAlias(ctx context.Context, path, alias string) (AliasResult, error) {
+	var result AliasResult
+	err := l.write(path, func() error {
+		var err error
+		result, err = l.Vault.AddAlias(ctx, path, alias)
+		return err
+	})
+	return result, err
+}
+
+// RemoveAlias removes an alias if the write limits allow it
+func (l *limitedVault) RemoveAlias(ctx context.Context, path, alias string) (AliasResult, error) {
+	var result AliasResult
+	err := l.write(path, func() error {
+		var err error
+		result, err = l.Vault.RemoveAlias(ctx, path, alias)
+		return err
+	})
+	return result, err
+}
+
+// PublishNote marks a note for publishing if the write limits allow it
+func (l *limitedVault) PublishNote(ctx context.Context, path string) (PublishResult, error) {
+	var result PublishResult
+	err := l.write(path, func() error {
+		var err error
+		result, err = l.Vault.PublishNote(ctx, path)
+		return err
+	})
+	return result, err
+}
+
+// UnpublishNote clears the publish mark of a note if the write limits
+// allow it
+func (l *limitedVault) UnpublishNote(ctx context.Context, path string) (PublishResult, error) {
+	var result PublishResult
+	err := l.write(path, func() error {
+		var err error
+		result, err = l.Vault.UnpublishNote(ctx, path)
+		return err
+	})
+	return result, err
+}
+
+// SyncTitles renames or retitles notes if the write limits allow it
+// The call counts as one write to its path; dry runs are not limited
+func (l *limitedVault) SyncTitles(ctx context.Context, opts SyncTitlesOptions) (SyncTitlesResult, error) {
+	if opts.DryRun {
+		return l.Vault.SyncTitles(ctx, opts)
+	}
+	var result SyncTitlesResult
+	err := l.write(opts.Path, func() error {
+		var err error
+		result, err = l.Vault.SyncTitles(ctx, opts)
+		return err
+	})
+	return result, err
+}
+
+// PromoteScratch writes a scratch note into the vault if the write limits
+// allow it
+func (l *limitedVault) PromoteScratch(ctx context.Context, opts PromoteScratchOptions) (ScratchPromotion, error) {
+	var result ScratchPromotion
+	err := l.write(opts.Path, func() error {
+		var err error
+		result, err = l.Vault.PromoteScratch(ctx, opts)
+		return err
+	})
+	return result, err
+}
+
+// GenerateRollup writes a rollup if the write limits allow it
+func (l *limitedVault) GenerateRollup(ctx context.Context, opts RollupOptions) (RollupResult, error) {
+	if opts.DryRun {
+		return l.Vault.GenerateRollup(ctx, opts)
+	}
+	var result RollupResult
+	err := l.write(opts.Target, func() error {
+		var err error
+		result, err = l.Vault.GenerateRollup(ctx, opts)
+		return err
+	})
+	return result, err
+}
+
+// LintNote checks a note, and fixes it if the write limits allow it
+func (l *limitedVault) LintNote(ctx context.Context, path string, fix bool) (LintResult, error) {
+	if !fix {
+		return l.Vault.LintNote(ctx, path, false)
+	}
+	var result LintResult
+	err := l.write(path, func() error {
+		var err error
+		result, err = l.Vault.LintNote(ctx, path, true)
+		return err
+	})
+	return result, err
+}
+
+// Info reports the wrapped vault's info with the write limits added
+func (l *limitedVault) Info(ctx context.Context) (VaultInfo, error) {
+	info, err := l.Vault.Info(ctx)
+	if err != nil {
+		return VaultInfo{}, err
+	}
+	limits := l.limits
+	info.Features.WriteLimits = &limits
+	return info, nil
+}
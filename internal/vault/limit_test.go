@@ -0,0 +1,152 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually advanced time source
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func setupLimitedVault(t *testing.T, limits WriteLimits) (*limitedVault, *fakeClock) {
+	v, _ := setupTestVault(t)
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	return newLimitedVault(v, limits, clock.Now), clock
+}
+
+func TestRateLimitedVault(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("no limits returns vault unchanged", func(t *testing.T) {
+		v, _ := setupTestVault(t)
+		if NewRateLimitedVault(v, WriteLimits{}) != v {
+			t.Error("Expected unwrapped vault")
+		}
+	})
+
+	t.Run("per file limit", func(t *testing.T) {
+		v, clock := setupLimitedVault(t, WriteLimits{FilePerMinute: 2})
+
+		for i := range 2 {
+			if err := v.Update(ctx, "note1.md", "Update"); err != nil {
+				t.Fatalf("Update() #%d error = %v", i, err)
+			}
+		}
+
+		err := v.Update(ctx, "note1.md", "Update")
+		var rateErr *RateLimitError
+		if !errors.As(err, &rateErr) || rateErr.Scope != LimitScopeFile {
+			t.Fatalf("Expected file RateLimitError, got %v", err)
+		}
+		if rateErr.RetrySeconds() != 30 {
+			t.Errorf("RetrySeconds() = %d, want 30", rateErr.RetrySeconds())
+		}
+
+		// Other notes are unaffected, including the same note spelled differently
+		if err := v.Update(ctx, "note2.md", "Update"); err != nil {
+			t.Errorf("Update() other note error = %v", err)
+		}
+		if err := v.Update(ctx, "./note1.md", "Update"); !errors.Is(err, ErrRateLimited) {
+			t.Errorf("Expected ErrRateLimited for equivalent path, got %v", err)
+		}
+
+		clock.Advance(30 * time.Second)
+		if err := v.Update(ctx, "note1.md", "Update"); err != nil {
+			t.Errorf("Update() after refill error = %v", err)
+		}
+	})
+
+	t.Run("vault limit", func(t *testing.T) {
+		v, clock := setupLimitedVault(t, WriteLimits{PerMinute: 3})
+
+		paths := []string{"note1.md", "note2.md", "subdir/note3.md"}
+		for _, p := range paths {
+			if err := v.Update(ctx, p, "Update"); err != nil {
+				t.Fatalf("Update(%s) error = %v", p, err)
+			}
+		}
+
+		err := v.Create(ctx, "new.md", "New")
+		var rateErr *RateLimitError
+		if !errors.As(err, &rateErr) || rateErr.Scope != LimitScopeVault {
+			t.Fatalf("Expected vault RateLimitError, got %v", err)
+		}
+		if rateErr.RetrySeconds() != 20 {
+			t.Errorf("RetrySeconds() = %d, want 20", rateErr.RetrySeconds())
+		}
+
+		// The rejected create must not have reached the vault
+		if _, err := v.Read(ctx, "new.md"); !errors.Is(err, ErrNoteNotFound) {
+			t.Errorf("Expected ErrNoteNotFound, got %v", err)
+		}
+
+		clock.Advance(20 * time.Second)
+		if err := v.Create(ctx, "new.md", "New"); err != nil {
+			t.Errorf("Create() after refill error = %v", err)
+		}
+	})
+
+	t.Run("files per session", func(t *testing.T) {
+		v, clock := setupLimitedVault(t, WriteLimits{FilesPerSession: 2})
+
+		if err := v.Update(ctx, "note1.md", "Update"); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+
+		// A failed write does not use up a session slot
+		if err := v.Update(ctx, "missing.md", "Update"); !errors.Is(err, ErrNoteNotFound) {
+			t.Fatalf("Expected ErrNoteNotFound, got %v", err)
+		}
+
+		if err := v.Update(ctx, "note2.md", "Update"); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+
+		// Notes already modified can still be written
+		if err := v.Update(ctx, "note1.md", "Again"); err != nil {
+			t.Errorf("Update() of modified note error = %v", err)
+		}
+
+		clock.Advance(time.Hour)
+		err := v.Update(ctx, "subdir/note3.md", "Update")
+		var rateErr *RateLimitError
+		if !errors.As(err, &rateErr) || rateErr.Scope != LimitScopeSession {
+			t.Fatalf("Expected session RateLimitError, got %v", err)
+		}
+		if rateErr.RetryAfter != 0 {
+			t.Errorf("RetryAfter = %v, want 0", rateErr.RetryAfter)
+		}
+	})
+
+	t.Run("reads and dry runs are never throttled", func(t *testing.T) {
+		v, _ := setupLimitedVault(t, WriteLimits{PerMinute: 1, FilePerMinute: 1, FilesPerSession: 1})
+
+		if err := v.Update(ctx, "note1.md", "Update"); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+
+		for range 5 {
+			if _, err := v.Read(ctx, "note1.md"); err != nil {
+				t.Errorf("Read() error = %v", err)
+			}
+			if _, err := v.ValidateUpdate(ctx, "note2.md"); err != nil {
+				t.Errorf("ValidateUpdate() error = %v", err)
+			}
+			if _, err := v.Search(ctx, SearchOptions{Query: "note"}); err != nil {
+				t.Errorf("Search() error = %v", err)
+			}
+		}
+	})
+}
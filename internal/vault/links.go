@@ -0,0 +1,412 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// LinkKind classifies an outgoing reference
+type LinkKind string
+
+// Kinds of outgoing references
+const (
+	LinkWiki     LinkKind = "wikilink" // [[Target]]
+	LinkEmbed    LinkKind = "embed"    // ![[Target]]
+	LinkMarkdown LinkKind = "markdown" // [text](relative/path.md)
+	LinkURL      LinkKind = "url"      // External URL
+)
+
+// Link represents an outgoing reference from a note
+type Link struct {
+	Kind     LinkKind `json:"kind"`               // Reference type
+	Target   string   `json:"target"`             // Target as written, without heading or block
+	Heading  string   `json:"heading,omitempty"`  // Referenced heading, if any
+	BlockID  string   `json:"block_id,omitempty"` // Referenced block id, if any
+	Display  string   `json:"display,omitempty"`  // Alias or link text
+	Path     string   `json:"path,omitempty"`     // Resolved vault-relative path
+	Resolved bool     `json:"resolved"`           // Whether Path points to an existing file
+	Line     int      `json:"line"`               // 1-based line number
+}
+
+var (
+	// wikiLinkRegex matches [[target]] and ![[target]] with optional |display
+	wikiLinkRegex = regexp.MustCompile(`(!?)\[\[([^\[\]|]*)(?:\|([^\[\]]*))?\]\]`)
+
+	// markdownLinkRegex matches [text](destination) and ![alt](destination)
+	markdownLinkRegex = regexp.MustCompile(`(!?)\[([^\[\]]*)\]\(([^()\s]+)(?:\s+"[^"]*")?\)`)
+
+	// bareURLRegex matches URLs not part of markdown link syntax
+	bareURLRegex = regexp.MustCompile(`<?(https?://[^\s<>()\[\]]+)>?`)
+
+	// urlSchemeRegex detects destinations with a URL scheme
+	urlSchemeRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:`)
+
+	// inlineCodeRegex matches inline code spans
+	inlineCodeRegex = regexp.MustCompile("`[^`]*`")
+)
+
+// ParseLinks extracts outgoing references from markdown content, in the
+// order they appear
+// Fenced code blocks and inline code are ignored
+// Returned links are unresolved; Path and Resolved are left empty
+func ParseLinks(content string) []Link {
+	links := []Link{}
+	inFence := false
+
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		// Blank out inline code so links inside it are ignored
+		line = inlineCodeRegex.ReplaceAllStringFunc(line, func(code string) string {
+			return strings.Repeat(" ", len(code))
+		})
+
+		lineNum := i + 1
+		// Each kind of link is blanked out once found, so the links of
+		// the line are put back in order by where they start
+		var found []Link
+		var starts []int
+
+		for _, loc := range wikiLinkRegex.FindAllStringSubmatchIndex(line, -1) {
+			m := submatches(line, loc)
+			link := Link{Kind: LinkWiki, Display: strings.TrimSpace(m[3]), Line: lineNum}
+			if m[1] == "!" {
+				link.Kind = LinkEmbed
+			}
+			link.Target, link.Heading, link.BlockID = splitAnchor(strings.TrimSpace(m[2]))
+			found, starts = append(found, link), append(starts, loc[0])
+		}
+		line = wikiLinkRegex.ReplaceAllStringFunc(line, func(s string) string {
+			return strings.Repeat(" ", len(s))
+		})
+
+		for _, loc := range markdownLinkRegex.FindAllStringSubmatchIndex(line, -1) {
+			m := submatches(line, loc)
+			dest := m[3]
+			link := Link{Display: m[2], Line: lineNum}
+
+			switch {
+			case urlSchemeRegex.MatchString(dest):
+				link.Kind = LinkURL
+				link.Target = dest
+			default:
+				link.Kind = LinkMarkdown
+				if m[1] == "!" {
+					link.Kind = LinkEmbed
+				}
+				if unescaped, err := url.PathUnescape(dest); err == nil {
+					dest = unescaped
+				}
+				link.Target, link.Heading, link.BlockID = splitAnchor(dest)
+			}
+			found, starts = append(found, link), append(starts, loc[0])
+		}
+		line = markdownLinkRegex.ReplaceAllStringFunc(line, func(s string) string {
+			return strings.Repeat(" ", len(s))
+		})
+
+		for _, loc := range bareURLRegex.FindAllStringSubmatchIndex(line, -1) {
+			m := submatches(line, loc)
+			found, starts = append(found, Link{Kind: LinkURL, Target: strings.TrimRight(m[1], ".,;:!?"), Line: lineNum}), append(starts, loc[0])
+		}
+
+		order := make([]int, len(found))
+		for j := range order {
+			order[j] = j
+		}
+		slices.SortStableFunc(order, func(a, b int) int { return starts[a] - starts[b] })
+		for _, j := range order {
+			links = append(links, found[j])
+		}
+	}
+
+	return links
+}
+
+// submatches returns the text of the submatches at loc in s, as
+// FindStringSubmatch would, with "" for those that did not match
+func submatches(s string, loc []int) []string {
+	m := make([]string, len(loc)/2)
+	for i := range m {
+		if loc[2*i] >= 0 {
+			m[i] = s[loc[2*i]:loc[2*i+1]]
+		}
+	}
+	return m
+}
+
+// markdownPathEscaper encodes the characters that would end a markdown
+// link destination
+var markdownPathEscaper = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29")
+
+// linkEdit replaces line[start:end] with text
+type linkEdit struct {
+	start, end int
+	text       string
+}
+
+// rewriteLinks replaces the targets of wikilinks, embeds and markdown links
+// in content, skipping code like ParseLinks. relink receives the decoded
+// target of each link and returns its replacement, or false to keep it.
+// Headings, block references and display text are left as written.
+// Returns the new content and the number of links changed.
+func rewriteLinks(content string, relink func(target string) (string, bool)) (string, int) {
+	blank := func(s string) string {
+		return strings.Repeat(" ", len(s))
+	}
+
+	lines := strings.Split(content, "\n")
+	inFence := false
+	changed := 0
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		// Match on a copy with code blanked out; offsets stay the same
+		masked := inlineCodeRegex.ReplaceAllStringFunc(line, blank)
+		var edits []linkEdit
+
+		for _, m := range wikiLinkRegex.FindAllStringSubmatchIndex(masked, -1) {
+			raw := line[m[4]:m[5]]
+			start := m[4] + len(raw) - len(strings.TrimLeft(raw, " \t"))
+			target, _, _ := splitAnchor(strings.TrimSpace(raw))
+			if replacement, ok := relink(target); ok {
+				edits = append(edits, linkEdit{start, start + len(target), replacement})
+			}
+		}
+		masked = wikiLinkRegex.ReplaceAllStringFunc(masked, blank)
+
+		for _, m := range markdownLinkRegex.FindAllStringSubmatchIndex(masked, -1) {
+			dest := line[m[6]:m[7]]
+			if urlSchemeRegex.MatchString(dest) {
+				continue
+			}
+			raw, _, _ := strings.Cut(dest, "#")
+			target := raw
+			if unescaped, err := url.PathUnescape(raw); err == nil {
+				target = unescaped
+			}
+			if replacement, ok := relink(target); ok {
+				edits = append(edits, linkEdit{m[6], m[6] + len(raw), markdownPathEscaper.Replace(replacement)})
+			}
+		}
+
+		// Apply from the end so earlier offsets stay valid
+		sort.Slice(edits, func(a, b int) bool { return edits[a].start > edits[b].start })
+		for _, edit := range edits {
+			line = line[:edit.start] + edit.text + line[edit.end:]
+		}
+		lines[i] = line
+		changed += len(edits)
+	}
+
+	return strings.Join(lines, "\n"), changed
+}
+
+// splitAnchor separates "Note#Heading" or "Note#^block" into its parts
+func splitAnchor(target string) (string, string, string) {
+	name, anchor, found := strings.Cut(target, "#")
+	if !found {
+		return name, "", ""
+	}
+	if blockID, ok := strings.CutPrefix(anchor, "^"); ok {
+		return name, "", blockID
+	}
+	return name, anchor, ""
+}
+
+// fileIndex maps files in the vault for link resolution
+type fileIndex struct {
+	paths  map[string]string   // lowercase vault-relative path -> path
+	byName map[string][]string // lowercase base name -> paths
+}
+
+// buildFileIndex walks the whole vault and indexes every file
+func (v *vault) buildFileIndex(ctx context.Context) (*fileIndex, error) {
+	index := &fileIndex{
+		paths:  make(map[string]string),
+		byName: make(map[string][]string),
+	}
+
+	walkFn := func(fullPath string, info os.FileInfo, err error) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		if err != nil || info.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(v.basePath, fullPath)
+		if err != nil {
+			return nil
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		lower := strings.ToLower(relPath)
+		index.paths[lower] = relPath
+		name := path.Base(lower)
+		index.byName[name] = append(index.byName[name], relPath)
+		return nil
+	}
+
+	if err := v.walk(v.basePath, walkFn); err != nil {
+		return nil, fmt.Errorf("failed to walk directory: %w", err)
+	}
+
+	index.sortNames()
+	return index, nil
+}
+
+// sortNames orders the paths sharing each name shortest first, matching
+// Obsidian's preference
+func (idx *fileIndex) sortNames() {
+	for _, paths := range idx.byName {
+		sort.Slice(paths, func(i, j int) bool {
+			if len(paths[i]) != len(paths[j]) {
+				return len(paths[i]) < len(paths[j])
+			}
+			return paths[i] < paths[j]
+		})
+	}
+}
+
+// renamed returns a copy of the index with the file or every path under
+// the folder from moved to to, as it will look after the move
+func (idx *fileIndex) renamed(from, to string) *fileIndex {
+	moved := &fileIndex{
+		paths:  make(map[string]string, len(idx.paths)),
+		byName: make(map[string][]string, len(idx.byName)),
+	}
+	for lower, p := range idx.paths {
+		if newPath, ok := movedPath(p, from, to); ok {
+			lower, p = strings.ToLower(newPath), newPath
+		}
+		moved.paths[lower] = p
+		name := path.Base(lower)
+		moved.byName[name] = append(moved.byName[name], p)
+	}
+	moved.sortNames()
+	return moved
+}
+
+// withFiles returns a copy of the index with the files at paths added, as
+// it will look once they are created
+func (idx *fileIndex) withFiles(paths ...string) *fileIndex {
+	added := &fileIndex{
+		paths:  maps.Clone(idx.paths),
+		byName: make(map[string][]string, len(idx.byName)),
+	}
+	for name, named := range idx.byName {
+		added.byName[name] = slices.Clone(named)
+	}
+	for _, p := range paths {
+		lower := strings.ToLower(p)
+		if _, ok := added.paths[lower]; ok {
+			continue
+		}
+		added.paths[lower] = p
+		name := path.Base(lower)
+		added.byName[name] = append(added.byName[name], p)
+	}
+	added.sortNames()
+	return added
+}
+
+// resolve finds the vault-relative path for a link target written in the
+// note at source. Targets without an extension refer to markdown notes.
+func (idx *fileIndex) resolve(source, target string) (string, bool) {
+	if target == "" {
+		return source, true // Same-note heading or block reference
+	}
+
+	target = strings.TrimPrefix(filepath.ToSlash(target), "/")
+	if path.Ext(target) == "" {
+		target += ".md"
+	}
+	lower := strings.ToLower(target)
+
+	// Relative to the source note's folder
+	relative := strings.ToLower(path.Join(path.Dir(source), target))
+	if p, ok := idx.paths[relative]; ok {
+		return p, true
+	}
+
+	// Vault-relative path
+	if p, ok := idx.paths[lower]; ok {
+		return p, true
+	}
+
+	// Shortest path whose trailing components match the target
+	for _, p := range idx.byName[path.Base(lower)] {
+		pl := strings.ToLower(p)
+		if pl == lower || strings.HasSuffix(pl, "/"+lower) {
+			return p, true
+		}
+	}
+
+	return "", false
+}
+
+// Links returns the outgoing references of a note with targets resolved
+// to vault-relative paths where possible
+func (v *vault) Links(ctx context.Context, notePath string) ([]Link, error) {
+	fullPath, err := v.validatePath(notePath)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := os.Stat(fullPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNoteNotFound
+		}
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	entry, err := v.loadEntry(fullPath, stat.ModTime())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	index, err := v.buildFileIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	source := v.relPath(fullPath)
+	links := make([]Link, len(entry.Links))
+	for i, link := range entry.Links {
+		if link.Kind != LinkURL {
+			link.Path, link.Resolved = index.resolve(source, link.Target)
+		}
+		links[i] = link
+	}
+
+	return links, nil
+}
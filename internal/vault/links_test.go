@@ -0,0 +1,135 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseLinks(t *testing.T) {
+	content := "# Title\n" +
+		"See [[Other Note]] and [[folder/Deep|the deep one]].\n" +
+		"Jump to [[Other Note#Section]] or [[#Local]] or [[Other Note#^abc123]].\n" +
+		"![[diagram.png]] and ![[Embedded Note]]\n" +
+		"Docs at [site](https://example.com/docs) and [local](sub/page%20one.md).\n" +
+		"Bare https://example.org/path.\n" +
+		"In order: https://a.example ![chart](chart.png) [[Last]]\n" +
+		"```\n[[Not a link]]\n```\n" +
+		"Inline `[[also not]]` code\n"
+
+	links := ParseLinks(content)
+
+	want := []Link{
+		{Kind: LinkWiki, Target: "Other Note", Line: 2},
+		{Kind: LinkWiki, Target: "folder/Deep", Display: "the deep one", Line: 2},
+		{Kind: LinkWiki, Target: "Other Note", Heading: "Section", Line: 3},
+		{Kind: LinkWiki, Target: "", Heading: "Local", Line: 3},
+		{Kind: LinkWiki, Target: "Other Note", BlockID: "abc123", Line: 3},
+		{Kind: LinkEmbed, Target: "diagram.png", Line: 4},
+		{Kind: LinkEmbed, Target: "Embedded Note", Line: 4},
+		{Kind: LinkURL, Target: "https://example.com/docs", Display: "site", Line: 5},
+		{Kind: LinkMarkdown, Target: "sub/page one.md", Display: "local", Line: 5},
+		{Kind: LinkURL, Target: "https://example.org/path", Line: 6},
+		{Kind: LinkURL, Target: "https://a.example", Line: 7},
+		{Kind: LinkEmbed, Target: "chart.png", Display: "chart", Line: 7},
+		{Kind: LinkWiki, Target: "Last", Line: 7},
+	}
+
+	if len(links) != len(want) {
+		t.Fatalf("ParseLinks() returned %d links, want %d: %+v", len(links), len(want), links)
+	}
+	for i := range want {
+		if links[i] != want[i] {
+			t.Errorf("links[%d] = %+v, want %+v", i, links[i], want[i])
+		}
+	}
+}
+
+func TestRewriteLinks(t *testing.T) {
+	content := "[[Old/Note]] and [[ Old/Note#Heading | shown ]] and ![[Old/img.png]]\n" +
+		"[text](Old/My%20Note.md#Part) [site](https://example.com/Old/Note) [[Keep]]\n" +
+		"```\n[[Old/Note]]\n```\n" +
+		"Inline `[[Old/Note]]` code"
+
+	relink := func(target string) (string, bool) {
+		if rest, ok := strings.CutPrefix(target, "Old/"); ok {
+			return "New Place/" + rest, true
+		}
+		return "", false
+	}
+
+	got, changed := rewriteLinks(content, relink)
+	want := "[[New Place/Note]] and [[ New Place/Note#Heading | shown ]] and ![[New Place/img.png]]\n" +
+		"[text](New%20Place/My%20Note.md#Part) [site](https://example.com/Old/Note) [[Keep]]\n" +
+		"```\n[[Old/Note]]\n```\n" +
+		"Inline `[[Old/Note]]` code"
+
+	if got != want {
+		t.Errorf("rewriteLinks() =\n%s\nwant\n%s", got, want)
+	}
+	if changed != 4 {
+		t.Errorf("changed = %d, want 4", changed)
+	}
+}
+
+func TestLinks(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"source.md":            "[[Target]] [[missing]] ![[image.png]] [[sub/Nested#Heading]] [[Nested]]",
+		"target.md":            "Target note",
+		"sub/nested.md":        "Nested",
+		"deeper/sub/nested.md": "Longer path with same name",
+		"assets/image.png":     "png",
+	}
+	writeFiles(t, tmpDir, files)
+
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	ctx := context.Background()
+
+	links, err := v.Links(ctx, "source.md")
+	if err != nil {
+		t.Fatalf("Links() error = %v", err)
+	}
+
+	want := []struct {
+		path     string
+		resolved bool
+	}{
+		{"target.md", true},        // Case-insensitive name match
+		{"", false},                // Unresolved
+		{"assets/image.png", true}, // Attachment resolved by name
+		{"sub/nested.md", true},    // Vault-relative path
+		{"sub/nested.md", true},    // Shortest path wins for bare names
+	}
+
+	if len(links) != len(want) {
+		t.Fatalf("Links() returned %d links, want %d", len(links), len(want))
+	}
+	for i, w := range want {
+		if links[i].Path != w.path || links[i].Resolved != w.resolved {
+			t.Errorf("links[%d] = {Path: %q, Resolved: %v}, want {%q, %v}", i, links[i].Path, links[i].Resolved, w.path, w.resolved)
+		}
+	}
+
+	t.Run("nonexistent note", func(t *testing.T) {
+		_, err := v.Links(ctx, "nope.md")
+		if !errors.Is(err, ErrNoteNotFound) {
+			t.Errorf("Expected ErrNoteNotFound, got %v", err)
+		}
+	})
+
+	t.Run("links cached with tags", func(t *testing.T) {
+		entry, ok := v.(*vault).cache.Get(filepath.Join(v.(*vault).basePath, "source.md"))
+		if !ok {
+			t.Fatal("Expected source.md to be cached")
+		}
+		if len(entry.Links) != len(want) {
+			t.Errorf("Cached %d links, want %d", len(entry.Links), len(want))
+		}
+	})
+}
@@ -0,0 +1,413 @@
+package vault
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// LintSeverity ranks a lint finding
+type LintSeverity string
+
+// Lint severities, most severe first
+const (
+	LintError   LintSeverity = "error"
+	LintWarning LintSeverity = "warning"
+	LintInfo    LintSeverity = "info"
+)
+
+// ParseLintSeverity validates a severity
+func ParseLintSeverity(s string) (LintSeverity, error) {
+	switch severity := LintSeverity(strings.ToLower(strings.TrimSpace(s))); severity {
+	case LintError, LintWarning, LintInfo:
+		return severity, nil
+	default:
+		return "", fmt.Errorf("unknown severity %q (want error, warning or info)", s)
+	}
+}
+
+// LintFinding is a place where a note breaks a lint rule
+type LintFinding struct {
+	Path     string       `json:"path"`
+	Rule     string       `json:"rule"`
+	Severity LintSeverity `json:"severity"`
+	Line     int          `json:"line,omitempty"` // 1-based, 0 for the note as a whole
+	Message  string       `json:"message"`
+	Fixable  bool         `json:"fixable"` // Lint with fix would fix it
+}
+
+// LintResult reports the findings in one note
+type LintResult struct {
+	Path     string        `json:"path"`
+	Findings []LintFinding `json:"findings"`        // Sorted by line and rule; after fixing, what is left
+	Fixed    []LintFinding `json:"fixed,omitempty"` // Findings fixed, at their lines before the fix
+	Revision string        `json:"revision"`        // Content hash of the note, after any fix
+}
+
+// LintReport lists the findings in the notes of a folder
+type LintReport struct {
+	NotesChecked int            `json:"notes_checked"`
+	Counts       map[string]int `json:"counts"`   // Findings of each rule
+	Findings     []LintFinding  `json:"findings"` // Sorted by path and line
+}
+
+// LintRuleInfo describes a lint rule
+type LintRuleInfo struct {
+	ID          string         `json:"id"`
+	Severity    LintSeverity   `json:"severity"`
+	Description string         `json:"description"`
+	Fixable     bool           `json:"fixable"`           // Some of its findings can be fixed
+	Options     map[string]any `json:"options,omitempty"` // Options and their defaults
+}
+
+// lintRule checks notes for one convention. A rule is a lintRule value
+// listed in lintRules; check and fix see the note, never the vault.
+type lintRule struct {
+	id          string
+	severity    LintSeverity // Unless configured otherwise
+	description string
+	options     map[string]any // Options the rule takes, with their defaults
+
+	// check returns the places the note breaks the rule
+	check func(note *lintNote, opts lintOptions) []lintIssue
+
+	// fix returns the note's content with the fixable issues fixed, and
+	// false when it cannot change it. Nil for rules that fix nothing.
+	fix func(note *lintNote, opts lintOptions) (string, bool)
+}
+
+// lintIssue is a finding before it is attributed to a rule and a note
+type lintIssue struct {
+	line    int
+	message string
+	fixable bool
+}
+
+// lintOptions are a rule's options: its defaults with the configured
+// values over them, of the same types
+type lintOptions map[string]any
+
+// bool returns a boolean option
+func (o lintOptions) bool(name string) bool {
+	b, _ := o[name].(bool)
+	return b
+}
+
+// LintSettings choose the lint rules applied and how
+type LintSettings struct {
+	Enable  []string                    // Only these rules, all when empty
+	Disable []string                    // Rules not applied
+	Rules   map[string]LintRuleSettings // Overrides by rule ID
+}
+
+// LintRuleSettings override a rule's defaults
+type LintRuleSettings struct {
+	Severity string         // Empty keeps the rule's
+	Options  map[string]any // Values of the options named
+}
+
+// Validate reports unknown rules, severities and options, and option
+// values of the wrong type
+func (s LintSettings) Validate() error {
+	known := func(id string) error {
+		if findLintRule(id) != nil {
+			return nil
+		}
+		ids := make([]string, len(lintRules))
+		for i, r := range lintRules {
+			ids[i] = r.id
+		}
+		return fmt.Errorf("unknown rule %q; rules are %s", id, strings.Join(ids, ", "))
+	}
+	for _, id := range slices.Concat(s.Enable, s.Disable) {
+		if err := known(id); err != nil {
+			return err
+		}
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(s.Rules)) {
+		if err := known(id); err != nil {
+			return err
+		}
+		rule, settings := findLintRule(id), s.Rules[id]
+		if settings.Severity != "" {
+			if _, err := ParseLintSeverity(settings.Severity); err != nil {
+				return fmt.Errorf("%s: %w", id, err)
+			}
+		}
+		for _, name := range slices.Sorted(maps.Keys(settings.Options)) {
+			def, ok := rule.options[name]
+			if !ok {
+				if len(rule.options) == 0 {
+					return fmt.Errorf("%s: unknown option %q; the rule has no options", id, name)
+				}
+				return fmt.Errorf("%s: unknown option %q; options are %s", id, name, strings.Join(slices.Sorted(maps.Keys(rule.options)), ", "))
+			}
+			if reflect.TypeOf(settings.Options[name]) != reflect.TypeOf(def) {
+				return fmt.Errorf("%s: option %s must be like %v, got %v", id, name, def, settings.Options[name])
+			}
+		}
+	}
+	return nil
+}
+
+// appliedRule is a rule with its configured severity and options
+type appliedRule struct {
+	*lintRule
+	severity LintSeverity
+	options  lintOptions
+}
+
+// WithLint chooses the lint rules applied and their severities and
+// options, as checked by LintSettings.Validate; invalid overrides are
+// ignored. By default every rule applies with its defaults.
+func WithLint(s LintSettings) Option {
+	return func(v *vault) {
+		v.lint = s.applied()
+	}
+}
+
+// applied returns the rules s selects, configured, in lintRules order
+func (s LintSettings) applied() []appliedRule {
+	var rules []appliedRule
+	for _, rule := range lintRules {
+		if len(s.Enable) > 0 && !slices.Contains(s.Enable, rule.id) || slices.Contains(s.Disable, rule.id) {
+			continue
+		}
+		applied := appliedRule{lintRule: rule, severity: rule.severity, options: maps.Clone(rule.options)}
+		settings := s.Rules[rule.id]
+		if severity, err := ParseLintSeverity(settings.Severity); err == nil {
+			applied.severity = severity
+		}
+		for name, value := range settings.Options {
+			if def, ok := rule.options[name]; ok && reflect.TypeOf(value) == reflect.TypeOf(def) {
+				applied.options[name] = value
+			}
+		}
+		rules = append(rules, applied)
+	}
+	return rules
+}
+
+// findLintRule returns the rule with id, nil if there is none
+func findLintRule(id string) *lintRule {
+	i := slices.IndexFunc(lintRules, func(r *lintRule) bool { return r.id == id })
+	if i < 0 {
+		return nil
+	}
+	return lintRules[i]
+}
+
+// LintRules describes the lint rules a vault can apply, with their
+// defaults, in the order they run
+func LintRules() []LintRuleInfo {
+	infos := make([]LintRuleInfo, len(lintRules))
+	for i, rule := range lintRules {
+		infos[i] = LintRuleInfo{
+			ID:          rule.id,
+			Severity:    rule.severity,
+			Description: rule.description,
+			Fixable:     rule.fix != nil,
+			Options:     rule.options,
+		}
+	}
+	return infos
+}
+
+// lintNote is a note as lint rules see it
+type lintNote struct {
+	path     string     // Vault-relative path
+	content  string     // Whole content, frontmatter included
+	lines    []string   // Lines of content
+	body     int        // 0-based index of the first line after the frontmatter
+	code     []bool     // Lines in fenced code blocks, delimiters included
+	headings []Heading  // As ParseHeadings finds them
+	index    *fileIndex // Resolves link targets
+}
+
+// newLintNote prepares the note at path for the rules
+func newLintNote(path, content string, index *fileIndex) *lintNote {
+	note := &lintNote{
+		path:     path,
+		content:  content,
+		lines:    strings.Split(content, "\n"),
+		headings: ParseHeadings(content),
+		index:    index,
+	}
+	note.body = len(note.lines)
+	note.code = make([]bool, len(note.lines))
+	markdownLines(content, func(_ string, lineNum int, code bool) {
+		note.body = min(note.body, lineNum-1)
+		note.code[lineNum-1] = code
+	})
+	return note
+}
+
+// prose reports whether the 1-based line is markdown text: neither
+// frontmatter nor code
+func (n *lintNote) prose(line int) bool {
+	return line > n.body && !n.code[line-1]
+}
+
+// lint applies rules to the note, returning its findings sorted by line
+// and rule
+func lint(rules []appliedRule, note *lintNote) []LintFinding {
+	findings := []LintFinding{}
+	for _, rule := range rules {
+		for _, issue := range rule.check(note, rule.options) {
+			findings = append(findings, LintFinding{
+				Path:     note.path,
+				Rule:     rule.id,
+				Severity: rule.severity,
+				Line:     issue.line,
+				Message:  issue.message,
+				Fixable:  issue.fixable && rule.fix != nil,
+			})
+		}
+	}
+	slices.SortStableFunc(findings, compareFindings)
+	return findings
+}
+
+// compareFindings orders findings by path, line and rule
+func compareFindings(a, b LintFinding) int {
+	return cmp.Or(cmp.Compare(a.Path, b.Path), cmp.Compare(a.Line, b.Line), cmp.Compare(a.Rule, b.Rule))
+}
+
+// lintFix applies the fixes of the rules with fixable findings in turn,
+// each to the content the last left. Returns the fixed content and the
+// findings of the rules whose fix changed it.
+func lintFix(rules []appliedRule, note *lintNote, findings []LintFinding) (string, []LintFinding) {
+	fixed := []LintFinding{}
+	for _, rule := range rules {
+		fixable := slices.DeleteFunc(slices.Clone(findings), func(f LintFinding) bool {
+			return f.Rule != rule.id || !f.Fixable
+		})
+		if len(fixable) == 0 {
+			continue
+		}
+		// Earlier fixes may have fixed these already, or moved them
+		if !slices.ContainsFunc(rule.check(note, rule.options), func(issue lintIssue) bool { return issue.fixable }) {
+			continue
+		}
+		content, ok := rule.fix(note, rule.options)
+		if !ok || content == note.content {
+			continue
+		}
+		note = newLintNote(note.path, content, note.index)
+		fixed = append(fixed, fixable...)
+	}
+	slices.SortStableFunc(fixed, compareFindings)
+	return note.content, fixed
+}
+
+// LintNote checks the note at path against the lint rules. With fix, the
+// findings the rules can fix are fixed in one write, under the note's
+// write lock, and the findings left are reported.
+func (v *vault) LintNote(ctx context.Context, path string, fix bool) (LintResult, error) {
+	fullPath, err := v.validatePath(path)
+	if err != nil {
+		return LintResult{}, err
+	}
+	relPath := v.relPath(fullPath)
+
+	index, err := v.buildFileIndex(ctx)
+	if err != nil {
+		return LintResult{}, err
+	}
+
+	if fix {
+		unlock := v.writeLocks.lock(fullPath)
+		defer unlock()
+		if _, err := v.checkUpdate(ctx, relPath); err != nil {
+			return LintResult{}, err
+		}
+	}
+	stat, err := os.Stat(fullPath)
+	if err != nil {
+		return LintResult{}, ErrNoteNotFound
+	}
+	entry, err := v.loadEntry(fullPath, stat.ModTime())
+	if err != nil {
+		return LintResult{}, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	note := newLintNote(relPath, entry.Content, index)
+	result := LintResult{Path: relPath, Findings: lint(v.lint, note), Revision: entry.ContentHash}
+	if !fix || !slices.ContainsFunc(result.Findings, func(f LintFinding) bool { return f.Fixable }) {
+		return result, nil
+	}
+
+	content, fixed := lintFix(v.lint, note, result.Findings)
+	if len(fixed) == 0 {
+		return result, nil
+	}
+	content, err = v.PrepareContent(relPath, content, false)
+	if err != nil {
+		return LintResult{}, err
+	}
+
+	// Check context cancellation before I/O; once writing starts it completes
+	if err := ctx.Err(); err != nil {
+		return LintResult{}, err
+	}
+	written, err := v.writeNote(fullPath, content)
+	if err != nil {
+		return LintResult{}, err
+	}
+	result.Findings = lint(v.lint, newLintNote(relPath, content, index))
+	result.Fixed = fixed
+	result.Revision = contentHash(content)
+	return result, v.record(ctx, written)
+}
+
+// LintVault checks every note under subpath against the lint rules
+func (v *vault) LintVault(ctx context.Context, subpath string) (LintReport, error) {
+	index, err := v.buildFileIndex(ctx)
+	if err != nil {
+		return LintReport{}, err
+	}
+
+	report := LintReport{Counts: make(map[string]int), Findings: []LintFinding{}}
+
+	// Notes are loaded concurrently; collect under a lock
+	var mu sync.Mutex
+	var loadErr error
+
+	notes, err := v.walkNotes(ctx, ListOptions{Subpath: subpath, Recursive: true}, func(file noteFile, entry CacheEntry) bool {
+		if entry.ContentOmitted {
+			var err error
+			if entry, err = v.loadEntry(file.fullPath, file.info.ModTime()); err != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				loadErr = cmp.Or(loadErr, err)
+				return false
+			}
+		}
+		findings := lint(v.lint, newLintNote(file.relPath, entry.Content, index))
+
+		mu.Lock()
+		defer mu.Unlock()
+		report.Findings = append(report.Findings, findings...)
+		return true
+	})
+	if err != nil {
+		return LintReport{}, err
+	}
+	if loadErr != nil {
+		return LintReport{}, fmt.Errorf("failed to read file: %w", loadErr)
+	}
+
+	report.NotesChecked = len(notes)
+	for _, finding := range report.Findings {
+		report.Counts[finding.Rule]++
+	}
+	slices.SortStableFunc(report.Findings, compareFindings)
+	return report, nil
+}
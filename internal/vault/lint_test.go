@@ -0,0 +1,122 @@
+package vault
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// findingRules returns the rules of findings, in order
+func findingRules(findings []LintFinding) []string {
+	rules := []string{}
+	for _, f := range findings {
+		rules = append(rules, f.Rule)
+	}
+	return rules
+}
+
+func TestLintNote(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"Plan.md":    "# Plan \n\n#project\n\nSee [the kickoff](Kickoff.md).\n\n### Steps\n\n## Steps\n",
+		"Kickoff.md": "# Kickoff\n",
+	})
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+
+	result, err := v.LintNote(ctx, "Plan.md", false)
+	if err != nil {
+		t.Fatalf("LintNote() error = %v", err)
+	}
+	want := []string{"no-trailing-whitespace", "frontmatter-tags", "wiki-links", "heading-increment", "no-duplicate-headings"}
+	if got := findingRules(result.Findings); !slices.Equal(got, want) || result.Fixed != nil {
+		t.Errorf("LintNote() = %+v, want findings of %v", result, want)
+	}
+	if result.Findings[0].Severity != LintInfo || result.Findings[0].Line != 1 || !result.Findings[0].Fixable {
+		t.Errorf("LintNote() first finding = %+v", result.Findings[0])
+	}
+
+	result, err = v.LintNote(ctx, "Plan.md", true)
+	if err != nil {
+		t.Fatalf("LintNote() with fix error = %v", err)
+	}
+	if got := findingRules(result.Findings); !slices.Equal(got, []string{"no-duplicate-headings"}) {
+		t.Errorf("LintNote() with fix left %v, want only the duplicate heading", got)
+	}
+	if got := findingRules(result.Fixed); !slices.Equal(got, want[:4]) {
+		t.Errorf("LintNote() fixed %v, want %v", got, want[:4])
+	}
+	content, _ := v.Read(ctx, "Plan.md")
+	wantContent := "---\ntags:\n  - project\n---\n# Plan\n\nSee [[Kickoff|the kickoff]].\n\n## Steps\n\n## Steps\n"
+	if content != wantContent || result.Revision != contentHash(content) {
+		t.Errorf("Plan.md = %q, want %q", content, wantContent)
+	}
+
+	if _, err := v.LintNote(ctx, "Missing.md", false); err == nil {
+		t.Error("LintNote() of a missing note succeeded")
+	}
+}
+
+func TestLintVault(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"Clean.md":        "# Clean\n",
+		"Work/Todo.md":    "# Todo\n#### Later \n",
+		"Work/Done.md":    "# Finished\n",
+		"Other/Ignore.md": "no heading",
+	})
+
+	settings := LintSettings{
+		Disable: []string{"no-trailing-whitespace"},
+		Rules:   map[string]LintRuleSettings{"heading-increment": {Severity: "error"}},
+	}
+	if err := settings.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	v, err := NewVault(tmpDir, WithLint(settings))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+
+	report, err := v.LintVault(ctx, "Work")
+	if err != nil {
+		t.Fatalf("LintVault() error = %v", err)
+	}
+	if report.NotesChecked != 2 || !maps.Equal(report.Counts, map[string]int{"single-h1": 1, "heading-increment": 1}) {
+		t.Errorf("LintVault() = %+v", report)
+	}
+	if len(report.Findings) != 2 || report.Findings[0].Path != "Work/Done.md" || report.Findings[1].Severity != LintError {
+		t.Errorf("LintVault() findings = %+v, want Work/Done.md first and the skipped level as an error", report.Findings)
+	}
+
+	if rules := v.(*vault).lint; len(rules) != len(lintRules)-1 || rules[0].id != "frontmatter-tags" {
+		t.Errorf("rules applied = %d, want every rule but no-trailing-whitespace", len(rules))
+	}
+}
+
+func TestLintSettingsValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings LintSettings
+		want     string
+	}{
+		{"unknown rule", LintSettings{Enable: []string{"single-h2"}}, `unknown rule "single-h2"; rules are no-trailing-whitespace`},
+		{"severity", LintSettings{Rules: map[string]LintRuleSettings{"single-h1": {Severity: "fatal"}}}, `single-h1: unknown severity "fatal"`},
+		{"option", LintSettings{Rules: map[string]LintRuleSettings{"single-h1": {Options: map[string]any{"match_title": true}}}}, `options are match_filename`},
+		{"no options", LintSettings{Rules: map[string]LintRuleSettings{"heading-increment": {Options: map[string]any{"max": 2}}}}, "the rule has no options"},
+		{"option type", LintSettings{Rules: map[string]LintRuleSettings{"single-h1": {Options: map[string]any{"match_filename": "yes"}}}}, "option match_filename must be like true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.settings.Validate(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,388 @@
+package vault
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// lintRules are the rules a vault can apply, in the order they run; fixes
+// are applied in this order too, each to the content the last left
+var lintRules = []*lintRule{
+	&trailingWhitespaceRule,
+	&frontmatterTagsRule,
+	&wikiLinksRule,
+	&singleH1Rule,
+	&headingIncrementRule,
+	&duplicateHeadingsRule,
+}
+
+// trailingWhitespaceRule finds spaces and tabs at the ends of lines
+var trailingWhitespaceRule = lintRule{
+	id:          "no-trailing-whitespace",
+	severity:    LintInfo,
+	description: "Lines do not end in spaces or tabs",
+	options:     map[string]any{"allow_line_breaks": false}, // Keep the two spaces of a markdown line break
+	check: func(note *lintNote, opts lintOptions) []lintIssue {
+		var issues []lintIssue
+		for i, line := range note.lines {
+			if trailing := trailingWhitespace(line, opts.bool("allow_line_breaks") && note.prose(i+1)); trailing > 0 {
+				issues = append(issues, lintIssue{line: i + 1, message: fmt.Sprintf("Line ends in %d whitespace characters", trailing), fixable: true})
+			}
+		}
+		return issues
+	},
+	fix: func(note *lintNote, opts lintOptions) (string, bool) {
+		lines := slices.Clone(note.lines)
+		for i, line := range lines {
+			if trailing := trailingWhitespace(line, opts.bool("allow_line_breaks") && note.prose(i+1)); trailing > 0 {
+				text, cr := strings.CutSuffix(line, "\r")
+				lines[i] = text[:len(text)-trailing]
+				if cr {
+					lines[i] += "\r"
+				}
+			}
+		}
+		return strings.Join(lines, "\n"), true
+	},
+}
+
+// trailingWhitespace returns the number of spaces and tabs ending line,
+// before any \r. With lineBreak, exactly two spaces after text are a
+// markdown line break and not counted.
+func trailingWhitespace(line string, lineBreak bool) int {
+	line = strings.TrimSuffix(line, "\r")
+	trimmed := strings.TrimRight(line, " \t")
+	trailing := len(line) - len(trimmed)
+	if lineBreak && trimmed != "" && line[len(trimmed):] == "  " {
+		return 0
+	}
+	return trailing
+}
+
+// frontmatterTagsRule finds #tags in the text of a note, which belong in
+// its frontmatter tags property
+var frontmatterTagsRule = lintRule{
+	id:          "frontmatter-tags",
+	severity:    LintWarning,
+	description: "Tags are listed in the frontmatter, not written as #tags in the text",
+	check: func(note *lintNote, _ lintOptions) []lintIssue {
+		var issues []lintIssue
+		for i, line := range note.lines {
+			if !note.prose(i + 1) {
+				continue
+			}
+			tags, only := inlineTags(line)
+			for _, tag := range tags {
+				message := fmt.Sprintf("Tag #%s is in the text, not the frontmatter", tag)
+				issues = append(issues, lintIssue{line: i + 1, message: message, fixable: only})
+			}
+		}
+		return issues
+	},
+	// Lines holding nothing but tags move to the frontmatter; tags within
+	// sentences are left for the author
+	fix: func(note *lintNote, _ lintOptions) (string, bool) {
+		var tags []any
+		var lines []string
+		for i, line := range note.lines {
+			if found, only := inlineTags(line); only && note.prose(i+1) {
+				for _, tag := range found {
+					tags = append(tags, tag)
+				}
+				// Do not leave two blank lines where the tags were
+				if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" &&
+					i+1 < len(note.lines) && strings.TrimSpace(note.lines[i+1]) == "" {
+					lines = lines[:len(lines)-1]
+				}
+				continue
+			}
+			lines = append(lines, line)
+		}
+		if len(tags) == 0 {
+			return note.content, false
+		}
+		content, err := mergeFrontmatter(strings.Join(lines, "\n"), map[string]any{"tags": tags})
+		if err != nil {
+			return note.content, false // Frontmatter that is not a list of properties
+		}
+		return content, true
+	},
+}
+
+// inlineTags returns the #tags in a line of text, without #, and whether
+// the line holds nothing else. Tags in code, links and URLs do not count,
+// nor do numbers such as #1 or a # in the middle of a word.
+func inlineTags(line string) ([]string, bool) {
+	masked := maskLinks(line)
+	var tags []string
+	rest := []byte(masked)
+	for _, m := range tagRegex.FindAllStringSubmatchIndex(masked, -1) {
+		if m[0] > 0 && !unicode.IsSpace(rune(masked[m[0]-1])) {
+			continue
+		}
+		tag := masked[m[2]:m[3]]
+		if strings.TrimFunc(tag, unicode.IsDigit) == "" {
+			continue
+		}
+		tags = append(tags, tag)
+		for i := m[0]; i < m[1]; i++ {
+			rest[i] = ' '
+		}
+	}
+	return tags, len(tags) > 0 && strings.TrimSpace(string(rest)) == ""
+}
+
+// maskLinks blanks out the inline code, links and URLs of a line, keeping
+// its length
+func maskLinks(line string) string {
+	for _, re := range []*regexp.Regexp{inlineCodeRegex, wikiLinkRegex, markdownLinkRegex, bareURLRegex} {
+		line = re.ReplaceAllStringFunc(line, blankOut)
+	}
+	return line
+}
+
+// blankOut returns as many spaces as s has bytes
+func blankOut(s string) string {
+	return strings.Repeat(" ", len(s))
+}
+
+// wikiLinksRule finds markdown links to files in the vault, which should
+// be wikilinks
+var wikiLinksRule = lintRule{
+	id:          "wiki-links",
+	severity:    LintWarning,
+	description: "Links to notes and attachments are wikilinks, [[Note]], not markdown links, [Note](Note.md)",
+	options:     map[string]any{"include_embeds": true}, // Also check ![image](image.png)
+	check: func(note *lintNote, opts lintOptions) []lintIssue {
+		var issues []lintIssue
+		for i, line := range note.lines {
+			if !note.prose(i + 1) {
+				continue
+			}
+			for _, link := range markdownLinks(note, line, opts.bool("include_embeds")) {
+				message := fmt.Sprintf("Markdown link %s", link.text)
+				if link.wiki != "" {
+					message += " should be " + link.wiki
+				} else {
+					message += " should be a wikilink"
+				}
+				issues = append(issues, lintIssue{line: i + 1, message: message, fixable: link.wiki != ""})
+			}
+		}
+		return issues
+	},
+	fix: func(note *lintNote, opts lintOptions) (string, bool) {
+		lines := slices.Clone(note.lines)
+		for i, line := range lines {
+			if !note.prose(i + 1) {
+				continue
+			}
+			links := markdownLinks(note, line, opts.bool("include_embeds"))
+			// Replace from the end so earlier offsets stay valid
+			for _, link := range slices.Backward(links) {
+				if link.wiki != "" {
+					line = line[:link.start] + link.wiki + line[link.end:]
+				}
+			}
+			lines[i] = line
+		}
+		return strings.Join(lines, "\n"), true
+	},
+}
+
+// markdownLink is a markdown link to a vault file within a line
+type markdownLink struct {
+	start, end int    // Byte offsets of the link in the line
+	text       string // The link as written
+	wiki       string // The same link as a wikilink, empty when it cannot be one
+}
+
+// markdownLinks returns the markdown links in line to files in the vault,
+// or to files missing from it, with their wikilink forms. URLs and links
+// within the note are left out, and so are embeds unless embeds is set.
+func markdownLinks(note *lintNote, line string, embeds bool) []markdownLink {
+	masked := wikiLinkRegex.ReplaceAllStringFunc(inlineCodeRegex.ReplaceAllStringFunc(line, blankOut), blankOut)
+
+	var links []markdownLink
+	for _, m := range markdownLinkRegex.FindAllStringSubmatchIndex(masked, -1) {
+		embed, display, dest := line[m[2]:m[3]] == "!", line[m[4]:m[5]], line[m[6]:m[7]]
+		if urlSchemeRegex.MatchString(dest) || strings.HasPrefix(dest, "#") || embed && !embeds {
+			continue
+		}
+		link := markdownLink{start: m[0], end: m[1], text: line[m[0]:m[1]]}
+		links = append(links, link)
+
+		if unescaped, err := url.PathUnescape(dest); err == nil {
+			dest = unescaped
+		}
+		target, heading, block := splitAnchor(dest)
+		resolved, ok := note.index.resolve(note.path, target)
+		if !ok || strings.ContainsAny(display, "|") {
+			continue
+		}
+		name := note.index.linkTo(note.path, resolved, path.Ext(target) != "" && path.Ext(resolved) != ".md")
+		wiki := name
+		switch {
+		case block != "":
+			wiki += "#^" + block
+		case heading != "":
+			wiki += "#" + heading
+		}
+		if display != "" && display != name {
+			wiki += "|" + display
+		}
+		if embed {
+			links[len(links)-1].wiki = "![[" + wiki + "]]"
+		} else {
+			links[len(links)-1].wiki = "[[" + wiki + "]]"
+		}
+	}
+	return links
+}
+
+// singleH1Rule checks that a note has one level 1 heading, which by
+// default repeats the file name
+var singleH1Rule = lintRule{
+	id:          "single-h1",
+	severity:    LintError,
+	description: "A note has exactly one H1 heading, matching its file name",
+	options:     map[string]any{"match_filename": true},
+	check: func(note *lintNote, opts lintOptions) []lintIssue {
+		name := noteName(note.path)
+		var h1 []Heading
+		for _, h := range note.headings {
+			if h.Level == 1 {
+				h1 = append(h1, h)
+			}
+		}
+		if len(h1) == 0 {
+			return []lintIssue{{message: fmt.Sprintf("Note has no H1 heading; expected # %s", name), fixable: true}}
+		}
+
+		var issues []lintIssue
+		if opts.bool("match_filename") && norm.NFC.String(h1[0].Text) != name {
+			// Only # headings are rewritten; a setext heading may span lines
+			atx := headingRegex.MatchString(strings.TrimSuffix(note.lines[h1[0].Line-1], "\r"))
+			issues = append(issues, lintIssue{line: h1[0].Line, message: fmt.Sprintf("H1 %q does not match the file name %q", h1[0].Text, name), fixable: atx})
+		}
+		for _, h := range h1[1:] {
+			issues = append(issues, lintIssue{line: h.Line, message: fmt.Sprintf("Second H1 heading %q; the first is on line %d", h.Text, h1[0].Line)})
+		}
+		return issues
+	},
+	fix: func(note *lintNote, opts lintOptions) (string, bool) {
+		name := noteName(note.path)
+		lines := slices.Clone(note.lines)
+		i := slices.IndexFunc(note.headings, func(h Heading) bool { return h.Level == 1 })
+		switch {
+		case i < 0:
+			// Insert the heading at the top of the body
+			add := []string{"# " + name}
+			if note.body < len(lines) && strings.TrimSpace(lines[note.body]) != "" {
+				add = append(add, "")
+			}
+			lines = slices.Insert(lines, note.body, add...)
+		case opts.bool("match_filename"):
+			m := headingRegex.FindStringSubmatch(strings.TrimSuffix(lines[note.headings[i].Line-1], "\r"))
+			if m == nil {
+				return note.content, false
+			}
+			lines[note.headings[i].Line-1] = m[1] + " " + name
+		default:
+			return note.content, false
+		}
+		return strings.Join(lines, "\n"), true
+	},
+}
+
+// noteName returns the file name of the note at p without .md, as an H1
+// repeating it would read
+func noteName(p string) string {
+	return norm.NFC.String(strings.TrimSuffix(path.Base(p), path.Ext(p)))
+}
+
+// headingIncrementRule checks that each heading is at most one level
+// below the one before it
+var headingIncrementRule = lintRule{
+	id:          "heading-increment",
+	severity:    LintWarning,
+	description: "Heading levels go down one at a time, e.g. ## is followed by ### and not ####",
+	check: func(note *lintNote, _ lintOptions) []lintIssue {
+		var issues []lintIssue
+		for i, h := range note.headings[min(1, len(note.headings)):] {
+			if prev := note.headings[i]; h.Level > prev.Level+1 {
+				issues = append(issues, lintIssue{
+					line:    h.Line,
+					message: fmt.Sprintf("H%d %q follows an H%d, skipping a level", h.Level, h.Text, prev.Level),
+					fixable: true,
+				})
+			}
+		}
+		return issues
+	},
+	// Each heading is raised to one level below the heading before it, as
+	// fixed, so nested headings keep their relative levels
+	fix: func(note *lintNote, _ lintOptions) (string, bool) {
+		lines := slices.Clone(note.lines)
+		changed := false
+		prev := 0
+		for _, h := range note.headings {
+			level := h.Level
+			if prev > 0 {
+				level = min(level, prev+1)
+			}
+			prev = level
+			if level == h.Level {
+				continue
+			}
+			// Only ATX headings skip levels: setext ones are H1 or H2
+			line := lines[h.Line-1]
+			lines[h.Line-1] = line[:strings.Index(line, "#")] + strings.Repeat("#", level) + strings.TrimLeft(line, " \t")[h.Level:]
+			changed = true
+		}
+		return strings.Join(lines, "\n"), changed
+	},
+}
+
+// duplicateHeadingsRule finds headings with the same text in one note,
+// which links to the heading cannot tell apart
+var duplicateHeadingsRule = lintRule{
+	id:          "no-duplicate-headings",
+	severity:    LintWarning,
+	description: "Headings in a note are unique, so links to them are not ambiguous",
+	options:     map[string]any{"siblings_only": false}, // Allow the same heading under different parents
+	check: func(note *lintNote, opts lintOptions) []lintIssue {
+		type key struct {
+			parent int // Line of the parent heading with siblings_only, 0 otherwise
+			text   string
+		}
+		seen := make(map[key]int) // First line of each heading
+		var parents []Heading     // Enclosing headings of the current one
+
+		var issues []lintIssue
+		for _, h := range note.headings {
+			for len(parents) > 0 && parents[len(parents)-1].Level >= h.Level {
+				parents = parents[:len(parents)-1]
+			}
+			k := key{text: foldText(h.Text)}
+			if opts.bool("siblings_only") && len(parents) > 0 {
+				k.parent = parents[len(parents)-1].Line
+			}
+			parents = append(parents, h)
+
+			if first, ok := seen[k]; ok {
+				issues = append(issues, lintIssue{line: h.Line, message: fmt.Sprintf("Heading %q repeats the heading on line %d", h.Text, first)})
+				continue
+			}
+			seen[k] = h.Line
+		}
+		return issues
+	},
+}
@@ -0,0 +1,180 @@
+package vault
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+// lintCase is a note checked by one rule
+type lintCase struct {
+	name    string
+	content string
+	options map[string]any // Over the rule's defaults
+	lines   []int          // Lines of the issues found, in order
+	fixable []bool         // Whether each issue is fixable, none when nil
+	fixed   string         // Content after the fix, the same when empty
+}
+
+// testLintRule runs the cases against rule for the note at notePath, in a
+// vault holding the files at paths
+func testLintRule(t *testing.T, rule *lintRule, notePath string, paths []string, cases []lintCase) {
+	t.Helper()
+	index := (&fileIndex{paths: map[string]string{}, byName: map[string][]string{}}).withFiles(append(paths, notePath)...)
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := lintOptions(maps.Clone(rule.options))
+			maps.Copy(opts, tt.options)
+			note := newLintNote(notePath, tt.content, index)
+
+			issues := rule.check(note, opts)
+			var lines []int
+			var fixable []bool
+			for _, issue := range issues {
+				lines = append(lines, issue.line)
+				fixable = append(fixable, issue.fixable)
+			}
+			if !slices.Equal(lines, tt.lines) || tt.fixable != nil && !slices.Equal(fixable, tt.fixable) {
+				t.Errorf("check() = %+v, want lines %v fixable %v", issues, tt.lines, tt.fixable)
+			}
+
+			if rule.fix == nil || !slices.Contains(fixable, true) {
+				return
+			}
+			want := tt.fixed
+			if want == "" {
+				want = tt.content
+			}
+			if got, _ := rule.fix(note, opts); got != want {
+				t.Errorf("fix() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestTrailingWhitespaceRule(t *testing.T) {
+	testLintRule(t, &trailingWhitespaceRule, "Note.md", nil, []lintCase{
+		{name: "clean", content: "# Note\n\nText.\n"},
+		{
+			name:    "spaces and tabs",
+			content: "# Note \n\nText.\t\n  \n```\ncode  \n```\n",
+			lines:   []int{1, 3, 4, 6},
+			fixed:   "# Note\n\nText.\n\n```\ncode\n```\n",
+		},
+		{name: "line ending", content: "Text. \r\nMore\r\n", lines: []int{1}, fixed: "Text.\r\nMore\r\n"},
+		{
+			name:    "line breaks allowed",
+			content: "First  \nSecond   \n",
+			options: map[string]any{"allow_line_breaks": true},
+			lines:   []int{2},
+			fixed:   "First  \nSecond\n",
+		},
+	})
+}
+
+func TestFrontmatterTagsRule(t *testing.T) {
+	testLintRule(t, &frontmatterTagsRule, "Note.md", nil, []lintCase{
+		{name: "frontmatter only", content: "---\ntags: [project]\n---\n# Note\n\nIssue #12, see [[Note#Heading]] and `#code`.\n"},
+		{
+			name:    "tag line",
+			content: "---\ntags: [project]\n---\n# Note\n\n#active #Work\n\nText.\n",
+			lines:   []int{6, 6},
+			fixed:   "---\ntags:\n  - project\n  - active\n  - Work\n---\n# Note\n\nText.\n",
+		},
+		{
+			name:    "no frontmatter",
+			content: "#draft\n# Note\n",
+			lines:   []int{1},
+			fixed:   "---\ntags:\n  - draft\n---\n# Note\n",
+		},
+		{
+			name:    "tags in a sentence",
+			content: "# Note\n\nReading about #golang today.\n```\n#not-a-tag\n```\n",
+			lines:   []int{3},
+			fixable: []bool{false},
+		},
+	})
+}
+
+func TestWikiLinksRule(t *testing.T) {
+	paths := []string{"Projects/Plan.md", "Meetings/Kickoff.md", "images/chart.png"}
+	testLintRule(t, &wikiLinksRule, "Projects/Apollo.md", paths, []lintCase{
+		{name: "wikilinks", content: "See [[Plan]], [site](https://example.com) and [top](#intro).\n"},
+		{
+			name:    "note links",
+			content: "See [Plan](Plan.md) and [the kickoff](../Meetings/Kickoff.md#Agenda%20items).\n",
+			lines:   []int{1, 1},
+			fixed:   "See [[Plan]] and [[Kickoff#Agenda items|the kickoff]].\n",
+		},
+		{
+			name:    "embed",
+			content: "![chart](../images/chart.png)\n",
+			lines:   []int{1},
+			fixed:   "![[chart.png|chart]]\n",
+		},
+		{
+			name:    "embeds left alone",
+			content: "![chart](../images/chart.png)\n",
+			options: map[string]any{"include_embeds": false},
+		},
+		{
+			name:    "missing target",
+			content: "[Gone](Gone.md)\n",
+			lines:   []int{1},
+			fixable: []bool{false},
+		},
+	})
+}
+
+func TestSingleH1Rule(t *testing.T) {
+	testLintRule(t, &singleH1Rule, "Notes/Weekly Review.md", nil, []lintCase{
+		{name: "matching", content: "---\ntitle: x\n---\n# Weekly Review\n\n## Wins\n"},
+		{
+			name:    "missing",
+			content: "---\ntitle: x\n---\nText.\n",
+			lines:   []int{0},
+			fixed:   "---\ntitle: x\n---\n# Weekly Review\n\nText.\n",
+		},
+		{
+			name:    "other text",
+			content: "# Review #\n\nText.\n",
+			lines:   []int{1},
+			fixed:   "# Weekly Review\n\nText.\n",
+		},
+		{name: "other text allowed", content: "# Review\n", options: map[string]any{"match_filename": false}},
+		{
+			name:    "two",
+			content: "# Weekly Review\n\n# Next week\n",
+			lines:   []int{3},
+			fixable: []bool{false},
+		},
+		{
+			name:    "setext",
+			content: "Review\n======\n",
+			lines:   []int{1},
+			fixable: []bool{false},
+		},
+	})
+}
+
+func TestHeadingIncrementRule(t *testing.T) {
+	testLintRule(t, &headingIncrementRule, "Note.md", nil, []lintCase{
+		{name: "steps", content: "## Start\n### Down\n# Up\n## Down\n"},
+		{
+			name:    "skips",
+			content: "# Note\n### Skipped\n##### Nested\n## Back\n```\n#### code\n```\n",
+			lines:   []int{2, 3},
+			fixed:   "# Note\n## Skipped\n### Nested\n## Back\n```\n#### code\n```\n",
+		},
+	})
+}
+
+func TestDuplicateHeadingsRule(t *testing.T) {
+	content := "# Note\n## 2024\n### Notes\n## 2025\n### notes\n## 2024\n"
+	testLintRule(t, &duplicateHeadingsRule, "Note.md", nil, []lintCase{
+		{name: "unique", content: "# Note\n## One\n## Two\n"},
+		{name: "anywhere", content: content, lines: []int{5, 6}, fixable: []bool{false, false}},
+		{name: "siblings", content: content, options: map[string]any{"siblings_only": true}, lines: []int{6}},
+	})
+}
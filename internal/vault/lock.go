@@ -0,0 +1,50 @@
+package vault
+
+import (
+	"hash/fnv"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// writeLockStripes is the number of mutexes note writes are spread over
+const writeLockStripes = 64
+
+// writeLocks serializes writes to the same note so concurrent tool calls
+// cannot interleave their existence checks, writes and cache updates
+// Paths are hashed onto a fixed set of mutexes, so unrelated notes
+// occasionally share one; memory stays bounded however many notes are
+// written. Keys are lowercased so notes differing only in case, which
+// are the same file on macOS and Windows, always share a mutex.
+type writeLocks struct {
+	stripes [writeLockStripes]sync.Mutex
+}
+
+// lock locks the mutexes of every full path and returns the function
+// unlocking them
+// Stripes are taken in index order, so calls locking several paths, such
+// as a move, cannot deadlock each other
+func (l *writeLocks) lock(fullPaths ...string) (unlock func()) {
+	stripes := make([]int, 0, len(fullPaths))
+	for _, fullPath := range fullPaths {
+		stripes = append(stripes, writeLockStripe(fullPath))
+	}
+	slices.Sort(stripes)
+	stripes = slices.Compact(stripes)
+
+	for _, i := range stripes {
+		l.stripes[i].Lock()
+	}
+	return func() {
+		for _, i := range slices.Backward(stripes) {
+			l.stripes[i].Unlock()
+		}
+	}
+}
+
+// writeLockStripe returns the index of the mutex guarding fullPath
+func writeLockStripe(fullPath string) int {
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(fullPath)))
+	return int(h.Sum32() % writeLockStripes)
+}
@@ -0,0 +1,361 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DataUsage is the disk use of one kind of server data
+type DataUsage struct {
+	Files  int       `json:"files"`
+	Bytes  int64     `json:"bytes"`
+	Oldest time.Time `json:"oldest,omitzero"` // Oldest entry, zero when there are none
+	Newest time.Time `json:"newest,omitzero"` // Newest entry, zero when there are none
+}
+
+// add counts a file of size bytes holding an entry from at
+func (u *DataUsage) add(size int64, at time.Time) {
+	u.Files++
+	u.Bytes += size
+	u.cover(at)
+}
+
+// cover widens the entry range to include at
+func (u *DataUsage) cover(at time.Time) {
+	if u.Oldest.IsZero() || at.Before(u.Oldest) {
+		u.Oldest = at
+	}
+	if at.After(u.Newest) {
+		u.Newest = at
+	}
+}
+
+// IndexUsage is the size of the in-memory search index
+type IndexUsage struct {
+	IndexStats
+	Missing int `json:"missing_notes"` // Indexed notes whose file no longer exists
+}
+
+// MaintenanceStatus reports the data the server keeps beside the notes
+type MaintenanceStatus struct {
+	Trash          DataUsage   `json:"trash"`            // Notes deleted or merged away, by when they were trashed
+	Backups        DataUsage   `json:"backups"`          // Earlier versions of notes, by when they were taken
+	BackupNotes    int         `json:"backup_notes"`     // Notes with at least one backup
+	BackupsPerNote int         `json:"backups_per_note"` // Versions kept per note, 0 when backups are disabled
+	Audit          DataUsage   `json:"audit_log"`        // The audit log and its rotated files, by entry time
+	Index          *IndexUsage `json:"index,omitempty"`  // Set when the search index is enabled
+}
+
+// PruneBackupsOptions selects the backups PruneBackups removes: the
+// versions of each note beyond the newest Keep, of those only the ones
+// taken before Before when it is set. At least one must be set.
+type PruneBackupsOptions struct {
+	Keep   int       // Newest versions kept per note, 0 to select by age only
+	Before time.Time // Only versions taken before this time, zero for any age
+	DryRun bool      // Report what would be removed without removing it
+}
+
+// EmptyTrashOptions selects the trashed notes EmptyTrash removes
+type EmptyTrashOptions struct {
+	Before time.Time // Only notes trashed before this time, zero for all
+	DryRun bool      // Report what would be removed without removing it
+}
+
+// CleanupItem is a file a cleanup removed, or failed to remove
+type CleanupItem struct {
+	Path  string `json:"path"` // Vault-relative
+	Bytes int64  `json:"bytes"`
+	Error string `json:"error,omitempty"`
+}
+
+// CleanupResult reports what a cleanup removed. Files that could not be
+// removed are listed in Failed without stopping the cleanup.
+type CleanupResult struct {
+	DryRun    bool          `json:"dry_run,omitempty"`
+	Removed   []CleanupItem `json:"removed"` // Files that would be removed on a dry run
+	Failed    []CleanupItem `json:"failed,omitempty"`
+	Reclaimed int64         `json:"bytes_reclaimed"`
+}
+
+// IndexCompaction reports the notes CompactIndex dropped from the index
+type IndexCompaction struct {
+	DryRun   bool     `json:"dry_run,omitempty"`
+	Removed  []string `json:"removed"`  // Vault-relative paths of notes no longer on disk
+	Postings int      `json:"postings"` // Word-note pairs freed
+}
+
+// trashPath returns the directory holding trashed notes
+func (v *vault) trashPath() string {
+	return filepath.Join(v.basePath, dataDir, trashDir)
+}
+
+// removeData deletes the file or empty directory at path, refusing
+// anything that is not strictly inside root once symlinks are resolved.
+// Cleanups delete only through it.
+func removeData(root, path string) error {
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return err
+	}
+	parent, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	target := filepath.Join(parent, filepath.Base(path))
+	if target == realRoot || !isWithin(target, realRoot) {
+		return fmt.Errorf("%w: %s is outside %s", ErrOutsideDataDir, path, root)
+	}
+	return os.Remove(target)
+}
+
+// dataFile is a file found in one of the server's data directories
+type dataFile struct {
+	path string // Full path
+	size int64
+	at   time.Time // When its entry was made
+}
+
+// trashedFiles returns the files in the trash, each dated by the
+// directory it was trashed into, and those directories
+func (v *vault) trashedFiles(ctx context.Context) ([]dataFile, []string, error) {
+	if err := v.checkAvailable(); err != nil {
+		return nil, nil, err
+	}
+	root := v.trashPath()
+	stamps, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read the trash: %w", err)
+	}
+
+	var files []dataFile
+	var dirs []string
+	for _, stamp := range stamps {
+		trashed, err := time.Parse(versionTimeFormat, stamp.Name())
+		if !stamp.IsDir() || err != nil {
+			continue // Not made by trash
+		}
+		err = filepath.WalkDir(filepath.Join(root, stamp.Name()), func(path string, d fs.DirEntry, err error) error {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			if err != nil {
+				return nil // Skip what cannot be read
+			}
+			if d.IsDir() {
+				dirs = append(dirs, path)
+				return nil
+			}
+			if info, err := d.Info(); err == nil {
+				files = append(files, dataFile{path: path, size: info.Size(), at: trashed})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return files, dirs, nil
+}
+
+// backupFiles returns the versions of every note with backups, by the
+// directory holding them, newest first
+func (v *vault) backupFiles(ctx context.Context) (map[string][]dataFile, error) {
+	if err := v.checkAvailable(); err != nil {
+		return nil, err
+	}
+	root := filepath.Join(v.basePath, dataDir, backupDir)
+	notes := make(map[string][]dataFile)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		versions, err := readVersions(path)
+		if err != nil {
+			return nil
+		}
+		for _, version := range versions {
+			notes[path] = append(notes[path], dataFile{path: filepath.Join(path, version.ID+".md"), size: version.Size, at: version.Created})
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return notes, nil
+}
+
+// missingIndexed returns the indexed notes whose file no longer exists,
+// with the postings each holds
+func (v *vault) missingIndexed() map[string]int {
+	missing := make(map[string]int)
+	for path, terms := range v.index.termCounts() {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			missing[path] = terms
+		}
+	}
+	return missing
+}
+
+// MaintenanceStatus reports the disk use of the trash, backups and audit
+// log, and the size of the search index
+func (v *vault) MaintenanceStatus(ctx context.Context) (MaintenanceStatus, error) {
+	status := MaintenanceStatus{BackupsPerNote: max(v.backupVersions, 0)}
+
+	trashed, _, err := v.trashedFiles(ctx)
+	if err != nil {
+		return MaintenanceStatus{}, err
+	}
+	for _, file := range trashed {
+		status.Trash.add(file.size, file.at)
+	}
+
+	backups, err := v.backupFiles(ctx)
+	if err != nil {
+		return MaintenanceStatus{}, err
+	}
+	for _, versions := range backups {
+		status.BackupNotes++
+		for _, version := range versions {
+			status.Backups.add(version.size, version.at)
+		}
+	}
+
+	for i := 0; i <= auditKeep; i++ {
+		file := v.audit.rotated(i)
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		status.Audit.Files++
+		status.Audit.Bytes += info.Size()
+		entries, err := readAuditFile(file)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			status.Audit.cover(entry.Time)
+		}
+	}
+
+	if v.index != nil {
+		status.Index = &IndexUsage{IndexStats: v.index.stats(), Missing: len(v.missingIndexed())}
+	}
+	return status, ctx.Err()
+}
+
+// PruneBackups removes the backups selected by opts. Each note's
+// versions are pruned under its write lock.
+func (v *vault) PruneBackups(ctx context.Context, opts PruneBackupsOptions) (CleanupResult, error) {
+	if opts.Keep < 0 {
+		return CleanupResult{}, fmt.Errorf("%w: keep %d is negative", ErrInvalidCleanup, opts.Keep)
+	}
+	if opts.Keep == 0 && opts.Before.IsZero() {
+		return CleanupResult{}, fmt.Errorf("%w: set keep, older_than or both", ErrInvalidCleanup)
+	}
+
+	backups, err := v.backupFiles(ctx)
+	if err != nil {
+		return CleanupResult{}, err
+	}
+	root := filepath.Join(v.basePath, dataDir, backupDir)
+	result := CleanupResult{DryRun: opts.DryRun, Removed: []CleanupItem{}}
+	for _, dir := range slices.Sorted(maps.Keys(backups)) {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		var selected []dataFile
+		for i, version := range backups[dir] {
+			if i >= opts.Keep && (opts.Before.IsZero() || version.at.Before(opts.Before)) {
+				selected = append(selected, version)
+			}
+		}
+		if len(selected) == 0 {
+			continue
+		}
+
+		rel, _ := filepath.Rel(root, dir)
+		unlock := v.writeLocks.lock(filepath.Join(v.basePath, rel))
+		v.removeFiles(root, selected, &result)
+		if len(selected) == len(backups[dir]) && !opts.DryRun {
+			_ = removeData(root, dir) // Only succeeds once the directory is empty
+		}
+		unlock()
+	}
+	return result, nil
+}
+
+// EmptyTrash removes the trashed notes selected by opts
+func (v *vault) EmptyTrash(ctx context.Context, opts EmptyTrashOptions) (CleanupResult, error) {
+	trashed, dirs, err := v.trashedFiles(ctx)
+	if err != nil {
+		return CleanupResult{}, err
+	}
+	root := v.trashPath()
+	result := CleanupResult{DryRun: opts.DryRun, Removed: []CleanupItem{}}
+	var selected []dataFile
+	for _, file := range trashed {
+		if opts.Before.IsZero() || file.at.Before(opts.Before) {
+			selected = append(selected, file)
+		}
+	}
+	v.removeFiles(root, selected, &result)
+
+	// Directories left empty go too, deepest first
+	if !opts.DryRun {
+		for _, dir := range slices.Backward(dirs) {
+			_ = removeData(root, dir) // Only succeeds once the directory is empty
+		}
+	}
+	return result, ctx.Err()
+}
+
+// removeFiles removes files inside root, recording each in result; on a
+// dry run it only records them
+func (v *vault) removeFiles(root string, files []dataFile, result *CleanupResult) {
+	for _, file := range files {
+		item := CleanupItem{Path: v.relPath(file.path), Bytes: file.size}
+		if !result.DryRun {
+			if err := removeData(root, file.path); err != nil {
+				item.Error = strings.ReplaceAll(err.Error(), v.basePath+string(filepath.Separator), "")
+				result.Failed = append(result.Failed, item)
+				continue
+			}
+		}
+		result.Removed = append(result.Removed, item)
+		result.Reclaimed += item.Bytes
+	}
+}
+
+// CompactIndex drops the notes whose file no longer exists from the
+// search index
+func (v *vault) CompactIndex(ctx context.Context, dryRun bool) (IndexCompaction, error) {
+	if v.index == nil {
+		return IndexCompaction{}, ErrIndexDisabled
+	}
+	if err := v.checkAvailable(); err != nil {
+		return IndexCompaction{}, err // Every note would look deleted
+	}
+	missing := v.missingIndexed()
+	result := IndexCompaction{DryRun: dryRun, Removed: []string{}}
+	for _, path := range slices.Sorted(maps.Keys(missing)) {
+		if !dryRun {
+			v.index.remove(path)
+		}
+		result.Removed = append(result.Removed, v.relPath(path))
+		result.Postings += missing[path]
+	}
+	return result, ctx.Err()
+}
@@ -0,0 +1,160 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// cleanupPaths returns the paths of items, in order
+func cleanupPaths(items []CleanupItem) []string {
+	paths := []string{}
+	for _, item := range items {
+		paths = append(paths, item.Path)
+	}
+	return paths
+}
+
+func TestMaintenance(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	now := time.Now().UTC()
+	stamp := func(age time.Duration) string { return now.Add(-age).Format(versionTimeFormat) }
+	day := 24 * time.Hour
+	writeFiles(t, tmpDir, map[string]string{
+		"a.md": "Current",
+		filepath.Join(dataDir, backupDir, "a.md", stamp(3*day)+".md"):     "Three days ago",
+		filepath.Join(dataDir, backupDir, "a.md", stamp(2*day)+".md"):     "Two days ago",
+		filepath.Join(dataDir, backupDir, "a.md", stamp(time.Hour)+".md"): "An hour ago",
+		filepath.Join(dataDir, backupDir, "b.md", stamp(3*day)+".md"):     "Only version",
+		filepath.Join(dataDir, trashDir, stamp(10*day), "Old", "x.md"):    "Trashed long ago",
+		filepath.Join(dataDir, trashDir, stamp(time.Hour), "y.md"):        "Trashed recently",
+		filepath.Join(dataDir, auditFile):                                 `{"time":"2024-03-01T10:00:00Z","op":"create","path":"a.md"}` + "\n",
+	})
+	v, err := NewVault(tmpDir, WithBackups(3))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+
+	status, err := v.MaintenanceStatus(ctx)
+	if err != nil {
+		t.Fatalf("MaintenanceStatus() error = %v", err)
+	}
+	if status.Backups.Files != 4 || status.BackupNotes != 2 || status.Trash.Files != 2 || status.Trash.Bytes != 32 || status.Audit.Files != 1 || status.Index != nil {
+		t.Errorf("MaintenanceStatus() = %+v", status)
+	}
+	if !status.Audit.Oldest.Equal(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)) || status.Trash.Oldest.After(now.Add(-10*day)) {
+		t.Errorf("MaintenanceStatus() oldest entries = audit %v, trash %v", status.Audit.Oldest, status.Trash.Oldest)
+	}
+
+	if _, err := v.PruneBackups(ctx, PruneBackupsOptions{}); !errors.Is(err, ErrInvalidCleanup) {
+		t.Errorf("PruneBackups() without keep or age error = %v, want ErrInvalidCleanup", err)
+	}
+
+	// A dry run removes nothing; keeping one version of those older than
+	// a day spares the newest of each note
+	opts := PruneBackupsOptions{Keep: 1, Before: now.Add(-day), DryRun: true}
+	result, err := v.PruneBackups(ctx, opts)
+	want := []string{filepath.ToSlash(filepath.Join(dataDir, backupDir, "a.md", stamp(2*day)+".md")), filepath.ToSlash(filepath.Join(dataDir, backupDir, "a.md", stamp(3*day)+".md"))}
+	if err != nil || !slices.Equal(cleanupPaths(result.Removed), want) || result.Reclaimed != 26 || !result.DryRun {
+		t.Fatalf("PruneBackups() dry run = %+v, %v; want %v", result, err, want)
+	}
+	if versions, _ := v.ListVersions(ctx, "a.md"); len(versions) != 3 {
+		t.Errorf("Dry run left %d versions, want 3", len(versions))
+	}
+	opts.DryRun = false
+	if result, err = v.PruneBackups(ctx, opts); err != nil || len(result.Removed) != 2 || len(result.Failed) != 0 {
+		t.Errorf("PruneBackups() = %+v, %v", result, err)
+	}
+	if versions, _ := v.ListVersions(ctx, "a.md"); len(versions) != 1 {
+		t.Errorf("PruneBackups() left %d versions of a.md, want 1", len(versions))
+	}
+
+	// Recent deletions survive, and emptied trash folders go
+	result, err = v.EmptyTrash(ctx, EmptyTrashOptions{Before: now.Add(-day)})
+	if err != nil || len(result.Removed) != 1 || result.Reclaimed != 16 {
+		t.Errorf("EmptyTrash() = %+v, %v", result, err)
+	}
+	if exists(tmpDir, filepath.Join(dataDir, trashDir, stamp(10*day))) || !exists(tmpDir, filepath.Join(dataDir, trashDir, stamp(time.Hour), "y.md")) {
+		t.Error("EmptyTrash() removed the wrong notes or left an empty folder")
+	}
+
+	if _, err := v.CompactIndex(ctx, false); !errors.Is(err, ErrIndexDisabled) {
+		t.Errorf("CompactIndex() without an index error = %v, want ErrIndexDisabled", err)
+	}
+}
+
+func TestCompactIndex(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{"a.md": "Kept", "b.md": "Deleted behind the server's back"})
+	v, err := NewVault(tmpDir, WithSearchIndex())
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	if _, err := v.List(ctx, ListOptions{Recursive: true}); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if err := os.Remove(filepath.Join(tmpDir, "b.md")); err != nil {
+		t.Fatalf("Failed to delete note: %v", err)
+	}
+
+	result, err := v.CompactIndex(ctx, true)
+	if err != nil || !slices.Equal(result.Removed, []string{"b.md"}) || result.Postings == 0 {
+		t.Fatalf("CompactIndex() dry run = %+v, %v", result, err)
+	}
+	if status, _ := v.MaintenanceStatus(ctx); status.Index == nil || status.Index.Notes != 2 || status.Index.Missing != 1 {
+		t.Errorf("Index after a dry run = %+v, want b.md still indexed", status.Index)
+	}
+	if _, err := v.CompactIndex(ctx, false); err != nil {
+		t.Fatalf("CompactIndex() error = %v", err)
+	}
+	if status, _ := v.MaintenanceStatus(ctx); status.Index.Notes != 1 || status.Index.Missing != 0 {
+		t.Errorf("Index after compaction = %+v, want only a.md", status.Index)
+	}
+}
+
+func TestCleanupStaysInDataDir(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	outside := t.TempDir()
+	writeFiles(t, outside, map[string]string{"victim.md": "Not server data"})
+	writeFiles(t, tmpDir, map[string]string{"note.md": "A note"})
+	trashed := filepath.Join(tmpDir, dataDir, trashDir, time.Now().UTC().Add(-time.Hour).Format(versionTimeFormat))
+	if err := os.MkdirAll(trashed, 0755); err != nil {
+		t.Fatalf("Failed to create trash: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(trashed, "escape")); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+
+	// Paths outside the directory, or reaching out of it through a
+	// link, are refused
+	root := filepath.Join(tmpDir, dataDir, trashDir)
+	for _, path := range []string{
+		filepath.Join(tmpDir, "note.md"),
+		filepath.Join(trashed, "..", "..", "..", "note.md"),
+		filepath.Join(trashed, "escape", "victim.md"),
+		root,
+	} {
+		if err := removeData(root, path); !errors.Is(err, ErrOutsideDataDir) {
+			t.Errorf("removeData(%s) error = %v, want ErrOutsideDataDir", path, err)
+		}
+	}
+
+	// Emptying the trash removes the link, not what it points at
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	if _, err := v.EmptyTrash(ctx, EmptyTrashOptions{}); err != nil {
+		t.Fatalf("EmptyTrash() error = %v", err)
+	}
+	if !exists(outside, "victim.md") || !exists(tmpDir, "note.md") {
+		t.Error("EmptyTrash() deleted a file outside the trash")
+	}
+}
@@ -0,0 +1,612 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// trashDir holds notes removed by the server, below the data directory
+const trashDir = "trash"
+
+// MergeStrategy selects how MergeNotes combines two notes
+type MergeStrategy string
+
+// Merge strategies
+const (
+	MergeAppend   MergeStrategy = "append"   // Source under a heading at the end of the target
+	MergePrepend  MergeStrategy = "prepend"  // Source under a heading at the start of the target
+	MergeSections MergeStrategy = "sections" // Source sections into the target sections of the same name
+)
+
+// MergeOptions describes a merge of one note into another
+type MergeOptions struct {
+	Source     string        // Note merged away
+	Target     string        // Note receiving the source's content
+	Strategy   MergeStrategy // How the contents combine, append when empty
+	KeepSource bool          // Leave the source in place instead of moving it to the trash
+	DryRun     bool          // Plan the merge without writing anything
+}
+
+// MergeResult reports the outcome of MergeNotes
+type MergeResult struct {
+	Source       string        `json:"source"`
+	Target       string        `json:"target"`
+	Strategy     MergeStrategy `json:"strategy"`
+	DryRun       bool          `json:"dry_run,omitempty"`
+	Content      string        `json:"content,omitempty"`       // Merged target content, on dry runs
+	Trashed      string        `json:"trashed,omitempty"`       // Where the source was moved, vault-relative
+	LinksUpdated int           `json:"links_updated"`           // Links to the source pointed at the target
+	UpdatedNotes []string      `json:"updated_notes,omitempty"` // Notes whose links were rewritten
+	NotUpdated   []string      `json:"not_updated,omitempty"`   // Notes whose links could not be rewritten
+	Warnings     []string      `json:"warnings,omitempty"`
+}
+
+// ParseMergeStrategy validates a merge strategy, defaulting to append
+func ParseMergeStrategy(s string) (MergeStrategy, error) {
+	switch strategy := MergeStrategy(strings.ToLower(strings.TrimSpace(s))); strategy {
+	case "":
+		return MergeAppend, nil
+	case MergeAppend, MergePrepend, MergeSections:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("unknown merge strategy %q (want append, prepend or sections)", s)
+	}
+}
+
+// MergeNotes combines the source note into the target, unions their tags
+// and aliases, points links to the source at the target and moves the
+// source to the trash. The target is backed up before it is rewritten.
+func (v *vault) MergeNotes(ctx context.Context, opts MergeOptions) (MergeResult, error) {
+	strategy, err := ParseMergeStrategy(string(opts.Strategy))
+	if err != nil {
+		return MergeResult{}, err
+	}
+
+	sourceFull, err := v.validatePath(opts.Source)
+	if err != nil {
+		return MergeResult{}, err
+	}
+	targetFull, err := v.validatePath(opts.Target)
+	if err != nil {
+		return MergeResult{}, err
+	}
+
+	sourceStat, err := statNote(sourceFull, opts.Source)
+	if err != nil {
+		return MergeResult{}, err
+	}
+	targetStat, err := statNote(targetFull, opts.Target)
+	if err != nil {
+		return MergeResult{}, err
+	}
+	if os.SameFile(sourceStat, targetStat) {
+		return MergeResult{}, fmt.Errorf("%w: cannot merge a note into itself", ErrInvalidPath)
+	}
+
+	source, target := v.relPath(sourceFull), v.relPath(targetFull)
+	result := MergeResult{Source: source, Target: target, Strategy: strategy, DryRun: opts.DryRun}
+
+	index, err := v.buildFileIndex(ctx)
+	if err != nil {
+		return MergeResult{}, err
+	}
+	merge, err := v.planMerge(index, sourceFull, targetFull, sourceStat.ModTime(), targetStat.ModTime(), strategy)
+	if err != nil {
+		return MergeResult{}, err
+	}
+	result.Warnings = merge.warnings
+	result.LinksUpdated = merge.links
+
+	// Other notes linking to the source, by full path
+	var linking []string
+	var mu sync.Mutex
+	_, err = v.walkNotes(ctx, ListOptions{Recursive: true}, func(file noteFile, entry CacheEntry) bool {
+		if file.fullPath == sourceFull || file.fullPath == targetFull {
+			return false
+		}
+		if _, changed := relinkMerged(index, file.relPath, file.relPath, source, target, entry.Content); changed > 0 {
+			mu.Lock()
+			linking = append(linking, file.fullPath)
+			result.LinksUpdated += changed
+			mu.Unlock()
+		}
+		return false
+	})
+	if err != nil {
+		return MergeResult{}, err
+	}
+	slices.Sort(linking)
+
+	if opts.DryRun {
+		result.Content = merge.content
+		for _, notePath := range linking {
+			result.UpdatedNotes = append(result.UpdatedNotes, v.relPath(notePath))
+		}
+		return result, nil
+	}
+
+	// Every note written must be writable; the source only when it moves
+	lockPaths := append([]string{targetFull}, linking...)
+	if !opts.KeepSource {
+		lockPaths = append(lockPaths, sourceFull)
+	}
+	if err := v.checkWritable(lockPaths...); err != nil {
+		return MergeResult{}, err
+	}
+	if err := v.checkLeases(ctx, lockPaths...); err != nil {
+		return MergeResult{}, err
+	}
+	if err := v.checkAudit(); err != nil {
+		return MergeResult{}, err
+	}
+
+	unlock := v.writeLocks.lock(append(lockPaths, sourceFull)...)
+	defer unlock()
+
+	// Plan again under the lock in case either note changed meanwhile
+	if sourceStat, err = statNote(sourceFull, opts.Source); err != nil {
+		return MergeResult{}, err
+	}
+	if targetStat, err = statNote(targetFull, opts.Target); err != nil {
+		return MergeResult{}, err
+	}
+	merge, err = v.planMerge(index, sourceFull, targetFull, sourceStat.ModTime(), targetStat.ModTime(), strategy)
+	if err != nil {
+		return MergeResult{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return MergeResult{}, err
+	}
+
+	// Once the target is written the rest is completed regardless
+	written, err := v.writeNote(targetFull, merge.content)
+	if err != nil {
+		return MergeResult{}, err
+	}
+	audit := []AuditEntry{written}
+	result.LinksUpdated, result.Warnings = merge.links, merge.warnings
+
+	if !opts.KeepSource {
+		trashedEntry := AuditEntry{Op: EditDelete, Path: source}
+		if entry, err := v.loadEntry(sourceFull, sourceStat.ModTime()); err == nil {
+			trashedEntry.PrevHash = entry.ContentHash
+		}
+		trashed, err := v.trash(sourceFull)
+		if err != nil {
+			v.logger.Warn("moving merged note to the trash failed", "path", source, "error", err)
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s was merged but could not be moved to the trash", source))
+		}
+		result.Trashed = trashed
+		if err == nil {
+			v.dropAnnotations(source)
+			v.dropLease(source)
+			v.dropPin(source)
+			v.paths.invalidate()
+			audit = append(audit, trashedEntry)
+		}
+	}
+
+	for _, notePath := range linking {
+		relPath := v.relPath(notePath)
+		changed, written, err := v.relinkMergedWritten(index, notePath, source, target)
+		if err != nil {
+			v.logger.Warn("updating links failed", "path", relPath, "error", err)
+			result.NotUpdated = append(result.NotUpdated, relPath)
+			continue
+		}
+		if changed > 0 {
+			result.LinksUpdated += changed
+			result.UpdatedNotes = append(result.UpdatedNotes, relPath)
+			audit = append(audit, written)
+		}
+	}
+
+	return result, v.record(ctx, audit...)
+}
+
+// statNote stats the note at fullPath, requested as notePath
+func statNote(fullPath, notePath string) (os.FileInfo, error) {
+	stat, err := os.Stat(fullPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrNoteNotFound, notePath)
+		}
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+	return stat, nil
+}
+
+// mergePlan is the merged target content and what went into it
+type mergePlan struct {
+	content  string
+	links    int // Links to the source in the merged content now pointing at the target
+	warnings []string
+}
+
+// planMerge builds the merged content of the target
+func (v *vault) planMerge(index *fileIndex, sourceFull, targetFull string, sourceMtime, targetMtime time.Time, strategy MergeStrategy) (mergePlan, error) {
+	sourceEntry, err := v.loadEntry(sourceFull, sourceMtime)
+	if err != nil {
+		return mergePlan{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	targetEntry, err := v.loadEntry(targetFull, targetMtime)
+	if err != nil {
+		return mergePlan{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	source, target := v.relPath(sourceFull), v.relPath(targetFull)
+
+	// Links in the source body must keep resolving from the target
+	_, body, _ := SplitFrontmatter(sourceEntry.Content)
+	body, _ = relinkMerged(index, source, target, source, target, body)
+	title, body := mergeTitle(sourceEntry, source, body)
+
+	var content string
+	switch strategy {
+	case MergePrepend:
+		content = prependSection(targetEntry.Content, title, body)
+	case MergeSections:
+		content = mergeSections(targetEntry.Content, body)
+	default:
+		content = strings.TrimRight(targetEntry.Content, "\n") + "\n\n" + mergedSection(title, body) + "\n"
+	}
+
+	// Links from the target to the source become links to itself
+	plan := mergePlan{}
+	content, plan.links = relinkMerged(index, target, target, source, target, content)
+
+	merged, err := mergeFrontmatter(content, sourceEntry.Properties)
+	if err != nil {
+		plan.warnings = append(plan.warnings, fmt.Sprintf("tags and aliases of %s were not merged: %v", source, err))
+		merged = content
+	}
+	plan.content = merged
+	return plan, nil
+}
+
+// mergeTitle returns the title of the merged source, from its frontmatter,
+// a leading # heading or its file name, and the body without that heading
+func mergeTitle(entry CacheEntry, source, body string) (string, string) {
+	body = strings.Trim(body, "\n")
+	first, rest, _ := strings.Cut(body, "\n")
+	if m := headingRegex.FindStringSubmatch(strings.TrimSpace(first)); m != nil && len(m[1]) == 1 {
+		title := m[2]
+		if entry.Title != "" {
+			title = entry.Title
+		}
+		return title, strings.Trim(rest, "\n")
+	}
+	if entry.Title != "" {
+		return entry.Title, body
+	}
+	return strings.TrimSuffix(path.Base(source), ".md"), body
+}
+
+// prependSection inserts body under a heading at the start of content,
+// after its frontmatter and a leading # heading
+func prependSection(content, title, body string) string {
+	lines := strings.Split(content, "\n")
+	return strings.Join(insertLines(lines, bodyStart(content, len(lines)), strings.Split(mergedSection(title, body), "\n")), "\n")
+}
+
+// bodyStart returns the 0-based index of the first line of content after
+// its frontmatter and a leading # heading, or end when there is none
+func bodyStart(content string, end int) int {
+	for _, line := range noteLines(content) {
+		if strings.TrimSpace(line.text) == "" {
+			continue
+		}
+		if m := headingRegex.FindStringSubmatch(strings.TrimSpace(line.text)); m != nil && !line.code && len(m[1]) == 1 {
+			return line.num
+		}
+		return line.num - 1
+	}
+	return end
+}
+
+// mergedSection returns body under a second-level heading named title
+func mergedSection(title, body string) string {
+	if body == "" {
+		return "## " + title
+	}
+	return "## " + title + "\n\n" + body
+}
+
+// mergeSections adds each top-level section of body to the end of the
+// section of content with the same heading, or to the end of content when
+// there is none. Text before the first heading of body goes after the
+// introduction of content, below its # heading.
+func mergeSections(content, body string) string {
+	type section struct {
+		heading string
+		lines   []string
+	}
+
+	// Split body at its highest-level headings
+	level := 0
+	for _, line := range noteLines(body) {
+		if m := headingRegex.FindStringSubmatch(strings.TrimSpace(line.text)); m != nil && !line.code {
+			if level == 0 || len(m[1]) < level {
+				level = len(m[1])
+			}
+		}
+	}
+	sections := []section{{}}
+	for _, line := range noteLines(body) {
+		m := headingRegex.FindStringSubmatch(strings.TrimSpace(line.text))
+		if m != nil && !line.code && len(m[1]) == level {
+			sections = append(sections, section{heading: m[2], lines: []string{line.text}})
+			continue
+		}
+		last := &sections[len(sections)-1]
+		last.lines = append(last.lines, line.text)
+	}
+
+	for i, s := range sections {
+		text := strings.Trim(strings.Join(s.lines, "\n"), "\n")
+		if text == "" {
+			continue
+		}
+		lines := strings.Split(content, "\n")
+
+		if i == 0 {
+			start := bodyStart(content, len(lines))
+			pos := len(lines)
+			for _, line := range noteLines(content) {
+				if line.num > start && !line.code && headingRegex.MatchString(strings.TrimSpace(line.text)) {
+					pos = line.num - 1
+					break
+				}
+			}
+			pos = max(backOverBlanks(lines, pos), start)
+			content = strings.Join(insertLines(lines, pos, strings.Split(text, "\n")), "\n")
+			continue
+		}
+
+		if start, end, ok := findHeadingSection(content, s.heading); ok {
+			_, sectionBody, _ := strings.Cut(text, "\n")
+			if sectionBody = strings.Trim(sectionBody, "\n"); sectionBody != "" {
+				pos := max(backOverBlanks(lines, end), start)
+				content = strings.Join(insertLines(lines, pos, strings.Split(sectionBody, "\n")), "\n")
+			}
+			continue
+		}
+		content = strings.TrimRight(content, "\n") + "\n\n" + text + "\n"
+	}
+	return content
+}
+
+// backOverBlanks moves the 0-based insertion point pos up past blank lines
+func backOverBlanks(lines []string, pos int) int {
+	for pos > 0 && strings.TrimSpace(lines[pos-1]) == "" {
+		pos--
+	}
+	return pos
+}
+
+// insertLines inserts block before lines[pos], separated from the
+// surrounding text by blank lines
+func insertLines(lines []string, pos int, block []string) []string {
+	if pos > 0 && strings.TrimSpace(lines[pos-1]) != "" {
+		block = append([]string{""}, block...)
+	}
+	if pos < len(lines) && strings.TrimSpace(lines[pos]) != "" {
+		block = append(block, "")
+	}
+	return slices.Insert(lines, pos, block...)
+}
+
+// relinkMerged rewrites the links in content, written in the note at from
+// and moving to the note at to, so links to source point at target and
+// every other link resolves as it did from from. Returns the new content
+// and the number of links changed.
+func relinkMerged(index *fileIndex, from, to, source, target, content string) (string, int) {
+	return rewriteLinks(content, func(link string) (string, bool) {
+		if link == "" {
+			return "", false // Same-note heading or block reference
+		}
+		resolved, ok := index.resolve(from, link)
+		if !ok {
+			return "", false // Broken links are left alone
+		}
+
+		want := resolved
+		if resolved == source {
+			want = target
+		}
+		if got, ok := index.resolve(to, link); ok && got == want {
+			return "", false
+		}
+		return index.linkTo(to, want, path.Ext(link) != ""), true
+	})
+}
+
+// linkTo returns how a link in the note at from names the file at to: by
+// its name when that resolves to it, otherwise by its vault-relative path.
+// The .md extension is kept only when withExt is set.
+func (idx *fileIndex) linkTo(from, to string, withExt bool) string {
+	name := path.Base(to)
+	link := to
+	if !withExt {
+		name, link = strings.TrimSuffix(name, ".md"), strings.TrimSuffix(to, ".md")
+	}
+	if got, ok := idx.resolve(from, name); ok && got == to {
+		return name
+	}
+	return link
+}
+
+// relinkMergedWritten points the links of the note at notePath to source
+// at target and writes it back when any changed, returning the number of
+// links changed and the update for the audit log
+// Caller must hold the note's write lock
+func (v *vault) relinkMergedWritten(index *fileIndex, notePath, source, target string) (int, AuditEntry, error) {
+	stat, err := os.Stat(notePath)
+	if err != nil {
+		return 0, AuditEntry{}, err
+	}
+	entry, err := v.loadEntry(notePath, stat.ModTime())
+	if err != nil {
+		return 0, AuditEntry{}, err
+	}
+
+	relPath := v.relPath(notePath)
+	content, changed := relinkMerged(index, relPath, relPath, source, target, entry.Content)
+	if changed == 0 {
+		return 0, AuditEntry{}, nil
+	}
+	written, err := v.writeNote(notePath, content)
+	if err != nil {
+		return 0, AuditEntry{}, err
+	}
+	return changed, written, nil
+}
+
+// trash moves the note at fullPath into the trash, below a folder named
+// for the time so repeated deletions of a path are all kept, and returns
+// its vault-relative path there
+func (v *vault) trash(fullPath string) (string, error) {
+	stamp := time.Now().UTC().Format(versionTimeFormat)
+	dst := filepath.Join(v.basePath, dataDir, trashDir, stamp, v.relPath(fullPath))
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return "", fmt.Errorf("failed to create trash directory: %w", v.permissionError(fullPath, err))
+	}
+	if err := os.Rename(fullPath, dst); err != nil {
+		return "", fmt.Errorf("failed to move note to the trash: %w", v.permissionError(fullPath, err))
+	}
+
+	v.cache.Delete(fullPath)
+	if v.index != nil {
+		v.index.remove(fullPath)
+	}
+	return v.relPath(dst), nil
+}
+
+// mergeFrontmatter adds the tags and aliases in fields that the
+// frontmatter of content lacks, keeping its other properties as written.
+// Fails when the existing frontmatter is not a YAML mapping.
+func mergeFrontmatter(content string, fields map[string]any) (string, error) {
+	block, body, hasBlock := SplitFrontmatter(content)
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(block), &doc); err != nil {
+		return "", fmt.Errorf("invalid frontmatter: %w", err)
+	}
+	var mapping *yaml.Node
+	switch {
+	case doc.Kind == 0:
+		mapping = &yaml.Node{Kind: yaml.MappingNode}
+	case doc.Kind == yaml.DocumentNode && len(doc.Content) == 1 && doc.Content[0].Kind == yaml.MappingNode:
+		mapping = doc.Content[0]
+	default:
+		return "", fmt.Errorf("frontmatter is not a list of properties")
+	}
+
+	var existing map[string]any
+	if err := mapping.Decode(&existing); err != nil {
+		return "", fmt.Errorf("invalid frontmatter: %w", err)
+	}
+
+	changed := false
+	for _, list := range []struct {
+		keys   []string
+		values func(map[string]any) []string
+		same   func(a, b string) bool
+	}{
+		{[]string{"tags", "tag"}, frontmatterTags, sameTag},
+		{[]string{"aliases", "alias"}, frontmatterAliases, strings.EqualFold},
+	} {
+		have := list.values(existing)
+		var missing []string
+		for _, value := range list.values(fields) {
+			if !slices.ContainsFunc(have, func(h string) bool { return list.same(h, value) }) {
+				have = append(have, value)
+				missing = append(missing, value)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		changed = true
+		setSequence(mapping, list.keys, have)
+	}
+	if !changed {
+		return content, nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(mapping); err != nil {
+		return "", fmt.Errorf("failed to encode frontmatter: %w", err)
+	}
+	if !hasBlock {
+		body = content
+	}
+	return "---\n" + buf.String() + "---\n" + body, nil
+}
+
+// frontmatterTags returns the tags property as a list
+// Accepts a list or a string of comma or space separated tags, under
+// "tags" or the legacy "tag"
+func frontmatterTags(fields map[string]any) []string {
+	var tags []string
+	for _, key := range []string{"tags", "tag"} {
+		switch value := fields[key].(type) {
+		case string:
+			tags = append(tags, strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' })...)
+		case []any:
+			for _, item := range value {
+				if item == nil {
+					continue
+				}
+				if tag := strings.TrimSpace(fmt.Sprint(item)); tag != "" {
+					tags = append(tags, tag)
+				}
+			}
+		}
+	}
+	return tags
+}
+
+// sameTag reports whether two tags are equal once folded, ignoring a
+// leading #
+func sameTag(a, b string) bool {
+	return foldTag(a) == foldTag(b)
+}
+
+// setSequence sets the first of keys present in mapping, or the first key
+// when none is, to a block sequence of values. Later keys are removed.
+func setSequence(mapping *yaml.Node, keys []string, values []string) {
+	seq := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, value := range values {
+		seq.Content = append(seq.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: value})
+	}
+
+	set := false
+	for i := 0; i+1 < len(mapping.Content); {
+		if !slices.Contains(keys, mapping.Content[i].Value) {
+			i += 2
+			continue
+		}
+		if set {
+			mapping.Content = slices.Delete(mapping.Content, i, i+2)
+			continue
+		}
+		mapping.Content[i+1] = seq
+		set = true
+		i += 2
+	}
+	if !set {
+		mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: keys[0]}, seq)
+	}
+}
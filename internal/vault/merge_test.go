@@ -0,0 +1,176 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// setupMergeVault creates a vault with two notes on the same topic and a
+// note linking to the one merged away
+func setupMergeVault(t *testing.T) (*vault, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+
+	notes := map[string]string{
+		"Projects/topic.md":   "---\ntags: [work]\naliases: Topic\nstatus: active\n---\n# Topic\nIntro\n\n## Goals\nShip\n\n## Notes\nSee [[old topic]]\n",
+		"Inbox/old topic.md":  "---\ntags:\n  - idea\n  - work\naliases: [Old]\n---\n# Old topic\nOld intro [[sibling]]\n\n## Goals\nAlso this\n\n## Risks\nNone\n",
+		"Inbox/sibling.md":    "Sibling",
+		"ref.md":              "Links [[old topic]] and [[Old topic#Goals|goals]] and [text](Inbox/old%20topic.md)\n`[[old topic]]`",
+		"unrelated.md":        "No links here [[sibling]]",
+		"Projects/nothing.md": "Plain",
+	}
+	writeFiles(t, tmpDir, notes)
+
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	return v.(*vault), tmpDir
+}
+
+// readFile returns the content of the vault file at path
+func readFile(t *testing.T, tmpDir, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(tmpDir, path))
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+const mergedFrontmatter = "---\ntags:\n  - work\n  - idea\naliases:\n  - Topic\n  - Old\nstatus: active\n---\n"
+
+func TestMergeNotes(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("append dry run", func(t *testing.T) {
+		v, tmpDir := setupMergeVault(t)
+		before := readFile(t, tmpDir, "Projects/topic.md")
+
+		result, err := v.MergeNotes(ctx, MergeOptions{Source: "Inbox/old topic.md", Target: "Projects/topic.md", DryRun: true})
+		if err != nil {
+			t.Fatalf("MergeNotes() error = %v", err)
+		}
+
+		want := mergedFrontmatter + "# Topic\nIntro\n\n## Goals\nShip\n\n## Notes\nSee [[topic]]\n\n" +
+			"## Old topic\n\nOld intro [[sibling]]\n\n## Goals\nAlso this\n\n## Risks\nNone\n"
+		if result.Content != want {
+			t.Errorf("Content = %q, want %q", result.Content, want)
+		}
+		if result.LinksUpdated != 4 || !reflect.DeepEqual(result.UpdatedNotes, []string{"ref.md"}) || result.Trashed != "" {
+			t.Errorf("MergeNotes() = %+v", result)
+		}
+		if readFile(t, tmpDir, "Projects/topic.md") != before {
+			t.Error("Dry run modified the target")
+		}
+		if _, err := os.Stat(filepath.Join(tmpDir, "Inbox", "old topic.md")); err != nil {
+			t.Errorf("Dry run removed the source: %v", err)
+		}
+	})
+
+	t.Run("sections", func(t *testing.T) {
+		v, tmpDir := setupMergeVault(t)
+
+		result, err := v.MergeNotes(ctx, MergeOptions{Source: "Inbox/old topic.md", Target: "Projects/topic.md", Strategy: MergeSections})
+		if err != nil {
+			t.Fatalf("MergeNotes() error = %v", err)
+		}
+
+		want := mergedFrontmatter + "# Topic\nIntro\n\nOld intro [[sibling]]\n\n## Goals\nShip\n\nAlso this\n\n" +
+			"## Notes\nSee [[topic]]\n\n## Risks\nNone\n"
+		if got := readFile(t, tmpDir, "Projects/topic.md"); got != want {
+			t.Errorf("Target = %q, want %q", got, want)
+		}
+		wantRef := "Links [[topic]] and [[topic#Goals|goals]] and [text](topic.md)\n`[[old topic]]`"
+		if got := readFile(t, tmpDir, "ref.md"); got != wantRef {
+			t.Errorf("ref.md = %q, want %q", got, wantRef)
+		}
+		if result.LinksUpdated != 4 || !reflect.DeepEqual(result.UpdatedNotes, []string{"ref.md"}) {
+			t.Errorf("MergeNotes() = %+v", result)
+		}
+
+		if _, err := os.Stat(filepath.Join(tmpDir, "Inbox", "old topic.md")); !os.IsNotExist(err) {
+			t.Errorf("Source still present: %v", err)
+		}
+		if !strings.HasPrefix(result.Trashed, ".mcp-notes/trash/") || !strings.HasSuffix(result.Trashed, "/Inbox/old topic.md") {
+			t.Errorf("Trashed = %q", result.Trashed)
+		}
+		if got := readFile(t, tmpDir, result.Trashed); !strings.Contains(got, "Also this") {
+			t.Errorf("Trashed content = %q", got)
+		}
+		if versions, err := v.ListVersions(ctx, "Projects/topic.md"); err != nil || len(versions) != 1 {
+			t.Errorf("ListVersions() = %v, %v; want the target backed up", versions, err)
+		}
+		if _, err := v.Read(ctx, "Inbox/old topic.md"); !errors.Is(err, ErrNoteNotFound) {
+			t.Errorf("Read() of the merged source error = %v, want ErrNoteNotFound", err)
+		}
+	})
+
+	t.Run("prepend keeping the source", func(t *testing.T) {
+		v, tmpDir := setupMergeVault(t)
+
+		_, err := v.MergeNotes(ctx, MergeOptions{Source: "Projects/nothing.md", Target: "unrelated.md", Strategy: MergePrepend, KeepSource: true})
+		if err != nil {
+			t.Fatalf("MergeNotes() error = %v", err)
+		}
+		if got, want := readFile(t, tmpDir, "unrelated.md"), "## nothing\n\nPlain\n\nNo links here [[sibling]]"; got != want {
+			t.Errorf("Target = %q, want %q", got, want)
+		}
+		if _, err := os.Stat(filepath.Join(tmpDir, "Projects", "nothing.md")); err != nil {
+			t.Errorf("Source was removed: %v", err)
+		}
+	})
+
+	tests := []struct {
+		name string
+		opts MergeOptions
+		want error
+	}{
+		{"into itself", MergeOptions{Source: "ref.md", Target: "./ref.md"}, ErrInvalidPath},
+		{"missing source", MergeOptions{Source: "nope.md", Target: "ref.md"}, ErrNoteNotFound},
+		{"missing target", MergeOptions{Source: "ref.md", Target: "nope.md"}, ErrNoteNotFound},
+		{"not markdown", MergeOptions{Source: "ref.txt", Target: "ref.md"}, ErrNotMarkdown},
+	}
+	v, _ := setupMergeVault(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := v.MergeNotes(ctx, tt.opts); !errors.Is(err, tt.want) {
+				t.Errorf("MergeNotes() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeFrontmatter(t *testing.T) {
+	source := map[string]any{"tags": "a, b", "aliases": []any{"One"}, "status": "draft"}
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{"no frontmatter", "Body", "---\ntags:\n  - a\n  - b\naliases:\n  - One\n---\nBody", false},
+		{"legacy keys", "---\ntag: '#A'\nalias: one\n---\nBody", "---\ntag:\n  - '#A'\n  - b\nalias: one\n---\nBody", false},
+		{"nothing missing", "---\ntags: [a, b]\naliases: [one]\n---\nBody", "---\ntags: [a, b]\naliases: [one]\n---\nBody", false},
+		{"invalid", "---\ntags: [a\n---\nBody", "", true},
+		{"not a mapping", "---\n- a\n---\nBody", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mergeFrontmatter(tt.content, source)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("mergeFrontmatter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("mergeFrontmatter() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// MoveNoteOptions describes the move or rename of one note
+type MoveNoteOptions struct {
+	Path        string // Note to move
+	NewPath     string // New path of the note; missing parents are created
+	UpdateLinks bool   // Rewrite links to the note, and its own relative links, to keep resolving
+	DryRun      bool   // Plan the move without writing anything
+}
+
+// LinkRewrite is a line whose links MoveNote rewrites
+type LinkRewrite struct {
+	Line   int    `json:"line"` // 1-based line number
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// RelinkedNote reports the links rewritten in one note
+type RelinkedNote struct {
+	Path     string        `json:"path"`               // Where the note is after the move
+	Links    int           `json:"links"`              // Links rewritten
+	Rewrites []LinkRewrite `json:"rewrites,omitempty"` // Lines changed, on dry runs
+}
+
+// NoteMove reports the outcome of MoveNote
+type NoteMove struct {
+	Path         string         `json:"path"`
+	NewPath      string         `json:"new_path"`
+	DryRun       bool           `json:"dry_run,omitempty"`
+	LinksUpdated int            `json:"links_updated"`
+	UpdatedNotes []RelinkedNote `json:"updated_notes,omitempty"` // Sorted by path
+	NotUpdated   []string       `json:"not_updated,omitempty"`   // Notes whose links could not be rewritten
+}
+
+// MoveNote moves or renames a note. Cached content, the search index,
+// backups, annotations and locks follow it. With UpdateLinks, every
+// wikilink, embed and markdown link to the note is pointed at its new
+// path, by name when that is unambiguous, keeping headings, block
+// references and display text; links that would resolve to another note
+// after the move, including relative links in the moved note, are
+// rewritten to keep their target. Links in code and links that resolve to
+// other notes of the same name are left alone. The destination must not
+// exist, except when only the case of the name changes.
+func (v *vault) MoveNote(ctx context.Context, opts MoveNoteOptions) (NoteMove, error) {
+	fullPath, err := v.validatePath(opts.Path)
+	if err != nil {
+		return NoteMove{}, err
+	}
+	newFullPath, err := v.validatePath(opts.NewPath)
+	if err != nil {
+		return NoteMove{}, err
+	}
+	if fullPath == newFullPath {
+		return NoteMove{}, fmt.Errorf("%w: cannot move a note onto itself", ErrInvalidPath)
+	}
+	caseOnly := strings.EqualFold(fullPath, newFullPath)
+	if err := v.checkNoteDestination(fullPath, newFullPath, caseOnly, opts.Path, opts.NewPath); err != nil {
+		return NoteMove{}, err
+	}
+
+	from, to := v.relPath(fullPath), v.relPath(newFullPath)
+	result := NoteMove{Path: from, NewPath: to, DryRun: opts.DryRun}
+
+	// Notes whose links change, by full path before the move
+	var relinked []string
+	var oldIndex, newIndex *fileIndex
+	if opts.UpdateLinks {
+		oldIndex, err = v.buildFileIndex(ctx)
+		if err != nil {
+			return NoteMove{}, err
+		}
+		newIndex = oldIndex.renamed(from, to)
+
+		var mu sync.Mutex
+		_, err = v.walkNotes(ctx, ListOptions{Recursive: true}, func(file noteFile, entry CacheEntry) bool {
+			notePath, _ := movedPath(file.relPath, from, to)
+			content, changed := relinkMoved(oldIndex, newIndex, file.relPath, notePath, from, to, entry.Content)
+			if changed == 0 {
+				return false
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			relinked = append(relinked, file.fullPath)
+			if opts.DryRun {
+				result.LinksUpdated += changed
+				result.UpdatedNotes = append(result.UpdatedNotes, RelinkedNote{
+					Path:     notePath,
+					Links:    changed,
+					Rewrites: linkRewrites(entry.Content, content),
+				})
+			}
+			return false
+		})
+		if err != nil {
+			return NoteMove{}, err
+		}
+	}
+
+	if opts.DryRun {
+		slices.SortFunc(result.UpdatedNotes, func(a, b RelinkedNote) int {
+			return strings.Compare(a.Path, b.Path)
+		})
+		return result, nil
+	}
+
+	// Every note written must be writable, the moved one at both paths
+	lockPaths := append([]string{fullPath, newFullPath}, relinked...)
+	if err := v.checkWritable(lockPaths...); err != nil {
+		return NoteMove{}, err
+	}
+	if err := v.checkLeases(ctx, lockPaths...); err != nil {
+		return NoteMove{}, err
+	}
+	if err := v.checkAudit(); err != nil {
+		return NoteMove{}, err
+	}
+
+	unlock := v.writeLocks.lock(lockPaths...)
+	defer unlock()
+
+	// Check again under the lock; the note may have moved meanwhile
+	if err := v.checkNoteDestination(fullPath, newFullPath, caseOnly, opts.Path, opts.NewPath); err != nil {
+		return NoteMove{}, err
+	}
+	stat, err := statNote(fullPath, opts.Path)
+	if err != nil {
+		return NoteMove{}, err
+	}
+	entry, err := v.loadEntry(fullPath, stat.ModTime())
+	if err != nil {
+		return NoteMove{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return NoteMove{}, err
+	}
+
+	// Move the note; once it has moved the rest is completed regardless
+	dirs, err := makeDirs(filepath.Dir(newFullPath))
+	if err != nil {
+		return NoteMove{}, v.permissionError(newFullPath, err)
+	}
+	if err := os.Rename(fullPath, newFullPath); err != nil {
+		removeDirs(dirs)
+		return NoteMove{}, fmt.Errorf("failed to move note: %w", v.permissionError(fullPath, err))
+	}
+	v.renameCached(fullPath, newFullPath)
+	v.moveBackups(from, to)
+	v.moveAnnotations(from, to)
+	v.moveLeases(from, to)
+	v.movePins(from, to)
+	v.paths.invalidate()
+	audit := []AuditEntry{{Op: EditMove, Path: from, NewPath: to, Bytes: len(entry.Content), PrevHash: entry.ContentHash, Hash: entry.ContentHash}}
+
+	for _, oldPath := range relinked {
+		notePath := oldPath
+		if oldPath == fullPath {
+			notePath = newFullPath
+		}
+		relPath := v.relPath(notePath)
+
+		changed, written, err := v.relinkMovedWritten(oldIndex, newIndex, v.relPath(oldPath), notePath, from, to)
+		if err != nil {
+			v.logger.Warn("updating links failed", "path", relPath, "error", err)
+			result.NotUpdated = append(result.NotUpdated, relPath)
+			continue
+		}
+		if changed > 0 {
+			result.LinksUpdated += changed
+			result.UpdatedNotes = append(result.UpdatedNotes, RelinkedNote{Path: relPath, Links: changed})
+			audit = append(audit, written)
+		}
+	}
+	slices.SortFunc(result.UpdatedNotes, func(a, b RelinkedNote) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	slices.Sort(result.NotUpdated)
+
+	return result, v.record(ctx, audit...)
+}
+
+// checkNoteDestination fails unless the note at fullPath exists and
+// nothing is at newFullPath, or it is the note itself reached through a
+// case-only rename
+func (v *vault) checkNoteDestination(fullPath, newFullPath string, caseOnly bool, notePath, newPath string) error {
+	stat, err := statNote(fullPath, notePath)
+	if err != nil {
+		return err
+	}
+	newStat, err := os.Lstat(newFullPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat destination: %w", err)
+	}
+	if caseOnly && os.SameFile(stat, newStat) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrNoteExists, newPath)
+}
+
+// relinkMoved rewrites the links in content, written in the note at from
+// and found at to after the note source moves to target, so every link
+// resolves to the note it did before, with source's new path in place of
+// its old one. Returns the new content and the number of links changed.
+func relinkMoved(oldIndex, newIndex *fileIndex, from, to, source, target, content string) (string, int) {
+	return rewriteLinks(content, func(link string) (string, bool) {
+		if link == "" {
+			return "", false // Same-note heading or block reference
+		}
+		resolved, ok := oldIndex.resolve(from, link)
+		if !ok {
+			return "", false // Broken links are left alone
+		}
+
+		want := resolved
+		if resolved == source {
+			want = target
+		}
+		if got, ok := newIndex.resolve(to, link); ok && got == want {
+			return "", false
+		}
+		return newIndex.linkTo(to, want, path.Ext(link) != ""), true
+	})
+}
+
+// relinkMovedWritten rewrites the links of a note now at notePath, known
+// as oldPath before source moved to target, and writes it back when any
+// changed, returning the number of links changed and the update for the
+// audit log
+// Caller must hold the note's write lock
+func (v *vault) relinkMovedWritten(oldIndex, newIndex *fileIndex, oldPath, notePath, source, target string) (int, AuditEntry, error) {
+	stat, err := os.Stat(notePath)
+	if err != nil {
+		return 0, AuditEntry{}, err
+	}
+	entry, err := v.loadEntry(notePath, stat.ModTime())
+	if err != nil {
+		return 0, AuditEntry{}, err
+	}
+
+	content, changed := relinkMoved(oldIndex, newIndex, oldPath, v.relPath(notePath), source, target, entry.Content)
+	if changed == 0 {
+		return 0, AuditEntry{}, nil
+	}
+	written, err := v.writeNote(notePath, content)
+	if err != nil {
+		return 0, AuditEntry{}, err
+	}
+	return changed, written, nil
+}
+
+// linkRewrites lists the lines of before that differ in after, which
+// rewriteLinks leaves with the same number of lines
+func linkRewrites(before, after string) []LinkRewrite {
+	oldLines, newLines := strings.Split(before, "\n"), strings.Split(after, "\n")
+	var rewrites []LinkRewrite
+	for i := range min(len(oldLines), len(newLines)) {
+		if oldLines[i] != newLines[i] {
+			rewrites = append(rewrites, LinkRewrite{Line: i + 1, Before: oldLines[i], After: newLines[i]})
+		}
+	}
+	return rewrites
+}
@@ -0,0 +1,204 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setupMoveVault creates a vault with a note linked in every form, a
+// note of the same name in another folder and a note linking to that one
+func setupMoveVault(t *testing.T) (*vault, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+
+	notes := map[string]string{
+		"Work/Old Name.md":     "# Old\nSee [up](../index.md) and [[Old Name#Intro]]\n",
+		"Personal/Old Name.md": "Other",
+		"Personal/ref.md":      "Mine: [[Old Name]]",
+		"index.md":             "[[Old Name]] [[Old Name|alias]] [[Work/Old Name#Heading]]\n![[Old Name#^block]] [text](Work/Old%20Name.md) `[[Old Name]]`\n```\n[[Old Name]]\n```\n",
+	}
+	writeFiles(t, tmpDir, notes)
+
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	return v.(*vault), tmpDir
+}
+
+func TestMoveNote(t *testing.T) {
+	ctx := context.Background()
+	opts := MoveNoteOptions{Path: "Work/Old Name.md", NewPath: "Archive/2024/New Name.md", UpdateLinks: true}
+
+	t.Run("dry run", func(t *testing.T) {
+		v, tmpDir := setupMoveVault(t)
+		dryRun := opts
+		dryRun.DryRun = true
+
+		result, err := v.MoveNote(ctx, dryRun)
+		if err != nil {
+			t.Fatalf("MoveNote() error = %v", err)
+		}
+		if !result.DryRun || result.LinksUpdated != 7 || len(result.UpdatedNotes) != 2 {
+			t.Fatalf("MoveNote() = %+v", result)
+		}
+		moved, index := result.UpdatedNotes[0], result.UpdatedNotes[1]
+		if moved.Path != "Archive/2024/New Name.md" || moved.Links != 2 || len(moved.Rewrites) != 1 {
+			t.Errorf("moved note = %+v", moved)
+		}
+		if index.Path != "index.md" || index.Links != 5 || len(index.Rewrites) != 2 {
+			t.Fatalf("index.md = %+v", index)
+		}
+		if rewrite := index.Rewrites[0]; rewrite.Line != 1 || rewrite.After != "[[New Name]] [[New Name|alias]] [[New Name#Heading]]" {
+			t.Errorf("first rewrite = %+v", rewrite)
+		}
+
+		if _, err := os.Stat(filepath.Join(tmpDir, "Archive")); !os.IsNotExist(err) {
+			t.Errorf("dry run created the destination: %v", err)
+		}
+		if got := readFile(t, tmpDir, "index.md"); got[:12] != "[[Old Name]]" {
+			t.Errorf("dry run rewrote index.md: %q", got)
+		}
+	})
+
+	t.Run("rewrites links", func(t *testing.T) {
+		v, tmpDir := setupMoveVault(t)
+
+		// Warm the cache and create a backup of the moved note
+		if err := v.Update(ctx, "Work/Old Name.md", "# Old\nSee [up](../index.md) and [[Old Name#Intro]]\n"); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+
+		result, err := v.MoveNote(ctx, opts)
+		if err != nil {
+			t.Fatalf("MoveNote() error = %v", err)
+		}
+		if result.DryRun || result.LinksUpdated != 7 || len(result.UpdatedNotes) != 2 || len(result.NotUpdated) != 0 {
+			t.Errorf("MoveNote() = %+v", result)
+		}
+		for _, note := range result.UpdatedNotes {
+			if len(note.Rewrites) != 0 {
+				t.Errorf("%s lists rewrites outside a dry run", note.Path)
+			}
+		}
+
+		want := map[string]string{
+			// Aliases, headings and block references are kept; code is not touched
+			"index.md": "[[New Name]] [[New Name|alias]] [[New Name#Heading]]\n![[New Name#^block]] [text](New%20Name.md) `[[Old Name]]`\n```\n[[Old Name]]\n```\n",
+			// The relative link broke with the move and the self-link follows it
+			"Archive/2024/New Name.md": "# Old\nSee [up](index.md) and [[New Name#Intro]]\n",
+			// [[Old Name]] resolves to the note in the same folder
+			"Personal/ref.md": "Mine: [[Old Name]]",
+		}
+		for path, content := range want {
+			if got := readFile(t, tmpDir, path); got != content {
+				t.Errorf("%s = %q, want %q", path, got, content)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(tmpDir, "Work", "Old Name.md")); !os.IsNotExist(err) {
+			t.Errorf("Expected the old note to be gone, got %v", err)
+		}
+
+		newPath := filepath.Join(tmpDir, "Archive", "2024", "New Name.md")
+		if entry, ok := v.cache.Get(newPath); !ok || entry.Content != want["Archive/2024/New Name.md"] {
+			t.Errorf("Expected cache entry under the new path, got %+v (%v)", entry, ok)
+		}
+		if versions, err := v.ListVersions(ctx, "Archive/2024/New Name.md"); err != nil || len(versions) != 2 {
+			t.Errorf("ListVersions() = %v, %v; want the backup to follow the note and one for the relink", versions, err)
+		}
+
+		entries, err := v.AuditLog(ctx, AuditQuery{})
+		if err != nil {
+			t.Fatalf("AuditLog() error = %v", err)
+		}
+		got := auditOps(entries)
+		if len(got) != 4 || got[3] != "update Work/Old Name.md" || got[2] != "move Work/Old Name.md Archive/2024/New Name.md" {
+			t.Errorf("AuditLog() = %v, want the update, the move and the two relinks", got)
+		}
+
+		report, err := v.Verify(ctx, VerifyOptions{})
+		if err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+		for _, problem := range report.Problems {
+			if problem.Kind == ProblemBrokenLink {
+				t.Errorf("Unexpected broken link after move: %+v", problem)
+			}
+		}
+	})
+
+	t.Run("without links", func(t *testing.T) {
+		v, tmpDir := setupMoveVault(t)
+
+		result, err := v.MoveNote(ctx, MoveNoteOptions{Path: "Work/Old Name.md", NewPath: "Work/old name.md"})
+		if err != nil {
+			t.Fatalf("MoveNote() error = %v", err)
+		}
+		if result.LinksUpdated != 0 || len(result.UpdatedNotes) != 0 {
+			t.Errorf("MoveNote() = %+v", result)
+		}
+		if got := readFile(t, tmpDir, "index.md"); got[:12] != "[[Old Name]]" {
+			t.Errorf("index.md rewritten without update_links: %q", got)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		v, _ := setupMoveVault(t)
+
+		tests := []struct {
+			name string
+			opts MoveNoteOptions
+			want error
+		}{
+			{"missing", MoveNoteOptions{Path: "Work/missing.md", NewPath: "Work/new.md"}, ErrNoteNotFound},
+			{"destination exists", MoveNoteOptions{Path: "Work/Old Name.md", NewPath: "index.md"}, ErrNoteExists},
+			{"onto itself", MoveNoteOptions{Path: "Work/Old Name.md", NewPath: "Work/Old Name.md"}, ErrInvalidPath},
+			{"not markdown", MoveNoteOptions{Path: "Work/Old Name.md", NewPath: "Work/new.txt"}, ErrNotMarkdown},
+			{"traversal", MoveNoteOptions{Path: "Work/Old Name.md", NewPath: "../outside.md"}, ErrPathTraversal},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if _, err := v.MoveNote(ctx, tt.opts); !errors.Is(err, tt.want) {
+					t.Errorf("MoveNote() error = %v, want %v", err, tt.want)
+				}
+			})
+		}
+	})
+}
+
+func TestRelinkMoved(t *testing.T) {
+	index := &fileIndex{paths: make(map[string]string), byName: make(map[string][]string)}
+	for _, p := range []string{"a/plan.md", "b/plan.md", "c/deep/notes.md", "todo.md"} {
+		index.paths[filepath.ToSlash(p)] = p
+		index.byName[filepath.Base(p)] = append(index.byName[filepath.Base(p)], p)
+	}
+	index.sortNames()
+
+	tests := []struct {
+		name           string
+		source, target string
+		from, content  string
+		want           string
+		changed        int
+	}{
+		{"by name", "todo.md", "c/tasks.md", "a/plan.md", "[[todo]] and [[todo.md]]", "[[tasks]] and [[tasks.md]]", 2},
+		{"name still resolves", "todo.md", "a/todo.md", "c/deep/notes.md", "[[todo]]", "[[todo]]", 0},
+		// Moving b/plan.md to the root makes [[plan]] from c resolve to it
+		{"shadowed link keeps its target", "b/plan.md", "plan.md", "c/deep/notes.md", "[[plan]] [[b/plan]]", "[[a/plan]] [[plan]]", 2},
+		{"other note of the same name", "a/plan.md", "x/goals.md", "b/plan.md", "[[plan]]", "[[plan]]", 0},
+		{"inline code and fences", "todo.md", "done.md", "a/plan.md", "`[[todo]]`\n```\n[[todo]]\n```", "`[[todo]]`\n```\n[[todo]]\n```", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			moved := index.renamed(tt.source, tt.target)
+			to, _ := movedPath(tt.from, tt.source, tt.target)
+			got, changed := relinkMoved(index, moved, tt.from, to, tt.source, tt.target, tt.content)
+			if got != tt.want || changed != tt.changed {
+				t.Errorf("relinkMoved() = %q, %d; want %q, %d", got, changed, tt.want, tt.changed)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package vault
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// obsidianDir is the folder where Obsidian keeps a vault's settings
+const obsidianDir = ".obsidian"
+
+// ObsidianSettings are the settings taken from the vault's .obsidian
+// folder. Files that are missing or cannot be parsed are left out.
+type ObsidianSettings struct {
+	Files            []string           `json:"files"`                       // Settings files read, relative to the vault
+	IgnoreFilters    []string           `json:"ignore_filters,omitempty"`    // "Excluded files": path prefixes, or /regex/
+	AttachmentFolder string             `json:"attachment_folder,omitempty"` // Folder of new attachments, "./" for the note's folder
+	TemplatesFolder  string             `json:"templates_folder,omitempty"`  // From the Templates core plugin, or Templater
+	DailyNotes       *DailyNoteSettings `json:"daily_notes,omitempty"`
+}
+
+// DailyNoteSettings are the settings of the Daily notes core plugin
+type DailyNoteSettings struct {
+	Folder   string `json:"folder,omitempty"`
+	Format   string `json:"format,omitempty"` // Moment.js date format, YYYY-MM-DD when empty
+	Template string `json:"template,omitempty"`
+}
+
+// ignoreFilter is one of Obsidian's excluded files filters: a prefix of
+// the vault-relative path, or a regular expression written as /regex/
+type ignoreFilter struct {
+	prefix string
+	re     *regexp.Regexp
+}
+
+// matches reports whether the vault-relative slash path is excluded
+func (f ignoreFilter) matches(relPath string) bool {
+	if f.re != nil {
+		return f.re.MatchString(relPath)
+	}
+	return strings.HasPrefix(relPath, f.prefix)
+}
+
+// WithObsidianConfig controls whether NewVault reads the ignore filters,
+// attachment, template and daily note settings of the vault's .obsidian
+// folder. It is on by default and does nothing without that folder.
+func WithObsidianConfig(enabled bool) Option {
+	return func(v *vault) {
+		v.readObsidian = enabled
+	}
+}
+
+// WithAttachmentFolder sets the folder where attachments named without a
+// folder are looked up, replacing the one in the Obsidian settings
+func WithAttachmentFolder(folder string) Option {
+	return func(v *vault) {
+		v.attachmentFolder = strings.Trim(filepath.ToSlash(folder), "/")
+	}
+}
+
+// loadObsidianSettings reads the settings files of the .obsidian folder,
+// logging at debug level those that are missing or malformed
+func (v *vault) loadObsidianSettings() {
+	dir := filepath.Join(v.vaultPath, obsidianDir)
+	if stat, err := os.Stat(dir); err != nil || !stat.IsDir() {
+		return
+	}
+	settings := &ObsidianSettings{Files: []string{}}
+
+	var app struct {
+		UserIgnoreFilters    []string `json:"userIgnoreFilters"`
+		AttachmentFolderPath string   `json:"attachmentFolderPath"`
+	}
+	if v.readObsidianFile(settings, "app.json", &app) {
+		for _, filter := range app.UserIgnoreFilters {
+			if f, ok := v.parseIgnoreFilter(filter); ok {
+				settings.IgnoreFilters = append(settings.IgnoreFilters, filter)
+				v.ignoreFilters = append(v.ignoreFilters, f)
+			}
+		}
+		settings.AttachmentFolder = app.AttachmentFolderPath
+	}
+
+	var templates struct {
+		Folder string `json:"folder"`
+	}
+	var templater struct {
+		Folder string `json:"templates_folder"`
+	}
+	if v.readObsidianFile(settings, "templates.json", &templates) && templates.Folder != "" {
+		settings.TemplatesFolder = templates.Folder
+	} else if v.readObsidianFile(settings, "plugins/templater-obsidian/data.json", &templater) {
+		settings.TemplatesFolder = templater.Folder
+	}
+
+	var daily DailyNoteSettings
+	if v.readObsidianFile(settings, "daily-notes.json", &daily) {
+		settings.DailyNotes = &daily
+	}
+
+	v.obsidian = settings
+	v.logger.Debug("read obsidian settings", "files", settings.Files, "ignore_filters", len(v.ignoreFilters))
+}
+
+// readObsidianFile decodes the JSON settings file name of the .obsidian
+// folder into dst, reporting whether it could
+func (v *vault) readObsidianFile(settings *ObsidianSettings, name string, dst any) bool {
+	relPath := path.Join(obsidianDir, name)
+	raw, err := os.ReadFile(filepath.Join(v.vaultPath, filepath.FromSlash(relPath)))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			v.logger.Debug("cannot read obsidian settings", "file", relPath, "error", err)
+		}
+		return false
+	}
+	if err := json.Unmarshal(raw, dst); err != nil {
+		v.logger.Debug("ignoring malformed obsidian settings", "file", relPath, "error", err)
+		return false
+	}
+	settings.Files = append(settings.Files, relPath)
+	return true
+}
+
+// parseIgnoreFilter parses an excluded files filter, skipping empty ones
+// and invalid regular expressions
+func (v *vault) parseIgnoreFilter(filter string) (ignoreFilter, bool) {
+	if len(filter) > 2 && strings.HasPrefix(filter, "/") && strings.HasSuffix(filter, "/") {
+		re, err := regexp.Compile(filter[1 : len(filter)-1])
+		if err != nil {
+			v.logger.Debug("ignoring invalid obsidian ignore filter", "filter", filter, "error", err)
+			return ignoreFilter{}, false
+		}
+		return ignoreFilter{re: re}, true
+	}
+	filter = strings.TrimPrefix(filter, "/")
+	if filter == "" {
+		return ignoreFilter{}, false
+	}
+	return ignoreFilter{prefix: filter}, true
+}
+
+// isIgnored reports whether an Obsidian ignore filter excludes fullPath
+// from walks. Folders are matched with a trailing slash, so a filter such
+// as "Archive/" excludes the folder itself. Filters are relative to the
+// vault directory, also when the vault is limited to a root inside it.
+func (v *vault) isIgnored(fullPath string, dir bool) bool {
+	if len(v.ignoreFilters) == 0 {
+		return false
+	}
+	relPath, err := filepath.Rel(v.vaultPath, fullPath)
+	if err != nil || relPath == "." {
+		return false
+	}
+	relPath = filepath.ToSlash(relPath)
+	if dir {
+		relPath += "/"
+	}
+	for _, f := range v.ignoreFilters {
+		if f.matches(relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// effectiveAttachmentFolder returns the folder attachments named without
+// one are looked up in: the flag's, else a fixed folder from the Obsidian
+// settings. Folders relative to each note have no single location, and
+// the Obsidian folder is left out when it is outside the root.
+func (v *vault) effectiveAttachmentFolder() string {
+	if v.attachmentFolder != "" {
+		return v.attachmentFolder
+	}
+	if v.obsidian == nil || v.obsidian.AttachmentFolder == "." || strings.HasPrefix(v.obsidian.AttachmentFolder, "./") {
+		return ""
+	}
+	folder, _ := v.inRoot(strings.Trim(v.obsidian.AttachmentFolder, "/"))
+	return folder
+}
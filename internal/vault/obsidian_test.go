@@ -0,0 +1,113 @@
+package vault
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// writeFiles creates files with their content below dir
+func writeFiles(t testing.TB, dir string, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		fullPath := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", path, err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", path, err)
+		}
+	}
+}
+
+func TestObsidianSettings(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		".obsidian/app.json":         `{"userIgnoreFilters": ["Archive/", "/\\.excalidraw\\.md$/", "/(unclosed/", ""], "attachmentFolderPath": "Assets"}`,
+		".obsidian/templates.json":   `{"folder": "Templates"}`,
+		".obsidian/daily-notes.json": `{"folder": "Daily", "format": "YYYY/MM/YYYY-MM-DD"`, // Malformed
+		"note.md":                    "Project notes",
+		"Archive/old.md":             "Project notes from last year",
+		"Archive/2020/older.md":      "Project notes from 2020",
+		"Archived.md":                "Project archive index",
+		"plan.excalidraw.md":         "Project drawing",
+		"Assets/diagram.png":         "png",
+		"diagram.png":                "root png",
+		"Assets/photo.jpg":           "jpg",
+	})
+
+	vi, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	ctx := context.Background()
+
+	paths := func(notes []NoteInfo) []string {
+		var got []string
+		for _, n := range notes {
+			got = append(got, n.Path)
+		}
+		slices.Sort(got)
+		return got
+	}
+	notes, err := vi.List(ctx, ListOptions{Recursive: true})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if got := paths(notes); !slices.Equal(got, []string{"Archived.md", "note.md"}) {
+		t.Errorf("List() = %v, want the notes outside the excluded files", got)
+	}
+	found, err := vi.Search(ctx, SearchOptions{Query: "Project", QueryMode: QueryLiteral})
+	if err != nil || len(found) != 2 {
+		t.Errorf("Search() = %v, %v; want the two notes not excluded", paths(found), err)
+	}
+	notes, err = vi.List(ctx, ListOptions{Subpath: "Archive", Recursive: true})
+	if got := paths(notes); err != nil || !slices.Equal(got, []string{"Archive/2020/older.md", "Archive/old.md"}) {
+		t.Errorf("List(Archive) = %v, %v; want an excluded folder listed when asked for", got, err)
+	}
+	if content, err := vi.Read(ctx, "Archive/old.md"); err != nil || content == "" {
+		t.Errorf("Read() of an excluded note = %q, %v", content, err)
+	}
+
+	info, err := vi.Info(ctx)
+	if err != nil {
+		t.Fatalf("Info() error = %v", err)
+	}
+	want := &ObsidianSettings{
+		Files:            []string{".obsidian/app.json", ".obsidian/templates.json"},
+		IgnoreFilters:    []string{"Archive/", `/\.excalidraw\.md$/`},
+		AttachmentFolder: "Assets",
+		TemplatesFolder:  "Templates",
+	}
+	if !reflect.DeepEqual(info.Features.Obsidian, want) || info.Features.AttachmentFolder != "Assets" {
+		t.Errorf("Info() obsidian = %+v, attachment folder %q; want %+v", info.Features.Obsidian, info.Features.AttachmentFolder, want)
+	}
+
+	// Bare names are looked up in the attachment folder when not at the root
+	if a, err := vi.StatAttachment(ctx, "photo.jpg"); err != nil || a.Path != "Assets/photo.jpg" {
+		t.Errorf("StatAttachment(photo.jpg) = %+v, %v; want it from Assets", a, err)
+	}
+	if a, err := vi.StatAttachment(ctx, "diagram.png"); err != nil || a.Path != "diagram.png" {
+		t.Errorf("StatAttachment(diagram.png) = %+v, %v; want the one at the root", a, err)
+	}
+
+	// Flags win over the Obsidian settings
+	writeFiles(t, tmpDir, map[string]string{"Media/photo.jpg": "jpg"})
+	vi, err = NewVault(tmpDir, WithAttachmentFolder("/Media/"), WithObsidianConfig(false))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	if a, err := vi.StatAttachment(ctx, "photo.jpg"); err != nil || a.Path != "Media/photo.jpg" {
+		t.Errorf("StatAttachment(photo.jpg) = %+v, %v; want it from the flag's folder", a, err)
+	}
+	notes, err = vi.List(ctx, ListOptions{Recursive: true})
+	if err != nil || len(notes) != 5 {
+		t.Errorf("List() without Obsidian settings = %v, %v; want every note", paths(notes), err)
+	}
+	if info, _ := vi.Info(ctx); info.Features.Obsidian != nil {
+		t.Errorf("Info() obsidian = %+v, want none when disabled", info.Features.Obsidian)
+	}
+}
@@ -0,0 +1,187 @@
+package vault
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// NoteSort selects the order of the notes returned by List and Search
+type NoteSort string
+
+// Note orders
+const (
+	SortPath      NoteSort = "path"      // By path under the collation
+	SortModified  NoteSort = "modified"  // Most recently modified first
+	SortCreated   NoteSort = "created"   // Most recently created first
+	SortRelevance NoteSort = "relevance" // Most query matches first; Search only
+)
+
+// ParseNoteSort validates a note order, defaulting to path
+func ParseNoteSort(s string) (NoteSort, error) {
+	switch by := NoteSort(strings.ToLower(strings.TrimSpace(s))); by {
+	case "":
+		return SortPath, nil
+	case SortPath, SortModified, SortCreated, SortRelevance:
+		return by, nil
+	default:
+		return "", fmt.Errorf("unknown sort %q (want path, modified, created or relevance)", s)
+	}
+}
+
+// Collation selects how paths are compared: "binary", "natural", or
+// "locale:" and a BCP 47 language tag such as "locale:de"
+type Collation string
+
+// Collations
+const (
+	CollationBinary  Collation = "binary"  // Byte-wise, so "Zebra" before "apple" and "note10" before "note2"
+	CollationNatural Collation = "natural" // Byte-wise, but digit runs compare by value, so "note2" before "note10"
+
+	collationLocale = "locale:"
+)
+
+// ParseCollation validates a collation, defaulting to binary
+func ParseCollation(s string) (Collation, error) {
+	s = strings.TrimSpace(s)
+	switch c := Collation(strings.ToLower(s)); c {
+	case "":
+		return CollationBinary, nil
+	case CollationBinary, CollationNatural:
+		return c, nil
+	}
+	if tag, ok := strings.CutPrefix(s, collationLocale); ok {
+		if _, err := language.Parse(tag); err != nil || tag == "" {
+			return "", fmt.Errorf("unknown locale %q in collation %q", tag, s)
+		}
+		return Collation(s), nil
+	}
+	return "", fmt.Errorf("unknown collation %q (want binary, natural or locale:xx)", s)
+}
+
+// comparer returns the path comparison of a parsed collation. A locale's
+// collator is not safe for concurrent use, so each sort makes its own.
+func (c Collation) comparer() func(a, b string) int {
+	switch c {
+	case CollationBinary:
+		return strings.Compare
+	case CollationNatural:
+		return compareNatural
+	}
+	collator := collate.New(language.Make(strings.TrimPrefix(string(c), collationLocale)))
+	return func(a, b string) int {
+		// Paths the locale deems equal, such as "a.md" and "A.md", still
+		// need a fixed order
+		if n := collator.CompareString(a, b); n != 0 {
+			return n
+		}
+		return strings.Compare(a, b)
+	}
+}
+
+// compareNatural compares a and b byte-wise, except that runs of ASCII
+// digits compare by their value. Runs of equal value but different
+// leading zeros, such as "07" and "7", fall back to byte order.
+func compareNatural(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if !isDigit(a[i]) || !isDigit(b[j]) {
+			if a[i] != b[j] {
+				return cmp.Compare(a[i], b[j])
+			}
+			i++
+			j++
+			continue
+		}
+
+		// Compare the digit runs without leading zeros: the longer one is
+		// larger, else the first differing digit decides
+		ei, ej := digitsEnd(a, i), digitsEnd(b, j)
+		na := strings.TrimLeft(a[i:ei], "0")
+		nb := strings.TrimLeft(b[j:ej], "0")
+		if n := cmp.Compare(len(na), len(nb)); n != 0 {
+			return n
+		}
+		if n := strings.Compare(na, nb); n != 0 {
+			return n
+		}
+		i, j = ei, ej
+	}
+	if n := cmp.Compare(len(a)-i, len(b)-j); n != 0 {
+		return n
+	}
+	return strings.Compare(a, b)
+}
+
+// isDigit reports whether c is an ASCII digit
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// digitsEnd returns the index after the run of digits starting at i
+func digitsEnd(s string, i int) int {
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return i
+}
+
+// sortNotes orders notes by the sort, then by path under the collation,
+// with pinned notes ahead of the rest when pinnedFirst is set. The sort is
+// stable and notes come in byte-wise path order from the walk, so paths a
+// collation deems equal keep that order. score is only used by
+// SortRelevance.
+func sortNotes(notes []NoteInfo, by NoteSort, collation Collation, pinnedFirst bool, score func(NoteInfo) int) error {
+	by, collation, err := parseOrder(by, collation, score != nil)
+	if err != nil {
+		return err
+	}
+	if by == SortPath && collation == CollationBinary && !pinnedFirst {
+		return nil
+	}
+
+	comparePaths := collation.comparer()
+	slices.SortStableFunc(notes, func(a, b NoteInfo) int {
+		var n int
+		if pinnedFirst && a.Pinned != b.Pinned {
+			if a.Pinned {
+				return -1
+			}
+			return 1
+		}
+		switch by {
+		case SortModified:
+			n = b.Modified.Compare(a.Modified)
+		case SortCreated:
+			n = b.Created.Compare(a.Created)
+		case SortRelevance:
+			n = cmp.Compare(score(b), score(a))
+		}
+		if n != 0 {
+			return n
+		}
+		return comparePaths(a.Path, b.Path)
+	})
+	return nil
+}
+
+// parseOrder validates a sort and collation before any note is read;
+// SortRelevance needs a search to score the notes
+func parseOrder(by NoteSort, collation Collation, search bool) (NoteSort, Collation, error) {
+	by, err := ParseNoteSort(string(by))
+	if err != nil {
+		return "", "", err
+	}
+	if by == SortRelevance && !search {
+		return "", "", fmt.Errorf("sort %q only applies to searches", by)
+	}
+	collation, err = ParseCollation(string(collation))
+	if err != nil {
+		return "", "", err
+	}
+	return by, collation, nil
+}
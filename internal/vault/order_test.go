@@ -0,0 +1,143 @@
+package vault
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCompareNatural(t *testing.T) {
+	want := []string{"a.md", "a/b.md", "note.md", "note1.md", "note2.md", "note07.md", "note7.md", "note10.md", "note10a.md", "note10b.md", "v1.2.md", "v1.10.md"}
+	got := slices.Clone(want)
+	slices.Reverse(got)
+	slices.SortFunc(got, compareNatural)
+	if !slices.Equal(got, want) {
+		t.Errorf("natural order = %q, want %q", got, want)
+	}
+}
+
+func TestParseCollation(t *testing.T) {
+	for _, s := range []string{"", "binary", "Natural", "locale:de", "locale:sv-SE"} {
+		if _, err := ParseCollation(s); err != nil {
+			t.Errorf("ParseCollation(%q) error = %v", s, err)
+		}
+	}
+	for _, s := range []string{"alphabetical", "locale:", "locale:not a tag"} {
+		if _, err := ParseCollation(s); err == nil {
+			t.Errorf("ParseCollation(%q) succeeded", s)
+		}
+	}
+}
+
+func TestListOrder(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"a.md":        "#x",
+		"a/b.md":      "#x",
+		"note10.md":   "#x",
+		"note2.md":    "#x",
+		"Zebra.md":    "#x",
+		"apple.md":    "#x",
+		"ärende.md":   "#x",
+		"b/note1.md":  "#x",
+		"b/note01.md": "#x",
+	})
+	// Modified times tie for two notes, which then stay in path order
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, path := range []string{"a.md", "a/b.md", "note10.md", "note2.md", "Zebra.md", "apple.md", "ärende.md", "b/note1.md", "b/note01.md"} {
+		mtime := base.Add(time.Duration(i) * time.Hour)
+		if path == "b/note01.md" {
+			mtime = base.Add(7 * time.Hour)
+		}
+		if err := os.Chtimes(filepath.Join(tmpDir, path), mtime, mtime); err != nil {
+			t.Fatalf("Failed to set times: %v", err)
+		}
+	}
+
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		opts ListOptions
+		want []string
+	}{
+		{"byte-wise default", ListOptions{}, []string{"Zebra.md", "a.md", "a/b.md", "apple.md", "b/note01.md", "b/note1.md", "note10.md", "note2.md", "ärende.md"}},
+		{"natural", ListOptions{Collation: CollationNatural}, []string{"Zebra.md", "a.md", "a/b.md", "apple.md", "b/note01.md", "b/note1.md", "note2.md", "note10.md", "ärende.md"}},
+		{"locale", ListOptions{Collation: "locale:de"}, []string{"a.md", "a/b.md", "apple.md", "ärende.md", "b/note01.md", "b/note1.md", "note10.md", "note2.md", "Zebra.md"}},
+		{"modified", ListOptions{Sort: SortModified}, []string{"b/note01.md", "b/note1.md", "ärende.md", "apple.md", "Zebra.md", "note2.md", "note10.md", "a/b.md", "a.md"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Recursive = true
+			var first []string
+			for range 5 {
+				notes, err := v.List(ctx, tt.opts)
+				if err != nil {
+					t.Fatalf("List() error = %v", err)
+				}
+				got := make([]string, len(notes))
+				for i, note := range notes {
+					got[i] = note.Path
+				}
+				if first == nil {
+					first = got
+				} else if !slices.Equal(got, first) {
+					t.Fatalf("List() = %q, earlier %q; want the same order on every run", got, first)
+				}
+			}
+			if !slices.Equal(first, tt.want) {
+				t.Errorf("List() = %q, want %q", first, tt.want)
+			}
+		})
+	}
+
+	if _, err := v.List(ctx, ListOptions{Sort: SortRelevance}); err == nil {
+		t.Error("List() by relevance succeeded, want an error")
+	}
+	if _, err := v.List(ctx, ListOptions{Collation: "locale:not a tag"}); err == nil {
+		t.Error("List() with an unknown locale succeeded, want an error")
+	}
+}
+
+func TestSearchRelevance(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"once.md":   "kubernetes",
+		"thrice.md": "kubernetes, Kubernetes and kubernetes",
+		"twice.md":  "kubernetes and helm",
+		"b.md":      "kubernetes",
+		"none.md":   "helm",
+	})
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+
+	notes, err := v.Search(context.Background(), SearchOptions{Query: "kubernetes", QueryAny: []string{"helm", "nomad"}, Sort: SortRelevance})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(notes) != 1 || notes[0].Path != "twice.md" {
+		t.Errorf("Search() = %v, want only twice.md", notes)
+	}
+
+	notes, err = v.Search(context.Background(), SearchOptions{Query: "kubernetes", Sort: SortRelevance})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	var got []string
+	for _, note := range notes {
+		got = append(got, note.Path)
+	}
+	// Ties on the number of matches stay in path order
+	if want := []string{"thrice.md", "b.md", "once.md", "twice.md"}; !slices.Equal(got, want) {
+		t.Errorf("Search() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,135 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// OutlineOptions selects the notes and headings returned by Outline
+type OutlineOptions struct {
+	Path          string // Note to outline, or folder whose notes are outlined; empty for the whole vault
+	MaxDepth      int    // Deepest heading level included, 0 for all
+	MaxNotes      int    // Notes returned for a folder, 0 for all
+	IncludeHidden bool   // Walk files and directories whose name starts with a dot
+}
+
+// OutlineHeading is a heading with the headings nested under it
+type OutlineHeading struct {
+	Heading
+	Children []OutlineHeading `json:"children,omitempty"`
+}
+
+// NoteOutline is the heading tree of one note
+type NoteOutline struct {
+	Path     string           `json:"path"`
+	Words    int              `json:"words"` // Words in the body, frontmatter excluded
+	Headings []OutlineHeading `json:"headings"`
+}
+
+// Outline holds the heading trees of the notes selected by OutlineOptions
+type Outline struct {
+	Notes     []NoteOutline `json:"notes"`               // Sorted by path
+	Total     int           `json:"total"`               // Notes selected before MaxNotes applied
+	Truncated bool          `json:"truncated,omitempty"` // MaxNotes left notes out
+}
+
+// Outline returns the heading tree of a note, or of every note under a
+// folder in a single walk ordered by path. Headings come from the cache,
+// so outlining notes read before costs no parsing.
+func (v *vault) Outline(ctx context.Context, opts OutlineOptions) (Outline, error) {
+	if strings.HasSuffix(strings.ToLower(opts.Path), ".md") {
+		note, err := v.noteOutline(ctx, opts.Path, opts.MaxDepth)
+		if err != nil {
+			return Outline{}, err
+		}
+		return Outline{Notes: []NoteOutline{note}, Total: 1}, nil
+	}
+
+	var mu sync.Mutex
+	var notes []NoteOutline
+	scope := ListOptions{Subpath: opts.Path, Recursive: true, IncludeHidden: opts.IncludeHidden}
+	_, err := v.walkNotes(ctx, scope, func(file noteFile, entry CacheEntry) bool {
+		note := NoteOutline{
+			Path:     file.relPath,
+			Words:    CountWords(entry.Content),
+			Headings: outlineTree(entry.Headings, opts.MaxDepth),
+		}
+		mu.Lock()
+		notes = append(notes, note)
+		mu.Unlock()
+		return false
+	})
+	if err != nil {
+		return Outline{}, err
+	}
+
+	slices.SortFunc(notes, func(a, b NoteOutline) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	outline := Outline{Notes: notes, Total: len(notes)}
+	if outline.Notes == nil {
+		outline.Notes = []NoteOutline{}
+	}
+	if opts.MaxNotes > 0 && len(notes) > opts.MaxNotes {
+		outline.Notes, outline.Truncated = notes[:opts.MaxNotes], true
+	}
+	return outline, nil
+}
+
+// noteOutline returns the heading tree of the note at path
+func (v *vault) noteOutline(ctx context.Context, path string, maxDepth int) (NoteOutline, error) {
+	fullPath, err := v.validatePath(path)
+	if err != nil {
+		return NoteOutline{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return NoteOutline{}, err
+	}
+
+	stat, err := os.Stat(fullPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NoteOutline{}, ErrNoteNotFound
+		}
+		return NoteOutline{}, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	entry, err := v.loadEntry(fullPath, stat.ModTime())
+	if err != nil {
+		return NoteOutline{}, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return NoteOutline{
+		Path:     v.relPath(fullPath),
+		Words:    CountWords(entry.Content),
+		Headings: outlineTree(entry.Headings, maxDepth),
+	}, nil
+}
+
+// outlineTree nests headings under the nearest heading above them with a
+// lower level, leaving out those deeper than maxDepth unless it is 0
+func outlineTree(headings []Heading, maxDepth int) []OutlineHeading {
+	if maxDepth > 0 {
+		headings = slices.DeleteFunc(slices.Clone(headings), func(h Heading) bool {
+			return h.Level > maxDepth
+		})
+	}
+	tree, _ := outlineLevel(headings, 0, 0)
+	return tree
+}
+
+// outlineLevel builds the headings from index i on that are deeper than
+// level, returning them and the index of the first heading not included
+func outlineLevel(headings []Heading, i, level int) ([]OutlineHeading, int) {
+	nodes := []OutlineHeading{}
+	for i < len(headings) && headings[i].Level > level {
+		node := OutlineHeading{Heading: headings[i]}
+		node.Children, i = outlineLevel(headings, i+1, headings[i].Level)
+		nodes = append(nodes, node)
+	}
+	return nodes, i
+}
@@ -0,0 +1,78 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestOutline(t *testing.T) {
+	tmpDir := t.TempDir()
+	notes := map[string]string{
+		"Book/02 middle.md": "# Part two\n\nSome words\n\n## Scene\n\nMore words here\n\n### Beat\n\nx\n\n## Other\n",
+		"Book/01 start.md":  "Opening\n=======\n\nFirst chapter text\n\nSubplot\n-------\n\nhere\n",
+		"Book/03 end.md":    "No headings at all",
+		"elsewhere.md":      "# Not in the book",
+	}
+	writeFiles(t, tmpDir, notes)
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	ctx := context.Background()
+
+	t.Run("note", func(t *testing.T) {
+		outline, err := v.Outline(ctx, OutlineOptions{Path: "Book/02 middle.md"})
+		if err != nil {
+			t.Fatalf("Outline failed: %v", err)
+		}
+		want := []OutlineHeading{{
+			Heading: Heading{Level: 1, Text: "Part two", Line: 1, Words: 6},
+			Children: []OutlineHeading{
+				{
+					Heading:  Heading{Level: 2, Text: "Scene", Line: 5, Words: 4},
+					Children: []OutlineHeading{{Heading: Heading{Level: 3, Text: "Beat", Line: 9, Words: 1}, Children: []OutlineHeading{}}},
+				},
+				{Heading: Heading{Level: 2, Text: "Other", Line: 13}, Children: []OutlineHeading{}},
+			},
+		}}
+		if len(outline.Notes) != 1 || !reflect.DeepEqual(outline.Notes[0].Headings, want) {
+			t.Errorf("Outline = %+v, want %+v", outline.Notes, want)
+		}
+	})
+
+	t.Run("max depth", func(t *testing.T) {
+		outline, err := v.Outline(ctx, OutlineOptions{Path: "Book/02 middle.md", MaxDepth: 1})
+		if err != nil {
+			t.Fatalf("Outline failed: %v", err)
+		}
+		if headings := outline.Notes[0].Headings; len(headings) != 1 || len(headings[0].Children) != 0 || headings[0].Words != 6 {
+			t.Errorf("Headings = %+v, want only Part two with its full word count", headings)
+		}
+	})
+
+	t.Run("folder", func(t *testing.T) {
+		outline, err := v.Outline(ctx, OutlineOptions{Path: "Book", MaxNotes: 2})
+		if err != nil {
+			t.Fatalf("Outline failed: %v", err)
+		}
+		var paths []string
+		for _, note := range outline.Notes {
+			paths = append(paths, note.Path)
+		}
+		if want := []string{"Book/01 start.md", "Book/02 middle.md"}; !reflect.DeepEqual(paths, want) || outline.Total != 3 || !outline.Truncated {
+			t.Errorf("Outline = %v (total %d, truncated %v), want %v of 3", paths, outline.Total, outline.Truncated, want)
+		}
+		start := outline.Notes[0].Headings
+		if len(start) != 1 || start[0].Text != "Opening" || len(start[0].Children) != 1 || start[0].Children[0].Text != "Subplot" {
+			t.Errorf("Setext outline = %+v", start)
+		}
+	})
+
+	t.Run("missing note", func(t *testing.T) {
+		if _, err := v.Outline(ctx, OutlineOptions{Path: "Book/99.md"}); !errors.Is(err, ErrNoteNotFound) {
+			t.Errorf("Expected ErrNoteNotFound, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,315 @@
+package vault
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// pinsFile stores the pinned notes, below the data directory
+const pinsFile = "pins.json"
+
+// Pin is a note kept in the working set: always cached and, on request,
+// listed first
+type Pin struct {
+	Path    string    `json:"path"`
+	Pinned  time.Time `json:"pinned"`           // When the note was pinned, or last re-pinned
+	Expires time.Time `json:"expires,omitzero"` // When the pin lapses, zero for never
+}
+
+// PinSet is the working set of pinned notes
+type PinSet struct {
+	Pins []Pin `json:"pins"` // Sorted by path
+
+	// SessionOnly is set when the pins could not be saved in the data
+	// directory; they then last until the server stops
+	SessionOnly bool `json:"session_only"`
+}
+
+// pinData is the content of pinsFile
+type pinData struct {
+	Pins []Pin `json:"pins"` // Sorted by path
+}
+
+// pinStore reads and replaces the pins file. Updates hold a lock file next
+// to it, so server processes sharing the vault never lose each other's
+// pins. When the file cannot be written the pins are kept in memory from
+// then on.
+// The zero value with file set is ready to use
+type pinStore struct {
+	file string
+
+	mu          sync.Mutex
+	sessionOnly bool  // The file could not be written; session holds the pins
+	session     []Pin // Pins kept in memory once sessionOnly
+}
+
+// load returns the pins that have not expired by now and whether they are
+// session-only, none if the file does not exist yet. Expired pins stay in
+// the file until the next update.
+func (s *pinStore) load(now time.Time) ([]Pin, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessionOnly {
+		return activePins(s.session, now), true, nil
+	}
+	pins, err := s.read()
+	return activePins(pins, now), false, err
+}
+
+// read returns the pins in the file, expired ones included
+func (s *pinStore) read() ([]Pin, error) {
+	raw, err := os.ReadFile(s.file)
+	if os.IsNotExist(err) || errors.Is(err, syscall.ENOTDIR) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pins: %w", err)
+	}
+	var data pinData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", pinsFile, err)
+	}
+	return data.Pins, nil
+}
+
+// update applies fn to the pins that have not expired by now and saves
+// them when fn reports a change. When the file cannot be written, the
+// pins are kept in memory from then on. Returns the pins after the update
+// and whether they are session-only.
+func (s *pinStore) update(ctx context.Context, now time.Time, fn func([]Pin) ([]Pin, bool)) ([]Pin, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessionOnly {
+		s.session, _ = fn(activePins(s.session, now))
+		return slices.Clone(s.session), true, nil
+	}
+
+	var pins []Pin
+	err := s.updateFile(ctx, func(stored []Pin) ([]Pin, bool) {
+		var changed bool
+		pins, changed = fn(activePins(stored, now))
+		// Dropping expired pins alone is no reason to write
+		return pins, changed
+	})
+	if !errors.Is(err, errPinsUnwritable) || ctx.Err() != nil {
+		return pins, false, err
+	}
+
+	// The data directory cannot be written: carry on in memory
+	stored, err := s.read()
+	if err != nil {
+		return nil, false, err
+	}
+	pins, _ = fn(activePins(stored, now))
+	s.sessionOnly, s.session = true, pins
+	return slices.Clone(pins), true, nil
+}
+
+// errPinsUnwritable marks a failure to save the pins file
+var errPinsUnwritable = errors.New("pins file cannot be written")
+
+// updateFile rewrites the pins file with fn applied to its pins under the
+// file's lock, when fn reports a change
+func (s *pinStore) updateFile(ctx context.Context, fn func([]Pin) ([]Pin, bool)) error {
+	if err := os.MkdirAll(filepath.Dir(s.file), 0755); err != nil {
+		return fmt.Errorf("%w: %w", errPinsUnwritable, err)
+	}
+	unlock, err := lockDataFile(ctx, s.file)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errPinsUnwritable, err)
+	}
+	defer unlock()
+
+	stored, err := s.read()
+	if err != nil {
+		return err
+	}
+	pins, changed := fn(stored)
+	if !changed {
+		return nil
+	}
+	raw, err := json.MarshalIndent(pinData{Pins: pins}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode pins: %w", err)
+	}
+	if err := writeDataFile(s.file, raw); err != nil {
+		return fmt.Errorf("%w: %w", errPinsUnwritable, err)
+	}
+	return nil
+}
+
+// activePins returns the pins that have not expired by now, sorted by path
+func activePins(pins []Pin, now time.Time) []Pin {
+	pins = slices.DeleteFunc(slices.Clone(pins), func(p Pin) bool {
+		return !p.Expires.IsZero() && !p.Expires.After(now)
+	})
+	slices.SortFunc(pins, func(a, b Pin) int { return cmp.Compare(a.Path, b.Path) })
+	return pins
+}
+
+// comparePin orders a pin against a path
+func comparePin(p Pin, path string) int {
+	return cmp.Compare(p.Path, path)
+}
+
+// removePin drops the pin of path, reporting whether there was one
+func removePin(pins []Pin, path string) ([]Pin, bool) {
+	i, found := slices.BinarySearchFunc(pins, path, comparePin)
+	if !found {
+		return pins, false
+	}
+	return slices.Delete(pins, i, i+1), true
+}
+
+// PinNote adds the note at path to the working set, for ttl or, when ttl
+// is zero, until unpinned. Pinning a pinned note again restarts its pin.
+// The note is loaded into the cache and kept there.
+func (v *vault) PinNote(ctx context.Context, path string, ttl time.Duration) (PinSet, error) {
+	if ttl < 0 {
+		return PinSet{}, fmt.Errorf("%w: duration %s is negative", ErrInvalidPin, ttl)
+	}
+	fullPath, err := v.validatePath(path)
+	if err != nil {
+		return PinSet{}, err
+	}
+	if _, err := os.Stat(fullPath); err != nil {
+		if os.IsNotExist(err) {
+			return PinSet{}, ErrNoteNotFound
+		}
+		return PinSet{}, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	now := time.Now().UTC()
+	pin := Pin{Path: v.relPath(fullPath), Pinned: now}
+	if ttl > 0 {
+		pin.Expires = now.Add(ttl)
+	}
+	pins, sessionOnly, err := v.pins.update(ctx, now, func(pins []Pin) ([]Pin, bool) {
+		i, found := slices.BinarySearchFunc(pins, pin.Path, comparePin)
+		if found {
+			pins[i] = pin
+			return pins, true
+		}
+		return slices.Insert(pins, i, pin), true
+	})
+	if err != nil {
+		return PinSet{}, err
+	}
+	return v.applyPins(ctx, pins, sessionOnly), nil
+}
+
+// UnpinNote removes the note at path from the working set. Unpinning a
+// note that is not pinned changes nothing.
+func (v *vault) UnpinNote(ctx context.Context, path string) (PinSet, error) {
+	fullPath, err := v.validatePath(path)
+	if err != nil {
+		return PinSet{}, err
+	}
+	relPath := v.relPath(fullPath)
+
+	pins, sessionOnly, err := v.pins.update(ctx, time.Now().UTC(), func(pins []Pin) ([]Pin, bool) {
+		return removePin(pins, relPath)
+	})
+	if err != nil {
+		return PinSet{}, err
+	}
+	return v.applyPins(ctx, pins, sessionOnly), nil
+}
+
+// ListPinned returns the working set, without pins that have expired
+func (v *vault) ListPinned(ctx context.Context) (PinSet, error) {
+	if err := ctx.Err(); err != nil {
+		return PinSet{}, err
+	}
+	if err := v.checkAvailable(); err != nil {
+		return PinSet{}, err
+	}
+	pins, sessionOnly, err := v.pins.load(time.Now())
+	if err != nil {
+		return PinSet{}, err
+	}
+	return v.applyPins(ctx, pins, sessionOnly), nil
+}
+
+// applyPins makes the cache keep the pinned notes and loads any that are
+// not cached, such as notes evicted before they were pinned or changed
+// since they were last read
+func (v *vault) applyPins(ctx context.Context, pins []Pin, sessionOnly bool) PinSet {
+	paths := make([]string, len(pins))
+	for i, pin := range pins {
+		paths[i] = filepath.Join(v.basePath, filepath.FromSlash(pin.Path))
+	}
+	v.cache.Pin(paths)
+
+	for _, fullPath := range paths {
+		if ctx.Err() != nil {
+			break
+		}
+		stat, err := os.Stat(fullPath)
+		if err != nil {
+			continue // Moved or deleted outside the server
+		}
+		if _, err := v.loadEntry(fullPath, stat.ModTime()); err != nil {
+			v.logger.Warn("loading pinned note failed", "path", v.relPath(fullPath), "error", err)
+		}
+	}
+
+	if pins == nil {
+		pins = []Pin{}
+	}
+	return PinSet{Pins: pins, SessionOnly: sessionOnly}
+}
+
+// markPinned sets Pinned on the listed notes in the working set and keeps
+// the working set cached. Failing to read the pins only loses the marks.
+func (v *vault) markPinned(ctx context.Context, notes []NoteInfo) {
+	pins, sessionOnly, err := v.pins.load(time.Now())
+	if err != nil {
+		v.logger.Warn("reading pins failed", "error", err)
+		return
+	}
+	v.applyPins(ctx, pins, sessionOnly)
+	if len(pins) == 0 {
+		return
+	}
+	for i, note := range notes {
+		_, notes[i].Pinned = slices.BinarySearchFunc(pins, note.Path, comparePin)
+	}
+}
+
+// movePins re-keys pins after a rename, logging failures: the move
+// itself has already happened
+func (v *vault) movePins(from, to string) {
+	_, _, err := v.pins.update(context.Background(), time.Now().UTC(), func(pins []Pin) ([]Pin, bool) {
+		var changed bool
+		for i, pin := range pins {
+			if newPath, ok := movedPath(pin.Path, from, to); ok {
+				pins[i].Path, changed = newPath, true
+			}
+		}
+		slices.SortFunc(pins, func(a, b Pin) int { return comparePin(a, b.Path) })
+		return pins, changed
+	})
+	if err != nil {
+		v.logger.Warn("moving pins failed", "path", from, "new_path", to, "error", err)
+	}
+}
+
+// dropPin removes the pin of a note that no longer exists
+func (v *vault) dropPin(path string) {
+	_, _, err := v.pins.update(context.Background(), time.Now().UTC(), func(pins []Pin) ([]Pin, bool) {
+		return removePin(pins, path)
+	})
+	if err != nil {
+		v.logger.Warn("dropping pin failed", "path", path, "error", err)
+	}
+}
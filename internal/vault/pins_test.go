@@ -0,0 +1,155 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// pinPaths returns the paths of the pins in set
+func pinPaths(set PinSet) []string {
+	paths := make([]string, len(set.Pins))
+	for i, pin := range set.Pins {
+		paths[i] = pin.Path
+	}
+	return paths
+}
+
+func TestPinNote(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"Alpha.md":         "alpha plan",
+		"Beta.md":          "beta plan",
+		"Projects/Plan.md": "the plan",
+	})
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+
+	if _, err := v.PinNote(ctx, "Projects/Plan.md", 0); err != nil {
+		t.Fatalf("PinNote() error = %v", err)
+	}
+	set, err := v.PinNote(ctx, "Beta.md", 8*time.Hour)
+	if err != nil {
+		t.Fatalf("PinNote() error = %v", err)
+	}
+	if got := pinPaths(set); !slices.Equal(got, []string{"Beta.md", "Projects/Plan.md"}) || set.SessionOnly {
+		t.Errorf("PinNote() = %+v, want Beta.md and Projects/Plan.md saved", set)
+	}
+	if set.Pins[0].Expires.Sub(set.Pins[0].Pinned) != 8*time.Hour || !set.Pins[1].Expires.IsZero() {
+		t.Errorf("PinNote() expiries = %v and %v, want 8h and none", set.Pins[0].Expires, set.Pins[1].Expires)
+	}
+
+	// Pins are marked in lists, and first only when asked for
+	notes, err := v.List(ctx, ListOptions{Recursive: true})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	var pinned []string
+	for _, note := range notes {
+		if note.Pinned {
+			pinned = append(pinned, note.Path)
+		}
+	}
+	if !slices.Equal(pinned, []string{"Beta.md", "Projects/Plan.md"}) || notes[0].Path != "Alpha.md" {
+		t.Errorf("List() = %+v, want Beta.md and Projects/Plan.md pinned in path order", notes)
+	}
+	notes, _ = v.List(ctx, ListOptions{Recursive: true, PinnedFirst: true})
+	if got := notePaths(notes); !slices.Equal(got, []string{"Beta.md", "Projects/Plan.md", "Alpha.md"}) {
+		t.Errorf("List() pinned first = %v", got)
+	}
+	notes, _ = v.Search(ctx, SearchOptions{Query: "plan", Sort: SortRelevance, PinnedFirst: true})
+	if len(notes) != 3 || !notes[0].Pinned || !notes[1].Pinned || notes[2].Pinned {
+		t.Errorf("Search() pinned first = %+v, want the pinned notes first", notes)
+	}
+
+	// Pins follow moved notes
+	if _, err := v.MoveNote(ctx, MoveNoteOptions{Path: "Projects/Plan.md", NewPath: "Plan.md"}); err != nil {
+		t.Fatalf("MoveNote() error = %v", err)
+	}
+	set, _ = v.ListPinned(ctx)
+	if got := pinPaths(set); !slices.Equal(got, []string{"Beta.md", "Plan.md"}) {
+		t.Errorf("ListPinned() after move = %v", got)
+	}
+
+	set, err = v.UnpinNote(ctx, "Beta.md")
+	if err != nil || !slices.Equal(pinPaths(set), []string{"Plan.md"}) {
+		t.Errorf("UnpinNote() = %+v, %v; want Plan.md left", set, err)
+	}
+	if set, err := v.UnpinNote(ctx, "Alpha.md"); err != nil || len(set.Pins) != 1 {
+		t.Errorf("UnpinNote() of a note not pinned = %+v, %v", set, err)
+	}
+
+	if _, err := v.PinNote(ctx, "Missing.md", 0); !errors.Is(err, ErrNoteNotFound) {
+		t.Errorf("PinNote() of a missing note error = %v, want ErrNoteNotFound", err)
+	}
+	if _, err := v.PinNote(ctx, "Alpha.md", -time.Hour); !errors.Is(err, ErrInvalidPin) {
+		t.Errorf("PinNote() with a negative duration error = %v, want ErrInvalidPin", err)
+	}
+}
+
+func TestPinsExpire(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{"Old.md": "old", "New.md": "new"})
+
+	past := time.Now().Add(-time.Hour).UTC()
+	data, _ := json.Marshal(pinData{Pins: []Pin{
+		{Path: "New.md", Pinned: past},
+		{Path: "Old.md", Pinned: past.Add(-time.Hour), Expires: past},
+	}})
+	writeFiles(t, tmpDir, map[string]string{filepath.Join(dataDir, pinsFile): string(data)})
+
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	set, err := v.ListPinned(ctx)
+	if err != nil || !slices.Equal(pinPaths(set), []string{"New.md"}) {
+		t.Errorf("ListPinned() = %+v, %v; want only New.md", set, err)
+	}
+
+	// The expired pin stays in the file until the next update drops it
+	if _, err := v.UnpinNote(ctx, "New.md"); err != nil {
+		t.Fatalf("UnpinNote() error = %v", err)
+	}
+	raw, _ := os.ReadFile(filepath.Join(tmpDir, dataDir, pinsFile))
+	var stored pinData
+	if err := json.Unmarshal(raw, &stored); err != nil || len(stored.Pins) != 0 {
+		t.Errorf("pins file = %s, want no pins", raw)
+	}
+}
+
+func TestPinsSessionOnly(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	// A file where the data directory belongs cannot hold the pins
+	writeFiles(t, tmpDir, map[string]string{"Note.md": "note", dataDir: "not a folder"})
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+
+	set, err := v.PinNote(ctx, "Note.md", 0)
+	if err != nil {
+		t.Fatalf("PinNote() error = %v", err)
+	}
+	if !set.SessionOnly || !slices.Equal(pinPaths(set), []string{"Note.md"}) {
+		t.Errorf("PinNote() = %+v, want Note.md pinned for the session", set)
+	}
+	set, err = v.ListPinned(ctx)
+	if err != nil || !set.SessionOnly || len(set.Pins) != 1 {
+		t.Errorf("ListPinned() = %+v, %v; want the session pin", set, err)
+	}
+	notes, _ := v.List(ctx, ListOptions{})
+	if len(notes) != 1 || !notes[0].Pinned {
+		t.Errorf("List() = %+v, want Note.md pinned", notes)
+	}
+}
@@ -0,0 +1,388 @@
+package vault
+
+import (
+	"context"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Weights of the placement signals
+const (
+	placementTagWeight   = 0.6 // Share of the notes with the draft's tags kept in the folder
+	placementTypeWeight  = 0.2 // The draft would have the folder's most common type
+	placementTitleWeight = 0.2 // Similarity of the draft's title to the names in the folder
+)
+
+// Placement limits
+const (
+	DefaultPlacementLimit = 3
+	MaxPlacementLimit     = 10
+	maxPlacementExamples  = 3   // Example notes per suggested folder
+	placementAddTags      = 5   // Tags suggested per folder
+	minNameSimilarity     = 0.6 // Edit similarity below which names count as unrelated
+)
+
+// PlacementOptions describes a draft to find a folder for
+type PlacementOptions struct {
+	Content string // Draft content, with or without frontmatter
+	Title   string // Proposed title, else the frontmatter title or first heading
+	Limit   int    // Maximum suggestions, DefaultPlacementLimit when 0 or less
+}
+
+// PlacementScore breaks a placement confidence down by signal, each from
+// 0 to 1
+type PlacementScore struct {
+	Tags  float64 `json:"tags"`
+	Type  float64 `json:"type"`
+	Title float64 `json:"title"`
+}
+
+// PlacementSuggestion is a folder a draft could be created in
+type PlacementSuggestion struct {
+	Folder     string         `json:"folder"`     // Vault-relative folder, "/" for the vault root
+	Confidence float64        `json:"confidence"` // Weighted signals, from 0 to 1
+	Breakdown  PlacementScore `json:"breakdown"`
+	Examples   []string       `json:"examples"`           // Notes in the folder most like the draft
+	AddTags    []string       `json:"add_tags,omitempty"` // Tags to give the draft to fit in
+	Fallback   bool           `json:"fallback,omitempty"` // The inbox, as nothing in the vault matched
+}
+
+// Placement holds the folders suggested for a draft, best first
+type Placement struct {
+	Title       string                `json:"title,omitempty"` // Title compared with the names in each folder
+	Tags        []string              `json:"tags"`            // The draft's tags and the vault tags its words name
+	Suggestions []PlacementSuggestion `json:"suggestions"`
+
+	// UncachedNotes counts the notes not in the note cache yet, which were
+	// compared by name only
+	UncachedNotes int `json:"uncached_notes,omitempty"`
+}
+
+// placementNote is a note compared with the draft
+type placementNote struct {
+	path  string
+	name  string              // File name without .md
+	title string              // Frontmatter title, if cached
+	tags  map[string]struct{} // Folded tags, if cached
+}
+
+// placementFolder gathers the notes directly in a folder
+type placementFolder struct {
+	notes  []placementNote
+	cached int            // Notes whose tags and type are known
+	tags   map[string]int // Folded tag -> notes carrying it
+	types  map[string]int // Note type -> notes of it
+}
+
+// SuggestPlacement ranks the folders a draft could be created in by how
+// many of the notes sharing its tags they hold, whether the draft would
+// have the type most of their notes have, other than the default type,
+// and how close its title is to their note names. Tags, titles and
+// frontmatter come from the note cache and names from the path listing,
+// so nothing is read; notes not cached yet count by name only. When no
+// folder matches, the folder of the capture inbox is suggested. Equal
+// input gives equal output.
+func (v *vault) SuggestPlacement(ctx context.Context, opts PlacementOptions) (Placement, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultPlacementLimit
+	}
+	limit = min(limit, MaxPlacementLimit)
+
+	candidates, err := v.noteCandidates(ctx)
+	if err != nil {
+		return Placement{}, err
+	}
+
+	// Group the notes by folder, with the tags and types of those cached
+	result := Placement{Tags: []string{}, Suggestions: []PlacementSuggestion{}}
+	folders := make(map[string]*placementFolder)
+	vaultTags := make(map[string]int)   // Folded tag -> notes carrying it
+	tagNames := make(map[string]string) // Folded tag -> first spelling seen
+	for _, c := range candidates {
+		if err := ctx.Err(); err != nil {
+			return Placement{}, err
+		}
+		dir := path.Dir(c.path)
+		folder := folders[dir]
+		if folder == nil {
+			folder = &placementFolder{tags: make(map[string]int), types: make(map[string]int)}
+			folders[dir] = folder
+		}
+
+		note := placementNote{path: c.path, name: strings.TrimSuffix(path.Base(c.path), ".md")}
+		entry, ok := v.cache.Metadata(filepath.Join(v.basePath, filepath.FromSlash(c.path)))
+		if !ok {
+			result.UncachedNotes++
+			folder.notes = append(folder.notes, note)
+			continue
+		}
+		note.title, _ = v.noteTitle(c.path, entry)
+		note.tags = make(map[string]struct{}, len(entry.Tags))
+		for _, tag := range entry.Tags {
+			key := foldTag(tag)
+			if _, seen := note.tags[key]; seen {
+				continue
+			}
+			note.tags[key] = struct{}{}
+			folder.tags[key]++
+			vaultTags[key]++
+			if _, named := tagNames[key]; !named {
+				tagNames[key] = strings.TrimPrefix(tag, "#")
+			}
+		}
+		if v.types.configured() {
+			folder.types[v.types.classify(c.path, entry.Properties)]++
+		}
+		folder.cached++
+		folder.notes = append(folder.notes, note)
+	}
+
+	// The draft's own tags, and the vault tags its words name
+	draft := newCacheEntry(opts.Content, time.Time{})
+	result.Title = placementTitle(opts.Title, draft)
+	explicit := make(map[string]struct{})
+	for _, tag := range draft.Tags {
+		key := foldTag(tag)
+		if _, seen := explicit[key]; !seen {
+			explicit[key] = struct{}{}
+			result.Tags = append(result.Tags, strings.TrimPrefix(tag, "#"))
+		}
+	}
+	words := make(map[string]struct{})
+	for _, term := range contentTerms(result.Title + "\n" + draft.Content) {
+		words[foldText(term)] = struct{}{}
+	}
+	var mentioned, known []string // Folded tags
+	for key := range vaultTags {
+		if _, ok := explicit[key]; ok {
+			known = append(known, key)
+			continue
+		}
+		if _, ok := words[key[strings.LastIndex(key, "/")+1:]]; ok {
+			mentioned = append(mentioned, key)
+			known = append(known, key)
+		}
+	}
+	slices.Sort(mentioned)
+	slices.Sort(known)
+	for _, key := range mentioned {
+		result.Tags = append(result.Tags, tagNames[key])
+	}
+	slices.Sort(result.Tags)
+
+	draftTags := make(map[string]struct{}, len(known))
+	for _, key := range known {
+		draftTags[key] = struct{}{}
+	}
+
+	// Weigh only the signals the draft and the vault provide
+	var weights float64
+	if len(known) > 0 {
+		weights += placementTagWeight
+	}
+	if v.types.configured() {
+		weights += placementTypeWeight
+	}
+	if result.Title != "" {
+		weights += placementTitleWeight
+	}
+
+	dirs := make([]string, 0, len(folders))
+	for dir := range folders {
+		dirs = append(dirs, dir)
+	}
+	slices.Sort(dirs)
+	for _, dir := range dirs {
+		if weights == 0 {
+			break
+		}
+		folder := folders[dir]
+		var score PlacementScore
+		for _, key := range known {
+			score.Tags += float64(folder.tags[key]) / float64(vaultTags[key])
+		}
+		if len(known) > 0 {
+			score.Tags /= float64(len(known))
+		}
+		if v.types.configured() && folder.cached > 0 {
+			name := result.Title
+			if name == "" {
+				name = "Untitled"
+			}
+			if dominant := dominantType(folder.types); dominant != v.types.fallback && v.types.classify(path.Join(dir, name+".md"), draft.Properties) == dominant {
+				score.Type = 1
+			}
+		}
+
+		// Keep each note's similarity to rank the examples by
+		similarity := make(map[string]float64, len(folder.notes))
+		if result.Title != "" {
+			for _, note := range folder.notes {
+				similarity[note.path] = max(nameSimilarity(result.Title, note.name), nameSimilarity(result.Title, note.title))
+				score.Title = max(score.Title, similarity[note.path])
+			}
+		}
+
+		confidence := (placementTagWeight*score.Tags + placementTypeWeight*score.Type + placementTitleWeight*score.Title) / weights
+		if confidence == 0 {
+			continue
+		}
+		suggestion := PlacementSuggestion{
+			Folder:     folderName(dir),
+			Confidence: roundScore(confidence),
+			Breakdown:  PlacementScore{Tags: roundScore(score.Tags), Type: score.Type, Title: roundScore(score.Title)},
+			Examples:   placementExamples(folder.notes, draftTags, similarity),
+			AddTags:    placementTags(folder, explicit, mentioned, tagNames),
+		}
+		result.Suggestions = append(result.Suggestions, suggestion)
+	}
+
+	sort.SliceStable(result.Suggestions, func(i, j int) bool {
+		return result.Suggestions[i].Confidence > result.Suggestions[j].Confidence
+	})
+	if len(result.Suggestions) > limit {
+		result.Suggestions = result.Suggestions[:limit]
+	}
+	if len(result.Suggestions) == 0 {
+		result.Suggestions = append(result.Suggestions, PlacementSuggestion{
+			Folder:   folderName(path.Dir(v.capture.Note)),
+			Examples: []string{},
+			Fallback: true,
+		})
+	}
+	return result, nil
+}
+
+// placementTitle returns the title a draft is compared by: the one
+// proposed, its frontmatter title or its first top-level heading
+func placementTitle(proposed string, draft CacheEntry) string {
+	if title := strings.TrimSpace(proposed); title != "" {
+		return title
+	}
+	if draft.Title != "" {
+		return draft.Title
+	}
+	for _, heading := range draft.Headings {
+		if heading.Level == 1 {
+			return heading.Text
+		}
+	}
+	return ""
+}
+
+// nameSimilarity compares two note names from 0 to 1: the share of their
+// words in common, or how few edits apart they are when that is closer
+func nameSimilarity(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+
+	termsA, termsB := make(map[string]struct{}), make(map[string]struct{})
+	for _, term := range contentTerms(a) {
+		termsA[foldText(term)] = struct{}{}
+	}
+	for _, term := range contentTerms(b) {
+		termsB[foldText(term)] = struct{}{}
+	}
+	var shared int
+	for term := range termsA {
+		if _, ok := termsB[term]; ok {
+			shared++
+		}
+	}
+	var similarity float64
+	if union := len(termsA) + len(termsB) - shared; union > 0 {
+		similarity = float64(shared) / float64(union)
+	}
+
+	slugA, slugB := slugName(a), slugName(b)
+	if longest := max(len([]rune(slugA)), len([]rune(slugB))); longest > 0 {
+		edits := 1 - float64(levenshtein(slugA, slugB))/float64(longest)
+		if edits >= minNameSimilarity {
+			similarity = max(similarity, edits)
+		}
+	}
+	return similarity
+}
+
+// dominantType returns the type most notes of a folder have, the first by
+// name on a tie
+func dominantType(types map[string]int) string {
+	var best string
+	for name, n := range types {
+		if n > types[best] || (n == types[best] && name < best) {
+			best = name
+		}
+	}
+	return best
+}
+
+// placementExamples returns the notes of a folder sharing the most of the
+// draft's tags, then with the closest names, at most maxPlacementExamples
+func placementExamples(notes []placementNote, draftTags map[string]struct{}, similarity map[string]float64) []string {
+	shared := make(map[string]int, len(notes))
+	for _, note := range notes {
+		for key := range note.tags {
+			if _, ok := draftTags[key]; ok {
+				shared[note.path]++
+			}
+		}
+	}
+	ranked := slices.Clone(notes)
+	slices.SortFunc(ranked, func(a, b placementNote) int {
+		if shared[a.path] != shared[b.path] {
+			return shared[b.path] - shared[a.path]
+		}
+		if similarity[a.path] != similarity[b.path] {
+			if similarity[a.path] > similarity[b.path] {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.path, b.path)
+	})
+
+	examples := make([]string, 0, maxPlacementExamples)
+	for _, note := range ranked[:min(len(ranked), maxPlacementExamples)] {
+		examples = append(examples, note.path)
+	}
+	return examples
+}
+
+// placementTags returns the tags to suggest for a draft placed in folder:
+// the vault tags its words name and the tags at least half the cached
+// notes there carry, leaving out those it has, most common there first
+func placementTags(folder *placementFolder, explicit map[string]struct{}, mentioned []string, tagNames map[string]string) []string {
+	keys := slices.Clone(mentioned)
+	for key, n := range folder.tags {
+		if _, ok := explicit[key]; !ok && n >= 2 && 2*n >= folder.cached && !slices.Contains(mentioned, key) {
+			keys = append(keys, key)
+		}
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		if folder.tags[a] != folder.tags[b] {
+			return folder.tags[b] - folder.tags[a]
+		}
+		return strings.Compare(a, b)
+	})
+
+	var tags []string
+	for _, key := range keys[:min(len(keys), placementAddTags)] {
+		tags = append(tags, tagNames[key])
+	}
+	return tags
+}
+
+// folderName returns the vault-relative folder dir as reported, "/" for
+// the vault root
+func folderName(dir string) string {
+	if dir == "." || dir == "" {
+		return "/"
+	}
+	return dir
+}
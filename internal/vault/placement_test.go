@@ -0,0 +1,90 @@
+package vault
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestSuggestPlacement(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"Projects/garden.md":    "# Garden plan\n#garden #project\n",
+		"Projects/balcony.md":   "# Balcony herbs\n#garden\n",
+		"Projects/taxes.md":     "# Taxes\n#project\n",
+		"Archive/old-garden.md": "# Old garden\n#Garden\n",
+		"People/Ann.md":         "---\ncategory: person\n---\nMet at the market\n",
+		"Journal/2024-03-01.md": "Planted seeds\n",
+	})
+	settings := NoteTypeSettings{Rules: []NoteTypeRule{{Type: "person", Properties: map[string]any{"category": "person"}}}}
+	vi, err := NewVault(tmpDir, WithNoteTypes(settings), WithWarmCache(2))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	v := vi.(*vault)
+	v.StartWarmup(ctx)
+	waitWarmup(t, v)
+
+	// garden is named in the text: two of its three notes are in Projects
+	draft := PlacementOptions{Content: "Raised beds for the garden\n", Title: "Raised beds"}
+	placement, err := v.SuggestPlacement(ctx, draft)
+	if err != nil {
+		t.Fatalf("SuggestPlacement() error = %v", err)
+	}
+	if !reflect.DeepEqual(placement.Tags, []string{"garden"}) || placement.UncachedNotes != 0 {
+		t.Errorf("Tags, UncachedNotes = %v, %d, want [garden], 0", placement.Tags, placement.UncachedNotes)
+	}
+	var folders []string
+	for _, s := range placement.Suggestions {
+		folders = append(folders, s.Folder)
+	}
+	if want := []string{"Projects", "Archive"}; !reflect.DeepEqual(folders, want) {
+		t.Fatalf("folders = %v, want %v", folders, want)
+	}
+	best := placement.Suggestions[0]
+	if best.Breakdown.Tags != 0.667 || best.Confidence <= placement.Suggestions[1].Confidence {
+		t.Errorf("best = %+v, want a tag score of 0.667 above Archive", best)
+	}
+	if want := []string{"Projects/balcony.md", "Projects/garden.md", "Projects/taxes.md"}; !reflect.DeepEqual(best.Examples, want) {
+		t.Errorf("Examples = %v, want %v", best.Examples, want)
+	}
+	if want := []string{"garden", "project"}; !reflect.DeepEqual(best.AddTags, want) {
+		t.Errorf("AddTags = %v, want %v", best.AddTags, want)
+	}
+
+	// Identical input gives identical output
+	again, err := v.SuggestPlacement(ctx, draft)
+	if err != nil || !reflect.DeepEqual(again, placement) {
+		t.Errorf("second SuggestPlacement() = %+v, %v, want %+v", again, err, placement)
+	}
+
+	// The draft's type matches the folder's; its explicit tag is not
+	// suggested again
+	placement, err = v.SuggestPlacement(ctx, PlacementOptions{Content: "---\ncategory: person\n---\n#contact\nBob from the market\n", Title: "Bob", Limit: 1})
+	if err != nil {
+		t.Fatalf("SuggestPlacement() error = %v", err)
+	}
+	if len(placement.Suggestions) != 1 || placement.Suggestions[0].Folder != "People" || placement.Suggestions[0].Breakdown.Type != 1 {
+		t.Errorf("Suggestions = %+v, want People by type", placement.Suggestions)
+	}
+	if !reflect.DeepEqual(placement.Tags, []string{"contact"}) {
+		t.Errorf("Tags = %v, want [contact]", placement.Tags)
+	}
+}
+
+func TestSuggestPlacementFallback(t *testing.T) {
+	v, err := NewVault(t.TempDir(), WithCapture(CaptureSettings{Note: "Inbox/Captured.md"}))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+
+	placement, err := v.SuggestPlacement(context.Background(), PlacementOptions{Content: "# Anything\n#new\n"})
+	if err != nil {
+		t.Fatalf("SuggestPlacement() error = %v", err)
+	}
+	want := []PlacementSuggestion{{Folder: "Inbox", Examples: []string{}, Fallback: true}}
+	if !reflect.DeepEqual(placement.Suggestions, want) || placement.Title != "Anything" {
+		t.Errorf("SuggestPlacement() = %+v, want the inbox folder as fallback", placement)
+	}
+}
@@ -0,0 +1,81 @@
+package vault
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// WithReadOnlyPaths protects notes and folders matching any of the globs
+// from every write. Globs use path.Match syntax against the vault-relative
+// path with forward slashes; a glob matching a folder protects everything
+// below it, so "Templates" and "Areas/*/Private" cover whole subtrees.
+// Read-only paths win over WithWritablePaths.
+func WithReadOnlyPaths(globs ...string) Option {
+	return func(v *vault) {
+		v.readOnlyPaths = append(v.readOnlyPaths, cleanGlobs(globs)...)
+	}
+}
+
+// WithWritablePaths switches writes to allowlist mode: only notes matching
+// one of the globs may be written. Globs follow WithReadOnlyPaths.
+func WithWritablePaths(globs ...string) Option {
+	return func(v *vault) {
+		v.writablePaths = append(v.writablePaths, cleanGlobs(globs)...)
+	}
+}
+
+// cleanGlobs normalizes globs to the form of cleaned relative paths,
+// dropping empty entries
+func cleanGlobs(globs []string) []string {
+	var cleaned []string
+	for _, glob := range globs {
+		glob = strings.TrimSpace(filepath.ToSlash(glob))
+		if glob == "" {
+			continue
+		}
+		cleaned = append(cleaned, path.Clean(strings.TrimPrefix(glob, "/")))
+	}
+	return cleaned
+}
+
+// validateGlobs reports the first malformed glob
+func validateGlobs(globs []string) error {
+	for _, glob := range globs {
+		if _, err := path.Match(glob, ""); err != nil {
+			return fmt.Errorf("invalid path glob %q: %w", glob, err)
+		}
+	}
+	return nil
+}
+
+// matchesGlob reports whether relPath or one of its parent folders
+// matches one of the globs
+func matchesGlob(globs []string, relPath string) bool {
+	for p := relPath; p != "." && p != "/"; p = path.Dir(p) {
+		for _, glob := range globs {
+			if ok, _ := path.Match(glob, p); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkWritable fails with ErrReadOnly unless every full path may be
+// written. Operations touching several notes, such as a move, pass both
+// the source and the destination. Globs are matched against the path from
+// the vault directory, also when the vault is limited to a root inside it.
+func (v *vault) checkWritable(fullPaths ...string) error {
+	for _, fullPath := range fullPaths {
+		relPath := path.Join(v.root, v.relPath(fullPath))
+		if matchesGlob(v.readOnlyPaths, relPath) {
+			return ErrReadOnly
+		}
+		if len(v.writablePaths) > 0 && !matchesGlob(v.writablePaths, relPath) {
+			return ErrReadOnly
+		}
+	}
+	return nil
+}
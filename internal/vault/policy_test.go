@@ -0,0 +1,261 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// setupPolicyVault creates a vault with a few nested folders and opts
+func setupPolicyVault(t *testing.T, opts ...Option) (*vault, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+
+	writeFiles(t, tmpDir, map[string]string{
+		"Inbox/idea.md":               "original",
+		"Daily/2024-01-05.md":         "original",
+		"Areas/Finance/budget.md":     "original",
+		"Areas/Finance/Taxes/2023.md": "original",
+		"Areas/Health/log.md":         "original",
+		"Templates/daily.md":          "original",
+	})
+
+	v, err := NewVault(tmpDir, opts...)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	return v.(*vault), tmpDir
+}
+
+func TestReadOnlyPaths(t *testing.T) {
+	v, tmpDir := setupPolicyVault(t, WithReadOnlyPaths("Areas/Finance/", "Templates", "Inbox/*.secret.md"))
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		path     string
+		existing bool
+		wantErr  bool
+	}{
+		{"folder itself", "Areas/Finance/budget.md", true, true},
+		{"nested folder", "Areas/Finance/Taxes/2023.md", true, true},
+		{"new note in protected folder", "Areas/Finance/new.md", false, true},
+		{"new nested folder", "Areas/Finance/2025/q1.md", false, true},
+		{"sibling folder", "Areas/Health/log.md", true, false},
+		{"similar prefix", "Areas/Financial.md", false, false},
+		{"templates", "Templates/daily.md", true, true},
+		{"file glob", "Inbox/keys.secret.md", false, true},
+		{"unprotected", "Inbox/idea.md", true, false},
+		{"path is cleaned", "Inbox/../Templates/daily.md", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.existing {
+				err = v.Update(ctx, tt.path, "changed")
+			} else {
+				err = v.Create(ctx, tt.path, "created")
+			}
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrReadOnly) {
+					t.Fatalf("Got error %v, want ErrReadOnly", err)
+				}
+				data, readErr := os.ReadFile(filepath.Join(tmpDir, tt.path))
+				if tt.existing && string(data) != "original" {
+					t.Errorf("Protected note changed to %q", data)
+				}
+				if !tt.existing && readErr == nil {
+					t.Errorf("Protected note was created")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Got error %v, want none", err)
+			}
+		})
+	}
+
+	// Reads and dry runs behave as before, dry runs report the policy
+	if _, err := v.Read(ctx, "Areas/Finance/budget.md"); err != nil {
+		t.Errorf("Read() error = %v", err)
+	}
+	if _, err := v.ValidateUpdate(ctx, "Templates/daily.md"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("ValidateUpdate() error = %v, want ErrReadOnly", err)
+	}
+	if err := v.ValidateCreate(ctx, "Templates/weekly.md"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("ValidateCreate() error = %v, want ErrReadOnly", err)
+	}
+	if err := v.RestoreVersion(ctx, "Templates/daily.md", "20240105T120000.000000000"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("RestoreVersion() error = %v, want ErrReadOnly", err)
+	}
+}
+
+func TestWritablePaths(t *testing.T) {
+	v, _ := setupPolicyVault(t,
+		WithWritablePaths("Inbox", "Daily"),
+		WithReadOnlyPaths("Daily/Archive"),
+	)
+	ctx := context.Background()
+
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{"Inbox/idea.md", false},
+		{"Inbox/nested/deeper/new.md", false},
+		{"Daily/2024-01-06.md", false},
+		{"Daily/Archive/2020-01-01.md", true}, // Read-only wins
+		{"Areas/Health/new.md", true},
+		{"root.md", true},
+		{"Inboxes/new.md", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			err := v.ValidateCreate(ctx, tt.path)
+			if tt.path == "Inbox/idea.md" {
+				_, err = v.ValidateUpdate(ctx, tt.path)
+			}
+			if got := errors.Is(err, ErrReadOnly); got != tt.wantErr {
+				t.Errorf("Got error %v, want ErrReadOnly: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckWritableMove(t *testing.T) {
+	v, tmpDir := setupPolicyVault(t, WithReadOnlyPaths("Templates"), WithWritablePaths("Inbox", "Daily", "Templates"))
+
+	tests := []struct {
+		name     string
+		src, dst string
+		wantErr  bool
+	}{
+		{"both writable", "Inbox/idea.md", "Daily/idea.md", false},
+		{"read-only source", "Templates/daily.md", "Inbox/daily.md", true},
+		{"read-only destination", "Inbox/idea.md", "Templates/idea.md", true},
+		{"destination outside allowlist", "Inbox/idea.md", "Areas/idea.md", true},
+		{"source outside allowlist", "Areas/Health/log.md", "Inbox/log.md", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.checkWritable(filepath.Join(tmpDir, tt.src), filepath.Join(tmpDir, tt.dst))
+			if got := errors.Is(err, ErrReadOnly); got != tt.wantErr {
+				t.Errorf("checkWritable(%s, %s) = %v, want ErrReadOnly: %v", tt.src, tt.dst, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicyUnderRoot(t *testing.T) {
+	v, tmpDir := setupPolicyVault(t, WithRoot("Areas"), WithReadOnlyPaths("Areas/Finance"), WithWritablePaths("Areas"))
+	ctx := context.Background()
+
+	// Globs are matched from the vault directory, not the root
+	if err := v.Update(ctx, "Finance/budget.md", "changed"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Update(Finance/budget.md) error = %v, want ErrReadOnly", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(tmpDir, "Areas/Finance/budget.md")); string(data) != "original" {
+		t.Errorf("Protected note changed to %q", data)
+	}
+	if err := v.Update(ctx, "Health/log.md", "changed"); err != nil {
+		t.Errorf("Update(Health/log.md) error = %v", err)
+	}
+}
+
+func TestInvalidPathGlob(t *testing.T) {
+	if _, err := NewVault(t.TempDir(), WithReadOnlyPaths("Areas/[")); err == nil {
+		t.Error("NewVault() with malformed glob succeeded, want error")
+	}
+}
+
+// chmod changes the mode of path below dir, restoring it after the test so
+// the directory can be cleaned up
+func chmod(t *testing.T, dir, path string, mode os.FileMode) {
+	t.Helper()
+	fullPath := filepath.Join(dir, path)
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		t.Fatalf("Failed to stat %s: %v", path, err)
+	}
+	if err := os.Chmod(fullPath, mode); err != nil {
+		t.Fatalf("Failed to chmod %s: %v", path, err)
+	}
+	t.Cleanup(func() { os.Chmod(fullPath, info.Mode().Perm()) })
+}
+
+// skipUnlessPermissionsEnforced skips tests relying on the OS refusing
+// access, which it never does for root and not by mode on Windows
+func skipUnlessPermissionsEnforced(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		t.Skip("File permissions are not enforced for this user or platform")
+	}
+}
+
+func TestFilePermissions(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("read-only note", func(t *testing.T) {
+		v, tmpDir := setupPolicyVault(t, WithBackups(3))
+		chmod(t, tmpDir, "Inbox/idea.md", 0444)
+
+		err := v.Update(ctx, "Inbox/idea.md", "changed")
+		if !errors.Is(err, ErrPermissionDenied) || errors.Is(err, ErrReadOnly) {
+			t.Fatalf("Update() of a read-only file error = %v, want ErrPermissionDenied", err)
+		}
+		if strings.Contains(err.Error(), tmpDir) {
+			t.Errorf("Update() error %q holds the host path", err)
+		}
+		if versions, _ := v.ListVersions(ctx, "Inbox/idea.md"); len(versions) != 0 {
+			t.Errorf("Update() backed up a note it could not write")
+		}
+
+		notes, err := v.List(ctx, ListOptions{Recursive: true})
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		for _, note := range notes {
+			readOnly := note.Path == "Inbox/idea.md"
+			if (note.Writable != nil) != readOnly || (readOnly && *note.Writable) {
+				t.Errorf("Writable of %s = %v, want false only for the read-only note", note.Path, note.Writable)
+			}
+		}
+	})
+
+	t.Run("read-only folder", func(t *testing.T) {
+		skipUnlessPermissionsEnforced(t)
+		v, tmpDir := setupPolicyVault(t)
+		chmod(t, tmpDir, "Areas/Health", 0555)
+
+		if err := v.Create(ctx, "Areas/Health/new.md", "x"); !errors.Is(err, ErrPermissionDenied) {
+			t.Errorf("Create() in a read-only folder error = %v, want ErrPermissionDenied", err)
+		}
+		if _, err := v.MoveNote(ctx, MoveNoteOptions{Path: "Areas/Health/log.md", NewPath: "Inbox/log.md"}); !errors.Is(err, ErrPermissionDenied) {
+			t.Errorf("MoveNote() out of a read-only folder error = %v, want ErrPermissionDenied", err)
+		}
+	})
+
+	t.Run("unreadable folder", func(t *testing.T) {
+		skipUnlessPermissionsEnforced(t)
+		v, tmpDir := setupPolicyVault(t)
+		chmod(t, tmpDir, "Areas/Finance", 0)
+
+		var warnings []string
+		warnCtx := WarningContext(ctx, func(path, warning string) { warnings = append(warnings, warning) })
+		notes, err := v.List(warnCtx, ListOptions{Recursive: true})
+		if err != nil || len(notes) != 4 {
+			t.Fatalf("List() = %d notes, %v; want the 4 outside the unreadable folder", len(notes), err)
+		}
+		if len(warnings) != 1 || !strings.Contains(warnings[0], "Areas/Finance") || strings.Contains(warnings[0], tmpDir) {
+			t.Errorf("List() warnings = %q, want one naming Areas/Finance", warnings)
+		}
+	})
+}
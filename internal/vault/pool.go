@@ -0,0 +1,177 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+)
+
+// noteFile is a candidate note collected during a walk
+type noteFile struct {
+	fullPath string      // Absolute filesystem path
+	relPath  string      // Path relative to vault root, with forward slashes
+	info     os.FileInfo // FileInfo reported by the walk
+}
+
+// noteInfo builds the NoteInfo for the file from its loaded entry
+func (f noteFile) noteInfo(entry CacheEntry) NoteInfo {
+	created := entry.Created
+	if created.IsZero() {
+		created = f.info.ModTime()
+	}
+	note := NoteInfo{
+		Path:        f.relPath,
+		Tags:        entry.Tags,
+		Modified:    f.info.ModTime(),
+		Created:     created,
+		ContentHash: entry.ContentHash,
+	}
+	if !writable(f.info) {
+		note.Writable = new(bool)
+	}
+	return note
+}
+
+// workerPanic is a panic raised by a job of a worker goroutine, raised
+// again on the goroutine waiting for the workers so that it can be
+// recovered there rather than crash the process
+type workerPanic struct {
+	value any
+	stack []byte // Stack of the worker when it panicked
+}
+
+func (p *workerPanic) Error() string {
+	return fmt.Sprintf("panic in vault worker: %v\n\n%s", p.value, p.stack)
+}
+
+// workerPanics records the first panic of a group of worker goroutines
+type workerPanics struct {
+	mu    sync.Mutex
+	first *workerPanic
+}
+
+// run calls job, recovering a panic it raises. Once a job panicked, the
+// jobs run after it are skipped.
+func (p *workerPanics) run(job func()) {
+	p.mu.Lock()
+	failed := p.first != nil
+	p.mu.Unlock()
+	if failed {
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			p.mu.Lock()
+			if p.first == nil {
+				p.first = &workerPanic{value: r, stack: debug.Stack()}
+			}
+			p.mu.Unlock()
+		}
+	}()
+	job()
+}
+
+// repanic raises the first recorded panic on the calling goroutine
+// Call it once the workers are done
+func (p *workerPanics) repanic() {
+	if p.first != nil {
+		panic(p.first)
+	}
+}
+
+// matchFunc decides whether a loaded note belongs in the results
+type matchFunc func(file noteFile, entry CacheEntry) bool
+
+// processNotes loads files through the cache using a bounded worker pool
+// and returns the notes accepted by match, in the same order as files
+// Unreadable files are skipped and malformed canvases are returned with
+// Error set. Cancelling ctx stops workers before their next file; the notes
+// matched until then are returned together with ctx.Err(). The count of
+// files that were loaded is returned either way. With a positive
+// previewLength each note carries an excerpt of its content. Each file
+// loaded is reported to the ProgressFunc of ctx.
+func (v *vault) processNotes(ctx context.Context, files []noteFile, previewLength int, match matchFunc) ([]NoteInfo, int, error) {
+	report := progressFrom(ctx)
+	var done atomic.Int64
+	loaded := make([]bool, len(files))
+	matched := make([]bool, len(files))
+	entries := make([]CacheEntry, len(files))
+	errs := make([]string, len(files))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var panics workerPanics
+
+	for range min(v.concurrency, len(files)) {
+		wg.Go(func() {
+			for i := range jobs {
+				if ctx.Err() != nil {
+					continue // Drain remaining jobs without doing work
+				}
+
+				panics.run(func() {
+					file := files[i]
+					entry, err := v.loadEntry(file.fullPath, file.info.ModTime())
+					loaded[i] = true
+					if report != nil {
+						report(int(done.Add(1)), len(files))
+					}
+					if errors.Is(err, ErrInvalidCanvas) || errors.Is(err, ErrNotUTF8) {
+						// Report malformed canvases and undecodable notes instead of hiding them
+						matched[i] = true
+						errs[i] = err.Error()
+						return
+					}
+					if err != nil {
+						return // Skip unreadable files
+					}
+
+					// Each worker writes only its own indices, so no locking is needed
+					if match == nil || match(file, entry) {
+						matched[i] = true
+						entries[i] = entry
+					}
+				})
+			}
+		})
+	}
+
+feed:
+	for i := range files {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	panics.repanic()
+
+	var notes []NoteInfo
+	scanned := 0
+	for i, file := range files {
+		if loaded[i] {
+			scanned++
+		}
+		if matched[i] {
+			note := file.noteInfo(entries[i])
+			note.Type = v.types.classify(file.relPath, entries[i].Properties)
+			if title, source := v.noteTitle(file.relPath, entries[i]); source != TitleFromFilename {
+				note.Title = title
+			}
+			note.Error = errs[i]
+			if previewLength > 0 && note.Error == "" {
+				note.Excerpt = excerpt(entries[i].searchText(), previewLength)
+			}
+			notes = append(notes, note)
+		}
+	}
+
+	return notes, scanned, ctx.Err()
+}
@@ -0,0 +1,250 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// generateVault writes count notes spread over a few folders and returns
+// the vault directory
+func generateVault(tb testing.TB, count int) string {
+	tb.Helper()
+	tmpDir := tb.TempDir()
+
+	notes := make(map[string]string, count)
+	for i := 0; i < count; i++ {
+		path := fmt.Sprintf("folder%d/sub%d/note%04d.md", i%7, i%3, i)
+		notes[path] = fmt.Sprintf("# Note %d\n\nSome text about topic%d with #tag%d and #group%d\n", i, i%11, i%5, i%2)
+	}
+	writeFiles(tb, tmpDir, notes)
+
+	return tmpDir
+}
+
+func TestConcurrentMatchesSequential(t *testing.T) {
+	tmpDir := generateVault(t, 300)
+	ctx := context.Background()
+
+	sequential, err := NewVault(tmpDir, WithConcurrency(1))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	concurrent, err := NewVault(tmpDir, WithConcurrency(8))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+
+	searches := []SearchOptions{
+		{},
+		{Query: "topic3"},
+		{TagsAny: []string{"tag1", "tag2"}},
+		{Query: "note 1", TagsNone: []string{"group0"}, Subpath: "folder2"},
+		{Query: "topic1", Sort: SortRelevance},
+		{TagsAny: []string{"tag3"}, Sort: SortModified, Collation: CollationNatural},
+		{Query: "note", Collation: "locale:en"},
+	}
+
+	for _, opts := range searches {
+		want, err := sequential.Search(ctx, opts)
+		if err != nil {
+			t.Fatalf("Sequential Search(%+v) error = %v", opts, err)
+		}
+		// Repeated runs give the same order whatever the scheduling
+		for range 3 {
+			got, err := concurrent.Search(ctx, opts)
+			if err != nil {
+				t.Fatalf("Concurrent Search(%+v) error = %v", opts, err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Search(%+v) results differ: concurrent %d notes, sequential %d notes", opts, len(got), len(want))
+			}
+		}
+	}
+
+	want, err := sequential.List(ctx, ListOptions{Recursive: true})
+	if err != nil {
+		t.Fatalf("Sequential List() error = %v", err)
+	}
+	got, err := concurrent.List(ctx, ListOptions{Recursive: true})
+	if err != nil {
+		t.Fatalf("Concurrent List() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("List() results differ: concurrent %d notes, sequential %d notes", len(got), len(want))
+	}
+	if len(got) != 300 {
+		t.Errorf("Expected 300 notes, got %d", len(got))
+	}
+
+	// Results are ordered by path
+	for i := 1; i < len(got); i++ {
+		if got[i-1].Path > got[i].Path {
+			t.Fatalf("Results not ordered by path: %s before %s", got[i-1].Path, got[i].Path)
+		}
+	}
+}
+
+func TestProcessNotesCancellation(t *testing.T) {
+	tmpDir := generateVault(t, 50)
+	v, err := NewVault(tmpDir, WithConcurrency(4))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	vaultImpl := v.(*vault)
+
+	notes, err := v.List(context.Background(), ListOptions{Recursive: true})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	files := make([]noteFile, 0, len(notes))
+	for _, note := range notes {
+		fullPath := filepath.Join(vaultImpl.basePath, note.Path)
+		info, err := os.Stat(fullPath)
+		if err != nil {
+			t.Fatalf("Failed to stat note: %v", err)
+		}
+		files = append(files, noteFile{fullPath: fullPath, relPath: note.Path, info: info})
+	}
+
+	// Cancel from inside the matcher while workers are busy
+	ctx, cancel := context.WithCancel(context.Background())
+	matched, scanned, err := vaultImpl.processNotes(ctx, files, 0, func(noteFile, CacheEntry) bool {
+		cancel()
+		return true
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	// Notes matched before the cancellation are kept
+	if len(matched) == 0 || len(matched) > scanned || scanned == len(files) {
+		t.Errorf("Expected the notes matched so far, got %d notes with %d of %d scanned", len(matched), scanned, len(files))
+	}
+}
+
+func TestWorkerPanic(t *testing.T) {
+	tmpDir := generateVault(t, 50)
+	v, err := NewVault(tmpDir, WithConcurrency(4))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	vaultImpl := v.(*vault)
+
+	// Raised on a pool worker, the panic reaches the caller, where it can be recovered
+	walk := func(match matchFunc) (p any) {
+		defer func() { p = recover() }()
+		vaultImpl.walkNotes(context.Background(), ListOptions{Recursive: true}, match)
+		return nil
+	}
+	p := walk(func(file noteFile, _ CacheEntry) bool {
+		panic("matcher broke on " + file.relPath)
+	})
+	wp, ok := p.(*workerPanic)
+	if !ok {
+		t.Fatalf("walkNotes() panicked with %v, want a *workerPanic", p)
+	}
+	if !strings.Contains(wp.Error(), "matcher broke on") || !strings.Contains(wp.Error(), "TestWorkerPanic") {
+		t.Errorf("Error() = %q, want the value and the worker's stack", wp.Error())
+	}
+
+	// Walks go on working afterwards
+	if p := walk(nil); p != nil {
+		t.Errorf("walkNotes() panicked with %v", p)
+	}
+
+	// A load that panicked does not leave later loads of the note waiting
+	func() {
+		defer func() { recover() }()
+		vaultImpl.loads.do("note.md", time.Time{}, func() (CacheEntry, error) { panic("read broke") })
+	}()
+	done := make(chan error, 1)
+	go func() {
+		_, err := vaultImpl.loads.do("note.md", time.Time{}, func() (CacheEntry, error) { return CacheEntry{}, nil })
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("load after a panic error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("load after a panic hangs")
+	}
+}
+
+func TestSearchTimeout(t *testing.T) {
+	tmpDir := generateVault(t, 500)
+	v, err := NewVault(tmpDir, WithConcurrency(2))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	ctx := context.Background()
+
+	t.Run("completes in time", func(t *testing.T) {
+		want, err := v.Search(ctx, SearchOptions{Query: "topic3"})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		got, err := v.Search(ctx, SearchOptions{Query: "topic3", Timeout: time.Minute})
+		if err != nil {
+			t.Fatalf("Search() with timeout error = %v", err)
+		}
+		if !slices.EqualFunc(got, want, func(a, b NoteInfo) bool { return a.Path == b.Path }) {
+			t.Errorf("Search() with timeout returned %d notes, want %d", len(got), len(want))
+		}
+	})
+
+	t.Run("partial results", func(t *testing.T) {
+		notes, err := v.Search(ctx, SearchOptions{Timeout: time.Nanosecond})
+		var partial *PartialResultsError
+		if !errors.As(err, &partial) || !errors.Is(err, ErrPartialResults) {
+			t.Fatalf("Search() error = %v, want *PartialResultsError", err)
+		}
+		if partial.Scanned >= 500 || partial.Scanned > partial.Total || len(notes) > partial.Scanned {
+			t.Errorf("Search() = %d notes, %+v", len(notes), partial)
+		}
+	})
+
+	t.Run("caller cancellation is an error", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		notes, err := v.Search(cancelled, SearchOptions{Timeout: time.Minute})
+		if !errors.Is(err, context.Canceled) || errors.Is(err, ErrPartialResults) || notes != nil {
+			t.Errorf("Search() = %v, %v; want context.Canceled", notes, err)
+		}
+	})
+}
+
+func BenchmarkSearchCold(b *testing.B) {
+	tmpDir := generateVault(b, 2000)
+	ctx := context.Background()
+
+	for _, concurrency := range []int{1, 0} {
+		name := fmt.Sprintf("concurrency=%d", concurrency)
+		if concurrency == 0 {
+			name = "concurrency=default"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			for b.Loop() {
+				// Fresh vault per iteration so every file is a cache miss
+				v, err := NewVault(tmpDir, WithConcurrency(concurrency))
+				if err != nil {
+					b.Fatalf("Failed to create vault: %v", err)
+				}
+				if _, err := v.Search(ctx, SearchOptions{Query: "topic7"}); err != nil {
+					b.Fatalf("Search() error = %v", err)
+				}
+			}
+		})
+	}
+}
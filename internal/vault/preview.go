@@ -0,0 +1,80 @@
+package vault
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Preview lengths, in characters
+const (
+	DefaultPreviewLength = 300
+	MaxPreviewLength     = 2000
+)
+
+// excerptEllipsis marks an excerpt cut short
+const excerptEllipsis = "…"
+
+// excerpt returns the start of a note for previews: the first paragraph
+// after the frontmatter, preceded by any headings above it, cut to at
+// most length characters on a word boundary plus an ellipsis
+func excerpt(content string, length int) string {
+	_, body, _ := SplitFrontmatter(content)
+
+	// Collect lines up to the end of the first paragraph that is not a heading
+	var lines []string
+	inParagraph := false
+	for line := range strings.Lines(body) {
+		line = strings.TrimRight(line, " \t\r\n")
+		if strings.TrimSpace(line) == "" {
+			if inParagraph {
+				break
+			}
+			continue
+		}
+		lines = append(lines, line)
+		if !isHeadingLine(line) {
+			inParagraph = true
+		}
+	}
+
+	return truncateText(strings.Join(lines, "\n"), length)
+}
+
+// isHeadingLine reports whether line is an ATX heading such as "## Notes"
+func isHeadingLine(line string) bool {
+	level := len(line) - len(strings.TrimLeft(line, "#"))
+	if level == 0 || level > 6 {
+		return false
+	}
+	rest := line[level:]
+	return rest == "" || rest[0] == ' ' || rest[0] == '\t'
+}
+
+// truncateText cuts text to at most length characters, preferring the last
+// whitespace before the limit, and marks the cut with an ellipsis
+func truncateText(text string, length int) string {
+	if utf8.RuneCountInString(text) <= length {
+		return text
+	}
+
+	// Byte offset of the first character past the limit; never mid-rune
+	cut, n := len(text), 0
+	for i := range text {
+		if n == length {
+			cut = i
+			break
+		}
+		n++
+	}
+
+	// Back up to a word boundary unless the first word alone exceeds the limit
+	truncated := text[:cut]
+	if r, _ := utf8.DecodeRuneInString(text[cut:]); !unicode.IsSpace(r) {
+		if space := strings.LastIndexFunc(truncated, unicode.IsSpace); space > 0 {
+			truncated = truncated[:space]
+		}
+	}
+
+	return strings.TrimRightFunc(truncated, unicode.IsSpace) + excerptEllipsis
+}
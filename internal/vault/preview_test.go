@@ -0,0 +1,137 @@
+package vault
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExcerpt(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		length  int
+		want    string
+	}{
+		{
+			name:    "first paragraph only",
+			content: "First line\nsecond line\n\nSecond paragraph",
+			length:  300,
+			want:    "First line\nsecond line",
+		},
+		{
+			name:    "frontmatter and blank lines skipped",
+			content: "---\ntitle: Note\n---\n\n\nBody text\n\nMore",
+			length:  300,
+			want:    "Body text",
+		},
+		{
+			name:    "headings kept",
+			content: "# Title\n\n## Section\nParagraph text\n\nNext",
+			length:  300,
+			want:    "# Title\n## Section\nParagraph text",
+		},
+		{
+			name:    "hashtag is not a heading",
+			content: "#tag line\n\nNext",
+			length:  300,
+			want:    "#tag line",
+		},
+		{
+			name:    "cut on word boundary",
+			content: "The quick brown fox jumps",
+			length:  12,
+			want:    "The quick…",
+		},
+		{
+			name:    "cut at space",
+			content: "The quick brown fox",
+			length:  9,
+			want:    "The quick…",
+		},
+		{
+			name:    "long first word",
+			content: "Supercalifragilistic word",
+			length:  5,
+			want:    "Super…",
+		},
+		{
+			name:    "multi-byte characters",
+			content: "Größenänderung über alles",
+			length:  16,
+			want:    "Größenänderung…",
+		},
+		{
+			name:    "empty note",
+			content: "",
+			length:  300,
+			want:    "",
+		},
+		{
+			name:    "frontmatter only",
+			content: "---\ntitle: Note\n---\n",
+			length:  300,
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := excerpt(tt.content, tt.length); got != tt.want {
+				t.Errorf("excerpt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListAndSearchPreview(t *testing.T) {
+	v, tmpDir := setupTestVault(t)
+	ctx := context.Background()
+
+	content := "---\ntags: [preview]\n---\n# Meeting\nDiscussed the roadmap\n\nAction items"
+	if err := os.WriteFile(filepath.Join(tmpDir, "meeting.md"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+	want := "# Meeting\nDiscussed the roadmap"
+
+	t.Run("list with preview", func(t *testing.T) {
+		notes, err := v.List(ctx, ListOptions{Recursive: true, PreviewLength: DefaultPreviewLength})
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		for _, note := range notes {
+			if note.Path == "meeting.md" && note.Excerpt != want {
+				t.Errorf("Excerpt = %q, want %q", note.Excerpt, want)
+			}
+			if note.Excerpt == "" {
+				t.Errorf("Expected an excerpt for %s", note.Path)
+			}
+		}
+	})
+
+	t.Run("list without preview", func(t *testing.T) {
+		notes, err := v.List(ctx, ListOptions{Recursive: true})
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		for _, note := range notes {
+			if note.Excerpt != "" {
+				t.Errorf("Expected no excerpt for %s, got %q", note.Path, note.Excerpt)
+			}
+		}
+	})
+
+	t.Run("search with preview", func(t *testing.T) {
+		notes, err := v.Search(ctx, SearchOptions{Query: "roadmap", PreviewLength: 10})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		if len(notes) != 1 {
+			t.Fatalf("Expected 1 note, got %d", len(notes))
+		}
+		if notes[0].Excerpt != "# Meeting…" {
+			t.Errorf("Excerpt = %q, want %q", notes[0].Excerpt, "# Meeting…")
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// ProgressFunc receives the progress of a walk over the vault: done of
+// total notes are finished. Before the walk has found every note, total
+// is an estimate taken from the search index, or 0 when there is none.
+// Every walk starts with a report of 0 done, and an operation may walk
+// the vault more than once. It is called from several goroutines at once,
+// so reports of one walk can arrive out of order.
+type ProgressFunc func(done, total int)
+
+// progressKey is the context key of the ProgressFunc
+type progressKey struct{}
+
+// ProgressContext returns a context whose walks over the vault report
+// their progress to fn. A nil fn stops reporting.
+func ProgressContext(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// ReportProgress reports to the ProgressFunc of ctx, if any, that done of
+// total units of a long operation are finished
+func ReportProgress(ctx context.Context, done, total int) {
+	if report := progressFrom(ctx); report != nil {
+		report(done, total)
+	}
+}
+
+// progressFrom returns the ProgressFunc of ctx, nil if it has none
+func progressFrom(ctx context.Context) ProgressFunc {
+	report, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return report
+}
+
+// estimateNotes returns the number of indexed notes below root, 0 when
+// the vault has no search index
+func (v *vault) estimateNotes(root string) int {
+	if v.index == nil {
+		return 0
+	}
+	return v.index.countWithin(root)
+}
+
+// WarningFunc receives a problem a walk over the vault worked around
+// instead of failing, such as a folder it could not read, with the
+// vault-relative path it concerns. Like a ProgressFunc it may be called
+// from several goroutines at once.
+type WarningFunc func(path, warning string)
+
+// warningKey is the context key of the WarningFunc
+type warningKey struct{}
+
+// WarningContext returns a context whose walks over the vault report what
+// they had to leave out to fn. A nil fn stops reporting.
+func WarningContext(ctx context.Context, fn WarningFunc) context.Context {
+	return context.WithValue(ctx, warningKey{}, fn)
+}
+
+// warnUnreadable reports to the WarningFunc of ctx, if any, that the walk
+// skipped fullPath because of err. The message holds no host paths.
+func (v *vault) warnUnreadable(ctx context.Context, fullPath string, info fs.FileInfo, err error) {
+	warn, _ := ctx.Value(warningKey{}).(WarningFunc)
+	if warn == nil {
+		return
+	}
+	kind := "file"
+	if info != nil && info.IsDir() {
+		kind = "folder"
+	}
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+	relPath := v.relPath(fullPath)
+	warn(relPath, fmt.Sprintf("%s %s could not be read and was skipped: %v", kind, relPath, err))
+}
@@ -0,0 +1,60 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestWalkProgress(t *testing.T) {
+	tmpDir := t.TempDir()
+	notes := make(map[string]string)
+	for i := range 20 {
+		notes[fmt.Sprintf("folder%d/note%d.md", i%2, i)] = "Note"
+	}
+	writeFiles(t, tmpDir, notes)
+	v, err := NewVault(tmpDir, WithSearchIndex())
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+
+	// reports lists the vault, returning the first and every later report
+	reports := func(subpath string) ([2]int, map[[2]int]bool) {
+		var mu sync.Mutex
+		var first [2]int
+		seen := make(map[[2]int]bool)
+		ctx := ProgressContext(context.Background(), func(done, total int) {
+			mu.Lock()
+			defer mu.Unlock()
+			if len(seen) == 0 {
+				first = [2]int{done, total}
+			}
+			seen[[2]int{done, total}] = true
+		})
+		if _, err := v.List(ctx, ListOptions{Subpath: subpath, Recursive: true}); err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		return first, seen
+	}
+
+	// Nothing is indexed yet, so the total is unknown until the walk ends
+	first, seen := reports("")
+	if first != [2]int{0, 0} {
+		t.Errorf("First report = %v, want 0 of an unknown total", first)
+	}
+	for done := 1; done <= 20; done++ {
+		if !seen[[2]int{done, 20}] {
+			t.Errorf("Missing report of %d of 20 notes in %v", done, seen)
+		}
+	}
+
+	// The index then estimates the notes of a folder before it is walked
+	if first, seen = reports("folder1"); first != [2]int{0, 10} || !seen[[2]int{10, 10}] {
+		t.Errorf("Reports of folder1 = %v, first %v, want 0 then 10 of 10", seen, first)
+	}
+
+	if _, err := v.List(ProgressContext(context.Background(), nil), ListOptions{Recursive: true}); err != nil {
+		t.Errorf("List() without a ProgressFunc error = %v", err)
+	}
+}
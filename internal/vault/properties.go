@@ -0,0 +1,237 @@
+package vault
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PropertyOp is a comparison applied to a frontmatter property
+type PropertyOp string
+
+// Supported property operators
+const (
+	PropertyEquals   PropertyOp = "="  // Equal value; for lists, any element equal
+	PropertyContains PropertyOp = "~"  // Substring of a string, or element of a list
+	PropertyExists   PropertyOp = "*"  // Present with a non-empty value
+	PropertyLess     PropertyOp = "<"  // Number or date before the value
+	PropertyLessEq   PropertyOp = "<=" // Number or date at or before the value
+	PropertyGreater  PropertyOp = ">"  // Number or date after the value
+	PropertyGreatEq  PropertyOp = ">=" // Number or date at or after the value
+)
+
+// PropertyFilter matches notes by a frontmatter property
+type PropertyFilter struct {
+	Key   string     // Property name, matched case-insensitively
+	Op    PropertyOp // Comparison to apply
+	Value string     // Expected value; unused for PropertyExists
+}
+
+// dateLayouts are the date formats recognized in property comparisons
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ParsePropertyFilter builds a filter from a property name and an
+// expression. String expressions may start with an operator: "*" for
+// exists, "~" for contains, or "<", "<=", ">", ">=", "=". Without one the
+// value must be equal. Numbers and booleans are compared for equality.
+func ParsePropertyFilter(key string, expr any) (PropertyFilter, error) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return PropertyFilter{}, fmt.Errorf("empty property name")
+	}
+
+	switch expr := expr.(type) {
+	case string:
+		expr = strings.TrimSpace(expr)
+		if expr == string(PropertyExists) {
+			return PropertyFilter{Key: key, Op: PropertyExists}, nil
+		}
+
+		// Longer operators first so "<=" is not read as "<"
+		for _, op := range []PropertyOp{PropertyLessEq, PropertyGreatEq, PropertyLess, PropertyGreater, PropertyEquals, PropertyContains} {
+			if value, ok := strings.CutPrefix(expr, string(op)); ok {
+				return PropertyFilter{Key: key, Op: op, Value: strings.TrimSpace(value)}, nil
+			}
+		}
+		return PropertyFilter{Key: key, Op: PropertyEquals, Value: expr}, nil
+
+	case bool, float64, int:
+		return PropertyFilter{Key: key, Op: PropertyEquals, Value: fmt.Sprint(expr)}, nil
+
+	default:
+		return PropertyFilter{}, fmt.Errorf("unsupported value for property %q: %v", key, expr)
+	}
+}
+
+// matchProperties reports whether frontmatter satisfies every filter
+// Notes without frontmatter never match a non-empty filter list
+func matchProperties(properties map[string]any, filters []PropertyFilter) bool {
+	for _, f := range filters {
+		value, ok := lookupProperty(properties, f.Key)
+		if !ok || !f.matches(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// lookupProperty finds key in properties, preferring an exact match
+func lookupProperty(properties map[string]any, key string) (any, bool) {
+	if value, ok := properties[key]; ok {
+		return value, true
+	}
+	for k, value := range properties {
+		if strings.EqualFold(k, key) {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// matches applies the filter to a single property value
+func (f PropertyFilter) matches(value any) bool {
+	switch f.Op {
+	case PropertyExists:
+		return !isEmptyProperty(value)
+
+	case PropertyEquals:
+		if list, ok := value.([]any); ok {
+			for _, item := range list {
+				if equalProperty(item, f.Value) {
+					return true
+				}
+			}
+			return false
+		}
+		return equalProperty(value, f.Value)
+
+	case PropertyContains:
+		if list, ok := value.([]any); ok {
+			for _, item := range list {
+				if equalProperty(item, f.Value) {
+					return true
+				}
+			}
+			return false
+		}
+		s, ok := value.(string)
+		return ok && strings.Contains(strings.ToLower(s), strings.ToLower(f.Value))
+
+	default:
+		cmp, ok := compareProperty(value, f.Value)
+		if !ok {
+			return false
+		}
+		switch f.Op {
+		case PropertyLess:
+			return cmp < 0
+		case PropertyLessEq:
+			return cmp <= 0
+		case PropertyGreater:
+			return cmp > 0
+		case PropertyGreatEq:
+			return cmp >= 0
+		}
+		return false
+	}
+}
+
+// isEmptyProperty reports whether a property has no meaningful value
+func isEmptyProperty(value any) bool {
+	switch value := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(value) == ""
+	case []any:
+		return len(value) == 0
+	}
+	return false
+}
+
+// equalProperty compares a typed property value with an expected string
+func equalProperty(value any, expected string) bool {
+	switch value := value.(type) {
+	case string:
+		if strings.EqualFold(value, expected) {
+			return true
+		}
+		// Quoted dates are still dates
+		a, okA := parseDate(value)
+		b, okB := parseDate(expected)
+		return okA && okB && a.Equal(b)
+	case bool:
+		b, err := strconv.ParseBool(expected)
+		return err == nil && b == value
+	case int, float64:
+		n, err := strconv.ParseFloat(expected, 64)
+		return err == nil && toFloat(value) == n
+	case time.Time:
+		t, ok := parseDate(expected)
+		return ok && t.Equal(value)
+	}
+	return false
+}
+
+// compareProperty orders a number or date property against expected
+// Returns false when the two cannot be compared
+func compareProperty(value any, expected string) (int, bool) {
+	switch value := value.(type) {
+	case int, float64:
+		n, err := strconv.ParseFloat(expected, 64)
+		if err != nil {
+			return 0, false
+		}
+		v := toFloat(value)
+		switch {
+		case v < n:
+			return -1, true
+		case v > n:
+			return 1, true
+		}
+		return 0, true
+	case time.Time:
+		t, ok := parseDate(expected)
+		if !ok {
+			return 0, false
+		}
+		return value.Compare(t), true
+	case string:
+		v, okV := parseDate(value)
+		t, okT := parseDate(expected)
+		if !okV || !okT {
+			return 0, false
+		}
+		return v.Compare(t), true
+	}
+	return 0, false
+}
+
+// toFloat converts a YAML number to float64
+func toFloat(value any) float64 {
+	switch value := value.(type) {
+	case int:
+		return float64(value)
+	case float64:
+		return value
+	}
+	return 0
+}
+
+// parseDate parses s using the recognized date layouts
+func parseDate(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,117 @@
+package vault
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParsePropertyFilter(t *testing.T) {
+	tests := []struct {
+		expr    any
+		want    PropertyFilter
+		wantErr bool
+	}{
+		{"in-progress", PropertyFilter{Key: "status", Op: PropertyEquals, Value: "in-progress"}, false},
+		{"=done", PropertyFilter{Key: "status", Op: PropertyEquals, Value: "done"}, false},
+		{"~prog", PropertyFilter{Key: "status", Op: PropertyContains, Value: "prog"}, false},
+		{"*", PropertyFilter{Key: "status", Op: PropertyExists}, false},
+		{"<=2024-06-01", PropertyFilter{Key: "status", Op: PropertyLessEq, Value: "2024-06-01"}, false},
+		{"> 3", PropertyFilter{Key: "status", Op: PropertyGreater, Value: "3"}, false},
+		{true, PropertyFilter{Key: "status", Op: PropertyEquals, Value: "true"}, false},
+		{float64(2), PropertyFilter{Key: "status", Op: PropertyEquals, Value: "2"}, false},
+		{[]any{"a"}, PropertyFilter{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParsePropertyFilter("status", tt.expr)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParsePropertyFilter(%v) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParsePropertyFilter(%v) = %+v, want %+v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestSearchProperties(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"alpha.md":  "---\nstatus: in-progress\nproject: alpha\npriority: 2\ndue: 2024-05-20\ndone: false\ntags: [work, urgent]\n---\nAlpha",
+		"beta.md":   "---\nstatus: Done\nproject: beta\npriority: 5\ndue: \"2024-07-01\"\ndone: true\ntags: [work]\n---\nBeta",
+		"gamma.md":  "---\nstatus:\nproject: alpha-two\n---\nGamma",
+		"plain.md":  "No frontmatter, status: in-progress",
+		"broken.md": "---\nstatus: [unclosed\n---\nBroken",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, path), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		properties map[string]any
+		want       []string
+	}{
+		{"string equals ignores case", map[string]any{"status": "done"}, []string{"beta.md"}},
+		{"contains substring", map[string]any{"project": "~alpha"}, []string{"alpha.md", "gamma.md"}},
+		{"list contains", map[string]any{"tags": "~urgent"}, []string{"alpha.md"}},
+		{"list equals any element", map[string]any{"tags": "work"}, []string{"alpha.md", "beta.md"}},
+		{"exists skips empty", map[string]any{"status": "*"}, []string{"alpha.md", "beta.md"}},
+		{"number comparison", map[string]any{"priority": ">=3"}, []string{"beta.md"}},
+		{"number equals", map[string]any{"priority": float64(2)}, []string{"alpha.md"}},
+		{"date comparison", map[string]any{"due": "<=2024-06-01"}, []string{"alpha.md"}},
+		{"quoted date comparison", map[string]any{"due": ">2024-06-01"}, []string{"beta.md"}},
+		{"boolean", map[string]any{"done": true}, []string{"beta.md"}},
+		{"all conditions must hold", map[string]any{"project": "~alpha", "priority": "<10"}, []string{"alpha.md"}},
+		{"key is case-insensitive", map[string]any{"Status": "Done"}, []string{"beta.md"}},
+		{"missing property", map[string]any{"owner": "*"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var filters []PropertyFilter
+			for key, expr := range tt.properties {
+				f, err := ParsePropertyFilter(key, expr)
+				if err != nil {
+					t.Fatalf("ParsePropertyFilter() error = %v", err)
+				}
+				filters = append(filters, f)
+			}
+
+			notes, err := v.Search(ctx, SearchOptions{Properties: filters})
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+
+			var got []string
+			for _, note := range notes {
+				got = append(got, note.Path)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Search() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("properties are cached", func(t *testing.T) {
+		entry, ok := v.(*vault).cache.Get(filepath.Join(v.(*vault).basePath, "alpha.md"))
+		if !ok {
+			t.Fatal("Expected alpha.md to be cached")
+		}
+		if entry.Properties["project"] != "alpha" {
+			t.Errorf("Cached properties = %v", entry.Properties)
+		}
+	})
+}
@@ -0,0 +1,314 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Default publish settings; see PublishSettings
+const (
+	DefaultPublishField     = "publish"
+	DefaultPublishDateField = "published_at"
+)
+
+// publishedHashKey is the annotation holding the content hash of a note
+// when it was last published, as recorded by MarkPublished
+const publishedHashKey = "last_published_hash"
+
+// publishFieldRegex matches the property names PublishSettings accept
+var publishFieldRegex = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_-]*$`)
+
+// PublishSettings name the frontmatter properties PublishNote and
+// UnpublishNote set
+type PublishSettings struct {
+	Field     string // Property marking a note for publishing with true
+	DateField string // Property set to the time a note is marked, empty for none
+}
+
+// Validate reports a property name that cannot be written as a plain
+// frontmatter key, or the same name for both properties
+func (s PublishSettings) Validate() error {
+	for _, field := range []string{s.Field, s.DateField} {
+		if field != "" && !publishFieldRegex.MatchString(field) {
+			return fmt.Errorf("property %q must be letters, digits, '_' or '-'", field)
+		}
+	}
+	if s.Field != "" && s.Field == s.DateField {
+		return fmt.Errorf("the publish and date properties are both %q", s.Field)
+	}
+	return nil
+}
+
+// WithPublish sets the properties PublishNote and UnpublishNote set, as
+// checked by PublishSettings.Validate. An empty Field keeps the default;
+// an empty DateField records no publish time.
+func WithPublish(s PublishSettings) Option {
+	return func(v *vault) {
+		if s.Validate() != nil {
+			return
+		}
+		if s.Field == "" {
+			s.Field = DefaultPublishField
+		}
+		v.publish = s
+	}
+}
+
+// PublishResult reports the publish flag of a note after PublishNote or
+// UnpublishNote
+type PublishResult struct {
+	Path        string `json:"path"`
+	Published   bool   `json:"published"`              // The publish property is true afterwards
+	PublishedAt string `json:"published_at,omitempty"` // The date property as written, when set by this call
+	Changed     bool   `json:"changed"`                // False when the flag already had the value; nothing was written
+	Revision    string `json:"revision"`               // Content hash of the note afterwards
+}
+
+// PublishNote sets the publish property of the note at path to true and
+// the date property, when configured, to the current time. Only the lines
+// of the two properties change. A note already marked is reported
+// unchanged, keeping its date, and nothing is written.
+func (v *vault) PublishNote(ctx context.Context, path string) (PublishResult, error) {
+	return v.setPublished(ctx, path, true)
+}
+
+// UnpublishNote sets the publish property of the note at path to false,
+// leaving the date property as a record of the last time it was marked.
+// A note not marked is reported unchanged and nothing is written.
+func (v *vault) UnpublishNote(ctx context.Context, path string) (PublishResult, error) {
+	return v.setPublished(ctx, path, false)
+}
+
+// setPublished implements PublishNote and UnpublishNote
+func (v *vault) setPublished(ctx context.Context, path string, publish bool) (PublishResult, error) {
+	fullPath, err := v.validatePath(path)
+	if err != nil {
+		return PublishResult{}, err
+	}
+	relPath := v.relPath(fullPath)
+
+	unlock := v.writeLocks.lock(fullPath)
+	defer unlock()
+
+	if _, err := v.checkUpdate(ctx, relPath); err != nil {
+		return PublishResult{}, err
+	}
+	stat, err := os.Stat(fullPath)
+	if err != nil {
+		return PublishResult{}, ErrNoteNotFound
+	}
+	entry, err := v.loadEntry(fullPath, stat.ModTime())
+	if err != nil {
+		return PublishResult{}, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	result := PublishResult{Path: relPath, Published: publish, Revision: entry.ContentHash}
+	if v.markedForPublishing(entry) == publish {
+		return result, nil
+	}
+	if _, err := parseFrontmatterBlock(entry.Content); err != nil {
+		return PublishResult{}, fmt.Errorf("%w: %s: %w", ErrInvalidFrontmatter, relPath, err)
+	}
+
+	content, _ := setFrontmatterProperty(entry.Content, v.publish.Field, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(publish)})
+	if publish && v.publish.DateField != "" {
+		result.PublishedAt = time.Now().Format(time.RFC3339)
+		content, _ = setFrontmatterProperty(content, v.publish.DateField, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!timestamp", Value: result.PublishedAt})
+	}
+
+	updated, err := v.PrepareContent(relPath, content, false)
+	if err != nil {
+		return PublishResult{}, err
+	}
+	result.Changed = true
+	result.Revision = contentHash(updated)
+
+	// Check context cancellation before I/O; once writing starts it completes
+	if err := ctx.Err(); err != nil {
+		return PublishResult{}, err
+	}
+	written, err := v.writeNote(fullPath, updated)
+	if err != nil {
+		return PublishResult{}, err
+	}
+	return result, v.record(ctx, written)
+}
+
+// markedForPublishing reports whether the publish property of the note is
+// true
+func (v *vault) markedForPublishing(entry CacheEntry) bool {
+	value, ok := entry.Properties[v.publish.Field]
+	return ok && equalProperty(value, "true")
+}
+
+// PublishState says why ListPublishable lists a note
+type PublishState string
+
+const (
+	PublishNew       PublishState = "new"       // Marked, never recorded as published
+	PublishChanged   PublishState = "changed"   // Marked, changed since last published
+	PublishWithdrawn PublishState = "withdrawn" // Recorded as published, no longer marked
+)
+
+// PublishableNote is a note the publishing pipeline has to act on
+type PublishableNote struct {
+	Path              string       `json:"path"`
+	State             PublishState `json:"state"`
+	ContentHash       string       `json:"content_hash"`                  // Current content; pass it to MarkPublished
+	LastPublishedHash string       `json:"last_published_hash,omitempty"` // Content when last published
+	LastPublished     *time.Time   `json:"last_published,omitempty"`      // When it was recorded as published
+}
+
+// PublishableReport is the result of ListPublishable
+type PublishableReport struct {
+	Notes     []PublishableNote `json:"notes"`     // By path
+	Unchanged int               `json:"unchanged"` // Marked notes published as they are now
+}
+
+// ListPublishable returns the notes under subpath that are marked for
+// publishing and were never published or changed since, by the content
+// hash MarkPublished recorded, and the notes recorded as published that
+// are no longer marked. Records are kept in the data directory, not in
+// the notes, and follow moves. A deleted note is not listed at all, so a
+// published note should be unpublished and marked before it is deleted.
+func (v *vault) ListPublishable(ctx context.Context, subpath string) (PublishableReport, error) {
+	type candidate struct {
+		path, hash string
+		marked     bool
+	}
+	var mu sync.Mutex
+	var candidates []candidate
+	_, err := v.walkNotes(ctx, ListOptions{Subpath: subpath, Recursive: true}, func(file noteFile, entry CacheEntry) bool {
+		if isCanvas(file.relPath) {
+			return false
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		candidates = append(candidates, candidate{file.relPath, entry.ContentHash, v.markedForPublishing(entry)})
+		return false
+	})
+	if err != nil {
+		return PublishableReport{}, err
+	}
+
+	paths := make([]string, len(candidates))
+	for i, c := range candidates {
+		paths[i] = c.path
+	}
+	stored, err := v.annotations.get(paths...)
+	if err != nil {
+		return PublishableReport{}, err
+	}
+
+	report := PublishableReport{Notes: []PublishableNote{}}
+	for _, c := range candidates {
+		record, recorded := stored[c.path][publishedHashKey]
+		note := PublishableNote{Path: c.path, ContentHash: c.hash}
+		switch {
+		case c.marked && !recorded:
+			note.State = PublishNew
+		case c.marked && record.Value == c.hash:
+			report.Unchanged++
+			continue
+		case c.marked:
+			note.State = PublishChanged
+		case recorded:
+			note.State = PublishWithdrawn
+		default:
+			continue
+		}
+		if recorded {
+			note.LastPublishedHash = record.Value
+			note.LastPublished = &record.Updated
+		}
+		report.Notes = append(report.Notes, note)
+	}
+	slices.SortFunc(report.Notes, func(a, b PublishableNote) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return report, nil
+}
+
+// MarkPublishedResult reports what MarkPublished recorded
+type MarkPublishedResult struct {
+	Path              string `json:"path"`
+	Published         bool   `json:"published"`                     // The note is marked and its hash recorded; false when a withdrawal was acknowledged
+	LastPublishedHash string `json:"last_published_hash,omitempty"` // The hash recorded
+}
+
+// MarkPublished records that the note at path was published as it is
+// now, so ListPublishable leaves it out until it changes. For a note no
+// longer marked it drops the record instead, acknowledging the
+// withdrawal. A revision, a content hash or modification time from
+// ListPublishable, makes it fail with ErrRevisionMismatch when the note
+// changed since, so a version the pipeline did not publish is never
+// recorded. The note itself is not modified.
+func (v *vault) MarkPublished(ctx context.Context, path, revision string) (MarkPublishedResult, error) {
+	var rev noteRevision
+	if revision != "" {
+		var err error
+		if rev, err = parseRevision(revision); err != nil {
+			return MarkPublishedResult{}, err
+		}
+	}
+
+	fullPath, err := v.validatePath(path)
+	if err != nil {
+		return MarkPublishedResult{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return MarkPublishedResult{}, err
+	}
+	stat, err := os.Stat(fullPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return MarkPublishedResult{}, ErrNoteNotFound
+		}
+		return MarkPublishedResult{}, fmt.Errorf("failed to stat file: %w", err)
+	}
+	entry, err := v.loadEntry(fullPath, stat.ModTime())
+	if err != nil {
+		return MarkPublishedResult{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	relPath := v.relPath(fullPath)
+	if revision != "" && !rev.matches(entry.ContentHash, stat.ModTime()) {
+		return MarkPublishedResult{}, fmt.Errorf("%w: %s is at %s", ErrRevisionMismatch, relPath, entry.ContentHash)
+	}
+
+	result := MarkPublishedResult{Path: relPath, Published: v.markedForPublishing(entry)}
+	if result.Published {
+		result.LastPublishedHash = entry.ContentHash
+	}
+	err = v.annotations.update(func(notes map[string]map[string]storedAnnotation) bool {
+		annotations := notes[relPath]
+		if !result.Published {
+			if _, ok := annotations[publishedHashKey]; !ok {
+				return false
+			}
+			delete(annotations, publishedHashKey)
+			if len(annotations) == 0 {
+				delete(notes, relPath)
+			}
+			return true
+		}
+
+		if annotations == nil {
+			annotations = make(map[string]storedAnnotation)
+			notes[relPath] = annotations
+		}
+		annotations[publishedHashKey] = storedAnnotation{Value: entry.ContentHash, Updated: time.Now().UTC(), ContentHash: entry.ContentHash}
+		return true
+	})
+	if err != nil {
+		return MarkPublishedResult{}, err
+	}
+	return result, nil
+}
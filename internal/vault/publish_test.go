@@ -0,0 +1,212 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestPublishSettingsValidate(t *testing.T) {
+	tests := []struct {
+		settings PublishSettings
+		wantErr  string
+	}{
+		{PublishSettings{Field: DefaultPublishField, DateField: DefaultPublishDateField}, ""},
+		{PublishSettings{Field: "share"}, ""},
+		{PublishSettings{}, ""},
+		{PublishSettings{Field: "pub lish"}, "must be letters"},
+		{PublishSettings{DateField: "when: now"}, "must be letters"},
+		{PublishSettings{Field: "publish", DateField: "publish"}, "both"},
+	}
+	for _, tt := range tests {
+		err := tt.settings.Validate()
+		if (err == nil) != (tt.wantErr == "") || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("Validate(%+v) error = %v, want %q", tt.settings, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPublishNote(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"Post.md":  "---\ntitle: Post # shown\npublish: false # not yet\ntags: [blog]\n---\nBody\n",
+		"Plain.md": "Body\n",
+		"List.md":  "---\n- a\n---\n",
+	})
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+
+	result, err := v.PublishNote(ctx, "Post.md")
+	if err != nil {
+		t.Fatalf("PublishNote() error = %v", err)
+	}
+	if !result.Published || !result.Changed || result.PublishedAt == "" {
+		t.Errorf("PublishNote() = %+v, want the note marked", result)
+	}
+	data, _ := os.ReadFile(filepath.Join(tmpDir, "Post.md"))
+	want := "---\ntitle: Post # shown\npublish: true # not yet\ntags: [blog]\npublished_at: " + result.PublishedAt + "\n---\nBody\n"
+	if string(data) != want {
+		t.Errorf("Post.md = %q, want %q", data, want)
+	}
+	if result.Revision != contentHash(string(data)) {
+		t.Errorf("Revision = %s, want the hash of the note written", result.Revision)
+	}
+
+	// Marking a marked note keeps its date
+	again, err := v.PublishNote(ctx, "Post.md")
+	if err != nil || again.Changed || again.Revision != result.Revision {
+		t.Errorf("second PublishNote() = %+v, %v, want unchanged", again, err)
+	}
+
+	result, err = v.UnpublishNote(ctx, "Post.md")
+	if err != nil || !result.Changed || result.Published {
+		t.Errorf("UnpublishNote() = %+v, %v, want the mark cleared", result, err)
+	}
+	data, _ = os.ReadFile(filepath.Join(tmpDir, "Post.md"))
+	if want := strings.Replace(want, "publish: true", "publish: false", 1); string(data) != want {
+		t.Errorf("Post.md = %q, want %q", data, want)
+	}
+	if result, err := v.UnpublishNote(ctx, "Plain.md"); err != nil || result.Changed {
+		t.Errorf("UnpublishNote(Plain.md) = %+v, %v, want unchanged", result, err)
+	}
+
+	// A note without frontmatter gets a block; a custom field without a date
+	v, err = NewVault(tmpDir, WithPublish(PublishSettings{Field: "share"}))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	if _, err := v.PublishNote(ctx, "Plain.md"); err != nil {
+		t.Fatalf("PublishNote(Plain.md) error = %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(tmpDir, "Plain.md")); string(data) != "---\nshare: true\n---\nBody\n" {
+		t.Errorf("Plain.md = %q", data)
+	}
+
+	for _, tt := range []struct {
+		path string
+		want error
+	}{
+		{"List.md", ErrInvalidFrontmatter},
+		{"Missing.md", ErrNoteNotFound},
+		{"../outside.md", ErrPathTraversal},
+	} {
+		if _, err := v.PublishNote(ctx, tt.path); !errors.Is(err, tt.want) {
+			t.Errorf("PublishNote(%q) error = %v, want %v", tt.path, err, tt.want)
+		}
+	}
+}
+
+func TestPublishedAtFormat(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{"Post.md": "Body\n"})
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	if _, err := v.PublishNote(context.Background(), "Post.md"); err != nil {
+		t.Fatalf("PublishNote() error = %v", err)
+	}
+
+	// Unquoted, so Obsidian shows a date property
+	data, _ := os.ReadFile(filepath.Join(tmpDir, "Post.md"))
+	if !regexp.MustCompile(`(?m)^published_at: \d{4}-\d\d-\d\dT[^"']+$`).Match(data) {
+		t.Errorf("Post.md = %q, want an unquoted published_at", data)
+	}
+}
+
+func TestListPublishable(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"new.md":       "---\npublish: true\n---\nNew\n",
+		"edited.md":    "---\npublish: true\n---\nFirst\n",
+		"same.md":      "---\npublish: \"true\"\n---\nSame\n",
+		"withdrawn.md": "---\npublish: true\n---\nGone soon\n",
+		"draft.md":     "---\npublish: false\n---\n",
+		"sub/new.md":   "---\npublish: true\n---\n",
+	})
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+
+	for _, path := range []string{"edited.md", "same.md", "withdrawn.md"} {
+		result, err := v.MarkPublished(ctx, path, "")
+		if err != nil || !result.Published || result.LastPublishedHash == "" {
+			t.Fatalf("MarkPublished(%s) = %+v, %v", path, result, err)
+		}
+	}
+	if report, err := v.ListPublishable(ctx, ""); err != nil || len(report.Notes) != 2 || report.Unchanged != 3 {
+		t.Errorf("ListPublishable() = %+v, %v, want only the new notes", report, err)
+	}
+	writeFiles(t, tmpDir, map[string]string{"edited.md": "---\npublish: true\n---\nSecond\n"})
+	if _, err := v.UnpublishNote(ctx, "withdrawn.md"); err != nil {
+		t.Fatalf("UnpublishNote() error = %v", err)
+	}
+	// Records follow moves
+	if _, err := v.MoveNote(ctx, MoveNoteOptions{Path: "same.md", NewPath: "moved.md"}); err != nil {
+		t.Fatalf("MoveNote() error = %v", err)
+	}
+
+	report, err := v.ListPublishable(ctx, "")
+	if err != nil {
+		t.Fatalf("ListPublishable() error = %v", err)
+	}
+	want := map[string]PublishState{"edited.md": PublishChanged, "new.md": PublishNew, "sub/new.md": PublishNew, "withdrawn.md": PublishWithdrawn}
+	if len(report.Notes) != len(want) || report.Unchanged != 1 {
+		t.Fatalf("ListPublishable() = %+v, want %v and one unchanged", report, want)
+	}
+	for i, note := range report.Notes {
+		if i > 0 && report.Notes[i-1].Path >= note.Path {
+			t.Errorf("notes not sorted by path: %+v", report.Notes)
+		}
+		if note.State != want[note.Path] {
+			t.Errorf("%s state = %s, want %s", note.Path, note.State, want[note.Path])
+		}
+		if (note.State == PublishNew) != (note.LastPublishedHash == "" && note.LastPublished == nil) {
+			t.Errorf("%s = %+v, want the record of published notes", note.Path, note)
+		}
+	}
+	if report.Notes[0].Path != "edited.md" || report.Notes[0].LastPublishedHash == report.Notes[0].ContentHash {
+		t.Errorf("edited.md = %+v, want the new hash differing from the published one", report.Notes[0])
+	}
+
+	if sub, err := v.ListPublishable(ctx, "sub"); err != nil || len(sub.Notes) != 1 || sub.Notes[0].Path != "sub/new.md" {
+		t.Errorf("ListPublishable(sub) = %+v, %v", sub, err)
+	}
+
+	// Marking a stale revision fails; the current one is recorded
+	stale := report.Notes[0].LastPublishedHash
+	if _, err := v.MarkPublished(ctx, "edited.md", stale); !errors.Is(err, ErrRevisionMismatch) {
+		t.Errorf("MarkPublished(stale) error = %v, want ErrRevisionMismatch", err)
+	}
+	if _, err := v.MarkPublished(ctx, "edited.md", "latest"); !errors.Is(err, ErrInvalidRevision) {
+		t.Errorf("MarkPublished(latest) error = %v, want ErrInvalidRevision", err)
+	}
+	if _, err := v.MarkPublished(ctx, "edited.md", report.Notes[0].ContentHash); err != nil {
+		t.Errorf("MarkPublished(current) error = %v", err)
+	}
+	// A withdrawal is acknowledged by dropping the record
+	result, err := v.MarkPublished(ctx, "withdrawn.md", "")
+	if err != nil || result.Published || result.LastPublishedHash != "" {
+		t.Errorf("MarkPublished(withdrawn.md) = %+v, %v, want the record dropped", result, err)
+	}
+
+	report, err = v.ListPublishable(ctx, "")
+	if err != nil || len(report.Notes) != 2 || report.Unchanged != 2 {
+		t.Errorf("ListPublishable() = %+v, %v, want only the new notes left", report, err)
+	}
+
+	// The hash lives in the data directory, not in the note
+	data, _ := os.ReadFile(filepath.Join(tmpDir, "edited.md"))
+	if strings.Contains(string(data), publishedHashKey) {
+		t.Errorf("edited.md = %q, want no bookkeeping in the note", data)
+	}
+}
@@ -0,0 +1,188 @@
+package vault
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// QueryMode selects how Search reads its query patterns
+type QueryMode string
+
+// Query modes
+const (
+	QueryRegex   QueryMode = "regex"   // Pattern is a Go regular expression
+	QueryLiteral QueryMode = "literal" // Pattern is plain text
+	QueryWord    QueryMode = "word"    // Pattern is plain text matched as whole words
+)
+
+// ParseQueryMode validates a query mode, defaulting to regex
+func ParseQueryMode(s string) (QueryMode, error) {
+	switch mode := QueryMode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case "":
+		return QueryRegex, nil
+	case QueryRegex, QueryLiteral, QueryWord:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown match mode %q (want regex, literal or word)", s)
+	}
+}
+
+// Word boundaries for QueryWord: any character that cannot be part of a
+// word, or the start or end of the content
+const (
+	wordStart = `(?:^|[^\pL\pN\pM_])`
+	wordEnd   = `(?:[^\pL\pN\pM_]|$)`
+)
+
+// queryPattern is one compiled query pattern
+type queryPattern struct {
+	re     *regexp.Regexp
+	folded bool // Matched against the folded content instead of the NFC content
+}
+
+// queryMatcher holds the compiled query patterns of a search
+type queryMatcher struct {
+	all  []queryPattern // Each must match
+	any  []queryPattern // One must match, unless empty
+	none []queryPattern // None may match
+}
+
+// haystack is a note's content as the query patterns see it, normalized
+// on first use and at most once per note
+type haystack struct {
+	content             string
+	nfc, folded         string
+	haveNFC, haveFolded bool
+}
+
+// text returns the content NFC-normalized, or folded when folded is set
+func (h *haystack) text(folded bool) string {
+	if folded {
+		if !h.haveFolded {
+			h.folded, h.haveFolded = foldText(h.content), true
+		}
+		return h.folded
+	}
+	if !h.haveNFC {
+		h.nfc, h.haveNFC = norm.NFC.String(h.content), true
+	}
+	return h.nfc
+}
+
+// match reports whether p matches the content
+func (h *haystack) match(p queryPattern) bool {
+	return p.re.MatchString(h.text(p.folded))
+}
+
+// matches reports whether content satisfies every query pattern
+// Required patterns come first since most notes fail one of them, then
+// exclusions, and the alternatives only for notes still in the running
+func (m queryMatcher) matches(content string) bool {
+	h := &haystack{content: content}
+	for _, p := range m.all {
+		if !h.match(p) {
+			return false
+		}
+	}
+	for _, p := range m.none {
+		if h.match(p) {
+			return false
+		}
+	}
+	if len(m.any) == 0 {
+		return true
+	}
+	for _, p := range m.any {
+		if h.match(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// score counts the matches in content of the patterns that must or may
+// match, ranking the notes of a search by relevance
+func (m queryMatcher) score(content string) int {
+	h := &haystack{content: content}
+	n := 0
+	for _, patterns := range [][]queryPattern{m.all, m.any} {
+		for _, p := range patterns {
+			n += len(p.re.FindAllStringIndex(h.text(p.folded), -1))
+		}
+	}
+	return n
+}
+
+// queriesAll returns the patterns that must all match, Query included
+func (opts SearchOptions) queriesAll() []string {
+	if opts.Query == "" {
+		return opts.QueryAll
+	}
+	return append([]string{opts.Query}, opts.QueryAll...)
+}
+
+// compileQueries compiles the query patterns of opts, naming the first
+// one that fails in a *PatternError
+func (v *vault) compileQueries(opts SearchOptions) (queryMatcher, error) {
+	mode, err := ParseQueryMode(string(opts.QueryMode))
+	if err != nil {
+		return queryMatcher{}, fmt.Errorf("%w: %s", ErrInvalidPattern, err)
+	}
+
+	var m queryMatcher
+	for _, list := range []struct {
+		param    string
+		patterns []string
+		into     *[]queryPattern
+	}{
+		{"query", []string{opts.Query}, &m.all},
+		{"query_all", opts.QueryAll, &m.all},
+		{"query_any", opts.QueryAny, &m.any},
+		{"query_none", opts.QueryNone, &m.none},
+	} {
+		for i, pattern := range list.patterns {
+			if pattern == "" {
+				if list.param == "query" {
+					continue // An empty query leaves only the other filters
+				}
+				return queryMatcher{}, &PatternError{Param: list.param, Index: i, Pattern: pattern, Reason: "empty pattern"}
+			}
+			expr, folded := queryExpr(pattern, mode, opts.CaseSensitive)
+			re, err := v.getOrCompileRegex(expr)
+			if err != nil {
+				return queryMatcher{}, newPatternError(list.param, i, pattern, err)
+			}
+			*list.into = append(*list.into, queryPattern{re, folded})
+		}
+	}
+	return m, nil
+}
+
+// queryExpr returns the regular expression matching pattern in mode, and
+// whether it is to be matched against folded content
+// Patterns are NFC-normalized like the content; case-insensitive literal
+// and word patterns are folded instead of relying on (?i), which misses
+// matches such as "straße" in "STRASSE"
+func queryExpr(pattern string, mode QueryMode, caseSensitive bool) (string, bool) {
+	folded := !caseSensitive && mode != QueryRegex
+	if folded {
+		pattern = foldText(pattern)
+	} else {
+		pattern = norm.NFC.String(pattern)
+	}
+
+	expr := pattern
+	switch mode {
+	case QueryLiteral:
+		expr = regexp.QuoteMeta(pattern)
+	case QueryWord:
+		expr = wordStart + regexp.QuoteMeta(pattern) + wordEnd
+	}
+	if !caseSensitive && !folded {
+		expr = "(?i)" + expr
+	}
+	return expr, folded
+}
@@ -0,0 +1,229 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestSearchQueries(t *testing.T) {
+	tmpDir := t.TempDir()
+	notes := map[string]string{
+		"k8s.md":      "Deploying Kubernetes with plain manifests #ops",
+		"helm.md":     "Kubernetes charts packaged with Helm #ops",
+		"docker.md":   "Docker images for the build #dev",
+		"planning.md": "Planning the Q3 roadmap #dev",
+		"plan.md":     "The plan: ship v1.2 (beta)",
+	}
+	for path, content := range notes {
+		if err := os.WriteFile(filepath.Join(tmpDir, path), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file %s: %v", path, err)
+		}
+	}
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		opts SearchOptions
+		want []string
+	}{
+		{
+			name: "all but none",
+			opts: SearchOptions{QueryAll: []string{"kubernetes"}, QueryNone: []string{"helm"}},
+			want: []string{"k8s.md"},
+		},
+		{
+			name: "query is sugar for query_all",
+			opts: SearchOptions{Query: "kubernetes", QueryAll: []string{"charts"}},
+			want: []string{"helm.md"},
+		},
+		{
+			name: "any",
+			opts: SearchOptions{QueryAny: []string{"docker", "helm", "roadmap"}},
+			want: []string{"docker.md", "helm.md", "planning.md"},
+		},
+		{
+			name: "all and any",
+			opts: SearchOptions{QueryAll: []string{"with"}, QueryAny: []string{"manifests", "docker"}},
+			want: []string{"k8s.md"},
+		},
+		{
+			name: "only none over the whole vault",
+			opts: SearchOptions{QueryNone: []string{"kubernetes", "docker"}},
+			want: []string{"plan.md", "planning.md"},
+		},
+		{
+			name: "none matching nothing keeps every note",
+			opts: SearchOptions{QueryNone: []string{"nothing-matches-this"}},
+			want: []string{"docker.md", "helm.md", "k8s.md", "plan.md", "planning.md"},
+		},
+		{
+			name: "none with tags",
+			opts: SearchOptions{TagsAny: []string{"ops"}, QueryNone: []string{"helm"}},
+			want: []string{"k8s.md"},
+		},
+		{
+			name: "any with tags",
+			opts: SearchOptions{TagsAll: []string{"dev"}, QueryAny: []string{"kubernetes", "q3"}},
+			want: []string{"planning.md"},
+		},
+		{
+			name: "regex",
+			opts: SearchOptions{QueryAny: []string{`v\d\.\d`, "^docker"}},
+			want: []string{"docker.md", "plan.md"},
+		},
+		{
+			name: "literal",
+			opts: SearchOptions{QueryAll: []string{"(beta)", "v1.2"}, QueryMode: QueryLiteral},
+			want: []string{"plan.md"},
+		},
+		{
+			name: "word",
+			opts: SearchOptions{QueryAll: []string{"plan"}, QueryMode: QueryWord},
+			want: []string{"plan.md"},
+		},
+		{
+			name: "word none",
+			opts: SearchOptions{QueryAny: []string{"plan", "planning"}, QueryNone: []string{"ship"}, QueryMode: QueryWord},
+			want: []string{"planning.md"},
+		},
+		{
+			name: "case-insensitive by default",
+			opts: SearchOptions{QueryAll: []string{"KUBERNETES"}},
+			want: []string{"helm.md", "k8s.md"},
+		},
+		{
+			name: "case-sensitive",
+			opts: SearchOptions{QueryAny: []string{"Helm", "docker"}, CaseSensitive: true},
+			want: []string{"helm.md"},
+		},
+		{
+			name: "case-sensitive none",
+			opts: SearchOptions{QueryAll: []string{"Kubernetes"}, QueryNone: []string{"helm"}, CaseSensitive: true},
+			want: []string{"helm.md", "k8s.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := v.Search(ctx, tt.opts)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			if paths := slices.Sorted(slices.Values(notePaths(got))); !reflect.DeepEqual(paths, tt.want) {
+				t.Errorf("Search() = %v, want %v", paths, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchQueriesUnicode(t *testing.T) {
+	tmpDir := t.TempDir()
+	notes := map[string]string{
+		"berlin.md":   "Treffen in der SCHLOSSSTRASSE #Straße",
+		"cafe.md":     "Notes from the cafe\u0301 downstairs #cafe\u0301", // Decomposed accent
+		"istanbul.md": "Trip to İSTANBUL #İstanbul",
+		"ascii.md":    "Plain Cafe and Istanbul notes",
+	}
+	for path, content := range notes {
+		if err := os.WriteFile(filepath.Join(tmpDir, path), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file %s: %v", path, err)
+		}
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		opts SearchOptions
+		want []string
+	}{
+		{"literal folds ß", SearchOptions{Query: "straße", QueryMode: QueryLiteral}, []string{"berlin.md"}},
+		{"literal composed query", SearchOptions{Query: "CAFÉ", QueryMode: QueryLiteral}, []string{"cafe.md"}},
+		{"word Turkish i", SearchOptions{Query: "istanbul", QueryMode: QueryWord}, []string{"ascii.md", "istanbul.md"}},
+		{"word dotless i", SearchOptions{Query: "ıstanbul", QueryMode: QueryWord}, []string{"ascii.md", "istanbul.md"}},
+		{"case-sensitive literal is only normalized", SearchOptions{Query: "café", QueryMode: QueryLiteral, CaseSensitive: true}, []string{"cafe.md"}},
+		{"regex sees NFC content", SearchOptions{Query: "caf\u00e9 down", CaseSensitive: true}, []string{"cafe.md"}},
+		{"tag filters fold", SearchOptions{TagsAny: []string{"CAFÉ", "STRASSE"}}, []string{"berlin.md", "cafe.md"}},
+		{"tag filters Turkish i", SearchOptions{TagsAll: []string{"#istanbul"}}, []string{"istanbul.md"}},
+	}
+
+	// The index must not rule out notes the folded matching finds
+	for _, indexed := range []bool{false, true} {
+		var opts []Option
+		if indexed {
+			opts = append(opts, WithSearchIndex())
+		}
+		v, err := NewVault(tmpDir, opts...)
+		if err != nil {
+			t.Fatalf("Failed to create vault: %v", err)
+		}
+		if _, err := v.Search(ctx, SearchOptions{}); err != nil { // Fills the index
+			t.Fatalf("Search() error = %v", err)
+		}
+
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s indexed=%v", tt.name, indexed), func(t *testing.T) {
+				got, err := v.Search(ctx, tt.opts)
+				if err != nil {
+					t.Fatalf("Search() error = %v", err)
+				}
+				if paths := slices.Sorted(slices.Values(notePaths(got))); !reflect.DeepEqual(paths, tt.want) {
+					t.Errorf("Search() = %v, want %v", paths, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestSearchQueryErrors(t *testing.T) {
+	v, _ := setupTestVault(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		opts     SearchOptions
+		want     string // PatternError.Name, empty for a mode error
+		fragment string // PatternError.Fragment
+		position int    // PatternError.Position
+	}{
+		{"query", SearchOptions{Query: "[invalid("}, "query", "[invalid(", 0},
+		{"query_all", SearchOptions{QueryAll: []string{"ok", "("}}, "query_all[1]", "(", 0},
+		{"query_any", SearchOptions{QueryAny: []string{"a)"}}, "query_any[0]", "a)", 0},
+		{"query_none", SearchOptions{QueryNone: []string{"x", "y", "a**"}}, "query_none[2]", "**", 1},
+		{"empty pattern", SearchOptions{QueryNone: []string{""}}, "query_none[0]", "", 0},
+		{"mode", SearchOptions{Query: "x", QueryMode: "glob"}, "", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.Search(ctx, tt.opts)
+			if !errors.Is(err, ErrInvalidPattern) {
+				t.Fatalf("Search() error = %v, want ErrInvalidPattern", err)
+			}
+			var patternErr *PatternError
+			if errors.As(err, &patternErr) != (tt.want != "") {
+				t.Fatalf("Search() error = %v, want a PatternError: %v", err, tt.want != "")
+			}
+			if tt.want != "" && patternErr.Name() != tt.want {
+				t.Errorf("PatternError.Name() = %q, want %q", patternErr.Name(), tt.want)
+			}
+			if tt.want != "" && (patternErr.Fragment != tt.fragment || patternErr.Position != tt.position) {
+				t.Errorf("PatternError fragment = %q at %d, want %q at %d", patternErr.Fragment, patternErr.Position, tt.fragment, tt.position)
+			}
+		})
+	}
+
+	// Literal mode never fails to compile
+	if _, err := v.Search(ctx, SearchOptions{Query: "[invalid(", QueryMode: QueryLiteral}); err != nil {
+		t.Errorf("Search() literal error = %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package vault
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// RecentOptions selects the notes Recent returns
+type RecentOptions struct {
+	Subpath string    // Directory to look in, empty for the whole vault
+	Since   time.Time // Only notes modified at or after this time
+	Limit   int       // Maximum number of notes, 0 for all
+	Type    string    // Only notes of this type, see WithNoteTypes
+}
+
+// Recent returns the notes selected by opts, sorted newest first and
+// truncated to opts.Limit when it is positive
+func (v *vault) Recent(ctx context.Context, opts RecentOptions) ([]NoteInfo, error) {
+	notes, err := v.List(ctx, ListOptions{Subpath: opts.Subpath, Recursive: true, Type: opts.Type})
+	if err != nil {
+		return nil, err
+	}
+
+	recent := make([]NoteInfo, 0, len(notes))
+	for _, note := range notes {
+		if !note.Modified.Before(opts.Since) {
+			recent = append(recent, note)
+		}
+	}
+
+	// Newest first, ties broken by path for stable output
+	sort.Slice(recent, func(i, j int) bool {
+		if !recent[i].Modified.Equal(recent[j].Modified) {
+			return recent[i].Modified.After(recent[j].Modified)
+		}
+		return recent[i].Path < recent[j].Path
+	})
+
+	if opts.Limit > 0 && len(recent) > opts.Limit {
+		recent = recent[:opts.Limit]
+	}
+
+	return recent, nil
+}
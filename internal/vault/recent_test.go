@@ -0,0 +1,73 @@
+package vault
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecent(t *testing.T) {
+	v, tmpDir := setupTestVault(t)
+	ctx := context.Background()
+
+	// Age every note, then make two of them recent at distinct times
+	old := time.Now().Add(-30 * 24 * time.Hour)
+	err := filepath.Walk(tmpDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		return os.Chtimes(path, old, old)
+	})
+	if err != nil {
+		t.Fatalf("Failed to age notes: %v", err)
+	}
+
+	now := time.Now()
+	if err := os.Chtimes(filepath.Join(tmpDir, "note2.md"), now, now.Add(-time.Hour)); err != nil {
+		t.Fatalf("Failed to touch note: %v", err)
+	}
+	if err := os.Chtimes(filepath.Join(tmpDir, "subdir", "note3.md"), now, now.Add(-time.Minute)); err != nil {
+		t.Fatalf("Failed to touch note: %v", err)
+	}
+
+	t.Run("newest first", func(t *testing.T) {
+		notes, err := v.Recent(ctx, RecentOptions{Since: now.Add(-24 * time.Hour)})
+		if err != nil {
+			t.Fatalf("Recent() error = %v", err)
+		}
+
+		if len(notes) != 2 {
+			t.Fatalf("Expected 2 notes, got %d", len(notes))
+		}
+		if notes[0].Path != filepath.Join("subdir", "note3.md") || notes[1].Path != "note2.md" {
+			t.Errorf("Unexpected order: %s, %s", notes[0].Path, notes[1].Path)
+		}
+		if notes[0].Modified.IsZero() || notes[0].Created.IsZero() {
+			t.Error("Expected Modified and Created to be set")
+		}
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		notes, err := v.Recent(ctx, RecentOptions{Since: now.Add(-24 * time.Hour), Limit: 1})
+		if err != nil {
+			t.Fatalf("Recent() error = %v", err)
+		}
+
+		if len(notes) != 1 {
+			t.Errorf("Expected 1 note, got %d", len(notes))
+		}
+	})
+
+	t.Run("subpath", func(t *testing.T) {
+		notes, err := v.Recent(ctx, RecentOptions{Subpath: "other", Since: now.Add(-24 * time.Hour)})
+		if err != nil {
+			t.Fatalf("Recent() error = %v", err)
+		}
+
+		if len(notes) != 0 {
+			t.Errorf("Expected 0 notes, got %d", len(notes))
+		}
+	})
+}
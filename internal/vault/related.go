@@ -0,0 +1,348 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Weights of the relatedness signals
+const (
+	relatedTagWeight     = 1.0 // Per shared tag
+	relatedLinkWeight    = 1.0 // Per shared outgoing link target
+	relatedMutualWeight  = 2.0 // Per direction one note links to the other
+	relatedFolderWeight  = 1.0 // Same folder, less for folders further apart
+	relatedContentWeight = 3.0 // Cosine similarity of title and heading terms
+)
+
+// defaultRelatedLimit is the number of related notes returned by default
+const defaultRelatedLimit = 10
+
+// RelatedOptions selects the note to find related notes for
+// Either Path or Content is required. With Content, Path is optional and
+// names where the not-yet-created note will live, for folder proximity.
+type RelatedOptions struct {
+	Path       string // Vault-relative path of the note
+	Content    string // Raw content of a note that does not exist yet
+	Limit      int    // Maximum results, 0 or less for the default of 10
+	UseContent bool   // Also compare terms in titles and headings
+}
+
+// RelatedScore breaks a relatedness score down by signal
+type RelatedScore struct {
+	SharedTags  float64 `json:"shared_tags"`
+	SharedLinks float64 `json:"shared_links"`
+	MutualLinks float64 `json:"mutual_links"`
+	Folder      float64 `json:"folder"`
+	Content     float64 `json:"content,omitempty"`
+}
+
+// total sums the signals
+func (s RelatedScore) total() float64 {
+	return s.SharedTags + s.SharedLinks + s.MutualLinks + s.Folder + s.Content
+}
+
+// RelatedNote is a note related to the requested one
+type RelatedNote struct {
+	Path        string       `json:"path"`
+	Score       float64      `json:"score"`
+	Breakdown   RelatedScore `json:"breakdown"`
+	SharedTags  []string     `json:"shared_tags,omitempty"`
+	SharedLinks []string     `json:"shared_links,omitempty"` // Link targets both notes reference
+}
+
+// relatedProfile holds the signals compared between two notes
+type relatedProfile struct {
+	path  string              // Vault-relative path with forward slashes
+	tags  map[string]string   // Folded tag -> tag as reported
+	links map[string]struct{} // Resolved targets of outgoing note links
+	terms map[string]float64  // Title and heading term counts, with UseContent
+}
+
+// newRelatedProfile builds the profile of a note titled title from its
+// parsed entry
+func newRelatedProfile(relPath, title string, entry CacheEntry, index *fileIndex, useContent bool) relatedProfile {
+	p := relatedProfile{
+		path:  relPath,
+		tags:  make(map[string]string, len(entry.Tags)),
+		links: make(map[string]struct{}, len(entry.Links)),
+	}
+	for _, tag := range entry.Tags {
+		p.tags[foldTag(tag)] = tag
+	}
+	for _, link := range entry.Links {
+		if link.Kind == LinkURL || link.Target == "" {
+			continue
+		}
+		// Unresolved targets still relate notes waiting for the same page
+		target, ok := index.resolve(relPath, link.Target)
+		if !ok {
+			target = strings.ToLower(strings.TrimPrefix(filepath.ToSlash(link.Target), "/"))
+			if path.Ext(target) == "" {
+				target += ".md"
+			}
+		}
+		p.links[target] = struct{}{}
+	}
+
+	if useContent {
+		p.terms = make(map[string]float64)
+		texts := []string{title}
+		for _, h := range entry.Headings {
+			texts = append(texts, h.Text)
+		}
+		for _, text := range texts {
+			for _, term := range contentTerms(text) {
+				p.terms[term]++
+			}
+		}
+	}
+
+	return p
+}
+
+// contentTerms splits text into lowercase words of at least three letters
+func contentTerms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := words[:0]
+	for _, w := range words {
+		if len([]rune(w)) >= 3 {
+			terms = append(terms, w)
+		}
+	}
+	return terms
+}
+
+// Related ranks other notes by relatedness to the note selected by opts
+// using shared tags, shared link targets, links between the two notes and
+// folder proximity, optionally adding title and heading similarity. Tags
+// and links come from the note cache. Notes related only by folder are
+// left out, and ties are broken by path so results are deterministic.
+func (v *vault) Related(ctx context.Context, opts RelatedOptions) ([]RelatedNote, error) {
+	if opts.Path == "" && opts.Content == "" {
+		return nil, fmt.Errorf("%w: path or content is required", ErrInvalidPath)
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = defaultRelatedLimit
+	}
+
+	index, err := v.buildFileIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Profile the source note
+	var source relatedProfile
+	switch {
+	case opts.Content != "":
+		relPath := ""
+		if opts.Path != "" {
+			fullPath, err := v.validatePath(opts.Path)
+			if err != nil {
+				return nil, err
+			}
+			relPath = v.relPath(fullPath)
+		}
+		entry := newCacheEntry(opts.Content, time.Time{})
+		title, _ := v.noteTitle(relPath, entry)
+		source = newRelatedProfile(relPath, title, entry, index, opts.UseContent)
+	default:
+		fullPath, err := v.validatePath(opts.Path)
+		if err != nil {
+			return nil, err
+		}
+		stat, err := os.Stat(fullPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, ErrNoteNotFound
+			}
+			return nil, fmt.Errorf("failed to stat file: %w", err)
+		}
+		entry, err := v.loadEntry(fullPath, stat.ModTime())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		relPath := v.relPath(fullPath)
+		title, _ := v.noteTitle(relPath, entry)
+		source = newRelatedProfile(relPath, title, entry, index, opts.UseContent)
+	}
+
+	// Profile every other note from the cache
+	var mu sync.Mutex
+	var candidates []relatedProfile
+	_, err = v.walkNotes(ctx, ListOptions{Recursive: true}, func(file noteFile, entry CacheEntry) bool {
+		if file.relPath == source.path {
+			return false
+		}
+		title, _ := v.noteTitle(file.relPath, entry)
+		p := newRelatedProfile(file.relPath, title, entry, index, opts.UseContent)
+		mu.Lock()
+		candidates = append(candidates, p)
+		mu.Unlock()
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var idf map[string]float64
+	if opts.UseContent {
+		idf = inverseDocumentFrequency(append(candidates, source))
+	}
+
+	var related []RelatedNote
+	for _, c := range candidates {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if note, ok := scoreRelated(source, c, idf); ok {
+			related = append(related, note)
+		}
+	}
+
+	sort.Slice(related, func(i, j int) bool {
+		if related[i].Score != related[j].Score {
+			return related[i].Score > related[j].Score
+		}
+		return related[i].Path < related[j].Path
+	})
+	if len(related) > limit {
+		related = related[:limit]
+	}
+
+	return related, nil
+}
+
+// scoreRelated compares a candidate with the source note, reporting false
+// when nothing but folder proximity relates them
+func scoreRelated(source, c relatedProfile, idf map[string]float64) (RelatedNote, bool) {
+	note := RelatedNote{Path: c.path}
+
+	for key, tag := range source.tags {
+		if _, ok := c.tags[key]; ok {
+			note.SharedTags = append(note.SharedTags, tag)
+		}
+	}
+	sort.Strings(note.SharedTags)
+	note.Breakdown.SharedTags = relatedTagWeight * float64(len(note.SharedTags))
+
+	for target := range source.links {
+		if target == c.path || target == source.path {
+			continue // Counted as a mutual link
+		}
+		if _, ok := c.links[target]; ok {
+			note.SharedLinks = append(note.SharedLinks, target)
+		}
+	}
+	sort.Strings(note.SharedLinks)
+	note.Breakdown.SharedLinks = relatedLinkWeight * float64(len(note.SharedLinks))
+
+	if _, ok := source.links[c.path]; ok {
+		note.Breakdown.MutualLinks += relatedMutualWeight
+	}
+	if source.path != "" {
+		// Links to a note not created yet stay unresolved and lowercase
+		_, linked := c.links[source.path]
+		_, pending := c.links[strings.ToLower(source.path)]
+		if linked || pending {
+			note.Breakdown.MutualLinks += relatedMutualWeight
+		}
+	}
+
+	if idf != nil {
+		note.Breakdown.Content = roundScore(relatedContentWeight * cosineSimilarity(source.terms, c.terms, idf))
+	}
+
+	if note.Breakdown.total() == 0 {
+		return RelatedNote{}, false
+	}
+
+	if source.path != "" {
+		note.Breakdown.Folder = roundScore(relatedFolderWeight * folderProximity(path.Dir(source.path), path.Dir(c.path)))
+	}
+	note.Score = roundScore(note.Breakdown.total())
+
+	return note, true
+}
+
+// folderProximity is 1 for notes in the same folder and 1/(1+n) for
+// folders n steps apart in the tree
+func folderProximity(a, b string) float64 {
+	split := func(dir string) []string {
+		if dir == "." || dir == "" {
+			return nil
+		}
+		return strings.Split(dir, "/")
+	}
+	pa, pb := split(a), split(b)
+
+	common := 0
+	for common < len(pa) && common < len(pb) && pa[common] == pb[common] {
+		common++
+	}
+	steps := len(pa) - common + len(pb) - common
+	return 1 / float64(1+steps)
+}
+
+// inverseDocumentFrequency weights terms by how few notes use them
+func inverseDocumentFrequency(profiles []relatedProfile) map[string]float64 {
+	df := make(map[string]int)
+	for _, p := range profiles {
+		for term := range p.terms {
+			df[term]++
+		}
+	}
+	idf := make(map[string]float64, len(df))
+	for term, n := range df {
+		idf[term] = math.Log(float64(1+len(profiles)) / float64(1+n))
+	}
+	return idf
+}
+
+// cosineSimilarity compares two term count vectors weighted by idf
+func cosineSimilarity(a, b map[string]float64, idf map[string]float64) float64 {
+	var dot, normA, normB float64
+	for _, term := range sortedTerms(a) {
+		wa := a[term] * idf[term]
+		normA += wa * wa
+		if count, ok := b[term]; ok {
+			dot += wa * count * idf[term]
+		}
+	}
+	for _, term := range sortedTerms(b) {
+		wb := b[term] * idf[term]
+		normB += wb * wb
+	}
+	if dot == 0 || normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// sortedTerms returns the terms of a vector in order so floating point
+// sums do not depend on map iteration order
+func sortedTerms(terms map[string]float64) []string {
+	keys := make([]string, 0, len(terms))
+	for term := range terms {
+		keys = append(keys, term)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// roundScore rounds a score to three decimals for stable output
+func roundScore(score float64) float64 {
+	return math.Round(score*1000) / 1000
+}
@@ -0,0 +1,180 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func setupRelatedVault(t *testing.T) Vault {
+	t.Helper()
+	tmpDir := t.TempDir()
+
+	notes := map[string]string{
+		"projects/garden.md":      "# Garden plan\n#garden #spring\nSee [[Tomatoes]] and [[Compost]].",
+		"projects/tomatoes.md":    "# Growing tomatoes\n#garden\nBack to [[garden]].",
+		"projects/balcony.md":     "# Balcony herbs\n#spring\nNeeds [[Compost]].",
+		"archive/old-garden.md":   "# Old garden\n#garden #spring\nUsed [[Compost]] and [[Tomatoes]].",
+		"projects/unrelated.md":   "# Taxes\n#finance",
+		"journal/2024/spring.md":  "# Spring planting plan\nNothing tagged.",
+		"compost.md":              "# Compost",
+		"projects/same-folder.md": "# Same folder only",
+	}
+	writeFiles(t, tmpDir, notes)
+
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	return v
+}
+
+func TestRelated(t *testing.T) {
+	v := setupRelatedVault(t)
+	ctx := context.Background()
+
+	related, err := v.Related(ctx, RelatedOptions{Path: "projects/garden.md"})
+	if err != nil {
+		t.Fatalf("Related() error = %v", err)
+	}
+
+	var paths []string
+	for _, r := range related {
+		paths = append(paths, r.Path)
+	}
+	// old-garden: 2 tags + 2 links + folder 1/3; tomatoes: 1 tag + 4 mutual + folder 1;
+	// compost: 2 mutual + folder 0.5; balcony: 1 tag + 1 link + folder 1
+	want := []string{"projects/tomatoes.md", "archive/old-garden.md", "projects/balcony.md", "compost.md"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Fatalf("Related() paths = %v, want %v", paths, want)
+	}
+
+	tomatoes := related[0]
+	wantScore := RelatedScore{SharedTags: 1, MutualLinks: 4, Folder: 1}
+	if tomatoes.Breakdown != wantScore || tomatoes.Score != 6 {
+		t.Errorf("tomatoes = %+v, want breakdown %+v and score 6", tomatoes, wantScore)
+	}
+
+	old := related[1]
+	if !reflect.DeepEqual(old.SharedTags, []string{"garden", "spring"}) ||
+		!reflect.DeepEqual(old.SharedLinks, []string{"compost.md", "projects/tomatoes.md"}) {
+		t.Errorf("old-garden = %+v", old)
+	}
+	if old.Breakdown.Folder != 0.333 {
+		t.Errorf("old-garden folder = %v, want 0.333", old.Breakdown.Folder)
+	}
+
+	// Deterministic for identical inputs
+	for range 5 {
+		again, err := v.Related(ctx, RelatedOptions{Path: "projects/garden.md"})
+		if err != nil {
+			t.Fatalf("Related() error = %v", err)
+		}
+		if !reflect.DeepEqual(again, related) {
+			t.Fatalf("Related() not deterministic: %+v vs %+v", again, related)
+		}
+	}
+
+	limited, _ := v.Related(ctx, RelatedOptions{Path: "projects/garden.md", Limit: 2})
+	if len(limited) != 2 || limited[0].Path != want[0] {
+		t.Errorf("Related() with limit = %+v", limited)
+	}
+}
+
+func TestRelatedContent(t *testing.T) {
+	v := setupRelatedVault(t)
+	ctx := context.Background()
+
+	content := "# Spring garden\n#spring\nMore about [[Compost]]."
+	related, err := v.Related(ctx, RelatedOptions{Content: content, Path: "projects/new.md"})
+	if err != nil {
+		t.Fatalf("Related() error = %v", err)
+	}
+	if len(related) == 0 {
+		t.Fatal("Related() returned nothing")
+	}
+	for _, r := range related {
+		if r.Path == "projects/unrelated.md" || r.Path == "projects/same-folder.md" {
+			t.Errorf("Related() included %s", r.Path)
+		}
+	}
+
+	// The stored copy of a note is excluded when its path is given
+	related, err = v.Related(ctx, RelatedOptions{Content: "#garden", Path: "projects/tomatoes.md"})
+	if err != nil {
+		t.Fatalf("Related() error = %v", err)
+	}
+	for _, r := range related {
+		if r.Path == "projects/tomatoes.md" {
+			t.Errorf("Related() included the note itself")
+		}
+	}
+
+	// Without a path there is no folder signal
+	related, err = v.Related(ctx, RelatedOptions{Content: content})
+	if err != nil {
+		t.Fatalf("Related() error = %v", err)
+	}
+	for _, r := range related {
+		if r.Breakdown.Folder != 0 {
+			t.Errorf("%s folder = %v, want 0 without a path", r.Path, r.Breakdown.Folder)
+		}
+	}
+}
+
+func TestRelatedUseContent(t *testing.T) {
+	v := setupRelatedVault(t)
+	ctx := context.Background()
+
+	// The journal note shares no tags or links, only heading words
+	content := "# Spring planting"
+	without, err := v.Related(ctx, RelatedOptions{Content: content})
+	if err != nil {
+		t.Fatalf("Related() error = %v", err)
+	}
+	if len(without) != 0 {
+		t.Errorf("Related() without use_content = %+v, want none", without)
+	}
+
+	with, err := v.Related(ctx, RelatedOptions{Content: content, UseContent: true})
+	if err != nil {
+		t.Fatalf("Related() error = %v", err)
+	}
+	if len(with) == 0 || with[0].Path != "journal/2024/spring.md" || with[0].Breakdown.Content <= 0 {
+		t.Errorf("Related() with use_content = %+v, want journal/2024/spring.md first", with)
+	}
+}
+
+func TestRelatedErrors(t *testing.T) {
+	v := setupRelatedVault(t)
+	ctx := context.Background()
+
+	if _, err := v.Related(ctx, RelatedOptions{}); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("Expected ErrInvalidPath, got %v", err)
+	}
+	if _, err := v.Related(ctx, RelatedOptions{Path: "missing.md"}); !errors.Is(err, ErrNoteNotFound) {
+		t.Errorf("Expected ErrNoteNotFound, got %v", err)
+	}
+	if _, err := v.Related(ctx, RelatedOptions{Path: "../outside.md"}); !errors.Is(err, ErrPathTraversal) {
+		t.Errorf("Expected ErrPathTraversal, got %v", err)
+	}
+}
+
+func TestFolderProximity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{".", ".", 1},
+		{"a/b", "a/b", 1},
+		{"a", "a/b", 0.5},
+		{"a/b", "a/c", 1.0 / 3},
+		{".", "a/b", 1.0 / 3},
+	}
+	for _, tt := range tests {
+		if got := folderProximity(tt.a, tt.b); got != tt.want {
+			t.Errorf("folderProximity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,426 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Defaults of ReplaceOptions
+const (
+	DefaultReplaceMaxFiles = 50 // Notes changed by one call
+	maxReplaceSamples      = 3  // Sample lines reported per note
+)
+
+// ReplaceMode selects how ReplaceInNotes reads its pattern
+type ReplaceMode string
+
+// Replace modes
+const (
+	ReplaceLiteral ReplaceMode = "literal" // Pattern is plain text
+	ReplaceRegex   ReplaceMode = "regex"   // Pattern is a Go regular expression
+)
+
+// ParseReplaceMode validates a replace mode, defaulting to literal
+func ParseReplaceMode(s string) (ReplaceMode, error) {
+	switch mode := ReplaceMode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case "":
+		return ReplaceLiteral, nil
+	case ReplaceLiteral, ReplaceRegex:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown match mode %q (want literal or regex)", s)
+	}
+}
+
+// ReplaceOptions describes a search and replace across notes
+type ReplaceOptions struct {
+	Pattern     string      // Text or regular expression to find
+	Replacement string      // Replacement text; $1 or ${name} insert capture groups in regex mode
+	Mode        ReplaceMode // How Pattern is read, literal when empty
+	IgnoreCase  bool        // Match regardless of case
+
+	// PreserveCase gives each literal replacement the case of the text it
+	// replaces (foo, Foo, FOO) and implies IgnoreCase
+	PreserveCase bool
+
+	Path           string   // Note or folder to change, empty for the whole vault
+	TagsAny        []string // Notes must have at least one of these tags
+	MaxFiles       int      // Notes changed at most, DefaultReplaceMaxFiles if 0
+	MaxPerFile     int      // Replacements per note at most, 0 for all
+	SkipCodeBlocks bool     // Leave fenced code blocks unchanged
+	DryRun         bool     // Report the changes without writing anything
+}
+
+// ReplaceStatus is the outcome of ReplaceInNotes for one note
+type ReplaceStatus string
+
+// Replace statuses
+const (
+	ReplaceChanged ReplaceStatus = "changed" // Written, or would be on a dry run
+	ReplaceSkipped ReplaceStatus = "skipped" // Left alone, e.g. read-only or no longer matching
+	ReplaceFailed  ReplaceStatus = "failed"  // Could not be written
+)
+
+// ReplaceSample shows one changed line of a note
+type ReplaceSample struct {
+	Line   int    `json:"line"` // 1-based line in the note before the change
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// FileReplacement reports the replacements in one note
+type FileReplacement struct {
+	Path         string          `json:"path"`
+	Status       ReplaceStatus   `json:"status"`
+	Matches      int             `json:"matches"`      // Matches found, outside skipped code blocks
+	Replacements int             `json:"replacements"` // Matches replaced, at most MaxPerFile
+	Samples      []ReplaceSample `json:"samples,omitempty"`
+	Revision     string          `json:"revision,omitempty"` // content_hash after the change
+	Err          error           `json:"-"`                  // Why the note was skipped or failed
+}
+
+// ReplaceResult reports the outcome of ReplaceInNotes
+type ReplaceResult struct {
+	Files        []FileReplacement `json:"files"`         // Sorted by path
+	FilesMatched int               `json:"files_matched"` // Notes with matches, including those beyond MaxFiles
+	Replacements int               `json:"replacements"`  // Replacements made, or planned on a dry run
+	DryRun       bool              `json:"dry_run,omitempty"`
+	Truncated    bool              `json:"truncated,omitempty"` // MaxFiles left matching notes unchanged
+}
+
+// replacer applies one ReplaceOptions pattern to note content
+type replacer struct {
+	re             *regexp.Regexp
+	replacement    string
+	expand         bool // Replacement refers to capture groups
+	preserveCase   bool
+	maxPerFile     int
+	skipCodeBlocks bool
+}
+
+// replaceEdit is one match and the text replacing it
+type replaceEdit struct {
+	start, end int
+	text       string
+}
+
+// ReplaceInNotes replaces a pattern in every note under opts.Path that
+// has one of opts.TagsAny. Each note is rewritten atomically, backed up
+// and cached like an update; a note that cannot be written is reported
+// and the others are still changed.
+func (v *vault) ReplaceInNotes(ctx context.Context, opts ReplaceOptions) (ReplaceResult, error) {
+	r, err := newReplacer(opts)
+	if err != nil {
+		return ReplaceResult{}, err
+	}
+	if opts.MaxFiles <= 0 {
+		opts.MaxFiles = DefaultReplaceMaxFiles
+	}
+	tags := newTagFilter(opts.TagsAny, nil, nil)
+
+	// Find the notes with matches
+	type candidate struct {
+		fullPath, relPath string
+		content           string
+	}
+	var mu sync.Mutex
+	var candidates []candidate
+	match := func(fullPath, relPath string, entry CacheEntry) {
+		if !tags.matches(entry.Tags) || len(r.edits(entry.Content)) == 0 {
+			return
+		}
+		mu.Lock()
+		candidates = append(candidates, candidate{fullPath, relPath, entry.Content})
+		mu.Unlock()
+	}
+	if strings.HasSuffix(strings.ToLower(opts.Path), ".md") {
+		fullPath, err := v.validatePath(opts.Path)
+		if err != nil {
+			return ReplaceResult{}, err
+		}
+		stat, err := statNote(fullPath, opts.Path)
+		if err != nil {
+			return ReplaceResult{}, err
+		}
+		entry, err := v.loadEntry(fullPath, stat.ModTime())
+		if err != nil {
+			return ReplaceResult{}, fmt.Errorf("failed to read file: %w", err)
+		}
+		match(fullPath, v.relPath(fullPath), entry)
+	} else {
+		_, err := v.walkNotes(ctx, ListOptions{Subpath: opts.Path, Recursive: true}, func(file noteFile, entry CacheEntry) bool {
+			match(file.fullPath, file.relPath, entry)
+			return false
+		})
+		if err != nil {
+			return ReplaceResult{}, err
+		}
+	}
+	slices.SortFunc(candidates, func(a, b candidate) int {
+		return strings.Compare(a.relPath, b.relPath)
+	})
+
+	result := ReplaceResult{Files: []FileReplacement{}, FilesMatched: len(candidates), DryRun: opts.DryRun}
+	if len(candidates) > opts.MaxFiles {
+		candidates, result.Truncated = candidates[:opts.MaxFiles], true
+	}
+
+	for _, c := range candidates {
+		var file FileReplacement
+		if opts.DryRun {
+			file = r.plan(c.relPath, c.content)
+			if err := v.checkWritable(c.fullPath); err != nil {
+				file.Status, file.Err = ReplaceSkipped, err
+			} else if err := v.checkLeases(ctx, c.fullPath); err != nil {
+				file.Status, file.Err = ReplaceSkipped, err
+			}
+		} else {
+			file = v.replaceNote(ctx, r, c.fullPath, c.relPath)
+		}
+		if file.Status == ReplaceChanged {
+			result.Replacements += file.Replacements
+		}
+		result.Files = append(result.Files, file)
+	}
+	return result, nil
+}
+
+// replaceNote rewrites one note under its write lock, replacing in its
+// current content rather than the content seen while searching
+func (v *vault) replaceNote(ctx context.Context, r replacer, fullPath, relPath string) FileReplacement {
+	unlock := v.writeLocks.lock(fullPath)
+	defer unlock()
+
+	skipped := func(err error) FileReplacement {
+		return FileReplacement{Path: relPath, Status: ReplaceSkipped, Err: err}
+	}
+	if err := ctx.Err(); err != nil {
+		return skipped(err)
+	}
+	if err := v.checkWritable(fullPath); err != nil {
+		return skipped(err)
+	}
+	if err := v.checkLeases(ctx, fullPath); err != nil {
+		return skipped(err)
+	}
+	if err := v.checkAudit(); err != nil {
+		return skipped(err)
+	}
+	stat, err := os.Stat(fullPath)
+	if err != nil {
+		return skipped(ErrNoteNotFound)
+	}
+	entry, err := v.loadEntry(fullPath, stat.ModTime())
+	if err != nil {
+		return FileReplacement{Path: relPath, Status: ReplaceFailed, Err: fmt.Errorf("failed to read file: %w", err)}
+	}
+
+	file := r.plan(relPath, entry.Content)
+	if file.Replacements == 0 {
+		return skipped(fmt.Errorf("%w: the note changed and no longer matches", ErrRevisionMismatch))
+	}
+	content, err := v.PrepareContent(relPath, r.apply(entry.Content), false)
+	var written AuditEntry
+	if err == nil {
+		written, err = v.writeNote(fullPath, content)
+	}
+	if err != nil {
+		file.Status, file.Err = ReplaceFailed, err
+		return file
+	}
+	file.Revision = contentHash(content)
+	if err := v.record(ctx, written); err != nil {
+		file.Err = err
+	}
+	return file
+}
+
+// newReplacer compiles the pattern of opts
+func newReplacer(opts ReplaceOptions) (replacer, error) {
+	mode, err := ParseReplaceMode(string(opts.Mode))
+	if err != nil {
+		return replacer{}, fmt.Errorf("%w: %s", ErrInvalidPattern, err)
+	}
+	if opts.Pattern == "" {
+		return replacer{}, fmt.Errorf("%w: empty pattern", ErrInvalidPattern)
+	}
+	if opts.PreserveCase && mode != ReplaceLiteral {
+		return replacer{}, fmt.Errorf("%w: case preservation needs the literal match mode", ErrInvalidPattern)
+	}
+
+	pattern := opts.Pattern
+	if mode == ReplaceLiteral {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if opts.IgnoreCase || opts.PreserveCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return replacer{}, newPatternError("pattern", 0, opts.Pattern, err)
+	}
+	return replacer{
+		re:             re,
+		replacement:    opts.Replacement,
+		expand:         mode == ReplaceRegex,
+		preserveCase:   opts.PreserveCase,
+		maxPerFile:     opts.MaxPerFile,
+		skipCodeBlocks: opts.SkipCodeBlocks,
+	}, nil
+}
+
+// edits returns every match in content outside skipped code blocks with
+// its replacement, ignoring MaxPerFile
+func (r replacer) edits(content string) []replaceEdit {
+	var code [][2]int
+	if r.skipCodeBlocks {
+		code = fencedRanges(content)
+	}
+
+	var edits []replaceEdit
+	for _, m := range r.re.FindAllStringSubmatchIndex(content, -1) {
+		if slices.ContainsFunc(code, func(c [2]int) bool { return m[0] < c[1] && m[1] > c[0] }) {
+			continue
+		}
+		var text string
+		switch {
+		case r.expand:
+			text = string(r.re.ExpandString(nil, r.replacement, content, m))
+		case r.preserveCase:
+			text = matchCase(content[m[0]:m[1]], r.replacement)
+		default:
+			text = r.replacement
+		}
+		edits = append(edits, replaceEdit{m[0], m[1], text})
+	}
+	return edits
+}
+
+// capped returns the edits limited to MaxPerFile
+func (r replacer) capped(edits []replaceEdit) []replaceEdit {
+	if r.maxPerFile > 0 && len(edits) > r.maxPerFile {
+		return edits[:r.maxPerFile]
+	}
+	return edits
+}
+
+// apply returns content with the capped edits made
+func (r replacer) apply(content string) string {
+	return applyEdits(content, r.capped(r.edits(content)))
+}
+
+// plan reports the replacements in content with sample lines
+func (r replacer) plan(relPath, content string) FileReplacement {
+	edits := r.edits(content)
+	applied := r.capped(edits)
+	file := FileReplacement{
+		Path:         relPath,
+		Status:       ReplaceChanged,
+		Matches:      len(edits),
+		Replacements: len(applied),
+	}
+
+	// Each sample covers the whole lines touched by overlapping edits
+	for i := 0; i < len(applied) && len(file.Samples) < maxReplaceSamples; {
+		start := strings.LastIndexByte(content[:applied[i].start], '\n') + 1
+		end := lineEnd(content, applied[i].end)
+		j := i + 1
+		for j < len(applied) && applied[j].start < end {
+			end = lineEnd(content, applied[j].end)
+			j++
+		}
+
+		local := make([]replaceEdit, j-i)
+		for k, e := range applied[i:j] {
+			local[k] = replaceEdit{e.start - start, e.end - start, e.text}
+		}
+		file.Samples = append(file.Samples, ReplaceSample{
+			Line:   strings.Count(content[:start], "\n") + 1,
+			Before: content[start:end],
+			After:  applyEdits(content[start:end], local),
+		})
+		i = j
+	}
+	return file
+}
+
+// lineEnd returns the offset of the line break ending the line holding
+// offset, or the end of content
+func lineEnd(content string, offset int) int {
+	if i := strings.IndexByte(content[offset:], '\n'); i >= 0 {
+		return offset + i
+	}
+	return len(content)
+}
+
+// applyEdits returns content with the sorted, disjoint edits made
+func applyEdits(content string, edits []replaceEdit) string {
+	var b strings.Builder
+	last := 0
+	for _, e := range edits {
+		b.WriteString(content[last:e.start])
+		b.WriteString(e.text)
+		last = e.end
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+// fencedRanges returns the byte ranges of the fenced code blocks of
+// content after its frontmatter, delimiters included
+func fencedRanges(content string) [][2]int {
+	skip := 0 // Frontmatter lines
+	if frontmatter, _, ok := SplitFrontmatter(content); ok {
+		skip = strings.Count(frontmatter, "\n") + 2
+	}
+
+	var ranges [][2]int
+	open := -1
+	for offset, lineNum := 0, 1; offset < len(content); lineNum++ {
+		end := lineEnd(content, offset)
+		if lineNum > skip && isFence(content[offset:end]) {
+			if open < 0 {
+				open = offset
+			} else {
+				ranges = append(ranges, [2]int{open, end})
+				open = -1
+			}
+		}
+		offset = end + 1
+	}
+	if open >= 0 {
+		ranges = append(ranges, [2]int{open, len(content)})
+	}
+	return ranges
+}
+
+// matchCase returns replacement in the case of matched: all upper, all
+// lower or capitalized, and unchanged otherwise
+func matchCase(matched, replacement string) string {
+	hasLetter := strings.IndexFunc(matched, unicode.IsLetter) >= 0
+	switch {
+	case !hasLetter:
+		return replacement
+	case strings.ToUpper(matched) == matched:
+		return strings.ToUpper(replacement)
+	case strings.ToLower(matched) == matched:
+		return strings.ToLower(replacement)
+	}
+
+	first := []rune(matched)[0]
+	rest := string([]rune(matched)[1:])
+	if unicode.IsUpper(first) && strings.ToLower(rest) == rest {
+		runes := []rune(strings.ToLower(replacement))
+		if len(runes) > 0 {
+			runes[0] = unicode.ToUpper(runes[0])
+		}
+		return string(runes)
+	}
+	return replacement
+}
@@ -0,0 +1,233 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// setupReplaceVault creates a vault holding notes and returns it with its
+// directory
+func setupReplaceVault(t *testing.T, notes map[string]string, opts ...Option) (Vault, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, notes)
+	v, err := NewVault(tmpDir, opts...)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	return v, tmpDir
+}
+
+func TestReplaceInNotes(t *testing.T) {
+	ctx := context.Background()
+	notes := map[string]string{
+		"a.md":      "Apollo launch\nThe apollo team and APOLLO.\n",
+		"b.md":      "#work\nApollo notes\n```\nApollo in code\n```\n",
+		"keep/c.md": "Apollo is frozen here",
+		"d.md":      "Nothing to see",
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		v, tmpDir := setupReplaceVault(t, notes, WithReadOnlyPaths("keep"))
+		result, err := v.ReplaceInNotes(ctx, ReplaceOptions{Pattern: "Apollo", Replacement: "Gemini", DryRun: true})
+		if err != nil {
+			t.Fatalf("ReplaceInNotes failed: %v", err)
+		}
+		if result.FilesMatched != 3 || result.Replacements != 3 || !result.DryRun {
+			t.Errorf("Result = %+v, want 3 notes and 3 replacements planned", result)
+		}
+		want := []ReplaceSample{{Line: 1, Before: "Apollo launch", After: "Gemini launch"}}
+		if got := result.Files[0]; got.Path != "a.md" || got.Status != ReplaceChanged || !reflect.DeepEqual(got.Samples, want) {
+			t.Errorf("Files[0] = %+v, want a sample of line 1", got)
+		}
+		if got := result.Files[2]; got.Status != ReplaceSkipped || !errors.Is(got.Err, ErrReadOnly) {
+			t.Errorf("Files[2] = %+v, want skipped as read-only", got)
+		}
+		if data, _ := os.ReadFile(filepath.Join(tmpDir, "a.md")); string(data) != notes["a.md"] {
+			t.Errorf("Dry run wrote a.md: %q", data)
+		}
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		v, tmpDir := setupReplaceVault(t, notes, WithReadOnlyPaths("keep"))
+		result, err := v.ReplaceInNotes(ctx, ReplaceOptions{
+			Pattern:        "apollo",
+			Replacement:    "gemini",
+			PreserveCase:   true,
+			SkipCodeBlocks: true,
+		})
+		if err != nil {
+			t.Fatalf("ReplaceInNotes failed: %v", err)
+		}
+
+		statuses := make(map[string]ReplaceStatus)
+		for _, file := range result.Files {
+			statuses[file.Path] = file.Status
+		}
+		want := map[string]ReplaceStatus{"a.md": ReplaceChanged, "b.md": ReplaceChanged, "keep/c.md": ReplaceSkipped}
+		if !reflect.DeepEqual(statuses, want) {
+			t.Errorf("Statuses = %v, want %v", statuses, want)
+		}
+		if result.Replacements != 4 {
+			t.Errorf("Replacements = %d, want 4", result.Replacements)
+		}
+
+		for path, content := range map[string]string{
+			"a.md":      "Gemini launch\nThe gemini team and GEMINI.\n",
+			"b.md":      "#work\nGemini notes\n```\nApollo in code\n```\n",
+			"keep/c.md": notes["keep/c.md"],
+		} {
+			data, err := os.ReadFile(filepath.Join(tmpDir, path))
+			if err != nil || string(data) != content {
+				t.Errorf("%s = %q, want %q", path, data, content)
+			}
+		}
+
+		// The cache serves the new content
+		if content, err := v.Read(ctx, "a.md"); err != nil || content != "Gemini launch\nThe gemini team and GEMINI.\n" {
+			t.Errorf("Read(a.md) = %q, %v", content, err)
+		}
+		if result.Files[0].Revision != contentHash("Gemini launch\nThe gemini team and GEMINI.\n") {
+			t.Errorf("Revision = %q, want the new content hash", result.Files[0].Revision)
+		}
+	})
+
+	t.Run("regex with filters and caps", func(t *testing.T) {
+		v, tmpDir := setupReplaceVault(t, notes)
+		result, err := v.ReplaceInNotes(ctx, ReplaceOptions{
+			Pattern:     `(?i)apollo (\w+)`,
+			Replacement: "${1} of Gemini",
+			Mode:        ReplaceRegex,
+			TagsAny:     []string{"work"},
+		})
+		if err != nil {
+			t.Fatalf("ReplaceInNotes failed: %v", err)
+		}
+		if result.FilesMatched != 1 || result.Files[0].Path != "b.md" || result.Files[0].Matches != 2 {
+			t.Fatalf("Result = %+v, want only b.md with two matches", result)
+		}
+		if data, _ := os.ReadFile(filepath.Join(tmpDir, "b.md")); string(data) != "#work\nnotes of Gemini\n```\nin of Gemini code\n```\n" {
+			t.Errorf("b.md = %q", data)
+		}
+
+		result, err = v.ReplaceInNotes(ctx, ReplaceOptions{Pattern: "apollo", Replacement: "x", IgnoreCase: true, MaxFiles: 1, MaxPerFile: 1})
+		if err != nil {
+			t.Fatalf("ReplaceInNotes failed: %v", err)
+		}
+		if !result.Truncated || result.FilesMatched != 2 || len(result.Files) != 1 || result.Files[0].Replacements != 1 || result.Files[0].Matches != 3 {
+			t.Errorf("Result = %+v, want one note with one of three matches replaced", result)
+		}
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		v, _ := setupReplaceVault(t, notes)
+		for _, opts := range []ReplaceOptions{
+			{Pattern: "(", Mode: ReplaceRegex},
+			{Pattern: ""},
+			{Pattern: "a", Mode: ReplaceRegex, PreserveCase: true},
+			{Pattern: "a", Mode: "glob"},
+		} {
+			if _, err := v.ReplaceInNotes(ctx, opts); !errors.Is(err, ErrInvalidPattern) {
+				t.Errorf("ReplaceInNotes(%+v) error = %v, want ErrInvalidPattern", opts, err)
+			}
+		}
+	})
+}
+
+func TestMatchCase(t *testing.T) {
+	tests := []struct {
+		matched, replacement, want string
+	}{
+		{"foo", "Bar", "bar"},
+		{"Foo", "bar baz", "Bar baz"},
+		{"FOO", "bar", "BAR"},
+		{"fOo", "bar", "bar"},
+		{"42", "Bar", "Bar"},
+		{"Über", "bAR", "Bar"},
+	}
+	for _, tt := range tests {
+		if got := matchCase(tt.matched, tt.replacement); got != tt.want {
+			t.Errorf("matchCase(%q, %q) = %q, want %q", tt.matched, tt.replacement, got, tt.want)
+		}
+	}
+}
+
+func TestCreateFileAtomic(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "note.md")
+	if err := createFileAtomic(path, []byte("new")); err != nil {
+		t.Fatalf("createFileAtomic failed: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new" {
+		t.Errorf("note.md = %q, want new", data)
+	}
+
+	// An existing file is left alone
+	if err := createFileAtomic(path, []byte("other")); !errors.Is(err, os.ErrExist) {
+		t.Errorf("createFileAtomic(existing) error = %v, want os.ErrExist", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new" {
+		t.Errorf("note.md = %q, want it unchanged", data)
+	}
+	if entries, _ := os.ReadDir(tmpDir); len(entries) != 1 {
+		t.Errorf("Directory holds %d entries, want no temporary file left", len(entries))
+	}
+}
+
+func TestCreateNoteCreatedMeanwhile(t *testing.T) {
+	tmpDir := t.TempDir()
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	vlt := v.(*vault)
+	ctx := context.Background()
+
+	fullPath, err := vlt.checkCreate(ctx, "Inbox/new.md")
+	if err != nil {
+		t.Fatalf("checkCreate failed: %v", err)
+	}
+	// Another program creates the note between the check and the write
+	writeFiles(t, tmpDir, map[string]string{"Inbox/new.md": "theirs"})
+
+	var exists *NoteExistsError
+	if err := vlt.createNote(ctx, fullPath, "ours"); !errors.As(err, &exists) || exists.Path != "Inbox/new.md" {
+		t.Fatalf("createNote() error = %v, want NoteExistsError", err)
+	}
+	if data, _ := os.ReadFile(fullPath); string(data) != "theirs" {
+		t.Errorf("Inbox/new.md = %q, want it unchanged", data)
+	}
+}
+
+func TestWriteFileAtomicFollowsSymlinks(t *testing.T) {
+	tmpDir := t.TempDir()
+	target := filepath.Join(tmpDir, "target.md")
+	link := filepath.Join(tmpDir, "link.md")
+	if err := os.WriteFile(target, []byte("old"), 0600); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("Symlinks unsupported: %v", err)
+	}
+
+	if err := writeFileAtomic(link, []byte("new")); err != nil {
+		t.Fatalf("writeFileAtomic failed: %v", err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("Link was replaced: %v", err)
+	}
+	info, err := os.Stat(target)
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Target mode = %v, %v, want 0600", info.Mode(), err)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "new" {
+		t.Errorf("Target = %q, want new", data)
+	}
+	if entries, _ := os.ReadDir(tmpDir); len(entries) != 2 {
+		t.Errorf("Directory holds %d entries, want no temporary file left", len(entries))
+	}
+}
@@ -0,0 +1,161 @@
+package vault
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MatchKind tells how a note name was matched, best first
+type MatchKind string
+
+// Ways a name can match a note, in order of preference
+const (
+	MatchPath     MatchKind = "path"     // Vault-relative path, extension optional
+	MatchFilename MatchKind = "filename" // File name without extension
+	MatchTitle    MatchKind = "title"    // Title from the frontmatter or first heading, see WithTitleSources
+	MatchAlias    MatchKind = "alias"    // Frontmatter alias
+)
+
+// matchRank orders match kinds; lower is better
+var matchRank = map[MatchKind]int{
+	MatchPath:     0,
+	MatchFilename: 1,
+	MatchTitle:    2,
+	MatchAlias:    3,
+}
+
+// NoteMatch is a note found by Resolve
+type NoteMatch struct {
+	Path      string    `json:"path"`       // Vault-relative path of the note
+	MatchedBy MatchKind `json:"matched_by"` // How the name matched
+}
+
+// Resolution is the result of resolving a note name
+type Resolution struct {
+	Match      *NoteMatch  `json:"match,omitempty"` // Best match, nil when ambiguous
+	Candidates []NoteMatch `json:"candidates"`      // Every match, best first
+}
+
+// Ambiguous returns the equally ranked best candidates when the name
+// does not identify a single note, or nil otherwise
+func (r Resolution) Ambiguous() []NoteMatch {
+	if r.Match != nil || len(r.Candidates) == 0 {
+		return nil
+	}
+	best := r.Candidates[0].MatchedBy
+	var tied []NoteMatch
+	for _, c := range r.Candidates {
+		if c.MatchedBy == best {
+			tied = append(tied, c)
+		}
+	}
+	return tied
+}
+
+// normalizeName lowercases a note name or path and drops the .md extension
+func normalizeName(name string) string {
+	name = strings.TrimPrefix(filepath.ToSlash(strings.TrimSpace(name)), "/")
+	name = strings.ToLower(name)
+	return strings.TrimSuffix(name, ".md")
+}
+
+// matchNote returns how name matches the note titled title, if at all
+func matchNote(name, relPath, title string, entry CacheEntry) (MatchKind, bool) {
+	notePath := normalizeName(relPath)
+	if notePath == name {
+		return MatchPath, true
+	}
+	if path.Base(notePath) == name {
+		return MatchFilename, true
+	}
+	if strings.EqualFold(title, name) {
+		return MatchTitle, true
+	}
+	for _, alias := range entry.Aliases {
+		if strings.EqualFold(alias, name) {
+			return MatchAlias, true
+		}
+	}
+	return "", false
+}
+
+// Resolve finds notes by path, file name, title or alias,
+// ignoring case. Candidates come from the cached path listing, walked
+// again only when a directory changed, and titles and aliases from the
+// cache; only notes changed since they were last read are loaded from
+// disk. Returns ErrNoteNotFound when nothing matches.
+func (v *vault) Resolve(ctx context.Context, name string) (Resolution, error) {
+	key := normalizeName(name)
+	if key == "" {
+		return Resolution{}, ErrInvalidPath
+	}
+
+	listed, err := v.noteCandidates(ctx)
+	if err != nil {
+		return Resolution{}, err
+	}
+
+	var candidates []NoteMatch
+	var stale []noteFile // Notes whose metadata is not cached as they are now
+	for _, c := range listed {
+		if err := ctx.Err(); err != nil {
+			return Resolution{}, err
+		}
+		fullPath := filepath.Join(v.basePath, filepath.FromSlash(c.path))
+		info, err := os.Stat(fullPath)
+		if err != nil {
+			continue // Removed since the listing
+		}
+		entry, ok := v.cache.Metadata(fullPath)
+		if !ok || !entry.Mtime.Equal(info.ModTime()) {
+			stale = append(stale, noteFile{fullPath: fullPath, relPath: c.path, info: info})
+			continue
+		}
+		title, _ := v.noteTitle(c.path, entry)
+		if kind, ok := matchNote(key, c.path, title, entry); ok {
+			candidates = append(candidates, NoteMatch{Path: c.path, MatchedBy: kind})
+		}
+	}
+
+	// Load the rest concurrently, which caches them for the next call
+	var mu sync.Mutex
+	_, _, err = v.processNotes(ctx, stale, 0, func(file noteFile, entry CacheEntry) bool {
+		title, _ := v.noteTitle(file.relPath, entry)
+		if kind, ok := matchNote(key, file.relPath, title, entry); ok {
+			mu.Lock()
+			candidates = append(candidates, NoteMatch{Path: file.relPath, MatchedBy: kind})
+			mu.Unlock()
+		}
+		return false
+	})
+	if err != nil {
+		return Resolution{}, err
+	}
+	if len(candidates) == 0 {
+		return Resolution{}, ErrNoteNotFound
+	}
+
+	// Best kind first, then shortest path as Obsidian prefers
+	sort.Slice(candidates, func(i, j int) bool {
+		ri, rj := matchRank[candidates[i].MatchedBy], matchRank[candidates[j].MatchedBy]
+		if ri != rj {
+			return ri < rj
+		}
+		if len(candidates[i].Path) != len(candidates[j].Path) {
+			return len(candidates[i].Path) < len(candidates[j].Path)
+		}
+		return candidates[i].Path < candidates[j].Path
+	})
+
+	res := Resolution{Candidates: candidates}
+	if len(candidates) == 1 || candidates[1].MatchedBy != candidates[0].MatchedBy {
+		res.Match = &candidates[0]
+	}
+
+	return res, nil
+}
@@ -0,0 +1,180 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseFrontmatter(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantTitle   string
+		wantAliases []string
+	}{
+		{
+			name:        "title and alias list",
+			content:     "---\ntitle: Quarterly Planning\naliases:\n  - Q-Plan\n  - planning\n---\nBody",
+			wantTitle:   "Quarterly Planning",
+			wantAliases: []string{"Q-Plan", "planning"},
+		},
+		{
+			name:        "single alias string and legacy key",
+			content:     "---\naliases: One\nalias: [Two]\n---\n",
+			wantAliases: []string{"One", "Two"},
+		},
+		{
+			name:      "CRLF line endings",
+			content:   "---\r\ntitle: Windows\r\n---\r\nBody",
+			wantTitle: "Windows",
+		},
+		{
+			name:    "no frontmatter",
+			content: "# Heading\ntitle: not frontmatter",
+		},
+		{
+			name:    "unterminated block",
+			content: "---\ntitle: Open\nBody",
+		},
+		{
+			name:    "invalid yaml",
+			content: "---\ntitle: [unclosed\n---\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := parseFrontmatter(tt.content)
+			if got := frontmatterTitle(fields); got != tt.wantTitle {
+				t.Errorf("title = %q, want %q", got, tt.wantTitle)
+			}
+			if got := frontmatterAliases(fields); !slices.Equal(got, tt.wantAliases) {
+				t.Errorf("aliases = %v, want %v", got, tt.wantAliases)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"planning.md":           "---\ntitle: Quarterly Planning\naliases: [Q-Plan]\n---\n",
+		"work/index.md":         "Work index",
+		"personal/index.md":     "Personal index",
+		"work/meetings.md":      "---\naliases: [Standup]\n---\n",
+		"work/daily/standup.md": "Daily standup",
+	}
+	writeFiles(t, tmpDir, files)
+
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		input         string
+		wantMatch     string
+		wantKind      MatchKind
+		wantAmbiguous []string
+	}{
+		{"exact path", "work/index.md", "work/index.md", MatchPath, nil},
+		{"path without extension", "Work/Index", "work/index.md", MatchPath, nil},
+		{"root note by name", "PLANNING", "planning.md", MatchPath, nil},
+		{"title", "quarterly planning", "planning.md", MatchTitle, nil},
+		{"alias", "q-plan", "planning.md", MatchAlias, nil},
+		{"filename beats alias", "standup", "work/daily/standup.md", MatchFilename, nil},
+		{"ambiguous filename", "index", "", "", []string{"work/index.md", "personal/index.md"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := v.Resolve(ctx, tt.input)
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+
+			if tt.wantAmbiguous != nil {
+				if res.Match != nil {
+					t.Fatalf("Expected ambiguous result, got match %+v", *res.Match)
+				}
+				var paths []string
+				for _, c := range res.Ambiguous() {
+					paths = append(paths, c.Path)
+				}
+				slices.Sort(paths)
+				slices.Sort(tt.wantAmbiguous)
+				if !slices.Equal(paths, tt.wantAmbiguous) {
+					t.Errorf("Ambiguous() = %v, want %v", paths, tt.wantAmbiguous)
+				}
+				return
+			}
+
+			if res.Match == nil {
+				t.Fatalf("Expected a match, got candidates %+v", res.Candidates)
+			}
+			if res.Match.Path != tt.wantMatch || res.Match.MatchedBy != tt.wantKind {
+				t.Errorf("Match = %+v, want {%s %s}", *res.Match, tt.wantMatch, tt.wantKind)
+			}
+		})
+	}
+
+	t.Run("other candidates are listed", func(t *testing.T) {
+		res, err := v.Resolve(ctx, "standup")
+		if err != nil {
+			t.Fatalf("Resolve() error = %v", err)
+		}
+		if len(res.Candidates) != 2 || res.Candidates[1].Path != "work/meetings.md" {
+			t.Errorf("Candidates = %+v, want standup.md then meetings.md", res.Candidates)
+		}
+	})
+
+	t.Run("uses the cached listing and sees changes", func(t *testing.T) {
+		if v.(*vault).paths.dirs == nil {
+			t.Fatal("Resolve() did not keep the path listing")
+		}
+
+		// An edit in place leaves directory times alone; the note's own time tells
+		fullPath := filepath.Join(tmpDir, "planning.md")
+		if err := os.WriteFile(fullPath, []byte("---\ntitle: Yearly Planning\n---\n"), 0644); err != nil {
+			t.Fatalf("Failed to update file: %v", err)
+		}
+		later := time.Now().Add(time.Minute)
+		if err := os.Chtimes(fullPath, later, later); err != nil {
+			t.Fatalf("Failed to set times: %v", err)
+		}
+		res, err := v.Resolve(ctx, "yearly planning")
+		if err != nil || res.Match == nil || res.Match.Path != "planning.md" {
+			t.Errorf("Resolve(yearly planning) = %+v, %v, want the retitled note", res, err)
+		}
+
+		if err := os.WriteFile(filepath.Join(tmpDir, "work", "review.md"), nil, 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		v.(*vault).paths.invalidate() // Directory times may not have ticked yet
+		res, err = v.Resolve(ctx, "review")
+		if err != nil || res.Match == nil || res.Match.Path != "work/review.md" {
+			t.Errorf("Resolve(review) = %+v, %v, want the new note", res, err)
+		}
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		_, err := v.Resolve(ctx, "nothing here")
+		if !errors.Is(err, ErrNoteNotFound) {
+			t.Errorf("Expected ErrNoteNotFound, got %v", err)
+		}
+	})
+
+	t.Run("empty name", func(t *testing.T) {
+		_, err := v.Resolve(ctx, "  ")
+		if !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("Expected ErrInvalidPath, got %v", err)
+		}
+	})
+}
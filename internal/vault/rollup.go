@@ -0,0 +1,452 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRollupExcerptLength is the length in characters of the excerpt
+// after each note of a rollup
+const DefaultRollupExcerptLength = 120
+
+// Rollup templates used when a template note leaves them out; see
+// RollupOptions.Template
+const (
+	defaultRollupLayout = "# Rollup {from} to {to}\n\n{groups}\n\n{tasks}\n"
+	defaultRollupGroup  = "## {group}"
+	defaultRollupNote   = "- [[{link}]] {excerpt}"
+	defaultRollupTasks  = "## Tasks completed"
+	defaultRollupTask   = "- [x] {task} ([[{link}]])"
+)
+
+// rollupUntagged is the group of notes without tags when grouping by tag
+const rollupUntagged = "Untagged"
+
+// rollupBlankLines matches the blank lines left by empty placeholders
+var rollupBlankLines = regexp.MustCompile(`\n{3,}`)
+
+// taskDoneDate matches the completion date the Tasks plugin adds to a
+// checked task, as in "✅ 2024-03-01"
+var taskDoneDate = regexp.MustCompile(`✅ (\d{4}-\d{2}-\d{2})`)
+
+// RollupGroup selects how GenerateRollup groups the notes
+type RollupGroup string
+
+// Rollup groupings
+const (
+	RollupByFolder RollupGroup = "folder" // Under the folder holding each note
+	RollupByTag    RollupGroup = "tag"    // Under each tag of a note, so a note can appear more than once
+	RollupByDay    RollupGroup = "day"    // Under the day each note was created or modified
+)
+
+// RollupInclude selects what a rollup covers
+type RollupInclude string
+
+// Rollup contents
+const (
+	RollupCreated  RollupInclude = "created"         // Notes created in the period
+	RollupModified RollupInclude = "modified"        // Notes last modified in the period
+	RollupTasks    RollupInclude = "tasks-completed" // Checked tasks completed in the period
+)
+
+// ParseRollupGroup validates a rollup grouping, defaulting to folder
+func ParseRollupGroup(s string) (RollupGroup, error) {
+	switch group := RollupGroup(strings.ToLower(strings.TrimSpace(s))); group {
+	case "":
+		return RollupByFolder, nil
+	case RollupByFolder, RollupByTag, RollupByDay:
+		return group, nil
+	default:
+		return "", fmt.Errorf("unknown grouping %q (want folder, tag or day)", s)
+	}
+}
+
+// ParseRollupInclude validates what a rollup covers
+func ParseRollupInclude(s string) (RollupInclude, error) {
+	switch include := RollupInclude(strings.ToLower(strings.TrimSpace(s))); include {
+	case RollupCreated, RollupModified, RollupTasks:
+		return include, nil
+	default:
+		return "", fmt.Errorf("unknown content %q (want created, modified or tasks-completed)", s)
+	}
+}
+
+// RollupOptions describes a rollup for GenerateRollup
+type RollupOptions struct {
+	From          time.Time       // First day covered; only the date counts
+	To            time.Time       // Last day covered, inclusive; only the date counts
+	Target        string          // Note the rollup is written to
+	Subpath       string          // Directory to look in, empty for the whole vault
+	GroupBy       RollupGroup     // How notes are grouped, by folder when empty
+	Include       []RollupInclude // What the rollup covers, everything when empty
+	Template      string          // Note whose body and rollup_* properties override the templates
+	ExcerptLength int             // Length of each note's excerpt, DefaultRollupExcerptLength when 0; negative for none
+	Append        bool            // Add the rollup to the end of the target instead of replacing it
+	DryRun        bool            // Render the rollup without writing it
+}
+
+// RollupResult reports what GenerateRollup rendered and wrote
+type RollupResult struct {
+	Path     string `json:"path"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Notes    int    `json:"notes"`              // Notes linked from the rollup
+	Tasks    int    `json:"tasks"`              // Completed tasks listed
+	DryRun   bool   `json:"dry_run,omitempty"`  // Nothing was written
+	Content  string `json:"content,omitempty"`  // Rendered rollup, on dry runs
+	Created  bool   `json:"created"`            // The target did not exist yet
+	Revision string `json:"revision,omitempty"` // Content hash of the target after writing
+}
+
+// rollupTemplate holds the templates a rollup is rendered with
+type rollupTemplate struct {
+	layout string // Whole rollup; {from}, {to}, {groups} and {tasks}
+	group  string // Heading of a group; {group}
+	note   string // Line of a note; {link}, {title}, {path}, {date} and {excerpt}
+	tasks  string // Heading of the completed tasks, left out with them when there are none
+	task   string // Line of a task; {task}, {link}, {title}, {path} and {date}
+}
+
+// rollupNote is a note in a rollup, under the day it counts on
+type rollupNote struct {
+	path    string
+	day     string
+	tags    []string
+	excerpt string
+}
+
+// rollupTask is a completed task in a rollup
+type rollupTask struct {
+	path string
+	day  string
+	line int
+	text string
+}
+
+// GenerateRollup renders a summary of the notes under opts.Subpath created
+// or last modified from opts.From to opts.To, grouped by folder, tag or day, with a link
+// and an excerpt for each, and of the tasks checked in the period. Tasks
+// count on their ✅ completion date when they have one and otherwise on
+// the day their note was last modified. The rollup replaces the target
+// note, or is appended to it, creating it when missing. Creation dates
+// come from frontmatter, birth time or mtime as for sorting by creation.
+// Notes and groups are in a fixed order, so the same vault renders the
+// same rollup.
+func (v *vault) GenerateRollup(ctx context.Context, opts RollupOptions) (RollupResult, error) {
+	group, err := ParseRollupGroup(string(opts.GroupBy))
+	if err != nil {
+		return RollupResult{}, fmt.Errorf("%w: %w", ErrInvalidRollup, err)
+	}
+	include := map[RollupInclude]bool{RollupCreated: len(opts.Include) == 0, RollupModified: len(opts.Include) == 0, RollupTasks: len(opts.Include) == 0}
+	for _, s := range opts.Include {
+		kind, err := ParseRollupInclude(string(s))
+		if err != nil {
+			return RollupResult{}, fmt.Errorf("%w: %w", ErrInvalidRollup, err)
+		}
+		include[kind] = true
+	}
+	from, to := opts.From.Format(activityDateLayout), opts.To.Format(activityDateLayout)
+	if from > to {
+		return RollupResult{}, fmt.Errorf("%w: from %s is after to %s", ErrInvalidRollup, from, to)
+	}
+	excerptLength := opts.ExcerptLength
+	if excerptLength == 0 {
+		excerptLength = DefaultRollupExcerptLength
+	}
+
+	fullPath, err := v.validatePath(opts.Target)
+	if err != nil {
+		return RollupResult{}, err
+	}
+	relPath := v.relPath(fullPath)
+	tmpl, templatePath, err := v.rollupTemplate(opts.Template)
+	if err != nil {
+		return RollupResult{}, err
+	}
+
+	// Collect the notes and tasks in the period, leaving out the rollup
+	// itself and its template
+	inRange := func(day string) bool { return day >= from && day <= to }
+	var mu sync.Mutex
+	var notes []rollupNote
+	var tasks []rollupTask
+	_, err = v.walkNotes(ctx, ListOptions{Subpath: opts.Subpath, Recursive: true}, func(file noteFile, entry CacheEntry) bool {
+		if file.relPath == relPath || file.relPath == templatePath {
+			return false
+		}
+		mtime := file.info.ModTime()
+		modified := mtime.Format(activityDateLayout)
+
+		var noteTasks []rollupTask
+		if include[RollupTasks] {
+			for _, task := range entry.Tasks {
+				if !task.Done {
+					continue
+				}
+				day := modified
+				if m := taskDoneDate.FindStringSubmatch(task.Text); m != nil {
+					day = m[1]
+				}
+				if inRange(day) {
+					noteTasks = append(noteTasks, rollupTask{path: file.relPath, day: day, line: task.Line, text: task.Text})
+				}
+			}
+		}
+
+		// A note created in the period counts on its creation day
+		day := ""
+		if include[RollupCreated] {
+			if created := v.resolveCreated(file.fullPath, entry.Properties, mtime).Format(activityDateLayout); inRange(created) {
+				day = created
+			}
+		}
+		if day == "" && include[RollupModified] && inRange(modified) {
+			day = modified
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		tasks = append(tasks, noteTasks...)
+		if day != "" {
+			notes = append(notes, rollupNote{path: file.relPath, day: day, tags: entry.Tags, excerpt: rollupExcerpt(entry.Content, excerptLength)})
+		}
+		return false // Only the collected notes are needed
+	})
+	if err != nil {
+		return RollupResult{}, err
+	}
+
+	index, err := v.buildFileIndex(ctx)
+	if err != nil {
+		return RollupResult{}, err
+	}
+	rendered := tmpl.render(from, to, groupRollupNotes(notes, group), tasks, func(p string) string {
+		return index.linkTo(relPath, p, false)
+	})
+
+	result := RollupResult{Path: relPath, From: from, To: to, Notes: len(notes), Tasks: len(tasks)}
+	if opts.DryRun {
+		_, err := os.Stat(fullPath)
+		result.DryRun, result.Content, result.Created = true, rendered, os.IsNotExist(err)
+		return result, nil
+	}
+
+	unlock := v.writeLocks.lock(fullPath)
+	defer unlock()
+
+	var current string
+	if _, err := os.Stat(fullPath); os.IsNotExist(err) {
+		if _, err := v.checkCreate(ctx, relPath); err != nil {
+			return RollupResult{}, err
+		}
+		result.Created = true
+	} else {
+		if _, err := v.checkUpdate(ctx, relPath); err != nil {
+			return RollupResult{}, err
+		}
+		stat, err := os.Stat(fullPath)
+		if err != nil {
+			return RollupResult{}, ErrNoteNotFound
+		}
+		entry, err := v.loadEntry(fullPath, stat.ModTime())
+		if err != nil {
+			return RollupResult{}, fmt.Errorf("failed to read file: %w", err)
+		}
+		current = entry.Content
+	}
+
+	raw := rendered
+	if opts.Append && strings.TrimSpace(current) != "" {
+		raw = strings.TrimRight(current, "\n") + "\n\n" + rendered
+	}
+	content, err := v.PrepareContent(relPath, raw, result.Created)
+	if err != nil {
+		return RollupResult{}, err
+	}
+	result.Revision = contentHash(content)
+
+	// Check context cancellation before I/O; once writing starts it completes
+	if err := ctx.Err(); err != nil {
+		return RollupResult{}, err
+	}
+	if result.Created {
+		return result, v.createNote(ctx, fullPath, content)
+	}
+	written, err := v.writeNote(fullPath, content)
+	if err != nil {
+		return RollupResult{}, err
+	}
+	return result, v.record(ctx, written)
+}
+
+// rollupTemplate returns the templates of the note at notePath and its
+// vault-relative path, or the defaults when notePath is empty. The note's
+// body replaces the layout unless blank; its rollup_group, rollup_note,
+// rollup_tasks and rollup_task properties replace the other templates.
+func (v *vault) rollupTemplate(notePath string) (rollupTemplate, string, error) {
+	tmpl := rollupTemplate{
+		layout: defaultRollupLayout,
+		group:  defaultRollupGroup,
+		note:   defaultRollupNote,
+		tasks:  defaultRollupTasks,
+		task:   defaultRollupTask,
+	}
+	if notePath == "" {
+		return tmpl, "", nil
+	}
+
+	fullPath, err := v.validatePath(notePath)
+	if err != nil {
+		return rollupTemplate{}, "", err
+	}
+	stat, err := os.Stat(fullPath)
+	if err != nil {
+		return rollupTemplate{}, "", ErrNoteNotFound
+	}
+	entry, err := v.loadEntry(fullPath, stat.ModTime())
+	if err != nil {
+		return rollupTemplate{}, "", fmt.Errorf("failed to read file: %w", err)
+	}
+	relPath := v.relPath(fullPath)
+
+	if _, body, _ := SplitFrontmatter(entry.Content); strings.TrimSpace(body) != "" {
+		tmpl.layout = body
+	}
+	for key, field := range map[string]*string{"rollup_group": &tmpl.group, "rollup_note": &tmpl.note, "rollup_tasks": &tmpl.tasks, "rollup_task": &tmpl.task} {
+		value, ok := entry.Properties[key]
+		if !ok || value == nil {
+			continue
+		}
+		text, ok := value.(string)
+		if !ok {
+			return rollupTemplate{}, "", fmt.Errorf("%w: %s in template %s is not text", ErrInvalidRollup, key, relPath)
+		}
+		*field = text
+	}
+	return tmpl, relPath, nil
+}
+
+// rollupGroup is a group heading of a rollup and its notes
+type rollupGroup struct {
+	name  string
+	notes []rollupNote
+}
+
+// groupRollupNotes sorts notes into groups by folder, tag or day, with
+// the groups in order of name and the notes in order of path. The vault
+// root is the folder "/"; notes without tags go in a last group of their
+// own.
+func groupRollupNotes(notes []rollupNote, by RollupGroup) []rollupGroup {
+	byName := make(map[string][]rollupNote)
+	for _, note := range notes {
+		switch by {
+		case RollupByTag:
+			if len(note.tags) == 0 {
+				byName[rollupUntagged] = append(byName[rollupUntagged], note)
+			}
+			for _, tag := range note.tags {
+				byName["#"+tag] = append(byName["#"+tag], note)
+			}
+		case RollupByDay:
+			byName[note.day] = append(byName[note.day], note)
+		default:
+			dir := path.Dir(note.path)
+			if dir == "." {
+				dir = "/"
+			}
+			byName[dir] = append(byName[dir], note)
+		}
+	}
+
+	groups := make([]rollupGroup, 0, len(byName))
+	for _, name := range slices.Sorted(maps.Keys(byName)) {
+		if name == rollupUntagged && by == RollupByTag {
+			continue
+		}
+		groups = append(groups, rollupGroup{name: name, notes: byName[name]})
+	}
+	if untagged := byName[rollupUntagged]; by == RollupByTag && len(untagged) > 0 {
+		groups = append(groups, rollupGroup{name: rollupUntagged, notes: untagged})
+	}
+	for _, g := range groups {
+		slices.SortFunc(g.notes, func(a, b rollupNote) int { return strings.Compare(a.path, b.path) })
+	}
+	return groups
+}
+
+// render fills the templates with the groups and tasks of a rollup from
+// from to to, with link giving the link text of a note's path
+func (t rollupTemplate) render(from, to string, groups []rollupGroup, tasks []rollupTask, link func(string) string) string {
+	line := func(template string, fields ...string) string {
+		return strings.TrimRight(strings.NewReplacer(fields...).Replace(template), " \t")
+	}
+
+	var groupText strings.Builder
+	for i, g := range groups {
+		if i > 0 {
+			groupText.WriteString("\n")
+		}
+		groupText.WriteString(line(t.group, "{group}", g.name) + "\n\n")
+		for _, note := range g.notes {
+			groupText.WriteString(line(t.note,
+				"{link}", link(note.path),
+				"{title}", noteName(note.path),
+				"{path}", note.path,
+				"{date}", note.day,
+				"{excerpt}", note.excerpt,
+			) + "\n")
+		}
+	}
+
+	var taskText strings.Builder
+	if len(tasks) > 0 {
+		slices.SortFunc(tasks, func(a, b rollupTask) int {
+			if c := strings.Compare(a.path, b.path); c != 0 {
+				return c
+			}
+			return a.line - b.line
+		})
+		taskText.WriteString(line(t.tasks) + "\n\n")
+		for _, task := range tasks {
+			taskText.WriteString(line(t.task,
+				"{task}", task.text,
+				"{link}", link(task.path),
+				"{title}", noteName(task.path),
+				"{path}", task.path,
+				"{date}", task.day,
+			) + "\n")
+		}
+	}
+
+	content := strings.NewReplacer(
+		"{from}", from,
+		"{to}", to,
+		"{groups}", strings.TrimSuffix(groupText.String(), "\n"),
+		"{tasks}", strings.TrimSuffix(taskText.String(), "\n"),
+	).Replace(t.layout)
+	return strings.TrimSpace(rollupBlankLines.ReplaceAllString(content, "\n\n")) + "\n"
+}
+
+// rollupExcerpt returns the start of a note's first paragraph on one line,
+// leaving out headings, or "" when length is negative
+func rollupExcerpt(content string, length int) string {
+	if length < 0 {
+		return ""
+	}
+	_, body, _ := SplitFrontmatter(content)
+	var text strings.Builder
+	for line := range strings.Lines(body) {
+		if !isHeadingLine(strings.TrimRight(line, " \t\r\n")) {
+			text.WriteString(line)
+		}
+	}
+	return strings.Join(strings.Fields(excerpt(text.String(), length)), " ")
+}
@@ -0,0 +1,110 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGenerateRollup(t *testing.T) {
+	tmpDir := t.TempDir()
+	day := func(date string) time.Time {
+		t, _ := time.ParseInLocation(activityDateLayout+" 15:04", date+" 12:00", time.Local)
+		return t
+	}
+	notes := []struct {
+		path, content string
+		mtime         time.Time
+	}{
+		{"Projects/Alpha.md", "---\ncreated: 2024-03-04\n---\n# Alpha\n\nShip the first release. #work\n\n- [x] Write spec\n- [ ] Review\n", day("2024-03-05")},
+		{"Daily.md", "---\ncreated: 2024-02-01\n---\nBusy day.\n\n- [x] Old task ✅ 2024-02-20\n- [x] Call Bob ✅ 2024-03-07\n", day("2024-03-06")},
+		{"Projects/Old.md", "---\ncreated: 2024-01-01\n---\n- [x] Filed taxes ✅ 2024-03-08\n", day("2024-03-20")}, // Only its task is in the week
+		{"Rollups/Week.md", "# Old\n", day("2024-03-06")}, // The rollup leaves itself out
+		{"Templates/Rollup.md", "---\nrollup_note: \"* {title} ({date})\"\n---\nWeek {from}\n{groups}\n", day("2024-01-01")},
+		{"Templates/Bad.md", "---\nrollup_task: 3\n---\n", day("2024-01-01")},
+	}
+	for _, n := range notes {
+		writeFiles(t, tmpDir, map[string]string{n.path: n.content})
+		fullPath := filepath.Join(tmpDir, n.path)
+		if err := os.Chtimes(fullPath, n.mtime, n.mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	ctx := context.Background()
+	week := RollupOptions{From: day("2024-03-04"), To: day("2024-03-10"), Target: "Rollups/Week.md", DryRun: true}
+
+	tests := []struct {
+		name string
+		opts func(RollupOptions) RollupOptions
+		want string
+	}{
+		{"by folder", func(o RollupOptions) RollupOptions { return o }, "# Rollup 2024-03-04 to 2024-03-10\n\n" +
+			"## /\n\n- [[Daily]] Busy day.\n\n## Projects\n\n- [[Alpha]] Ship the first release. #work\n\n" +
+			"## Tasks completed\n\n- [x] Call Bob ✅ 2024-03-07 ([[Daily]])\n- [x] Write spec ([[Alpha]])\n- [x] Filed taxes ✅ 2024-03-08 ([[Old]])\n"},
+		{"by tag", func(o RollupOptions) RollupOptions {
+			o.GroupBy, o.Include, o.ExcerptLength = RollupByTag, []RollupInclude{RollupCreated, RollupModified}, -1
+			return o
+		}, "# Rollup 2024-03-04 to 2024-03-10\n\n## #work\n\n- [[Alpha]]\n\n## Untagged\n\n- [[Daily]]\n"},
+		{"by day", func(o RollupOptions) RollupOptions {
+			o.GroupBy, o.Include, o.ExcerptLength = RollupByDay, []RollupInclude{RollupCreated, RollupModified}, -1
+			return o
+		}, "# Rollup 2024-03-04 to 2024-03-10\n\n## 2024-03-04\n\n- [[Alpha]]\n\n## 2024-03-06\n\n- [[Daily]]\n"},
+		{"template", func(o RollupOptions) RollupOptions {
+			o.Template, o.Include = "Templates/Rollup.md", []RollupInclude{RollupModified}
+			return o
+		}, "Week 2024-03-04\n## /\n\n* Daily (2024-03-06)\n\n## Projects\n\n* Alpha (2024-03-05)\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := v.GenerateRollup(ctx, tt.opts(week))
+			if err != nil {
+				t.Fatalf("GenerateRollup() error = %v", err)
+			}
+			if result.Content != tt.want {
+				t.Errorf("GenerateRollup() content =\n%s\nwant\n%s", result.Content, tt.want)
+			}
+		})
+	}
+
+	if _, err := v.GenerateRollup(ctx, RollupOptions{From: week.From, To: week.To, Target: week.Target, Template: "Templates/Bad.md"}); !errors.Is(err, ErrInvalidRollup) {
+		t.Errorf("GenerateRollup() with a bad template error = %v, want ErrInvalidRollup", err)
+	}
+	if _, err := v.GenerateRollup(ctx, RollupOptions{From: week.To, To: week.From, Target: week.Target}); !errors.Is(err, ErrInvalidRollup) {
+		t.Errorf("GenerateRollup() with from after to error = %v, want ErrInvalidRollup", err)
+	}
+	if content, _ := v.Read(ctx, week.Target); content != "# Old\n" {
+		t.Fatalf("Dry runs changed the rollup note to %q", content)
+	}
+
+	// Appending keeps the note's content; replacing it does not
+	opts := week
+	opts.DryRun, opts.Append, opts.Include = false, true, []RollupInclude{RollupTasks}
+	result, err := v.GenerateRollup(ctx, opts)
+	if err != nil {
+		t.Fatalf("GenerateRollup() error = %v", err)
+	}
+	rendered := "# Rollup 2024-03-04 to 2024-03-10\n\n## Tasks completed\n\n- [x] Call Bob ✅ 2024-03-07 ([[Daily]])\n- [x] Write spec ([[Alpha]])\n- [x] Filed taxes ✅ 2024-03-08 ([[Old]])\n"
+	content, _ := v.Read(ctx, week.Target)
+	if content != "# Old\n\n"+rendered || result.Created || result.Tasks != 3 || result.Revision != contentHash(content) {
+		t.Errorf("GenerateRollup() appended = %+v, note holds %q", result, content)
+	}
+	opts.Append = false
+	if _, err := v.GenerateRollup(ctx, opts); err != nil {
+		t.Fatalf("GenerateRollup() error = %v", err)
+	}
+	if content, _ := v.Read(ctx, week.Target); content != rendered {
+		t.Errorf("GenerateRollup() replaced note holds %q, want %q", content, rendered)
+	}
+
+	opts.Target = "Rollups/New.md"
+	if result, err := v.GenerateRollup(ctx, opts); err != nil || !result.Created {
+		t.Errorf("GenerateRollup() of a new note = %+v, %v", result, err)
+	}
+}
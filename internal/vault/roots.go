@@ -0,0 +1,121 @@
+package vault
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// WithRoot limits the vault to the folder root, relative to the vault
+// directory, as if root were the vault: paths taken and returned are
+// relative to it, and notes outside it cannot be read, written, found or
+// linked to. Unlike the roots a client declares, this is the server's
+// boundary. The Obsidian settings and excluded files are still read from
+// the vault directory, and write policy globs match paths from it, while
+// the server's data directory is in root.
+// NewVault fails when root is not a folder of the vault.
+func WithRoot(root string) Option {
+	return func(v *vault) {
+		v.root = root
+	}
+}
+
+// enterRoot makes the folder set with WithRoot the base of the vault
+func (v *vault) enterRoot() error {
+	cleaned, err := cleanVaultPath(v.root)
+	if err != nil {
+		return fmt.Errorf("root: %w", err)
+	}
+	if cleaned == "." {
+		v.root = ""
+		return nil
+	}
+
+	fullPath := filepath.Join(v.basePath, filepath.FromSlash(cleaned))
+	if v.isDataPath(fullPath) {
+		return fmt.Errorf("root: %w: %s", ErrReservedPath, cleaned)
+	}
+	resolved, err := filepath.EvalSymlinks(fullPath)
+	if err != nil {
+		return fmt.Errorf("root: %w: %s", ErrDirectoryNotFound, cleaned)
+	}
+	if !v.withinBase(resolved) {
+		return fmt.Errorf("root: %w: %s", ErrPathTraversal, cleaned)
+	}
+	if stat, err := os.Stat(resolved); err != nil || !stat.IsDir() {
+		return fmt.Errorf("root: %w: %s", ErrDirectoryNotFound, cleaned)
+	}
+	v.root, v.basePath = cleaned, resolved
+	return nil
+}
+
+// inRoot returns the path relative to the root of p, relative to the
+// vault directory, and whether p is inside the root
+func (v *vault) inRoot(p string) (string, bool) {
+	if v.root == "" {
+		return p, true
+	}
+	if !InFolder(p, v.root) {
+		return "", false
+	}
+	rel := strings.TrimPrefix(strings.TrimPrefix(path.Clean(p), v.root), "/")
+	return rel, true
+}
+
+// RootFolders returns the vault folders inside dirs, absolute directories
+// such as the roots an MCP client declares. A directory holding the whole
+// vault yields "" and directories outside the vault are left out.
+// Symlinks are resolved on both sides, and folders inside another
+// returned folder are dropped.
+func (v *vault) RootFolders(dirs []string) []string {
+	var folders []string
+	for _, dir := range dirs {
+		if !filepath.IsAbs(dir) {
+			continue
+		}
+		resolved, err := resolveSymlinks(filepath.Clean(dir))
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case isWithin(v.basePath, resolved):
+			return []string{""}
+		case v.withinBase(resolved):
+			rel, err := filepath.Rel(v.basePath, resolved)
+			if err != nil {
+				continue
+			}
+			folders = append(folders, filepath.ToSlash(rel))
+		}
+	}
+	return outermostFolders(folders)
+}
+
+// outermostFolders sorts folders and removes duplicates and folders
+// inside another one of them
+func outermostFolders(folders []string) []string {
+	slices.Sort(folders)
+	var outer []string
+	for _, folder := range folders {
+		if slices.ContainsFunc(outer, func(o string) bool { return InFolder(folder, o) }) {
+			continue
+		}
+		outer = append(outer, folder)
+	}
+	return outer
+}
+
+// InFolder reports whether the vault-relative path p is folder or located
+// inside it. Paths are cleaned first, and the folder "" holds every path.
+func InFolder(p, folder string) bool {
+	p = path.Clean(strings.ReplaceAll(p, `\`, "/"))
+	if folder == "" || folder == rootFolder {
+		return true
+	}
+	folder = path.Clean(folder)
+	return p == folder || strings.HasPrefix(p, folder+"/")
+}
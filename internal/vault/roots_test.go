@@ -0,0 +1,181 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRootFolders(t *testing.T) {
+	parent := t.TempDir()
+	base := filepath.Join(parent, "vault")
+	for _, dir := range []string{"Work/Projects", "Work b", "Personal"} {
+		if err := os.MkdirAll(filepath.Join(base, dir), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+	}
+	outside := t.TempDir()
+	link := filepath.Join(outside, "work-link")
+	if err := os.Symlink(filepath.Join(base, "Work"), link); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	v, err := NewVault(base)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		dirs []string
+		want []string
+	}{
+		{"folder inside the vault", []string{filepath.Join(base, "Work")}, []string{"Work"}},
+		{"vault itself", []string{base}, []string{""}},
+		{"parent of the vault", []string{parent, filepath.Join(base, "Work")}, []string{""}},
+		{"outside the vault", []string{outside}, nil},
+		{"sibling sharing a prefix", []string{base + "-old"}, nil},
+		{"relative directory", []string{"Work"}, nil},
+		{"nested and duplicate folders", []string{
+			filepath.Join(base, "Work/Projects"),
+			filepath.Join(base, "Personal"),
+			filepath.Join(base, "Work b"),
+			filepath.Join(base, "Work"),
+			filepath.Join(base, "Work") + "/",
+		}, []string{"Personal", "Work", "Work b"}},
+		{"symlink into the vault", []string{link}, []string{"Work"}},
+		{"missing folder", []string{filepath.Join(base, "Later")}, []string{"Later"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := v.RootFolders(tt.dirs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RootFolders(%v) = %q, want %q", tt.dirs, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInFolder(t *testing.T) {
+	tests := []struct {
+		path, folder string
+		want         bool
+	}{
+		{"Work/a.md", "Work", true},
+		{"Work", "Work", true},
+		{"Work/Projects/a.md", "Work", true},
+		{"Workshop/a.md", "Work", false},
+		{"a.md", "Work", false},
+		{"Work/../Personal/a.md", "Work", false},
+		{"./Work/a.md", "Work/", true},
+		{`Work\a.md`, "Work", true},
+		{"a.md", "", true},
+		{"a.md", "/", true},
+	}
+	for _, tt := range tests {
+		if got := InFolder(tt.path, tt.folder); got != tt.want {
+			t.Errorf("InFolder(%q, %q) = %v, want %v", tt.path, tt.folder, got, tt.want)
+		}
+	}
+}
+
+func TestWithRoot(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		".obsidian/app.json":   `{"userIgnoreFilters": ["Work/Archive/", "Old/"], "attachmentFolderPath": "Work/Assets"}`,
+		"Work/Plan.md":         "See [[Outside]], [[Task]] and [the budget](../Budget.md)\n",
+		"Work/Tasks/Task.md":   "# Task\n",
+		"Work/Archive/Done.md": "Done\n",
+		"Work/Old/Kept.md":     "Only the vault's Old/ is excluded\n",
+		"Outside.md":           "Links [[Task]]\n",
+		"Budget.md":            "Numbers\n",
+	})
+	if err := os.Symlink(filepath.Join(tmpDir, "Outside.md"), filepath.Join(tmpDir, "Work", "Escape.md")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	v, err := NewVault(tmpDir, WithRoot("./Work/"))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+
+	// Paths are relative to the root, and the vault's filters still apply
+	notes, err := v.List(ctx, ListOptions{Recursive: true})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	var paths []string
+	for _, note := range notes {
+		paths = append(paths, note.Path)
+	}
+	slices.Sort(paths)
+	if want := []string{"Old/Kept.md", "Plan.md", "Tasks/Task.md"}; !slices.Equal(paths, want) {
+		t.Errorf("List() = %q, want %q", paths, want)
+	}
+
+	for _, p := range []string{"../Outside.md", "Escape.md", "../Work/Plan.md"} {
+		if _, err := v.Read(ctx, p); !errors.Is(err, ErrPathTraversal) {
+			t.Errorf("Read(%q) error = %v, want ErrPathTraversal", p, err)
+		}
+	}
+	if _, err := v.Resolve(ctx, "Outside"); !errors.Is(err, ErrNoteNotFound) {
+		t.Errorf("Resolve(Outside) error = %v, want ErrNoteNotFound", err)
+	}
+
+	// Links out of the root do not resolve
+	report, err := v.Verify(ctx, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	var broken []string
+	for _, problem := range report.Problems {
+		if problem.Kind == ProblemBrokenLink {
+			broken = append(broken, problem.Message)
+		}
+	}
+	if len(broken) != 2 || !strings.Contains(strings.Join(broken, "\n"), "Outside") || !strings.Contains(strings.Join(broken, "\n"), "Budget") {
+		t.Errorf("broken links = %q, want Outside and Budget", broken)
+	}
+
+	// Created notes and the data directory are inside the root
+	if err := v.Create(ctx, "New.md", "New"); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "Work", "New.md")); err != nil {
+		t.Errorf("New.md not created in the root: %v", err)
+	}
+	if err := v.SetAnnotation(ctx, "New.md", "summary", "new"); err != nil {
+		t.Fatalf("SetAnnotation() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "Work", dataDir, annotationsFile)); err != nil {
+		t.Errorf("annotations not kept in the root: %v", err)
+	}
+
+	info, err := v.Info(ctx)
+	if err != nil {
+		t.Fatalf("Info() error = %v", err)
+	}
+	if info.Name != filepath.Base(tmpDir) || info.Features.Root != "Work" || info.Features.AttachmentFolder != "Assets" {
+		t.Errorf("Info() = %q, root %q, attachments %q", info.Name, info.Features.Root, info.Features.AttachmentFolder)
+	}
+
+	for _, root := range []string{"Missing", "Plan.md", "../elsewhere", "/abs", ".mcp-notes"} {
+		if err := os.MkdirAll(filepath.Join(tmpDir, dataDir), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewVault(tmpDir, WithRoot(root)); err == nil {
+			t.Errorf("NewVault(WithRoot(%q)) succeeded, want an error", root)
+		}
+	}
+	if v, err := NewVault(tmpDir, WithRoot(".")); err != nil {
+		t.Errorf("NewVault(WithRoot(.)) error = %v", err)
+	} else if info, _ := v.Info(ctx); info.Features.Root != "" {
+		t.Errorf("root = %q, want the whole vault", info.Features.Root)
+	}
+}
@@ -0,0 +1,109 @@
+package vault
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// windowsInvalidChars are the characters Windows forbids in file names
+const windowsInvalidChars = `<>:"|?*`
+
+// nameChars are removed from headings and titles made into file names on
+// top of the sanitization rules, as they would make the file name
+// unlinkable or add folders
+const nameChars = `#^[]|/\`
+
+// maxNameRunes is the length headings and titles made into file names are
+// cut to, keeping the names portable
+const maxNameRunes = 100
+
+// windowsReservedNames are device names Windows reserves regardless of
+// extension, compared case-insensitively
+var windowsReservedNames = map[string]bool{
+	"con": true, "prn": true, "aux": true, "nul": true,
+	"com1": true, "com2": true, "com3": true, "com4": true, "com5": true,
+	"com6": true, "com7": true, "com8": true, "com9": true,
+	"lpt1": true, "lpt2": true, "lpt3": true, "lpt4": true, "lpt5": true,
+	"lpt6": true, "lpt7": true, "lpt8": true, "lpt9": true,
+}
+
+// SanitizePath turns a model-supplied note path into one that is valid on
+// every platform Obsidian runs on: unicode is normalized to NFC, both /
+// and \ separate folders, characters invalid on Windows and control
+// characters are removed, whitespace runs collapse to one space and
+// leading or trailing spaces and trailing dots are trimmed from every
+// component. Paths that cannot be repaired, such as a component left
+// empty or a Windows device name like CON.md, fail with ErrInvalidPath.
+// "." and ".." are kept so traversal is still rejected by validation.
+func SanitizePath(path string) (string, error) {
+	path = norm.NFC.String(strings.ReplaceAll(path, `\`, "/"))
+
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range parts {
+		if part == "." || part == ".." {
+			continue
+		}
+
+		// The extension is kept apart so "Draft?.md" trims to "Draft.md"
+		name, ext := part, ""
+		if i == len(parts)-1 {
+			if dot := strings.LastIndex(part, "."); dot > 0 {
+				name, ext = part[:dot], part[dot:]
+			}
+		}
+
+		name = sanitizeComponent(name)
+		if name == "" {
+			return "", fmt.Errorf("%w: %q has an empty file or folder name after removing invalid characters", ErrInvalidPath, path)
+		}
+
+		base, _, _ := strings.Cut(name, ".")
+		if windowsReservedNames[strings.ToLower(strings.TrimRight(base, " "))] {
+			return "", fmt.Errorf("%w: %q is a reserved device name on Windows", ErrInvalidPath, name+ext)
+		}
+
+		parts[i] = name + sanitizeComponent(ext)
+	}
+
+	return strings.Join(parts, "/"), nil
+}
+
+// sanitizeComponent cleans a single file or folder name
+func sanitizeComponent(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(windowsInvalidChars, r) {
+			return -1
+		}
+		if unicode.IsSpace(r) {
+			return ' '
+		}
+		return r
+	}, name)
+
+	name = strings.Join(strings.Fields(name), " ")
+	return strings.TrimRight(name, ". ")
+}
+
+// linkableName makes text, such as a heading or a title, safe as a file
+// name without extension, returning "" when nothing usable is left of it
+func linkableName(text string) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(nameChars, r) {
+			return ' '
+		}
+		return r
+	}, norm.NFC.String(text))
+	name = strings.TrimLeft(sanitizeComponent(name), ". ")
+	if runes := []rune(name); len(runes) > maxNameRunes {
+		name = sanitizeComponent(string(runes[:maxNameRunes]))
+	}
+
+	base, _, _ := strings.Cut(name, ".")
+	if windowsReservedNames[strings.ToLower(strings.TrimRight(base, " "))] {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,69 @@
+package vault
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSanitizePath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{"unchanged", "projects/plan.md", "projects/plan.md", false},
+		{"invalid characters", "Projects/Q3 Plan: Draft?.md", "Projects/Q3 Plan Draft.md", false},
+		{"all windows characters", `a<b>c:d"e|f?g*h.md`, "abcdefgh.md", false},
+		{"control characters", "to\x00do\x1f.md", "todo.md", false},
+		{"collapse whitespace", "My   Notes/\tweekly  review .md", "My Notes/weekly review.md", false},
+		{"trailing dots and spaces", "Archive. /notes...md", "Archive/notes.md", false},
+		{"backslashes", `Daily\2024\01.md`, "Daily/2024/01.md", false},
+		{"surrounding slashes", "/inbox/idea.md/", "inbox/idea.md", false},
+		{"hidden note kept", ".drafts/.idea.md", ".drafts/.idea.md", false},
+		{"traversal left for validation", "../outside.md", "../outside.md", false},
+
+		// Unicode normalization: decomposed e + combining acute becomes é
+		{"nfd to nfc", "cafe\u0301.md", "caf\u00e9.md", false},
+		{"nfc unchanged", "café.md", "café.md", false},
+		{"nfd folder", "Re\u0301sume\u0301/cv.md", "R\u00e9sum\u00e9/cv.md", false},
+		{"non-latin", "日本語/メモ?.md", "日本語/メモ.md", false},
+
+		// Windows reserved device names
+		{"reserved name", "CON.md", "", true},
+		{"reserved lowercase", "notes/nul.md", "", true},
+		{"reserved with extra extension", "com1.txt.md", "", true},
+		{"reserved folder", "aux/idea.md", "", true},
+		{"reserved after sanitizing", "LPT1 ?.md", "", true},
+		{"reserved prefix is fine", "console.md", "console.md", false},
+		{"com0 is fine", "COM0.md", "COM0.md", false},
+
+		// Nothing left
+		{"empty", "", "", true},
+		{"only invalid characters", "???.md", "", true},
+		{"empty folder", "notes/ ... /idea.md", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SanitizePath(tt.path)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPath) {
+					t.Errorf("SanitizePath(%q) = %q, %v, want ErrInvalidPath", tt.path, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SanitizePath(%q) error = %v", tt.path, err)
+			}
+			if got != tt.want {
+				t.Errorf("SanitizePath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+
+			// Sanitizing is idempotent
+			if again, err := SanitizePath(got); err != nil || again != got {
+				t.Errorf("SanitizePath(%q) = %q, %v, want it unchanged", got, again, err)
+			}
+		})
+	}
+}
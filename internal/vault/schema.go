@@ -0,0 +1,272 @@
+package vault
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultCreatedFormat is the layout of the created timestamp added by a
+// frontmatter template that does not set one
+const DefaultCreatedFormat = time.RFC3339
+
+// FrontmatterTemplate describes the frontmatter added to created notes
+// whose content has none
+type FrontmatterTemplate struct {
+	CreatedField  string         `yaml:"created_field" json:"created_field"`   // Property set to the creation time, "created" by default
+	CreatedFormat string         `yaml:"created_format" json:"created_format"` // Go time layout, DefaultCreatedFormat by default
+	Tags          []string       `yaml:"tags" json:"tags,omitempty"`           // Tags every new note starts with
+	Fields        map[string]any `yaml:"fields" json:"fields,omitempty"`       // Further properties, e.g. source: mcp
+}
+
+// Property types a FieldRule can require
+const (
+	FieldString  = "string"
+	FieldNumber  = "number"
+	FieldBoolean = "boolean"
+	FieldDate    = "date"
+	FieldList    = "list"
+)
+
+// FieldRule constrains one frontmatter property
+type FieldRule struct {
+	Type     string   `yaml:"type" json:"type,omitempty"`         // One of the Field types, any type when empty
+	Required bool     `yaml:"required" json:"required,omitempty"` // The property must be present and not empty
+	Enum     []string `yaml:"enum" json:"enum,omitempty"`         // Allowed values; for lists, allowed items
+}
+
+// FrontmatterSchema maps property names to the rules notes must follow
+type FrontmatterSchema map[string]FieldRule
+
+// FrontmatterConfig is the content of a frontmatter configuration file
+// Either part may be left out to enable only the other
+type FrontmatterConfig struct {
+	Template *FrontmatterTemplate `yaml:"template"`
+	Schema   FrontmatterSchema    `yaml:"schema"`
+}
+
+// LoadFrontmatterConfig reads a YAML file with an optional template
+// section, as for WithFrontmatterTemplate, and an optional schema section
+// mapping property names to rules, as for WithFrontmatterSchema
+func LoadFrontmatterConfig(file string) (FrontmatterConfig, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return FrontmatterConfig{}, fmt.Errorf("failed to read frontmatter config: %w", err)
+	}
+
+	var config FrontmatterConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(raw))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return FrontmatterConfig{}, fmt.Errorf("failed to parse frontmatter config: %w", err)
+	}
+	if err := config.Schema.Validate(); err != nil {
+		return FrontmatterConfig{}, err
+	}
+	return config, nil
+}
+
+// Validate reports rules with an unknown type
+func (s FrontmatterSchema) Validate() error {
+	types := []string{FieldString, FieldNumber, FieldBoolean, FieldDate, FieldList}
+	for _, field := range slices.Sorted(maps.Keys(s)) {
+		if rule := s[field]; rule.Type != "" && !slices.Contains(types, rule.Type) {
+			return fmt.Errorf("schema field %s: unknown type %q; valid types are %s", field, rule.Type, strings.Join(types, ", "))
+		}
+	}
+	return nil
+}
+
+// WithFrontmatterTemplate makes Create prepend frontmatter built from t to
+// content that has no frontmatter block. Content that already starts with
+// one is written as given.
+func WithFrontmatterTemplate(t FrontmatterTemplate) Option {
+	return func(v *vault) {
+		if t.CreatedField == "" {
+			t.CreatedField = "created"
+		}
+		if t.CreatedFormat == "" {
+			t.CreatedFormat = DefaultCreatedFormat
+		}
+		v.template = &t
+	}
+}
+
+// WithFrontmatterSchema makes Create and Update reject notes whose
+// frontmatter breaks any of the rules with a *SchemaError
+func WithFrontmatterSchema(s FrontmatterSchema) Option {
+	return func(v *vault) {
+		v.schema = s
+	}
+}
+
+// PrepareContent returns content as Create, when create is set, or Update
+// would write it to the note at path: with the frontmatter template
+// applied to new notes, and checked against the schema
+func (v *vault) PrepareContent(path, content string, create bool) (string, error) {
+	if create {
+		content = v.applyTemplate(content, time.Now())
+	}
+	if err := v.checkSchema(path, content); err != nil {
+		return "", err
+	}
+	return content, nil
+}
+
+// applyTemplate prepends the template's frontmatter to content without
+// a frontmatter block
+func (v *vault) applyTemplate(content string, now time.Time) string {
+	if v.template == nil {
+		return content
+	}
+	if _, _, ok := SplitFrontmatter(content); ok {
+		return content
+	}
+
+	fields := &yaml.Node{Kind: yaml.MappingNode}
+	add := func(key string, value any) {
+		var node yaml.Node
+		if err := node.Encode(value); err != nil {
+			return
+		}
+		fields.Content = append(fields.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, &node)
+	}
+
+	add(v.template.CreatedField, now.Format(v.template.CreatedFormat))
+	if len(v.template.Tags) > 0 {
+		add("tags", v.template.Tags)
+	}
+	for _, key := range slices.Sorted(maps.Keys(v.template.Fields)) {
+		if key != v.template.CreatedField && key != "tags" {
+			add(key, v.template.Fields[key])
+		}
+	}
+
+	var b strings.Builder
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(fields); err != nil {
+		return content
+	}
+	return "---\n" + b.String() + "---\n" + content
+}
+
+// checkSchema validates the frontmatter of content against the schema
+func (v *vault) checkSchema(path, content string) error {
+	if len(v.schema) == 0 {
+		return nil
+	}
+
+	var properties map[string]any
+	if block, _, ok := SplitFrontmatter(content); ok {
+		if err := yaml.Unmarshal([]byte(block), &properties); err != nil {
+			return &SchemaError{Path: path, Violations: []SchemaViolation{{Problem: fmt.Sprintf("frontmatter is not valid YAML: %v", err)}}}
+		}
+	}
+
+	var violations []SchemaViolation
+	for _, field := range slices.Sorted(maps.Keys(v.schema)) {
+		rule := v.schema[field]
+		value, ok := properties[field]
+		if !ok || isEmptyProperty(value) {
+			if rule.Required {
+				violations = append(violations, SchemaViolation{Field: field, Problem: "is required"})
+			}
+			continue
+		}
+		if problem := v.checkField(rule, value); problem != "" {
+			violations = append(violations, SchemaViolation{Field: field, Problem: problem})
+		}
+	}
+
+	if len(violations) > 0 {
+		return &SchemaError{Path: path, Violations: violations}
+	}
+	return nil
+}
+
+// checkField describes how value breaks rule, or returns ""
+func (v *vault) checkField(rule FieldRule, value any) string {
+	items := []any{value}
+	switch rule.Type {
+	case FieldString:
+		if _, ok := value.(string); !ok {
+			return fmt.Sprintf("must be a string, got %s", propertyType(value))
+		}
+	case FieldNumber:
+		switch value.(type) {
+		case int, int64, float64:
+		default:
+			return fmt.Sprintf("must be a number, got %s", propertyType(value))
+		}
+	case FieldBoolean:
+		if _, ok := value.(bool); !ok {
+			return fmt.Sprintf("must be true or false, got %s", propertyType(value))
+		}
+	case FieldDate:
+		if !v.isDate(value) {
+			return fmt.Sprintf("must be a date such as 2024-06-01, got %v", value)
+		}
+	case FieldList:
+		list, ok := value.([]any)
+		if !ok {
+			return fmt.Sprintf("must be a list, got %s", propertyType(value))
+		}
+		items = list
+	}
+
+	if len(rule.Enum) == 0 {
+		return ""
+	}
+	for _, item := range items {
+		if !slices.Contains(rule.Enum, fmt.Sprint(item)) {
+			return fmt.Sprintf("must be one of %s, got %v", strings.Join(rule.Enum, ", "), item)
+		}
+	}
+	return ""
+}
+
+// isDate reports whether a property value holds a date
+func (v *vault) isDate(value any) bool {
+	switch value := value.(type) {
+	case time.Time:
+		return true
+	case string:
+		if v.dateFormat != "" {
+			if _, err := time.Parse(v.dateFormat, strings.TrimSpace(value)); err == nil {
+				return true
+			}
+		}
+		_, ok := parseDate(value)
+		return ok
+	}
+	return false
+}
+
+// propertyType names the type of a decoded YAML value for messages
+func propertyType(value any) string {
+	switch value.(type) {
+	case string:
+		return "a string"
+	case int, int64, float64:
+		return "a number"
+	case bool:
+		return "a boolean"
+	case time.Time:
+		return "a date"
+	case []any:
+		return "a list"
+	case map[string]any:
+		return "a mapping"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
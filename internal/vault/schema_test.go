@@ -0,0 +1,265 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFrontmatterTemplate(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		template FrontmatterTemplate
+		content  string
+		want     string
+	}{
+		{
+			name:     "defaults",
+			template: FrontmatterTemplate{},
+			content:  "# Note\n",
+			want:     "---\ncreated: \"2024-06-01T09:30:00Z\"\n---\n# Note\n",
+		},
+		{
+			name: "tags and fields",
+			template: FrontmatterTemplate{
+				CreatedFormat: "2006-01-02",
+				Tags:          []string{"inbox", "mcp"},
+				Fields:        map[string]any{"source": "mcp", "draft": true},
+			},
+			content: "Body",
+			want:    "---\ncreated: \"2024-06-01\"\ntags:\n  - inbox\n  - mcp\ndraft: true\nsource: mcp\n---\nBody",
+		},
+		{
+			name:     "custom created field",
+			template: FrontmatterTemplate{CreatedField: "date", CreatedFormat: "2006-01-02"},
+			content:  "",
+			want:     "---\ndate: \"2024-06-01\"\n---\n",
+		},
+		{
+			name:     "content already has frontmatter",
+			template: FrontmatterTemplate{Tags: []string{"inbox"}},
+			content:  "---\ntitle: Mine\n---\n# Note\n",
+			want:     "---\ntitle: Mine\n---\n# Note\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &vault{}
+			WithFrontmatterTemplate(tt.template)(v)
+			if got := v.applyTemplate(tt.content, now); got != tt.want {
+				t.Errorf("applyTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("create", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		v, err := NewVault(tmpDir, WithFrontmatterTemplate(FrontmatterTemplate{Tags: []string{"inbox"}, Fields: map[string]any{"source": "mcp"}}))
+		if err != nil {
+			t.Fatalf("Failed to create vault: %v", err)
+		}
+
+		if err := v.Create(ctx, "new.md", "# New\n"); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		content := readFile(t, tmpDir, "new.md")
+		properties := parseFrontmatter(content)
+		if properties["source"] != "mcp" || !strings.HasSuffix(content, "---\n# New\n") {
+			t.Errorf("Expected the template's frontmatter before the content, got %q", content)
+		}
+		if _, ok := parseDate(properties["created"].(string)); !ok {
+			t.Errorf("Expected a created timestamp, got %v", properties["created"])
+		}
+
+		// Updates are written as given
+		if err := v.Update(ctx, "new.md", "Replaced"); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		if content := readFile(t, tmpDir, "new.md"); content != "Replaced" {
+			t.Errorf("Expected the update unchanged, got %q", content)
+		}
+
+		// Without a template content is written as given
+		plain, _ := setupTestVault(t)
+		if got, err := plain.PrepareContent("x.md", "Body", true); err != nil || got != "Body" {
+			t.Errorf("PrepareContent() = %q, %v, want the content unchanged", got, err)
+		}
+	})
+}
+
+func TestFrontmatterSchema(t *testing.T) {
+	ctx := context.Background()
+	schema := FrontmatterSchema{
+		"status":  {Type: FieldString, Required: true, Enum: []string{"draft", "active", "done"}},
+		"created": {Type: FieldDate, Required: true},
+		"tags":    {Type: FieldList},
+		"rating":  {Type: FieldNumber},
+		"pinned":  {Type: FieldBoolean},
+		"kind":    {Type: FieldList, Enum: []string{"idea", "task"}},
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    []SchemaViolation
+	}{
+		{
+			name:    "valid",
+			content: "---\nstatus: draft\ncreated: 2024-06-01\ntags: [a]\nrating: 4.5\npinned: true\nkind: [idea]\n---\nBody",
+		},
+		{
+			name:    "date as string",
+			content: "---\nstatus: done\ncreated: \"2024-06-01T10:00\"\n---\n",
+		},
+		{
+			name:    "no frontmatter",
+			content: "Body",
+			want: []SchemaViolation{
+				{Field: "created", Problem: "is required"},
+				{Field: "status", Problem: "is required"},
+			},
+		},
+		{
+			name:    "wrong types",
+			content: "---\nstatus: 3\ncreated: soon\ntags: a\nrating: high\npinned: \"yes\"\n---\n",
+			want: []SchemaViolation{
+				{Field: "created", Problem: "must be a date such as 2024-06-01, got soon"},
+				{Field: "pinned", Problem: "must be true or false, got a string"},
+				{Field: "rating", Problem: "must be a number, got a string"},
+				{Field: "status", Problem: "must be a string, got a number"},
+				{Field: "tags", Problem: "must be a list, got a string"},
+			},
+		},
+		{
+			name:    "not in enum",
+			content: "---\nstatus: open\ncreated: 2024-06-01\nkind: [idea, note]\n---\n",
+			want: []SchemaViolation{
+				{Field: "kind", Problem: "must be one of idea, task, got note"},
+				{Field: "status", Problem: "must be one of draft, active, done, got open"},
+			},
+		},
+		{
+			name:    "empty required value",
+			content: "---\nstatus: \"\"\ncreated: 2024-06-01\n---\n",
+			want:    []SchemaViolation{{Field: "status", Problem: "is required"}},
+		},
+		{
+			name:    "invalid yaml",
+			content: "---\nstatus: [draft\n---\n",
+			want:    []SchemaViolation{{Problem: "frontmatter is not valid YAML: yaml: line 1: did not find expected ',' or ']'"}},
+		},
+	}
+
+	v := &vault{schema: schema}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.checkSchema("note.md", tt.content)
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("Expected no violations, got %v", err)
+				}
+				return
+			}
+
+			var schemaErr *SchemaError
+			if !errors.As(err, &schemaErr) || !errors.Is(err, ErrSchemaViolation) {
+				t.Fatalf("Expected a SchemaError, got %v", err)
+			}
+			if !reflect.DeepEqual(schemaErr.Violations, tt.want) {
+				t.Errorf("Violations = %+v, want %+v", schemaErr.Violations, tt.want)
+			}
+		})
+	}
+
+	t.Run("create and update", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		v, err := NewVault(tmpDir, WithFrontmatterSchema(schema))
+		if err != nil {
+			t.Fatalf("Failed to create vault: %v", err)
+		}
+
+		if err := v.Create(ctx, "bad.md", "No frontmatter"); !errors.Is(err, ErrSchemaViolation) {
+			t.Errorf("Expected a schema violation, got %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(tmpDir, "bad.md")); !os.IsNotExist(err) {
+			t.Errorf("Expected the rejected note not to be written")
+		}
+
+		valid := "---\nstatus: draft\ncreated: 2024-06-01\n---\nBody"
+		if err := v.Create(ctx, "good.md", valid); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		if err := v.Update(ctx, "good.md", "---\nstatus: gone\ncreated: 2024-06-01\n---\nBody"); !errors.Is(err, ErrSchemaViolation) {
+			t.Errorf("Expected a schema violation, got %v", err)
+		}
+		if content := readFile(t, tmpDir, "good.md"); content != valid {
+			t.Errorf("Expected the rejected update not to be written, got %q", content)
+		}
+	})
+
+	t.Run("template satisfies schema", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		v, err := NewVault(tmpDir,
+			WithFrontmatterTemplate(FrontmatterTemplate{CreatedFormat: "2006-01-02", Fields: map[string]any{"status": "draft"}}),
+			WithFrontmatterSchema(schema),
+		)
+		if err != nil {
+			t.Fatalf("Failed to create vault: %v", err)
+		}
+		if err := v.Create(ctx, "new.md", "Body"); err != nil {
+			t.Errorf("Expected the templated note to pass the schema, got %v", err)
+		}
+	})
+}
+
+func TestLoadFrontmatterConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		want    FrontmatterConfig
+		wantErr bool
+	}{
+		{
+			name:   "both",
+			config: "template:\n  created_format: \"2006-01-02\"\n  tags: [inbox]\n  fields:\n    source: mcp\nschema:\n  status:\n    type: string\n    required: true\n    enum: [draft, done]\n",
+			want: FrontmatterConfig{
+				Template: &FrontmatterTemplate{CreatedFormat: "2006-01-02", Tags: []string{"inbox"}, Fields: map[string]any{"source": "mcp"}},
+				Schema:   FrontmatterSchema{"status": {Type: FieldString, Required: true, Enum: []string{"draft", "done"}}},
+			},
+		},
+		{
+			name:   "schema only",
+			config: "schema:\n  created:\n    type: date\n",
+			want:   FrontmatterConfig{Schema: FrontmatterSchema{"created": {Type: FieldDate}}},
+		},
+		{name: "empty", config: ""},
+		{name: "unknown type", config: "schema:\n  status:\n    type: text\n", wantErr: true},
+		{name: "unknown key", config: "templates:\n  tags: [a]\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "frontmatter.yaml")
+			if err := os.WriteFile(file, []byte(tt.config), 0644); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+
+			got, err := LoadFrontmatterConfig(file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadFrontmatterConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadFrontmatterConfig() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,418 @@
+package vault
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default scratch limits; see ScratchSettings
+const (
+	DefaultScratchMaxNotes = 50
+	DefaultScratchMaxBytes = 4 << 20
+)
+
+// ScratchSettings bound the scratch notes and decide where they are kept
+type ScratchSettings struct {
+	Dir      string // Folder keeping scratch notes across restarts, empty to keep them in memory
+	MaxNotes int    // Most scratch notes at a time
+	MaxBytes int64  // Most content of all scratch notes together
+}
+
+// WithScratch sets the limits of the scratch notes and where they are
+// kept. Zero limits keep the defaults. Without a Dir scratch notes live in
+// memory and are gone when the server stops; with one, each is kept in a
+// JSON file there. Only one server may use a Dir at a time.
+func WithScratch(s ScratchSettings) Option {
+	return func(v *vault) {
+		if s.MaxNotes == 0 {
+			s.MaxNotes = DefaultScratchMaxNotes
+		}
+		if s.MaxBytes == 0 {
+			s.MaxBytes = DefaultScratchMaxBytes
+		}
+		v.scratch.settings = s
+	}
+}
+
+// ScratchInfo describes a scratch note without its content
+type ScratchInfo struct {
+	ID       string    `json:"id"`
+	Title    string    `json:"title,omitempty"`
+	Tags     []string  `json:"tags"`
+	Size     int       `json:"size"` // Content length in bytes
+	Created  time.Time `json:"created"`
+	Modified time.Time `json:"modified"`
+	Revision string    `json:"revision"` // Content hash, as for notes
+}
+
+// ScratchNote is a scratch note, or a section of one, with its content
+type ScratchNote struct {
+	ScratchInfo
+	Content   string   `json:"content"`
+	StartLine int      `json:"start_line,omitempty"` // 1-based, when a section was read
+	EndLine   int      `json:"end_line,omitempty"`   // 1-based, inclusive
+	Warnings  []string `json:"warnings,omitempty"`   // E.g. a block ID defined more than once
+}
+
+// ScratchList is the scratch notes with the space they use
+type ScratchList struct {
+	Notes      []ScratchInfo `json:"notes"` // Oldest first
+	Bytes      int64         `json:"bytes"` // Content of all scratch notes
+	MaxNotes   int           `json:"max_notes"`
+	MaxBytes   int64         `json:"max_bytes"`
+	Persistent bool          `json:"persistent"` // Kept across restarts in a scratch folder
+}
+
+// PromoteScratchOptions move a scratch note into the vault
+type PromoteScratchOptions struct {
+	ID        string
+	Path      string // Vault path of the note to write
+	Overwrite bool   // Replace a note already at Path instead of failing
+}
+
+// ScratchPromotion is the note a scratch note became
+type ScratchPromotion struct {
+	ID       string `json:"id"`
+	Path     string `json:"path"`
+	Created  bool   `json:"created"`  // False when an existing note was overwritten
+	Revision string `json:"revision"` // Content hash of the note as written
+}
+
+// scratchNote is a scratch note as scratchStore holds it, and as its
+// files store it
+type scratchNote struct {
+	ID       string    `json:"id"`
+	Title    string    `json:"title,omitempty"`
+	Content  string    `json:"content"`
+	Created  time.Time `json:"created"`
+	Modified time.Time `json:"modified"`
+}
+
+// info describes the note
+func (n scratchNote) info() ScratchInfo {
+	return ScratchInfo{
+		ID:       n.ID,
+		Title:    n.Title,
+		Tags:     ExtractTags(n.Content),
+		Size:     len(n.Content),
+		Created:  n.Created,
+		Modified: n.Modified,
+		Revision: contentHash(n.Content),
+	}
+}
+
+// scratchStore holds the scratch notes within their limits, in memory and,
+// when settings.Dir is set, in a file per note read back on first use.
+// The zero value with settings set is ready to use
+type scratchStore struct {
+	settings ScratchSettings
+
+	mu     sync.Mutex
+	loaded bool                   // notes holds the files of settings.Dir
+	notes  map[string]scratchNote // By ID
+	bytes  int64                  // Content of all notes
+}
+
+// load reads the scratch folder the first time the store is used
+// Caller must hold s.mu
+func (s *scratchStore) load() error {
+	if s.loaded {
+		return nil
+	}
+	notes := make(map[string]scratchNote)
+	var bytes int64
+	if s.settings.Dir != "" {
+		files, err := filepath.Glob(filepath.Join(s.settings.Dir, "*.json"))
+		if err != nil {
+			return fmt.Errorf("failed to list scratch notes: %w", err)
+		}
+		for _, file := range files {
+			raw, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("failed to read scratch note: %w", err)
+			}
+			var note scratchNote
+			if err := json.Unmarshal(raw, &note); err != nil || note.ID+".json" != filepath.Base(file) {
+				return fmt.Errorf("failed to parse scratch note %s: not a scratch note", filepath.Base(file))
+			}
+			notes[note.ID] = note
+			bytes += int64(len(note.Content))
+		}
+	}
+	s.notes, s.bytes, s.loaded = notes, bytes, true
+	return nil
+}
+
+// save stores note, replacing the note with its ID, if the limits allow
+// Caller must hold s.mu and have loaded the store
+func (s *scratchStore) save(note scratchNote) error {
+	previous, exists := s.notes[note.ID]
+	if !exists && len(s.notes) >= s.settings.MaxNotes {
+		return fmt.Errorf("%w: %d scratch notes, at most %d", ErrScratchFull, len(s.notes), s.settings.MaxNotes)
+	}
+	bytes := s.bytes - int64(len(previous.Content)) + int64(len(note.Content))
+	if bytes > s.settings.MaxBytes {
+		return fmt.Errorf("%w: scratch notes would hold %d bytes, at most %d", ErrScratchFull, bytes, s.settings.MaxBytes)
+	}
+
+	if s.settings.Dir != "" {
+		raw, err := json.MarshalIndent(note, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode scratch note: %w", err)
+		}
+		if err := os.MkdirAll(s.settings.Dir, 0755); err != nil {
+			return fmt.Errorf("failed to create scratch folder: %w", err)
+		}
+		if err := writeDataFile(filepath.Join(s.settings.Dir, note.ID+".json"), raw); err != nil {
+			return fmt.Errorf("failed to write scratch note: %w", err)
+		}
+	}
+	s.notes[note.ID], s.bytes = note, bytes
+	return nil
+}
+
+// create adds a note with a new ID
+func (s *scratchStore) create(title, content string) (scratchNote, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return scratchNote{}, err
+	}
+
+	var id string
+	for id == "" || s.notes[id].ID != "" {
+		id = newScratchID()
+	}
+	now := time.Now().UTC()
+	note := scratchNote{ID: id, Title: title, Content: content, Created: now, Modified: now}
+	if err := s.save(note); err != nil {
+		return scratchNote{}, err
+	}
+	return note, nil
+}
+
+// update replaces the content of the note with id, and its title unless
+// title is empty
+func (s *scratchStore) update(id, title, content string) (scratchNote, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return scratchNote{}, err
+	}
+
+	note, ok := s.notes[id]
+	if !ok {
+		return scratchNote{}, fmt.Errorf("%w: %s", ErrScratchNotFound, id)
+	}
+	if title != "" {
+		note.Title = title
+	}
+	note.Content, note.Modified = content, time.Now().UTC()
+	if err := s.save(note); err != nil {
+		return scratchNote{}, err
+	}
+	return note, nil
+}
+
+// get returns the note with id
+func (s *scratchStore) get(id string) (scratchNote, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return scratchNote{}, err
+	}
+
+	note, ok := s.notes[id]
+	if !ok {
+		return scratchNote{}, fmt.Errorf("%w: %s", ErrScratchNotFound, id)
+	}
+	return note, nil
+}
+
+// list returns the notes, oldest first, and the bytes they hold
+func (s *scratchStore) list() ([]scratchNote, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return nil, 0, err
+	}
+
+	notes := make([]scratchNote, 0, len(s.notes))
+	for _, note := range s.notes {
+		notes = append(notes, note)
+	}
+	slices.SortFunc(notes, func(a, b scratchNote) int {
+		return cmp.Or(a.Created.Compare(b.Created), cmp.Compare(a.ID, b.ID))
+	})
+	return notes, s.bytes, nil
+}
+
+// remove drops the note with id unless it changed from content since
+func (s *scratchStore) remove(id, content string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	note, ok := s.notes[id]
+	if !ok || note.Content != content {
+		return nil
+	}
+	if s.settings.Dir != "" {
+		if err := os.Remove(filepath.Join(s.settings.Dir, id+".json")); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove scratch note: %w", err)
+		}
+	}
+	delete(s.notes, id)
+	s.bytes -= int64(len(note.Content))
+	return nil
+}
+
+// newScratchID returns a random ID for a scratch note
+func newScratchID() string {
+	var b [4]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// CreateScratch stores content as a new scratch note, outside the vault
+func (v *vault) CreateScratch(ctx context.Context, title, content string) (ScratchInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return ScratchInfo{}, err
+	}
+	note, err := v.scratch.create(strings.TrimSpace(title), content)
+	if err != nil {
+		return ScratchInfo{}, err
+	}
+	return note.info(), nil
+}
+
+// UpdateScratch replaces the content of a scratch note, and its title
+// unless title is empty
+func (v *vault) UpdateScratch(ctx context.Context, id, title, content string) (ScratchInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return ScratchInfo{}, err
+	}
+	note, err := v.scratch.update(id, strings.TrimSpace(title), content)
+	if err != nil {
+		return ScratchInfo{}, err
+	}
+	return note.info(), nil
+}
+
+// ReadScratch returns a scratch note, or the section of it under a heading
+// or the block with an ID when opts sets one, as ReadSection does for notes
+func (v *vault) ReadScratch(ctx context.Context, id string, opts SectionOptions) (ScratchNote, error) {
+	if opts.Heading != "" && opts.Block != "" {
+		return ScratchNote{}, fmt.Errorf("%w: address a section by heading or by block", ErrInvalidPath)
+	}
+	if err := ctx.Err(); err != nil {
+		return ScratchNote{}, err
+	}
+	note, err := v.scratch.get(id)
+	if err != nil {
+		return ScratchNote{}, err
+	}
+
+	result := ScratchNote{ScratchInfo: note.info(), Content: note.Content}
+	if opts.Heading == "" && opts.Block == "" {
+		return result, nil
+	}
+	section, err := findSection("scratch note "+id, note.Content, ParseBlocks(note.Content), opts)
+	if err != nil {
+		return ScratchNote{}, err
+	}
+	result.Content, result.StartLine, result.EndLine, result.Warnings = section.Content, section.StartLine, section.EndLine, section.Warnings
+	return result, nil
+}
+
+// ListScratch returns the scratch notes, oldest first
+func (v *vault) ListScratch(ctx context.Context) (ScratchList, error) {
+	if err := ctx.Err(); err != nil {
+		return ScratchList{}, err
+	}
+	notes, bytes, err := v.scratch.list()
+	if err != nil {
+		return ScratchList{}, err
+	}
+
+	list := ScratchList{
+		Notes:      make([]ScratchInfo, len(notes)),
+		Bytes:      bytes,
+		MaxNotes:   v.scratch.settings.MaxNotes,
+		MaxBytes:   v.scratch.settings.MaxBytes,
+		Persistent: v.scratch.settings.Dir != "",
+	}
+	for i, note := range notes {
+		list.Notes[i] = note.info()
+	}
+	return list, nil
+}
+
+// PromoteScratch writes a scratch note into the vault at opts.Path with
+// the checks of Create, or of Update when opts.Overwrite is set and a note
+// is there, and drops the scratch note once written
+func (v *vault) PromoteScratch(ctx context.Context, opts PromoteScratchOptions) (ScratchPromotion, error) {
+	note, err := v.scratch.get(opts.ID)
+	if err != nil {
+		return ScratchPromotion{}, err
+	}
+	fullPath, err := v.validatePath(opts.Path)
+	if err != nil {
+		return ScratchPromotion{}, err
+	}
+
+	// Check and write under the note's lock, as Create and Update do
+	unlock := v.writeLocks.lock(fullPath)
+	defer unlock()
+
+	_, statErr := os.Stat(fullPath)
+	exists := statErr == nil
+	if exists && !opts.Overwrite {
+		return ScratchPromotion{}, fmt.Errorf("%w: %s", ErrNoteExists, opts.Path)
+	}
+	if exists {
+		_, err = v.checkUpdate(ctx, opts.Path)
+	} else {
+		_, err = v.checkCreate(ctx, opts.Path)
+	}
+	if err != nil {
+		return ScratchPromotion{}, err
+	}
+
+	relPath := v.relPath(fullPath)
+	content, err := v.PrepareContent(relPath, note.Content, !exists)
+	if err != nil {
+		return ScratchPromotion{}, err
+	}
+
+	// Check context cancellation before I/O; once writing starts it completes
+	if err := ctx.Err(); err != nil {
+		return ScratchPromotion{}, err
+	}
+	if exists {
+		var entry AuditEntry
+		if entry, err = v.writeNote(fullPath, content); err != nil {
+			return ScratchPromotion{}, err
+		}
+		err = v.record(ctx, entry)
+	} else {
+		err = v.createNote(ctx, fullPath, content)
+	}
+	if err != nil {
+		return ScratchPromotion{}, err
+	}
+
+	if err := v.scratch.remove(note.ID, note.Content); err != nil {
+		v.logger.Warn("dropping promoted scratch note failed", "id", note.ID, "error", err)
+	}
+	return ScratchPromotion{ID: note.ID, Path: relPath, Created: !exists, Revision: contentHash(content)}, nil
+}
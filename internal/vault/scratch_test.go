@@ -0,0 +1,147 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestScratch(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	v, err := NewVault(tmpDir, WithScratch(ScratchSettings{MaxNotes: 2, MaxBytes: 100}))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+
+	draft, err := v.CreateScratch(ctx, " Weekly ", "# Draft #idea\n\n## Goals\nShip it. ^goal\n")
+	if err != nil {
+		t.Fatalf("CreateScratch() error = %v", err)
+	}
+	if len(draft.ID) != 8 || draft.Title != "Weekly" || !slices.Equal(draft.Tags, []string{"idea"}) {
+		t.Errorf("CreateScratch() = %+v", draft)
+	}
+
+	section, err := v.ReadScratch(ctx, draft.ID, SectionOptions{Heading: "Goals"})
+	if err != nil {
+		t.Fatalf("ReadScratch() error = %v", err)
+	}
+	if section.Content != "## Goals\nShip it. ^goal" || section.StartLine != 3 || section.Revision != draft.Revision {
+		t.Errorf("ReadScratch() section = %+v", section)
+	}
+	if block, err := v.ReadScratch(ctx, draft.ID, SectionOptions{Block: "goal"}); err != nil || block.Content != "Ship it. ^goal" {
+		t.Errorf("ReadScratch() block = %+v, %v", block, err)
+	}
+	if _, err := v.ReadScratch(ctx, draft.ID, SectionOptions{Heading: "Risks"}); !errors.Is(err, ErrSectionNotFound) {
+		t.Errorf("ReadScratch() of a missing heading error = %v, want ErrSectionNotFound", err)
+	}
+
+	updated, err := v.UpdateScratch(ctx, draft.ID, "", "# Draft\n#final\n")
+	if err != nil {
+		t.Fatalf("UpdateScratch() error = %v", err)
+	}
+	if updated.Title != "Weekly" || !slices.Equal(updated.Tags, []string{"final"}) || updated.Revision == draft.Revision {
+		t.Errorf("UpdateScratch() = %+v", updated)
+	}
+	if _, err := v.UpdateScratch(ctx, "missing", "", "x"); !errors.Is(err, ErrScratchNotFound) {
+		t.Errorf("UpdateScratch() of a missing note error = %v, want ErrScratchNotFound", err)
+	}
+
+	// The limits count notes and the content of them all
+	if _, err := v.CreateScratch(ctx, "", string(make([]byte, 90))); !errors.Is(err, ErrScratchFull) {
+		t.Errorf("CreateScratch() over the size limit error = %v, want ErrScratchFull", err)
+	}
+	if _, err := v.CreateScratch(ctx, "", "second"); err != nil {
+		t.Fatalf("CreateScratch() error = %v", err)
+	}
+	if _, err := v.CreateScratch(ctx, "", "third"); !errors.Is(err, ErrScratchFull) {
+		t.Errorf("CreateScratch() over the count limit error = %v, want ErrScratchFull", err)
+	}
+	list, err := v.ListScratch(ctx)
+	if err != nil {
+		t.Fatalf("ListScratch() error = %v", err)
+	}
+	if len(list.Notes) != 2 || list.Notes[0].ID != draft.ID || list.Bytes != 21 || list.Persistent {
+		t.Errorf("ListScratch() = %+v", list)
+	}
+
+	// Nothing reaches the vault before promotion
+	if entries, _ := os.ReadDir(tmpDir); len(entries) != 0 {
+		t.Errorf("vault holds %d entries before promotion, want none", len(entries))
+	}
+}
+
+func TestPromoteScratch(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{"Plan.md": "old plan\n"})
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+
+	draft, err := v.CreateScratch(ctx, "", "new plan\n")
+	if err != nil {
+		t.Fatalf("CreateScratch() error = %v", err)
+	}
+	if _, err := v.PromoteScratch(ctx, PromoteScratchOptions{ID: draft.ID, Path: "Plan.md"}); !errors.Is(err, ErrNoteExists) {
+		t.Errorf("PromoteScratch() onto a note error = %v, want ErrNoteExists", err)
+	}
+	if _, err := v.PromoteScratch(ctx, PromoteScratchOptions{ID: draft.ID, Path: "../Plan.md"}); !errors.Is(err, ErrPathTraversal) {
+		t.Errorf("PromoteScratch() outside the vault error = %v, want ErrPathTraversal", err)
+	}
+
+	promoted, err := v.PromoteScratch(ctx, PromoteScratchOptions{ID: draft.ID, Path: "Drafts/Plan.md"})
+	if err != nil {
+		t.Fatalf("PromoteScratch() error = %v", err)
+	}
+	if content, _ := v.Read(ctx, "Drafts/Plan.md"); content != "new plan\n" || !promoted.Created || promoted.Revision != contentHash(content) {
+		t.Errorf("PromoteScratch() = %+v, note holds %q", promoted, content)
+	}
+	if _, err := v.ReadScratch(ctx, draft.ID, SectionOptions{}); !errors.Is(err, ErrScratchNotFound) {
+		t.Errorf("ReadScratch() after promotion error = %v, want ErrScratchNotFound", err)
+	}
+
+	draft, _ = v.CreateScratch(ctx, "", "newer plan\n")
+	promoted, err = v.PromoteScratch(ctx, PromoteScratchOptions{ID: draft.ID, Path: "Plan.md", Overwrite: true})
+	if err != nil {
+		t.Fatalf("PromoteScratch() with overwrite error = %v", err)
+	}
+	if content, _ := v.Read(ctx, "Plan.md"); content != "newer plan\n" || promoted.Created {
+		t.Errorf("PromoteScratch() with overwrite = %+v, note holds %q", promoted, content)
+	}
+	if versions, _ := v.ListVersions(ctx, "Plan.md"); len(versions) != 1 {
+		t.Errorf("ListVersions() = %d versions, want the overwritten content backed up", len(versions))
+	}
+}
+
+func TestScratchDir(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	scratchDir := filepath.Join(t.TempDir(), "scratch")
+	v, err := NewVault(tmpDir, WithScratch(ScratchSettings{Dir: scratchDir}))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	kept, _ := v.CreateScratch(ctx, "kept", "stays\n")
+	promoted, _ := v.CreateScratch(ctx, "", "goes\n")
+	if _, err := v.PromoteScratch(ctx, PromoteScratchOptions{ID: promoted.ID, Path: "Goes.md"}); err != nil {
+		t.Fatalf("PromoteScratch() error = %v", err)
+	}
+
+	// Another server using the folder finds the notes left
+	v, err = NewVault(tmpDir, WithScratch(ScratchSettings{Dir: scratchDir}))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	list, err := v.ListScratch(ctx)
+	if err != nil {
+		t.Fatalf("ListScratch() error = %v", err)
+	}
+	if len(list.Notes) != 1 || list.Notes[0].ID != kept.ID || list.Notes[0].Title != "kept" || !list.Persistent {
+		t.Errorf("ListScratch() = %+v, want only %s", list, kept.ID)
+	}
+}
@@ -0,0 +1,195 @@
+package vault
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// searchesFile stores the saved searches, below the data directory
+const searchesFile = "searches.json"
+
+// MaxSavedSearchName is the longest saved search name accepted, in
+// characters
+const MaxSavedSearchName = 100
+
+// SavedSearch is a named search kept with the vault
+type SavedSearch struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Params      map[string]any `json:"params"` // search_notes parameters, as given
+	Created     time.Time      `json:"created"`
+	Updated     time.Time      `json:"updated"`
+}
+
+// SaveSearchOptions describes the search SaveSearch stores
+type SaveSearchOptions struct {
+	Name        string         // Unique name, trimmed
+	Description string         // What the search is for
+	Params      map[string]any // Parameters stored for running the search
+	Query       SearchOptions  // Params as parsed, validated before saving
+	Overwrite   bool           // Replace a saved search of the same name
+}
+
+// searchData is the content of searchesFile
+type searchData struct {
+	Searches []SavedSearch `json:"searches"` // Sorted by name
+}
+
+// searchStore reads and replaces the saved searches file. Updates hold a
+// lock file next to it, so server processes sharing the vault never lose
+// each other's changes.
+// The zero value with file set is ready to use
+type searchStore struct {
+	file string
+}
+
+// load returns the saved searches, none if the file does not exist yet
+func (s *searchStore) load() ([]SavedSearch, error) {
+	raw, err := os.ReadFile(s.file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read saved searches: %w", err)
+	}
+	var data searchData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", searchesFile, err)
+	}
+	return data.Searches, nil
+}
+
+// update applies fn to the saved searches under the lock and saves them
+// when fn reports a change
+func (s *searchStore) update(ctx context.Context, fn func([]SavedSearch) ([]SavedSearch, bool, error)) error {
+	if err := os.MkdirAll(filepath.Dir(s.file), 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+	unlock, err := lockDataFile(ctx, s.file)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	searches, err := s.load()
+	if err != nil {
+		return err
+	}
+	searches, changed, err := fn(searches)
+	if err != nil || !changed {
+		return err
+	}
+	slices.SortFunc(searches, func(a, b SavedSearch) int { return cmp.Compare(a.Name, b.Name) })
+
+	raw, err := json.MarshalIndent(searchData{Searches: searches}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode saved searches: %w", err)
+	}
+	if err := writeDataFile(s.file, raw); err != nil {
+		return fmt.Errorf("failed to write saved searches: %w", err)
+	}
+	return nil
+}
+
+// savedSearchName trims name and checks it can name a saved search
+func savedSearchName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxSavedSearchName || strings.ContainsFunc(name, unicode.IsControl) {
+		return "", fmt.Errorf("%w: name %q must be 1 to %d characters without control characters", ErrInvalidSavedSearch, name, MaxSavedSearchName)
+	}
+	return name, nil
+}
+
+// SaveSearch stores a named search after checking that its patterns
+// compile and its folder exists. A search of the same name is replaced
+// only with opts.Overwrite, keeping its creation time.
+func (v *vault) SaveSearch(ctx context.Context, opts SaveSearchOptions) (SavedSearch, error) {
+	name, err := savedSearchName(opts.Name)
+	if err != nil {
+		return SavedSearch{}, err
+	}
+	if _, err := v.compileQueries(opts.Query); err != nil {
+		return SavedSearch{}, err
+	}
+	if _, err := v.validateDir(opts.Query.Subpath); err != nil {
+		return SavedSearch{}, err
+	}
+
+	now := time.Now().UTC()
+	saved := SavedSearch{Name: name, Description: opts.Description, Params: opts.Params, Created: now, Updated: now}
+	if saved.Params == nil {
+		saved.Params = make(map[string]any)
+	}
+	err = v.searches.update(ctx, func(searches []SavedSearch) ([]SavedSearch, bool, error) {
+		i := slices.IndexFunc(searches, func(s SavedSearch) bool { return s.Name == name })
+		if i < 0 {
+			return append(searches, saved), true, nil
+		}
+		if !opts.Overwrite {
+			return nil, false, fmt.Errorf("%w: %s", ErrSavedSearchExists, name)
+		}
+		saved.Created = searches[i].Created
+		searches[i] = saved
+		return searches, true, nil
+	})
+	if err != nil {
+		return SavedSearch{}, err
+	}
+	return saved, nil
+}
+
+// ListSavedSearches returns the saved searches sorted by name
+func (v *vault) ListSavedSearches(ctx context.Context) ([]SavedSearch, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := v.checkAvailable(); err != nil {
+		return nil, err
+	}
+	searches, err := v.searches.load()
+	if err != nil {
+		return nil, err
+	}
+	if searches == nil {
+		searches = []SavedSearch{}
+	}
+	return searches, nil
+}
+
+// GetSavedSearch returns the saved search called name
+func (v *vault) GetSavedSearch(ctx context.Context, name string) (SavedSearch, error) {
+	searches, err := v.ListSavedSearches(ctx)
+	if err != nil {
+		return SavedSearch{}, err
+	}
+	name = strings.TrimSpace(name)
+	i := slices.IndexFunc(searches, func(s SavedSearch) bool { return s.Name == name })
+	if i < 0 {
+		return SavedSearch{}, fmt.Errorf("%w: %s", ErrSavedSearchNotFound, name)
+	}
+	return searches[i], nil
+}
+
+// DeleteSavedSearch removes the saved search called name
+func (v *vault) DeleteSavedSearch(ctx context.Context, name string) error {
+	if err := v.checkAvailable(); err != nil {
+		return err
+	}
+	name = strings.TrimSpace(name)
+	return v.searches.update(ctx, func(searches []SavedSearch) ([]SavedSearch, bool, error) {
+		i := slices.IndexFunc(searches, func(s SavedSearch) bool { return s.Name == name })
+		if i < 0 {
+			return nil, false, fmt.Errorf("%w: %s", ErrSavedSearchNotFound, name)
+		}
+		return slices.Delete(searches, i, i+1), true, nil
+	})
+}
@@ -0,0 +1,116 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestSavedSearches(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmpDir, "Work"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	vi, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	v := vi.(*vault)
+	ctx := context.Background()
+
+	todo := SaveSearchOptions{
+		Name:        " open tasks ",
+		Description: "Unchecked tasks at work",
+		Params:      map[string]any{"query": `- \[ \]`, "path": "Work"},
+		Query:       SearchOptions{Query: `- \[ \]`, Subpath: "Work"},
+	}
+	saved, err := v.SaveSearch(ctx, todo)
+	if err != nil {
+		t.Fatalf("SaveSearch() error = %v", err)
+	}
+	if saved.Name != "open tasks" || saved.Created.IsZero() {
+		t.Errorf("SaveSearch() = %+v", saved)
+	}
+
+	if _, err := v.SaveSearch(ctx, todo); !errors.Is(err, ErrSavedSearchExists) {
+		t.Errorf("SaveSearch() of an existing name error = %v, want ErrSavedSearchExists", err)
+	}
+	todo.Overwrite, todo.Description = true, "Everything unchecked"
+	updated, err := v.SaveSearch(ctx, todo)
+	if err != nil || !updated.Created.Equal(saved.Created) || updated.Description != todo.Description {
+		t.Errorf("SaveSearch() with overwrite = %+v, %v; want the creation time kept", updated, err)
+	}
+
+	for _, tc := range []struct {
+		opts SaveSearchOptions
+		want error
+	}{
+		{SaveSearchOptions{Name: "bad", Query: SearchOptions{Query: "kube(rnetes"}}, ErrInvalidPattern},
+		{SaveSearchOptions{Name: "missing", Query: SearchOptions{Subpath: "Missing"}}, ErrDirectoryNotFound},
+		{SaveSearchOptions{Name: "  "}, ErrInvalidSavedSearch},
+		{SaveSearchOptions{Name: "a\nb"}, ErrInvalidSavedSearch},
+	} {
+		if _, err := v.SaveSearch(ctx, tc.opts); !errors.Is(err, tc.want) {
+			t.Errorf("SaveSearch(%q) error = %v, want %v", tc.opts.Name, err, tc.want)
+		}
+	}
+
+	if _, err := v.SaveSearch(ctx, SaveSearchOptions{Name: "inbox"}); err != nil {
+		t.Fatalf("SaveSearch() error = %v", err)
+	}
+	searches, err := v.ListSavedSearches(ctx)
+	if err != nil || len(searches) != 2 || searches[0].Name != "inbox" || searches[1].Name != "open tasks" {
+		t.Fatalf("ListSavedSearches() = %+v, %v; want inbox and open tasks", searches, err)
+	}
+	got, err := v.GetSavedSearch(ctx, "open tasks")
+	if err != nil || got.Params["path"] != "Work" || got.Params["query"] != `- \[ \]` {
+		t.Errorf("GetSavedSearch() = %+v, %v", got, err)
+	}
+
+	if err := v.DeleteSavedSearch(ctx, "inbox"); err != nil {
+		t.Fatalf("DeleteSavedSearch() error = %v", err)
+	}
+	if err := v.DeleteSavedSearch(ctx, "inbox"); !errors.Is(err, ErrSavedSearchNotFound) {
+		t.Errorf("DeleteSavedSearch() of a deleted search error = %v", err)
+	}
+	if _, err := v.GetSavedSearch(ctx, "inbox"); !errors.Is(err, ErrSavedSearchNotFound) {
+		t.Errorf("GetSavedSearch() of a deleted search error = %v", err)
+	}
+}
+
+func TestSavedSearchesConcurrentSaves(t *testing.T) {
+	tmpDir := t.TempDir()
+	// Two vaults on one directory stand in for two server processes
+	var vaults []*vault
+	for range 2 {
+		vi, err := NewVault(tmpDir)
+		if err != nil {
+			t.Fatalf("Failed to create vault: %v", err)
+		}
+		vaults = append(vaults, vi.(*vault))
+	}
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Go(func() {
+			opts := SaveSearchOptions{Name: fmt.Sprintf("search %02d", i)}
+			if _, err := vaults[i%2].SaveSearch(ctx, opts); err != nil {
+				t.Errorf("SaveSearch(%q) error = %v", opts.Name, err)
+			}
+		})
+	}
+	wg.Wait()
+
+	searches, err := vaults[0].ListSavedSearches(ctx)
+	if err != nil || len(searches) != 20 {
+		t.Errorf("ListSavedSearches() = %d searches, %v; want all 20", len(searches), err)
+	}
+	if leftover, _ := filepath.Glob(filepath.Join(tmpDir, dataDir, searchesFile+".*")); len(leftover) > 0 {
+		t.Errorf("Files left next to %s: %v", searchesFile, leftover)
+	}
+}
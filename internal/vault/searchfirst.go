@@ -0,0 +1,198 @@
+package vault
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SearchPage is the notes a search found before stopping, and the cursor
+// resuming it
+type SearchPage struct {
+	Notes      []NoteInfo `json:"notes"`
+	Scanned    int        `json:"scanned_notes"`         // Notes checked by this call
+	Remaining  int        `json:"remaining_notes"`       // Notes left for later calls
+	NextCursor string     `json:"next_cursor,omitempty"` // Set while notes remain
+}
+
+// searchCursor is the decoded form of a SearchFirst cursor. Notes are
+// checked newest first, ties by path; a resumed search checks the notes
+// past the position in that order, those modified since the previous call
+// started, and those still pending from before it.
+type searchCursor struct {
+	Modified time.Time `json:"m"`           // Modification time of the last note checked
+	Path     string    `json:"p"`           // Path of the last note checked, empty before the first
+	Since    time.Time `json:"s"`           // When the previous call started
+	Pending  []string  `json:"r,omitempty"` // Notes before the position left unchecked
+}
+
+// String encodes the cursor as an opaque token
+func (c searchCursor) String() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(append([]byte("search:"), raw...))
+}
+
+// parseSearchCursor decodes a cursor returned by SearchFirst; an empty
+// token starts a new search
+func parseSearchCursor(token string) (searchCursor, error) {
+	var c searchCursor
+	if token == "" {
+		return c, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	data, ok := strings.CutPrefix(string(raw), "search:")
+	if err != nil || !ok || json.Unmarshal([]byte(data), &c) != nil || (c.Path == "" && len(c.Pending) > 0) {
+		return searchCursor{}, fmt.Errorf("%w: not a cursor returned by search_notes with first_n", ErrInvalidCursor)
+	}
+	return c, nil
+}
+
+// compareNewest orders notes newest first, ties by path
+func compareNewest(aModified time.Time, aPath string, bModified time.Time, bPath string) int {
+	if n := bModified.Compare(aModified); n != 0 {
+		return n
+	}
+	return strings.Compare(aPath, bPath)
+}
+
+// past reports whether file comes after the cursor's position
+func (c searchCursor) past(file noteFile) bool {
+	return c.Path == "" || compareNewest(file.info.ModTime(), file.relPath, c.Modified, c.Path) > 0
+}
+
+// remains reports whether a resumed search still has to check file
+func (c searchCursor) remains(file noteFile) bool {
+	return c.past(file) || !file.info.ModTime().Before(c.Since) || slices.Contains(c.Pending, file.relPath)
+}
+
+// SearchFirst checks the notes Search would, newest first, and returns as
+// soon as opts.FirstN of them match, all that match when it is 0. The
+// cursor of the page resumes the search with the same options: no note is
+// skipped and notes already returned are not returned again, except those
+// modified in between, which are checked again and may be returned twice.
+// Deleted notes are left out. If opts.Timeout elapses first, the page so
+// far is returned with a *PartialResultsError, its cursor resuming after
+// the notes checked.
+func (v *vault) SearchFirst(ctx context.Context, opts SearchOptions) (SearchPage, error) {
+	cursor, err := parseSearchCursor(opts.Cursor)
+	if err != nil {
+		return SearchPage{}, err
+	}
+	if opts.Sort == "" {
+		opts.Sort = SortModified // The order the notes are checked in
+	}
+	search, err := v.prepareSearch(opts)
+	if err != nil {
+		return SearchPage{}, err
+	}
+	started := time.Now()
+
+	// Only the search's own deadline yields partial results; cancellation by
+	// the caller still fails the search
+	searchCtx := ctx
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		searchCtx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	timedOut := func(err error) bool {
+		return ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded)
+	}
+
+	files, err := v.collectNotes(searchCtx, search.scope, search.skip)
+	if timedOut(err) {
+		return SearchPage{Notes: []NoteInfo{}, NextCursor: cursor.String()}, &PartialResultsError{Total: len(files)}
+	}
+	if err != nil {
+		return SearchPage{}, err
+	}
+	files = slices.DeleteFunc(files, func(file noteFile) bool { return !cursor.remains(file) })
+	slices.SortFunc(files, func(a, b noteFile) int {
+		return compareNewest(a.info.ModTime(), a.relPath, b.info.ModTime(), b.relPath)
+	})
+
+	// Check the notes in growing batches, each loaded concurrently, until
+	// enough match; the notes of a batch past the last match returned are
+	// left for the next call
+	limit := opts.FirstN
+	if limit <= 0 {
+		limit = len(files)
+	}
+	match := v.types.filter(search.scope.Type, search.match)
+	report := progressFrom(ctx)
+	page := SearchPage{Notes: []NoteInfo{}}
+	checked := 0
+	partial := false
+	for size := max(v.concurrency, limit); checked < len(files) && len(page.Notes) < limit; size *= 2 {
+		batch := files[checked:min(checked+size, len(files))]
+		batchCtx := searchCtx
+		if report != nil {
+			offset := checked
+			batchCtx = ProgressContext(searchCtx, func(done, _ int) { report(offset+done, len(files)) })
+		}
+		notes, _, err := v.processNotes(batchCtx, batch, search.scope.PreviewLength, match)
+		if timedOut(err) {
+			partial = true // The batch is checked again when resumed
+			break
+		}
+		if err != nil {
+			return SearchPage{}, err
+		}
+		if need := limit - len(page.Notes); len(notes) >= need {
+			notes = notes[:need]
+			last := notes[need-1].Path
+			batch = batch[:slices.IndexFunc(batch, func(file noteFile) bool { return file.relPath == last })+1]
+		}
+		page.Notes = append(page.Notes, notes...)
+		checked += len(batch)
+	}
+	page.Scanned = checked
+	page.Remaining = len(files) - checked
+
+	if page.Remaining > 0 {
+		page.NextCursor = cursor.next(files, checked, started).String()
+	}
+	if search.scope.IncludeAnnotations {
+		v.annotateNotes(page.Notes)
+	}
+	v.markPinned(ctx, page.Notes)
+
+	// sortNotes expects notes in path order, as walks return them
+	slices.SortFunc(page.Notes, func(a, b NoteInfo) int { return strings.Compare(a.Path, b.Path) })
+	if err := sortNotes(page.Notes, opts.Sort, opts.Collation, opts.PinnedFirst, search.score); err != nil {
+		return SearchPage{}, err
+	}
+	if partial {
+		return page, &PartialResultsError{Scanned: checked, Total: len(files)}
+	}
+	return page, nil
+}
+
+// next returns the cursor resuming a search that checked files[:checked]
+// of the remaining files, in the order they were checked, in a call that
+// started at started
+func (c searchCursor) next(files []noteFile, checked int, started time.Time) searchCursor {
+	if checked == 0 {
+		return c
+	}
+	last := files[checked-1]
+	if c.past(last) {
+		// Every note left is past the last one checked
+		return searchCursor{Modified: last.info.ModTime(), Path: last.relPath, Since: started}
+	}
+
+	// The call stopped among notes modified since the previous call or
+	// pending from it; those left join the pending ones
+	next := searchCursor{Modified: c.Modified, Path: c.Path, Since: started}
+	for _, file := range files[checked:] {
+		if !c.past(file) {
+			next.Pending = append(next.Pending, file.relPath)
+		}
+	}
+	return next
+}
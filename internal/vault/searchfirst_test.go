@@ -0,0 +1,91 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSearchFirst(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+
+	// note1 to note6, newest last; note3 does not match
+	base := time.Now().Add(-time.Hour)
+	touch := func(name string, modified time.Time) {
+		t.Helper()
+		if err := os.Chtimes(filepath.Join(tmpDir, name), modified, modified); err != nil {
+			t.Fatalf("Failed to set times: %v", err)
+		}
+	}
+	for i := 1; i <= 6; i++ {
+		name := fmt.Sprintf("note%d.md", i)
+		content := "Meeting notes"
+		if i == 3 {
+			content = "Shopping list"
+		}
+		writeFiles(t, tmpDir, map[string]string{name: content})
+		touch(name, base.Add(time.Duration(i)*time.Minute))
+	}
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+
+	search := func(firstN int, cursor string) SearchPage {
+		t.Helper()
+		page, err := v.SearchFirst(ctx, SearchOptions{Query: "meeting", FirstN: firstN, Cursor: cursor})
+		if err != nil {
+			t.Fatalf("SearchFirst() error = %v", err)
+		}
+		return page
+	}
+
+	// The newest matches come first, and the cursor resumes after them
+	page := search(2, "")
+	if got := notePaths(page.Notes); !slices.Equal(got, []string{"note6.md", "note5.md"}) || page.NextCursor == "" || page.Scanned != 2 || page.Remaining != 4 {
+		t.Fatalf("SearchFirst() = %v, scanned %d, remaining %d, cursor %q", got, page.Scanned, page.Remaining, page.NextCursor)
+	}
+	page = search(2, page.NextCursor)
+	if got := notePaths(page.Notes); !slices.Equal(got, []string{"note4.md", "note2.md"}) || page.Scanned != 3 {
+		t.Errorf("Resumed SearchFirst() = %v, scanned %d; want note4 and note2 past note3", got, page.Scanned)
+	}
+
+	// Between calls, a note already returned is modified, a pending one is
+	// deleted and a new one is created
+	cursor := search(2, "").NextCursor
+	time.Sleep(10 * time.Millisecond)
+	touch("note6.md", time.Now())
+	if err := os.Remove(filepath.Join(tmpDir, "note4.md")); err != nil {
+		t.Fatalf("Failed to delete note: %v", err)
+	}
+	writeFiles(t, tmpDir, map[string]string{"note7.md": "Meeting again"})
+
+	// Stopping among the notes changed since the previous call leaves the
+	// rest of them pending
+	page = search(1, cursor)
+	if got := notePaths(page.Notes); !slices.Equal(got, []string{"note7.md"}) || page.NextCursor == "" {
+		t.Fatalf("SearchFirst() after changes = %v, cursor %q; want the new note", got, page.NextCursor)
+	}
+	page = search(0, page.NextCursor)
+	if got := notePaths(page.Notes); !slices.Equal(got, []string{"note6.md", "note2.md", "note1.md"}) || page.NextCursor != "" || page.Remaining != 0 {
+		t.Errorf("SearchFirst() of the rest = %v, cursor %q; want the modified note again and the older ones", got, page.NextCursor)
+	}
+
+	// An explicit sort orders the page
+	page, err = v.SearchFirst(ctx, SearchOptions{Query: "meeting", FirstN: 3, Sort: SortPath})
+	if got := notePaths(page.Notes); err != nil || !slices.Equal(got, []string{"note5.md", "note6.md", "note7.md"}) {
+		t.Errorf("SearchFirst() sorted by path = %v, %v", got, err)
+	}
+
+	for _, cursor := range []string{"bogus", ChunkCursor("note1.md")} {
+		if _, err := v.SearchFirst(ctx, SearchOptions{Query: "meeting", FirstN: 1, Cursor: cursor}); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("SearchFirst(%q) error = %v, want ErrInvalidCursor", cursor, err)
+		}
+	}
+}
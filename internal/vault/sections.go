@@ -0,0 +1,277 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Block is a part of a note marked with a ^block-id so [[Note#^id]] can
+// link to it: the marked line for headings, the item with its nested
+// items for lists, the whole callout for callouts, and the whole paragraph
+// otherwise. A marker on a line of its own marks the block above it.
+type Block struct {
+	ID        string `json:"id"`
+	Text      string `json:"text"`       // Block content without the marker
+	StartLine int    `json:"start_line"` // 1-based first line of the block
+	EndLine   int    `json:"end_line"`   // 1-based last line of the block, inclusive
+}
+
+// SectionOptions addresses part of a note; set exactly one field
+type SectionOptions struct {
+	Heading string // Heading text; "Parent#Child" picks a nested heading
+	Block   string // Block ID, with or without the leading ^
+}
+
+// Section is part of a note returned by ReadSection
+type Section struct {
+	Path      string   `json:"path"`
+	Content   string   `json:"content"`            // The lines of the section, verbatim
+	StartLine int      `json:"start_line"`         // 1-based
+	EndLine   int      `json:"end_line"`           // 1-based, inclusive
+	Warnings  []string `json:"warnings,omitempty"` // E.g. a block ID defined more than once
+}
+
+// blockIDRegex matches a ^block-id marker at the end of a line
+var blockIDRegex = regexp.MustCompile(`(?:^|\s)\^([A-Za-z0-9-]+)\s*$`)
+
+// sourceLine is a line of a note with its place in the markdown structure
+type sourceLine struct {
+	text string
+	num  int  // 1-based line number
+	code bool // Inside or delimiting a fenced code block
+}
+
+// noteLines returns the lines of content after the frontmatter
+func noteLines(content string) []sourceLine {
+	var lines []sourceLine
+	markdownLines(content, func(line string, lineNum int, code bool) {
+		lines = append(lines, sourceLine{line, lineNum, code})
+	})
+	return lines
+}
+
+// isBlank reports whether the line ends a paragraph
+func (l sourceLine) isBlank() bool {
+	return !l.code && strings.TrimSpace(l.text) == ""
+}
+
+// ParseBlocks returns the blocks of markdown content marked with ^block-id
+// in line order. Markers inside code blocks and frontmatter are ignored.
+func ParseBlocks(content string) []Block {
+	blocks := []Block{}
+	lines := noteLines(content)
+	callouts := ParseCallouts(content)
+
+	for i, line := range lines {
+		if line.code {
+			continue
+		}
+		m := blockIDRegex.FindStringSubmatchIndex(line.text)
+		if m == nil {
+			continue
+		}
+		id := line.text[m[2]:m[3]]
+		marked := strings.TrimRight(line.text[:m[0]], " \t")
+
+		start, end := i, i
+		switch {
+		case strings.TrimSpace(marked) == "":
+			// A marker on its own line names the block just above it,
+			// which may be a whole code block
+			end = i - 1
+			start = end
+			for start > 0 && !lines[start-1].isBlank() && lines[start-1].code == lines[end].code {
+				start--
+			}
+			if end < 0 || lines[end].isBlank() {
+				continue
+			}
+		case headingRegex.MatchString(marked):
+		case listItemRegex.MatchString(marked):
+			// The item with its nested items
+			indent := indentWidth(listItemRegex.FindStringSubmatch(marked)[1])
+			for end+1 < len(lines) && !lines[end+1].isBlank() &&
+				indentWidth(leadingSpace(lines[end+1].text)) > indent {
+				end++
+			}
+		default:
+			// The paragraph ending at the marker
+			for start > 0 && !lines[start-1].isBlank() && !lines[start-1].code &&
+				!headingRegex.MatchString(lines[start-1].text) && !listItemRegex.MatchString(lines[start-1].text) {
+				start--
+			}
+		}
+		// A callout is one block, however many paragraphs it holds
+		if c, ok := outerCallout(callouts, lines[end].num); ok {
+			start = min(start, c.StartLine-lines[0].num)
+			end = max(end, c.EndLine-lines[0].num)
+		}
+
+		text := make([]string, 0, end-start+1)
+		for j := start; j <= end; j++ {
+			if j == i {
+				text = append(text, marked)
+			} else {
+				text = append(text, lines[j].text)
+			}
+		}
+		blocks = append(blocks, Block{
+			ID:        id,
+			Text:      strings.TrimSpace(strings.Join(text, "\n")),
+			StartLine: lines[start].num,
+			EndLine:   lines[end].num,
+		})
+	}
+
+	return blocks
+}
+
+// leadingSpace returns the indentation of line
+func leadingSpace(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// duplicateBlockWarnings describes the block IDs defined more than once
+func duplicateBlockWarnings(blocks []Block) []string {
+	var warnings []string
+	lines := make(map[string][]int)
+	var order []string
+	for _, block := range blocks {
+		id := strings.ToLower(block.ID)
+		if _, seen := lines[id]; !seen {
+			order = append(order, id)
+		}
+		lines[id] = append(lines[id], block.StartLine)
+	}
+	for _, id := range order {
+		if len(lines[id]) > 1 {
+			warnings = append(warnings, fmt.Sprintf("block ID ^%s is defined %d times, at lines %s; links to it are ambiguous",
+				id, len(lines[id]), joinInts(lines[id])))
+		}
+	}
+	return warnings
+}
+
+// joinInts formats numbers as a comma-separated list
+func joinInts(numbers []int) string {
+	parts := make([]string, len(numbers))
+	for i, n := range numbers {
+		parts[i] = fmt.Sprint(n)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// findBlock returns the first block with the ID, matched case-insensitively,
+// and warnings when the ID is defined more than once
+func findBlock(blocks []Block, id string) (Block, []string, bool) {
+	id = strings.TrimPrefix(id, "^")
+	var found []Block
+	for _, block := range blocks {
+		if strings.EqualFold(block.ID, id) {
+			found = append(found, block)
+		}
+	}
+	if len(found) == 0 {
+		return Block{}, nil, false
+	}
+	return found[0], duplicateBlockWarnings(found), true
+}
+
+// findHeadingSection returns the 1-based line range from the heading named
+// heading up to the next heading of the same or a higher level. Nested
+// anchors such as "Plan#Goals" match the last heading. Headings compare
+// case-insensitively; code blocks and frontmatter are skipped.
+func findHeadingSection(content, heading string) (int, int, bool) {
+	if i := strings.LastIndex(heading, "#"); i >= 0 {
+		heading = heading[i+1:]
+	}
+	heading = strings.TrimSpace(heading)
+
+	lines := noteLines(content)
+	start, level := -1, 0
+	for _, line := range lines {
+		if line.code {
+			continue
+		}
+		m := headingRegex.FindStringSubmatch(strings.TrimSpace(line.text))
+		if m == nil {
+			continue
+		}
+		if start >= 0 {
+			if len(m[1]) <= level {
+				return start, line.num - 1, true
+			}
+			continue
+		}
+		if strings.EqualFold(m[2], heading) {
+			start, level = line.num, len(m[1])
+		}
+	}
+
+	if start < 0 {
+		return 0, 0, false
+	}
+	return start, lines[len(lines)-1].num, true
+}
+
+// lineRange returns lines start to end of content, 1-based and inclusive
+func lineRange(content string, start, end int) string {
+	lines := strings.Split(content, "\n")
+	return strings.Join(lines[start-1:end], "\n")
+}
+
+// ReadSection returns the part of a note under a heading, or the block
+// with an ID
+func (v *vault) ReadSection(ctx context.Context, path string, opts SectionOptions) (Section, error) {
+	if (opts.Heading == "") == (opts.Block == "") {
+		return Section{}, fmt.Errorf("%w: address a section by heading or by block", ErrInvalidPath)
+	}
+
+	fullPath, err := v.validatePath(path)
+	if err != nil {
+		return Section{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return Section{}, err
+	}
+
+	stat, err := os.Stat(fullPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Section{}, v.noteNotFound(ctx, fullPath)
+		}
+		return Section{}, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	entry, err := v.loadEntry(fullPath, stat.ModTime())
+	if err != nil {
+		return Section{}, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return findSection(v.relPath(fullPath), entry.Content, entry.Blocks, opts)
+}
+
+// findSection returns the section of content, parsed into blocks, that
+// opts addresses. name is where the content came from, for errors.
+func findSection(name, content string, blocks []Block, opts SectionOptions) (Section, error) {
+	section := Section{Path: name}
+	if opts.Block != "" {
+		block, warnings, ok := findBlock(blocks, opts.Block)
+		if !ok {
+			return Section{}, fmt.Errorf("%w: ^%s in %s", ErrSectionNotFound, strings.TrimPrefix(opts.Block, "^"), name)
+		}
+		section.StartLine, section.EndLine, section.Warnings = block.StartLine, block.EndLine, warnings
+	} else {
+		start, end, ok := findHeadingSection(content, opts.Heading)
+		if !ok {
+			return Section{}, fmt.Errorf("%w: #%s in %s", ErrSectionNotFound, opts.Heading, name)
+		}
+		section.StartLine, section.EndLine = start, end
+	}
+
+	section.Content = strings.TrimRight(lineRange(content, section.StartLine, section.EndLine), "\r\n")
+	return section, nil
+}
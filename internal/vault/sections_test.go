@@ -0,0 +1,139 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseBlocks(t *testing.T) {
+	content := strings.Join([]string{
+		"---",
+		"id: ^notablock",
+		"---",
+		"First line of a paragraph",
+		"that ends here ^para",
+		"",
+		"## Heading ^head",
+		"- item one ^item",
+		"  - nested child",
+		"- item two",
+		"",
+		"> quoted",
+		"> lines",
+		"^quote",
+		"",
+		"```",
+		"code ^incode",
+		"```",
+		"Not a block^glued",
+		"Dash ok ^with-dash-1",
+		"^orphan",
+	}, "\n")
+
+	got := ParseBlocks(content)
+	want := []Block{
+		{ID: "para", Text: "First line of a paragraph\nthat ends here", StartLine: 4, EndLine: 5},
+		{ID: "head", Text: "## Heading", StartLine: 7, EndLine: 7},
+		{ID: "item", Text: "- item one\n  - nested child", StartLine: 8, EndLine: 9},
+		{ID: "quote", Text: "> quoted\n> lines", StartLine: 12, EndLine: 13},
+		{ID: "with-dash-1", Text: "Not a block^glued\nDash ok", StartLine: 19, EndLine: 20},
+		{ID: "orphan", Text: "Not a block^glued\nDash ok ^with-dash-1", StartLine: 19, EndLine: 20},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseBlocks() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestFindHeadingSection(t *testing.T) {
+	content := "---\ntitle: x\n---\nIntro\n# Title\n## Goals ##\nShip\n```\n# not a heading\n```\n### Sub\nDetail\n## Next\nLater"
+
+	tests := []struct {
+		heading    string
+		start, end int
+		found      bool
+	}{
+		{"Goals", 6, 12, true},
+		{"goals", 6, 12, true},
+		{"Title#Next", 13, 14, true},
+		{"Title", 5, 14, true},
+		{"title: x", 0, 0, false},
+		{"not a heading", 0, 0, false},
+		{"Missing", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.heading, func(t *testing.T) {
+			start, end, found := findHeadingSection(content, tt.heading)
+			if start != tt.start || end != tt.end || found != tt.found {
+				t.Errorf("findHeadingSection(%q) = %d, %d, %v; want %d, %d, %v", tt.heading, start, end, found, tt.start, tt.end, tt.found)
+			}
+		})
+	}
+}
+
+func TestReadSection(t *testing.T) {
+	v, tmpDir := setupTestVault(t)
+	ctx := context.Background()
+
+	content := "# Plan\nIntro\n## Quotes\n> Be brief ^quote1\n\nOther ^quote1\n## End\nDone"
+	if err := os.WriteFile(filepath.Join(tmpDir, "plan.md"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+
+	t.Run("heading", func(t *testing.T) {
+		section, err := v.ReadSection(ctx, "plan.md", SectionOptions{Heading: "Quotes"})
+		if err != nil {
+			t.Fatalf("ReadSection() error = %v", err)
+		}
+		want := Section{Path: "plan.md", Content: "## Quotes\n> Be brief ^quote1\n\nOther ^quote1", StartLine: 3, EndLine: 6}
+		if !reflect.DeepEqual(section, want) {
+			t.Errorf("ReadSection() = %+v, want %+v", section, want)
+		}
+	})
+
+	t.Run("duplicate block", func(t *testing.T) {
+		section, err := v.ReadSection(ctx, "plan.md", SectionOptions{Block: "^QUOTE1"})
+		if err != nil {
+			t.Fatalf("ReadSection() error = %v", err)
+		}
+		if section.Content != "> Be brief ^quote1" || section.StartLine != 4 || len(section.Warnings) != 1 ||
+			!strings.Contains(section.Warnings[0], "lines 4, 6") {
+			t.Errorf("ReadSection() = %+v", section)
+		}
+	})
+
+	t.Run("analysis lists blocks", func(t *testing.T) {
+		analysis, err := v.Analyze(ctx, "plan.md")
+		if err != nil {
+			t.Fatalf("Analyze() error = %v", err)
+		}
+		if len(analysis.Blocks) != 2 || len(analysis.Warnings) != 1 {
+			t.Errorf("Analyze() blocks = %+v, warnings = %v", analysis.Blocks, analysis.Warnings)
+		}
+	})
+
+	tests := []struct {
+		name string
+		path string
+		opts SectionOptions
+		want error
+	}{
+		{"missing heading", "plan.md", SectionOptions{Heading: "Nope"}, ErrSectionNotFound},
+		{"missing block", "plan.md", SectionOptions{Block: "nope"}, ErrSectionNotFound},
+		{"missing note", "nope.md", SectionOptions{Heading: "Plan"}, ErrNoteNotFound},
+		{"no address", "plan.md", SectionOptions{}, ErrInvalidPath},
+		{"both addresses", "plan.md", SectionOptions{Heading: "Plan", Block: "quote1"}, ErrInvalidPath},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := v.ReadSection(ctx, tt.path, tt.opts); !errors.Is(err, tt.want) {
+				t.Errorf("ReadSection() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// SimilarMode tells create_note what to do when notes with a name close
+// to the new note's already exist
+type SimilarMode string
+
+// What to do about similar notes
+const (
+	SimilarError  SimilarMode = "error"  // Refuse to create the note
+	SimilarWarn   SimilarMode = "warn"   // Create the note and report the similar ones
+	SimilarIgnore SimilarMode = "ignore" // Do not look for similar notes
+)
+
+// ParseSimilarMode validates a similar note mode, defaulting to
+// SimilarError
+func ParseSimilarMode(s string) (SimilarMode, error) {
+	switch mode := SimilarMode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case "":
+		return SimilarError, nil
+	case SimilarError, SimilarWarn, SimilarIgnore:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown mode %q (want error, warn or ignore)", s)
+	}
+}
+
+// SimilarNote is an existing note whose name is close to a new note's
+type SimilarNote struct {
+	Path      string    `json:"path"`       // Vault-relative path of the existing note
+	MatchedBy MatchKind `json:"matched_by"` // filename, title or alias
+	Name      string    `json:"name"`       // The file name, title or alias that matched
+	Distance  int       `json:"distance"`   // Edits between the normalized names, 0 when they are equal
+}
+
+// slugName normalizes a note name for comparison: compatibility
+// decomposed, with accents dropped, case folded and every character but
+// letters and digits removed, so "Kubernetes Setup" and
+// "kubernetes-setup" are the same name
+func slugName(name string) string {
+	var b strings.Builder
+	for _, r := range norm.NFKD.String(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(foldRune(r))
+		}
+	}
+	return b.String()
+}
+
+// similarNames returns the edits between two names normalized by slugName
+// and whether they count as similar. Short names must be equal, so "plan"
+// and "plans" stay distinct, and longer ones may be an edit apart per six
+// characters. Their digits must be the same, so numbered and dated notes
+// such as "2024-03-01" and "2024-03-02" are never similar.
+func similarNames(a, b string) (int, bool) {
+	notDigit := func(r rune) bool { return !unicode.IsDigit(r) }
+	if strings.Join(strings.FieldsFunc(a, notDigit), " ") != strings.Join(strings.FieldsFunc(b, notDigit), " ") {
+		return 0, false
+	}
+	distance := levenshtein(a, b)
+	return distance, distance <= len([]rune(a))/6
+}
+
+// SimilarNotes returns existing notes whose file name, frontmatter title
+// or alias is close to the name of the new note at path, closest first,
+// at most maxSuggestions. Names are compared after slugName as
+// similarNames does. File names come from the path listing of
+// FindNote and titles and aliases from notes already in the cache, so no
+// note is read. A note at path itself is left out.
+func (v *vault) SimilarNotes(ctx context.Context, notePath string) ([]SimilarNote, error) {
+	fullPath, err := v.validatePath(notePath)
+	if err != nil {
+		return nil, err
+	}
+	relPath := v.relPath(fullPath)
+	target := slugName(strings.TrimSuffix(path.Base(relPath), ".md"))
+	if target == "" {
+		return nil, nil
+	}
+
+	candidates, err := v.noteCandidates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var similar []SimilarNote
+	for _, c := range candidates {
+		if c.path == relPath {
+			continue
+		}
+		names := []SimilarNote{{MatchedBy: MatchFilename, Name: strings.TrimSuffix(path.Base(c.path), ".md")}}
+		if entry, ok := v.cache.Metadata(filepath.Join(v.basePath, filepath.FromSlash(c.path))); ok {
+			if title, source := v.noteTitle(c.path, entry); source != TitleFromFilename {
+				names = append(names, SimilarNote{MatchedBy: MatchTitle, Name: title})
+			}
+			for _, alias := range entry.Aliases {
+				names = append(names, SimilarNote{MatchedBy: MatchAlias, Name: alias})
+			}
+		}
+
+		// The closest of the note's names counts
+		best := SimilarNote{Distance: -1}
+		for _, name := range names {
+			distance, ok := similarNames(target, slugName(name.Name))
+			if ok && (best.Distance < 0 || distance < best.Distance) {
+				best, best.Distance = name, distance
+			}
+		}
+		if best.Distance >= 0 {
+			best.Path = c.path
+			similar = append(similar, best)
+		}
+	}
+
+	sort.Slice(similar, func(i, j int) bool {
+		a, b := similar[i], similar[j]
+		if a.Distance != b.Distance {
+			return a.Distance < b.Distance
+		}
+		if matchRank[a.MatchedBy] != matchRank[b.MatchedBy] {
+			return matchRank[a.MatchedBy] < matchRank[b.MatchedBy]
+		}
+		return a.Path < b.Path
+	})
+	return similar[:min(len(similar), maxSuggestions)], nil
+}
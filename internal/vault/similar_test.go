@@ -0,0 +1,56 @@
+package vault
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestSimilarNotes(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"Ops/kubernetes-setup.md": "Setup",
+		"Cafe\u0301 Menu.md":      "Decomposed accent",
+		"Daily/2024-03-01.md":     "Day",
+		"People/ann.md":           "---\ntitle: Annabel Smith\naliases: [Project Zeus]\n---\n",
+		"plan.md":                 "Plan",
+	})
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	// Titles and aliases are only known for notes already read
+	if _, err := v.List(ctx, ListOptions{Recursive: true}); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want []string
+		kind MatchKind
+	}{
+		{"Kubernetes Setup.md", []string{"Ops/kubernetes-setup.md"}, MatchFilename},
+		{"Notes/kubernetes_setpu.md", []string{"Ops/kubernetes-setup.md"}, MatchFilename},
+		{"Caf\u00e9 menu.md", []string{"Cafe\u0301 Menu.md"}, MatchFilename},
+		{"Cafe Menu.md", []string{"Cafe\u0301 Menu.md"}, MatchFilename},
+		{"annabel-smith.md", []string{"People/ann.md"}, MatchTitle},
+		{"Projects/Project ZEUS.md", []string{"People/ann.md"}, MatchAlias},
+		{"Daily/2024-03-02.md", nil, ""},
+		{"plans.md", nil, ""},
+		{"Ops/kubernetes-setup.md", nil, ""}, // The note itself
+	}
+	for _, tt := range tests {
+		similar, err := v.SimilarNotes(ctx, tt.path)
+		if err != nil {
+			t.Fatalf("SimilarNotes(%s) error = %v", tt.path, err)
+		}
+		var paths []string
+		for _, note := range similar {
+			paths = append(paths, note.Path)
+		}
+		if !slices.Equal(paths, tt.want) || (len(similar) > 0 && similar[0].MatchedBy != tt.kind) {
+			t.Errorf("SimilarNotes(%s) = %+v, want %v matched by %s", tt.path, similar, tt.want, tt.kind)
+		}
+	}
+}
@@ -0,0 +1,397 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SplitIndexMode selects where SplitNote lists the notes it creates
+type SplitIndexMode string
+
+// Index modes
+const (
+	SplitKeepSource    SplitIndexMode = "keep_source"               // Leave the source as is and write a separate index note
+	SplitReplaceSource SplitIndexMode = "replace_source_with_links" // Replace each split section of the source with a link
+)
+
+// defaultSplitHeadingLevel is the heading level SplitNote splits at by
+// default
+const defaultSplitHeadingLevel = 2
+
+// splitParentField is the frontmatter property linking each created note
+// back to the source when its frontmatter is not copied
+const splitParentField = "parent"
+
+// SplitNoteOptions describes the split of a note into one note per section
+type SplitNoteOptions struct {
+	Path            string         // Note to split
+	HeadingLevel    int            // Headings of this level start a new note, 2 when 0
+	TargetFolder    string         // Folder of the new notes; empty for a folder named after the note, next to it
+	IndexMode       SplitIndexMode // Where the links to the new notes go, keep_source when empty
+	CopyFrontmatter bool           // Give each new note the source's frontmatter instead of a parent link
+	DryRun          bool           // Plan the split without writing anything
+}
+
+// SplitSection is one section of the source and the note it becomes
+type SplitSection struct {
+	Heading string `json:"heading"`
+	Path    string `json:"path"` // Created note, vault-relative
+	Line    int    `json:"line"` // 1-based line of the heading in the source
+	Bytes   int    `json:"bytes"`
+}
+
+// SplitResult reports the outcome of SplitNote
+type SplitResult struct {
+	Path      string         `json:"path"`
+	IndexMode SplitIndexMode `json:"index_mode"`
+	Index     string         `json:"index"` // Note listing the links: the source, or the created index note
+	DryRun    bool           `json:"dry_run,omitempty"`
+	Sections  []SplitSection `json:"sections"` // In the order of the source
+}
+
+// ParseSplitIndexMode validates an index mode, defaulting to keep_source
+func ParseSplitIndexMode(s string) (SplitIndexMode, error) {
+	switch mode := SplitIndexMode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case "":
+		return SplitKeepSource, nil
+	case SplitKeepSource, SplitReplaceSource:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown index mode %q (want keep_source or replace_source_with_links)", s)
+	}
+}
+
+// SplitNote moves every section of a note starting at a heading of
+// opts.HeadingLevel, up to the next heading of that level or higher, into
+// a note of its own named after the heading. Sections are copied
+// verbatim, sub-headings included, with links rewritten only where they
+// would otherwise resolve to another note from the new folder. The links
+// to the new notes, in the order of the source, go into an index note or
+// replace the sections in the source. All notes are written or none: if
+// one write fails, the ones before it are undone.
+func (v *vault) SplitNote(ctx context.Context, opts SplitNoteOptions) (SplitResult, error) {
+	mode, err := ParseSplitIndexMode(string(opts.IndexMode))
+	if err != nil {
+		return SplitResult{}, err
+	}
+	level := opts.HeadingLevel
+	if level == 0 {
+		level = defaultSplitHeadingLevel
+	}
+	if level < 1 || level > 6 {
+		return SplitResult{}, fmt.Errorf("heading level %d out of range (want 1 to 6)", level)
+	}
+	opts.IndexMode, opts.HeadingLevel = mode, level
+
+	fullPath, err := v.validatePath(opts.Path)
+	if err != nil {
+		return SplitResult{}, err
+	}
+
+	index, err := v.buildFileIndex(ctx)
+	if err != nil {
+		return SplitResult{}, err
+	}
+	plan, err := v.planSplit(index, fullPath, opts)
+	if err != nil {
+		return SplitResult{}, err
+	}
+	if opts.DryRun {
+		return plan.result, nil
+	}
+
+	// Every note written must be writable, the source only when rewritten
+	var lockPaths []string
+	for _, step := range plan.steps {
+		lockPaths = append(lockPaths, step.fullPath)
+	}
+	if err := v.checkWritable(lockPaths...); err != nil {
+		return SplitResult{}, err
+	}
+	if err := v.checkLeases(ctx, lockPaths...); err != nil {
+		return SplitResult{}, err
+	}
+	if err := v.checkAudit(); err != nil {
+		return SplitResult{}, err
+	}
+
+	unlock := v.writeLocks.lock(append(lockPaths, fullPath)...)
+	defer unlock()
+
+	// Plan again under the lock in case the source changed or one of the
+	// new paths was taken meanwhile
+	if index, err = v.buildFileIndex(ctx); err != nil {
+		return SplitResult{}, err
+	}
+	again, err := v.planSplit(index, fullPath, opts)
+	if err != nil {
+		return SplitResult{}, err
+	}
+	if !slices.EqualFunc(plan.steps, again.steps, func(a, b batchStep) bool { return a.fullPath == b.fullPath }) {
+		return SplitResult{}, fmt.Errorf("%w: the notes around %s changed while splitting it, try again", ErrNoteExists, plan.result.Path)
+	}
+	plan = again
+	if err := ctx.Err(); err != nil {
+		return SplitResult{}, err
+	}
+
+	var undo []func() error
+	for i, step := range plan.steps {
+		if _, err := v.applyStep(step, &undo); err != nil {
+			return SplitResult{}, &BatchError{
+				Problems:    []EditProblem{{Index: i, Op: step.op, Path: v.relPath(step.fullPath), Err: err}},
+				RolledBack:  true,
+				NotRestored: v.rollback(undo),
+			}
+		}
+	}
+	v.paths.invalidate()
+
+	audit := make([]AuditEntry, len(plan.steps))
+	for i, step := range plan.steps {
+		audit[i] = AuditEntry{Op: step.op, Path: v.relPath(step.fullPath), Bytes: len(step.content), Hash: contentHash(step.content)}
+		if step.op == EditUpdate {
+			audit[i].PrevHash = plan.sourceHash
+		}
+	}
+	return plan.result, v.record(ctx, audit...)
+}
+
+// splitPlan is the notes a split writes, created notes first so a
+// rewritten source comes last
+type splitPlan struct {
+	steps      []batchStep
+	sourceHash string // Content hash of the source as planned
+	result     SplitResult
+}
+
+// splitSection is a section of the source, by 0-based line index
+type splitSection struct {
+	heading    string
+	start, end int // The heading line and the line after the section
+}
+
+// planSplit reads the source and plans the notes of the split against
+// the files in index
+func (v *vault) planSplit(index *fileIndex, fullPath string, opts SplitNoteOptions) (splitPlan, error) {
+	stat, err := statNote(fullPath, opts.Path)
+	if err != nil {
+		return splitPlan{}, err
+	}
+	entry, err := v.loadEntry(fullPath, stat.ModTime())
+	if err != nil {
+		return splitPlan{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	source := v.relPath(fullPath)
+	content := entry.Content
+
+	// A section runs to the next heading of the same or a higher level
+	lines := strings.Split(content, "\n")
+	headings := ParseHeadings(content)
+	var sections []splitSection
+	for i, h := range headings {
+		if h.Level != opts.HeadingLevel {
+			continue
+		}
+		s := splitSection{heading: h.Text, start: h.Line - 1, end: len(lines)}
+		for _, next := range headings[i+1:] {
+			if next.Level <= h.Level {
+				s.end = next.Line - 1
+				break
+			}
+		}
+		sections = append(sections, s)
+	}
+	if len(sections) == 0 {
+		return splitPlan{}, fmt.Errorf("%w: %s has no level %d headings to split at", ErrSectionNotFound, source, opts.HeadingLevel)
+	}
+
+	folder, err := v.splitFolder(source, opts.TargetFolder)
+	if err != nil {
+		return splitPlan{}, err
+	}
+
+	// Pick a free name for every new note, the index note last
+	planned := make(map[string]bool)
+	claim := func(name string) string {
+		candidate := path.Join(folder, name+".md")
+		for n := 2; ; n++ {
+			lower := strings.ToLower(candidate)
+			if _, taken := index.paths[lower]; !taken && !planned[lower] {
+				planned[lower] = true
+				return candidate
+			}
+			candidate = path.Join(folder, fmt.Sprintf("%s %d.md", name, n))
+		}
+	}
+	result := SplitResult{Path: source, IndexMode: opts.IndexMode, Index: source, DryRun: opts.DryRun}
+	var created []string
+	for i, s := range sections {
+		notePath := claim(splitFileName(s.heading, i+1))
+		created = append(created, notePath)
+		result.Sections = append(result.Sections, SplitSection{Heading: s.heading, Path: notePath, Line: s.start + 1})
+	}
+	title := strings.TrimSuffix(path.Base(source), ".md")
+	if opts.IndexMode == SplitKeepSource {
+		result.Index = claim(title + " Index")
+		created = append(created, result.Index)
+	}
+	newIndex := index.withFiles(created...)
+
+	// Each new note starts with the source's frontmatter or a link to it
+	frontmatter := func(notePath string) (string, error) {
+		if opts.CopyFrontmatter {
+			_, body, _ := SplitFrontmatter(content)
+			return content[:len(content)-len(body)], nil
+		}
+		fields, err := yaml.Marshal(map[string]string{splitParentField: "[[" + newIndex.linkTo(notePath, source, false) + "]]"})
+		if err != nil {
+			return "", fmt.Errorf("failed to encode frontmatter: %w", err)
+		}
+		return "---\n" + string(fields) + "---\n", nil
+	}
+	create := func(notePath, body string) (batchStep, error) {
+		noteFull, err := v.validatePath(notePath)
+		if err != nil {
+			return batchStep{}, err
+		}
+		fields, err := frontmatter(notePath)
+		if err != nil {
+			return batchStep{}, err
+		}
+		body, err = v.PrepareContent(notePath, fields+body, true)
+		if err != nil {
+			return batchStep{}, err
+		}
+		return batchStep{op: EditCreate, fullPath: noteFull, content: body}, nil
+	}
+
+	plan := splitPlan{sourceHash: entry.ContentHash, result: result}
+	links := make([]string, len(sections))
+	for i, s := range sections {
+		notePath := result.Sections[i].Path
+		body, _ := relinkMoved(index, newIndex, source, notePath, "", "", sectionText(lines[s.start:s.end]))
+		step, err := create(notePath, body)
+		if err != nil {
+			return splitPlan{}, err
+		}
+		plan.steps = append(plan.steps, step)
+		plan.result.Sections[i].Bytes = len(step.content)
+		links[i] = splitLink(newIndex, result.Index, notePath, s.heading)
+	}
+
+	switch opts.IndexMode {
+	case SplitKeepSource:
+		if entry.Title != "" {
+			title = entry.Title
+		}
+		step, err := create(result.Index, "# "+title+"\n\n"+strings.Join(links, "\n")+"\n")
+		if err != nil {
+			return splitPlan{}, err
+		}
+		plan.steps = append(plan.steps, step)
+
+	case SplitReplaceSource:
+		rewritten, _ := relinkMoved(index, newIndex, source, source, "", "", replaceSections(lines, sections, links))
+		if rewritten, err = v.PrepareContent(source, rewritten, false); err != nil {
+			return splitPlan{}, err
+		}
+		plan.steps = append(plan.steps, batchStep{op: EditUpdate, fullPath: fullPath, content: rewritten})
+	}
+
+	return plan, nil
+}
+
+// splitFolder returns the vault-relative folder the notes split from
+// source go to: folder, or one named after source next to it. The folder
+// may not exist yet, but must not be a file.
+func (v *vault) splitFolder(source, folder string) (string, error) {
+	if folder == "" {
+		folder = strings.TrimSuffix(source, ".md")
+	}
+	if folder == rootFolder {
+		return "", nil
+	}
+	fullPath, err := v.validateFile(folder)
+	if err != nil {
+		return "", err
+	}
+	if stat, err := os.Stat(fullPath); err == nil && !stat.IsDir() {
+		return "", fmt.Errorf("%w: %s is a file, not a folder", ErrInvalidPath, folder)
+	}
+	if fullPath == v.basePath {
+		return "", nil
+	}
+	return v.relPath(fullPath), nil
+}
+
+// splitFileName returns the name, without extension, of the note for the
+// nth section, headed heading: the heading made safe as a file name, or
+// "Section n" when nothing usable is left of it
+func splitFileName(heading string, n int) string {
+	if name := linkableName(heading); name != "" {
+		return name
+	}
+	return fmt.Sprintf("Section %d", n)
+}
+
+// sectionText joins the lines of a section, without its trailing blank
+// lines, ending in a newline
+func sectionText(lines []string) string {
+	end := len(lines)
+	for end > 1 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	return strings.Join(lines[:end], "\n") + "\n"
+}
+
+// splitLink returns the list item linking the note at from to the note
+// split off as notePath, shown as heading when it can be
+func splitLink(index *fileIndex, from, notePath, heading string) string {
+	link := index.linkTo(from, notePath, false)
+	if strings.ContainsAny(heading, "[]|") {
+		heading = path.Base(link)
+	}
+	if heading == link {
+		return "- [[" + link + "]]"
+	}
+	return "- [[" + link + "|" + heading + "]]"
+}
+
+// replaceSections returns the source lines with each section replaced by
+// its link, consecutive links forming one list set apart by blank lines
+func replaceSections(lines []string, sections []splitSection, links []string) string {
+	var out []string
+	separate := func() {
+		if len(out) > 0 && strings.TrimSpace(out[len(out)-1]) != "" {
+			out = append(out, "")
+		}
+	}
+
+	prev := 0
+	for i, s := range sections {
+		if s.start > prev || i == 0 {
+			if i > 0 && strings.TrimSpace(lines[prev]) != "" {
+				separate()
+			}
+			out = append(out, lines[prev:s.start]...)
+			separate()
+		}
+		out = append(out, links[i])
+		prev = s.end
+	}
+	if prev < len(lines) && strings.TrimSpace(lines[prev]) != "" {
+		separate()
+	}
+	out = append(out, lines[prev:]...)
+	if out[len(out)-1] != "" {
+		out = append(out, "") // End with a newline
+	}
+	return strings.Join(out, "\n")
+}
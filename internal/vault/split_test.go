@@ -0,0 +1,156 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const splitSource = "---\ntags: [work]\n---\n# Projects\nIntro [[Alpha]]\n\n## Alpha\nFirst [[notes/ref]]\n\n### Tasks\n- [ ] ship\n\n## Beta: Q1/Q2\nSecond\n\n## Alpha\nAgain\n\n# Archive\nOld\n"
+
+// setupSplitVault creates a vault with a note to split, a note of the
+// same name as one of its sections and a note linked relatively
+func setupSplitVault(t *testing.T) (*vault, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"Projects.md":    splitSource,
+		"Other/Alpha.md": "Elsewhere",
+		"notes/ref.md":   "Reference",
+	})
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	return v.(*vault), tmpDir
+}
+
+func TestSplitNote(t *testing.T) {
+	ctx := context.Background()
+	wantSections := []SplitSection{
+		{Heading: "Alpha", Path: "Projects/Alpha.md", Line: 7},
+		{Heading: "Beta: Q1/Q2", Path: "Projects/Beta Q1 Q2.md", Line: 13},
+		{Heading: "Alpha", Path: "Projects/Alpha 2.md", Line: 16},
+	}
+	checkSections := func(t *testing.T, got []SplitSection) {
+		t.Helper()
+		if len(got) != len(wantSections) {
+			t.Fatalf("SplitNote() sections = %+v, want %+v", got, wantSections)
+		}
+		for i, want := range wantSections {
+			if got[i].Heading != want.Heading || got[i].Path != want.Path || got[i].Line != want.Line || got[i].Bytes == 0 {
+				t.Errorf("SplitNote() section %d = %+v, want %+v", i, got[i], want)
+			}
+		}
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		v, tmpDir := setupSplitVault(t)
+		result, err := v.SplitNote(ctx, SplitNoteOptions{Path: "Projects.md", DryRun: true})
+		if err != nil {
+			t.Fatalf("SplitNote() error = %v", err)
+		}
+		checkSections(t, result.Sections)
+		if result.Index != "Projects/Projects Index.md" || !result.DryRun || exists(tmpDir, "Projects") {
+			t.Errorf("SplitNote() = %+v; wrote Projects: %v", result, exists(tmpDir, "Projects"))
+		}
+	})
+
+	t.Run("keep source", func(t *testing.T) {
+		v, tmpDir := setupSplitVault(t)
+		result, err := v.SplitNote(ctx, SplitNoteOptions{Path: "Projects.md"})
+		if err != nil {
+			t.Fatalf("SplitNote() error = %v", err)
+		}
+		checkSections(t, result.Sections)
+
+		if got := readFile(t, tmpDir, "Projects.md"); got != splitSource {
+			t.Errorf("source = %q, want it unchanged", got)
+		}
+		// The relative link is rewritten to keep resolving from the folder
+		if got, want := readFile(t, tmpDir, "Projects/Alpha.md"), "---\nparent: '[[Projects]]'\n---\n## Alpha\nFirst [[notes/ref]]\n\n### Tasks\n- [ ] ship\n"; got != want {
+			t.Errorf("Alpha.md = %q, want %q", got, want)
+		}
+		if got, want := readFile(t, tmpDir, "Projects/Projects Index.md"), "---\nparent: '[[Projects]]'\n---\n# Projects\n\n- [[Alpha]]\n- [[Beta Q1 Q2|Beta: Q1/Q2]]\n- [[Alpha 2|Alpha]]\n"; got != want {
+			t.Errorf("index = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("replace source", func(t *testing.T) {
+		v, tmpDir := setupSplitVault(t)
+		_, err := v.SplitNote(ctx, SplitNoteOptions{Path: "Projects.md", TargetFolder: "Split", IndexMode: SplitReplaceSource, CopyFrontmatter: true})
+		if err != nil {
+			t.Fatalf("SplitNote() error = %v", err)
+		}
+
+		want := "---\ntags: [work]\n---\n# Projects\nIntro [[Alpha]]\n\n- [[Split/Alpha|Alpha]]\n- [[Beta Q1 Q2|Beta: Q1/Q2]]\n- [[Alpha 2|Alpha]]\n\n# Archive\nOld\n"
+		if got := readFile(t, tmpDir, "Projects.md"); got != want {
+			t.Errorf("source = %q, want %q", got, want)
+		}
+		if got, want := readFile(t, tmpDir, "Split/Alpha 2.md"), "---\ntags: [work]\n---\n## Alpha\nAgain\n"; got != want {
+			t.Errorf("Alpha 2.md = %q, want %q", got, want)
+		}
+		if entries, err := v.AuditLog(ctx, AuditQuery{}); err != nil || len(entries) != 4 {
+			t.Errorf("AuditLog() = %+v, %v; want 3 creates and an update", entries, err)
+		}
+	})
+
+	t.Run("rolls back when a write fails", func(t *testing.T) {
+		v, tmpDir := setupSplitVault(t)
+		// A file where the backups of the source belong makes its update fail
+		blocker := v.backupPath("Projects.md")
+		if err := os.MkdirAll(filepath.Dir(blocker), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(blocker, nil, 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+
+		_, err := v.SplitNote(ctx, SplitNoteOptions{Path: "Projects.md", IndexMode: SplitReplaceSource})
+		var batchErr *BatchError
+		if !errors.As(err, &batchErr) || !batchErr.RolledBack || len(batchErr.NotRestored) > 0 {
+			t.Fatalf("SplitNote() error = %v, want a rolled back BatchError", err)
+		}
+		if exists(tmpDir, "Projects") || readFile(t, tmpDir, "Projects.md") != splitSource {
+			t.Error("SplitNote() left the vault changed")
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		v, _ := setupSplitVault(t)
+		for _, tc := range []struct {
+			opts SplitNoteOptions
+			want error
+		}{
+			{SplitNoteOptions{Path: "missing.md"}, ErrNoteNotFound},
+			{SplitNoteOptions{Path: "Projects.md", HeadingLevel: 4}, ErrSectionNotFound},
+			{SplitNoteOptions{Path: "Projects.md", TargetFolder: "notes/ref.md"}, ErrInvalidPath},
+			{SplitNoteOptions{Path: "Projects.md", TargetFolder: "../out"}, ErrPathTraversal},
+		} {
+			if _, err := v.SplitNote(ctx, tc.opts); !errors.Is(err, tc.want) {
+				t.Errorf("SplitNote(%+v) error = %v, want %v", tc.opts, err, tc.want)
+			}
+		}
+	})
+}
+
+func TestSplitFileName(t *testing.T) {
+	tests := []struct {
+		heading string
+		want    string
+	}{
+		{"Alpha", "Alpha"},
+		{"Phase 1: Design", "Phase 1 Design"},
+		{"[[Linked]] #tag", "Linked tag"},
+		{"...", "Section 3"},
+		{"CON", "Section 3"},
+		{"Café", "Café"},
+	}
+	for _, tt := range tests {
+		if got := splitFileName(tt.heading, 3); got != tt.want {
+			t.Errorf("splitFileName(%q) = %q, want %q", tt.heading, got, tt.want)
+		}
+	}
+}
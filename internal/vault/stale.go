@@ -0,0 +1,174 @@
+package vault
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultStaleLimit is the number of stale notes returned by default
+const defaultStaleLimit = 50
+
+// defaultModifiedFields are the frontmatter properties checked, in order,
+// for when a note was last changed once file times cannot be trusted
+var defaultModifiedFields = []string{"modified", "updated"}
+
+// Modification times are judged unreliable when at least
+// unreliableMtimeShare of at least unreliableMtimeNotes notes share the
+// same second, as after copying a vault without preserving times
+const (
+	unreliableMtimeNotes = 10
+	unreliableMtimeShare = 0.5
+)
+
+// StaleOptions selects the notes Stale reports
+type StaleOptions struct {
+	Subpath         string    // Directory to look in, empty for the whole vault
+	Before          time.Time // Notes last changed before this time are stale
+	MaxInboundLinks int       // Notes linked from more other notes are not stale
+	ExcludeTags     []string  // Notes with any of these tags are never stale
+	Limit           int       // Maximum results, 0 or less for the default of 50
+}
+
+// StaleNote is a note flagged by Stale, with the signals that flagged it
+type StaleNote struct {
+	Path         string    `json:"path"`
+	LastModified time.Time `json:"last_modified"`
+	ModifiedFrom string    `json:"modified_from"` // "mtime", or the frontmatter property LastModified was read from
+	DaysStale    int       `json:"days_stale"`    // Whole days since LastModified
+	InboundLinks int       `json:"inbound_links"` // Other notes and canvases linking here
+	Tags         []string  `json:"tags"`
+}
+
+// StaleReport is the result of Stale
+type StaleReport struct {
+	Notes []StaleNote `json:"notes"` // Stalest first
+	Total int         `json:"total"` // Stale notes found, before the limit
+
+	// UnreliableMtimes is set when most notes share one modification time;
+	// notes carrying it are dated from their frontmatter where possible
+	UnreliableMtimes bool `json:"unreliable_mtimes,omitempty"`
+}
+
+// staleCandidate is a note under the requested folder before filtering
+type staleCandidate struct {
+	path       string
+	mtime      time.Time
+	properties map[string]any
+	tags       []string
+}
+
+// Stale finds notes under opts.Subpath last changed before opts.Before,
+// linked from at most opts.MaxInboundLinks other notes and tagged with
+// none of opts.ExcludeTags, stalest first. Tags, frontmatter and links
+// come from the note cache; inbound links are counted across the whole
+// vault, canvas text cards included, in the same walk. When modification
+// times look reset by a migration, notes carrying the shared time are
+// dated by a modified, updated or created frontmatter field instead.
+func (v *vault) Stale(ctx context.Context, opts StaleOptions) (StaleReport, error) {
+	root, err := v.validateDir(opts.Subpath)
+	if err != nil {
+		return StaleReport{}, err
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = defaultStaleLimit
+	}
+
+	index, err := v.buildFileIndex(ctx)
+	if err != nil {
+		return StaleReport{}, err
+	}
+
+	// Count distinct linking notes per target and collect the candidates
+	var mu sync.Mutex
+	inbound := make(map[string]int)
+	mtimes := make(map[int64]int) // Unix second -> notes
+	var candidates []staleCandidate
+	notes := 0
+	_, err = v.walkNotes(ctx, ListOptions{Recursive: true, IncludeCanvas: true}, func(file noteFile, entry CacheEntry) bool {
+		targets := make(map[string]struct{})
+		for _, link := range entry.Links {
+			if link.Kind == LinkURL || link.Target == "" {
+				continue
+			}
+			if target, ok := index.resolve(file.relPath, link.Target); ok && target != file.relPath {
+				targets[target] = struct{}{}
+			}
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		for target := range targets {
+			inbound[target]++
+		}
+		if isCanvas(file.relPath) {
+			return false
+		}
+		notes++
+		mtimes[file.info.ModTime().Unix()]++
+		if isWithin(file.fullPath, root) {
+			candidates = append(candidates, staleCandidate{file.relPath, file.info.ModTime(), entry.Properties, entry.Tags})
+		}
+		return false // Only the signals are needed
+	})
+	if err != nil {
+		return StaleReport{}, err
+	}
+
+	// A migration stamps most notes with the same time
+	report := StaleReport{Notes: []StaleNote{}}
+	var shared int64
+	if notes >= unreliableMtimeNotes {
+		for second, count := range mtimes {
+			if float64(count) >= unreliableMtimeShare*float64(notes) {
+				shared, report.UnreliableMtimes = second, true
+			}
+		}
+	}
+
+	excluded := newTagFilter(nil, nil, opts.ExcludeTags)
+	now := time.Now()
+	for _, c := range candidates {
+		if !excluded.matches(c.tags) || inbound[c.path] > opts.MaxInboundLinks {
+			continue
+		}
+
+		modified, from := c.mtime, "mtime"
+		if report.UnreliableMtimes && c.mtime.Unix() == shared {
+			fields := append(append([]string{}, defaultModifiedFields...), v.createdFields...)
+			if t, field, ok := v.frontmatterDate(c.properties, fields); ok {
+				modified, from = t, field
+			}
+		}
+		if !modified.Before(opts.Before) {
+			continue
+		}
+
+		report.Notes = append(report.Notes, StaleNote{
+			Path:         c.path,
+			LastModified: modified,
+			ModifiedFrom: from,
+			DaysStale:    int(now.Sub(modified) / (24 * time.Hour)),
+			InboundLinks: inbound[c.path],
+			Tags:         c.tags,
+		})
+	}
+
+	// Stalest first, ties broken by path for stable output
+	sort.Slice(report.Notes, func(i, j int) bool {
+		a, b := report.Notes[i], report.Notes[j]
+		if !a.LastModified.Equal(b.LastModified) {
+			return a.LastModified.Before(b.LastModified)
+		}
+		return a.Path < b.Path
+	})
+	report.Total = len(report.Notes)
+	if len(report.Notes) > limit {
+		report.Notes = report.Notes[:limit]
+	}
+
+	return report, nil
+}
@@ -0,0 +1,137 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// writeAged creates the notes with their modification times set age ago
+func writeAged(t *testing.T, dir string, notes map[string]string, ages map[string]time.Duration) {
+	t.Helper()
+	now := time.Now()
+	writeFiles(t, dir, notes)
+	for path := range notes {
+		mtime := now.Add(-ages[path])
+		if err := os.Chtimes(filepath.Join(dir, path), mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestStale(t *testing.T) {
+	tmpDir := t.TempDir()
+	year := 365 * 24 * time.Hour
+	writeAged(t, tmpDir, map[string]string{
+		"orphan.md":         "Nobody links here",
+		"linked.md":         "Linked from hub",
+		"evergreen.md":      "Timeless #Evergreen",
+		"fresh.md":          "New and unlinked",
+		"hub.md":            "[[linked]] and [[linked#Again]] and [[missing]]",
+		"Archive/old.md":    "Old archive note, links to itself: [[old]]",
+		"Archive/canvas.md": "Placed on a canvas",
+		"board.canvas":      `{"nodes":[{"id":"1","type":"text","text":"See [[canvas]]","x":0,"y":0,"width":100,"height":100}],"edges":[]}`,
+	}, map[string]time.Duration{
+		"orphan.md":         3 * year,
+		"linked.md":         2 * year,
+		"evergreen.md":      4 * year,
+		"Archive/old.md":    2*year + time.Hour,
+		"Archive/canvas.md": 2 * year,
+		"hub.md":            time.Hour,
+		"fresh.md":          time.Hour,
+	})
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	ctx := context.Background()
+	before := time.Now().Add(-year)
+
+	tests := []struct {
+		name string
+		opts StaleOptions
+		want []string
+	}{
+		{"orphans, stalest first", StaleOptions{Before: before}, []string{"evergreen.md", "orphan.md", "Archive/old.md"}},
+		{"excluded tags", StaleOptions{Before: before, ExcludeTags: []string{"#evergreen"}}, []string{"orphan.md", "Archive/old.md"}},
+		{"linked notes", StaleOptions{Before: before, MaxInboundLinks: 1, ExcludeTags: []string{"evergreen"}}, []string{"orphan.md", "Archive/old.md", "Archive/canvas.md", "linked.md"}},
+		{"folder", StaleOptions{Subpath: "Archive", Before: before, MaxInboundLinks: 1}, []string{"Archive/old.md", "Archive/canvas.md"}},
+		{"limit", StaleOptions{Before: before, Limit: 1}, []string{"evergreen.md"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := v.Stale(ctx, tt.opts)
+			if err != nil {
+				t.Fatalf("Stale() error = %v", err)
+			}
+			var paths []string
+			for _, note := range report.Notes {
+				paths = append(paths, note.Path)
+			}
+			if !slices.Equal(paths, tt.want) || report.UnreliableMtimes {
+				t.Errorf("Stale() = %v (unreliable %v), want %v", paths, report.UnreliableMtimes, tt.want)
+			}
+		})
+	}
+
+	report, err := v.Stale(ctx, StaleOptions{Before: before, MaxInboundLinks: 1, Limit: 2})
+	if err != nil {
+		t.Fatalf("Stale() error = %v", err)
+	}
+	if report.Total != 5 || len(report.Notes) != 2 {
+		t.Errorf("Stale() total = %d with %d notes, want 5 with 2", report.Total, len(report.Notes))
+	}
+	for _, note := range report.Notes {
+		if note.ModifiedFrom != "mtime" || note.DaysStale < 365 {
+			t.Errorf("Stale() note = %+v", note)
+		}
+	}
+
+	if _, err := v.Stale(ctx, StaleOptions{Subpath: "missing", Before: before}); !errors.Is(err, ErrDirectoryNotFound) {
+		t.Errorf("Stale() missing folder error = %v", err)
+	}
+}
+
+func TestStaleUnreliableMtimes(t *testing.T) {
+	// Every note copied in the same second, as after a migration
+	tmpDir := t.TempDir()
+	notes := map[string]string{
+		"dated.md":   "---\nupdated: 2020-01-02\ncreated: 2019-05-01\n---\nEdited long ago",
+		"created.md": "---\ncreated: 2021-03-04\n---\nNever edited",
+		"recent.md":  fmt.Sprintf("---\nmodified: %s\n---\nEdited lately", time.Now().Format("2006-01-02")),
+	}
+	for i := range 8 {
+		notes[fmt.Sprintf("plain%d.md", i)] = "No frontmatter"
+	}
+	ages := make(map[string]time.Duration)
+	for path := range notes {
+		ages[path] = time.Hour
+	}
+	writeAged(t, tmpDir, notes, ages)
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+
+	report, err := v.Stale(context.Background(), StaleOptions{Before: time.Now().AddDate(-1, 0, 0)})
+	if err != nil {
+		t.Fatalf("Stale() error = %v", err)
+	}
+	if !report.UnreliableMtimes || len(report.Notes) != 2 {
+		t.Fatalf("Stale() = %+v, want the two notes dated by frontmatter", report)
+	}
+	for i, want := range []StaleNote{
+		{Path: "dated.md", ModifiedFrom: "updated", LastModified: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{Path: "created.md", ModifiedFrom: "created", LastModified: time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC)},
+	} {
+		got := report.Notes[i]
+		if got.Path != want.Path || got.ModifiedFrom != want.ModifiedFrom || !got.LastModified.Equal(want.LastModified) {
+			t.Errorf("Stale() note %d = %+v, want %+v", i, got, want)
+		}
+	}
+}
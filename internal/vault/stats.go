@@ -0,0 +1,115 @@
+package vault
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rootFolder is the folder key used in VaultStats.Folders for notes
+// located directly in the vault root
+const rootFolder = "/"
+
+// TagCount pairs a tag with the number of notes that contain it
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// VaultStats represents aggregate statistics about notes in the vault
+type VaultStats struct {
+	NoteCount      int            `json:"note_count"`       // Total number of notes
+	TotalSize      int64          `json:"total_size"`       // Total size of all notes in bytes
+	AverageSize    int64          `json:"average_size"`     // Average note size in bytes
+	UntaggedCount  int            `json:"untagged_count"`   // Notes without any tags
+	ModifiedLast7  int            `json:"modified_last_7"`  // Notes modified in the last 7 days
+	ModifiedLast30 int            `json:"modified_last_30"` // Notes modified in the last 30 days
+	CreatedLast7   int            `json:"created_last_7"`   // Notes created in the last 7 days
+	CreatedLast30  int            `json:"created_last_30"`  // Notes created in the last 30 days
+	Folders        map[string]int `json:"folders"`          // Note count per top-level folder
+	Tags           []TagCount     `json:"tags"`             // Tag usage sorted by count descending
+}
+
+// Stats computes aggregate statistics for all notes in the given subpath
+// The vault is walked once; tag data is served from the cache when possible
+func (v *vault) Stats(ctx context.Context, subpath string) (VaultStats, error) {
+	now := time.Now()
+	stats := VaultStats{
+		Folders: make(map[string]int),
+	}
+	tagCounts := make(map[string]TagCount) // Folded tag -> count
+
+	// Notes are loaded concurrently; aggregate under a lock
+	var mu sync.Mutex
+
+	_, err := v.walkNotes(ctx, ListOptions{Subpath: subpath, Recursive: true}, func(file noteFile, entry CacheEntry) bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		stats.NoteCount++
+		stats.TotalSize += file.info.Size()
+
+		if len(entry.Tags) == 0 {
+			stats.UntaggedCount++
+		}
+		for _, tag := range entry.Tags {
+			// Tags that fold alike are counted together under the
+			// smallest display form, whichever notes they came from
+			key := foldTag(tag)
+			count := tagCounts[key]
+			if count.Count == 0 || tag < count.Tag {
+				count.Tag = tag
+			}
+			count.Count++
+			tagCounts[key] = count
+		}
+
+		age := now.Sub(file.info.ModTime())
+		if age <= 7*24*time.Hour {
+			stats.ModifiedLast7++
+		}
+		if age <= 30*24*time.Hour {
+			stats.ModifiedLast30++
+		}
+
+		createdAge := now.Sub(file.noteInfo(entry).Created)
+		if createdAge <= 7*24*time.Hour {
+			stats.CreatedLast7++
+		}
+		if createdAge <= 30*24*time.Hour {
+			stats.CreatedLast30++
+		}
+
+		folder := rootFolder
+		if dir, _, found := strings.Cut(file.relPath, "/"); found {
+			folder = dir
+		}
+		stats.Folders[folder]++
+
+		return false // Only the aggregates are needed
+	})
+	if err != nil {
+		return VaultStats{}, err
+	}
+
+	if stats.NoteCount > 0 {
+		stats.AverageSize = stats.TotalSize / int64(stats.NoteCount)
+	}
+
+	stats.Tags = make([]TagCount, 0, len(tagCounts))
+	for _, count := range tagCounts {
+		stats.Tags = append(stats.Tags, count)
+	}
+
+	// Most used tags first, ties broken alphabetically for stable output
+	sort.Slice(stats.Tags, func(i, j int) bool {
+		if stats.Tags[i].Count != stats.Tags[j].Count {
+			return stats.Tags[i].Count > stats.Tags[j].Count
+		}
+		return stats.Tags[i].Tag < stats.Tags[j].Tag
+	})
+
+	return stats, nil
+}
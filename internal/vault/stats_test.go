@@ -0,0 +1,111 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	v, _ := setupTestVault(t)
+	ctx := context.Background()
+
+	t.Run("stats for entire vault", func(t *testing.T) {
+		stats, err := v.Stats(ctx, "")
+		if err != nil {
+			t.Fatalf("Stats() error = %v", err)
+		}
+
+		// 5 visible .md files; readme.txt and subdir/.hidden.md ignored
+		if stats.NoteCount != 5 {
+			t.Errorf("NoteCount = %d, want 5", stats.NoteCount)
+		}
+
+		if stats.TotalSize == 0 {
+			t.Error("Expected non-zero TotalSize")
+		}
+
+		if stats.AverageSize != stats.TotalSize/int64(stats.NoteCount) {
+			t.Errorf("AverageSize = %d, want %d", stats.AverageSize, stats.TotalSize/int64(stats.NoteCount))
+		}
+
+		// Freshly written notes count as recently created and modified
+		if stats.ModifiedLast7 != 5 || stats.ModifiedLast30 != 5 {
+			t.Errorf("ModifiedLast7 = %d, ModifiedLast30 = %d, want 5 and 5", stats.ModifiedLast7, stats.ModifiedLast30)
+		}
+		if stats.CreatedLast7 != 5 || stats.CreatedLast30 != 5 {
+			t.Errorf("CreatedLast7 = %d, CreatedLast30 = %d, want 5 and 5", stats.CreatedLast7, stats.CreatedLast30)
+		}
+
+		wantFolders := map[string]int{rootFolder: 2, "subdir": 2, "other": 1}
+		for folder, want := range wantFolders {
+			if got := stats.Folders[folder]; got != want {
+				t.Errorf("Folders[%q] = %d, want %d", folder, got, want)
+			}
+		}
+
+		if len(stats.Tags) == 0 {
+			t.Fatal("Expected tag counts")
+		}
+
+		// tag1 and tag2 both appear in two notes; tag1 sorts first
+		if stats.Tags[0].Tag != "tag1" || stats.Tags[0].Count != 2 {
+			t.Errorf("Tags[0] = %+v, want {tag1 2}", stats.Tags[0])
+		}
+		if stats.Tags[1].Tag != "tag2" || stats.Tags[1].Count != 2 {
+			t.Errorf("Tags[1] = %+v, want {tag2 2}", stats.Tags[1])
+		}
+	})
+
+	t.Run("untagged notes", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		v, err := NewVault(tmpDir)
+		if err != nil {
+			t.Fatalf("Failed to create vault: %v", err)
+		}
+
+		if err := v.Create(ctx, "plain.md", "No tags here"); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if err := v.Create(ctx, "tagged.md", "Has a #tag"); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+
+		stats, err := v.Stats(ctx, "")
+		if err != nil {
+			t.Fatalf("Stats() error = %v", err)
+		}
+
+		if stats.UntaggedCount != 1 {
+			t.Errorf("UntaggedCount = %d, want 1", stats.UntaggedCount)
+		}
+	})
+
+	t.Run("stats for subdirectory", func(t *testing.T) {
+		stats, err := v.Stats(ctx, "subdir")
+		if err != nil {
+			t.Fatalf("Stats() error = %v", err)
+		}
+
+		if stats.NoteCount != 2 {
+			t.Errorf("NoteCount = %d, want 2", stats.NoteCount)
+		}
+	})
+
+	t.Run("stats with path traversal", func(t *testing.T) {
+		_, err := v.Stats(ctx, "../../../etc")
+		if !errors.Is(err, ErrPathTraversal) {
+			t.Errorf("Expected ErrPathTraversal, got %v", err)
+		}
+	})
+
+	t.Run("stats with cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := v.Stats(ctx, "")
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,161 @@
+package vault
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// maxSuggestions limits the number of alternatives offered for a missing path
+const maxSuggestions = 3
+
+// suggestDirs returns vault-relative paths of existing directories whose
+// names are similar to the missing directory at fullPath
+// Candidates are taken from the nearest existing ancestor directory
+func (v *vault) suggestDirs(fullPath string) []string {
+	// Find the nearest existing ancestor and the first missing component
+	missing := filepath.Base(fullPath)
+	parent := filepath.Dir(fullPath)
+	for v.withinBase(parent) {
+		if stat, err := os.Stat(parent); err == nil && stat.IsDir() {
+			break
+		}
+		missing = filepath.Base(parent)
+		parent = filepath.Dir(parent)
+	}
+	if !v.withinBase(parent) {
+		return nil
+	}
+
+	entries, err := os.ReadDir(parent)
+	if err != nil {
+		return nil
+	}
+
+	type candidate struct {
+		path     string
+		distance int
+	}
+
+	target := strings.ToLower(missing)
+	var candidates []candidate
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		name := strings.ToLower(entry.Name())
+		distance := levenshtein(target, name)
+		if distance > max(2, len(target)/3) && !strings.HasPrefix(name, target) {
+			continue
+		}
+
+		relPath, err := filepath.Rel(v.basePath, filepath.Join(parent, entry.Name()))
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, candidate{path: filepath.ToSlash(relPath), distance: distance})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].path < candidates[j].path
+	})
+
+	var suggestions []string
+	for i := 0; i < len(candidates) && i < maxSuggestions; i++ {
+		suggestions = append(suggestions, candidates[i].path)
+	}
+
+	return suggestions
+}
+
+// noteNotFound returns the error for the missing note at fullPath, with
+// the paths of similarly named notes
+func (v *vault) noteNotFound(ctx context.Context, fullPath string) error {
+	relPath := v.relPath(fullPath)
+	return &NoteNotFoundError{Path: relPath, Suggestions: v.suggestNotes(ctx, relPath)}
+}
+
+// suggestNotes returns vault-relative paths of existing notes similar to
+// the missing note at relPath: the best FindNote matches of its path, or
+// of its name when the path matches nothing, then notes whose names are
+// a few edits away, so misspelt names are found too. It uses the cached
+// path listing of FindNote.
+func (v *vault) suggestNotes(ctx context.Context, relPath string) []string {
+	var suggestions []string
+	for _, query := range []string{relPath, path.Base(relPath)} {
+		matches, err := v.FindNote(ctx, FuzzyOptions{Query: query, Limit: maxSuggestions})
+		if err != nil {
+			return nil
+		}
+		for _, m := range matches {
+			suggestions = append(suggestions, m.Path)
+		}
+		if len(suggestions) > 0 {
+			break
+		}
+	}
+	if len(suggestions) >= maxSuggestions {
+		return suggestions
+	}
+
+	candidates, err := v.noteCandidates(ctx)
+	if err != nil {
+		return suggestions
+	}
+	type candidate struct {
+		path     string
+		distance int
+	}
+	target := strings.ToLower(strings.TrimSuffix(path.Base(relPath), ".md"))
+	var close []candidate
+	for _, c := range candidates {
+		name := strings.ToLower(strings.TrimSuffix(path.Base(c.path), ".md"))
+		if distance := levenshtein(target, name); distance <= max(2, len(target)/3) && !slices.Contains(suggestions, c.path) {
+			close = append(close, candidate{path: c.path, distance: distance})
+		}
+	}
+	sort.Slice(close, func(i, j int) bool {
+		if close[i].distance != close[j].distance {
+			return close[i].distance < close[j].distance
+		}
+		return close[i].path < close[j].path
+	})
+	for i := 0; i < len(close) && len(suggestions) < maxSuggestions; i++ {
+		suggestions = append(suggestions, close[i].path)
+	}
+
+	return suggestions
+}
+
+// levenshtein computes the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
@@ -2,36 +2,112 @@ package vault
 
 import (
 	"regexp"
+	"sort"
 	"strings"
+
+	"golang.org/x/text/unicode/norm"
 )
 
 // tagRegex matches hashtags in markdown content
-// Pattern: #(\w+) matches # followed by one or more word characters
-var tagRegex = regexp.MustCompile(`#(\w+)`)
+// Pattern: # followed by one or more letters, digits, marks or underscores
+var tagRegex = regexp.MustCompile(`#([\pL\pN\pM_]+)`)
 
 // ExtractTags finds all unique tags in the given content
 // Tags are identified by the # prefix followed by word characters
-// Returns a deduplicated slice of tag names (without the # prefix)
+// Returns a deduplicated, sorted slice of tag names (without the # prefix)
+// Tags that fold to the same form, like #Café and #CAFÉ, are one tag,
+// reported in its display form
 func ExtractTags(content string) []string {
 	matches := tagRegex.FindAllStringSubmatch(content, -1)
 	if len(matches) == 0 {
 		return []string{}
 	}
 
-	// Use map for deduplication
-	tagMap := make(map[string]struct{}, len(matches))
+	// Use map for deduplication, keeping the smallest display form of
+	// each folded tag so the result does not depend on their order
+	tagMap := make(map[string]string, len(matches))
 	for _, match := range matches {
 		if len(match) > 1 {
-			tag := strings.ToLower(match[1]) // Normalize to lowercase
-			tagMap[tag] = struct{}{}
+			tag := displayTag(match[1])
+			key := foldTag(tag)
+			if prev, ok := tagMap[key]; !ok || tag < prev {
+				tagMap[key] = tag
+			}
 		}
 	}
 
 	// Convert map to slice
 	tags := make([]string, 0, len(tagMap))
-	for tag := range tagMap {
+	for _, tag := range tagMap {
 		tags = append(tags, tag)
 	}
+	sort.Strings(tags)
 
 	return tags
 }
+
+// displayTag returns the form in which a tag is reported: lowercase and
+// NFC-normalized
+func displayTag(tag string) string {
+	return norm.NFC.String(strings.ToLower(tag))
+}
+
+// foldTag returns the form in which tags are compared: without a leading
+// #, NFC-normalized and case-folded
+func foldTag(tag string) string {
+	return foldText(strings.TrimPrefix(tag, "#"))
+}
+
+// tagFilter selects notes by their tag sets
+// Tags are compared folded so matching is case-insensitive
+type tagFilter struct {
+	any  map[string]struct{} // At least one must be present
+	all  map[string]struct{} // Every one must be present
+	none map[string]struct{} // None may be present
+}
+
+// newTagFilter builds a tagFilter from raw tag lists, ignoring a leading #
+func newTagFilter(anyTags, allTags, noneTags []string) tagFilter {
+	return tagFilter{
+		any:  tagSet(anyTags),
+		all:  tagSet(allTags),
+		none: tagSet(noneTags),
+	}
+}
+
+// tagSet normalizes tags into a lookup set
+func tagSet(tags []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		set[foldTag(tag)] = struct{}{}
+	}
+	return set
+}
+
+// matches reports whether a note with the given tags passes the filter
+// An empty filter matches every note
+func (f tagFilter) matches(tags []string) bool {
+	noteTags := tagSet(tags)
+
+	for tag := range f.none {
+		if _, ok := noteTags[tag]; ok {
+			return false
+		}
+	}
+
+	for tag := range f.all {
+		if _, ok := noteTags[tag]; !ok {
+			return false
+		}
+	}
+
+	if len(f.any) == 0 {
+		return true
+	}
+	for tag := range f.any {
+		if _, ok := noteTags[tag]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -57,6 +57,21 @@ func TestExtractTags(t *testing.T) {
 			content: "",
 			want:    []string{},
 		},
+		{
+			name:    "unicode tags",
+			content: "#Café #Straße #日本",
+			want:    []string{"café", "straße", "日本"},
+		},
+		{
+			name:    "composed and decomposed accents",
+			content: "#caf\u00e9 #cafe\u0301 #CAFÉ",
+			want:    []string{"café"},
+		},
+		{
+			name:    "Turkish dotted and dotless i",
+			content: "#İstanbul #ISTANBUL #ıstanbul",
+			want:    []string{"istanbul"},
+		},
 		{
 			name:    "hashtag in code block",
 			content: "```\n#include <stdio.h>\n```\n#actualtag",
@@ -91,3 +106,75 @@ func TestExtractTagsPerformance(t *testing.T) {
 		t.Error("Expected to extract tags from large content")
 	}
 }
+
+func TestTagFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter tagFilter
+		tags   []string
+		want   bool
+	}{
+		{
+			name:   "empty filter matches untagged note",
+			filter: newTagFilter(nil, nil, nil),
+			tags:   nil,
+			want:   true,
+		},
+		{
+			name:   "any matches one tag",
+			filter: newTagFilter([]string{"a", "b"}, nil, nil),
+			tags:   []string{"b"},
+			want:   true,
+		},
+		{
+			name:   "any without match",
+			filter: newTagFilter([]string{"a"}, nil, nil),
+			tags:   []string{"c"},
+			want:   false,
+		},
+		{
+			name:   "all requires every tag",
+			filter: newTagFilter(nil, []string{"project", "2024"}, nil),
+			tags:   []string{"project"},
+			want:   false,
+		},
+		{
+			name:   "all satisfied",
+			filter: newTagFilter(nil, []string{"project", "2024"}, nil),
+			tags:   []string{"2024", "project", "extra"},
+			want:   true,
+		},
+		{
+			name:   "none excludes",
+			filter: newTagFilter(nil, []string{"project"}, []string{"archived"}),
+			tags:   []string{"project", "archived"},
+			want:   false,
+		},
+		{
+			name:   "folded unicode",
+			filter: newTagFilter([]string{"CAFE\u0301"}, []string{"İSTANBUL"}, []string{"strasse"}),
+			tags:   []string{"café", "ıstanbul"},
+			want:   true,
+		},
+		{
+			name:   "folded exclusion",
+			filter: newTagFilter(nil, nil, []string{"STRASSE"}),
+			tags:   []string{"straße"},
+			want:   false,
+		},
+		{
+			name:   "case-insensitive with hash prefix",
+			filter: newTagFilter([]string{"#Project"}, nil, []string{"ARCHIVED"}),
+			tags:   []string{"project"},
+			want:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.matches(tt.tags); got != tt.want {
+				t.Errorf("matches(%v) = %v, want %v", tt.tags, got, tt.want)
+			}
+		})
+	}
+}
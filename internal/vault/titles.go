@@ -0,0 +1,390 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TitleSource is where the title of a note comes from
+type TitleSource string
+
+// Title sources
+const (
+	TitleFromFrontmatter TitleSource = "frontmatter" // The title property
+	TitleFromFirstH1     TitleSource = "first_h1"    // The first level 1 heading
+	TitleFromFilename    TitleSource = "filename"    // The file name without extension
+)
+
+// TitleSources lists where the title of a note is looked for, the first
+// found wins. The file name, which every note has, comes last whether or
+// not it is listed.
+type TitleSources []TitleSource
+
+// DefaultTitleSources take the title property, then the file name
+var DefaultTitleSources = TitleSources{TitleFromFrontmatter, TitleFromFilename}
+
+// Validate reports an unknown or repeated source, or one listed after the
+// file name, which would never be used
+func (s TitleSources) Validate() error {
+	seen := make(map[TitleSource]bool)
+	for i, source := range s {
+		switch source {
+		case TitleFromFrontmatter, TitleFromFirstH1, TitleFromFilename:
+		default:
+			return fmt.Errorf("unknown title source %q; valid sources are frontmatter, first_h1 and filename", source)
+		}
+		if seen[source] {
+			return fmt.Errorf("title source %s is listed twice", source)
+		}
+		seen[source] = true
+		if source == TitleFromFilename && i < len(s)-1 {
+			return fmt.Errorf("title source filename must come last, as every note has one")
+		}
+	}
+	return nil
+}
+
+// WithTitleSources sets where the title of a note comes from, as checked
+// by TitleSources.Validate; sources after the file name are ignored. The
+// title names notes in results and is matched by Resolve and ranked by
+// FindRelated, FindSimilar and SuggestPlacement. By default titles come
+// from DefaultTitleSources.
+func WithTitleSources(sources TitleSources) Option {
+	return func(v *vault) {
+		v.titleSources = sources
+	}
+}
+
+// noteTitle returns the title of the note at relPath with the parsed
+// entry, and where it came from
+func (v *vault) noteTitle(relPath string, entry CacheEntry) (string, TitleSource) {
+sources:
+	for _, source := range v.titleSources {
+		switch source {
+		case TitleFromFrontmatter:
+			if entry.Title != "" {
+				return entry.Title, source
+			}
+		case TitleFromFirstH1:
+			if h := firstH1(entry.Headings); h != nil {
+				return h.Text, source
+			}
+		case TitleFromFilename:
+			break sources
+		}
+	}
+	return noteName(relPath), TitleFromFilename
+}
+
+// firstH1 returns the first level 1 heading with text, nil when there is
+// none
+func firstH1(headings []Heading) *Heading {
+	for i, h := range headings {
+		if h.Level == 1 && strings.TrimSpace(h.Text) != "" {
+			return &headings[i]
+		}
+	}
+	return nil
+}
+
+// TitleFix selects how SyncTitles makes a title and file name agree
+type TitleFix string
+
+// Title fixes
+const (
+	TitleFixRename  TitleFix = "rename"  // Rename the file after the title
+	TitleFixRetitle TitleFix = "retitle" // Rewrite the title after the file name
+)
+
+// ParseTitleFix validates a title fix
+func ParseTitleFix(s string) (TitleFix, error) {
+	switch fix := TitleFix(strings.ToLower(strings.TrimSpace(s))); fix {
+	case TitleFixRename, TitleFixRetitle:
+		return fix, nil
+	default:
+		return "", fmt.Errorf("unknown fix %q (want rename or retitle)", s)
+	}
+}
+
+// SyncTitlesOptions describes a SyncTitles run
+type SyncTitlesOptions struct {
+	Path   string   // Folder to check, empty for the whole vault
+	Fix    TitleFix // Which of the title and the file name changes
+	DryRun bool     // Report the discrepancies without changing anything
+}
+
+// TitleSyncStatus is the outcome of SyncTitles for one note
+type TitleSyncStatus string
+
+// Title sync statuses
+const (
+	TitleSyncFixed    TitleSyncStatus = "fixed"    // Renamed or retitled, or would be on a dry run
+	TitleSyncSkipped  TitleSyncStatus = "skipped"  // Left alone, e.g. an index note or a failed write
+	TitleSyncConflict TitleSyncStatus = "conflict" // Not renamed as another note has or would get the name
+)
+
+// TitleSync reports a note whose title and file name disagree
+type TitleSync struct {
+	Path         string          `json:"path"`
+	Title        string          `json:"title"`
+	TitleSource  TitleSource     `json:"title_source"`
+	Status       TitleSyncStatus `json:"status"`
+	NewPath      string          `json:"new_path,omitempty"`      // Path after a rename
+	NewTitle     string          `json:"new_title,omitempty"`     // Title after a retitle
+	LinksUpdated int             `json:"links_updated,omitempty"` // Links pointed at the new path
+	Reason       string          `json:"reason,omitempty"`        // Why the note was skipped or conflicts
+}
+
+// SyncTitlesResult reports the outcome of SyncTitles
+type SyncTitlesResult struct {
+	Notes     []TitleSync `json:"notes"`   // Sorted by path
+	Checked   int         `json:"checked"` // Notes compared
+	Fixed     int         `json:"fixed"`
+	Skipped   int         `json:"skipped"`
+	Conflicts int         `json:"conflicts"`
+	DryRun    bool        `json:"dry_run,omitempty"`
+}
+
+// SyncTitles finds the notes under opts.Path whose title, see
+// WithTitleSources, is not their file name once made safe as one, and
+// makes them agree. TitleFixRename renames each note after its title with
+// MoveNote, updating links; every rename is planned first, and a note
+// whose new name another note has or would get, ignoring case, is reported
+// as a conflict and left alone. TitleFixRetitle rewrites the title
+// property or first heading the title came from to the file name. Index
+// notes keep their names.
+func (v *vault) SyncTitles(ctx context.Context, opts SyncTitlesOptions) (SyncTitlesResult, error) {
+	fix, err := ParseTitleFix(string(opts.Fix))
+	if err != nil {
+		return SyncTitlesResult{}, err
+	}
+
+	var mu sync.Mutex
+	result := SyncTitlesResult{Notes: []TitleSync{}, DryRun: opts.DryRun}
+	_, err = v.walkNotes(ctx, ListOptions{Subpath: opts.Path, Recursive: true}, func(file noteFile, entry CacheEntry) bool {
+		if path.Ext(file.relPath) != ".md" {
+			return false
+		}
+		title, source := v.noteTitle(file.relPath, entry)
+		mu.Lock()
+		defer mu.Unlock()
+		result.Checked++
+		if source == TitleFromFilename || titleMatchesName(title, file.relPath) {
+			return false
+		}
+		result.Notes = append(result.Notes, TitleSync{Path: file.relPath, Title: title, TitleSource: source})
+		return false
+	})
+	if err != nil {
+		return SyncTitlesResult{}, err
+	}
+	slices.SortFunc(result.Notes, func(a, b TitleSync) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	for i := range result.Notes {
+		if slices.Contains(v.indexNotes, path.Base(result.Notes[i].Path)) {
+			result.Notes[i].Status = TitleSyncSkipped
+			result.Notes[i].Reason = "index notes keep their file name"
+		}
+	}
+	if fix == TitleFixRename {
+		err = v.renameToTitles(ctx, result.Notes, opts.DryRun)
+	} else {
+		err = v.retitleToNames(ctx, result.Notes, opts.DryRun)
+	}
+	if err != nil {
+		return SyncTitlesResult{}, err
+	}
+
+	for _, note := range result.Notes {
+		switch note.Status {
+		case TitleSyncFixed:
+			result.Fixed++
+		case TitleSyncSkipped:
+			result.Skipped++
+		case TitleSyncConflict:
+			result.Conflicts++
+		}
+	}
+	return result, nil
+}
+
+// titleMatchesName reports whether title and the file name of the note at
+// relPath are the same once made safe as file names
+func titleMatchesName(title, relPath string) bool {
+	return linkableName(title) == linkableName(noteName(relPath))
+}
+
+// renameToTitles renames the notes without a status after their titles,
+// first marking those whose new path conflicts with an existing note or
+// another rename
+func (v *vault) renameToTitles(ctx context.Context, notes []TitleSync, dryRun bool) error {
+	candidates, err := v.noteCandidates(ctx)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]string, len(candidates)) // Folded path -> path
+	for _, c := range candidates {
+		existing[strings.ToLower(c.path)] = c.path
+	}
+
+	// Plan every rename before making any
+	targets := make(map[string][]int) // Folded new path -> notes
+	for i := range notes {
+		note := &notes[i]
+		if note.Status != "" {
+			continue
+		}
+		name := linkableName(note.Title)
+		if name == "" {
+			note.Status, note.Reason = TitleSyncSkipped, fmt.Sprintf("the title %q leaves no usable file name", note.Title)
+			continue
+		}
+		note.NewPath = path.Join(path.Dir(note.Path), name+".md")
+		targets[strings.ToLower(note.NewPath)] = append(targets[strings.ToLower(note.NewPath)], i)
+	}
+	for key, planned := range targets {
+		for _, i := range planned {
+			note := &notes[i]
+			other, taken := existing[key]
+			switch {
+			case len(planned) > 1:
+				note.Status = TitleSyncConflict
+				note.Reason = fmt.Sprintf("%d notes would be renamed to %s", len(planned), note.NewPath)
+			case taken && other != note.Path:
+				note.Status, note.Reason = TitleSyncConflict, fmt.Sprintf("%s already exists", other)
+			case !taken && !strings.EqualFold(note.NewPath, note.Path):
+				// Files the walk leaves out, such as ignored ones, count too
+				if _, err := os.Lstat(filepath.Join(v.basePath, filepath.FromSlash(note.NewPath))); err == nil {
+					note.Status, note.Reason = TitleSyncConflict, fmt.Sprintf("%s already exists", note.NewPath)
+				}
+			}
+		}
+	}
+
+	for i := range notes {
+		note := &notes[i]
+		if note.Status != "" {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		move, err := v.MoveNote(ctx, MoveNoteOptions{Path: note.Path, NewPath: note.NewPath, UpdateLinks: true, DryRun: dryRun})
+		if err != nil {
+			note.Status, note.Reason = TitleSyncSkipped, err.Error()
+			continue
+		}
+		note.Status, note.LinksUpdated = TitleSyncFixed, move.LinksUpdated
+	}
+	return nil
+}
+
+// retitleToNames rewrites the title of the notes without a status to
+// their file names
+func (v *vault) retitleToNames(ctx context.Context, notes []TitleSync, dryRun bool) error {
+	for i := range notes {
+		note := &notes[i]
+		if note.Status != "" {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		note.NewTitle = noteName(note.Path)
+		if dryRun {
+			note.Status = TitleSyncFixed
+			continue
+		}
+		if err := v.retitleNote(ctx, note.Path, note.NewTitle); err != nil {
+			note.Status, note.Reason = TitleSyncSkipped, err.Error()
+			continue
+		}
+		note.Status = TitleSyncFixed
+	}
+	return nil
+}
+
+// retitleNote rewrites the title property or heading the title of the
+// note at relPath comes from to title
+func (v *vault) retitleNote(ctx context.Context, relPath, title string) error {
+	fullPath, err := v.validatePath(relPath)
+	if err != nil {
+		return err
+	}
+
+	unlock := v.writeLocks.lock(fullPath)
+	defer unlock()
+
+	if _, err := v.checkUpdate(ctx, relPath); err != nil {
+		return err
+	}
+	stat, err := os.Stat(fullPath)
+	if err != nil {
+		return ErrNoteNotFound
+	}
+	entry, err := v.loadEntry(fullPath, stat.ModTime())
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	// The note may have changed since it was compared
+	var content string
+	switch current, source := v.noteTitle(relPath, entry); {
+	case source == TitleFromFilename || titleMatchesName(current, relPath):
+		return fmt.Errorf("%w: the title already matches the file name", ErrRevisionMismatch)
+	case source == TitleFromFrontmatter:
+		content, err = setFrontmatterProperty(entry.Content, "title", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: title})
+	default:
+		content = replaceHeading(entry.Content, *firstH1(entry.Headings), title)
+	}
+	if err != nil {
+		return err
+	}
+
+	updated, err := v.PrepareContent(relPath, content, false)
+	if err != nil {
+		return err
+	}
+	// Check context cancellation before I/O; once writing starts it completes
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	written, err := v.writeNote(fullPath, updated)
+	if err != nil {
+		return err
+	}
+	return v.record(ctx, written)
+}
+
+// replaceHeading replaces the text of the heading h in content with text,
+// keeping its level and style
+func replaceHeading(content string, h Heading, text string) string {
+	lines := strings.Split(content, "\n")
+	line := lines[h.Line-1]
+	cr := ""
+	if strings.HasSuffix(line, "\r") {
+		cr = "\r"
+	}
+	if m := headingRegex.FindStringSubmatch(strings.TrimSuffix(line, "\r")); m != nil {
+		lines[h.Line-1] = m[1] + " " + text + cr
+		return strings.Join(lines, "\n")
+	}
+
+	// A setext heading: its text runs up to the underline
+	end := h.Line
+	for end < len(lines) && !setextRegex.MatchString(strings.TrimSuffix(lines[end], "\r")) {
+		end++
+	}
+	lines = slices.Replace(lines, h.Line-1, end, text+cr)
+	return strings.Join(lines, "\n")
+}
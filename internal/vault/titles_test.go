@@ -0,0 +1,234 @@
+package vault
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTitleSourcesValidate(t *testing.T) {
+	tests := []struct {
+		sources TitleSources
+		wantErr string
+	}{
+		{DefaultTitleSources, ""},
+		{TitleSources{TitleFromFirstH1, TitleFromFrontmatter}, ""},
+		{TitleSources{}, ""},
+		{TitleSources{"heading"}, "unknown title source"},
+		{TitleSources{TitleFromFirstH1, TitleFromFirstH1}, "listed twice"},
+		{TitleSources{TitleFromFilename, TitleFromFrontmatter}, "must come last"},
+	}
+	for _, tt := range tests {
+		err := tt.sources.Validate()
+		if (err == nil) != (tt.wantErr == "") || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("Validate(%q) error = %v, want %q", tt.sources, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNoteTitles(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"both.md":    "---\ntitle: Property\n---\n# Heading\n",
+		"heading.md": "Intro\n\nSetext Heading\n==============\n",
+		"plain.md":   "## Only a subheading\n",
+	})
+
+	tests := []struct {
+		sources TitleSources
+		want    map[string]string // Title by path, "" for the file name
+	}{
+		{DefaultTitleSources, map[string]string{"both.md": "Property", "heading.md": "", "plain.md": ""}},
+		{TitleSources{TitleFromFirstH1, TitleFromFrontmatter}, map[string]string{"both.md": "Heading", "heading.md": "Setext Heading", "plain.md": ""}},
+		{TitleSources{TitleFromFilename, TitleFromFrontmatter}, map[string]string{"both.md": "", "heading.md": "", "plain.md": ""}},
+	}
+	for _, tt := range tests {
+		v, err := NewVault(tmpDir, WithTitleSources(tt.sources))
+		if err != nil {
+			t.Fatalf("Failed to create vault: %v", err)
+		}
+		notes, err := v.List(ctx, ListOptions{})
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		got := make(map[string]string)
+		for _, note := range notes {
+			got[note.Path] = note.Title
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("titles with %q = %q, want %q", tt.sources, got, tt.want)
+		}
+	}
+
+	// Resolve matches the title the sources give
+	v, err := NewVault(tmpDir, WithTitleSources(TitleSources{TitleFromFirstH1}))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	resolution, err := v.Resolve(ctx, "setext heading")
+	if err != nil || resolution.Match == nil || resolution.Match.Path != "heading.md" || resolution.Match.MatchedBy != MatchTitle {
+		t.Errorf("Resolve() = %+v, %v, want heading.md by title", resolution, err)
+	}
+	if _, err := v.Resolve(ctx, "Property"); err == nil {
+		t.Errorf("Resolve(Property) succeeded, want the frontmatter title ignored")
+	}
+}
+
+func TestSyncTitlesRename(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"a.md":           "---\ntitle: \"Alpha: Plan\"\n---\nBody\n",
+		"links.md":       "See [[a]] and [[a#Body|the plan]].\n",
+		"c.md":           "---\ntitle: Taken\n---\n",
+		"Taken.md":       "Already here\n",
+		"d.md":           "---\ntitle: Twin\n---\n",
+		"sub/e.md":       "---\ntitle: Other\n---\n",
+		"sub/f.md":       "---\ntitle: other\n---\n",
+		"case.md":        "---\ntitle: Case\n---\n",
+		"Matching.md":    "---\ntitle: Matching\n---\n",
+		"README.md":      "---\ntitle: About\n---\n",
+		"reserved.md":    "---\ntitle: CON\n---\n",
+		"sub/Twin.md":    "Not in d's folder\n",
+		"untitled.md":    "No title\n",
+		"sub/index.base": "not a note",
+	}
+	writeFiles(t, tmpDir, files)
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+
+	want := []TitleSync{
+		{Path: "README.md", Title: "About", Status: TitleSyncSkipped},
+		{Path: "a.md", Title: "Alpha: Plan", Status: TitleSyncFixed, NewPath: "Alpha Plan.md", LinksUpdated: 2},
+		{Path: "c.md", Title: "Taken", Status: TitleSyncConflict, NewPath: "Taken.md"},
+		{Path: "case.md", Title: "Case", Status: TitleSyncFixed, NewPath: "Case.md"},
+		{Path: "d.md", Title: "Twin", Status: TitleSyncFixed, NewPath: "Twin.md"},
+		{Path: "reserved.md", Title: "CON", Status: TitleSyncSkipped},
+		{Path: "sub/e.md", Title: "Other", Status: TitleSyncConflict, NewPath: "sub/Other.md"},
+		{Path: "sub/f.md", Title: "other", Status: TitleSyncConflict, NewPath: "sub/other.md"},
+	}
+	check := func(result SyncTitlesResult, dryRun bool) {
+		t.Helper()
+		if len(result.Notes) != len(want) {
+			t.Fatalf("SyncTitles() = %+v, want %d notes", result.Notes, len(want))
+		}
+		for i, note := range result.Notes {
+			w := want[i]
+			if note.Path != w.Path || note.Title != w.Title || note.TitleSource != TitleFromFrontmatter ||
+				note.Status != w.Status || note.NewPath != w.NewPath || note.LinksUpdated != w.LinksUpdated {
+				t.Errorf("note %d = %+v, want %+v", i, note, w)
+			}
+			if (note.Status == TitleSyncFixed) == (note.Reason != "") {
+				t.Errorf("note %s reason = %q", note.Path, note.Reason)
+			}
+		}
+		if result.Checked != 13 || result.Fixed != 3 || result.Skipped != 2 || result.Conflicts != 3 || result.DryRun != dryRun {
+			t.Errorf("SyncTitles() counts = %+v", result)
+		}
+	}
+
+	// A dry run changes nothing
+	result, err := v.SyncTitles(ctx, SyncTitlesOptions{Fix: TitleFixRename, DryRun: true})
+	if err != nil {
+		t.Fatalf("SyncTitles() error = %v", err)
+	}
+	check(result, true)
+	for name, content := range files {
+		if data, err := os.ReadFile(filepath.Join(tmpDir, name)); err != nil || string(data) != content {
+			t.Errorf("%s changed on a dry run: %q, %v", name, data, err)
+		}
+	}
+
+	result, err = v.SyncTitles(ctx, SyncTitlesOptions{Fix: TitleFixRename})
+	if err != nil {
+		t.Fatalf("SyncTitles() error = %v", err)
+	}
+	check(result, false)
+	for name, wantExists := range map[string]bool{
+		"a.md": false, "Alpha Plan.md": true, "d.md": false, "Twin.md": true,
+		"c.md": true, "sub/e.md": true, "sub/f.md": true, "README.md": true,
+	} {
+		if _, err := os.Stat(filepath.Join(tmpDir, name)); (err == nil) != wantExists {
+			t.Errorf("%s exists = %v, want %v", name, err == nil, wantExists)
+		}
+	}
+	if entries, _ := os.ReadDir(tmpDir); !containsName(entries, "Case.md") {
+		t.Errorf("case.md not renamed to Case.md")
+	}
+	data, _ := os.ReadFile(filepath.Join(tmpDir, "links.md"))
+	if want := "See [[Alpha Plan]] and [[Alpha Plan#Body|the plan]].\n"; string(data) != want {
+		t.Errorf("links.md = %q, want %q", data, want)
+	}
+
+	// Everything left differs for a reason
+	again, err := v.SyncTitles(ctx, SyncTitlesOptions{Fix: TitleFixRename, DryRun: true})
+	if err != nil || again.Fixed != 0 || len(again.Notes) != 5 {
+		t.Errorf("second SyncTitles() = %+v, %v, want only the skipped and conflicting notes", again, err)
+	}
+
+	if _, err := v.SyncTitles(ctx, SyncTitlesOptions{Fix: "merge"}); err == nil {
+		t.Errorf("SyncTitles(merge) succeeded, want an error")
+	}
+}
+
+func containsName(entries []os.DirEntry, name string) bool {
+	for _, entry := range entries {
+		if entry.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSyncTitlesRetitle(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"Property.md": "---\n# kept\ntitle: >-\n  Folded\n  title\ntags: [a] # kept too\n---\n# Heading\n",
+		"Heading.md":  "Intro\n\n## Sub\n\n# Old heading ##\n\nBody\n",
+		"Setext.md":   "Old\nsetext\n===\nBody\n",
+		"Quoted.md":   "---\ntitle: Other # why\n---\n",
+		"Plan: Q3.md": "# Something\n",
+	})
+	v, err := NewVault(tmpDir, WithTitleSources(TitleSources{TitleFromFrontmatter, TitleFromFirstH1}))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+
+	result, err := v.SyncTitles(ctx, SyncTitlesOptions{Fix: TitleFixRetitle})
+	if err != nil {
+		t.Fatalf("SyncTitles() error = %v", err)
+	}
+	if result.Fixed != 5 || result.Checked != 5 {
+		t.Errorf("SyncTitles() = %+v, want 5 notes retitled", result)
+	}
+	for _, note := range result.Notes {
+		if note.NewTitle != noteName(note.Path) {
+			t.Errorf("%s new title = %q", note.Path, note.NewTitle)
+		}
+	}
+
+	for name, want := range map[string]string{
+		"Property.md": "---\n# kept\ntitle: Property\ntags: [a] # kept too\n---\n# Heading\n",
+		"Heading.md":  "Intro\n\n## Sub\n\n# Heading\n\nBody\n",
+		"Setext.md":   "Setext\n===\nBody\n",
+		"Quoted.md":   "---\ntitle: Quoted # why\n---\n",
+		"Plan: Q3.md": "# Plan: Q3\n",
+	} {
+		data, err := os.ReadFile(filepath.Join(tmpDir, name))
+		if err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v, want %q", name, data, err, want)
+		}
+	}
+
+	again, err := v.SyncTitles(ctx, SyncTitlesOptions{Fix: TitleFixRetitle})
+	if err != nil || len(again.Notes) != 0 {
+		t.Errorf("second SyncTitles() = %+v, %v, want nothing left", again, err)
+	}
+}
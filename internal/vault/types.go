@@ -0,0 +1,206 @@
+package vault
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// DefaultNoteType is the type of notes no rule matches when the settings
+// name none
+const DefaultNoteType = "note"
+
+// NoteTypeRule classifies the notes it matches as Type. Path and
+// Properties combine with AND; a rule needs at least one of them.
+type NoteTypeRule struct {
+	Type       string         `json:"type"`
+	Path       string         `json:"path,omitempty"`       // Glob of the note's path or one of its folders, as for WithReadOnlyPaths
+	Properties map[string]any `json:"properties,omitempty"` // Frontmatter conditions, as for SearchOptions.Properties
+}
+
+// NoteTypeSettings classify notes into types. The first rule matching a
+// note gives its type; notes no rule matches have the Default type.
+type NoteTypeSettings struct {
+	Rules   []NoteTypeRule
+	Default string // DefaultNoteType when empty
+}
+
+// NoteTypeCount is a note type with the number of notes of that type
+type NoteTypeCount struct {
+	Type    string         `json:"type"`
+	Notes   int            `json:"notes"`
+	Default bool           `json:"default,omitempty"` // Notes no rule matches have this type
+	Rules   []NoteTypeRule `json:"rules,omitempty"`   // Rules giving the type, in the order they apply
+}
+
+// UnknownNoteTypeError reports a type filter naming no configured type
+// It matches ErrUnknownNoteType with errors.Is
+type UnknownNoteTypeError struct {
+	Type  string   // Type that was requested
+	Types []string // Configured types, empty when there are none
+}
+
+func (e *UnknownNoteTypeError) Error() string {
+	if len(e.Types) == 0 {
+		return fmt.Sprintf("unknown note type %q; no note types are configured", e.Type)
+	}
+	return fmt.Sprintf("unknown note type %q; types are %s", e.Type, strings.Join(e.Types, ", "))
+}
+
+// Is reports whether target is ErrUnknownNoteType
+func (e *UnknownNoteTypeError) Is(target error) bool {
+	return target == ErrUnknownNoteType
+}
+
+// Validate reports rules without a type or a condition, malformed globs
+// and unsupported property conditions
+func (s NoteTypeSettings) Validate() error {
+	for i, rule := range s.Rules {
+		if strings.TrimSpace(rule.Type) == "" {
+			return fmt.Errorf("rule %d: missing type", i+1)
+		}
+		if rule.Path == "" && len(rule.Properties) == 0 {
+			return fmt.Errorf("rule %d (%s): set path, properties or both", i+1, rule.Type)
+		}
+		if err := validateGlobs(cleanGlobs([]string{rule.Path})); err != nil {
+			return fmt.Errorf("rule %d (%s): %w", i+1, rule.Type, err)
+		}
+		for key, expr := range rule.Properties {
+			if _, err := ParsePropertyFilter(key, expr); err != nil {
+				return fmt.Errorf("rule %d (%s): %w", i+1, rule.Type, err)
+			}
+		}
+	}
+	return nil
+}
+
+// WithNoteTypes classifies notes into types, as checked by
+// NoteTypeSettings.Validate; invalid rules are ignored. Without rules
+// notes have no type.
+func WithNoteTypes(s NoteTypeSettings) Option {
+	return func(v *vault) {
+		v.types = s.compile()
+	}
+}
+
+// typeRule is a NoteTypeRule ready to match
+type typeRule struct {
+	NoteTypeRule
+	globs   []string // The cleaned path glob, empty for any path
+	filters []PropertyFilter
+}
+
+// noteTypes classifies notes with the configured rules
+type noteTypes struct {
+	rules    []typeRule
+	fallback string // Type of notes no rule matches
+}
+
+// compile returns the valid rules of s ready to match
+func (s NoteTypeSettings) compile() noteTypes {
+	types := noteTypes{fallback: cmp.Or(strings.TrimSpace(s.Default), DefaultNoteType)}
+	for _, rule := range s.Rules {
+		if (NoteTypeSettings{Rules: []NoteTypeRule{rule}}).Validate() != nil {
+			continue
+		}
+		compiled := typeRule{NoteTypeRule: rule, globs: cleanGlobs([]string{rule.Path})}
+		compiled.Type = strings.TrimSpace(rule.Type)
+		for _, key := range slices.Sorted(maps.Keys(rule.Properties)) {
+			filter, _ := ParsePropertyFilter(key, rule.Properties[key])
+			compiled.filters = append(compiled.filters, filter)
+		}
+		types.rules = append(types.rules, compiled)
+	}
+	return types
+}
+
+// configured reports whether notes are classified at all
+func (t noteTypes) configured() bool {
+	return len(t.rules) > 0
+}
+
+// classify returns the type of the note at relPath with the frontmatter
+// properties, "" when no types are configured. It reads nothing.
+func (t noteTypes) classify(relPath string, properties map[string]any) string {
+	if !t.configured() {
+		return ""
+	}
+	for _, rule := range t.rules {
+		if len(rule.globs) > 0 && !matchesGlob(rule.globs, relPath) {
+			continue
+		}
+		if matchProperties(properties, rule.filters) {
+			return rule.Type
+		}
+	}
+	return t.fallback
+}
+
+// names returns the configured types in the order their first rule
+// applies, followed by the default type
+func (t noteTypes) names() []string {
+	var names []string
+	for _, rule := range t.rules {
+		if !slices.Contains(names, rule.Type) {
+			names = append(names, rule.Type)
+		}
+	}
+	if t.configured() && !slices.Contains(names, t.fallback) {
+		names = append(names, t.fallback)
+	}
+	return names
+}
+
+// filter returns match narrowed to notes of noteType, match itself when
+// noteType is empty
+func (t noteTypes) filter(noteType string, match matchFunc) matchFunc {
+	if noteType == "" {
+		return match
+	}
+	return func(file noteFile, entry CacheEntry) bool {
+		return t.classify(file.relPath, entry.Properties) == noteType && (match == nil || match(file, entry))
+	}
+}
+
+// check fails with an *UnknownNoteTypeError unless noteType is empty or
+// one of the configured types
+func (t noteTypes) check(noteType string) error {
+	if noteType == "" || slices.Contains(t.names(), noteType) {
+		return nil
+	}
+	return &UnknownNoteTypeError{Type: noteType, Types: t.names()}
+}
+
+// NoteTypes returns the configured note types with the number of notes
+// of each under subpath, in the order their first rule applies and the
+// default type last. Notes are classified from the cache.
+func (v *vault) NoteTypes(ctx context.Context, subpath string) ([]NoteTypeCount, error) {
+	counts := make(map[string]int)
+	var mu sync.Mutex
+	_, err := v.walkNotes(ctx, ListOptions{Subpath: subpath, Recursive: true}, func(file noteFile, entry CacheEntry) bool {
+		noteType := v.types.classify(file.relPath, entry.Properties)
+		mu.Lock()
+		counts[noteType]++
+		mu.Unlock()
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	types := []NoteTypeCount{}
+	for _, name := range v.types.names() {
+		count := NoteTypeCount{Type: name, Notes: counts[name], Default: name == v.types.fallback}
+		for _, rule := range v.types.rules {
+			if rule.Type == name {
+				count.Rules = append(count.Rules, rule.NoteTypeRule)
+			}
+		}
+		types = append(types, count)
+	}
+	return types, nil
+}
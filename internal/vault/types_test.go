@@ -0,0 +1,98 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestNoteTypes(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"Daily/2024-03-01.md": "- [ ] Call Ann\n",
+		"Daily/2024-03-02.md": "---\ncategory: person\n---\n- [x] Met Bob\n",
+		"People/Ann.md":       "---\ncategory: person\n---\n- [ ] Ask about the trip\n",
+		"People/Index.md":     "All people\n",
+		"Ideas.md":            "- [ ] Write more\n",
+	})
+	settings := NoteTypeSettings{Rules: []NoteTypeRule{
+		{Type: "daily", Path: "Daily"},
+		{Type: "person", Properties: map[string]any{"category": "person"}},
+		{Type: "broken"}, // Ignored: no condition
+	}}
+	if err := settings.Validate(); err == nil {
+		t.Errorf("Validate() of a rule without a condition succeeded")
+	}
+	v, err := NewVault(tmpDir, WithNoteTypes(settings))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+
+	// The first matching rule applies: a daily note with person
+	// frontmatter is still a daily note
+	notes, err := v.List(ctx, ListOptions{Recursive: true})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	types := make(map[string]string)
+	for _, note := range notes {
+		types[note.Path] = note.Type
+	}
+	want := map[string]string{
+		"Daily/2024-03-01.md": "daily",
+		"Daily/2024-03-02.md": "daily",
+		"People/Ann.md":       "person",
+		"People/Index.md":     DefaultNoteType,
+		"Ideas.md":            DefaultNoteType,
+	}
+	for path, noteType := range want {
+		if types[path] != noteType {
+			t.Errorf("Type of %s = %q, want %q", path, types[path], noteType)
+		}
+	}
+
+	notes, err = v.List(ctx, ListOptions{Recursive: true, Type: "person"})
+	if err != nil || !slices.Equal(notePaths(notes), []string{"People/Ann.md"}) {
+		t.Errorf("List() of persons = %v, %v; want People/Ann.md", notePaths(notes), err)
+	}
+	notes, err = v.Search(ctx, SearchOptions{Query: "e", Type: DefaultNoteType})
+	if err != nil || !slices.Equal(notePaths(notes), []string{"Ideas.md", "People/Index.md"}) {
+		t.Errorf("Search() of untyped notes = %v, %v", notePaths(notes), err)
+	}
+	tasks, err := v.FindTasks(ctx, TaskOptions{Status: TaskStatusOpen, Type: "daily"})
+	if err != nil || len(tasks) != 1 || tasks[0].Path != "Daily/2024-03-01.md" {
+		t.Errorf("FindTasks() of daily notes = %+v, %v", tasks, err)
+	}
+
+	var unknown *UnknownNoteTypeError
+	_, err = v.List(ctx, ListOptions{Type: "meeting"})
+	if !errors.Is(err, ErrUnknownNoteType) || !errors.As(err, &unknown) || !slices.Equal(unknown.Types, []string{"daily", "person", DefaultNoteType}) {
+		t.Errorf("List() of an unknown type error = %v, want the configured types", err)
+	}
+
+	counts, err := v.NoteTypes(ctx, "")
+	if err != nil {
+		t.Fatalf("NoteTypes() error = %v", err)
+	}
+	got := make(map[string]int)
+	for _, count := range counts {
+		got[count.Type] = count.Notes
+	}
+	if len(counts) != 3 || got["daily"] != 2 || got["person"] != 1 || got[DefaultNoteType] != 2 || !counts[2].Default || len(counts[0].Rules) != 1 {
+		t.Errorf("NoteTypes() = %+v", counts)
+	}
+
+	// Without rules notes have no type
+	plain, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	if notes, err = plain.List(ctx, ListOptions{Recursive: true}); err != nil || notes[0].Type != "" {
+		t.Errorf("List() without types = %+v, %v; want no type", notes, err)
+	}
+	if counts, err = plain.NoteTypes(ctx, ""); err != nil || len(counts) != 0 {
+		t.Errorf("NoteTypes() without types = %+v, %v; want none", counts, err)
+	}
+}
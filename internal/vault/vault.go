@@ -2,53 +2,600 @@ package vault
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"strings"
 	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/text/encoding"
+
+	"github.com/kratos/mcp-notes/internal/metrics"
 )
 
 // NoteInfo represents metadata about a note
 type NoteInfo struct {
-	Path string   `json:"path"` // Relative path from vault root
-	Tags []string `json:"tags"` // Extracted tags from content
+	Path     string    `json:"path"`              // Relative path from vault root
+	Tags     []string  `json:"tags"`              // Extracted tags from content
+	Modified time.Time `json:"modified"`          // File modification time
+	Created  time.Time `json:"created"`           // Frontmatter created date, file birth time or Modified
+	Error    string    `json:"error,omitempty"`   // Why the file could not be indexed, e.g. malformed canvas
+	Excerpt  string    `json:"excerpt,omitempty"` // Start of the note, when a preview was requested
+
+	// ContentHash is the hex SHA-256 of the note's text, empty when Error is set
+	ContentHash string `json:"content_hash,omitempty"`
+
+	// Annotations are the note's stored annotations by key, when requested
+	Annotations map[string]Annotation `json:"annotations,omitempty"`
+
+	// Pinned is set for notes in the working set, see PinNote
+	Pinned bool `json:"pinned,omitempty"`
+
+	// Type is the note's type, see WithNoteTypes; empty without types
+	Type string `json:"type,omitempty"`
+
+	// Writable is false for notes whose file permissions forbid writing,
+	// and nil for all others
+	Writable *bool `json:"writable,omitempty"`
+
+	// Title is the note's title when it is not its file name, see
+	// WithTitleSources
+	Title string `json:"title,omitempty"`
+
+	// IsIndex is set for the note describing its folder when
+	// ListOptions.MarkIndexNotes asks for it, see WithIndexNotes
+	IsIndex bool `json:"is_index,omitempty"`
+}
+
+// SearchOptions describes the criteria for Search
+// All criteria are optional; zero values match every note
+type SearchOptions struct {
+	Query    string   // Pattern note content must match, same as a one-element QueryAll
+	Subpath  string   // Directory to search within, empty for the whole vault
+	TagsAny  []string // Notes must have at least one of these tags
+	TagsAll  []string // Notes must have all of these tags
+	TagsNone []string // Notes must have none of these tags
+
+	QueryAll  []string  // Patterns note content must all match
+	QueryAny  []string  // Patterns of which note content must match one
+	QueryNone []string  // Patterns note content must match none of
+	QueryMode QueryMode // How the patterns are read, regex when empty
+
+	// CaseSensitive matches the patterns exactly instead of regardless of
+	// case
+	CaseSensitive bool
+
+	// Properties are frontmatter conditions that must all hold
+	Properties []PropertyFilter
+
+	// Type limits the search to notes of this type, see WithNoteTypes
+	Type string
+
+	// NonRecursive limits the search to notes directly inside Subpath
+	NonRecursive bool
+
+	// IncludeHidden searches files and directories whose name starts with a dot
+	IncludeHidden bool
+
+	// IncludeCanvas also searches the text cards of .canvas files
+	IncludeCanvas bool
+
+	// PreviewLength adds an excerpt of up to this many characters to each
+	// result, 0 for none
+	PreviewLength int
+
+	// Timeout bounds how long the search may read notes, 0 for no limit
+	// When it elapses, Search returns the notes matched so far together
+	// with a *PartialResultsError
+	Timeout time.Duration
+
+	// IncludeAnnotations adds each result's annotations
+	IncludeAnnotations bool
+
+	// Sort orders the results, by path when empty. SortRelevance puts
+	// the notes with the most matches of Query, QueryAll and QueryAny first.
+	Sort NoteSort
+
+	// Collation compares paths, byte-wise when empty
+	Collation Collation
+
+	// PinnedFirst puts pinned notes ahead of the rest, each group in the
+	// order of Sort
+	PinnedFirst bool
+	// FirstN stops the search once this many notes match, checking the
+	// most recently modified notes first, and Cursor resumes it where an
+	// earlier search stopped; only SearchFirst applies them
+	FirstN int
+	Cursor string
+}
+
+// ListOptions selects the notes returned by List
+type ListOptions struct {
+	Subpath       string // Directory to list, empty for the vault root
+	Recursive     bool   // Include notes in subdirectories
+	IncludeHidden bool   // Include files and directories whose name starts with a dot
+	IncludeCanvas bool   // Include .canvas files, indexed by the text of their cards
+	PreviewLength int    // Excerpt length in characters, 0 for no excerpt
+	Type          string // Only notes of this type, see WithNoteTypes
+
+	// IncludeAnnotations adds each note's annotations
+	IncludeAnnotations bool
+
+	// Filter narrows the listing; only List applies it
+	Filter NoteFilter
+
+	// Sort and Collation order the listing, by byte-wise path when empty,
+	// and PinnedFirst puts pinned notes ahead of the rest; only List
+	// applies them
+	Sort        NoteSort
+	Collation   Collation
+	PinnedFirst bool
+
+	// MarkIndexNotes sets IsIndex on each folder's index note; only List
+	// applies it
+	MarkIndexNotes bool
 }
 
 // Vault provides operations for managing a collection of markdown notes
+// It is safe for concurrent use. Writes to the same note are serialized:
+// Create, Update and RestoreVersion each check, write and cache a note as
+// one step, so concurrent calls behave as if made one after another and
+// the cache never holds content other than the last written. Reads take
+// no write lock.
 type Vault interface {
-	// List returns all notes in the given subpath
-	// If recursive is true, includes notes from subdirectories
-	List(ctx context.Context, subpath string, recursive bool) ([]NoteInfo, error)
+	// List returns all notes selected by opts
+	// Notes are in byte-wise path order unless opts.Sort or opts.Collation
+	// say otherwise; the order is stable and the same on every run, and so
+	// is that of every other method returning a list of notes
+	List(ctx context.Context, opts ListOptions) ([]NoteInfo, error)
+
+	// FolderIndex returns the index note directly in folder, nil when it
+	// has none, whole when full is set and it is small, otherwise as an
+	// excerpt
+	FolderIndex(ctx context.Context, folder string, full bool) (*IndexNote, error)
 
 	// Search finds notes matching the query string and optional tag filters
 	// Query is matched against note content using regex
-	Search(ctx context.Context, query, subpath string, tags []string) ([]NoteInfo, error)
+	// Results are ordered as List orders them
+	// If opts.Timeout elapses first, the notes matched so far are returned
+	// with a *PartialResultsError
+	Search(ctx context.Context, opts SearchOptions) ([]NoteInfo, error)
+
+	// SearchFirst is Search returning as soon as opts.FirstN notes match,
+	// with a cursor resuming it. Notes are checked newest first and returned
+	// in that order unless opts.Sort is set.
+	SearchFirst(ctx context.Context, opts SearchOptions) (SearchPage, error)
 
 	// Read returns the content of a note
 	Read(ctx context.Context, path string) (string, error)
 
+	// ReadProse returns the content of a note, or only the prose of a
+	// note classified as a blob by WithBlobThresholds
+	ReadProse(ctx context.Context, path string) (ProseNote, error)
+
+	// ReadSection returns the part of a note under a heading, or a block
+	ReadSection(ctx context.Context, path string, opts SectionOptions) (Section, error)
+
+	// ReadExpanded returns the content of a note with embedded notes inlined
+	ReadExpanded(ctx context.Context, path string, opts ExpandOptions) (ExpandedNote, error)
+
+	// ReadMany reads several notes, reporting failures per note and
+	// truncating once the combined content exceeds maxBytes
+	ReadMany(ctx context.Context, paths []string, maxBytes int) ([]NoteContent, error)
+
+	// ReadDigest returns the notes matching a tag filter with as much of
+	// their content as a byte budget allows
+	ReadDigest(ctx context.Context, opts DigestOptions) (Digest, error)
+
 	// Create creates a new note with the given content
 	// Creates parent directories if they don't exist
 	Create(ctx context.Context, path, content string) error
 
 	// Update modifies an existing note
 	Update(ctx context.Context, path, content string) error
+
+	// ValidateCreate performs every check Create would without writing
+	ValidateCreate(ctx context.Context, path string) error
+
+	// SimilarNotes returns existing notes whose file name, title or alias
+	// is close to that of a new note at path, without reading any note
+	SimilarNotes(ctx context.Context, path string) ([]SimilarNote, error)
+
+	// ValidateUpdate performs every check Update would without writing
+	// Returns the current content of the note
+	ValidateUpdate(ctx context.Context, path string) (string, error)
+
+	// Stats returns aggregate statistics for notes in the given subpath
+	Stats(ctx context.Context, subpath string) (VaultStats, error)
+
+	// ListVersions returns the backed up versions of a note, newest first
+	ListVersions(ctx context.Context, path string) ([]NoteVersion, error)
+
+	// DiffNote returns the current content of a note and, when the cache
+	// or the backups still have it, its content at an earlier revision
+	DiffNote(ctx context.Context, path, revision string) (NoteDiff, error)
+
+	// Links returns the outgoing references of a note
+	Links(ctx context.Context, path string) ([]Link, error)
+
+	// RestoreVersion replaces a note's content with a backed up version
+	RestoreVersion(ctx context.Context, path, versionID string) error
+
+	// MaintenanceStatus reports the disk use of the trash, backups and
+	// audit log, and the size of the search index
+	MaintenanceStatus(ctx context.Context) (MaintenanceStatus, error)
+
+	// PruneBackups removes backups beyond a number per note or older than
+	// a time; files that cannot be removed are reported, not fatal
+	PruneBackups(ctx context.Context, opts PruneBackupsOptions) (CleanupResult, error)
+
+	// EmptyTrash removes notes trashed before a time, or all of them
+	EmptyTrash(ctx context.Context, opts EmptyTrashOptions) (CleanupResult, error)
+
+	// CompactIndex drops notes whose file no longer exists from the
+	// search index, failing with ErrIndexDisabled without one
+	CompactIndex(ctx context.Context, dryRun bool) (IndexCompaction, error)
+
+	// SaveIndex writes the search index to the data directory, to be
+	// loaded by vaults created with WithSearchIndex, failing with
+	// ErrIndexDisabled without one
+	SaveIndex(ctx context.Context) (IndexStats, error)
+
+	// Resolve finds notes by path, file name, frontmatter title or alias
+	Resolve(ctx context.Context, name string) (Resolution, error)
+
+	// FindNote ranks notes whose path fuzzily matches a query, without
+	// reading their content
+	FindNote(ctx context.Context, opts FuzzyOptions) ([]FuzzyMatch, error)
+
+	// Recent returns notes modified at or after opts.Since, newest first
+	// A limit of 0 or less returns all matching notes
+	Recent(ctx context.Context, opts RecentOptions) ([]NoteInfo, error)
+
+	// Analyze returns the word count, heading outline and tasks of a note
+	Analyze(ctx context.Context, path string) (NoteAnalysis, error)
+
+	// Outline returns the heading trees of a note or of the notes under
+	// a folder, ordered by path
+	Outline(ctx context.Context, opts OutlineOptions) (Outline, error)
+
+	// Chunks splits a note, or the notes under a folder a page at a time,
+	// into chunks with stable IDs for embedding
+	Chunks(ctx context.Context, opts ChunkOptions) (ChunkPage, error)
+
+	// FindTasks returns checkbox tasks selected by opts, grouped by note
+	FindTasks(ctx context.Context, opts TaskOptions) ([]NoteTasks, error)
+
+	// Related ranks other notes by shared tags, links and folder proximity
+	Related(ctx context.Context, opts RelatedOptions) ([]RelatedNote, error)
+
+	// SuggestPlacement ranks the folders a draft could be created in by
+	// tags, note type and title similarity, from cached metadata only
+	SuggestPlacement(ctx context.Context, opts PlacementOptions) (Placement, error)
+
+	// Stale returns notes not changed in a long time and rarely linked,
+	// stalest first, with the signals that flagged them
+	Stale(ctx context.Context, opts StaleOptions) (StaleReport, error)
+
+	// ActivityReport counts the notes created and modified per day in a
+	// date range, with their words, rolled up by weekday and month
+	ActivityReport(ctx context.Context, opts ActivityOptions) (ActivityReport, error)
+
+	// Info returns the vault name, note count, enabled features and cache usage
+	Info(ctx context.Context) (VaultInfo, error)
+
+	// RootFolders returns the vault folders inside the given absolute
+	// directories, "" when one of them holds the whole vault
+	RootFolders(dirs []string) []string
+
+	// Changes reports the notes created, modified or deleted since a time
+	// or since the call that returned a cursor
+	Changes(ctx context.Context, opts ChangesOptions) (ChangeSet, error)
+
+	// ListFolders returns the folders selected by opts with their note counts
+	ListFolders(ctx context.Context, opts FolderOptions) ([]FolderInfo, error)
+
+	// CreateFolder creates an empty folder and any missing parents
+	CreateFolder(ctx context.Context, path string) error
+
+	// RenameFolder moves a folder and everything in it, optionally
+	// rewriting links that point into it
+	RenameFolder(ctx context.Context, opts RenameFolderOptions) (FolderRename, error)
+
+	// SetAnnotation stores a value under key alongside a note without
+	// modifying it; an empty value removes the key
+	SetAnnotation(ctx context.Context, path, key, value string) error
+
+	// GetAnnotations returns a note's annotations by key, flagging those
+	// set before the note last changed
+	GetAnnotations(ctx context.Context, path string) (map[string]Annotation, error)
+
+	// SaveSearch stores a named search in the data directory after
+	// validating its query
+	SaveSearch(ctx context.Context, opts SaveSearchOptions) (SavedSearch, error)
+
+	// ListSavedSearches returns the saved searches sorted by name
+	ListSavedSearches(ctx context.Context) ([]SavedSearch, error)
+
+	// GetSavedSearch returns the saved search called name
+	GetSavedSearch(ctx context.Context, name string) (SavedSearch, error)
+
+	// DeleteSavedSearch removes the saved search called name
+	DeleteSavedSearch(ctx context.Context, name string) error
+
+	// PrepareContent returns content as Create (create set) or Update
+	// would write it, with the frontmatter template applied and checked
+	// against the schema
+	PrepareContent(path, content string, create bool) (string, error)
+
+	// MoveNote moves or renames a note, optionally rewriting the links
+	// to it
+	MoveNote(ctx context.Context, opts MoveNoteOptions) (NoteMove, error)
+
+	// MergeNotes combines one note into another, points links at the
+	// merged note and moves the source to the trash
+	MergeNotes(ctx context.Context, opts MergeOptions) (MergeResult, error)
+
+	// SplitNote moves each section of a note at a heading level into a
+	// note of its own, linked from an index note or from the source
+	SplitNote(ctx context.Context, opts SplitNoteOptions) (SplitResult, error)
+
+	// ApplyEdits performs a batch of creates, updates, appends, deletes
+	// and moves all or none, failing with a *BatchError
+	ApplyEdits(ctx context.Context, opts BatchOptions) (BatchResult, error)
+
+	// ReplaceInNotes replaces a pattern across the notes of a folder,
+	// reporting the outcome per note
+	ReplaceInNotes(ctx context.Context, opts ReplaceOptions) (ReplaceResult, error)
+
+	// Capture appends a timestamped entry under today's date heading of
+	// the inbox note, creating the note and the heading when missing
+	Capture(ctx context.Context, opts CaptureOptions) (CaptureResult, error)
+
+	// PinNote adds a note to the working set of pinned notes, which stay
+	// cached and can be listed first, for ttl or until unpinned when zero
+	PinNote(ctx context.Context, path string, ttl time.Duration) (PinSet, error)
+
+	// UnpinNote removes a note from the working set
+	UnpinNote(ctx context.Context, path string) (PinSet, error)
+
+	// ListPinned returns the working set of pinned notes
+	ListPinned(ctx context.Context) (PinSet, error)
+
+	// AddLink inserts a wikilink to one note into another at the end or
+	// under a heading, unless that place already links it
+	AddLink(ctx context.Context, opts AddLinkOptions) (AddLinkResult, error)
+
+	// AddAlias adds an alias to a note's frontmatter, rewriting only the
+	// aliases field, unless the note already has it
+	AddAlias(ctx context.Context, path, alias string) (AliasResult, error)
+
+	// RemoveAlias removes an alias from a note's frontmatter, rewriting
+	// only the fields holding it
+	RemoveAlias(ctx context.Context, path, alias string) (AliasResult, error)
+
+	// SyncTitles finds notes whose title and file name disagree and
+	// renames them after their titles or retitles them after their names
+	SyncTitles(ctx context.Context, opts SyncTitlesOptions) (SyncTitlesResult, error)
+
+	// PublishNote marks a note for publishing in its frontmatter, with
+	// the time it was marked
+	PublishNote(ctx context.Context, path string) (PublishResult, error)
+
+	// UnpublishNote clears the publish mark in a note's frontmatter
+	UnpublishNote(ctx context.Context, path string) (PublishResult, error)
+
+	// ListPublishable returns the marked notes not published as they are
+	// now and the published notes no longer marked
+	ListPublishable(ctx context.Context, subpath string) (PublishableReport, error)
+
+	// MarkPublished records a note's content hash as published, outside
+	// the note, or forgets a withdrawn note
+	MarkPublished(ctx context.Context, path, revision string) (MarkPublishedResult, error)
+
+	// Verify reports notes with unportable names, undecodable content,
+	// malformed frontmatter, broken links, empty or conflicted content
+	// and cache entries that disagree with disk
+	Verify(ctx context.Context, opts VerifyOptions) (VerifyReport, error)
+
+	// LintNote checks a note against the configured lint rules, fixing
+	// what the rules can fix when fix is set
+	LintNote(ctx context.Context, path string, fix bool) (LintResult, error)
+
+	// LintVault checks the notes under subpath against the lint rules
+	LintVault(ctx context.Context, subpath string) (LintReport, error)
+
+	// NoteTypes returns the configured note types with the number of
+	// notes of each under subpath
+	NoteTypes(ctx context.Context, subpath string) ([]NoteTypeCount, error)
+
+	// CreateScratch stores a draft as a scratch note outside the vault
+	CreateScratch(ctx context.Context, title, content string) (ScratchInfo, error)
+
+	// UpdateScratch replaces the content of a scratch note
+	UpdateScratch(ctx context.Context, id, title, content string) (ScratchInfo, error)
+
+	// ReadScratch returns a scratch note, or a section of it
+	ReadScratch(ctx context.Context, id string, opts SectionOptions) (ScratchNote, error)
+
+	// ListScratch returns the scratch notes with the space they use
+	ListScratch(ctx context.Context) (ScratchList, error)
+
+	// PromoteScratch writes a scratch note into the vault as a created or
+	// overwritten note and drops it from the scratch notes
+	PromoteScratch(ctx context.Context, opts PromoteScratchOptions) (ScratchPromotion, error)
+
+	// GenerateRollup renders a summary of the notes and tasks of a period
+	// and writes it to a note
+	GenerateRollup(ctx context.Context, opts RollupOptions) (RollupResult, error)
+
+	// ExportVault writes the notes selected by opts to w as a zip or tar
+	// archive with a manifest, returning the manifest
+	ExportVault(ctx context.Context, opts ArchiveOptions, w io.Writer) (ArchiveManifest, error)
+
+	// ListAttachments returns non-markdown files selected by opts
+	ListAttachments(ctx context.Context, opts AttachmentOptions) ([]AttachmentInfo, error)
+
+	// StatAttachment returns metadata about a single attachment
+	StatAttachment(ctx context.Context, path string) (AttachmentInfo, error)
+
+	// ReadCanvas returns the cards and connections of a .canvas file
+	ReadCanvas(ctx context.Context, path string) (Canvas, error)
+
+	// ReadAttachment returns the content of an attachment of at most maxBytes
+	ReadAttachment(ctx context.Context, path string, maxBytes int64) ([]byte, AttachmentInfo, error)
+
+	// LockNote leases a note to the holder set with HolderContext, so
+	// other holders cannot write it until the lease is released or expires
+	LockNote(ctx context.Context, opts LockOptions) (Lease, error)
+
+	// UnlockNote releases a note's lease, reporting false if it had none
+	UnlockNote(ctx context.Context, path string, force bool) (bool, error)
+
+	// AuditLog returns the recorded changes selected by q, newest first
+	AuditLog(ctx context.Context, q AuditQuery) ([]AuditEntry, error)
+
+	// Reconnect flushes the cache, the search index and the path listing
+	// and reports the notes that appeared or disappeared meanwhile
+	Reconnect(ctx context.Context) (Reconnection, error)
+
+	// StartWarmup loads notes into the cache in the background when
+	// WithWarmCache is set, until ctx is cancelled
+	StartWarmup(ctx context.Context)
 }
 
 // vault implements the Vault interface
 var _ Vault = (*vault)(nil)
 
 type vault struct {
-	basePath    string
-	cache       CacheInterface
-	regexCache  sync.Map // map[string]*regexp.Regexp for compiled regex patterns
+	basePath       string
+	vaultPath      string // The vault directory; basePath is the folder set with WithRoot inside it
+	root           string // Vault-relative folder the vault is limited to, empty for the whole vault
+	cache          CacheInterface
+	regexCache     sync.Map // map[string]*regexp.Regexp for compiled regex patterns
+	followSymlinks bool
+	includeHidden  bool // Always include dotfiles in walks
+	logger         *slog.Logger
+	metrics        metrics.Metrics // Receives cache, walk and read counters
+	cacheMaxBytes  int64           // Content limit of the cache, 0 for unbounded
+	concurrency    int             // Maximum number of files read in parallel
+	backupVersions int             // Versions kept per note, 0 disables backups
+	createdFields  []string        // Frontmatter properties holding the creation date
+	dateFormat     string          // Extra layout for frontmatter dates
+	indexNotes     []string        // File names of the note describing its folder
+	titleSources   TitleSources    // Where note titles come from
+
+	sourceEncodingName string            // Encoding of notes that are not UTF-8
+	sourceEncoding     encoding.Encoding // Resolved sourceEncodingName, nil if unset
+
+	index *searchIndex // Inverted word index for Search, nil when disabled
+
+	readOnlyPaths []string // Globs of paths that must not be written
+	writablePaths []string // Globs of the only paths that may be written, empty for all
+	writeLocks    writeLocks
+
+	batchLimits    BatchLimits     // Bounds of an ApplyEdits batch
+	blobThresholds BlobThresholds  // When a note is classified as a blob
+	capture        CaptureSettings // Where Capture writes and how
+	publish        PublishSettings // Properties PublishNote sets
+	lint           []appliedRule   // Lint rules applied, configured
+	types          noteTypes       // Note type rules, see WithNoteTypes
+
+	template *FrontmatterTemplate // Frontmatter added to created notes, nil when off
+	schema   FrontmatterSchema    // Rules for written frontmatter, empty when off
+
+	changes     changeLog       // Snapshots behind the cursors returned by Changes
+	annotations annotationStore // Annotations kept in the data directory
+	searches    searchStore     // Saved searches kept in the data directory
+	pins        pinStore        // Pinned notes kept in the data directory
+	scratch     scratchStore    // Drafts kept outside the vault
+	paths       pathListing     // Note paths for FindNote
+	loads       loadGroup       // Reads in progress, shared by concurrent cache misses
+	warmup      warmup          // Background cache warm-up, off unless WithWarmCache
+	leases      leaseStore      // Advisory note locks in the data directory
+	lockTTL     time.Duration   // Lease duration when LockNote is given none
+	audit       auditLog        // Record of the changes made to notes
+	liveness    availability    // Whether the vault directory was there at the last check
+
+	readObsidian     bool              // Read the .obsidian settings
+	obsidian         *ObsidianSettings // Settings read, nil without a .obsidian folder
+	ignoreFilters    []ignoreFilter    // Obsidian's excluded files, left out of walks
+	attachmentFolder string            // Set by WithAttachmentFolder
+}
+
+// Option configures optional vault behavior
+type Option func(*vault)
+
+// WithFollowSymlinks controls whether List and Search descend into
+// symlinked directories. Only links resolving inside the vault are followed.
+func WithFollowSymlinks(follow bool) Option {
+	return func(v *vault) {
+		v.followSymlinks = follow
+	}
+}
+
+// WithIncludeHidden makes walks always include files and directories whose
+// name starts with a dot, as if every call set IncludeHidden. By default
+// they are skipped like Obsidian does; explicit paths work either way.
+func WithIncludeHidden(include bool) Option {
+	return func(v *vault) {
+		v.includeHidden = include
+	}
+}
+
+// WithLogger sets the logger used for vault debug logging
+// By default vault operations are not logged
+func WithLogger(logger *slog.Logger) Option {
+	return func(v *vault) {
+		v.logger = logger
+	}
+}
+
+// WithMetrics makes the vault and its cache record cache hits, misses and
+// evictions, walk durations and notes read from disk in m
+// By default nothing is recorded
+func WithMetrics(m metrics.Metrics) Option {
+	return func(v *vault) {
+		v.metrics = m
+	}
+}
+
+// minConcurrency is the lower bound of the default read concurrency
+// Reading is I/O bound, so even single-CPU hosts benefit from overlap
+const minConcurrency = 8
+
+// WithConcurrency sets the maximum number of files List and Search read
+// in parallel. Values below 1 keep the default of GOMAXPROCS, at least 8.
+func WithConcurrency(n int) Option {
+	return func(v *vault) {
+		if n >= 1 {
+			v.concurrency = n
+		}
+	}
+}
+
+// WithCacheSize bounds the note cache to maxBytes of content, evicting
+// least recently used notes beyond it. Zero leaves the cache unbounded.
+func WithCacheSize(maxBytes int64) Option {
+	return func(v *vault) {
+		v.cache = NewBoundedCache(maxBytes, 0)
+		v.cacheMaxBytes = maxBytes
+	}
 }
 
 // NewVault creates a new vault instance
 // basePath must exist and be a valid directory
-func NewVault(basePath string) (Vault, error) {
+func NewVault(basePath string, opts ...Option) (Vault, error) {
 	// Validate base path exists
 	stat, err := os.Stat(basePath)
 	if err != nil {
@@ -65,77 +612,198 @@ func NewVault(basePath string) (Vault, error) {
 		return nil, fmt.Errorf("failed to resolve absolute path: %w", err)
 	}
 
-	return &vault{
-		basePath: absPath,
-		cache:    NewCache(),
-	}, nil
+	// Resolve symlinks so containment checks compare real paths
+	realPath, err := filepath.EvalSymlinks(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve symlinks: %w", err)
+	}
+
+	v := &vault{
+		basePath:       realPath,
+		vaultPath:      realPath,
+		cache:          NewCache(),
+		logger:         slog.New(slog.DiscardHandler),
+		metrics:        metrics.Nop{},
+		concurrency:    max(runtime.GOMAXPROCS(0), minConcurrency),
+		backupVersions: defaultBackupVersions,
+		createdFields:  defaultCreatedFields,
+		indexNotes:     DefaultIndexNotes,
+		titleSources:   DefaultTitleSources,
+		batchLimits:    BatchLimits{MaxOperations: DefaultBatchMaxOperations, MaxBytes: DefaultBatchMaxBytes},
+		blobThresholds: BlobThresholds{MinSize: DefaultBlobMinSize, LineLength: DefaultBlobLineLength, DataRatio: DefaultBlobDataRatio},
+		capture:        CaptureSettings{Note: DefaultCaptureNote, Entry: DefaultCaptureEntry, TimeFormat: DefaultCaptureTimeFormat, Heading: DefaultCaptureHeading},
+		publish:        PublishSettings{Field: DefaultPublishField, DateField: DefaultPublishDateField},
+		lint:           LintSettings{}.applied(),
+		lockTTL:        DefaultLockTTL,
+		readObsidian:   true,
+	}
+	v.scratch.settings = ScratchSettings{MaxNotes: DefaultScratchMaxNotes, MaxBytes: DefaultScratchMaxBytes}
+	v.audit.maxBytes = DefaultAuditMaxBytes
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	// The data directory is in the root the vault is limited to
+	if v.root != "" {
+		if err := v.enterRoot(); err != nil {
+			return nil, err
+		}
+	}
+	v.annotations.file = filepath.Join(v.basePath, dataDir, annotationsFile)
+	v.searches.file = filepath.Join(v.basePath, dataDir, searchesFile)
+	v.pins.file = filepath.Join(v.basePath, dataDir, pinsFile)
+	v.leases.dir = filepath.Join(v.basePath, dataDir, locksDir)
+	if v.audit.file == "" {
+		v.audit.file = filepath.Join(v.basePath, dataDir, auditFile)
+	}
+	if c, ok := v.cache.(*Cache); ok {
+		c.SetMetrics(v.metrics)
+	}
+
+	if v.sourceEncodingName != "" {
+		if v.sourceEncoding, err = lookupEncoding(v.sourceEncodingName); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateGlobs(append(v.readOnlyPaths, v.writablePaths...)); err != nil {
+		return nil, err
+	}
+
+	if v.readObsidian {
+		v.loadObsidianSettings()
+	}
+	if v.index != nil {
+		v.loadIndex()
+	}
+
+	return v, nil
 }
 
 // getOrCompileRegex retrieves a compiled regex from cache or compiles and caches it
-func (v *vault) getOrCompileRegex(pattern string) (*regexp.Regexp, error) {
+func (v *vault) getOrCompileRegex(expr string) (*regexp.Regexp, error) {
 	// Try to load from cache
-	if cached, ok := v.regexCache.Load(pattern); ok {
+	if cached, ok := v.regexCache.Load(expr); ok {
 		return cached.(*regexp.Regexp), nil
 	}
 
 	// Compile new regex
-	compiled, err := regexp.Compile("(?i)" + pattern) // Case-insensitive
+	compiled, err := regexp.Compile(expr)
 	if err != nil {
-		return nil, fmt.Errorf("invalid query regex: %w", err)
+		return nil, err
 	}
 
 	// Store in cache
-	v.regexCache.Store(pattern, compiled)
+	v.regexCache.Store(expr, compiled)
 	return compiled, nil
 }
 
-// validateSubpath validates a subpath and returns the full filesystem path
-// Used by List() and Search() for directory validation
-func (v *vault) validateSubpath(subpath string) (string, error) {
-	if subpath == "" {
-		return v.basePath, nil
+// drivePrefixRegex matches a Windows drive letter at the start of a path
+var drivePrefixRegex = regexp.MustCompile(`^[A-Za-z]:`)
+
+// cleanVaultPath normalizes a vault-relative path from a tool call the
+// same way on every platform: backslashes count as separators and the
+// result uses forward slashes. Absolute paths, including drive letters
+// and UNC shares, are rejected rather than joined under the vault.
+func cleanVaultPath(p string) (string, error) {
+	slashed := strings.ReplaceAll(p, `\`, "/")
+	if strings.HasPrefix(slashed, "/") || drivePrefixRegex.MatchString(slashed) {
+		return "", fmt.Errorf("%w: %q is absolute, use a path relative to the vault root", ErrInvalidPath, p)
 	}
 
 	// Clean the path to resolve . and ..
-	cleaned := filepath.Clean(subpath)
+	cleaned := path.Clean(slashed)
 
 	// Check for path traversal attempts
 	if strings.Contains(cleaned, "..") {
-		return "", ErrPathTraversal
+		return "", fmt.Errorf("%w: %s", ErrPathTraversal, cleaned)
+	}
+	return cleaned, nil
+}
+
+// resolveInVault joins a vault-relative path onto basePath and ensures the
+// result, with symlinks resolved, stays within the vault
+// Shared by validateDir and validatePath so all call sites behave identically
+func (v *vault) resolveInVault(path string) (string, error) {
+	cleaned, err := cleanVaultPath(path)
+	if err != nil {
+		return "", err
+	}
+	if err := v.checkAvailable(); err != nil {
+		return "", err
 	}
 
 	// Build full path
-	fullPath := filepath.Join(v.basePath, cleaned)
+	fullPath := filepath.Join(v.basePath, filepath.FromSlash(cleaned))
 
-	// Ensure the resolved path is still within basePath
-	if !strings.HasPrefix(fullPath, v.basePath) {
-		return "", ErrPathTraversal
+	// Ensure the resolved path is still within basePath, following any
+	// symlinks along the way
+	resolved, err := resolveSymlinks(fullPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path: %w", err)
+	}
+	if !v.withinBase(fullPath) || !v.withinBase(resolved) {
+		return "", fmt.Errorf("%w: %s", ErrPathTraversal, cleaned)
 	}
 
 	return fullPath, nil
 }
 
-// validatePath ensures the path is safe and returns the full filesystem path
-// Used for individual note operations (Read, Create, Update)
-func (v *vault) validatePath(path string) (string, error) {
+// validateDir validates a directory subpath and returns the full filesystem path
+// Used by List(), Search() and Stats() for directory validation
+func (v *vault) validateDir(subpath string) (string, error) {
+	if subpath == "" || subpath == rootFolder {
+		return v.basePath, v.checkAvailable()
+	}
+
+	fullPath, err := v.resolveInVault(subpath)
+	if err != nil {
+		return "", err
+	}
+
+	// Stat up front so a missing directory yields a clear error instead of
+	// a walk failure carrying the absolute host path
+	stat, err := os.Stat(fullPath)
+	if err != nil && !os.IsNotExist(err) && !errors.Is(err, syscall.ENOTDIR) {
+		return "", fmt.Errorf("failed to stat directory: %w", err)
+	}
+	if err != nil || !stat.IsDir() {
+		return "", &DirectoryNotFoundError{
+			Path:        filepath.ToSlash(filepath.Clean(subpath)),
+			Suggestions: v.suggestDirs(fullPath),
+		}
+	}
+
+	return fullPath, nil
+}
+
+// validateFile ensures a file path is safe and returns the full filesystem
+// path, without checking the file type
+// Shared by validatePath and the attachment and canvas variants
+func (v *vault) validateFile(path string) (string, error) {
 	if path == "" {
 		return "", ErrInvalidPath
 	}
 
-	// Clean the path to resolve . and ..
-	cleaned := filepath.Clean(path)
+	fullPath, err := v.resolveInVault(path)
+	if err != nil {
+		return "", err
+	}
 
-	// Check for path traversal attempts
-	if strings.Contains(cleaned, "..") {
-		return "", ErrPathTraversal
+	// Server data such as backups is only reachable through dedicated tools
+	if v.isDataPath(fullPath) {
+		return "", ErrReservedPath
 	}
 
-	// Build full path
-	fullPath := filepath.Join(v.basePath, cleaned)
+	return fullPath, nil
+}
 
-	// Ensure the resolved path is still within basePath
-	if !strings.HasPrefix(fullPath, v.basePath) {
-		return "", ErrPathTraversal
+// validatePath ensures the path is safe and returns the full filesystem path
+// Used for individual note operations (Read, Create, Update)
+func (v *vault) validatePath(path string) (string, error) {
+	fullPath, err := v.validateFile(path)
+	if err != nil {
+		return "", err
 	}
 
 	// Ensure it's a markdown file
@@ -146,179 +814,159 @@ func (v *vault) validatePath(path string) (string, error) {
 	return fullPath, nil
 }
 
-// List returns all notes in the given subpath
-func (v *vault) List(ctx context.Context, subpath string, recursive bool) ([]NoteInfo, error) {
-	// Validate and build search directory
-	searchPath, err := v.validateSubpath(subpath)
-	if err != nil {
+// List returns all notes selected by opts
+func (v *vault) List(ctx context.Context, opts ListOptions) ([]NoteInfo, error) {
+	filter := opts.Filter
+	if err := filter.validate(); err != nil {
+		return nil, err
+	}
+	if _, _, err := parseOrder(opts.Sort, opts.Collation, false); err != nil {
 		return nil, err
 	}
 
-	var notes []NoteInfo
-
-	walkFn := func(path string, info os.FileInfo, err error) error {
-		// Check context cancellation
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-		}
-
-		if err != nil {
-			// Skip inaccessible files/directories
-			return nil
-		}
-
-		// Skip directories
-		if info.IsDir() {
-			if !recursive && path != searchPath {
-				return filepath.SkipDir
+	// Rule out notes by name, size and mtime before reading any of them
+	skip := func(file noteFile) bool {
+		return !filter.matchesFile(file)
+	}
+	if v.index != nil {
+		q := indexQuery{tagsAll: tagTerms(filter.TagsAll), tagsAny: tagTerms(filter.TagsAny)}
+		if len(q.tagsAll) > 0 || len(q.tagsAny) > 0 {
+			indexSkip := v.index.skipper(q)
+			skip = func(file noteFile) bool {
+				return !filter.matchesFile(file) || indexSkip(file)
 			}
-			return nil
 		}
+	}
 
-		// Only include .md files
-		if !strings.HasSuffix(path, ".md") {
-			return nil
-		}
-
-		// Get relative path from vault root
-		relPath, err := filepath.Rel(v.basePath, path)
-		if err != nil {
-			return nil // Skip files we can't get relative path for
-		}
-
-		// Try to get tags from cache
-		var tags []string
-		if entry, ok := v.cache.Get(path); ok {
-			tags = entry.Tags
-		} else {
-			// Read file to extract tags
-			content, err := os.ReadFile(path)
-			if err != nil {
-				return nil // Skip unreadable files
-			}
-
-			tags = ExtractTags(string(content))
-			v.cache.Set(path, string(content), tags, info.ModTime())
+	var match matchFunc
+	if filter.hasTags() {
+		tagFilter := newTagFilter(filter.TagsAny, filter.TagsAll, filter.TagsNone)
+		match = func(_ noteFile, entry CacheEntry) bool {
+			return tagFilter.matches(entry.Tags)
 		}
-
-		notes = append(notes, NoteInfo{
-			Path: relPath,
-			Tags: tags,
-		})
-
-		return nil
 	}
 
-	if err := filepath.Walk(searchPath, walkFn); err != nil {
-		return nil, fmt.Errorf("failed to walk directory: %w", err)
+	notes, err := v.walkNotesSkipping(ctx, opts, skip, match)
+	if err != nil {
+		return nil, err
+	}
+	v.markPinned(ctx, notes)
+	if opts.MarkIndexNotes {
+		v.markIndexNotes(notes)
+	}
+	if err := sortNotes(notes, opts.Sort, opts.Collation, opts.PinnedFirst, nil); err != nil {
+		return nil, err
 	}
-
 	return notes, nil
 }
 
-// Search finds notes matching the query and optional tag filters
-func (v *vault) Search(ctx context.Context, query, subpath string, tags []string) ([]NoteInfo, error) {
-	// Validate and build search directory
-	searchPath, err := v.validateSubpath(subpath)
+// Search finds notes matching the query patterns and optional tag and
+// property filters, which all apply together
+func (v *vault) Search(ctx context.Context, opts SearchOptions) ([]NoteInfo, error) {
+	search, err := v.prepareSearch(opts)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get or compile query regex if provided
-	var queryRegex *regexp.Regexp
-	if query != "" {
-		var err error
-		queryRegex, err = v.getOrCompileRegex(query)
-		if err != nil {
-			return nil, err
-		}
+	// Only the search's own deadline yields partial results; cancellation by
+	// the caller still fails the search
+	searchCtx := ctx
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		searchCtx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
 	}
 
-	// Normalize tag filter to lowercase for comparison
-	tagFilter := make(map[string]struct{})
-	for _, tag := range tags {
-		tagFilter[strings.ToLower(tag)] = struct{}{}
+	notes, progress, err := v.scanNotes(searchCtx, search.scope, search.skip, search.match)
+	if err != nil && !(ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded)) {
+		return nil, err
+	}
+	v.markPinned(ctx, notes)
+	if sortErr := sortNotes(notes, opts.Sort, opts.Collation, opts.PinnedFirst, search.score); sortErr != nil {
+		return nil, sortErr
 	}
+	if err != nil {
+		return notes, &PartialResultsError{Scanned: progress.scanned, Total: progress.total}
+	}
+	return notes, nil
+}
 
-	var results []NoteInfo
+// noteSearch is a search ready to walk the vault
+type noteSearch struct {
+	scope ListOptions
+	skip  func(noteFile) bool // Notes the index rules out, nil without an index
+	match matchFunc
+	score func(NoteInfo) int // Matches of a matched note, nil unless sorting by relevance
+}
 
-	walkFn := func(path string, info os.FileInfo, err error) error {
-		// Check context cancellation
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-		}
+// prepareSearch compiles the patterns and filters of opts and checks its
+// order before any note is read
+func (v *vault) prepareSearch(opts SearchOptions) (noteSearch, error) {
+	// Get or compile the query patterns
+	queries, err := v.compileQueries(opts)
+	if err != nil {
+		return noteSearch{}, err
+	}
 
-		if err != nil {
-			return nil // Skip inaccessible files
-		}
+	tagFilter := newTagFilter(opts.TagsAny, opts.TagsAll, opts.TagsNone)
 
-		if info.IsDir() {
-			return nil
-		}
+	search := noteSearch{scope: ListOptions{
+		Subpath:       opts.Subpath,
+		Recursive:     !opts.NonRecursive,
+		IncludeHidden: opts.IncludeHidden,
+		IncludeCanvas: opts.IncludeCanvas,
+		PreviewLength: opts.PreviewLength,
+		Type:          opts.Type,
 
-		if !strings.HasSuffix(path, ".md") {
-			return nil
-		}
+		IncludeAnnotations: opts.IncludeAnnotations,
+	}}
 
-		// Read file content
-		var content string
-		var noteTags []string
-
-		if entry, ok := v.cache.Get(path); ok {
-			content = entry.Content
-			noteTags = entry.Tags
-		} else {
-			data, err := os.ReadFile(path)
-			if err != nil {
-				return nil // Skip unreadable files
-			}
-			content = string(data)
-			noteTags = ExtractTags(content)
-			v.cache.Set(path, content, noteTags, info.ModTime())
+	// Let the index rule out notes that cannot match without reading them
+	if v.index != nil {
+		if q, ok := newIndexQuery(opts); ok {
+			search.skip = v.index.skipper(q)
 		}
+	}
 
-		// Apply query filter
-		if queryRegex != nil && !queryRegex.MatchString(content) {
-			return nil
-		}
+	// Relevance counts the matches of each note as it is matched
+	var (
+		scoresMu sync.Mutex
+		scores   map[string]int
+	)
+	sortBy, _, err := parseOrder(opts.Sort, opts.Collation, true)
+	if err != nil {
+		return noteSearch{}, err
+	}
+	if sortBy == SortRelevance {
+		scores = make(map[string]int)
+		search.score = func(note NoteInfo) int { return scores[note.Path] }
+	}
 
+	// Parsed tags and properties are checked before scanning the content
+	search.match = func(file noteFile, entry CacheEntry) bool {
 		// Apply tag filter
-		if len(tagFilter) > 0 {
-			hasMatchingTag := false
-			for _, tag := range noteTags {
-				if _, exists := tagFilter[tag]; exists {
-					hasMatchingTag = true
-					break
-				}
-			}
-			if !hasMatchingTag {
-				return nil
-			}
+		if !tagFilter.matches(entry.Tags) {
+			return false
 		}
 
-		// Get relative path
-		relPath, err := filepath.Rel(v.basePath, path)
-		if err != nil {
-			return nil
+		// Apply frontmatter property filters
+		if !matchProperties(entry.Properties, opts.Properties) {
+			return false
 		}
 
-		results = append(results, NoteInfo{
-			Path: relPath,
-			Tags: noteTags,
-		})
-
-		return nil
-	}
-
-	if err := filepath.Walk(searchPath, walkFn); err != nil {
-		return nil, fmt.Errorf("failed to walk directory: %w", err)
+		// Apply query filters; only the prose of a blob is searched
+		if !queries.matches(entry.searchText()) {
+			return false
+		}
+		if scores != nil {
+			n := queries.score(entry.searchText())
+			scoresMu.Lock()
+			scores[file.relPath] = n
+			scoresMu.Unlock()
+		}
+		return true
 	}
-
-	return results, nil
+	return search, nil
 }
 
 // Read returns the content of a note
@@ -346,34 +994,328 @@ func (v *vault) Read(ctx context.Context, path string) (string, error) {
 	stat, err := os.Stat(fullPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return "", ErrNoteNotFound
+			return "", v.noteNotFound(ctx, fullPath)
 		}
 		return "", fmt.Errorf("failed to stat file: %w", err)
 	}
 
-	// Check cache first
+	// Check context cancellation before file read
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	default:
+	}
+
+	// Read through the cache
+	content, _, err := v.loadNote(fullPath, stat.ModTime())
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return content, nil
+}
+
+// loadNote returns the content and tags of the note at fullPath, serving
+// them from the cache when fresh and populating the cache otherwise
+func (v *vault) loadNote(fullPath string, mtime time.Time) (string, []string, error) {
+	entry, err := v.loadEntry(fullPath, mtime)
+	if err != nil {
+		return "", nil, err
+	}
+	return entry.Content, entry.Tags, nil
+}
+
+// loadEntry returns the note at fullPath with its parsed metadata, serving
+// it from the cache when fresh and populating the cache otherwise
+// The returned entry always carries the full content
+func (v *vault) loadEntry(fullPath string, mtime time.Time) (CacheEntry, error) {
 	if entry, ok := v.cache.Get(fullPath); ok {
-		return entry.Content, nil
+		if !entry.ContentOmitted {
+			v.logger.Debug("cache hit", "path", v.relPath(fullPath))
+			v.indexEntry(fullPath, entry)
+			return entry, nil
+		}
+
+		// Oversized note or blob: metadata is cached but content must come from disk
+		v.logger.Debug("cache hit without content", "path", v.relPath(fullPath))
+		fresh, err := v.readEntry(fullPath, mtime)
+		if err != nil {
+			return CacheEntry{}, err
+		}
+		entry.Content = fresh.Content
+		entry.ContentOmitted = false
+		v.indexEntry(fullPath, entry)
+		return entry, nil
 	}
+	v.logger.Debug("cache miss", "path", v.relPath(fullPath))
 
-	// Check context cancellation before file read
+	// Concurrent misses, such as a search during warm-up, share one read
+	return v.loads.do(fullPath, mtime, func() (CacheEntry, error) {
+		entry, err := v.readEntry(fullPath, mtime)
+		if err != nil {
+			if v.index != nil {
+				v.index.remove(fullPath)
+			}
+			return CacheEntry{}, err
+		}
+		entry.Created = v.resolveCreated(fullPath, entry.Properties, mtime)
+		v.cache.SetEntry(fullPath, entry)
+		v.indexEntry(fullPath, entry)
+		return entry, nil
+	})
+}
+
+// loadGroup lets concurrent loads of the same note version share one
+// read, so they cannot race to cache different results
+type loadGroup struct {
+	mu    sync.Mutex
+	calls map[loadKey]*loadCall
+}
+
+// loadKey identifies a note version
+type loadKey struct {
+	path  string
+	mtime int64
+}
+
+// loadCall is a load in progress
+type loadCall struct {
+	done  chan struct{}
+	entry CacheEntry
+	err   error
+}
+
+// do runs load unless a load of the same path and mtime is in progress,
+// in which case it waits for that one and returns its result
+func (g *loadGroup) do(path string, mtime time.Time, load func() (CacheEntry, error)) (CacheEntry, error) {
+	key := loadKey{path, mtime.UnixNano()}
+
+	g.mu.Lock()
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		<-call.done
+		return call.entry, call.err
+	}
+	if g.calls == nil {
+		g.calls = make(map[loadKey]*loadCall)
+	}
+	call := &loadCall{done: make(chan struct{})}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	// Release waiters even when load panics, which they see as a failure
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(call.done)
+	}()
+	call.err = fmt.Errorf("loading %s panicked", filepath.Base(path))
+	call.entry, call.err = load()
+
+	return call.entry, call.err
+}
+
+// indexEntry adds a loaded note to the search index unless it is already
+// indexed at the same modification time
+func (v *vault) indexEntry(fullPath string, entry CacheEntry) {
+	if v.index != nil && !v.index.has(fullPath, entry.Mtime) {
+		v.index.add(fullPath, entry.Mtime, entry.ContentHash, entry.searchText(), entry.Tags)
+	}
+}
+
+// readEntry reads and parses the note or canvas at fullPath
+func (v *vault) readEntry(fullPath string, mtime time.Time) (CacheEntry, error) {
+	data, err := os.ReadFile(fullPath)
+	if err != nil {
+		return CacheEntry{}, err
+	}
+	v.metrics.Add(metrics.FileReads, "", 1)
+	v.metrics.Add(metrics.BytesRead, "", uint64(len(data)))
+
+	content, _, err := v.decodeNote(data)
+	if err != nil {
+		return CacheEntry{}, err
+	}
+
+	if isCanvas(fullPath) {
+		return newCanvasEntry(content, mtime)
+	}
+	return v.newNoteEntry(content, mtime), nil
+}
+
+// cacheWritten caches content just written to fullPath so the next read
+// does not go back to disk
+func (v *vault) cacheWritten(fullPath, content string) {
+	stat, err := os.Stat(fullPath)
+	if err != nil {
+		return
+	}
+	entry := v.newNoteEntry(content, stat.ModTime())
+	entry.Created = v.resolveCreated(fullPath, entry.Properties, stat.ModTime())
+	v.cache.SetEntry(fullPath, entry)
+	v.indexEntry(fullPath, entry)
+}
+
+// writable reports whether the permissions of a file let its owner write
+// it. Only the mode is checked, as writeFileAtomic does, so the note looks
+// the same to every reader whoever runs the server.
+func writable(info os.FileInfo) bool {
+	return info.Mode().Perm()&0200 != 0
+}
+
+// permissionError returns ErrPermissionDenied for the note at fullPath
+// when the file system refused err, and err otherwise. The host path in
+// err is dropped.
+func (v *vault) permissionError(fullPath string, err error) error {
+	if errors.Is(err, os.ErrPermission) {
+		return fmt.Errorf("%w: %s", ErrPermissionDenied, v.relPath(fullPath))
+	}
+	return err
+}
+
+// writeFileAtomic replaces the existing file at path with data through a
+// temporary file in the same directory, so readers and crashes never see
+// it half written. The file keeps its mode, and a symlink is followed so
+// the link stays in place.
+func writeFileAtomic(path string, data []byte) error {
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return err
+	}
+	stat, err := os.Stat(target)
+	if err != nil {
+		return err
+	}
+	if !writable(stat) {
+		return &os.PathError{Op: "open", Path: target, Err: os.ErrPermission}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), stat.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+// createFileAtomic creates the file at path with data, failing with
+// os.ErrExist when there is one. The name is claimed with O_EXCL, so a
+// file created meanwhile is never overwritten, and the content replaces
+// the empty file through writeFileAtomic: readers see it empty or whole,
+// never half written. On failure the file is removed again.
+func createFileAtomic(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(path)
+		return err
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
+
+// createError reports a failed createFileAtomic of fullPath, as a
+// NoteExistsError when a note took the path after it was checked
+func (v *vault) createError(fullPath string, err error) error {
+	if errors.Is(err, os.ErrExist) {
+		if stat, statErr := os.Stat(fullPath); statErr == nil {
+			return &NoteExistsError{Path: v.relPath(fullPath), Size: stat.Size(), Modified: stat.ModTime()}
+		}
+	}
+	return fmt.Errorf("failed to write file: %w", v.permissionError(fullPath, err))
+}
+
+// newCacheEntry parses content into a cache entry
+func newCacheEntry(content string, mtime time.Time) CacheEntry {
+	fields := parseFrontmatter(content)
+	return CacheEntry{
+		Content:     content,
+		Tags:        ExtractTags(content),
+		Links:       ParseLinks(content),
+		Tasks:       ParseTasks(content),
+		Headings:    ParseHeadings(content),
+		Blocks:      ParseBlocks(content),
+		Title:       frontmatterTitle(fields),
+		Aliases:     frontmatterAliases(fields),
+		Properties:  fields,
+		Mtime:       mtime,
+		ContentHash: contentHash(content),
+	}
+}
+
+// contentHash returns the hex SHA-256 of content
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// relPath returns fullPath relative to the vault root with forward
+// slashes, as results and error messages show it, falling back to the
+// base name
+func (v *vault) relPath(fullPath string) string {
+	relPath, err := filepath.Rel(v.basePath, fullPath)
+	if err != nil {
+		return filepath.Base(fullPath)
+	}
+	return filepath.ToSlash(relPath)
+}
+
+// ValidateCreate performs every check Create would without writing
+func (v *vault) ValidateCreate(ctx context.Context, path string) error {
+	_, err := v.checkCreate(ctx, path)
+	return err
+}
+
+// checkCreate validates a note creation and returns the full path
+func (v *vault) checkCreate(ctx context.Context, path string) (string, error) {
 	select {
 	case <-ctx.Done():
 		return "", ctx.Err()
 	default:
 	}
 
-	// Read from filesystem
-	data, err := os.ReadFile(fullPath)
+	fullPath, err := v.validatePath(path)
 	if err != nil {
-		return "", fmt.Errorf("failed to read file: %w", err)
+		return "", err
+	}
+
+	if err := v.checkWritable(fullPath); err != nil {
+		return "", err
+	}
+	if err := v.checkLeases(ctx, fullPath); err != nil {
+		return "", err
+	}
+	if err := v.checkAudit(); err != nil {
+		return "", err
 	}
 
-	content := string(data)
-	tags := ExtractTags(content)
-	v.cache.Set(fullPath, content, tags, stat.ModTime())
+	// Check if file already exists
+	if stat, err := os.Stat(fullPath); err == nil {
+		return "", &NoteExistsError{Path: v.relPath(fullPath), Size: stat.Size(), Modified: stat.ModTime()}
+	}
 
-	return content, nil
+	return fullPath, nil
 }
 
 // Create creates a new note with the given content
@@ -383,30 +1325,99 @@ func (v *vault) Create(ctx context.Context, path, content string) error {
 		return err
 	}
 
-	// Check if file already exists
-	if _, err := os.Stat(fullPath); err == nil {
-		return fmt.Errorf("note already exists: %s", path)
+	// Check and write under the note's lock so a concurrent create cannot
+	// slip in between the existence check and the write
+	unlock := v.writeLocks.lock(fullPath)
+	defer unlock()
+
+	if _, err := v.checkCreate(ctx, path); err != nil {
+		return err
+	}
+
+	content, err = v.PrepareContent(v.relPath(fullPath), content, true)
+	if err != nil {
+		return err
+	}
+
+	// Check context cancellation before I/O; once writing starts it completes
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
 	}
 
+	return v.createNote(ctx, fullPath, content)
+}
+
+// createNote writes a new note at fullPath, creating its parent
+// directories, and records it in the audit log
+// Caller must hold the note's write lock and have checked the path
+func (v *vault) createNote(ctx context.Context, fullPath, content string) error {
 	// Create parent directories
 	dir := filepath.Dir(fullPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directories: %w", err)
+		return fmt.Errorf("failed to create directories: %w", v.permissionError(fullPath, err))
 	}
 
-	// Write file
-	if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+	// Write file; a note created since checkCreate is never overwritten
+	if err := createFileAtomic(fullPath, []byte(content)); err != nil {
+		return v.createError(fullPath, err)
 	}
 
 	// Update cache
+	v.cacheWritten(fullPath, content)
+	v.paths.invalidate()
+
+	return v.record(ctx, AuditEntry{Op: EditCreate, Path: v.relPath(fullPath), Bytes: len(content), Hash: contentHash(content)})
+}
+
+// ValidateUpdate performs every check Update would without writing and
+// returns the note's current content
+func (v *vault) ValidateUpdate(ctx context.Context, path string) (string, error) {
+	fullPath, err := v.checkUpdate(ctx, path)
+	if err != nil {
+		return "", err
+	}
+
+	return v.Read(ctx, v.relPath(fullPath))
+}
+
+// checkUpdate validates a note update and returns the full path
+func (v *vault) checkUpdate(ctx context.Context, path string) (string, error) {
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	default:
+	}
+
+	fullPath, err := v.validatePath(path)
+	if err != nil {
+		return "", err
+	}
+
+	if err := v.checkWritable(fullPath); err != nil {
+		return "", err
+	}
+	if err := v.checkLeases(ctx, fullPath); err != nil {
+		return "", err
+	}
+	if err := v.checkAudit(); err != nil {
+		return "", err
+	}
+
+	// Check if file exists
 	stat, err := os.Stat(fullPath)
-	if err == nil {
-		tags := ExtractTags(content)
-		v.cache.Set(fullPath, content, tags, stat.ModTime())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", v.noteNotFound(ctx, fullPath)
+		}
+		return "", fmt.Errorf("failed to stat file: %w", err)
+	}
+	if !writable(stat) {
+		return "", fmt.Errorf("%w: %s", ErrPermissionDenied, v.relPath(fullPath))
 	}
 
-	return nil
+	return fullPath, nil
 }
 
 // Update modifies an existing note
@@ -416,25 +1427,76 @@ func (v *vault) Update(ctx context.Context, path, content string) error {
 		return err
 	}
 
-	// Check if file exists
-	if _, err := os.Stat(fullPath); err != nil {
-		if os.IsNotExist(err) {
-			return ErrNoteNotFound
-		}
-		return fmt.Errorf("failed to stat file: %w", err)
+	// Read, back up, write and cache under the note's lock so concurrent
+	// updates cannot interleave and leave the cache out of step with disk
+	unlock := v.writeLocks.lock(fullPath)
+	defer unlock()
+
+	if _, err := v.checkUpdate(ctx, path); err != nil {
+		return err
+	}
+
+	content, err = v.PrepareContent(v.relPath(fullPath), content, false)
+	if err != nil {
+		return err
+	}
+
+	// Check context cancellation before I/O; once writing starts it completes
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	entry, err := v.writeNote(fullPath, content)
+	if err != nil {
+		return err
+	}
+	return v.record(ctx, entry)
+}
+
+// writeNote backs up and overwrites the existing note at fullPath,
+// returning the update for the audit log
+// Caller must hold the note's write lock and have checked the path
+func (v *vault) writeNote(fullPath, content string) (AuditEntry, error) {
+	// Write the note back with its original BOM, line endings and encoding
+	existing, err := os.ReadFile(fullPath)
+	if err != nil {
+		return AuditEntry{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	previous, format, err := v.decodeNote(existing)
+	if err != nil {
+		return AuditEntry{}, err
+	}
+	data, err := format.encode(content)
+	if err != nil {
+		return AuditEntry{}, err
+	}
+
+	// Keep a copy of the previous content; never overwrite without one
+	if err := v.backup(fullPath); err != nil {
+		return AuditEntry{}, err
 	}
 
 	// Write file
-	if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+	if err := writeFileAtomic(fullPath, data); err != nil {
+		return AuditEntry{}, fmt.Errorf("failed to write file: %w", v.permissionError(fullPath, err))
+	}
+	entry := AuditEntry{
+		Op:       EditUpdate,
+		Path:     v.relPath(fullPath),
+		Bytes:    len(content),
+		PrevHash: contentHash(previous),
+		Hash:     contentHash(content),
 	}
 
-	// Update cache
-	stat, err := os.Stat(fullPath)
-	if err == nil {
-		tags := ExtractTags(content)
-		v.cache.Set(fullPath, content, tags, stat.ModTime())
+	// Update cache with the content as it reads back
+	cached, _, err := v.decodeNote(data)
+	if err != nil {
+		v.cache.Delete(fullPath)
+		return entry, nil
 	}
+	v.cacheWritten(fullPath, cached)
 
-	return nil
+	return entry, nil
 }
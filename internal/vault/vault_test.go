@@ -3,8 +3,10 @@ package vault
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -122,6 +124,65 @@ func TestValidatePath(t *testing.T) {
 	}
 }
 
+func TestCleanVaultPath(t *testing.T) {
+	tests := []struct {
+		name      string
+		path      string
+		want      string
+		wantError error
+	}{
+		{"relative", "notes/test.md", "notes/test.md", nil},
+		{"backslashes", `Projects\Plan.md`, "Projects/Plan.md", nil},
+		{"mixed separators", `Projects\2024/Plan.md`, "Projects/2024/Plan.md", nil},
+		{"dot segments", `./notes\.\test.md`, "notes/test.md", nil},
+		{"unix absolute", "/etc/passwd.md", "", ErrInvalidPath},
+		{"drive absolute", `C:\vault\note.md`, "", ErrInvalidPath},
+		{"drive forward slashes", "c:/vault/note.md", "", ErrInvalidPath},
+		{"drive relative", "D:note.md", "", ErrInvalidPath},
+		{"UNC share", `\\server\share\note.md`, "", ErrInvalidPath},
+		{"rooted backslash", `\note.md`, "", ErrInvalidPath},
+		{"backslash traversal", `notes\..\..\secret.md`, "", ErrPathTraversal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cleanVaultPath(tt.path)
+			if !errors.Is(err, tt.wantError) {
+				t.Fatalf("cleanVaultPath(%q) error = %v, want %v", tt.path, err, tt.wantError)
+			}
+			if got != tt.want {
+				t.Errorf("cleanVaultPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWindowsStylePaths(t *testing.T) {
+	v, _ := setupTestVault(t)
+	ctx := context.Background()
+
+	content, err := v.Read(ctx, `subdir\deep\note4.md`)
+	if err != nil || !strings.Contains(content, "note 4") {
+		t.Errorf("Read() with backslashes = %q, %v", content, err)
+	}
+	if _, err := v.Read(ctx, `C:\vault\note1.md`); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("Read() of a drive path error = %v, want ErrInvalidPath", err)
+	}
+
+	notes, err := v.List(ctx, ListOptions{Subpath: `subdir\deep`, Recursive: true})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(notes) != 1 || notes[0].Path != "subdir/deep/note4.md" {
+		t.Errorf("List() = %+v, want subdir/deep/note4.md", notes)
+	}
+
+	// The root folder reported by list_folders is accepted back
+	if _, err := v.List(ctx, ListOptions{Subpath: rootFolder}); err != nil {
+		t.Errorf("List() of the root folder error = %v", err)
+	}
+}
+
 func setupTestVault(t *testing.T) (Vault, string) {
 	tmpDir := t.TempDir()
 
@@ -159,7 +220,7 @@ func TestList(t *testing.T) {
 	ctx := context.Background()
 
 	t.Run("list all non-recursive", func(t *testing.T) {
-		notes, err := v.List(ctx, "", false)
+		notes, err := v.List(ctx, ListOptions{Recursive: false})
 		if err != nil {
 			t.Fatalf("List() error = %v", err)
 		}
@@ -171,43 +232,55 @@ func TestList(t *testing.T) {
 	})
 
 	t.Run("list all recursive", func(t *testing.T) {
-		notes, err := v.List(ctx, "", true)
+		notes, err := v.List(ctx, ListOptions{Recursive: true})
 		if err != nil {
 			t.Fatalf("List() error = %v", err)
 		}
 
-		// Should include all .md files (6 total)
+		// Should include all visible .md files (5 total)
+		if len(notes) != 5 {
+			t.Errorf("Expected 5 notes, got %d", len(notes))
+		}
+	})
+
+	t.Run("list all recursive including hidden", func(t *testing.T) {
+		notes, err := v.List(ctx, ListOptions{Recursive: true, IncludeHidden: true})
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+
+		// Adds subdir/.hidden.md
 		if len(notes) != 6 {
 			t.Errorf("Expected 6 notes, got %d", len(notes))
 		}
 	})
 
 	t.Run("list subdir non-recursive", func(t *testing.T) {
-		notes, err := v.List(ctx, "subdir", false)
+		notes, err := v.List(ctx, ListOptions{Subpath: "subdir", Recursive: false})
 		if err != nil {
 			t.Fatalf("List() error = %v", err)
 		}
 
 		// Should only include subdir/note3.md
-		if len(notes) != 2 {
-			t.Errorf("Expected 2 notes, got %d", len(notes))
+		if len(notes) != 1 {
+			t.Errorf("Expected 1 note, got %d", len(notes))
 		}
 	})
 
 	t.Run("list subdir recursive", func(t *testing.T) {
-		notes, err := v.List(ctx, "subdir", true)
+		notes, err := v.List(ctx, ListOptions{Subpath: "subdir", Recursive: true})
 		if err != nil {
 			t.Fatalf("List() error = %v", err)
 		}
 
-		// Should include subdir/note3.md, subdir/deep/note4.md, and .hidden.md
-		if len(notes) != 3 {
-			t.Errorf("Expected 3 notes, got %d", len(notes))
+		// Should include subdir/note3.md and subdir/deep/note4.md, not .hidden.md
+		if len(notes) != 2 {
+			t.Errorf("Expected 2 notes, got %d", len(notes))
 		}
 	})
 
 	t.Run("list with path traversal", func(t *testing.T) {
-		_, err := v.List(ctx, "../../../etc", false)
+		_, err := v.List(ctx, ListOptions{Subpath: "../../../etc", Recursive: false})
 		if !errors.Is(err, ErrPathTraversal) {
 			t.Errorf("Expected ErrPathTraversal, got %v", err)
 		}
@@ -219,7 +292,7 @@ func TestSearch(t *testing.T) {
 	ctx := context.Background()
 
 	t.Run("search by content", func(t *testing.T) {
-		notes, err := v.Search(ctx, "note 1", "", nil)
+		notes, err := v.Search(ctx, SearchOptions{Query: "note 1"})
 		if err != nil {
 			t.Fatalf("Search() error = %v", err)
 		}
@@ -234,7 +307,7 @@ func TestSearch(t *testing.T) {
 	})
 
 	t.Run("search by tag", func(t *testing.T) {
-		notes, err := v.Search(ctx, "", "", []string{"tag1"})
+		notes, err := v.Search(ctx, SearchOptions{TagsAny: []string{"tag1"}})
 		if err != nil {
 			t.Fatalf("Search() error = %v", err)
 		}
@@ -246,7 +319,7 @@ func TestSearch(t *testing.T) {
 	})
 
 	t.Run("search by multiple tags", func(t *testing.T) {
-		notes, err := v.Search(ctx, "", "", []string{"tag2", "tag3"})
+		notes, err := v.Search(ctx, SearchOptions{TagsAny: []string{"tag2", "tag3"}})
 		if err != nil {
 			t.Fatalf("Search() error = %v", err)
 		}
@@ -258,7 +331,7 @@ func TestSearch(t *testing.T) {
 	})
 
 	t.Run("search by content and tag", func(t *testing.T) {
-		notes, err := v.Search(ctx, "subdir", "", []string{"tag1"})
+		notes, err := v.Search(ctx, SearchOptions{Query: "subdir", TagsAny: []string{"tag1"}})
 		if err != nil {
 			t.Fatalf("Search() error = %v", err)
 		}
@@ -269,15 +342,62 @@ func TestSearch(t *testing.T) {
 		}
 	})
 
+	t.Run("search with all tags", func(t *testing.T) {
+		notes, err := v.Search(ctx, SearchOptions{TagsAll: []string{"tag1", "tag2"}})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+
+		// Only note1.md has both tag1 and tag2
+		if len(notes) != 1 || notes[0].Path != "note1.md" {
+			t.Errorf("Expected only note1.md, got %v", notes)
+		}
+	})
+
+	t.Run("search excluding tags only", func(t *testing.T) {
+		notes, err := v.Search(ctx, SearchOptions{TagsNone: []string{"TAG1"}})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+
+		// 5 visible notes minus note1.md and subdir/note3.md
+		if len(notes) != 3 {
+			t.Errorf("Expected 3 notes without tag1, got %d", len(notes))
+		}
+		for _, note := range notes {
+			for _, tag := range note.Tags {
+				if tag == "tag1" {
+					t.Errorf("Excluded tag found in %s", note.Path)
+				}
+			}
+		}
+	})
+
+	t.Run("search combining any, all and none", func(t *testing.T) {
+		notes, err := v.Search(ctx, SearchOptions{
+			TagsAny:  []string{"tag1", "tag3"},
+			TagsAll:  []string{"tag2"},
+			TagsNone: []string{"tag3"},
+		})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+
+		// note1.md: tag1+tag2; note2.md excluded by tag3
+		if len(notes) != 1 || notes[0].Path != "note1.md" {
+			t.Errorf("Expected only note1.md, got %v", notes)
+		}
+	})
+
 	t.Run("search with invalid regex", func(t *testing.T) {
-		_, err := v.Search(ctx, "[invalid(", "", nil)
+		_, err := v.Search(ctx, SearchOptions{Query: "[invalid("})
 		if err == nil {
 			t.Error("Expected error for invalid regex")
 		}
 	})
 
 	t.Run("search in subpath", func(t *testing.T) {
-		notes, err := v.Search(ctx, "", "subdir", nil)
+		notes, err := v.Search(ctx, SearchOptions{Subpath: "subdir"})
 		if err != nil {
 			t.Fatalf("Search() error = %v", err)
 		}
@@ -291,6 +411,133 @@ func TestSearch(t *testing.T) {
 	})
 }
 
+func TestListSearchAgree(t *testing.T) {
+	v, tmpDir := setupTestVault(t)
+	ctx := context.Background()
+
+	// Server data and symlinks must be treated the same by both
+	if err := os.MkdirAll(filepath.Join(tmpDir, dataDir, backupDir), 0755); err != nil {
+		t.Fatalf("Failed to create data dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, dataDir, backupDir, "old.md"), []byte("backup"), 0644); err != nil {
+		t.Fatalf("Failed to create backup: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(tmpDir, "other"), filepath.Join(tmpDir, "subdir", "link")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	paths := func(notes []NoteInfo) []string {
+		result := make([]string, len(notes))
+		for i, note := range notes {
+			result[i] = note.Path
+		}
+		return result
+	}
+
+	for _, subpath := range []string{"", "subdir"} {
+		for _, recursive := range []bool{true, false} {
+			t.Run(fmt.Sprintf("path=%q recursive=%v", subpath, recursive), func(t *testing.T) {
+				listed, err := v.List(ctx, ListOptions{Subpath: subpath, Recursive: recursive})
+				if err != nil {
+					t.Fatalf("List() error = %v", err)
+				}
+
+				searched, err := v.Search(ctx, SearchOptions{Subpath: subpath, NonRecursive: !recursive})
+				if err != nil {
+					t.Fatalf("Search() error = %v", err)
+				}
+
+				if !slices.Equal(paths(listed), paths(searched)) {
+					t.Errorf("List() = %v, Search() = %v", paths(listed), paths(searched))
+				}
+			})
+		}
+	}
+
+	t.Run("non-recursive search", func(t *testing.T) {
+		notes, err := v.Search(ctx, SearchOptions{Query: "note", Subpath: "subdir", NonRecursive: true})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+
+		got := paths(notes)
+		want := []string{filepath.Join("subdir", "note3.md")}
+		if !slices.Equal(got, want) {
+			t.Errorf("Search() = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestHiddenFiles(t *testing.T) {
+	v, tmpDir := setupTestVault(t)
+	ctx := context.Background()
+
+	pluginNote := filepath.Join(tmpDir, ".obsidian", "workspace.md")
+	if err := os.MkdirAll(filepath.Dir(pluginNote), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(pluginNote, []byte("Plugin state #hidden"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	t.Run("hidden directories skipped by default", func(t *testing.T) {
+		notes, err := v.Search(ctx, SearchOptions{TagsAny: []string{"hidden"}})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		if len(notes) != 0 {
+			t.Errorf("Expected no hidden notes, got %v", notes)
+		}
+	})
+
+	t.Run("hidden notes included on request", func(t *testing.T) {
+		notes, err := v.Search(ctx, SearchOptions{TagsAny: []string{"hidden"}, IncludeHidden: true})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		if len(notes) != 2 {
+			t.Errorf("Expected 2 hidden notes, got %v", notes)
+		}
+	})
+
+	t.Run("vault option includes hidden notes", func(t *testing.T) {
+		hv, err := NewVault(tmpDir, WithIncludeHidden(true))
+		if err != nil {
+			t.Fatalf("Failed to create vault: %v", err)
+		}
+
+		notes, err := hv.List(ctx, ListOptions{Recursive: true})
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if len(notes) != 7 {
+			t.Errorf("Expected 7 notes, got %d", len(notes))
+		}
+	})
+
+	t.Run("explicit hidden directory can be listed", func(t *testing.T) {
+		notes, err := v.List(ctx, ListOptions{Subpath: ".obsidian"})
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if len(notes) != 1 {
+			t.Errorf("Expected 1 note, got %d", len(notes))
+		}
+	})
+
+	t.Run("explicit hidden paths can be read and written", func(t *testing.T) {
+		if _, err := v.Read(ctx, "subdir/.hidden.md"); err != nil {
+			t.Errorf("Read() error = %v", err)
+		}
+		if err := v.Update(ctx, "subdir/.hidden.md", "Updated"); err != nil {
+			t.Errorf("Update() error = %v", err)
+		}
+		if err := v.Create(ctx, ".drafts/new.md", "New"); err != nil {
+			t.Errorf("Create() error = %v", err)
+		}
+	})
+}
+
 func TestRead(t *testing.T) {
 	v, tmpDir := setupTestVault(t)
 	ctx := context.Background()
@@ -531,6 +778,82 @@ func TestUpdate(t *testing.T) {
 	})
 }
 
+func TestValidateWrite(t *testing.T) {
+	v, tmpDir := setupTestVault(t)
+	ctx := context.Background()
+
+	t.Run("validate create leaves filesystem untouched", func(t *testing.T) {
+		if err := v.ValidateCreate(ctx, "drafts/new.md"); err != nil {
+			t.Fatalf("ValidateCreate() error = %v", err)
+		}
+
+		if _, err := os.Stat(filepath.Join(tmpDir, "drafts")); !os.IsNotExist(err) {
+			t.Errorf("Expected no directory to be created, got %v", err)
+		}
+	})
+
+	t.Run("validate create of existing note", func(t *testing.T) {
+		if err := v.ValidateCreate(ctx, "note1.md"); err == nil {
+			t.Error("Expected error for existing note")
+		}
+	})
+
+	t.Run("validate update returns current content", func(t *testing.T) {
+		fullPath := filepath.Join(tmpDir, "note1.md")
+		before, err := os.ReadFile(fullPath)
+		if err != nil {
+			t.Fatalf("Failed to read file: %v", err)
+		}
+		statBefore, err := os.Stat(fullPath)
+		if err != nil {
+			t.Fatalf("Failed to stat file: %v", err)
+		}
+
+		content, err := v.ValidateUpdate(ctx, "note1.md")
+		if err != nil {
+			t.Fatalf("ValidateUpdate() error = %v", err)
+		}
+		if content != string(before) {
+			t.Errorf("Content = %q, want %q", content, before)
+		}
+
+		statAfter, err := os.Stat(fullPath)
+		if err != nil {
+			t.Fatalf("Failed to stat file: %v", err)
+		}
+		if !statAfter.ModTime().Equal(statBefore.ModTime()) {
+			t.Error("Expected note to be left unmodified")
+		}
+
+		versions, err := v.ListVersions(ctx, "note1.md")
+		if err != nil {
+			t.Fatalf("ListVersions() error = %v", err)
+		}
+		if len(versions) != 0 {
+			t.Errorf("Expected no backups, got %d", len(versions))
+		}
+	})
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr error
+	}{
+		{"nonexistent note", "nonexistent.md", ErrNoteNotFound},
+		{"path traversal", "../../../etc/passwd.md", ErrPathTraversal},
+		{"non-markdown file", "readme.txt", ErrNotMarkdown},
+	}
+
+	for _, tt := range tests {
+		t.Run("validate update "+tt.name, func(t *testing.T) {
+			_, err := v.ValidateUpdate(ctx, tt.path)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
 func TestContextCancellation(t *testing.T) {
 	v, _ := setupTestVault(t)
 
@@ -538,7 +861,7 @@ func TestContextCancellation(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		cancel() // Cancel immediately
 
-		_, err := v.List(ctx, "", true)
+		_, err := v.List(ctx, ListOptions{Recursive: true})
 		if !errors.Is(err, context.Canceled) {
 			t.Errorf("Expected context.Canceled, got %v", err)
 		}
@@ -548,7 +871,7 @@ func TestContextCancellation(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		cancel()
 
-		_, err := v.Search(ctx, "query", "", nil)
+		_, err := v.Search(ctx, SearchOptions{Query: "query"})
 		if !errors.Is(err, context.Canceled) {
 			t.Errorf("Expected context.Canceled, got %v", err)
 		}
@@ -559,7 +882,7 @@ func TestNoteInfoTags(t *testing.T) {
 	v, _ := setupTestVault(t)
 	ctx := context.Background()
 
-	notes, err := v.List(ctx, "", true)
+	notes, err := v.List(ctx, ListOptions{Recursive: true})
 	if err != nil {
 		t.Fatalf("List() error = %v", err)
 	}
@@ -592,3 +915,297 @@ func TestNoteInfoTags(t *testing.T) {
 		t.Error("Did not find note1.md in list")
 	}
 }
+
+func TestSymlinks(t *testing.T) {
+	v, tmpDir := setupTestVault(t)
+	ctx := context.Background()
+
+	// Directory outside the vault reachable through a symlink
+	outsideDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outsideDir, "secret.md"), []byte("Secret #outside"), 0644); err != nil {
+		t.Fatalf("Failed to create outside file: %v", err)
+	}
+	if err := os.Symlink(outsideDir, filepath.Join(tmpDir, "escape")); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+
+	// Internal symlink to a directory inside the vault
+	if err := os.Symlink(filepath.Join(tmpDir, "other"), filepath.Join(tmpDir, "linked")); err != nil {
+		t.Fatalf("Failed to create internal symlink: %v", err)
+	}
+
+	// Self-referencing symlink that would loop forever if followed blindly
+	if err := os.Symlink(tmpDir, filepath.Join(tmpDir, "subdir", "loop")); err != nil {
+		t.Fatalf("Failed to create loop symlink: %v", err)
+	}
+
+	t.Run("read through escaping symlink", func(t *testing.T) {
+		_, err := v.Read(ctx, "escape/secret.md")
+		if !errors.Is(err, ErrPathTraversal) {
+			t.Errorf("Expected ErrPathTraversal, got %v", err)
+		}
+	})
+
+	t.Run("create through escaping symlink", func(t *testing.T) {
+		err := v.Create(ctx, "escape/new/evil.md", "Content")
+		if !errors.Is(err, ErrPathTraversal) {
+			t.Errorf("Expected ErrPathTraversal, got %v", err)
+		}
+	})
+
+	t.Run("list through escaping symlink", func(t *testing.T) {
+		_, err := v.List(ctx, ListOptions{Subpath: "escape", Recursive: true})
+		if !errors.Is(err, ErrPathTraversal) {
+			t.Errorf("Expected ErrPathTraversal, got %v", err)
+		}
+	})
+
+	t.Run("read through internal symlink", func(t *testing.T) {
+		content, err := v.Read(ctx, "linked/note5.md")
+		if err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+		if !strings.Contains(content, "note 5") {
+			t.Errorf("Unexpected content: %s", content)
+		}
+	})
+
+	t.Run("symlinked directories not followed by default", func(t *testing.T) {
+		notes, err := v.List(ctx, ListOptions{Recursive: true})
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+
+		// Same 5 notes as without symlinks
+		if len(notes) != 5 {
+			t.Errorf("Expected 5 notes, got %d", len(notes))
+		}
+	})
+
+	t.Run("symlinked directories followed when enabled", func(t *testing.T) {
+		fv, err := NewVault(tmpDir, WithFollowSymlinks(true))
+		if err != nil {
+			t.Fatalf("Failed to create vault: %v", err)
+		}
+
+		notes, err := fv.List(ctx, ListOptions{Recursive: true})
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+
+		// note5 is listed through both "other" and "linked"; the loop and
+		// escaping links are skipped
+		if len(notes) != 6 {
+			t.Errorf("Expected 6 notes, got %d: %v", len(notes), notes)
+		}
+
+		for _, note := range notes {
+			if strings.Contains(note.Path, "secret") {
+				t.Errorf("Note outside the vault was listed: %s", note.Path)
+			}
+		}
+
+		results, err := fv.Search(ctx, SearchOptions{Query: "note 5", Subpath: "linked"})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		if len(results) != 1 || results[0].Path != filepath.Join("linked", "note5.md") {
+			t.Errorf("Expected linked/note5.md, got %v", results)
+		}
+	})
+}
+
+func TestValidateDir(t *testing.T) {
+	parent := t.TempDir()
+	vaultDir := filepath.Join(parent, "notes")
+	siblingDir := filepath.Join(parent, "notes-private")
+	for _, dir := range []string{vaultDir, siblingDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(siblingDir, "private.md"), []byte("Private"), 0644); err != nil {
+		t.Fatalf("Failed to create sibling file: %v", err)
+	}
+
+	v, err := NewVault(vaultDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	vaultImpl := v.(*vault)
+
+	t.Run("dot subpath is vault root", func(t *testing.T) {
+		got, err := vaultImpl.validateDir(".")
+		if err != nil {
+			t.Fatalf("validateDir() error = %v", err)
+		}
+		if got != vaultImpl.basePath {
+			t.Errorf("validateDir(\".\") = %s, want %s", got, vaultImpl.basePath)
+		}
+	})
+
+	t.Run("slash subpath is vault root", func(t *testing.T) {
+		got, err := vaultImpl.validateDir("/")
+		if err != nil {
+			t.Fatalf("validateDir() error = %v", err)
+		}
+		if got != vaultImpl.basePath {
+			t.Errorf("validateDir(\"/\") = %s, want %s", got, vaultImpl.basePath)
+		}
+	})
+
+	t.Run("sibling directory sharing vault prefix", func(t *testing.T) {
+		realSibling, err := filepath.EvalSymlinks(siblingDir)
+		if err != nil {
+			t.Fatalf("Failed to resolve sibling: %v", err)
+		}
+		if vaultImpl.withinBase(realSibling) {
+			t.Errorf("withinBase(%s) = true for sibling of %s", realSibling, vaultImpl.basePath)
+		}
+		if vaultImpl.withinBase(filepath.Join(realSibling, "private.md")) {
+			t.Error("withinBase() = true for file in sibling directory")
+		}
+	})
+
+	t.Run("symlink to sibling directory", func(t *testing.T) {
+		if err := os.Symlink(siblingDir, filepath.Join(vaultDir, "private")); err != nil {
+			t.Skipf("Symlinks not supported: %v", err)
+		}
+
+		if _, err := vaultImpl.validateDir("private"); !errors.Is(err, ErrPathTraversal) {
+			t.Errorf("validateDir() expected ErrPathTraversal, got %v", err)
+		}
+		if _, err := vaultImpl.validatePath("private/private.md"); !errors.Is(err, ErrPathTraversal) {
+			t.Errorf("validatePath() expected ErrPathTraversal, got %v", err)
+		}
+	})
+
+	t.Run("list and search accept dot and slash", func(t *testing.T) {
+		ctx := context.Background()
+		for _, subpath := range []string{".", "/"} {
+			if _, err := v.List(ctx, ListOptions{Subpath: subpath, Recursive: true}); err != nil {
+				t.Errorf("List(%q) error = %v", subpath, err)
+			}
+			if _, err := v.Search(ctx, SearchOptions{Subpath: subpath}); err != nil {
+				t.Errorf("Search(%q) error = %v", subpath, err)
+			}
+		}
+	})
+}
+
+func TestDirectoryNotFound(t *testing.T) {
+	v, _ := setupTestVault(t)
+	ctx := context.Background()
+
+	t.Run("list nonexistent directory", func(t *testing.T) {
+		_, err := v.List(ctx, ListOptions{Subpath: "subdri", Recursive: true})
+		if !errors.Is(err, ErrDirectoryNotFound) {
+			t.Fatalf("Expected ErrDirectoryNotFound, got %v", err)
+		}
+
+		var dirErr *DirectoryNotFoundError
+		if !errors.As(err, &dirErr) {
+			t.Fatalf("Expected *DirectoryNotFoundError, got %T", err)
+		}
+		if dirErr.Path != "subdri" {
+			t.Errorf("Path = %s, want subdri", dirErr.Path)
+		}
+		if len(dirErr.Suggestions) == 0 || dirErr.Suggestions[0] != "subdir" {
+			t.Errorf("Suggestions = %v, want subdir first", dirErr.Suggestions)
+		}
+	})
+
+	t.Run("search nonexistent nested directory", func(t *testing.T) {
+		_, err := v.Search(ctx, SearchOptions{Subpath: "subdir/deeep"})
+
+		var dirErr *DirectoryNotFoundError
+		if !errors.As(err, &dirErr) {
+			t.Fatalf("Expected *DirectoryNotFoundError, got %v", err)
+		}
+		if len(dirErr.Suggestions) == 0 || dirErr.Suggestions[0] != "subdir/deep" {
+			t.Errorf("Suggestions = %v, want subdir/deep first", dirErr.Suggestions)
+		}
+	})
+
+	t.Run("subpath is a file", func(t *testing.T) {
+		_, err := v.List(ctx, ListOptions{Subpath: "note1.md", Recursive: true})
+		if !errors.Is(err, ErrDirectoryNotFound) {
+			t.Errorf("Expected ErrDirectoryNotFound, got %v", err)
+		}
+	})
+
+	t.Run("error text has no absolute paths", func(t *testing.T) {
+		_, err := v.List(ctx, ListOptions{Subpath: "missing", Recursive: true})
+		if err == nil {
+			t.Fatal("Expected error")
+		}
+		if strings.Contains(err.Error(), string(os.PathSeparator)+"missing") {
+			t.Errorf("Error leaks filesystem path: %v", err)
+		}
+	})
+}
+
+func TestNoteErrorDetails(t *testing.T) {
+	v, _ := setupTestVault(t)
+	ctx := context.Background()
+
+	t.Run("missing note", func(t *testing.T) {
+		_, err := v.Read(ctx, "subdir/nte3.md")
+		var notFoundErr *NoteNotFoundError
+		if !errors.Is(err, ErrNoteNotFound) || !errors.As(err, &notFoundErr) {
+			t.Fatalf("Read() error = %v, want *NoteNotFoundError", err)
+		}
+		if notFoundErr.Path != "subdir/nte3.md" || len(notFoundErr.Suggestions) == 0 || notFoundErr.Suggestions[0] != "subdir/note3.md" {
+			t.Errorf("NoteNotFoundError = %+v, want subdir/note3.md suggested first", notFoundErr)
+		}
+
+		// Misspelt names are found by edit distance, hidden notes never
+		err = v.Update(ctx, "notr2.md", "x")
+		if !errors.As(err, &notFoundErr) || !slices.Contains(notFoundErr.Suggestions, "note2.md") || slices.Contains(notFoundErr.Suggestions, "subdir/.hidden.md") {
+			t.Errorf("Update() error = %#v, want note2.md suggested", err)
+		}
+	})
+
+	t.Run("existing note", func(t *testing.T) {
+		err := v.Create(ctx, "./note1.md", "x")
+		var existsErr *NoteExistsError
+		if !errors.Is(err, ErrNoteExists) || !errors.As(err, &existsErr) {
+			t.Fatalf("Create() error = %v, want *NoteExistsError", err)
+		}
+		if existsErr.Path != "note1.md" || existsErr.Size != int64(len("This is note 1 with #tag1 and #tag2")) || existsErr.Modified.IsZero() {
+			t.Errorf("NoteExistsError = %+v", existsErr)
+		}
+	})
+
+	t.Run("traversal", func(t *testing.T) {
+		_, err := v.Read(ctx, "subdir/../../outside.md")
+		if !errors.Is(err, ErrPathTraversal) || err.Error() != ErrPathTraversal.Error()+": ../outside.md" {
+			t.Errorf("Read() error = %v, want the cleaned relative path", err)
+		}
+	})
+}
+
+func TestReadOversizedNote(t *testing.T) {
+	tmpDir := t.TempDir()
+	v, err := NewVault(tmpDir, WithCacheSize(8))
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	ctx := context.Background()
+
+	content := "A note larger than the cache with #tag"
+	if err := v.Create(ctx, "big.md", content); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// Both reads must return full content, the second via a content-free entry
+	for i := 0; i < 2; i++ {
+		got, err := v.Read(ctx, "big.md")
+		if err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+		if got != content {
+			t.Errorf("Read() = %q, want %q", got, content)
+		}
+	}
+}
@@ -0,0 +1,325 @@
+package vault
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/unicode/norm"
+	"gopkg.in/yaml.v3"
+)
+
+// ProblemKind classifies a problem found by Verify
+type ProblemKind string
+
+// Kinds of problems
+const (
+	ProblemPath        ProblemKind = "path"           // File name not valid on every platform
+	ProblemEncoding    ProblemKind = "encoding"       // Content that cannot be decoded
+	ProblemFrontmatter ProblemKind = "frontmatter"    // Unterminated or invalid YAML frontmatter
+	ProblemBrokenLink  ProblemKind = "broken_link"    // Link to a file that does not exist
+	ProblemEmpty       ProblemKind = "empty"          // Zero bytes or only whitespace
+	ProblemConflict    ProblemKind = "sync_conflict"  // Conflict markers, or the name of a conflict copy
+	ProblemCaseClash   ProblemKind = "case_duplicate" // Path differing from another only by case
+	ProblemCacheDrift  ProblemKind = "cache_drift"    // Cached content that differs from disk
+	ProblemIndexDrift  ProblemKind = "index_drift"    // Search index entry that differs from disk
+)
+
+// Sync conflict patterns used when VerifyOptions leaves them nil
+var (
+	// DefaultConflictMarkers start the lines git and merge tools leave in
+	// conflicted files
+	DefaultConflictMarkers = []string{"<<<<<<<", ">>>>>>>"}
+
+	// DefaultConflictNames appear in the names of the copies Syncthing and
+	// Dropbox make of conflicted files
+	DefaultConflictNames = []string{".sync-conflict-", "conflicted copy"}
+)
+
+// VerifyOptions selects the notes Verify checks and how
+type VerifyOptions struct {
+	Subpath         string   // Folder to check, empty for the whole vault
+	ConflictMarkers []string // Line prefixes marking a sync conflict, DefaultConflictMarkers if nil
+	ConflictNames   []string // File name substrings marking a conflict copy, DefaultConflictNames if nil
+	Repair          bool     // Drop cache and index entries that disagree with disk
+}
+
+// Problem is one issue found in a note
+type Problem struct {
+	Path     string      `json:"path"`               // Vault-relative path of the note
+	Kind     ProblemKind `json:"kind"`               // Problem type
+	Line     int         `json:"line,omitempty"`     // 1-based line number, when known
+	Message  string      `json:"message"`            // Human-readable description
+	Repaired bool        `json:"repaired,omitempty"` // The drifted entry was dropped
+}
+
+// VerifyReport lists the problems found in the notes of a folder
+type VerifyReport struct {
+	NotesChecked int                 `json:"notes_checked"`
+	Counts       map[ProblemKind]int `json:"counts"`   // Problems of each kind found
+	Problems     []Problem           `json:"problems"` // Sorted by path and line
+}
+
+// Verify checks every note under opts.Subpath for file names that are
+// not portable or look like sync conflict copies, content that cannot be
+// decoded, is empty or holds conflict markers, malformed frontmatter,
+// wikilinks, embeds or markdown links whose target does not exist, and
+// paths that differ only by case. Cache and search index entries that
+// disagree with disk are checked first, so with Repair the walk reads
+// the notes they held afresh.
+func (v *vault) Verify(ctx context.Context, opts VerifyOptions) (VerifyReport, error) {
+	if opts.ConflictMarkers == nil {
+		opts.ConflictMarkers = DefaultConflictMarkers
+	}
+	if opts.ConflictNames == nil {
+		opts.ConflictNames = DefaultConflictNames
+	}
+
+	drift, err := v.verifyDrift(ctx, opts.Subpath, opts.Repair)
+	if err != nil {
+		return VerifyReport{}, err
+	}
+
+	index, err := v.buildFileIndex(ctx)
+	if err != nil {
+		return VerifyReport{}, err
+	}
+
+	report := VerifyReport{Problems: drift}
+
+	// Notes are loaded concurrently; collect under a lock
+	var mu sync.Mutex
+
+	notes, err := v.walkNotes(ctx, ListOptions{Subpath: opts.Subpath, Recursive: true}, func(file noteFile, entry CacheEntry) bool {
+		problems := verifyEntry(index, file.relPath, entry)
+		problems = append(problems, verifyContent(file, entry, opts)...)
+
+		mu.Lock()
+		defer mu.Unlock()
+		report.Problems = append(report.Problems, problems...)
+		return true
+	})
+	if err != nil {
+		return VerifyReport{}, err
+	}
+
+	report.NotesChecked = len(notes)
+	for _, note := range notes {
+		if sanitized, err := SanitizePath(note.Path); err != nil || sanitized != norm.NFC.String(filepath.ToSlash(note.Path)) {
+			report.Problems = append(report.Problems, Problem{
+				Path:    note.Path,
+				Kind:    ProblemPath,
+				Message: "File name contains characters or names that are invalid on Windows",
+			})
+		}
+		if note.Error != "" {
+			report.Problems = append(report.Problems, Problem{
+				Path:    note.Path,
+				Kind:    ProblemEncoding,
+				Message: note.Error,
+			})
+		}
+	}
+
+	report.Problems = append(report.Problems, caseClashes(notes)...)
+
+	slices.SortStableFunc(report.Problems, func(a, b Problem) int {
+		return cmp.Or(strings.Compare(a.Path, b.Path), cmp.Compare(a.Line, b.Line))
+	})
+
+	report.Counts = make(map[ProblemKind]int)
+	for _, problem := range report.Problems {
+		report.Counts[problem.Kind]++
+	}
+	return report, nil
+}
+
+// verifyDrift compares the cache and search index entries of notes under
+// subpath with disk, dropping those that differ when repair is set
+// Entries older than the file are left alone: the next read replaces them
+func (v *vault) verifyDrift(ctx context.Context, subpath string, repair bool) ([]Problem, error) {
+	root, err := v.validateDir(subpath)
+	if err != nil {
+		return nil, err
+	}
+
+	cached := v.cache.Stamps()
+	indexed := map[string]CacheStamp{}
+	if v.index != nil {
+		indexed = v.index.stamps()
+	}
+	paths := slices.Sorted(maps.Keys(cached))
+	for path := range indexed {
+		if _, ok := cached[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+
+	var problems []Problem
+	for _, fullPath := range paths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if !isWithin(fullPath, root) {
+			continue
+		}
+		stat, err := os.Stat(fullPath)
+		if err != nil {
+			continue // Gone files are never served from the cache
+		}
+
+		var disk string
+		for _, entries := range []struct {
+			stamps map[string]CacheStamp
+			kind   ProblemKind
+			drop   func(string)
+			name   string
+		}{
+			{cached, ProblemCacheDrift, v.cache.Delete, "Cached"},
+			{indexed, ProblemIndexDrift, func(path string) { v.index.remove(path) }, "Search index"},
+		} {
+			stamp, ok := entries.stamps[fullPath]
+			if !ok || !stamp.Mtime.Equal(stat.ModTime()) {
+				continue
+			}
+			if disk == "" {
+				entry, err := v.readEntry(fullPath, stat.ModTime())
+				if err != nil {
+					break // Reported by the walk
+				}
+				disk = entry.ContentHash
+			}
+			if stamp.ContentHash == disk {
+				continue
+			}
+			if repair {
+				entries.drop(fullPath)
+			}
+			problems = append(problems, Problem{
+				Path:     filepath.FromSlash(v.relPath(fullPath)),
+				Kind:     entries.kind,
+				Message:  fmt.Sprintf("%s content differs from the file although its modification time matches", entries.name),
+				Repaired: repair,
+			})
+		}
+	}
+	return problems, nil
+}
+
+// verifyContent returns the empty and sync conflict problems of one note
+func verifyContent(file noteFile, entry CacheEntry, opts VerifyOptions) []Problem {
+	var problems []Problem
+
+	name := strings.ToLower(filepath.Base(file.relPath))
+	for _, pattern := range opts.ConflictNames {
+		if pattern != "" && strings.Contains(name, strings.ToLower(pattern)) {
+			problems = append(problems, Problem{
+				Path:    file.relPath,
+				Kind:    ProblemConflict,
+				Message: fmt.Sprintf("File name contains %q, like a sync conflict copy", pattern),
+			})
+			break
+		}
+	}
+
+	switch {
+	case file.info.Size() == 0:
+		problems = append(problems, Problem{Path: file.relPath, Kind: ProblemEmpty, Message: "Note is empty"})
+	case entry.ContentOmitted:
+		// Content over the cache limit is not checked
+	case strings.TrimSpace(entry.Content) == "":
+		problems = append(problems, Problem{Path: file.relPath, Kind: ProblemEmpty, Message: "Note contains only whitespace"})
+	default:
+		for i, line := range strings.Split(entry.Content, "\n") {
+			marker := slices.IndexFunc(opts.ConflictMarkers, func(m string) bool {
+				return m != "" && strings.HasPrefix(line, m)
+			})
+			if marker >= 0 {
+				problems = append(problems, Problem{
+					Path:    file.relPath,
+					Kind:    ProblemConflict,
+					Line:    i + 1,
+					Message: fmt.Sprintf("Line starts with the sync conflict marker %q", opts.ConflictMarkers[marker]),
+				})
+				break
+			}
+		}
+	}
+
+	return problems
+}
+
+// caseClashes returns a problem for each note whose path differs from
+// another's only by case, which case-insensitive file systems and sync
+// clients cannot hold apart
+func caseClashes(notes []NoteInfo) []Problem {
+	groups := make(map[string][]string)
+	for _, note := range notes {
+		key := strings.ToLower(norm.NFC.String(filepath.ToSlash(note.Path)))
+		groups[key] = append(groups[key], note.Path)
+	}
+
+	var problems []Problem
+	for _, paths := range groups {
+		if len(paths) < 2 {
+			continue
+		}
+		slices.Sort(paths)
+		for _, path := range paths {
+			others := slices.DeleteFunc(slices.Clone(paths), func(p string) bool { return p == path })
+			problems = append(problems, Problem{
+				Path:    path,
+				Kind:    ProblemCaseClash,
+				Message: fmt.Sprintf("Path differs only by case from %s", strings.Join(others, ", ")),
+			})
+		}
+	}
+	return problems
+}
+
+// verifyEntry returns the frontmatter and link problems of one note
+func verifyEntry(index *fileIndex, relPath string, entry CacheEntry) []Problem {
+	var problems []Problem
+
+	if block, _, ok := SplitFrontmatter(entry.Content); ok {
+		var fields map[string]any
+		if err := yaml.Unmarshal([]byte(block), &fields); err != nil {
+			problems = append(problems, Problem{
+				Path:    relPath,
+				Kind:    ProblemFrontmatter,
+				Line:    1,
+				Message: fmt.Sprintf("Invalid YAML frontmatter: %v", err),
+			})
+		}
+	} else if strings.HasPrefix(entry.Content, "---\n") || strings.HasPrefix(entry.Content, "---\r\n") {
+		problems = append(problems, Problem{
+			Path:    relPath,
+			Kind:    ProblemFrontmatter,
+			Line:    1,
+			Message: "Frontmatter is not closed by a --- line",
+		})
+	}
+
+	source := filepath.ToSlash(relPath)
+	for _, link := range entry.Links {
+		if link.Kind == LinkURL {
+			continue
+		}
+		if _, ok := index.resolve(source, link.Target); !ok {
+			problems = append(problems, Problem{
+				Path:    relPath,
+				Kind:    ProblemBrokenLink,
+				Line:    link.Line,
+				Message: fmt.Sprintf("Link target %q does not exist", link.Target),
+			})
+		}
+	}
+
+	return problems
+}
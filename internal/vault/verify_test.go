@@ -0,0 +1,200 @@
+package vault
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	v, tmpDir := setupTestVault(t)
+	ctx := context.Background()
+
+	notes := map[string]string{
+		"links.md":        "[[note1]] [[subdir/note3#Heading]] ![[missing.png]]\n[text](note2.md) [gone](gone.md) https://example.com",
+		"bad-yaml.md":     "---\ntags: [open\n---\nBody",
+		"unclosed.md":     "---\ntitle: Never closed\nBody",
+		"good.md":         "---\ntitle: Fine\n---\nSee [[note3]]",
+		"Draft?.md":       "Name invalid on Windows",
+		"other/latin1.md": "caf\xe9",
+	}
+	for path, content := range notes {
+		if err := os.WriteFile(filepath.Join(tmpDir, path), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", path, err)
+		}
+	}
+
+	report, err := v.Verify(ctx, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	// The five test vault notes plus the six above
+	if report.NotesChecked != 11 {
+		t.Errorf("NotesChecked = %d, want 11", report.NotesChecked)
+	}
+
+	want := []struct {
+		path string
+		kind ProblemKind
+		line int
+	}{
+		{"Draft?.md", ProblemPath, 0},
+		{"bad-yaml.md", ProblemFrontmatter, 1},
+		{"links.md", ProblemBrokenLink, 1},
+		{"links.md", ProblemBrokenLink, 2},
+		{filepath.Join("other", "latin1.md"), ProblemEncoding, 0},
+		{"unclosed.md", ProblemFrontmatter, 1},
+	}
+	if len(report.Problems) != len(want) {
+		t.Fatalf("Problems = %+v, want %d", report.Problems, len(want))
+	}
+	for i, w := range want {
+		got := report.Problems[i]
+		if got.Path != w.path || got.Kind != w.kind || got.Line != w.line {
+			t.Errorf("Problems[%d] = %+v, want %s %s line %d", i, got, w.path, w.kind, w.line)
+		}
+	}
+
+	t.Run("subpath", func(t *testing.T) {
+		report, err := v.Verify(ctx, VerifyOptions{Subpath: "subdir"})
+		if err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+		if report.NotesChecked != 2 || len(report.Problems) != 0 {
+			t.Errorf("Verify(subdir) = %+v, want 2 notes without problems", report)
+		}
+	})
+}
+
+func TestVerifySyncDamage(t *testing.T) {
+	tmpDir := t.TempDir()
+	notes := map[string]string{
+		"empty.md":                       "",
+		"blank.md":                       " \n\t\n",
+		"merged.md":                      "# Plan\n<<<<<<< HEAD\nmine\n=======\ntheirs\n>>>>>>> remote\n",
+		"plan.sync-conflict-20240101.md": "# Plan",
+		"Inbox.md":                       "upper",
+		"inbox.md":                       "lower",
+		"fine.md":                        "Heading\n=======\n",
+	}
+	for path, content := range notes {
+		if err := os.WriteFile(filepath.Join(tmpDir, path), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", path, err)
+		}
+	}
+	if entries, _ := os.ReadDir(tmpDir); len(entries) != len(notes) {
+		t.Skip("File system is case-insensitive")
+	}
+	v, err := NewVault(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	ctx := context.Background()
+
+	report, err := v.Verify(ctx, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	want := []struct {
+		path string
+		kind ProblemKind
+		line int
+	}{
+		{"Inbox.md", ProblemCaseClash, 0},
+		{"blank.md", ProblemEmpty, 0},
+		{"empty.md", ProblemEmpty, 0},
+		{"inbox.md", ProblemCaseClash, 0},
+		{"merged.md", ProblemConflict, 2},
+		{"plan.sync-conflict-20240101.md", ProblemConflict, 0},
+	}
+	if len(report.Problems) != len(want) {
+		t.Fatalf("Problems = %+v, want %d", report.Problems, len(want))
+	}
+	for i, w := range want {
+		got := report.Problems[i]
+		if got.Path != w.path || got.Kind != w.kind || got.Line != w.line {
+			t.Errorf("Problems[%d] = %+v, want %s %s line %d", i, got, w.path, w.kind, w.line)
+		}
+	}
+	wantCounts := map[ProblemKind]int{ProblemCaseClash: 2, ProblemEmpty: 2, ProblemConflict: 2}
+	if !reflect.DeepEqual(report.Counts, wantCounts) {
+		t.Errorf("Counts = %v, want %v", report.Counts, wantCounts)
+	}
+
+	t.Run("custom markers", func(t *testing.T) {
+		report, err := v.Verify(ctx, VerifyOptions{ConflictMarkers: []string{"======="}, ConflictNames: []string{}})
+		if err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+		var conflicts []string
+		for _, problem := range report.Problems {
+			if problem.Kind == ProblemConflict {
+				conflicts = append(conflicts, problem.Path)
+			}
+		}
+		if want := []string{"fine.md", "merged.md"}; !reflect.DeepEqual(conflicts, want) {
+			t.Errorf("Conflicts = %v, want %v", conflicts, want)
+		}
+	})
+}
+
+func TestVerifyDrift(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "note.md")
+	if err := os.WriteFile(path, []byte("original words"), 0644); err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+	v, err := NewVault(tmpDir, WithSearchIndex())
+	if err != nil {
+		t.Fatalf("Failed to create vault: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := v.Read(ctx, "note.md"); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+
+	// Rewrite the note without changing its modification time, as some
+	// sync tools do
+	stat, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("synced words"), 0644); err != nil {
+		t.Fatalf("Failed to rewrite note: %v", err)
+	}
+	if err := os.Chtimes(path, stat.ModTime(), stat.ModTime()); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+
+	kinds := func(report VerifyReport) []ProblemKind {
+		var kinds []ProblemKind
+		for _, problem := range report.Problems {
+			kinds = append(kinds, problem.Kind)
+		}
+		return kinds
+	}
+	report, err := v.Verify(ctx, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if got, want := kinds(report), []ProblemKind{ProblemCacheDrift, ProblemIndexDrift}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Problems = %v, want %v", got, want)
+	}
+
+	report, err = v.Verify(ctx, VerifyOptions{Repair: true})
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if len(report.Problems) != 2 || !report.Problems[0].Repaired {
+		t.Errorf("Problems = %+v, want both repaired", report.Problems)
+	}
+	if content, err := v.Read(ctx, "note.md"); err != nil || content != "synced words" {
+		t.Errorf("Read after repair = %q, %v", content, err)
+	}
+	if report, err := v.Verify(ctx, VerifyOptions{}); err != nil || len(report.Problems) != 0 {
+		t.Errorf("Verify after repair = %+v, %v", report.Problems, err)
+	}
+}
@@ -0,0 +1,263 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kratos/mcp-notes/internal/metrics"
+)
+
+// withinBase reports whether path is the vault root or located inside it
+// Compares whole path components so sibling directories sharing the vault
+// prefix (e.g. /notes-private for vault /notes) are rejected
+func (v *vault) withinBase(path string) bool {
+	return isWithin(path, v.basePath)
+}
+
+// resolveSymlinks evaluates symlinks in path, tolerating a path whose
+// trailing components do not exist yet (e.g. a note about to be created)
+// The longest existing prefix is resolved and the remainder appended
+func resolveSymlinks(path string) (string, error) {
+	existing := path
+	var rest []string
+
+	for {
+		resolved, err := filepath.EvalSymlinks(existing)
+		if err == nil {
+			return filepath.Join(append([]string{resolved}, rest...)...), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return path, nil
+		}
+		rest = append([]string{filepath.Base(existing)}, rest...)
+		existing = parent
+	}
+}
+
+// walk traverses the tree rooted at root, calling fn for each file or
+// directory like filepath.Walk. Symlinks are handled as follows:
+//   - the server data directory is skipped
+//   - links resolving outside the vault or broken links are skipped
+//   - symlinked files are reported with the target's FileInfo
+//   - symlinked directories are descended into only when followSymlinks
+//     is enabled, skipping links that point back at a directory already
+//     on the current descent chain so cycles terminate
+//
+// Paths passed to fn are always the logical paths through the link, so
+// they remain relative to the vault root. A walk of a vault whose
+// directory is gone fails with ErrVaultUnavailable without calling fn.
+func (v *vault) walk(root string, fn filepath.WalkFunc) error {
+	if err := v.checkAvailable(); err != nil {
+		return err
+	}
+
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		info, _ := os.Lstat(root)
+		return fn(root, info, err)
+	}
+
+	// A walk asked for an excluded folder by name lists it anyway
+	filter := !v.isIgnored(root, true)
+
+	start := time.Now()
+	err = v.walkTree(root, realRoot, nil, filter, fn)
+	v.metrics.Observe(metrics.WalkDuration, "", time.Since(start))
+	v.logger.Debug("walk completed", "root", v.relPath(root), "duration", time.Since(start), "error", err)
+
+	return err
+}
+
+// walkTree walks realRoot, reporting paths rebased onto logicalRoot
+// ancestors holds the real directories containing each followed link;
+// filter leaves out the files Obsidian excludes
+func (v *vault) walkTree(logicalRoot, realRoot string, ancestors []string, filter bool, fn filepath.WalkFunc) error {
+	return filepath.Walk(realRoot, func(realPath string, info os.FileInfo, err error) error {
+		logicalPath := logicalRoot
+		if rel, relErr := filepath.Rel(realRoot, realPath); relErr == nil && rel != "." {
+			logicalPath = filepath.Join(logicalRoot, rel)
+		}
+
+		if err != nil {
+			return fn(logicalPath, info, err)
+		}
+
+		// Server data such as backups never shows up in walks, nor do
+		// files Obsidian excludes
+		if info.IsDir() && v.isDataPath(logicalPath) {
+			return filepath.SkipDir
+		}
+		if filter && v.isIgnored(logicalPath, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if info.Mode()&os.ModeSymlink == 0 {
+			return fn(logicalPath, info, nil)
+		}
+
+		target, err := filepath.EvalSymlinks(realPath)
+		if err != nil || !v.withinBase(target) {
+			return nil // Skip broken links and links escaping the vault
+		}
+
+		targetInfo, err := os.Stat(target)
+		if err != nil {
+			return nil
+		}
+
+		if !targetInfo.IsDir() {
+			return fn(logicalPath, targetInfo, nil)
+		}
+
+		if !v.followSymlinks {
+			return nil
+		}
+
+		// Cycle detection: a link pointing at or above any directory on
+		// the current chain would revisit it forever
+		chain := append(slices.Clip(ancestors), filepath.Dir(realPath))
+		for _, dir := range chain {
+			if isWithin(dir, target) {
+				return nil
+			}
+		}
+
+		return v.walkTree(logicalPath, target, chain, filter, fn)
+	})
+}
+
+// walkNotes finds the notes selected by scope and loads them through the
+// cache, returning those accepted by match (all when match is nil) in
+// walk order. It is the single entry point for operations that scan
+// notes, so validation, visibility rules and cancellation stay consistent.
+func (v *vault) walkNotes(ctx context.Context, scope ListOptions, match matchFunc) ([]NoteInfo, error) {
+	return v.walkNotesSkipping(ctx, scope, nil, match)
+}
+
+// walkNotesSkipping is walkNotes with a prefilter: notes for which skip
+// returns true are left out without being loaded
+func (v *vault) walkNotesSkipping(ctx context.Context, scope ListOptions, skip func(noteFile) bool, match matchFunc) ([]NoteInfo, error) {
+	notes, _, err := v.scanNotes(ctx, scope, skip, match)
+	if err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+// scanProgress reports how far a walk got before it finished or stopped
+type scanProgress struct {
+	scanned int // Candidate notes loaded and matched
+	total   int // Candidate notes found, a lower bound if the walk stopped early
+}
+
+// scanNotes is walkNotesSkipping without discarding the work done when ctx
+// is cancelled: the notes matched so far are returned with the error
+func (v *vault) scanNotes(ctx context.Context, scope ListOptions, skip func(noteFile) bool, match matchFunc) ([]NoteInfo, scanProgress, error) {
+	files, err := v.collectNotes(ctx, scope, skip)
+	if err != nil {
+		return nil, scanProgress{total: len(files)}, err
+	}
+
+	// Phase 2: load and match candidates concurrently
+	notes, scanned, err := v.processNotes(ctx, files, scope.PreviewLength, v.types.filter(scope.Type, match))
+	if scope.IncludeAnnotations {
+		v.annotateNotes(notes)
+	}
+	return notes, scanProgress{scanned: scanned, total: len(files)}, err
+}
+
+// collectNotes finds the candidate notes selected by scope, in byte-wise
+// path order, without loading them. Notes for which skip returns true are
+// left out. On failure the candidates found so far are returned.
+func (v *vault) collectNotes(ctx context.Context, scope ListOptions, skip func(noteFile) bool) ([]noteFile, error) {
+	root, err := v.validateDir(scope.Subpath)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.types.check(scope.Type); err != nil {
+		return nil, err
+	}
+	includeHidden := v.includeHidden || scope.IncludeHidden
+	if report := progressFrom(ctx); report != nil {
+		report(0, v.estimateNotes(root))
+	}
+
+	// Phase 1: collect candidate notes
+	var files []noteFile
+
+	walkFn := func(path string, info os.FileInfo, err error) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		if err != nil {
+			v.warnUnreadable(ctx, path, info, err)
+			return nil // Skip inaccessible files and directories
+		}
+
+		// The root was requested by name, so only entries below it can be hidden
+		if path != root && !includeHidden && isHidden(path) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if info.IsDir() {
+			if !scope.Recursive && path != root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !strings.HasSuffix(path, ".md") && !(scope.IncludeCanvas && isCanvas(path)) {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(v.basePath, path)
+		if err != nil {
+			return nil
+		}
+
+		file := noteFile{fullPath: path, relPath: filepath.ToSlash(relPath), info: info}
+		if skip != nil && skip(file) {
+			return nil
+		}
+		files = append(files, file)
+		return nil
+	}
+
+	if err := v.walk(root, walkFn); err != nil {
+		return files, fmt.Errorf("failed to walk directory: %w", err)
+	}
+
+	// Walks list a directory before the files next to it, so "a/b.md" comes
+	// before "a.md"; results are in byte-wise path order whatever the walk
+	slices.SortFunc(files, func(a, b noteFile) int { return strings.Compare(a.relPath, b.relPath) })
+	return files, nil
+}
+
+// isHidden reports whether the final element of path starts with a dot
+func isHidden(path string) bool {
+	return strings.HasPrefix(filepath.Base(path), ".")
+}
+
+// isWithin reports whether path equals dir or is located inside it
+func isWithin(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(os.PathSeparator))
+}
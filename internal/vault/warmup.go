@@ -0,0 +1,163 @@
+package vault
+
+import (
+	"context"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Warm-up states
+const (
+	WarmupRunning   = "running"
+	WarmupDone      = "done"
+	WarmupCancelled = "cancelled"
+)
+
+// WarmupStatus reports the progress of the cache warm-up
+type WarmupStatus struct {
+	State       string    `json:"state"`                 // running, done or cancelled
+	FilesTotal  int       `json:"files_total"`           // Notes found, 0 until the walk finishes
+	FilesPrimed int       `json:"files_primed"`          // Notes loaded into the cache
+	BytesLoaded int64     `json:"bytes_loaded"`          // Content loaded into the cache
+	BudgetFull  bool      `json:"budget_full,omitempty"` // Stopped at the cache's size budget
+	Started     time.Time `json:"started"`
+	Finished    time.Time `json:"finished,omitzero"`
+}
+
+// warmup tracks the background cache warm-up
+type warmup struct {
+	concurrency int // Notes loaded in parallel, 0 when warm-up is off
+	once        sync.Once
+
+	mu     sync.Mutex
+	status WarmupStatus
+
+	primed atomic.Int64
+	bytes  atomic.Int64
+}
+
+// WithWarmCache makes StartWarmup load every note into the cache in the
+// background, n at a time, newest first, so the first searches after
+// startup find it warm. Values below 1 leave warm-up off.
+func WithWarmCache(n int) Option {
+	return func(v *vault) {
+		v.warmup.concurrency = max(n, 0)
+	}
+}
+
+// StartWarmup starts the cache warm-up enabled by WithWarmCache and
+// returns at once. Notes are loaded most recently modified first, under
+// their write lock so a note being written is never cached stale, until
+// all are loaded, the cache's size budget is used up or ctx is cancelled.
+// Later calls do nothing.
+func (v *vault) StartWarmup(ctx context.Context) {
+	if v.warmup.concurrency == 0 {
+		return
+	}
+	v.warmup.once.Do(func() {
+		v.warmup.mu.Lock()
+		v.warmup.status = WarmupStatus{State: WarmupRunning, Started: time.Now()}
+		v.warmup.mu.Unlock()
+
+		go v.runWarmup(ctx)
+	})
+}
+
+// runWarmup loads the vault's notes into the cache. A panic ends the
+// warm-up rather than the process, as no tool call waits on it.
+func (v *vault) runWarmup(ctx context.Context) {
+	defer func() {
+		if p := recover(); p != nil {
+			v.logger.Error("cache warm-up panicked", "panic", p, "stack", string(debug.Stack()))
+			v.finishWarmup(ctx, false)
+		}
+	}()
+
+	var files []noteFile
+	_, err := v.walkNotesSkipping(ctx, ListOptions{Recursive: true}, func(file noteFile) bool {
+		files = append(files, file)
+		return true // Collect only; loading happens below
+	}, nil)
+	if err != nil {
+		v.finishWarmup(ctx, false)
+		return
+	}
+	slices.SortFunc(files, func(a, b noteFile) int {
+		return b.info.ModTime().Compare(a.info.ModTime())
+	})
+
+	v.warmup.mu.Lock()
+	v.warmup.status.FilesTotal = len(files)
+	v.warmup.mu.Unlock()
+
+	// Stop short of the budget so priming older notes never evicts newer ones
+	var full atomic.Bool
+	jobs := make(chan noteFile)
+	var wg sync.WaitGroup
+	var panics workerPanics
+	for range min(v.warmup.concurrency, len(files)) {
+		wg.Go(func() {
+			for file := range jobs {
+				if ctx.Err() != nil || full.Load() {
+					continue
+				}
+				if v.cacheMaxBytes > 0 && v.warmup.bytes.Load()+file.info.Size() > v.cacheMaxBytes {
+					full.Store(true)
+					continue
+				}
+
+				panics.run(func() {
+					unlock := v.writeLocks.lock(file.fullPath)
+					defer unlock()
+					entry, err := v.loadEntry(file.fullPath, file.info.ModTime())
+					if err != nil {
+						return
+					}
+					v.warmup.primed.Add(1)
+					v.warmup.bytes.Add(int64(len(entry.Content)))
+				})
+			}
+		})
+	}
+	for _, file := range files {
+		jobs <- file
+	}
+	close(jobs)
+	wg.Wait()
+	panics.repanic()
+
+	v.finishWarmup(ctx, full.Load())
+}
+
+// finishWarmup records the end of the warm-up
+func (v *vault) finishWarmup(ctx context.Context, budgetFull bool) {
+	v.warmup.mu.Lock()
+	defer v.warmup.mu.Unlock()
+
+	v.warmup.status.State = WarmupDone
+	if ctx.Err() != nil {
+		v.warmup.status.State = WarmupCancelled
+	}
+	v.warmup.status.BudgetFull = budgetFull
+	v.warmup.status.Finished = time.Now()
+	v.logger.Debug("cache warm-up finished", "state", v.warmup.status.State,
+		"primed", v.warmup.primed.Load(), "bytes", v.warmup.bytes.Load(),
+		"duration", v.warmup.status.Finished.Sub(v.warmup.status.Started))
+}
+
+// warmupStatus returns the warm-up progress, nil when it never started
+func (v *vault) warmupStatus() *WarmupStatus {
+	v.warmup.mu.Lock()
+	defer v.warmup.mu.Unlock()
+
+	if v.warmup.status.State == "" {
+		return nil
+	}
+	status := v.warmup.status
+	status.FilesPrimed = int(v.warmup.primed.Load())
+	status.BytesLoaded = v.warmup.bytes.Load()
+	return &status
+}
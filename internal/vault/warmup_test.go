@@ -0,0 +1,133 @@
+package vault
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitWarmup polls until the warm-up of v leaves the running state
+func waitWarmup(t *testing.T, v *vault) WarmupStatus {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if status := v.warmupStatus(); status != nil && status.State != WarmupRunning {
+			return *status
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("warm-up did not finish")
+	return WarmupStatus{}
+}
+
+func TestWarmup(t *testing.T) {
+	_, tmpDir := setupTestVault(t)
+	vi, err := NewVault(tmpDir, WithWarmCache(2))
+	if err != nil {
+		t.Fatalf("NewVault() error = %v", err)
+	}
+	v := vi.(*vault)
+
+	v.StartWarmup(context.Background())
+	v.StartWarmup(context.Background()) // Later calls do nothing
+	status := waitWarmup(t, v)
+
+	if status.State != WarmupDone {
+		t.Errorf("State = %q, want %q", status.State, WarmupDone)
+	}
+	// note1-5; hidden notes are skipped
+	if status.FilesTotal != 5 || status.FilesPrimed != 5 {
+		t.Errorf("FilesTotal, FilesPrimed = %d, %d, want 5, 5", status.FilesTotal, status.FilesPrimed)
+	}
+	if status.BytesLoaded == 0 || status.Finished.IsZero() {
+		t.Errorf("BytesLoaded = %d, Finished = %v, want both set", status.BytesLoaded, status.Finished)
+	}
+	if stats := v.cache.CacheStats(); stats.Entries != 5 {
+		t.Errorf("cache entries = %d, want 5", stats.Entries)
+	}
+
+	// Notes served afterwards come from the cache
+	if _, err := v.Read(context.Background(), "note1.md"); err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if stats := v.cache.CacheStats(); stats.Hits == 0 {
+		t.Errorf("cache hits = 0 after warm-up, want > 0")
+	}
+
+	info, err := v.Info(context.Background())
+	if err != nil {
+		t.Fatalf("Info() error = %v", err)
+	}
+	if info.Warmup == nil || info.Warmup.FilesPrimed != 5 || info.Features.WarmCache != 2 {
+		t.Errorf("Info() Warmup = %+v, WarmCache = %d", info.Warmup, info.Features.WarmCache)
+	}
+}
+
+func TestWarmupNewestFirst(t *testing.T) {
+	tmpDir := t.TempDir()
+	base := time.Now().Add(-time.Hour)
+	for i, name := range []string{"old.md", "middle.md", "new.md"} {
+		path := filepath.Join(tmpDir, name)
+		if err := os.WriteFile(path, []byte(strings.Repeat("x", 100)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := base.Add(time.Duration(i) * time.Minute)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Room for two of the three notes
+	vi, err := NewVault(tmpDir, WithWarmCache(1), WithCacheSize(250))
+	if err != nil {
+		t.Fatalf("NewVault() error = %v", err)
+	}
+	v := vi.(*vault)
+	v.StartWarmup(context.Background())
+	status := waitWarmup(t, v)
+
+	if status.FilesTotal != 3 || status.FilesPrimed != 2 || !status.BudgetFull {
+		t.Errorf("status = %+v, want 2 of 3 primed with the budget full", status)
+	}
+	stamps := v.cache.Stamps()
+	for name, want := range map[string]bool{"new.md": true, "middle.md": true, "old.md": false} {
+		if _, ok := stamps[filepath.Join(tmpDir, name)]; ok != want {
+			t.Errorf("%s cached = %v, want %v", name, ok, want)
+		}
+	}
+}
+
+func TestWarmupCancelled(t *testing.T) {
+	_, tmpDir := setupTestVault(t)
+	vi, err := NewVault(tmpDir, WithWarmCache(1))
+	if err != nil {
+		t.Fatalf("NewVault() error = %v", err)
+	}
+	v := vi.(*vault)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	v.StartWarmup(ctx)
+	if status := waitWarmup(t, v); status.State != WarmupCancelled {
+		t.Errorf("State = %q, want %q", status.State, WarmupCancelled)
+	}
+}
+
+func TestWarmupDisabled(t *testing.T) {
+	vi, tmpDir := setupTestVault(t)
+	vi.StartWarmup(context.Background())
+
+	info, err := vi.Info(context.Background())
+	if err != nil {
+		t.Fatalf("Info() error = %v", err)
+	}
+	if info.Warmup != nil {
+		t.Errorf("Info() Warmup = %+v, want nil when warm-up is off", info.Warmup)
+	}
+	if stats := vi.(*vault).cache.CacheStats(); stats.Entries != 0 {
+		t.Errorf("cache entries = %d in %s, want 0", stats.Entries, tmpDir)
+	}
+}
@@ -1,42 +1,335 @@
 // Package main provides the entry point for the MCP notes server.
-// It initializes the vault and starts the MCP server with stdio transport.
+// It initializes the vault and starts the MCP server with stdio transport,
+// or runs one of the offline commands such as verify against the vault.
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
 
-	"github.com/mark3labs/mcp-go/server"
+	"gopkg.in/yaml.v3"
 
+	"github.com/kratos/mcp-notes/internal/config"
+	"github.com/kratos/mcp-notes/internal/metrics"
 	internalserver "github.com/kratos/mcp-notes/internal/server"
 	"github.com/kratos/mcp-notes/internal/vault"
 )
 
 func main() {
-	// Parse command-line arguments
-	if len(os.Args) < 2 {
-		fmt.Fprintf(os.Stderr, "Usage: %s <vault-path>\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "\nExample:\n")
-		fmt.Fprintf(os.Stderr, "  %s /path/to/obsidian/vault\n", os.Args[0])
-		os.Exit(1)
+	// An optional command comes first; a bare vault path serves it
+	command, args := commandServe, os.Args[1:]
+	if len(args) > 0 && isCommand(args[0]) {
+		command, args = args[0], args[1:]
+	}
+
+	// Read the settings from the config file and the flags
+	loaded, err := parseSettings(args)
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	cfg := loaded.config
+
+	if cfg.Server.ExportDir != "" {
+		dir, err := filepath.Abs(cfg.Server.ExportDir)
+		if err != nil {
+			log.Fatalf("Invalid export directory %q: %v", cfg.Server.ExportDir, err)
+		}
+		cfg.Server.ExportDir = dir
+	}
+
+	// Set up logging
+	// Logs must never go to stdout: it carries the stdio transport
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Log.Level)); err != nil {
+		log.Fatalf("Invalid log level %q: %v", cfg.Log.Level, err)
 	}
 
-	vaultPath := os.Args[1]
+	var logOutput io.Writer = os.Stderr
+	if cfg.Log.File != "" && !loaded.checkConfig {
+		f, err := os.OpenFile(cfg.Log.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			log.Fatalf("Failed to open log file: %v", err)
+		}
+		defer f.Close()
+		logOutput = f
+	}
+
+	logHandler := slog.NewTextHandler(logOutput, &slog.HandlerOptions{Level: level})
+	logger := slog.New(logHandler)
+	if !loaded.checkConfig {
+		for _, warning := range loaded.warnings {
+			logger.Warn(warning, "config", loaded.file)
+		}
+	}
+
+	backupVersions := cfg.Backups.Versions
+	if cfg.Backups.Disabled {
+		backupVersions = 0
+	}
 
 	// Create vault instance
+	vaultOpts := []vault.Option{
+		vault.WithRoot(cfg.Notes.Root),
+		vault.WithFollowSymlinks(cfg.Notes.FollowSymlinks),
+		vault.WithIncludeHidden(cfg.Notes.IncludeHidden),
+		vault.WithLogger(logger),
+		vault.WithConcurrency(cfg.Notes.Concurrency),
+		vault.WithCacheSize(cfg.Cache.SizeMiB << 20),
+		vault.WithWarmCache(cfg.Cache.Warm),
+		vault.WithLockTTL(cfg.Locks.TTL),
+		vault.WithAuditLog(cfg.Audit.File, cfg.Audit.SizeMiB<<20),
+		vault.WithStrictAudit(cfg.Audit.Strict),
+		vault.WithBackups(backupVersions),
+		vault.WithCreatedFields(cfg.Notes.CreatedFields...),
+		vault.WithDateFormat(cfg.Notes.DateFormat),
+		vault.WithIndexNotes(cfg.Notes.IndexNotes...),
+		vault.WithTitleSources(cfg.TitleSources()),
+		vault.WithSourceEncoding(cfg.Notes.SourceEncoding),
+		vault.WithObsidianConfig(cfg.Notes.ObsidianConfig),
+		vault.WithAttachmentFolder(cfg.Notes.AttachmentFolder),
+		vault.WithReadOnlyPaths(cfg.Paths.ReadOnly...),
+		vault.WithWritablePaths(cfg.Paths.Writable...),
+		vault.WithBatchLimits(vault.BatchLimits{MaxOperations: cfg.Limits.BatchOps, MaxBytes: cfg.Limits.BatchKiB << 10}),
+		vault.WithCapture(cfg.CaptureSettings()),
+		vault.WithPublish(cfg.PublishSettings()),
+		vault.WithLint(cfg.LintSettings()),
+		vault.WithNoteTypes(cfg.NoteTypeSettings()),
+		vault.WithScratch(vault.ScratchSettings{Dir: cfg.Scratch.Dir, MaxNotes: cfg.Scratch.MaxNotes, MaxBytes: cfg.Scratch.MaxKiB << 10}),
+		vault.WithBlobThresholds(vault.BlobThresholds{MinSize: cfg.Blobs.MinSizeKiB << 10, LineLength: cfg.Blobs.LineLength, DataRatio: cfg.Blobs.DataRatio}),
+	}
+	frontmatterOpts, err := frontmatterOptions(cfg.Frontmatter.Config, cfg.Frontmatter.Auto, cfg.Frontmatter.Tags, cfg.Frontmatter.DateFormat)
+	if err != nil {
+		log.Fatalf("Invalid frontmatter settings: %v", err)
+	}
+	vaultOpts = append(vaultOpts, frontmatterOpts...)
+
+	if loaded.checkConfig {
+		if err := printConfig(os.Stdout, os.Stderr, loaded); err != nil {
+			log.Fatalf("Failed to print configuration: %v", err)
+		}
+		return
+	}
+
+	if cfg.Cache.SearchIndex || command == commandIndex {
+		vaultOpts = append(vaultOpts, vault.WithSearchIndex())
+	}
+	var registry *metrics.Registry
+	if (cfg.Metrics.Enabled || cfg.Metrics.Addr != "") && command == commandServe {
+		registry = metrics.NewRegistry()
+		vaultOpts = append(vaultOpts, vault.WithMetrics(registry))
+	}
+
 	// NewVault validates that the path exists and is accessible
-	v, err := vault.NewVault(vaultPath)
+	v, err := vault.NewVault(cfg.Vault, vaultOpts...)
 	if err != nil {
 		log.Fatalf("Failed to create vault: %v", err)
 	}
 
+	// Cancel the root context on SIGINT or SIGTERM
+	// A second signal terminates immediately
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	if command != commandServe {
+		err := runCommand(ctx, command, v, cfg.JSON, os.Stdout)
+		stop()
+		if errors.Is(err, errProblemsFound) {
+			os.Exit(1)
+		}
+		if err != nil {
+			log.Fatalf("%s failed: %v", command, err)
+		}
+		return
+	}
+
+	// Throttle writes so a looping agent cannot churn the vault
+	v = vault.NewRateLimitedVault(v, vault.WriteLimits{
+		PerMinute:       cfg.Limits.WritesPerMinute,
+		FilePerMinute:   cfg.Limits.FileWritesPerMinute,
+		FilesPerSession: cfg.Limits.FilesPerSession,
+	})
+
 	// Create MCP server with registered tools
-	srv := internalserver.NewServer(v)
+	serverOpts := internalserver.Options{
+		VaultName:        cfg.Notes.VaultName,
+		VaultRoot:        cfg.Notes.Root,
+		SearchTimeout:    cfg.Server.SearchTimeout,
+		ToolTimeout:      cfg.Server.ToolTimeout,
+		MaxResponseBytes: cfg.Server.MaxResponseBytes,
+		IgnoreRoots:      cfg.Server.IgnoreRoots,
+		Tools:            cfg.ToolPolicy(),
+		ClientName:       cfg.Locks.ClientName,
+		ExportDir:        cfg.Server.ExportDir,
+	}
+	if registry != nil {
+		serverOpts.Metrics = registry
+	}
+	srv := internalserver.NewServer(v, logger, serverOpts)
+
+	if cfg.Metrics.Addr != "" {
+		if _, err := internalserver.ServeMetrics(ctx, cfg.Metrics.Addr, registry, logger); err != nil {
+			log.Fatalf("Failed to serve metrics: %v", err)
+		}
+	}
+
+	logger.Info("serving vault", "path", cfg.Vault)
+
+	// Prime the cache while the client connects; tool calls are served meanwhile
+	v.StartWarmup(ctx)
 
 	// Serve via stdio transport
-	// This blocks until the server is shut down or an error occurs
-	if err := server.ServeStdio(srv); err != nil {
-		log.Fatalf("Server error: %v", err)
+	// This blocks until stdin is closed or in-flight calls drain after a signal
+	err = internalserver.Run(
+		ctx,
+		srv,
+		internalserver.WithGracePeriod(cfg.Server.ShutdownTimeout),
+		internalserver.WithLogger(logger),
+	)
+	stop()
+	if err != nil {
+		logger.Error("server error", "error", err)
+		os.Exit(1)
+	}
+}
+
+// frontmatterOptions builds the vault options for the frontmatter config
+// file and flags. The flags enable the template and override its tags and
+// format; the schema comes only from the file.
+func frontmatterOptions(file string, auto bool, tags []string, dateFormat string) ([]vault.Option, error) {
+	var fm vault.FrontmatterConfig
+	if file != "" {
+		var err error
+		if fm, err = vault.LoadFrontmatterConfig(file); err != nil {
+			return nil, err
+		}
+	}
+
+	if auto && fm.Template == nil {
+		fm.Template = &vault.FrontmatterTemplate{Fields: map[string]any{"source": "mcp"}}
+	}
+	if fm.Template == nil && (len(tags) > 0 || dateFormat != "") {
+		return nil, errors.New("--frontmatter-tags and --frontmatter-date-format (frontmatter.tags and frontmatter.date_format) need --auto-frontmatter or a template in --frontmatter-config")
+	}
+
+	var opts []vault.Option
+	if fm.Template != nil {
+		if len(tags) > 0 {
+			fm.Template.Tags = tags
+		}
+		if dateFormat != "" {
+			fm.Template.CreatedFormat = dateFormat
+		}
+		opts = append(opts, vault.WithFrontmatterTemplate(*fm.Template))
+	}
+	if len(fm.Schema) > 0 {
+		opts = append(opts, vault.WithFrontmatterSchema(fm.Schema))
+	}
+	return opts, nil
+}
+
+// settings are the server settings and where they came from
+type settings struct {
+	config      config.Config
+	file        string   // Config file read, empty when there is none
+	warnings    []string // Unknown keys in the file
+	checkConfig bool     // Print the settings instead of running
+}
+
+// parseSettings reads the config file named by --config, or found in the
+// vault, and applies the flags of args over it. Flags are parsed twice:
+// once to find the vault and the file, then over the file's settings so
+// that flags given explicitly win.
+func parseSettings(args []string) (settings, error) {
+	cfg := baseConfig()
+	fs, file, check := newFlagSet(&cfg)
+	fs.Parse(args)
+	s := settings{file: *file, checkConfig: *check}
+	if s.file == "" && fs.NArg() > 0 {
+		s.file = config.Find(fs.Arg(0))
+	}
+
+	cfg = baseConfig()
+	if s.file != "" {
+		var err error
+		if s.warnings, err = config.Load(s.file, &cfg); err != nil {
+			return s, err
+		}
+	}
+	fs, _, _ = newFlagSet(&cfg)
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		cfg.Vault = fs.Arg(0)
+	}
+	if cfg.Vault == "" {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	s.config = cfg
+	return s, cfg.Validate()
+}
+
+// baseConfig returns the default settings, with those taken from the
+// environment
+func baseConfig() config.Config {
+	cfg := config.Default()
+	cfg.Notes.VaultName = os.Getenv("MCP_NOTES_VAULT_NAME")
+	return cfg
+}
+
+// newFlagSet returns the command-line flags setting cfg, and the --config
+// and --check-config flags
+func newFlagSet(cfg *config.Config) (*flag.FlagSet, *string, *bool) {
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	file := fs.String("config", "", "YAML file with the settings of these flags (default "+config.DefaultFile+" in the vault)")
+	check := fs.Bool("check-config", false, "Load and validate the settings, print them and exit without serving")
+	cfg.BindFlags(fs)
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [command] [flags] <vault-path>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nCommands:\n")
+		for _, command := range commands {
+			fmt.Fprintf(os.Stderr, "  %-8s %s\n", command.name, command.description)
+		}
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  %s /path/to/obsidian/vault\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s verify --json /path/to/obsidian/vault\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --config notes.yaml --check-config\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	return fs, file, check
+}
+
+// printConfig writes the settings of --check-config as YAML to out, and
+// the file they came from and its warnings to errOut
+func printConfig(out, errOut io.Writer, s settings) error {
+	if s.file != "" {
+		fmt.Fprintf(errOut, "Read %s\n", s.file)
+	}
+	for _, warning := range s.warnings {
+		fmt.Fprintf(errOut, "Warning: %s\n", warning)
+	}
+	if stat, err := os.Stat(s.config.Vault); err != nil || !stat.IsDir() {
+		return fmt.Errorf("vault %s is not a directory", s.config.Vault)
+	}
+
+	encoder := yaml.NewEncoder(out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(s.config); err != nil {
+		return err
 	}
+	return encoder.Close()
 }